@@ -131,7 +131,7 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 			dockerCli.ContainerKill(context.TODO(), containerName, "SIGKILL")
 			dockerCli.ContainerRemove(context.TODO(), containerName, dockertypes.ContainerRemoveOptions{Force: true})
 			dockerCli.ImageRemove(context.TODO(), repoName, dockertypes.ImageRemoveOptions{Force: true, PruneChildren: true})
-			cacheImage, err := cache.New(repoName, dockerCli)
+			cacheImage, err := cache.New(repoName, dockerCli, cache.TypeImage)
 			h.AssertNil(t, err)
 			cacheImage.Clear(context.TODO())
 			if sourceCodePath != "" {
@@ -156,6 +156,9 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 				t.Log("app is runnable")
 				assertNodeAppRuns(t, repoName)
 
+				t.Log("skips analyze, as there is no previous image or cache")
+				h.AssertContains(t, output, "Skipping 'analyze' as there is no previous image or cache")
+
 				t.Log("it uses the default run image as a base image")
 				assertHasBase(t, repoName, h.DefaultRunImage(t, registryConfig.RunRegistryPort))
 
@@ -181,9 +184,10 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 				t.Log("app is runnable")
 				assertNodeAppRuns(t, repoName)
 
-				t.Log("restores the cache")
+				t.Log("restores the cache and analyzes the previous image")
 				h.AssertContainsMatch(t, output, `\[restorer] restoring cached layer 'io.buildpacks.samples.nodejs:nodejs'`)
 				h.AssertContainsMatch(t, output, `\[analyzer] using cached launch layer 'io.buildpacks.samples.nodejs:nodejs'`)
+				h.AssertNotContains(t, output, "Skipping 'analyze'")
 
 				t.Log("exporter and cacher reuse unchanged layers")
 				h.AssertContainsMatch(t, output, `\[exporter] reusing layer 'io.buildpacks.samples.nodejs:nodejs'`)
@@ -205,12 +209,36 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 
 				t.Log("cacher adds layers")
 				h.AssertContainsMatch(t, output, `\[cacher] adding layer 'io.buildpacks.samples.nodejs:nodejs'`)
+
+				t.Log("rebuild with --no-cache")
+				cmd = packCmd("build", repoName, "-p", "testdata/node_app/.", "--no-cache")
+				output = h.Run(t, cmd)
+				h.AssertContains(t, output, fmt.Sprintf("Successfully built image '%s'", repoName))
+
+				t.Log("skips restore and cache")
+				h.AssertContains(t, output, "Skipping 'restore' as caching is disabled")
+				h.AssertContains(t, output, "Skipping 'cache' as caching is disabled")
+				h.AssertNotContains(t, output, "[restorer]")
+				h.AssertNotContains(t, output, "[cacher]")
+
+				t.Log("analyzes the previous image without a cache")
+				h.AssertNotContains(t, output, "Skipping 'analyze'")
+
+				t.Log("rebuild without the previous image")
+				_, err = dockerCli.ImageRemove(context.TODO(), repoName, dockertypes.ImageRemoveOptions{Force: true})
+				h.AssertNil(t, err)
+				cmd = packCmd("build", repoName, "-p", "testdata/node_app/.")
+				output = h.Run(t, cmd)
+				h.AssertContains(t, output, fmt.Sprintf("Successfully built image '%s'", repoName))
+
+				t.Log("analyzes the cache")
+				h.AssertNotContains(t, output, "Skipping 'analyze'")
+				h.AssertContains(t, output, "[analyzer]")
 			})
 
 			when("--buildpack", func() {
 				when("the argument is a directory", func() {
 					it("adds the buildpack to the builder and runs it", func() {
-						skipOnWindows(t, "buildpack directories not supported on windows")
 						cmd := packCmd(
 							"build", repoName,
 							"-p", filepath.Join("testdata", "mock_app"),
@@ -242,7 +270,6 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 
 					when("the buildpack stack doesn't match the builder", func() {
 						it.Pend("errors", func() {
-							skipOnWindows(t, "buildpack directories not supported on windows")
 							cmd := packCmd(
 								"build", repoName,
 								"-p", filepath.Join("testdata", "mock_app"),
@@ -261,8 +288,6 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 				var envPath string
 
 				it.Before(func() {
-					skipOnWindows(t, "directory buildpacks are not implemented on windows")
-
 					envfile, err := ioutil.TempFile("", "envfile")
 					h.AssertNil(t, err)
 					err = os.Setenv("VAR3", "value from env")
@@ -300,8 +325,6 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 
 			when("--env", func() {
 				it.Before(func() {
-					skipOnWindows(t, "directory buildpacks are not implemented on windows")
-
 					err := os.Setenv("VAR3", "value from env")
 					h.AssertNil(t, err)
 				})
@@ -407,6 +430,7 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 					}
 					output := runPackBuild()
 					h.AssertContains(t, output, fmt.Sprintf("Successfully built image '%s'", repoName))
+					h.AssertNotContains(t, output, "Skipping 'analyze'")
 					imgSHA, err := imgSHAFromOutput(output, repoName)
 					if err != nil {
 						t.Log(output)
@@ -490,7 +514,7 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 			it.After(func() {
 				repoName := fmt.Sprintf("pack.local/run/%x", md5.Sum([]byte(sourceCodePath)))
 				h.AssertNil(t, h.DockerRmi(dockerCli, repoName))
-				cacheImage, err := cache.New(repoName, dockerCli)
+				cacheImage, err := cache.New(repoName, dockerCli, cache.TypeImage)
 				h.AssertNil(t, err)
 				cacheImage.Clear(context.TODO())
 				if sourceCodePath != "" {
@@ -599,7 +623,7 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 
 			it.After(func() {
 				h.DockerRmi(dockerCli, origID, runBefore, runAfter)
-				cacheImage, err := cache.New(repoName, dockerCli)
+				cacheImage, err := cache.New(repoName, dockerCli, cache.TypeImage)
 				h.AssertNil(t, err)
 				cacheImage.Clear(context.TODO())
 			})
@@ -638,7 +662,7 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 
 			it.After(func() {
 				h.DockerRmi(dockerCli, runBefore, runAfter)
-				cacheImage, err := cache.New(repoName, dockerCli)
+				cacheImage, err := cache.New(repoName, dockerCli, cache.TypeImage)
 				h.AssertNil(t, err)
 				cacheImage.Clear(context.TODO())
 			})
@@ -688,7 +712,7 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 
 			it.After(func() {
 				h.DockerRmi(dockerCli, origID, builderName, origRunImageID, runImage)
-				cacheImage, err := cache.New(repoName, dockerCli)
+				cacheImage, err := cache.New(repoName, dockerCli, cache.TypeImage)
 				h.AssertNil(t, err)
 				cacheImage.Clear(context.TODO())
 			})
@@ -751,7 +775,7 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S) {
 			dockerCli.ContainerKill(context.TODO(), containerName, "SIGKILL")
 			dockerCli.ImageRemove(context.TODO(), builderRepoName, dockertypes.ImageRemoveOptions{Force: true, PruneChildren: true})
 			dockerCli.ImageRemove(context.TODO(), repoName, dockertypes.ImageRemoveOptions{Force: true, PruneChildren: true})
-			cacheImage, err := cache.New(repoName, dockerCli)
+			cacheImage, err := cache.New(repoName, dockerCli, cache.TypeImage)
 			h.AssertNil(t, err)
 			cacheImage.Clear(context.TODO())
 		})
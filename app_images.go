@@ -0,0 +1,67 @@
+package pack
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/buildpack/lifecycle"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+)
+
+// AppImage is an app image built by pack in the docker daemon
+type AppImage struct {
+	ID string
+	// Tags are the tags of the image, empty when it is dangling
+	Tags []string
+	// RunImage is the run image of the stack the image was built for
+	RunImage string
+	// Stack is the ID of the stack of the run image the image is based on
+	Stack   string
+	Created time.Time
+}
+
+// AppImages lists the app images built by pack in the docker daemon, which carry the metadata of
+// the lifecycle, the most recently created first
+func (c *Client) AppImages(ctx context.Context) ([]AppImage, error) {
+	return ListAppImages(ctx, c.docker)
+}
+
+// ListAppImages lists the app images in the daemon of the given docker client, see Client.AppImages
+func ListAppImages(ctx context.Context, docker Docker) ([]AppImage, error) {
+	summaries, err := docker.ImageList(ctx, types.ImageListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", lifecycle.MetadataLabel)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing app images")
+	}
+
+	var images []AppImage
+	for _, summary := range summaries {
+		img := AppImage{
+			ID:      summary.ID,
+			Stack:   summary.Labels["io.buildpacks.stack.id"],
+			Created: time.Unix(summary.Created, 0),
+		}
+		for _, tag := range summary.RepoTags {
+			if tag != "<none>:<none>" {
+				img.Tags = append(img.Tags, tag)
+			}
+		}
+		sort.Strings(img.Tags)
+
+		var metadata lifecycle.AppImageMetadata
+		// images with metadata of another format are still listed, without their run image
+		if json.Unmarshal([]byte(summary.Labels[lifecycle.MetadataLabel]), &metadata) == nil {
+			img.RunImage = metadata.Stack.RunImage.Image
+		}
+		images = append(images, img)
+	}
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].Created.After(images[j].Created)
+	})
+	return images, nil
+}
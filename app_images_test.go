@@ -0,0 +1,102 @@
+package pack_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestAppImages(t *testing.T) {
+	spec.Run(t, "AppImages", testAppImages, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testAppImages(t *testing.T, when spec.G, it spec.S) {
+	var (
+		mockController *gomock.Controller
+		mockDocker     *mocks.MockDocker
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockDocker = mocks.NewMockDocker(mockController)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("lists the images carrying the lifecycle metadata, the most recent first", func() {
+		mockDocker.EXPECT().ImageList(gomock.Any(), types.ImageListOptions{
+			Filters: filters.NewArgs(filters.Arg("label", "io.buildpacks.lifecycle.metadata")),
+		}).Return([]types.ImageSummary{
+			{
+				ID:       "sha256:old",
+				RepoTags: []string{"<none>:<none>"},
+				Created:  100,
+				Labels: map[string]string{
+					"io.buildpacks.lifecycle.metadata": `{"stack": {"runImage": {"image": "other/run"}}}`,
+				},
+			},
+			{
+				ID:       "sha256:new",
+				RepoTags: []string{"some/app:v2", "some/app:latest"},
+				Created:  200,
+				Labels: map[string]string{
+					"io.buildpacks.lifecycle.metadata": `{"stack": {"runImage": {"image": "some/run"}}}`,
+					"io.buildpacks.stack.id":           "some.stack",
+				},
+			},
+		}, nil)
+
+		images, err := pack.ListAppImages(context.TODO(), mockDocker)
+		h.AssertNil(t, err)
+		h.AssertEq(t, images, []pack.AppImage{
+			{
+				ID:       "sha256:new",
+				Tags:     []string{"some/app:latest", "some/app:v2"},
+				RunImage: "some/run",
+				Stack:    "some.stack",
+				Created:  time.Unix(200, 0),
+			},
+			{
+				ID:       "sha256:old",
+				RunImage: "other/run",
+				Created:  time.Unix(100, 0),
+			},
+		})
+	})
+
+	it("lists images whose metadata cannot be parsed without their run image", func() {
+		mockDocker.EXPECT().ImageList(gomock.Any(), gomock.Any()).Return([]types.ImageSummary{
+			{
+				ID:       "sha256:some",
+				RepoTags: []string{"some/app:latest"},
+				Labels:   map[string]string{"io.buildpacks.lifecycle.metadata": "not json"},
+			},
+		}, nil)
+
+		images, err := pack.ListAppImages(context.TODO(), mockDocker)
+		h.AssertNil(t, err)
+		h.AssertEq(t, len(images), 1)
+		h.AssertEq(t, images[0].Tags, []string{"some/app:latest"})
+		h.AssertEq(t, images[0].RunImage, "")
+	})
+
+	it("fails when the images cannot be listed", func() {
+		mockDocker.EXPECT().ImageList(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+
+		_, err := pack.ListAppImages(context.TODO(), mockDocker)
+		h.AssertError(t, err, "listing app images: some error")
+	})
+}
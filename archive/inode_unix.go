@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package archive
+
+import (
+	"os"
+	"syscall"
+)
+
+// hardlinkID identifies the file behind fi when other hard links to it may exist
+func hardlinkID(fi os.FileInfo) (inode, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || st.Nlink < 2 {
+		return inode{}, false
+	}
+	return inode{dev: uint64(st.Dev), ino: uint64(st.Ino)}, true
+}
@@ -0,0 +1,8 @@
+package archive
+
+import "os"
+
+// hardlinkID always reports false on windows, where os.FileInfo does not carry a file index
+func hardlinkID(fi os.FileInfo) (inode, bool) {
+	return inode{}, false
+}
@@ -0,0 +1,103 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"runtime"
+	"sync"
+)
+
+const gzipBlockSize = 1 << 20
+
+// parallelGzipWriter compresses blocks of its input concurrently. Each block is written as a
+// separate gzip member, in order, which gzip readers decompress as one continuous stream.
+type parallelGzipWriter struct {
+	w       io.Writer
+	buf     []byte
+	started bool
+	queue   chan chan []byte
+	done    chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func newParallelGzipWriter(w io.Writer) *parallelGzipWriter {
+	pw := &parallelGzipWriter{
+		w:     w,
+		buf:   make([]byte, 0, gzipBlockSize),
+		queue: make(chan chan []byte, runtime.NumCPU()),
+		done:  make(chan struct{}),
+	}
+	go pw.writeBlocks()
+	return pw
+}
+
+func (pw *parallelGzipWriter) Write(p []byte) (int, error) {
+	if err := pw.error(); err != nil {
+		return 0, err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		free := gzipBlockSize - len(pw.buf)
+		if free > len(p) {
+			free = len(p)
+		}
+		pw.buf = append(pw.buf, p[:free]...)
+		p = p[free:]
+		if len(pw.buf) == gzipBlockSize {
+			pw.compress()
+		}
+	}
+	return n, nil
+}
+
+// Close compresses any remaining input and waits until all blocks are written
+func (pw *parallelGzipWriter) Close() error {
+	if len(pw.buf) > 0 || !pw.started {
+		// empty input still needs one member to be a valid gzip stream
+		pw.compress()
+	}
+	close(pw.queue)
+	<-pw.done
+	return pw.error()
+}
+
+// compress starts compressing the buffered block, keeping its place in the output order
+func (pw *parallelGzipWriter) compress() {
+	block := pw.buf
+	pw.buf = make([]byte, 0, gzipBlockSize)
+	pw.started = true
+
+	result := make(chan []byte, 1)
+	pw.queue <- result
+	go func() {
+		var out bytes.Buffer
+		zw := gzip.NewWriter(&out)
+		zw.Write(block)
+		zw.Close()
+		result <- out.Bytes()
+	}()
+}
+
+func (pw *parallelGzipWriter) writeBlocks() {
+	defer close(pw.done)
+	for result := range pw.queue {
+		member := <-result
+		if pw.error() != nil {
+			continue
+		}
+		if _, err := pw.w.Write(member); err != nil {
+			pw.mu.Lock()
+			pw.err = err
+			pw.mu.Unlock()
+		}
+	}
+}
+
+func (pw *parallelGzipWriter) error() error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.err
+}
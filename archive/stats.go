@@ -0,0 +1,59 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DirStats summarizes the regular files in a directory
+type DirStats struct {
+	Files int
+	Size  int64
+	// Largest holds the largest files, largest first, with paths relative to the directory
+	Largest []FileSize
+}
+
+type FileSize struct {
+	Path string
+	Size int64
+}
+
+// ScanDir counts the regular files in dir accepted by filter, or all of them when it is nil, and
+// adds up their sizes, recording the top largest of them. Symlinks are not followed.
+func ScanDir(dir string, top int, filter Filter) (DirStats, error) {
+	var stats DirStats
+	err := filepath.Walk(dir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		if filter != nil && relPath != "." && !filter(filepath.ToSlash(relPath), fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		stats.Files++
+		stats.Size += fi.Size()
+
+		if top == 0 || (len(stats.Largest) == top && fi.Size() <= stats.Largest[top-1].Size) {
+			return nil
+		}
+		i := sort.Search(len(stats.Largest), func(i int) bool { return stats.Largest[i].Size < fi.Size() })
+		stats.Largest = append(stats.Largest, FileSize{})
+		copy(stats.Largest[i+1:], stats.Largest[i:])
+		stats.Largest[i] = FileSize{Path: relPath, Size: fi.Size()}
+		if len(stats.Largest) > top {
+			stats.Largest = stats.Largest[:top]
+		}
+		return nil
+	})
+	return stats, err
+}
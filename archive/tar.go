@@ -2,15 +2,18 @@ package archive
 
 import (
 	"archive/tar"
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"fmt"
 	"github.com/pkg/errors"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,35 +23,263 @@ func init() {
 	NormalizedDateTime = time.Date(1980, time.January, 1, 0, 0, 1, 0, time.UTC)
 }
 
-func CreateTar(tarFile, srcDir, tarDir string, uid, gid int) error {
+// SymlinkMode determines how symlinks are written when creating a tar of a directory
+type SymlinkMode int
+
+const (
+	// PreserveSymlinks writes symlinks as links, whether or not their targets are in the archive
+	PreserveSymlinks SymlinkMode = iota
+	// FollowSymlinks writes the files and directories that symlinks point to in place of the links
+	FollowSymlinks
+	// RejectEscapingSymlinks writes symlinks as links, but fails on links pointing outside of the directory
+	RejectEscapingSymlinks
+)
+
+var symlinkModeNames = map[SymlinkMode]string{
+	PreserveSymlinks:       "preserve",
+	FollowSymlinks:         "follow",
+	RejectEscapingSymlinks: "reject-escaping",
+}
+
+func (m SymlinkMode) String() string {
+	return symlinkModeNames[m]
+}
+
+// Set implements pflag.Value so the mode can be bound directly to a command line flag
+func (m *SymlinkMode) Set(s string) error {
+	for mode, name := range symlinkModeNames {
+		if name == s {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown symlink mode '%s', must be one of 'preserve', 'follow' or 'reject-escaping'", s)
+}
+
+func (m *SymlinkMode) Type() string {
+	return "mode"
+}
+
+// TarOptions holds the settings applied by options such as WithSymlinks
+type TarOptions struct {
+	symlinks     SymlinkMode
+	gzipAbove    int64
+	normalize    bool
+	windowsLayer bool
+	filter       Filter
+	modTime      time.Time
+}
+
+// entryModTime is the modification time of the entries, NormalizedDateTime unless set by WithModTime
+func (o TarOptions) entryModTime() time.Time {
+	if o.modTime.IsZero() {
+		return NormalizedDateTime
+	}
+	return o.modTime
+}
+
+// Filter selects the files written to a tar by their paths relative to the source directory, with
+// forward slashes. Directories it rejects are skipped with all of their contents.
+type Filter func(path string, isDir bool) bool
+
+// WithSymlinks sets how symlinks in the source directory are written. The default is PreserveSymlinks.
+func WithSymlinks(mode SymlinkMode) func(*TarOptions) {
+	return func(o *TarOptions) {
+		o.symlinks = mode
+	}
+}
+
+// WithNormalization makes the tar depend only on the names, contents and executable bits of the
+// files in the source directory. Besides the modification times, uid, gid and user names that are
+// always normalized, it sets the permissions of directories and executables to 0755 and of other
+// files to 0644, and leaves out access and change times and extended attributes, all of which vary
+// between machines checking out the same source.
+func WithNormalization() func(*TarOptions) {
+	return func(o *TarOptions) {
+		o.normalize = true
+	}
+}
+
+// WithModTime gives the entries of the tar the modification time t rather than NormalizedDateTime,
+// such as the time of the last commit of the source directory, so that tars of the same sources
+// are identical while recording when those sources changed
+func WithModTime(t time.Time) func(*TarOptions) {
+	return func(o *TarOptions) {
+		o.modTime = t.UTC()
+	}
+}
+
+// WithWindowsLayerFormat writes the tar as a layer of a windows image, which keeps the files of
+// the container file system in a Files directory next to a Hives directory for registry changes
+func WithWindowsLayerFormat() func(*TarOptions) {
+	return func(o *TarOptions) {
+		o.windowsLayer = true
+	}
+}
+
+// WithParallelGzip gzip compresses the tar, using all CPUs, when the regular files in the source
+// directory add up to more than threshold bytes. Smaller directories are written uncompressed.
+func WithParallelGzip(threshold int64) func(*TarOptions) {
+	return func(o *TarOptions) {
+		o.gzipAbove = threshold
+	}
+}
+
+// WithFilter writes only the files and directories of the source directory accepted by filter
+func WithFilter(filter Filter) func(*TarOptions) {
+	return func(o *TarOptions) {
+		o.filter = filter
+	}
+}
+
+func CreateTar(tarFile, srcDir, tarDir string, uid, gid int, ops ...func(*TarOptions)) error {
 	fh, err := os.Create(tarFile)
 	if err != nil {
 		return fmt.Errorf("create file for tar: %s", err)
 	}
 	defer fh.Close()
-	return writeTarArchive(fh, srcDir, tarDir, uid, gid)
+	return writeTarArchive(fh, srcDir, tarDir, uid, gid, ops...)
 }
 
-func CreateTarReader(srcDir, tarDir string, uid, gid int) (io.Reader, chan error) {
+// CreateTarReader streams a tar of srcDir as it is read, so that no more than a small buffer per
+// file is held in memory. Closing the reader early stops writing the tar. The error of writing
+// the tar is sent on the returned channel once writing has finished.
+func CreateTarReader(srcDir, tarDir string, uid, gid int, ops ...func(*TarOptions)) (io.ReadCloser, chan error) {
 	r, w := io.Pipe()
 	errChan := make(chan error, 1)
 	go func() {
-		defer w.Close()
-		err := writeTarArchive(w, srcDir, tarDir, uid, gid)
-		w.Close()
+		err := writeTarArchive(w, srcDir, tarDir, uid, gid, ops...)
+		w.CloseWithError(err)
 		errChan <- err
 	}()
 	return r, errChan
 }
 
-func CreateSingleFileTar(tarFile, path, txt string) error {
+// RewriteTarReader streams the entries of the tar read from r, which may be gzip compressed, as a
+// tar of the directory tarDir, owned by uid and gid and with the modification time of the options.
+// Entries are kept within tarDir and filtered like the files of a directory. Symlinks are always
+// written as links, failing with RejectEscapingSymlinks on those pointing outside of tarDir. The
+// error of rewriting the tar is sent on the returned channel once writing has finished.
+func RewriteTarReader(r io.Reader, tarDir string, uid, gid int, ops ...func(*TarOptions)) (io.ReadCloser, chan error) {
+	pr, pw := io.Pipe()
+	errChan := make(chan error, 1)
+	go func() {
+		err := rewriteTar(pw, r, tarDir, uid, gid, ops...)
+		pw.CloseWithError(err)
+		errChan <- err
+	}()
+	return pr, errChan
+}
+
+func rewriteTar(w io.Writer, r io.Reader, tarDir string, uid, gid int, ops ...func(*TarOptions)) error {
+	var opts TarOptions
+	for _, op := range ops {
+		op(&opts)
+	}
+
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzr, err := gzip.NewReader(br)
+		if err != nil {
+			return errors.Wrap(err, "failed to create gzip reader")
+		}
+		defer gzr.Close()
+		r = gzr
+	} else {
+		r = br
+	}
+
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+
+	tarDir = filepath.ToSlash(tarDir)
+	if err := writeParentDirectoryHeaders(tarDir, tw, uid, gid, opts.entryModTime()); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "reading tar")
+		}
+
+		// entries named with leading slashes or '..' are kept within tarDir
+		rel := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if rel == "" {
+			continue
+		}
+		isDir := header.Typeflag == tar.TypeDir
+		if opts.filter != nil && !opts.filter(rel, isDir) {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir, tar.TypeReg, tar.TypeRegA:
+		case tar.TypeSymlink:
+			if opts.symlinks == RejectEscapingSymlinks && escapesTar(rel, header.Linkname) {
+				return fmt.Errorf("symlink %s points to %s outside of the tar", header.Name, header.Linkname)
+			}
+		case tar.TypeLink:
+			header.Linkname = path.Join(tarDir, strings.TrimPrefix(path.Clean("/"+header.Linkname), "/"))
+		default:
+			return fmt.Errorf("unsupported type of tar entry %s", header.Name)
+		}
+
+		header.Name = path.Join(tarDir, rel)
+		header.ModTime = opts.entryModTime()
+		header.Uid = uid
+		header.Gid = gid
+		header.Uname = ""
+		header.Gname = ""
+		if opts.normalize {
+			normalizeHeader(header)
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeRegA {
+			buf := copyBuffers.Get().(*[]byte)
+			_, err := io.CopyBuffer(tw, tr, *buf)
+			copyBuffers.Put(buf)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// escapesTar reports whether the symlink at the relative path rel of a tar, with the given target,
+// points outside of the tar
+func escapesTar(rel, target string) bool {
+	if path.IsAbs(target) {
+		return true
+	}
+	joined := path.Join(path.Dir(rel), target)
+	return joined == ".." || strings.HasPrefix(joined, "../")
+}
+
+func CreateSingleFileTar(tarFile, name, txt string, ops ...func(*TarOptions)) error {
+	var opts TarOptions
+	for _, op := range ops {
+		op(&opts)
+	}
+
 	fh, err := os.Create(tarFile)
 	if err != nil {
 		return fmt.Errorf("create file for tar: %s", err)
 	}
 
 	tw := tar.NewWriter(fh)
-	if err := tw.WriteHeader(&tar.Header{Name: path, Size: int64(len(txt)), Mode: 0666}); err != nil {
+	if opts.windowsLayer {
+		if err := WriteWindowsLayerDirs(tw); err != nil {
+			return err
+		}
+		name = WindowsLayerPath(name)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(txt)), Mode: 0666}); err != nil {
 		return err
 	}
 
@@ -77,6 +308,35 @@ func CreateSingleFileTarReader(path, txt string) (io.Reader, error) {
 	return bytes.NewReader(buf.Bytes()), nil
 }
 
+const (
+	windowsLayerFilesDir = "Files"
+	windowsLayerHivesDir = "Hives"
+)
+
+// WindowsLayerPath returns the name of the entry for the container path name in a windows layer
+func WindowsLayerPath(name string) string {
+	return path.Join(windowsLayerFilesDir, filepath.ToSlash(name))
+}
+
+// WriteWindowsLayerDirs writes the directories every windows layer starts with
+func WriteWindowsLayerDirs(tw *tar.Writer) error {
+	for _, dir := range []string{windowsLayerFilesDir, windowsLayerHivesDir} {
+		header := &tar.Header{
+			Name:     dir,
+			Mode:     0755,
+			Typeflag: tar.TypeDir,
+			ModTime:  NormalizedDateTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExtractTar extracts the tar read from r into dest. Entries outside of dest, symlinks pointing
+// outside of it and entries written through symlinks are rejected, so that a tar cannot write to
+// files elsewhere on the host.
 func ExtractTar(r io.Reader, dest string) error {
 	tr := tar.NewReader(r)
 	for {
@@ -88,6 +348,12 @@ func ExtractTar(r io.Reader, dest string) error {
 		}
 
 		path := filepath.Join(dest, hdr.Name)
+		if !withinDir(dest, path) {
+			return fmt.Errorf("tar entry %s is outside of the destination directory", hdr.Name)
+		}
+		if err := checkNoSymlinks(dest, path); err != nil {
+			return fmt.Errorf("tar entry %s is written through a symlink: %s", hdr.Name, err)
+		}
 
 		switch hdr.Typeflag {
 		case tar.TypeDir:
@@ -114,15 +380,62 @@ func ExtractTar(r io.Reader, dest string) error {
 
 			fh.Close()
 		case tar.TypeSymlink:
+			target := hdr.Linkname
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			if !withinDir(dest, target) {
+				return fmt.Errorf("tar entry %s links to %s outside of the destination directory", hdr.Name, hdr.Linkname)
+			}
 			if err := os.Symlink(hdr.Linkname, path); err != nil {
 				return err
 			}
+		case tar.TypeLink:
+			target := filepath.Join(dest, hdr.Linkname)
+			if !withinDir(dest, target) {
+				return fmt.Errorf("tar entry %s links to %s outside of the destination directory", hdr.Name, hdr.Linkname)
+			}
+			if err := checkNoSymlinks(dest, filepath.Dir(target)); err != nil {
+				return fmt.Errorf("tar entry %s links through a symlink: %s", hdr.Name, err)
+			}
+			if err := os.Link(target, path); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("unknown file type in tar %d", hdr.Typeflag)
 		}
 	}
 }
 
+// withinDir reports whether path, which is cleaned, is dir or inside of it
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkNoSymlinks returns an error when path, which is within dir, or one of its parents below dir
+// is an existing symlink, which writing to path would follow
+func checkNoSymlinks(dir, path string) error {
+	rel, err := filepath.Rel(filepath.Clean(dir), path)
+	if err != nil || rel == "." {
+		return err
+	}
+	current := filepath.Clean(dir)
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		fi, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symlink", current)
+		}
+	}
+	return nil
+}
+
 func ExtractTarGZ(r io.Reader, dest string) error {
 	gzr, err := gzip.NewReader(r)
 	if err != nil {
@@ -132,10 +445,10 @@ func ExtractTarGZ(r io.Reader, dest string) error {
 	return ExtractTar(gzr, dest)
 }
 
-func writeParentDirectoryHeaders(tarDir string, tw *tar.Writer, uid int, gid int) error {
-	parent := filepath.Dir(tarDir)
-	if isNotRootDir(parent) {
-		if err := writeParentDirectoryHeaders(parent, tw, uid, gid); err != nil {
+func writeParentDirectoryHeaders(tarDir string, tw *tar.Writer, uid int, gid int, modTime time.Time) error {
+	parent := path.Dir(tarDir)
+	if parent != "/" && parent != "." && parent != windowsLayerFilesDir {
+		if err := writeParentDirectoryHeaders(parent, tw, uid, gid, modTime); err != nil {
 			return err
 		}
 	}
@@ -145,10 +458,7 @@ func writeParentDirectoryHeaders(tarDir string, tw *tar.Writer, uid int, gid int
 		Gid:      gid,
 		Mode:     0755,
 		Typeflag: tar.TypeDir,
-		ModTime:  NormalizedDateTime,
-	}
-	if runtime.GOOS == "windows" {
-		header.Name = strings.Replace(header.Name, "\\", "/", -1)
+		ModTime:  modTime,
 	}
 	if err := tw.WriteHeader(header); err != nil {
 		return err
@@ -156,78 +466,221 @@ func writeParentDirectoryHeaders(tarDir string, tw *tar.Writer, uid int, gid int
 	return nil
 }
 
-func isNotRootDir(parent string) bool {
-	if runtime.GOOS == "windows" {
-		return parent != "\\"
+func writeTarArchive(w io.Writer, srcDir, tarDir string, uid, gid int, ops ...func(*TarOptions)) (err error) {
+	var opts TarOptions
+	for _, op := range ops {
+		op(&opts)
+	}
+
+	if opts.gzipAbove > 0 {
+		stats, err := ScanDir(srcDir, 0, opts.filter)
+		if err != nil {
+			return err
+		}
+		if stats.Size > opts.gzipAbove {
+			zw := newParallelGzipWriter(w)
+			defer func() {
+				if closeErr := zw.Close(); err == nil {
+					err = closeErr
+				}
+			}()
+			w = zw
+		}
 	}
-	return parent != "/"
-}
 
-func writeTarArchive(w io.Writer, srcDir, tarDir string, uid, gid int) error {
 	tw := tar.NewWriter(w)
 	defer tw.Close()
 
-	err := writeParentDirectoryHeaders(tarDir, tw, uid, gid)
-	if err != nil {
+	// entries are always named with forward slashes, as tarDir may have been built with filepath.Join
+	tarDir = filepath.ToSlash(tarDir)
+	if opts.windowsLayer {
+		if err := WriteWindowsLayerDirs(tw); err != nil {
+			return err
+		}
+		tarDir = WindowsLayerPath(tarDir)
+	}
+	if err := writeParentDirectoryHeaders(tarDir, tw, uid, gid, opts.entryModTime()); err != nil {
 		return err
 	}
 
-	return filepath.Walk(srcDir, func(file string, fi os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	aw := &archiveWriter{tw: tw, srcDir: srcDir, tarDir: tarDir, uid: uid, gid: gid, opts: opts, following: map[string]bool{}, links: map[inode]string{}}
+	return aw.writeDir(srcDir, tarDir)
+}
 
-		var header *tar.Header
-		if fi.Mode()&os.ModeSymlink != 0 {
-			target, err := os.Readlink(file)
-			if err != nil {
-				return err
-			}
+type archiveWriter struct {
+	tw       *tar.Writer
+	srcDir   string
+	tarDir   string
+	uid, gid int
+	opts     TarOptions
+	// following holds the directories currently being walked through a followed symlink
+	following map[string]bool
+	// links holds the name of the first entry written for each hard linked file
+	links map[inode]string
+}
 
-			header, err = tar.FileInfoHeader(fi, target)
-			if err != nil {
-				return err
-			}
-		} else {
-			header, err = tar.FileInfoHeader(fi, fi.Name())
-			if err != nil {
-				return err
-			}
+type inode struct {
+	dev, ino uint64
+}
+
+// writeDir writes the contents of dir to the archive under tarDir
+func (aw *archiveWriter) writeDir(dir, tarDir string) error {
+	return filepath.Walk(dir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
 		}
 
-		relPath, err := filepath.Rel(srcDir, file)
+		relPath, err := filepath.Rel(dir, file)
 		if err != nil {
 			return err
 		} else if relPath == "." {
 			return nil
 		}
 
-		header.Name = filepath.Join(tarDir, relPath)
-		if runtime.GOOS == "windows" {
-			header.Name = strings.Replace(header.Name, "\\", "/", -1)
+		name := path.Join(tarDir, filepath.ToSlash(relPath))
+		// files reached through followed symlinks are filtered by their path in the tar
+		if aw.opts.filter != nil && !aw.opts.filter(strings.TrimPrefix(name, aw.tarDir+"/"), fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
-		header.ModTime = NormalizedDateTime
-		header.Uid = uid
-		header.Gid = gid
-		header.Uname = ""
-		header.Gname = ""
+		return aw.writeEntry(file, fi, name)
+	})
+}
 
-		if err := tw.WriteHeader(header); err != nil {
-			return err
+func (aw *archiveWriter) writeEntry(file string, fi os.FileInfo, name string) error {
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return aw.writeFile(file, fi, name, "")
+	}
+
+	target, err := os.Readlink(file)
+	if err != nil {
+		return err
+	}
+	switch aw.opts.symlinks {
+	case FollowSymlinks:
+		return aw.followSymlink(file, name)
+	case RejectEscapingSymlinks:
+		if escapes(aw.srcDir, file, target) {
+			return fmt.Errorf("symlink %s points to %s outside of %s", file, target, aw.srcDir)
 		}
+	}
+	return aw.writeFile(file, fi, name, target)
+}
 
-		if fi.Mode().IsRegular() {
-			f, err := os.Open(file)
-			if err != nil {
-				return err
-			}
-			defer f.Close()
+func (aw *archiveWriter) followSymlink(file, name string) error {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return errors.Wrapf(err, "following symlink %s", file)
+	}
+	if !fi.IsDir() {
+		return aw.writeFile(file, fi, name, "")
+	}
 
-			if _, err := io.Copy(tw, f); err != nil {
-				return err
+	realDir, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return errors.Wrapf(err, "following symlink %s", file)
+	}
+	if aw.following[realDir] {
+		return fmt.Errorf("following symlink %s: cycle through %s", file, realDir)
+	}
+	aw.following[realDir] = true
+	defer delete(aw.following, realDir)
+
+	if err := aw.writeFile(file, fi, name, ""); err != nil {
+		return err
+	}
+	return aw.writeDir(realDir, name)
+}
+
+// writeFile writes a header for fi, followed by the contents of file when it is a regular file
+func (aw *archiveWriter) writeFile(file string, fi os.FileInfo, name, link string) error {
+	header, err := tar.FileInfoHeader(fi, link)
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+	if runtime.GOOS == "windows" {
+		// windows has no executable bits, so make everything executable like docker does,
+		// and take away write permission from the group and others
+		header.Mode = header.Mode&0755 | 0111
+	}
+	header.ModTime = aw.opts.entryModTime()
+	header.Uid = aw.uid
+	header.Gid = aw.gid
+	header.Uname = ""
+	header.Gname = ""
+	if aw.opts.normalize {
+		normalizeHeader(header)
+	}
+
+	if fi.Mode().IsRegular() {
+		if id, ok := hardlinkID(fi); ok {
+			if first, seen := aw.links[id]; seen {
+				// write further links to the same file as hard links rather than duplicating its contents
+				header.Typeflag = tar.TypeLink
+				header.Linkname = first
+				header.Size = 0
+				return aw.tw.WriteHeader(header)
 			}
+			aw.links[id] = header.Name
 		}
+	}
 
-		return nil
-	})
+	if err := aw.tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if fi.Mode().IsRegular() {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		buf := copyBuffers.Get().(*[]byte)
+		defer copyBuffers.Put(buf)
+		// copy exactly the size in the header, in case the file changes while it is written
+		if _, err := io.CopyBuffer(aw.tw, io.LimitReader(f, header.Size), *buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// normalizeHeader clears the fields of header that vary between machines, beyond those that
+// are always normalized
+func normalizeHeader(header *tar.Header) {
+	switch {
+	case header.Typeflag == tar.TypeSymlink:
+		header.Mode = 0777
+	case header.Typeflag == tar.TypeDir, header.Mode&0111 != 0:
+		header.Mode = 0755
+	default:
+		header.Mode = 0644
+	}
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Xattrs = nil
+	header.PAXRecords = nil
+	header.Format = tar.FormatUnknown
+}
+
+var copyBuffers = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 32*1024)
+		return &buf
+	},
+}
+
+// escapes reports whether the symlink file, with the given target, points outside of root
+func escapes(root, file, target string) bool {
+	if filepath.IsAbs(target) {
+		return true
+	}
+	rel, err := filepath.Rel(root, filepath.Join(filepath.Dir(file), target))
+	return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
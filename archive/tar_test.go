@@ -2,11 +2,15 @@ package archive_test
 
 import (
 	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +20,7 @@ import (
 	"github.com/sclevine/spec/report"
 
 	"github.com/buildpack/pack/archive"
+	h "github.com/buildpack/pack/testhelpers"
 )
 
 func TestArchive(t *testing.T) {
@@ -67,6 +72,473 @@ func testArchive(t *testing.T, when spec.G, it spec.S) {
 			verify.nextSymLink("/nested/dir/dir-in-archive/sub-dir/link-file", "../some-file.txt")
 		}
 	})
+
+	when("#CreateTarReader", func() {
+		var appDir, contents string
+
+		it.Before(func() {
+			appDir = filepath.Join(tmpDir, "app")
+			contents = strings.Repeat("some-content\n", 100000)
+			mustWriteFile(t, filepath.Join(appDir, "large.txt"), contents)
+		})
+
+		it("streams the tar as it is read", func() {
+			r, errChan := archive.CreateTarReader(appDir, "/app", 0, 0)
+			defer r.Close()
+
+			verify := tarVerifier{t, tar.NewReader(r), 0, 0}
+			verify.nextDirectory("/app", 0755)
+			verify.nextFile("/app/large.txt", contents)
+			_, err := ioutil.ReadAll(r)
+			h.AssertNil(t, err)
+			h.AssertNil(t, <-errChan)
+		})
+
+		it("stops writing the tar when the reader is closed", func() {
+			r, errChan := archive.CreateTarReader(appDir, "/app", 0, 0)
+			_, err := r.Read(make([]byte, 512))
+			h.AssertNil(t, err)
+			h.AssertNil(t, r.Close())
+			h.AssertError(t, <-errChan, "closed pipe")
+		})
+	})
+
+	when("#RewriteTarReader", func() {
+		var source bytes.Buffer
+
+		it.Before(func() {
+			source.Reset()
+			tw := tar.NewWriter(&source)
+			for _, entry := range []struct {
+				name, contents string
+				typeflag       byte
+			}{
+				{name: "src", typeflag: tar.TypeDir},
+				{name: "src/main.go", contents: "package main", typeflag: tar.TypeReg},
+				{name: "../escaping.txt", contents: "kept", typeflag: tar.TypeReg},
+				{name: "node_modules/dep.js", contents: "dep", typeflag: tar.TypeReg},
+			} {
+				h.AssertNil(t, tw.WriteHeader(&tar.Header{
+					Name:     entry.name,
+					Mode:     0644,
+					Size:     int64(len(entry.contents)),
+					Typeflag: entry.typeflag,
+					Uid:      501,
+					ModTime:  time.Now(),
+				}))
+				_, err := tw.Write([]byte(entry.contents))
+				h.AssertNil(t, err)
+			}
+			h.AssertNil(t, tw.Close())
+		})
+
+		it("writes the entries within the tar dir, owned by the uid and gid", func() {
+			r, errChan := archive.RewriteTarReader(&source, "/workspace", 1234, 2345, archive.WithFilter(func(path string, isDir bool) bool {
+				return !strings.HasPrefix(path, "node_modules")
+			}))
+			defer r.Close()
+
+			verify := tarVerifier{t, tar.NewReader(r), 1234, 2345}
+			verify.nextDirectory("/workspace", 0755)
+			verify.nextDirectory("/workspace/src", 0644)
+			verify.nextFile("/workspace/src/main.go", "package main")
+			verify.nextFile("/workspace/escaping.txt", "kept")
+			_, err := ioutil.ReadAll(r)
+			h.AssertNil(t, err)
+			h.AssertNil(t, <-errChan)
+		})
+
+		it("reads gzip compressed tars", func() {
+			var compressed bytes.Buffer
+			zw := gzip.NewWriter(&compressed)
+			_, err := io.Copy(zw, &source)
+			h.AssertNil(t, err)
+			h.AssertNil(t, zw.Close())
+
+			r, errChan := archive.RewriteTarReader(&compressed, "/workspace", 0, 0)
+			defer r.Close()
+
+			verify := tarVerifier{t, tar.NewReader(r), 0, 0}
+			verify.nextDirectory("/workspace", 0755)
+			verify.nextDirectory("/workspace/src", 0644)
+			verify.nextFile("/workspace/src/main.go", "package main")
+			_, err = ioutil.ReadAll(r)
+			h.AssertNil(t, err)
+			h.AssertNil(t, <-errChan)
+		})
+
+		it("fails on symlinks pointing outside of the tar when rejecting them", func() {
+			source.Reset()
+			tw := tar.NewWriter(&source)
+			h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "src/link", Linkname: "../../etc/passwd", Typeflag: tar.TypeSymlink}))
+			h.AssertNil(t, tw.Close())
+
+			r, errChan := archive.RewriteTarReader(&source, "/workspace", 0, 0, archive.WithSymlinks(archive.RejectEscapingSymlinks))
+			_, err := ioutil.ReadAll(r)
+			h.AssertError(t, err, "outside of the tar")
+			h.AssertError(t, <-errChan, "outside of the tar")
+		})
+	})
+
+	when("#WithNormalization", func() {
+		it("normalizes permissions of files and directories", func() {
+			appDir := filepath.Join(tmpDir, "app")
+			mustWriteFile(t, filepath.Join(appDir, "private", "script.sh"), "#!/bin/sh")
+			mustWriteFile(t, filepath.Join(appDir, "private", "data.txt"), "some-data")
+			h.AssertNil(t, os.Chmod(filepath.Join(appDir, "private", "script.sh"), 0700))
+			h.AssertNil(t, os.Chmod(filepath.Join(appDir, "private", "data.txt"), 0600))
+			h.AssertNil(t, os.Chmod(filepath.Join(appDir, "private"), 0700))
+
+			tr := createTar(t, filepath.Join(tmpDir, "app.tar"), appDir, archive.WithNormalization())
+			verify := tarVerifier{t, tr, 0, 0}
+			verify.nextDirectory("/app", 0755)
+			verify.nextDirectory("/app/private", 0755)
+			verify.nextFileWithMode("/app/private/data.txt", 0644)
+			if runtime.GOOS != "windows" {
+				verify.nextFileWithMode("/app/private/script.sh", 0755)
+			}
+		})
+	})
+
+	when("#WithModTime", func() {
+		it("gives every entry the modification time", func() {
+			modTime := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+			tr := createTar(t, filepath.Join(tmpDir, "app.tar"), src, archive.WithModTime(modTime))
+			for {
+				header, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				h.AssertNil(t, err)
+				h.AssertEq(t, header.ModTime.Equal(modTime), true)
+			}
+		})
+
+		it("writes identical tars of the same sources", func() {
+			modTime := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+			var tars [][]byte
+			for i := 0; i < 2; i++ {
+				r, errChan := archive.CreateTarReader(src, "/app", 0, 0, archive.WithModTime(modTime), archive.WithNormalization())
+				contents, err := ioutil.ReadAll(r)
+				h.AssertNil(t, err)
+				h.AssertNil(t, <-errChan)
+				tars = append(tars, contents)
+			}
+			h.AssertEq(t, bytes.Equal(tars[0], tars[1]), true)
+		})
+	})
+
+	when("#ScanDir", func() {
+		it("counts files and lists the largest", func() {
+			appDir := filepath.Join(tmpDir, "app")
+			mustWriteFile(t, filepath.Join(appDir, "small.txt"), "1")
+			mustWriteFile(t, filepath.Join(appDir, "sub", "large.txt"), "1234")
+			mustWriteFile(t, filepath.Join(appDir, "medium.txt"), "12")
+
+			stats, err := archive.ScanDir(appDir, 2, nil)
+			h.AssertNil(t, err)
+			h.AssertEq(t, stats.Files, 3)
+			h.AssertEq(t, stats.Size, int64(7))
+			h.AssertEq(t, stats.Largest, []archive.FileSize{
+				{Path: filepath.Join("sub", "large.txt"), Size: 4},
+				{Path: "medium.txt", Size: 2},
+			})
+		})
+	})
+
+	when("#WithFilter", func() {
+		it("writes only the accepted files, skipping rejected directories", func() {
+			appDir := filepath.Join(tmpDir, "app")
+			mustWriteFile(t, filepath.Join(appDir, "node_modules", "dep.js"), "dep")
+			mustWriteFile(t, filepath.Join(appDir, "src", "app.js"), "app")
+			mustWriteFile(t, filepath.Join(appDir, "src", "app.log"), "log")
+
+			var seen []string
+			tr := createTar(t, filepath.Join(tmpDir, "some.tar"), appDir, archive.WithFilter(func(path string, isDir bool) bool {
+				seen = append(seen, path)
+				return path != "node_modules" && !strings.HasSuffix(path, ".log")
+			}))
+			verify := tarVerifier{t, tr, 0, 0}
+			verify.nextDirectory("/app", 0755)
+			verify.nextDirectory("/app/src", 0755)
+			verify.nextFile("/app/src/app.js", "app")
+			_, err := tr.Next()
+			h.AssertEq(t, err == io.EOF, true)
+			h.AssertEq(t, seen, []string{"node_modules", "src", "src/app.js", "src/app.log"})
+		})
+	})
+
+	when("#WithWindowsLayerFormat", func() {
+		it("writes the files under the Files directory of a windows layer", func() {
+			tr := createTar(t, filepath.Join(tmpDir, "some.tar"), src, archive.WithWindowsLayerFormat())
+			verify := tarVerifier{t, tr, 0, 0}
+			verify.nextDirectory("Files", 0755)
+			verify.nextDirectory("Hives", 0755)
+			verify.nextDirectory("Files/app", 0755)
+			verify.nextFile("Files/app/some-file.txt", "some-content")
+		})
+	})
+
+	when("#WithParallelGzip", func() {
+		it("compresses directories above the threshold", func() {
+			appDir := filepath.Join(tmpDir, "app")
+			contents := strings.Repeat("some-content\n", 200000)
+			mustWriteFile(t, filepath.Join(appDir, "large.txt"), contents)
+			mustWriteFile(t, filepath.Join(appDir, "small.txt"), "small")
+
+			tarFile := filepath.Join(tmpDir, "app.tgz")
+			h.AssertNil(t, archive.CreateTar(tarFile, appDir, "/app", 0, 0, archive.WithParallelGzip(1024)))
+			f, err := os.Open(tarFile)
+			h.AssertNil(t, err)
+			defer f.Close()
+
+			extractDir := filepath.Join(tmpDir, "extracted")
+			h.AssertNil(t, archive.ExtractTarGZ(f, extractDir))
+			h.AssertDirContainsFileWithContents(t, filepath.Join(extractDir, "app"), "large.txt", contents)
+			h.AssertDirContainsFileWithContents(t, filepath.Join(extractDir, "app"), "small.txt", "small")
+		})
+
+		it("decompresses with compress/gzip to the same tar as without compression", func() {
+			appDir := filepath.Join(tmpDir, "app")
+			// random contents spanning several compressed blocks, which are not a multiple of the block size
+			contents := make([]byte, 3<<20+123)
+			_, err := rand.Read(contents)
+			h.AssertNil(t, err)
+			mustWriteFile(t, filepath.Join(appDir, "random.bin"), string(contents))
+			mustWriteFile(t, filepath.Join(appDir, "text.txt"), strings.Repeat("some-content\n", 100000))
+
+			plainFile := filepath.Join(tmpDir, "app.tar")
+			h.AssertNil(t, archive.CreateTar(plainFile, appDir, "/app", 0, 0))
+			gzipFile := filepath.Join(tmpDir, "app.tgz")
+			h.AssertNil(t, archive.CreateTar(gzipFile, appDir, "/app", 0, 0, archive.WithParallelGzip(1)))
+
+			plain, err := ioutil.ReadFile(plainFile)
+			h.AssertNil(t, err)
+			f, err := os.Open(gzipFile)
+			h.AssertNil(t, err)
+			defer f.Close()
+			zr, err := gzip.NewReader(f)
+			h.AssertNil(t, err)
+			decompressed, err := ioutil.ReadAll(zr)
+			h.AssertNil(t, err)
+			h.AssertNil(t, zr.Close())
+			if !bytes.Equal(decompressed, plain) {
+				t.Fatalf("decompressed tar of %d bytes differs from the tar of %d bytes written without compression", len(decompressed), len(plain))
+			}
+		})
+
+		it("does not compress directories below the threshold", func() {
+			tr := createTar(t, filepath.Join(tmpDir, "some.tar"), src, archive.WithParallelGzip(1024*1024))
+			verify := tarVerifier{t, tr, 0, 0}
+			verify.nextDirectory("/app", 0755)
+		})
+	})
+
+	when("#ExtractTar", func() {
+		it("fails on entries outside of the destination dir", func() {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "../escaped.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4}))
+			_, err := tw.Write([]byte("evil"))
+			h.AssertNil(t, err)
+			h.AssertNil(t, tw.Close())
+
+			extractDir := filepath.Join(tmpDir, "extracted")
+			err = archive.ExtractTar(&buf, extractDir)
+			h.AssertError(t, err, "tar entry ../escaped.txt is outside of the destination directory")
+			_, err = os.Stat(filepath.Join(tmpDir, "escaped.txt"))
+			h.AssertEq(t, os.IsNotExist(err), true)
+		})
+
+		when("the tar contains symlinks", func() {
+			var extractDir string
+
+			it.Before(func() {
+				if runtime.GOOS == "windows" {
+					t.Skip("symlinks require elevated privileges on windows")
+				}
+				extractDir = filepath.Join(tmpDir, "extracted")
+				h.AssertNil(t, os.MkdirAll(filepath.Join(tmpDir, "outside"), 0755))
+			})
+
+			extract := func(headers ...*tar.Header) error {
+				var buf bytes.Buffer
+				tw := tar.NewWriter(&buf)
+				for _, hdr := range headers {
+					h.AssertNil(t, tw.WriteHeader(hdr))
+					if hdr.Size > 0 {
+						_, err := tw.Write([]byte("evil"))
+						h.AssertNil(t, err)
+					}
+				}
+				h.AssertNil(t, tw.Close())
+				return archive.ExtractTar(&buf, extractDir)
+			}
+
+			it("extracts symlinks within the destination dir", func() {
+				h.AssertNil(t, extract(
+					&tar.Header{Name: "dir/file.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+					&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir/file.txt"},
+				))
+				target, err := os.Readlink(filepath.Join(extractDir, "link"))
+				h.AssertNil(t, err)
+				h.AssertEq(t, target, "dir/file.txt")
+			})
+
+			it("fails on absolute symlinks outside of the destination dir", func() {
+				err := extract(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: filepath.Join(tmpDir, "outside")})
+				h.AssertError(t, err, "tar entry link links to")
+				h.AssertError(t, err, "outside of the destination directory")
+			})
+
+			it("fails on relative symlinks outside of the destination dir", func() {
+				err := extract(&tar.Header{Name: "dir/link", Typeflag: tar.TypeSymlink, Linkname: "../../outside"})
+				h.AssertError(t, err, "tar entry dir/link links to ../../outside outside of the destination directory")
+			})
+
+			it("fails on entries written through a symlink", func() {
+				err := extract(
+					&tar.Header{Name: "dir", Typeflag: tar.TypeDir, Mode: 0755},
+					&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir"},
+					&tar.Header{Name: "link/file.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+				)
+				h.AssertError(t, err, "tar entry link/file.txt is written through a symlink")
+				_, err = os.Stat(filepath.Join(extractDir, "dir", "file.txt"))
+				h.AssertEq(t, os.IsNotExist(err), true)
+			})
+
+			it("fails on files replacing a symlink", func() {
+				err := extract(
+					&tar.Header{Name: "dir/file.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+					&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir/file.txt"},
+					&tar.Header{Name: "link", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+				)
+				h.AssertError(t, err, "tar entry link is written through a symlink")
+			})
+		})
+	})
+
+	when("the source dir contains hard links", func() {
+		it("writes further links to a file as hard link entries", func() {
+			if runtime.GOOS == "windows" {
+				t.Skip("hard links are written as regular files on windows")
+			}
+			appDir := filepath.Join(tmpDir, "app")
+			mustWriteFile(t, filepath.Join(appDir, "a.txt"), "content")
+			if err := os.Link(filepath.Join(appDir, "a.txt"), filepath.Join(appDir, "b.txt")); err != nil {
+				t.Fatal(err)
+			}
+
+			tr := createTar(t, filepath.Join(tmpDir, "app.tar"), appDir)
+			verify := tarVerifier{t, tr, 0, 0}
+			verify.nextDirectory("/app", 0755)
+			verify.nextFile("/app/a.txt", "content")
+			header, err := tr.Next()
+			h.AssertNil(t, err)
+			h.AssertEq(t, header.Name, "/app/b.txt")
+			h.AssertEq(t, header.Typeflag, byte(tar.TypeLink))
+			h.AssertEq(t, header.Linkname, "/app/a.txt")
+			h.AssertEq(t, header.Size, int64(0))
+
+			extractDir := filepath.Join(tmpDir, "extracted")
+			f, err := os.Open(filepath.Join(tmpDir, "app.tar"))
+			h.AssertNil(t, err)
+			defer f.Close()
+			h.AssertNil(t, archive.ExtractTar(f, extractDir))
+			contents, err := ioutil.ReadFile(filepath.Join(extractDir, "app", "b.txt"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(contents), "content")
+		})
+	})
+
+	when("the source dir contains symlinks", func() {
+		var appDir string
+
+		it.Before(func() {
+			if runtime.GOOS == "windows" {
+				t.Skip("symlinks require elevated privileges on windows")
+			}
+			appDir = filepath.Join(tmpDir, "app")
+			mustWriteFile(t, filepath.Join(tmpDir, "outside.txt"), "outside")
+			mustWriteFile(t, filepath.Join(appDir, "dir", "inner.txt"), "inner")
+			mustWriteFile(t, filepath.Join(appDir, "file.txt"), "content")
+			mustSymlink(t, "dir", filepath.Join(appDir, "link-to-dir"))
+			mustSymlink(t, "../outside.txt", filepath.Join(appDir, "link-out"))
+		})
+
+		it("preserves symlinks by default", func() {
+			tr := createTar(t, filepath.Join(tmpDir, "app.tar"), appDir)
+
+			verify := tarVerifier{t, tr, 0, 0}
+			verify.nextDirectory("/app", 0755)
+			verify.nextDirectory("/app/dir", fileMode(t, filepath.Join(appDir, "dir")))
+			verify.nextFile("/app/dir/inner.txt", "inner")
+			verify.nextFile("/app/file.txt", "content")
+			verify.nextSymLink("/app/link-out", "../outside.txt")
+			verify.nextSymLink("/app/link-to-dir", "dir")
+		})
+
+		it("writes the targets of symlinks when following them", func() {
+			tr := createTar(t, filepath.Join(tmpDir, "app.tar"), appDir, archive.WithSymlinks(archive.FollowSymlinks))
+
+			verify := tarVerifier{t, tr, 0, 0}
+			verify.nextDirectory("/app", 0755)
+			verify.nextDirectory("/app/dir", fileMode(t, filepath.Join(appDir, "dir")))
+			verify.nextFile("/app/dir/inner.txt", "inner")
+			verify.nextFile("/app/file.txt", "content")
+			verify.nextFile("/app/link-out", "outside")
+			verify.nextDirectory("/app/link-to-dir", fileMode(t, filepath.Join(appDir, "dir")))
+			verify.nextFile("/app/link-to-dir/inner.txt", "inner")
+		})
+
+		it("fails on symlink cycles when following them", func() {
+			mustSymlink(t, "..", filepath.Join(appDir, "dir", "loop"))
+
+			err := archive.CreateTar(filepath.Join(tmpDir, "app.tar"), appDir, "/app", 0, 0, archive.WithSymlinks(archive.FollowSymlinks))
+			h.AssertError(t, err, "cycle through")
+		})
+
+		it("fails on symlinks pointing outside of the source dir when rejecting them", func() {
+			err := archive.CreateTar(filepath.Join(tmpDir, "app.tar"), appDir, "/app", 0, 0, archive.WithSymlinks(archive.RejectEscapingSymlinks))
+			h.AssertError(t, err, "points to ../outside.txt outside of")
+		})
+
+		it("returns tar errors through the reader", func() {
+			r, errChan := archive.CreateTarReader(appDir, "/app", 0, 0, archive.WithSymlinks(archive.RejectEscapingSymlinks))
+			_, err := ioutil.ReadAll(r)
+			h.AssertError(t, err, "outside of")
+			h.AssertError(t, <-errChan, "outside of")
+		})
+	})
+}
+
+func createTar(t *testing.T, tarFile, srcDir string, ops ...func(*archive.TarOptions)) *tar.Reader {
+	t.Helper()
+	if err := archive.CreateTar(tarFile, srcDir, "/app", 0, 0, ops...); err != nil {
+		t.Fatalf("CreateTar failed: %s", err)
+	}
+	b, err := ioutil.ReadFile(tarFile)
+	if err != nil {
+		t.Fatalf("could not read tar file %s: %s", tarFile, err)
+	}
+	return tar.NewReader(bytes.NewReader(b))
+}
+
+func mustWriteFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustSymlink(t *testing.T, target, path string) {
+	t.Helper()
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func fileMode(t *testing.T, path string) int64 {
@@ -131,7 +603,7 @@ func (v *tarVerifier) nextFile(name, expectedFileContents string) {
 	}
 
 	fileContents := make([]byte, header.Size, header.Size)
-	v.tr.Read(fileContents)
+	io.ReadFull(v.tr, fileContents)
 	if string(fileContents) != expectedFileContents {
 		v.t.Fatalf(`expected to some-file.txt to have %s got %s`, expectedFileContents, string(fileContents))
 	}
@@ -141,6 +613,26 @@ func (v *tarVerifier) nextFile(name, expectedFileContents string) {
 	}
 }
 
+func (v *tarVerifier) nextFileWithMode(name string, mode int64) {
+	header, err := v.tr.Next()
+	if err != nil {
+		v.t.Fatalf("Failed to get next file: %s", err)
+	}
+
+	if header.Name != name {
+		v.t.Fatalf(`expected file with name %s, got %s`, name, header.Name)
+	}
+	if header.Mode != mode {
+		v.t.Fatalf(`expected %s to have mode %o, got: %o`, header.Name, mode, header.Mode)
+	}
+	if header.Uname != "" || header.Gname != "" {
+		v.t.Fatalf(`expected %s to have no user or group name, got: %s:%s`, header.Name, header.Uname, header.Gname)
+	}
+	if !header.ModTime.Equal(archive.NormalizedDateTime) {
+		v.t.Fatalf(`expected %s to have been normalized, got: %s`, header.Name, header.ModTime.String())
+	}
+}
+
 func (v *tarVerifier) nextSymLink(name, link string) {
 	header, err := v.tr.Next()
 	if err != nil {
@@ -160,7 +652,7 @@ func (v *tarVerifier) nextSymLink(name, link string) {
 		v.t.Fatalf(`expected %s to have gid %d but, got: %d`, header.Name, v.gid, header.Gid)
 	}
 
-	if header.Linkname != "../some-file.txt" {
+	if header.Linkname != link {
 		v.t.Fatalf(`expected to link-file to have target %s got: %s`, link, header.Linkname)
 	}
 	if !header.ModTime.Equal(time.Date(1980, time.January, 1, 0, 0, 1, 0, time.UTC)) {
@@ -0,0 +1,233 @@
+package pack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buildpack/lifecycle/image"
+
+	"github.com/buildpack/pack/style"
+)
+
+// AppBuilder builds app images. *Client implements it.
+type AppBuilder interface {
+	Build(ctx context.Context, flags BuildFlags, ops ...func(*BuildFactory)) (*BuildResult, error)
+}
+
+// BatchBuild is one of the builds run by a Batch
+type BatchBuild struct {
+	Flags BuildFlags
+	// Ops apply to this build only, such as WithBuildLogger to keep its output apart
+	Ops []func(*BuildFactory)
+}
+
+// BatchResult is the outcome of a build run by a Batch
+type BatchResult struct {
+	RepoName string        `json:"image"`
+	AppDir   string        `json:"appDir"`
+	Error    string        `json:"error,omitempty"`
+	Err      error         `json:"-"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	// Result is the image exported by a successful build
+	Result *BuildResult `json:"result,omitempty"`
+}
+
+// BatchResults are the outcomes of the builds of a Batch, in the order the builds were given
+type BatchResults []BatchResult
+
+// Err returns an error naming the builds that failed, if any did
+func (r BatchResults) Err() error {
+	var failed []string
+	for _, result := range r {
+		if result.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", style.Symbol(result.RepoName), result.Err))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d builds failed:\n%s", len(failed), len(r), strings.Join(failed, "\n"))
+}
+
+// Batch runs many builds concurrently, up to a limit. Builds of the same image, which share a
+// cache, run one after another in the order they were given. When a fetcher is shared, builds
+// using the same builder or run image pull it once.
+type Batch struct {
+	builder     AppBuilder
+	fetcher     *sharedFetcher
+	parallelism int
+	failFast    bool
+}
+
+// WithParallelism limits the number of builds running at once. Defaults to 4.
+func WithParallelism(n int) func(*Batch) {
+	return func(b *Batch) {
+		b.parallelism = n
+	}
+}
+
+// WithSharedFetcher pulls each builder and run image once for all builds, with fetcher, and
+// uses the pulled image in every other build
+func WithSharedFetcher(fetcher Fetcher) func(*Batch) {
+	return func(b *Batch) {
+		b.fetcher = &sharedFetcher{Fetcher: fetcher, pulls: map[string]*pull{}}
+	}
+}
+
+// WithFailFast cancels the remaining builds once one fails
+func WithFailFast() func(*Batch) {
+	return func(b *Batch) {
+		b.failFast = true
+	}
+}
+
+func NewBatch(builder AppBuilder, ops ...func(*Batch)) *Batch {
+	b := &Batch{
+		builder:     builder,
+		parallelism: 4,
+	}
+	for _, op := range ops {
+		op(b)
+	}
+	if b.parallelism < 1 {
+		b.parallelism = 1
+	}
+	return b
+}
+
+// BuildAll runs builds as a Batch, sharing the image pulls of the client between them
+func (c *Client) BuildAll(ctx context.Context, builds []BatchBuild, ops ...func(*Batch)) BatchResults {
+	return NewBatch(c, append([]func(*Batch){WithSharedFetcher(c.fetcher)}, ops...)...).Run(ctx, builds)
+}
+
+// Run runs builds until all have finished
+func (b *Batch) Run(ctx context.Context, builds []BatchBuild) BatchResults {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		slots   = make(chan struct{}, b.parallelism)
+		results = make(BatchResults, len(builds))
+		// last holds, per image, a channel closed once the latest build of the image so far finishes
+		last = map[string]chan struct{}{}
+	)
+	for i, build := range builds {
+		repoName := build.Flags.RepoName
+		if source, err := appSource(build.Flags.AppDir); err == nil {
+			// an invalid project descriptor fails the build itself
+			project, _ := ReadProjectDescriptor(source)
+			repoName = calculateRepositoryName(source, &build.Flags, project)
+		}
+		results[i] = BatchResult{RepoName: repoName, AppDir: build.Flags.AppDir}
+		prev, done := last[repoName], make(chan struct{})
+		last[repoName] = done
+
+		wg.Add(1)
+		go func(result *BatchResult, build BatchBuild, prev, done chan struct{}) {
+			defer wg.Done()
+			defer close(done)
+
+			result.Err = b.run(ctx, build, prev, slots, result)
+			if result.Err != nil {
+				result.Error = result.Err.Error()
+				if b.failFast {
+					cancel()
+				}
+			}
+		}(&results[i], build, prev, done)
+	}
+	wg.Wait()
+	return results
+}
+
+func (b *Batch) run(ctx context.Context, build BatchBuild, prev, slots chan struct{}, result *BatchResult) error {
+	if prev != nil {
+		select {
+		case <-prev:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	select {
+	case slots <- struct{}{}:
+		defer func() { <-slots }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	ops := build.Ops
+	if b.fetcher != nil {
+		ops = append([]func(*BuildFactory){withFetcher(b.fetcher)}, ops...)
+	}
+	result.Started = time.Now()
+	defer func() { result.Duration = time.Since(result.Started) }()
+	var err error
+	result.Result, err = b.builder.Build(ctx, build.Flags, ops...)
+	return err
+}
+
+func withFetcher(fetcher Fetcher) func(*BuildFactory) {
+	return func(bf *BuildFactory) {
+		bf.Fetcher = fetcher
+	}
+}
+
+// sharedFetcher pulls each image once, returning the local image to later callers
+type sharedFetcher struct {
+	Fetcher
+	mu    sync.Mutex
+	pulls map[string]*pull
+}
+
+type pull struct {
+	done chan struct{}
+	err  error
+}
+
+func (f *sharedFetcher) FetchUpdatedLocalImage(ctx context.Context, name string, stdout io.Writer, ops ...func(*FetchOptions)) (image.Image, error) {
+	var opts FetchOptions
+	for _, op := range ops {
+		op(&opts)
+	}
+	key := name + "|" + opts.platform
+
+	for {
+		f.mu.Lock()
+		p, ok := f.pulls[key]
+		if !ok {
+			p = &pull{done: make(chan struct{})}
+			f.pulls[key] = p
+		}
+		f.mu.Unlock()
+
+		if !ok {
+			img, err := f.Fetcher.FetchUpdatedLocalImage(ctx, name, stdout, ops...)
+			if err != nil {
+				// a failed pull, possibly of a canceled build, is retried by the builds waiting on it
+				f.mu.Lock()
+				delete(f.pulls, key)
+				f.mu.Unlock()
+				p.err = err
+			}
+			close(p.done)
+			return img, err
+		}
+
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if p.err == nil {
+			return f.Fetcher.FetchLocalImage(name)
+		}
+	}
+}
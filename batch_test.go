@@ -0,0 +1,166 @@
+package pack_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/buildpack/lifecycle/image"
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBatch(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Batch", testBatch, spec.Report(report.Terminal{}))
+}
+
+// fakeAppBuilder pulls the builder of each build, then builds for a moment, failing the builds
+// of app dirs in errs
+type fakeAppBuilder struct {
+	mu      sync.Mutex
+	running map[string]bool
+	maxRun  int
+	order   []string
+	errs    map[string]error
+}
+
+func (f *fakeAppBuilder) Build(ctx context.Context, flags pack.BuildFlags, ops ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+	bf := &pack.BuildFactory{}
+	for _, op := range ops {
+		op(bf)
+	}
+	if bf.Fetcher != nil {
+		if _, err := bf.Fetcher.FetchUpdatedLocalImage(ctx, flags.Builder, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	f.mu.Lock()
+	if f.running[flags.RepoName] {
+		f.mu.Unlock()
+		return nil, errors.New("image is already being built")
+	}
+	f.running[flags.RepoName] = true
+	f.order = append(f.order, flags.AppDir)
+	if len(f.running) > f.maxRun {
+		f.maxRun = len(f.running)
+	}
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		delete(f.running, flags.RepoName)
+		f.mu.Unlock()
+	}()
+	select {
+	case <-time.After(20 * time.Millisecond):
+		if err := f.errs[flags.AppDir]; err != nil {
+			return nil, err
+		}
+		return &pack.BuildResult{Image: flags.RepoName}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// countingFetcher counts the images it pulls
+type countingFetcher struct {
+	mu     sync.Mutex
+	pulled map[string]int
+}
+
+func (f *countingFetcher) FetchUpdatedLocalImage(ctx context.Context, name string, stdout io.Writer, ops ...func(*pack.FetchOptions)) (image.Image, error) {
+	time.Sleep(10 * time.Millisecond)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pulled[name]++
+	return nil, nil
+}
+
+func (f *countingFetcher) FetchLocalImage(name string) (image.Image, error) {
+	return nil, nil
+}
+
+func (f *countingFetcher) FetchRemoteImage(name string) (image.Image, error) {
+	return nil, nil
+}
+
+func testBatch(t *testing.T, when spec.G, it spec.S) {
+	var (
+		builder *fakeAppBuilder
+		fetcher *countingFetcher
+	)
+
+	it.Before(func() {
+		builder = &fakeAppBuilder{running: map[string]bool{}, errs: map[string]error{}}
+		fetcher = &countingFetcher{pulled: map[string]int{}}
+	})
+
+	build := func(repoName, appDir string) pack.BatchBuild {
+		return pack.BatchBuild{Flags: pack.BuildFlags{RepoName: repoName, AppDir: appDir, Builder: "some/builder"}}
+	}
+
+	it("runs builds concurrently up to the parallelism", func() {
+		results := pack.NewBatch(builder, pack.WithParallelism(2)).Run(context.Background(), []pack.BatchBuild{
+			build("some/app", "/some/app"),
+			build("other/app", "/other/app"),
+			build("third/app", "/third/app"),
+			build("fourth/app", "/fourth/app"),
+		})
+		h.AssertNil(t, results.Err())
+		h.AssertEq(t, len(results), 4)
+		h.AssertEq(t, results[2].RepoName, "third/app")
+		h.AssertEq(t, builder.maxRun <= 2, true)
+	})
+
+	it("builds one image at a time, in order", func() {
+		results := pack.NewBatch(builder, pack.WithParallelism(3)).Run(context.Background(), []pack.BatchBuild{
+			build("some/app", "/first"),
+			build("some/app", "/second"),
+			build("some/app", "/third"),
+		})
+		h.AssertNil(t, results.Err())
+		h.AssertEq(t, builder.order, []string{"/first", "/second", "/third"})
+	})
+
+	it("pulls shared images once", func() {
+		results := pack.NewBatch(builder, pack.WithSharedFetcher(fetcher)).Run(context.Background(), []pack.BatchBuild{
+			build("some/app", "/some/app"),
+			build("other/app", "/other/app"),
+			build("third/app", "/third/app"),
+		})
+		h.AssertNil(t, results.Err())
+		h.AssertEq(t, fetcher.pulled, map[string]int{"some/builder": 1})
+	})
+
+	it("reports the builds that failed", func() {
+		builder.errs["/some/app"] = errors.New("some-error")
+		results := pack.NewBatch(builder).Run(context.Background(), []pack.BatchBuild{
+			build("some/app", "/some/app"),
+			build("other/app", "/other/app"),
+		})
+		h.AssertEq(t, results[0].Error, "some-error")
+		h.AssertNil(t, results[0].Result)
+		h.AssertNil(t, results[1].Err)
+		h.AssertEq(t, results[1].Result.Image, "other/app")
+		h.AssertError(t, results.Err(), "1 of 2 builds failed:\n'some/app': some-error")
+	})
+
+	it("cancels the remaining builds after a failure when failing fast", func() {
+		builder.errs["/first"] = errors.New("some-error")
+		results := pack.NewBatch(builder, pack.WithFailFast()).Run(context.Background(), []pack.BatchBuild{
+			build("some/app", "/first"),
+			build("some/app", "/second"),
+		})
+		h.AssertEq(t, results[0].Error, "some-error")
+		h.AssertError(t, results[1].Err, "context canceled")
+	})
+}
@@ -0,0 +1,155 @@
+package pack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/buildpack/lifecycle"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+const (
+	// BOMCycloneDX writes the bill-of-materials of the app image as a CycloneDX JSON document
+	BOMCycloneDX = "cyclonedx"
+	// BOMSPDX writes the bill-of-materials of the app image as an SPDX JSON document
+	BOMSPDX = "spdx"
+)
+
+// BOM is the bill-of-materials of an app image: the entries of the build plan that its buildpacks
+// provided, such as the runtimes and libraries they installed
+type BOM struct {
+	// Buildpacks are the IDs of the buildpacks that built the app, in order
+	Buildpacks []string   `json:"buildpacks,omitempty"`
+	Entries    []BOMEntry `json:"entries,omitempty"`
+}
+
+// BOMEntry is a dependency of the app, with the metadata its buildpack recorded
+type BOMEntry struct {
+	Name string `json:"name"`
+	// Version is the 'version' of the metadata, when there is one
+	Version  string                 `json:"version,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+func newBOM(metadata *lifecycle.BuildMetadata) *BOM {
+	bom := &BOM{Buildpacks: metadata.Buildpacks}
+	for name, entry := range metadata.BOM {
+		version, _ := entry["version"].(string)
+		bom.Entries = append(bom.Entries, BOMEntry{Name: name, Version: version, Metadata: entry})
+	}
+	sort.Slice(bom.Entries, func(i, j int) bool {
+		return bom.Entries[i].Name < bom.Entries[j].Name
+	})
+	return bom
+}
+
+func checkBOM(f *BuildFlags) error {
+	switch f.BOMFormat {
+	case "":
+		if f.BOMPath != "" {
+			return errors.Errorf("a bill-of-materials format of %s or %s is required to write it", style.Symbol(BOMCycloneDX), style.Symbol(BOMSPDX))
+		}
+		return nil
+	case BOMCycloneDX, BOMSPDX:
+	default:
+		return errors.Errorf("unknown bill-of-materials format %s, expected %s or %s", style.Symbol(f.BOMFormat), style.Symbol(BOMCycloneDX), style.Symbol(BOMSPDX))
+	}
+	if f.BOMPath == "" {
+		return errors.Errorf("a path is required to write the bill-of-materials as %s", style.Symbol(f.BOMFormat))
+	}
+	return nil
+}
+
+// writeBOM writes the bill-of-materials of the result to BOMPath in BOMFormat
+func (b *BuildConfig) writeBOM(result *BuildResult) error {
+	b.Logger.Verbose("Writing bill-of-materials to %s", style.Symbol(b.BOMPath))
+	f, err := os.Create(b.BOMPath)
+	if err != nil {
+		return err
+	}
+	if err := result.WriteBOM(f, b.BOMFormat); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "writing bill-of-materials %s", style.Symbol(b.BOMPath))
+	}
+	return f.Close()
+}
+
+// WriteBOM writes the bill-of-materials of the image as a JSON document in format, either
+// BOMCycloneDX or BOMSPDX, describing the image by its Reference
+func (r *BuildResult) WriteBOM(w io.Writer, format string) error {
+	var doc interface{}
+	switch format {
+	case BOMCycloneDX:
+		doc = cycloneDXDocument(r)
+	case BOMSPDX:
+		doc = spdxDocument(r, time.Now())
+	default:
+		return errors.Errorf("unknown bill-of-materials format %s, expected %s or %s", style.Symbol(format), style.Symbol(BOMCycloneDX), style.Symbol(BOMSPDX))
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+func cycloneDXDocument(result *BuildResult) map[string]interface{} {
+	components := []map[string]interface{}{}
+	if result.BOM != nil {
+		for _, entry := range result.BOM.Entries {
+			component := map[string]interface{}{"type": "library", "name": entry.Name}
+			if entry.Version != "" {
+				component["version"] = entry.Version
+			}
+			components = append(components, component)
+		}
+	}
+	return map[string]interface{}{
+		"bomFormat":   "CycloneDX",
+		"specVersion": "1.2",
+		"version":     1,
+		"metadata": map[string]interface{}{
+			"tools":     []map[string]string{{"vendor": "Cloud Native Buildpacks", "name": "pack"}},
+			"component": map[string]string{"type": "container", "name": result.Reference()},
+		},
+		"components": components,
+	}
+}
+
+func spdxDocument(result *BuildResult, created time.Time) map[string]interface{} {
+	packages := []map[string]interface{}{}
+	if result.BOM != nil {
+		for i, entry := range result.BOM.Entries {
+			pkg := map[string]interface{}{
+				"SPDXID":           fmt.Sprintf("SPDXRef-Package-%d", i+1),
+				"name":             entry.Name,
+				"downloadLocation": "NOASSERTION",
+				"licenseConcluded": "NOASSERTION",
+				"licenseDeclared":  "NOASSERTION",
+				"copyrightText":    "NOASSERTION",
+				"filesAnalyzed":    false,
+			}
+			if entry.Version != "" {
+				pkg["versionInfo"] = entry.Version
+			}
+			packages = append(packages, pkg)
+		}
+	}
+	return map[string]interface{}{
+		"spdxVersion":       "SPDX-2.2",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              result.Reference(),
+		"documentNamespace": fmt.Sprintf("https://buildpacks.io/spdx/%s-%d", url.PathEscape(result.Reference()), created.Unix()),
+		"creationInfo": map[string]interface{}{
+			"created":  created.UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: pack"},
+		},
+		"packages": packages,
+	}
+}
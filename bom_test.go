@@ -0,0 +1,83 @@
+package pack_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBOM(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "BOM", testBOM, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBOM(t *testing.T, when spec.G, it spec.S) {
+	when("#WriteBOM", func() {
+		var result *pack.BuildResult
+
+		it.Before(func() {
+			result = &pack.BuildResult{
+				Image:  "some/app",
+				Digest: "sha256:some-digest",
+				BOM: &pack.BOM{
+					Buildpacks: []string{"some.bp"},
+					Entries: []pack.BOMEntry{
+						{Name: "node", Version: "10.15.3", Metadata: map[string]interface{}{"version": "10.15.3"}},
+						{Name: "npm"},
+					},
+				},
+			}
+		})
+
+		decode := func(format string) map[string]interface{} {
+			t.Helper()
+			buf := &bytes.Buffer{}
+			h.AssertNil(t, result.WriteBOM(buf, format))
+			var doc map[string]interface{}
+			h.AssertNil(t, json.Unmarshal(buf.Bytes(), &doc))
+			return doc
+		}
+
+		it("writes a CycloneDX document with the entries as components", func() {
+			doc := decode(pack.BOMCycloneDX)
+			h.AssertEq(t, doc["bomFormat"], "CycloneDX")
+			h.AssertEq(t, doc["metadata"].(map[string]interface{})["component"], map[string]interface{}{
+				"type": "container",
+				"name": "index.docker.io/some/app@sha256:some-digest",
+			})
+			h.AssertEq(t, doc["components"], []interface{}{
+				map[string]interface{}{"type": "library", "name": "node", "version": "10.15.3"},
+				map[string]interface{}{"type": "library", "name": "npm"},
+			})
+		})
+
+		it("writes an SPDX document with the entries as packages", func() {
+			doc := decode(pack.BOMSPDX)
+			h.AssertEq(t, doc["spdxVersion"], "SPDX-2.2")
+			h.AssertEq(t, doc["name"], "index.docker.io/some/app@sha256:some-digest")
+			packages := doc["packages"].([]interface{})
+			h.AssertEq(t, len(packages), 2)
+			h.AssertEq(t, packages[0].(map[string]interface{})["SPDXID"], "SPDXRef-Package-1")
+			h.AssertEq(t, packages[0].(map[string]interface{})["name"], "node")
+			h.AssertEq(t, packages[0].(map[string]interface{})["versionInfo"], "10.15.3")
+			h.AssertEq(t, packages[1].(map[string]interface{})["name"], "npm")
+		})
+
+		it("writes documents without components when the builder did not record the bill-of-materials", func() {
+			result.BOM = nil
+			doc := decode(pack.BOMCycloneDX)
+			h.AssertEq(t, doc["components"], []interface{}{})
+		})
+
+		it("fails for unknown formats", func() {
+			h.AssertError(t, result.WriteBOM(&bytes.Buffer{}, "some-format"), "unknown bill-of-materials format 'some-format'")
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package pack
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"fmt"
@@ -8,45 +9,135 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/buildpack/pack/archive"
 	"github.com/buildpack/pack/build"
 	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/buildpack"
 	"github.com/buildpack/pack/cache"
 	"github.com/buildpack/pack/config"
-	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/descriptor"
+	"github.com/buildpack/pack/git"
 	"github.com/buildpack/pack/logging"
 	"github.com/buildpack/pack/style"
 
 	lcimg "github.com/buildpack/lifecycle/image"
+	"github.com/docker/docker/client"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 )
 
 //go:generate mockgen -package mocks -destination mocks/cache.go github.com/buildpack/pack Cache
 type Cache interface {
 	Clear(context.Context) error
+	// Dir is the volume or host directory the cache is kept in, or empty when it is kept in Image
+	Dir() string
 	Image() string
+	// Remote is true for caches kept at a registry rather than in the docker daemon
+	Remote() bool
+	// Exists reports whether there is a cache, without reading its size
+	Exists(ctx context.Context) (bool, error)
+	// Size returns the size of the cache, zero when there is none or -1 when it is unknown
+	Size(ctx context.Context) (int64, error)
+	// Prune clears the cache when it was last written more than olderThan ago
+	Prune(ctx context.Context, olderThan time.Duration) error
+	// Lock waits until no other build in this process uses the cache, returning the function that
+	// releases it. It returns at once when the cache is free, even when ctx is done.
+	Lock(ctx context.Context) (unlock func(), err error)
 }
 
+// DefaultLifecycleImageRepo is the repository of the lifecycle images that run the phases of builds with
+// untrusted builders that access the docker daemon or registries. Its tags are lifecycle versions.
+const DefaultLifecycleImageRepo = "buildpacksio/lifecycle"
+
 type BuildFactory struct {
 	Cli     Docker
-	Logger  *logging.Logger
+	Logger  Logger
 	Config  *config.Config
 	Cache   Cache
 	Fetcher Fetcher
+	OnEvent EventHandler
+	// PhaseHook, when set, is notified of the phases of builds, see BuildConfig
+	PhaseHook PhaseHook
+	// BuildpackFetcher downloads and extracts buildpacks given as .tgz archives or URLs. Defaults to
+	// a fetcher caching downloads in the pack home directory.
+	BuildpackFetcher BuildpackFetcher
+	// TrustPrompt, when set, is asked whether to trust builders that are not trusted, see WithTrustPrompt
+	TrustPrompt TrustPrompt
+	// logSettings are those given by options such as WithVerbose, which create Logger
+	logSettings logSettings
 }
 
+// AppDirStdin is the app dir of builds reading the app from stdin, as a tar that may be gzip
+// compressed, such as one piped from 'git archive'. The image must be named by the build flags.
+const AppDirStdin = "-"
+
 type BuildFlags struct {
-	AppDir     string
-	Builder    string
-	RunImage   string
-	Env        []string
-	EnvFile    string
-	RepoName   string
-	Publish    bool
-	NoPull     bool
-	ClearCache bool
-	Buildpacks []string
+	AppDir      string
+	Builder     string
+	RunImage    string
+	Env         []string
+	EnvFile     string
+	RepoName    string
+	Publish     bool
+	ClearCache  bool
+	Buildpacks  []string
+	AppSymlinks archive.SymlinkMode
+	AppLimits   build.AppLimits
+	// PullPolicy determines when the builder, run and lifecycle images are pulled, PullAlways by default
+	PullPolicy PullPolicy
+	// Include and Exclude select the files copied from the app dir, see build.AppFilter
+	Include []string
+	Exclude []string
+	// NoCache skips the restore and cache phases, see BuildConfig
+	NoCache bool
+	// DefaultProcess is the process type the app image runs by default, see build.LifecycleConfig
+	DefaultProcess string
+	// MountApp mounts the app dir into a container copying it into builds with trusted builders,
+	// rather than sending it to the docker daemon, see build.LifecycleConfig
+	MountApp bool
+	// Platform selects the os/arch[/variant] of the builder and run images, such as linux/arm64
+	Platform string
+	// CacheImage is an image at a registry keeping the cache of the build, rather than an image in
+	// the docker daemon
+	CacheImage string
+	// CacheType is how the cache is kept in the docker daemon when there is no CacheImage, in an
+	// image by default, see cache.Type
+	CacheType cache.Type
+	// CacheDir is the host directory holding the caches of type cache.TypeBind
+	CacheDir string
+	// Volumes are mounted into the detect and build containers, see build.LifecycleConfig
+	Volumes []string
+	// Network is the network mode of the detect and build containers, see build.LifecycleConfig
+	Network string
+	// OutputFormat, when set, additionally saves the app image to OutputPath, see BuildConfig
+	OutputFormat string
+	OutputPath   string
+	// AdditionalTags are applied to the app image besides RepoName, see BuildConfig
+	AdditionalTags []string
+	// BOMFormat, when set, writes the bill-of-materials of the app image to BOMPath, see BuildConfig
+	BOMFormat string
+	BOMPath   string
+	// ReportPath, when set, is where a JSON report of the build is written, see BuildConfig
+	ReportPath string
+	// RegistryRetries is how many times published builds retry after transient registry errors, see
+	// BuildConfig
+	RegistryRetries int
+	// Labels are set on the app image, see BuildConfig
+	Labels map[string]string
+	// ExposedPorts, Args and WorkingDir are set in the config of the app image, see BuildConfig
+	ExposedPorts []string
+	Args         []string
+	WorkingDir   string
+	// SkipUnchanged reuses the image last built from the same app source, see BuildConfig
+	SkipUnchanged bool
+	// UserID and GroupID, when set, own the files copied into the build instead of the user of the
+	// builder, see build.LifecycleConfig
+	UserID  *int
+	GroupID *int
 }
 
 type BuildConfig struct {
@@ -55,16 +146,75 @@ type BuildConfig struct {
 	RepoName   string
 	Publish    bool
 	ClearCache bool
+	// NoCache skips the restorer and cacher, so that the build neither uses nor updates its cache,
+	// which makes builds hermetic and shows how much the cache saves
+	NoCache bool
+	// OutputFormat is either OutputOCI or OutputDockerArchive to save the app image to OutputPath
+	// once it is exported to the daemon, or empty to leave it in the daemon only
+	OutputFormat string
+	OutputPath   string
+	// AdditionalTags are given to the exported app image in the daemon, or are pushed when it is
+	// published, which copies its layers to the registry of each tag with the credentials of each.
+	// Lifecycles supporting it export the tags themselves, see build.SupportsExportTags.
+	AdditionalTags []string
+	// BOMFormat is either BOMCycloneDX or BOMSPDX to write the bill-of-materials of the app image to
+	// BOMPath once it is exported, or empty to only return it in the BuildResult
+	BOMFormat string
+	BOMPath   string
+	// ReportPath, when set, is where the BuildResult is written as JSON once the build completes, see
+	// BuildResult.WriteReport
+	ReportPath string
+	// RegistryRetries is how many times the analyzer and exporter of published builds run again when
+	// they fail with a transient registry error, such as a rate limit or a server error, waiting
+	// RegistryRetryBackoff (a second by default) before the first retry and twice as long before each
+	// next one. Builds running every phase in the creator are not retried.
+	RegistryRetries      int
+	RegistryRetryBackoff time.Duration
+	// Labels are set on the exported app image, such as the commit it was built from or the team
+	// owning it. Published images are pushed again with the labels, which changes their digest.
+	// Labels starting with 'io.buildpacks.' are reserved for the lifecycle and stacks.
+	Labels map[string]string
+	// ExposedPorts, as '<port>/<protocol>', Args and WorkingDir are set in the config of the exported
+	// app image, like the EXPOSE, CMD and WORKDIR instructions of a Dockerfile. Args are passed to the
+	// launcher, the entrypoint of the image, such as the process type to run or a command. Like
+	// labels, they change the digest of published images.
+	ExposedPorts []string
+	Args         []string
+	WorkingDir   string
+	// SourceDigest, when set, is the digest of the app source and of the other inputs of the build,
+	// which is set on the app image as its SourceDigestLabel. Builds with the digest of an image that
+	// exists already skip every phase and give that image the name and tags of the build instead, so
+	// that building unchanged source again, such as in CI, is nearly instant.
+	SourceDigest string
+	// LifecycleImage, when set, runs the restorer, analyzer, exporter and cacher instead of the builder,
+	// which is the case when the builder is not one of the configured TrustedBuilders
+	LifecycleImage string
 	// Above are copied from BuildFlags are set by init
-	Cli    Docker
-	Logger *logging.Logger
-	Config *config.Config
+	Cli     Docker
+	Logger  Logger
+	Config  *config.Config
+	OnEvent EventHandler
+	// PhaseHook, when set, is called before and after each phase the build runs, together with the
+	// PhaseStarted and PhaseFinished events
+	PhaseHook PhaseHook
 	// Above are copied from BuildFactory
 	Cache           Cache
 	LifecycleConfig build.LifecycleConfig
+	// clonedAppDir holds the app source cloned from Git, which is removed once the build finishes
+	clonedAppDir string
+	// extractedBuildpackDirs hold buildpacks extracted from local archives, which are removed once
+	// the build finishes
+	extractedBuildpackDirs []string
+	// phases are the timings of the phases run so far, which are reported in the BuildResult
+	phases []PhaseTiming
 }
 
-func DefaultBuildFactory(logger *logging.Logger, cache Cache, dockerClient Docker, fetcher Fetcher) (*BuildFactory, error) {
+// DefaultBuildFactory returns a factory of builds with the given logger, cache, docker client and
+// fetcher, and the configuration in the pack home directory. Options such as WithVerbose replace
+// the logger with one writing to stdout and stderr, which is also used when logger is nil.
+//
+// Deprecated: use Client.Build, which also sets up the cache and buildpack fetching of builds.
+func DefaultBuildFactory(logger Logger, cache Cache, dockerClient Docker, fetcher Fetcher, ops ...func(*BuildFactory)) (*BuildFactory, error) {
 	f := &BuildFactory{
 		Logger:  logger,
 		Cache:   cache,
@@ -79,10 +229,16 @@ func DefaultBuildFactory(logger *logging.Logger, cache Cache, dockerClient Docke
 		return nil, err
 	}
 
+	for _, op := range ops {
+		op(f)
+	}
+	if f.Logger == nil {
+		f.Logger = f.logSettings.logger()
+	}
 	return f, nil
 }
 
-func RepositoryName(logger *logging.Logger, buildFlags *BuildFlags) (string, error) {
+func RepositoryName(logger Logger, buildFlags *BuildFlags) (string, error) {
 	if buildFlags.AppDir == "" {
 		var err error
 		buildFlags.AppDir, err = os.Getwd()
@@ -92,18 +248,34 @@ func RepositoryName(logger *logging.Logger, buildFlags *BuildFlags) (string, err
 		logger.Verbose("Defaulting app directory to current working directory %s (use --path to override)", style.Symbol(buildFlags.AppDir))
 	}
 
-	appDir, err := filepath.Abs(buildFlags.AppDir)
+	appDir, err := appSource(buildFlags.AppDir)
 	if err != nil {
 		return "", err
 	}
-	return calculateRepositoryName(appDir, buildFlags), nil
+	project, err := ReadProjectDescriptor(appDir)
+	if err != nil {
+		return "", err
+	}
+	return calculateRepositoryName(appDir, buildFlags, project), nil
 }
 
-func calculateRepositoryName(appDir string, buildFlags *BuildFlags) string {
-	if buildFlags.RepoName == "" {
-		return fmt.Sprintf("pack.local/run/%x", md5.Sum([]byte(appDir)))
+// appSource returns the absolute path of the app dir, or the app dir itself when it is the URL of
+// a Git repository or AppDirStdin
+func appSource(appDir string) (string, error) {
+	if _, _, ok := git.ParseURL(appDir); ok || appDir == AppDirStdin {
+		return appDir, nil
 	}
-	return buildFlags.RepoName
+	return filepath.Abs(appDir)
+}
+
+func calculateRepositoryName(appDir string, buildFlags *BuildFlags, project *ProjectDescriptor) string {
+	if buildFlags.RepoName != "" {
+		return buildFlags.RepoName
+	}
+	if project != nil && project.Image.Name != "" {
+		return project.Image.Name
+	}
+	return fmt.Sprintf("pack.local/run/%x", md5.Sum([]byte(appDir)))
 }
 
 func (bf *BuildFactory) BuildConfigFromFlags(ctx context.Context, f *BuildFlags) (*BuildConfig, error) {
@@ -119,207 +291,814 @@ func (bf *BuildFactory) BuildConfigFromFlags(ctx context.Context, f *BuildFlags)
 		}
 		bf.Logger.Verbose("Defaulting app directory to current working directory %s (use --path to override)", style.Symbol(f.AppDir))
 	}
-	appDir, err := filepath.Abs(f.AppDir)
+	appDir, err := appSource(f.AppDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if appDir == AppDirStdin && f.RepoName == "" {
+		return nil, errors.New("an image name is required to build an app read from stdin")
+	}
+	if f.SkipUnchanged && appDir == AppDirStdin {
+		return nil, errors.New("unchanged builds cannot be skipped for apps read from stdin")
+	}
+	if f.SkipUnchanged && f.BOMFormat != "" {
+		return nil, errors.New("the bill-of-materials cannot be written for builds skipped when unchanged")
+	}
+
+	// app sources in Git are cloned first, so that the project descriptor and configuration of the
+	// repository apply to the build. The clone is removed unless the build config is returned.
+	source := appDir
+	var clonedAppDir string
+	defer func() {
+		if clonedAppDir != "" {
+			os.RemoveAll(clonedAppDir)
+		}
+	}()
+	if url, ref, ok := git.ParseURL(appDir); ok {
+		if clonedAppDir, err = ioutil.TempDir("", "pack.app"); err != nil {
+			return nil, err
+		}
+		bf.Logger.Verbose("Cloning app source from %s", style.Symbol(f.AppDir))
+		if err := git.Clone(ctx, url, ref, clonedAppDir); err != nil {
+			return nil, err
+		}
+		appDir = clonedAppDir
+	}
+
+	cfg := bf.Config
+	if appDir != AppDirStdin {
+		if cfg, err = bf.Config.ForProject(appDir); err != nil {
+			return nil, errors.Wrapf(err, "reading configuration of project %s", style.Symbol(appDir))
+		}
+		if cfg != bf.Config {
+			bf.Logger.Verbose("Using project configuration %s", style.Symbol(filepath.Join(appDir, config.ProjectDir, "config.toml")))
+		}
+	}
+
+	project, err := ReadProjectDescriptor(appDir)
 	if err != nil {
 		return nil, err
 	}
+	if project != nil {
+		bf.Logger.Verbose("Using project descriptor %s", style.Symbol(filepath.Join(appDir, ProjectDescriptorName)))
+		if len(f.Buildpacks) == 0 {
+			f.Buildpacks = project.buildpacks(appDir)
+		}
+		if len(f.Include) == 0 {
+			f.Include = project.Build.Include
+		}
+		if len(f.Exclude) == 0 {
+			f.Exclude = project.Build.Exclude
+		}
+	}
 
-	f.RepoName = calculateRepositoryName(appDir, f)
+	f.RepoName = calculateRepositoryName(source, f, project)
+
+	if err := checkOutput(f); err != nil {
+		return nil, err
+	}
+	if err := checkTags(f); err != nil {
+		return nil, err
+	}
+	if err := checkLabels(f); err != nil {
+		return nil, err
+	}
+	ports, err := exposedPorts(f)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBOM(f); err != nil {
+		return nil, err
+	}
 
 	b := &BuildConfig{
-		RepoName:   f.RepoName,
-		Publish:    f.Publish,
-		ClearCache: f.ClearCache,
-		Cli:        bf.Cli,
-		Logger:     bf.Logger,
-		Config:     bf.Config,
+		RepoName:        f.RepoName,
+		Publish:         f.Publish,
+		ClearCache:      f.ClearCache,
+		NoCache:         f.NoCache,
+		OutputFormat:    f.OutputFormat,
+		OutputPath:      f.OutputPath,
+		AdditionalTags:  f.AdditionalTags,
+		BOMFormat:       f.BOMFormat,
+		BOMPath:         f.BOMPath,
+		ReportPath:      f.ReportPath,
+		RegistryRetries: f.RegistryRetries,
+		Labels:          f.Labels,
+		ExposedPorts:    ports,
+		Args:            f.Args,
+		WorkingDir:      f.WorkingDir,
+		Cli:             bf.Cli,
+		Logger:          bf.Logger,
+		Config:          cfg,
+		OnEvent:         bf.OnEvent,
+		PhaseHook:       bf.PhaseHook,
 	}
 
-	var env map[string]string
+	env := map[string]string{}
+	if project != nil {
+		for _, item := range project.Build.Env {
+			env[item.Name] = item.Value
+		}
+	}
 	if f.EnvFile != "" {
-		env, err = parseEnvFile(f.EnvFile)
+		fileEnv, err := parseEnvFile(f.EnvFile)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		env = map[string]string{}
+		for k, v := range fileEnv {
+			env[k] = v
+		}
 	}
 	for _, item := range f.Env {
 		env = addEnvVar(env, item)
 	}
 
+	var fetchOps []func(*FetchOptions)
+	if f.Platform != "" {
+		if !platformPattern.MatchString(f.Platform) {
+			return nil, fmt.Errorf("invalid platform %s, must be of the form os/arch[/variant], such as linux/arm64", style.Symbol(f.Platform))
+		}
+		bf.Logger.Verbose("Using images for platform %s, phases run under emulation if the docker daemon runs on another one", style.Symbol(f.Platform))
+		fetchOps = append(fetchOps, WithPlatform(f.Platform))
+	}
+
 	if f.Builder == "" {
-		bf.Logger.Verbose("Using default builder image %s", style.Symbol(bf.Config.DefaultBuilder))
-		b.Builder = bf.Config.DefaultBuilder
+		if cfg.DefaultBuilder == "" {
+			return nil, errNoBuilder()
+		}
+		bf.Logger.Verbose("Using default builder image %s", style.Symbol(cfg.DefaultBuilder))
+		b.Builder = cfg.DefaultBuilder
 	} else {
 		bf.Logger.Verbose("Using user-provided builder image %s", style.Symbol(f.Builder))
 		b.Builder = f.Builder
 	}
 
-	if !f.NoPull {
-		bf.Logger.Verbose("Pulling builder image %s (use --no-pull flag to skip this step)", style.Symbol(b.Builder))
-		img, err := bf.Fetcher.FetchUpdatedLocalImage(ctx, b.Builder, bf.Logger.RawVerboseWriter())
+	if f.RunImage != "" {
+		bf.Logger.Verbose("Using user-provided run image %s", style.Symbol(f.RunImage))
+		b.RunImage = f.RunImage
+
+		// the run image is known up front, so it is fetched while the builder is pulled. The daemon
+		// downloads layers shared by both images once, and the run image's pull progress is shown after
+		// the builder's rather than interleaved with it. A failed pull cancels the other one.
+		var (
+			runOut               bytes.Buffer
+			builderImg           lcimg.Image
+			builderPull, runPull time.Duration
+		)
+		bf.logPull(f, "builder", b.Builder)
+		if !f.Publish {
+			bf.logPull(f, "run", b.RunImage)
+		}
+		g, pullCtx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			var err error
+			builderImg, builderPull, err = bf.fetchImage(pullCtx, b.Builder, f.PullPolicy, logging.RawVerboseWriter(bf.Logger), fetchOps)
+			return err
+		})
+		g.Go(func() error {
+			var err error
+			runPull, err = bf.fetchRunImage(pullCtx, b.RunImage, f, &runOut, fetchOps)
+			return err
+		})
+		err := g.Wait()
+		if runOut.Len() > 0 {
+			bf.Logger.Verbose("%s", strings.TrimSuffix(runOut.String(), "\n"))
+		}
 		if err != nil {
 			return nil, err
 		}
-		builderImage = builder.NewBuilder(img, bf.Config)
+		bf.emitPulled(b.Builder, builderPull)
+		bf.emitPulled(b.RunImage, runPull)
+		builderImage = builder.NewBuilder(builderImg, cfg)
 	} else {
-		img, err := bf.Fetcher.FetchLocalImage(b.Builder)
+		bf.logPull(f, "builder", b.Builder)
+		img, pull, err := bf.fetchImage(ctx, b.Builder, f.PullPolicy, logging.RawVerboseWriter(bf.Logger), fetchOps)
 		if err != nil {
 			return nil, err
 		}
-		builderImage = builder.NewBuilder(img, bf.Config)
-	}
+		bf.emitPulled(b.Builder, pull)
+		builderImage = builder.NewBuilder(img, cfg)
 
-	if f.RunImage != "" {
-		bf.Logger.Verbose("Using user-provided run image %s", style.Symbol(f.RunImage))
-		b.RunImage = f.RunImage
-	} else {
 		b.RunImage, err = builderImage.GetRunImageByRepoName(f.RepoName)
 		if err != nil {
 			return nil, err
 		}
-
 		b.Logger.Verbose("Selected run image %s from builder %s", style.Symbol(b.RunImage), style.Symbol(b.Builder))
+
+		if !f.Publish {
+			bf.logPull(f, "run", b.RunImage)
+		}
+		pull, err = bf.fetchRunImage(ctx, b.RunImage, f, logging.RawVerboseWriter(b.Logger), fetchOps)
+		if err != nil {
+			return nil, err
+		}
+		bf.emitPulled(b.RunImage, pull)
 	}
 
-	var runImage lcimg.Image
-	if f.Publish {
-		runImage, err = bf.Fetcher.FetchRemoteImage(b.RunImage)
+	metadata, err := builderImage.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	var lifecycleVersion, platformAPI string
+	if metadata.Lifecycle != nil {
+		lifecycleVersion = metadata.Lifecycle.Version
+		if metadata.Lifecycle.API != nil {
+			platformAPI = metadata.Lifecycle.API.Platform
+		}
+	}
+	if err := build.CheckLifecycleCompatibility(lifecycleVersion, platformAPI); err != nil {
+		return nil, errors.Wrapf(err, "builder %s is incompatible", style.Symbol(b.Builder))
+	}
+
+	trusted := cfg.IsTrustedBuilder(b.Builder)
+	if !trusted {
+		if trusted, err = bf.confirmTrust(b.Builder); err != nil {
+			return nil, err
+		}
+	}
+	if !trusted {
+		if f.MountApp {
+			return nil, errors.Errorf("app directory can only be mounted into builds with trusted builders, builder %s is not trusted", style.Symbol(b.Builder))
+		}
+		b.LifecycleImage, err = bf.fetchLifecycleImage(ctx, lifecycleVersion, f, fetchOps)
 		if err != nil {
 			return nil, err
 		}
+		bf.Logger.Verbose("Builder %s is not trusted, running phases with access to the daemon or registries in lifecycle image %s", style.Symbol(b.Builder), style.Symbol(b.LifecycleImage))
+	}
 
-		if found, err := runImage.Found(); !found {
-			return nil, fmt.Errorf("remote run image %s does not exist", style.Symbol(b.RunImage))
-		} else if err != nil {
-			return nil, fmt.Errorf("invalid run image %s: %s", style.Symbol(b.RunImage), err)
+	if f.Platform != "" {
+		images := []string{b.Builder}
+		if !f.Publish {
+			images = append(images, b.RunImage)
 		}
-	} else {
-		if !f.NoPull {
-			bf.Logger.Verbose("Pulling run image %s (use --no-pull flag to skip this step)", style.Symbol(b.RunImage))
-			runImage, err = bf.Fetcher.FetchUpdatedLocalImage(ctx, b.RunImage, b.Logger.RawVerboseWriter())
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			runImage, err = bf.Fetcher.FetchLocalImage(b.RunImage)
-			if err != nil {
+		if b.LifecycleImage != "" {
+			images = append(images, b.LifecycleImage)
+		}
+		for _, name := range images {
+			if err := bf.checkPlatform(ctx, name, f.Platform); err != nil {
 				return nil, err
 			}
 		}
+	}
 
-		if found, err := runImage.Found(); !found {
-			return nil, fmt.Errorf("local run image %s does not exist", style.Symbol(b.RunImage))
-		} else if err != nil {
-			return nil, fmt.Errorf("invalid run image %s: %s", style.Symbol(b.RunImage), err)
+	b.Cache = bf.Cache
+	bf.Logger.Verbose(fmt.Sprintf("Using %s", b.cacheName()))
+	if len(cfg.InsecureRegistries) > 0 {
+		if err := checkInsecureRegistries(cfg, b.registryImages()...); err != nil {
+			return nil, err
 		}
 	}
 
-	b.Cache = bf.Cache
-	bf.Logger.Verbose(fmt.Sprintf("Using cache image %s", style.Symbol(b.Cache.Image())))
+	buildpacks, err := bf.fetchBuildpacks(b, f.Buildpacks, metadata.Buildpacks, cfg.BuildpackRegistry)
+	if err != nil {
+		b.cleanup()
+		return nil, err
+	}
+
+	var appReader io.Reader
+	if appDir == AppDirStdin {
+		appReader = os.Stdin
+	}
 
 	b.LifecycleConfig = build.LifecycleConfig{
 		BuilderImage: b.Builder,
 		Logger:       b.Logger,
-		Buildpacks:   f.Buildpacks,
+		Buildpacks:   buildpacks,
 		Env:          env,
 		AppDir:       appDir,
+		AppReader:    appReader,
+		AppSymlinks:  f.AppSymlinks,
+		AppLimits:    f.AppLimits,
+		Include:      f.Include,
+		Exclude:      f.Exclude,
+		MountApp:     f.MountApp,
+		Volumes:      f.Volumes,
+		Network:      f.Network,
+
+		LifecycleVersion: lifecycleVersion,
+		PlatformAPI:      platformAPI,
+		AdditionalTags:   f.AdditionalTags,
+		DefaultProcess:   f.DefaultProcess,
+		UserID:           f.UserID,
+		GroupID:          f.GroupID,
 	}
 
+	if f.SkipUnchanged {
+		if b.SourceDigest, err = bf.sourceDigest(ctx, f, b, metadata); err != nil {
+			b.cleanup()
+			return nil, err
+		}
+		labels := map[string]string{SourceDigestLabel: b.SourceDigest}
+		for key, value := range b.Labels {
+			labels[key] = value
+		}
+		b.Labels = labels
+		bf.Logger.Verbose("Using source digest %s", style.Symbol(b.SourceDigest))
+	}
+	b.clonedAppDir, clonedAppDir = clonedAppDir, ""
 	return b, nil
 }
 
-func Build(ctx context.Context, outWriter, errWriter io.Writer, appDir, builderImage, runImage, repoName string, publish, clearCache bool) error {
-	// TODO: Receive Cache as an argument of this function
-	dockerClient, err := docker.New()
+// fetchBuildpacks downloads and extracts the buildpacks given as .tgz archives or http(s) URLs,
+// returning the buildpacks with those replaced by the directories they were extracted to. Archives
+// given as <uri>@sha256:<digest> must have that digest. Buildpacks given as <id>@<version> that the
+// builder does not contain are downloaded from the buildpack registry, when one is configured.
+func (bf *BuildFactory) fetchBuildpacks(b *BuildConfig, buildpacks []string, builderBuildpacks []builder.BuildpackMetadata, registryURL string) ([]string, error) {
+	var (
+		out      []string
+		registry *buildpack.Registry
+	)
+	if registryURL != "" {
+		registry = buildpack.NewRegistry(registryURL)
+	}
+	for _, bp := range buildpacks {
+		uri, digest, err := buildpack.SplitDigest(bp)
+		if err != nil {
+			return nil, err
+		}
+		remote := strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")
+		var registered *buildpack.Buildpack
+		if !remote && !strings.HasSuffix(uri, ".tgz") {
+			if digest != "" {
+				return nil, errors.Errorf("buildpack %s must be a .tgz archive or URL to verify its digest", style.Symbol(uri))
+			}
+			if registered, err = resolveBuildpack(registry, bp, builderBuildpacks); err != nil {
+				return nil, err
+			} else if registered == nil {
+				out = append(out, bp)
+				continue
+			}
+			bf.Logger.Verbose("Builder %s does not contain buildpack %s, downloading it from buildpack registry %s", style.Symbol(b.Builder), style.Symbol(bp), style.Symbol(registryURL))
+			uri, digest = registered.URI, registered.SHA256
+			remote = strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")
+		}
+		if bf.BuildpackFetcher == nil {
+			bf.BuildpackFetcher = buildpack.NewFetcher(bf.Logger, bf.Config.Path())
+		}
+		fetched, err := bf.BuildpackFetcher.FetchBuildpack("", buildpack.Buildpack{URI: uri, SHA256: digest})
+		if !remote && fetched.Dir != "" {
+			// local archives are extracted into a new directory for each build
+			b.extractedBuildpackDirs = append(b.extractedBuildpackDirs, fetched.Dir)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetching buildpack %s", style.Symbol(bp))
+		}
+		bpDescriptor, err := buildpack.ReadDescriptor(fetched.Dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading buildpack.toml of buildpack %s", style.Symbol(bp))
+		}
+		info := bpDescriptor.Buildpack
+		if registered != nil && (info.ID != registered.ID || info.Version != registered.Version) {
+			return nil, errors.Errorf("buildpack %s from the buildpack registry is %s", style.Symbol(bp), style.Symbol(info.ID+"@"+info.Version))
+		}
+		bf.Logger.Verbose("Using buildpack %s from %s", style.Symbol(info.ID), style.Symbol(bp))
+		out = append(out, fetched.Dir)
+	}
+	return out, nil
+}
+
+// resolveBuildpack looks up the buildpack given as <id>@<version> in the registry, returning nil when
+// there is no registry, when bp is a directory or has no version, or when the builder contains it
+func resolveBuildpack(registry *buildpack.Registry, bp string, builderBuildpacks []builder.BuildpackMetadata) (*buildpack.Buildpack, error) {
+	if registry == nil {
+		return nil, nil
+	}
+	if _, err := os.Stat(filepath.Join(bp, "buildpack.toml")); err == nil {
+		return nil, nil
+	}
+	parts := strings.SplitN(bp, "@", 2)
+	if len(parts) != 2 || parts[1] == "" || parts[1] == "latest" {
+		return nil, nil
+	}
+	id, version := parts[0], parts[1]
+	for _, builderBuildpack := range builderBuildpacks {
+		if builderBuildpack.ID == id && builderBuildpack.Version == version {
+			return nil, nil
+		}
+	}
+	registered, err := registry.Resolve(id, version)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	c, err := cache.New(repoName, dockerClient)
+	return &registered, nil
+}
+
+// cleanup removes the app source cloned from Git and the buildpacks extracted for the build
+func (b *BuildConfig) cleanup() {
+	if b.clonedAppDir != "" {
+		os.RemoveAll(b.clonedAppDir)
+	}
+	for _, dir := range b.extractedBuildpackDirs {
+		os.RemoveAll(dir)
+	}
+}
+
+// fetchLifecycleImage pulls the lifecycle image with the lifecycle version of the builder, or the
+// latest one when the builder does not record its version
+func (bf *BuildFactory) fetchLifecycleImage(ctx context.Context, version string, f *BuildFlags, fetchOps []func(*FetchOptions)) (string, error) {
+	if version == "" {
+		version = "latest"
+	}
+	name := DefaultLifecycleImageRepo + ":" + version
+
+	bf.logPull(f, "lifecycle", name)
+	img, pull, err := bf.fetchImage(ctx, name, f.PullPolicy, logging.RawVerboseWriter(bf.Logger), fetchOps)
 	if err != nil {
-		return err
+		return "", err
 	}
-	imageFactory, err := lcimg.NewFactory(lcimg.WithOutWriter(outWriter))
+	if found, err := img.Found(); !found {
+		return "", fmt.Errorf("lifecycle image %s does not exist", style.Symbol(name))
+	} else if err != nil {
+		return "", fmt.Errorf("invalid lifecycle image %s: %s", style.Symbol(name), err)
+	}
+	bf.emitPulled(name, pull)
+	return name, nil
+}
+
+func (bf *BuildFactory) logPull(f *BuildFlags, kind, name string) {
+	switch f.PullPolicy {
+	case PullAlways:
+		bf.Logger.Verbose("Pulling %s image %s (use --pull-policy if-not-present or never to skip this step)", kind, style.Symbol(name))
+	case PullIfNotPresent:
+		bf.Logger.Verbose("Pulling %s image %s if it is not in the docker daemon", kind, style.Symbol(name))
+	}
+}
+
+func (bf *BuildFactory) emitPulled(name string, duration time.Duration) {
+	if duration != 0 {
+		bf.OnEvent.emit(Event{Type: ImagePulled, Image: name, Duration: duration})
+	}
+}
+
+// fetchImage pulls the named image, returning how long the pull took, or uses the local image
+// without pulling it as the policy allows. It does not log, so that images may be fetched concurrently.
+func (bf *BuildFactory) fetchImage(ctx context.Context, name string, policy PullPolicy, stdout io.Writer, fetchOps []func(*FetchOptions)) (lcimg.Image, time.Duration, error) {
+	if policy != PullAlways {
+		img, err := bf.Fetcher.FetchLocalImage(name)
+		if err != nil || policy == PullNever {
+			return img, 0, err
+		}
+		if found, err := img.Found(); err != nil || found {
+			return img, 0, err
+		}
+	}
+	start := time.Now()
+	img, err := bf.Fetcher.FetchUpdatedLocalImage(ctx, name, stdout, fetchOps...)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
-	imageFetcher := &ImageFetcher{
-		Factory: imageFactory,
-		Docker:  dockerClient,
+	return img, time.Since(start), nil
+}
+
+// fetchRunImage checks that the run image exists at the registry when publishing, or pulls it into the
+// daemon otherwise, returning how long the pull took
+func (bf *BuildFactory) fetchRunImage(ctx context.Context, name string, f *BuildFlags, stdout io.Writer, fetchOps []func(*FetchOptions)) (time.Duration, error) {
+	if f.Publish {
+		runImage, err := bf.Fetcher.FetchRemoteImage(name)
+		if err != nil {
+			return 0, err
+		}
+		if found, err := runImage.Found(); !found {
+			return 0, fmt.Errorf("remote run image %s does not exist", style.Symbol(name))
+		} else if err != nil {
+			return 0, fmt.Errorf("invalid run image %s: %s", style.Symbol(name), err)
+		}
+		return 0, nil
 	}
-	logger := logging.NewLogger(outWriter, errWriter, true, false)
-	bf, err := DefaultBuildFactory(logger, c, dockerClient, imageFetcher)
+
+	runImage, pull, err := bf.fetchImage(ctx, name, f.PullPolicy, stdout, fetchOps)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	b, err := bf.BuildConfigFromFlags(ctx,
-		&BuildFlags{
-			AppDir:     appDir,
-			Builder:    builderImage,
-			RunImage:   runImage,
-			RepoName:   repoName,
-			Publish:    publish,
-			ClearCache: clearCache,
-		})
+	if found, err := runImage.Found(); !found {
+		return 0, fmt.Errorf("local run image %s does not exist", style.Symbol(name))
+	} else if err != nil {
+		return 0, fmt.Errorf("invalid run image %s: %s", style.Symbol(name), err)
+	}
+	return pull, nil
+}
+
+var platformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+// checkPlatform fails when the local image name is not for platform, which happens when the docker
+// daemon does not support pulling images for other platforms, or when they were not pulled
+func (bf *BuildFactory) checkPlatform(ctx context.Context, name, platform string) error {
+	inspect, _, err := bf.Cli.ImageInspectWithRaw(ctx, name)
+	if err != nil {
+		return errors.Wrapf(err, "inspecting image %s", style.Symbol(name))
+	}
+	parts := strings.Split(platform, "/")
+	if inspect.Os != parts[0] || inspect.Architecture != parts[1] {
+		return fmt.Errorf("image %s is for platform %s, not %s", style.Symbol(name), style.Symbol(inspect.Os+"/"+inspect.Architecture), style.Symbol(platform))
+	}
+	return nil
+}
+
+// Build builds an app image with a Client writing to outWriter and errWriter
+//
+// Deprecated: use NewClient and Client.Build, which take options and return the BuildResult.
+func Build(ctx context.Context, outWriter, errWriter io.Writer, appDir, builderImage, runImage, repoName string, publish, clearCache bool) error {
+	client, err := NewClient(WithLogger(logging.NewLogger(outWriter, errWriter, true, false)))
 	if err != nil {
 		return err
 	}
-	return b.Run(ctx)
+	_, err = client.Build(ctx, BuildFlags{
+		AppDir:     appDir,
+		Builder:    builderImage,
+		RunImage:   runImage,
+		RepoName:   repoName,
+		Publish:    publish,
+		ClearCache: clearCache,
+	})
+	return err
 }
 
+// Run builds the app image
 func (b *BuildConfig) Run(ctx context.Context) error {
-	if b.ClearCache {
-		if err := b.Cache.Clear(ctx); err != nil {
-			return errors.Wrap(err, "clearing cache")
+	_, err := b.Build(ctx)
+	return err
+}
+
+// Build builds the app image, returning the image that was exported
+func (b *BuildConfig) Build(ctx context.Context) (result *BuildResult, err error) {
+	defer b.cleanup()
+	export := &exportObserver{handler: b.OnEvent}
+	defer func() {
+		var size int64
+		if result != nil {
+			size = result.Size
+		}
+		b.OnEvent.emit(Event{Type: BuildCompleted, Image: b.RepoName, Digest: export.digest, Size: size, Err: err})
+	}()
+
+	if b.SourceDigest != "" {
+		reused, err := b.reuseImage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if reused {
+			return b.reusedResult(ctx)
 		}
-		b.Logger.Verbose("Cache image %s cleared", style.Symbol(b.Cache.Image()))
+	}
+	if !b.NoCache || b.ClearCache {
+		unlock, err := b.lockCache(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+	if err := b.PrepareCache(ctx); err != nil {
+		return nil, err
 	}
 	lifecycle, err := build.NewLifecycle(b.LifecycleConfig)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	// the lifecycle is cleaned up with a context of its own, so that canceled builds leave no
+	// containers, volumes or images behind
+	defer func() {
+		if err := lifecycle.Cleanup(); err != nil {
+			b.Logger.Verbose("Failed to clean up build: %s", err)
+		}
+	}()
+
+	// trusted builders whose lifecycle has the creator run every phase up to export in one container,
+	// unless the restorer and cacher are skipped, which the creator always runs
+	creator := b.LifecycleImage == "" && !b.NoCache && build.SupportsCreator(b.LifecycleConfig.LifecycleVersion)
+	if creator {
+		b.Logger.Verbose(style.Step("CREATING"))
+		if err := b.runPhase(ctx, "creator", lifecycle, func(ctx context.Context, lifecycle *build.Lifecycle) error {
+			return b.create(ctx, lifecycle, build.WithOutputObserver(export))
+		}); err != nil {
+			return nil, err
+		}
+	} else if err := b.runPhases(ctx, lifecycle, export); err != nil {
+		return nil, err
+	}
+
+	if b.configuresImage() {
+		b.Logger.Verbose(style.Step("CONFIGURING"))
+		digest, err := b.configureImage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if b.Publish {
+			export.digest = digest
+		}
+	}
+	// lifecycles exporting the additional tags have applied them already, to the image without its
+	// new config
+	if len(b.AdditionalTags) > 0 && (!build.SupportsExportTags(b.LifecycleConfig.LifecycleVersion) || b.configuresImage()) {
+		b.Logger.Verbose(style.Step("TAGGING"))
+		if err := b.tag(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if result, err = b.result(ctx, export.digest); err != nil {
+		return nil, err
+	}
+	if err := b.readBuildMetadata(ctx, lifecycle, result); err != nil {
+		return nil, err
+	}
+	b.logResult(result)
+
+	if b.BOMFormat != "" {
+		if err := b.writeBOM(result); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.OutputFormat != "" {
+		b.Logger.Verbose(style.Step("SAVING"))
+		if err := b.save(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if !creator {
+		b.Logger.Verbose(style.Step("CACHING"))
+		if b.NoCache {
+			b.Logger.Verbose("Skipping 'cache' as caching is disabled")
+		} else if err := b.runPhase(ctx, "cacher", lifecycle, b.cache); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Phases = b.phases
+	if b.ReportPath != "" {
+		if err := b.writeReport(result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// runPhases runs detect, restore, analyze, build and export each in a container of its own
+func (b *BuildConfig) runPhases(ctx context.Context, lifecycle *build.Lifecycle, export io.Writer) error {
+	if b.LifecycleImage != "" {
+		b.Logger.Verbose("Running the restorer, analyzer, exporter and cacher in lifecycle image %s", style.Symbol(b.LifecycleImage))
 	}
-	defer lifecycle.Cleanup()
 
 	b.Logger.Verbose(style.Step("DETECTING"))
-	if err := b.detect(ctx, lifecycle); err != nil {
+	if err := b.runPhase(ctx, "detector", lifecycle, b.detect); err != nil {
 		return err
 	}
 
 	b.Logger.Verbose(style.Step("RESTORING"))
-	if b.ClearCache {
+	if b.NoCache {
+		b.Logger.Verbose("Skipping 'restore' as caching is disabled")
+	} else if b.ClearCache {
 		b.Logger.Verbose("Skipping 'restore' due to clearing cache")
-	} else if err := b.restore(ctx, lifecycle); err != nil {
+	} else if err := b.runPhase(ctx, "restorer", lifecycle, b.restore); err != nil {
 		return err
 	}
 
 	b.Logger.Verbose(style.Step("ANALYZING"))
 	if b.ClearCache {
 		b.Logger.Verbose("Skipping 'analyze' due to clearing cache")
-	} else {
-		if err := b.analyze(ctx, lifecycle); err != nil {
-			return err
-		}
+	} else if first, err := b.firstBuild(ctx); err != nil {
+		return err
+	} else if first {
+		b.Logger.Verbose("Skipping 'analyze' as there is no previous image or cache")
+	} else if err := b.runPhase(ctx, "analyzer", lifecycle, b.retryRegistry("analyzer", b.analyze)); err != nil {
+		return err
 	}
 
 	b.Logger.Verbose(style.Step("BUILDING"))
-	if err := b.build(ctx, lifecycle); err != nil {
+	if err := b.runPhase(ctx, "builder", lifecycle, b.build); err != nil {
 		return err
 	}
 
 	b.Logger.Verbose(style.Step("EXPORTING"))
-	if err := b.export(ctx, lifecycle); err != nil {
-		return err
+	return b.runPhase(ctx, "exporter", lifecycle, b.retryRegistry("exporter", func(ctx context.Context, lifecycle *build.Lifecycle, ops ...func(*build.Phase) (*build.Phase, error)) error {
+		return b.export(ctx, lifecycle, append(ops, build.WithOutputObserver(export))...)
+	}))
+}
+
+// defaultRegistryRetryBackoff is how long published builds wait before retrying a phase after a
+// transient registry error, when RegistryRetryBackoff is not set
+const defaultRegistryRetryBackoff = time.Second
+
+// retryRegistry runs phase again, up to RegistryRetries times, when it fails with a transient error
+// of the registry it publishes to or analyzes the previous image at, which is told from the errors
+// the lifecycle logs
+func (b *BuildConfig) retryRegistry(name string, phase func(context.Context, *build.Lifecycle, ...func(*build.Phase) (*build.Phase, error)) error) func(context.Context, *build.Lifecycle) error {
+	return func(ctx context.Context, lifecycle *build.Lifecycle) error {
+		if !b.Publish {
+			return phase(ctx, lifecycle)
+		}
+		backoff := b.RegistryRetryBackoff
+		if backoff == 0 {
+			backoff = defaultRegistryRetryBackoff
+		}
+		for retry := 1; ; retry++ {
+			detector := &build.RegistryErrorDetector{}
+			err := phase(ctx, lifecycle, build.WithErrorObserver(detector))
+			if err == nil || retry > b.RegistryRetries || !detector.Transient() || ctx.Err() != nil {
+				return err
+			}
+			b.Logger.Warn("'%s' failed with a transient registry error, retrying in %s (%d/%d)", name, backoff, retry, b.RegistryRetries)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
 	}
+}
 
-	b.Logger.Verbose(style.Step("CACHING"))
-	if err := b.cache(ctx, lifecycle); err != nil {
-		return err
+// firstBuild reports whether the analyzer has nothing to analyze, as there is neither a previous app
+// image in the daemon nor a cache. Published images are always analyzed, as looking them up at the
+// registry costs about as much as the analyzer.
+func (b *BuildConfig) firstBuild(ctx context.Context) (bool, error) {
+	if b.Publish {
+		return false, nil
+	}
+	if _, _, err := b.Cli.ImageInspectWithRaw(ctx, b.RepoName); err == nil {
+		return false, nil
+	} else if !client.IsErrNotFound(err) {
+		return false, errors.Wrapf(err, "inspecting previous image %s", style.Symbol(b.RepoName))
+	}
+	if b.NoCache {
+		return true, nil
+	}
+	exists, err := b.Cache.Exists(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, "looking up %s", b.cacheName())
+	}
+	return !exists, nil
+}
+
+// lockCache waits for the other builds using the cache in this process, so that concurrent builds of
+// the same image take turns with it rather than overwrite each other's layers
+func (b *BuildConfig) lockCache(ctx context.Context) (func(), error) {
+	done, cancel := context.WithCancel(ctx)
+	cancel()
+	if unlock, err := b.Cache.Lock(done); err == nil {
+		return unlock, nil
+	}
+	b.Logger.Info("Waiting for another build using %s", b.cacheName())
+	unlock, err := b.Cache.Lock(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "waiting for %s", b.cacheName())
 	}
+	return unlock, nil
+}
 
+// PrepareCache clears the cache of the build when ClearCache is set, so that the build starts over
+// without cached layers
+func (b *BuildConfig) PrepareCache(ctx context.Context) error {
+	if !b.ClearCache {
+		return nil
+	}
+	if err := b.Cache.Clear(ctx); err != nil {
+		return errors.Wrap(err, "clearing cache")
+	}
+	b.Logger.Verbose("Cleared %s", b.cacheName())
 	return nil
 }
 
+// cacheName names the cache of the build in messages
+func (b *BuildConfig) cacheName() string {
+	if dir := b.Cache.Dir(); dir != "" {
+		return "cache " + style.Symbol(dir)
+	}
+	return "cache image " + style.Symbol(b.Cache.Image())
+}
+
+// cacheConfig tells the restorer, cacher and creator where the cache of the build is kept
+func (b *BuildConfig) cacheConfig() build.CacheConfig {
+	return build.CacheConfig{Image: b.Cache.Image(), Remote: b.Cache.Remote(), Dir: b.Cache.Dir()}
+}
+
+func (b *BuildConfig) runPhase(ctx context.Context, name string, lifecycle *build.Lifecycle, phase func(context.Context, *build.Lifecycle) error) error {
+	b.OnEvent.emit(Event{Type: PhaseStarted, Phase: name})
+	if b.PhaseHook != nil {
+		b.PhaseHook.BeforePhase(name)
+	}
+	start := time.Now()
+	err := phase(ctx, lifecycle)
+	duration := time.Since(start)
+	b.phases = append(b.phases, PhaseTiming{Phase: name, Duration: duration})
+	b.OnEvent.emit(Event{Type: PhaseFinished, Phase: name, Duration: duration, Err: err})
+	if b.PhaseHook != nil {
+		b.PhaseHook.AfterPhase(name, err, duration)
+	}
+	return err
+}
+
+// privileged returns the options of the phases with access to the docker daemon or registries, which
+// run in the lifecycle image when the builder is not trusted
+func (b *BuildConfig) privileged() []func(*build.Phase) (*build.Phase, error) {
+	if b.LifecycleImage == "" {
+		return nil
+	}
+	return []func(*build.Phase) (*build.Phase, error){build.WithLifecycleImage(b.LifecycleImage)}
+}
+
 func (b *BuildConfig) detect(ctx context.Context, lifecycle *build.Lifecycle) error {
 	detect, err := lifecycle.NewDetect()
 	if err != nil {
@@ -330,7 +1109,7 @@ func (b *BuildConfig) detect(ctx context.Context, lifecycle *build.Lifecycle) er
 }
 
 func (b *BuildConfig) restore(ctx context.Context, lifecycle *build.Lifecycle) error {
-	restore, err := lifecycle.NewRestore(b.Cache.Image())
+	restore, err := lifecycle.NewRestore(b.cacheConfig(), b.privileged()...)
 	if err != nil {
 		return err
 	}
@@ -338,8 +1117,8 @@ func (b *BuildConfig) restore(ctx context.Context, lifecycle *build.Lifecycle) e
 	return restore.Run(ctx)
 }
 
-func (b *BuildConfig) analyze(ctx context.Context, lifecycle *build.Lifecycle) error {
-	analyze, err := lifecycle.NewAnalyze(b.RepoName, b.Publish)
+func (b *BuildConfig) analyze(ctx context.Context, lifecycle *build.Lifecycle, ops ...func(*build.Phase) (*build.Phase, error)) error {
+	analyze, err := lifecycle.NewAnalyze(b.RepoName, b.Publish, append(b.privileged(), ops...)...)
 	if err != nil {
 		return err
 	}
@@ -356,8 +1135,8 @@ func (b *BuildConfig) build(ctx context.Context, lifecycle *build.Lifecycle) err
 	return build.Run(ctx)
 }
 
-func (b *BuildConfig) export(ctx context.Context, lifecycle *build.Lifecycle) error {
-	export, err := lifecycle.NewExport(b.RepoName, b.RunImage, b.Publish)
+func (b *BuildConfig) export(ctx context.Context, lifecycle *build.Lifecycle, ops ...func(*build.Phase) (*build.Phase, error)) error {
+	export, err := lifecycle.NewExport(b.RepoName, b.RunImage, b.Publish, append(b.privileged(), ops...)...)
 	if err != nil {
 		return err
 	}
@@ -365,8 +1144,17 @@ func (b *BuildConfig) export(ctx context.Context, lifecycle *build.Lifecycle) er
 	return export.Run(ctx)
 }
 
+func (b *BuildConfig) create(ctx context.Context, lifecycle *build.Lifecycle, ops ...func(*build.Phase) (*build.Phase, error)) error {
+	create, err := lifecycle.NewCreate(b.RepoName, b.RunImage, b.cacheConfig(), b.Publish, b.ClearCache, ops...)
+	if err != nil {
+		return err
+	}
+	defer create.Cleanup()
+	return create.Run(ctx)
+}
+
 func (b *BuildConfig) cache(ctx context.Context, lifecycle *build.Lifecycle) error {
-	cache, err := lifecycle.NewCache(b.Cache.Image())
+	cache, err := lifecycle.NewCache(b.cacheConfig(), b.privileged()...)
 	if err != nil {
 		return err
 	}
@@ -380,13 +1168,22 @@ func parseEnvFile(filename string) (map[string]string, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "open %s", filename)
 	}
-	for _, line := range strings.Split(string(f), "\n") {
+	var errs descriptor.ValidationErrors
+	for i, line := range strings.Split(string(f), "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := strings.SplitN(line, "=", 2)[0]
+		if name == "" || strings.ContainsAny(name, " \t") {
+			errs = append(errs, &descriptor.ValidationError{File: filename, Line: i + 1, Key: name, Message: "invalid variable name, lines must be of the form <name>[=<value>]"})
 			continue
 		}
 		out = addEnvVar(out, line)
 	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
 	return out, nil
 }
 
@@ -0,0 +1,73 @@
+package build
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+var windowsPath = regexp.MustCompile(`^([a-zA-Z]):[\\/]`)
+
+// Bind returns the bind spec mounting source at target in a container, with the given options
+// such as "ro". Windows host paths like C:\Users\app are converted to the /c/Users/app form that
+// the docker daemon accepts, while volume names and unix paths are used as they are.
+func Bind(source, target string, options ...string) string {
+	if m := windowsPath.FindStringSubmatch(source); m != nil {
+		source = "/" + strings.ToLower(m[1]) + "/" + strings.Replace(source[len(m[0]):], `\`, "/", -1)
+	}
+	spec := source + ":" + target
+	if len(options) > 0 {
+		spec += ":" + strings.Join(options, ",")
+	}
+	return spec
+}
+
+// ParseVolume returns the bind spec of a volume given by a user in the form
+// '<host path or volume name>:<target>[:<options>]', where the options are separated by commas.
+// Volumes are mounted read-only unless the options include 'rw', and relative host paths are
+// resolved against the working directory.
+func ParseVolume(volume string) (string, error) {
+	source, rest := splitVolumePath(volume)
+	target, opts := splitVolumePath(rest)
+	if source == "" || target == "" {
+		return "", errors.Errorf("invalid volume %s, expected '<source>:<target>[:<options>]'", style.Symbol(volume))
+	}
+	var options []string
+	var hasMode bool
+	if opts != "" {
+		for _, option := range strings.Split(opts, ",") {
+			if option == "ro" || option == "rw" {
+				hasMode = true
+			}
+			options = append(options, option)
+		}
+	}
+	if !hasMode {
+		options = append([]string{"ro"}, options...)
+	}
+	if strings.HasPrefix(source, ".") {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return "", errors.Wrapf(err, "resolving volume %s", style.Symbol(volume))
+		}
+		source = abs
+	}
+	return Bind(source, target, options...), nil
+}
+
+// splitVolumePath splits s at the first colon which is not part of a windows drive letter
+func splitVolumePath(s string) (string, string) {
+	offset := 0
+	if windowsPath.MatchString(s) {
+		offset = 2
+	}
+	i := strings.Index(s[offset:], ":")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:offset+i], s[offset+i+1:]
+}
@@ -0,0 +1,84 @@
+package build_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBind(t *testing.T) {
+	spec.Run(t, "Bind", testBind, spec.Report(report.Terminal{}))
+}
+
+func testBind(t *testing.T, when spec.G, it spec.S) {
+	it("uses volume names and unix paths as they are", func() {
+		h.AssertEq(t, build.Bind("pack-app-abc", "/workspace"), "pack-app-abc:/workspace")
+		h.AssertEq(t, build.Bind("/var/run/docker.sock", "/var/run/docker.sock"), "/var/run/docker.sock:/var/run/docker.sock")
+	})
+
+	it("converts windows host paths", func() {
+		h.AssertEq(t, build.Bind(`C:\Users\some-user\app`, "/workspace"), "/c/Users/some-user/app:/workspace")
+		h.AssertEq(t, build.Bind(`d:/some/dir`, "/workspace"), "/d/some/dir:/workspace")
+	})
+
+	it("appends options", func() {
+		h.AssertEq(t, build.Bind("pack-app-abc", "/workspace", "ro", "z"), "pack-app-abc:/workspace:ro,z")
+	})
+
+	when("#ParseVolume", func() {
+		it("mounts volumes read-only by default", func() {
+			bind, err := build.ParseVolume("/home/some-user/.m2:/home/cnb/.m2")
+			h.AssertNil(t, err)
+			h.AssertEq(t, bind, "/home/some-user/.m2:/home/cnb/.m2:ro")
+		})
+
+		it("keeps given options", func() {
+			bind, err := build.ParseVolume("some-cache:/cache:rw")
+			h.AssertNil(t, err)
+			h.AssertEq(t, bind, "some-cache:/cache:rw")
+		})
+
+		it("keeps read-only volumes read-only with other options", func() {
+			bind, err := build.ParseVolume("/home/some-user/.m2:/home/cnb/.m2:ro,z")
+			h.AssertNil(t, err)
+			h.AssertEq(t, bind, "/home/some-user/.m2:/home/cnb/.m2:ro,z")
+		})
+
+		it("mounts volumes read-only when the options do not give the mode", func() {
+			bind, err := build.ParseVolume("/home/some-user/.m2:/home/cnb/.m2:z")
+			h.AssertNil(t, err)
+			h.AssertEq(t, bind, "/home/some-user/.m2:/home/cnb/.m2:ro,z")
+		})
+
+		it("keeps read-write volumes read-write with other options", func() {
+			bind, err := build.ParseVolume("some-cache:/cache:rw,Z")
+			h.AssertNil(t, err)
+			h.AssertEq(t, bind, "some-cache:/cache:rw,Z")
+		})
+
+		it("converts windows host paths", func() {
+			bind, err := build.ParseVolume(`C:\Users\some-user\.npmrc:/home/cnb/.npmrc`)
+			h.AssertNil(t, err)
+			h.AssertEq(t, bind, "/c/Users/some-user/.npmrc:/home/cnb/.npmrc:ro")
+		})
+
+		it("resolves relative host paths", func() {
+			wd, err := os.Getwd()
+			h.AssertNil(t, err)
+			bind, err := build.ParseVolume("./settings.xml:/settings.xml")
+			h.AssertNil(t, err)
+			h.AssertEq(t, bind, build.Bind(filepath.Join(wd, "settings.xml"), "/settings.xml", "ro"))
+		})
+
+		it("fails without a target", func() {
+			_, err := build.ParseVolume("/some/dir")
+			h.AssertError(t, err, "invalid volume '/some/dir'")
+		})
+	})
+}
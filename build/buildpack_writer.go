@@ -0,0 +1,103 @@
+package build
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/buildpack/pack/style"
+)
+
+// the lines the lifecycle logs at debug level when each buildpack starts and finishes building, see
+// buildpackLogArgs
+var (
+	buildpackStartPattern  = regexp.MustCompile(`Running build for buildpack ([^@\s]+)`)
+	buildpackFinishPattern = regexp.MustCompile(`Finished running build for buildpack`)
+)
+
+// BuildpackWriter prefixes each line of the output of the builder with the ID of the buildpack that
+// wrote it, colored by style.Prefix. The buildpack is followed from the lines the lifecycle logs
+// when each buildpack starts and finishes building, and from the section markers of the creator,
+// such as '===> EXPORTING', which end the output of the buildpacks.
+type BuildpackWriter struct {
+	mu    sync.Mutex
+	out   io.Writer
+	buf   bytes.Buffer
+	state *buildpackState
+}
+
+type buildpackState struct {
+	mu      sync.Mutex
+	current string
+}
+
+// NewBuildpackWriters returns writers prefixing the standard output and error of a phase, which
+// follow the same buildpack as the lifecycle may log on either
+func NewBuildpackWriters(stdout, stderr io.Writer) (*BuildpackWriter, *BuildpackWriter) {
+	state := &buildpackState{}
+	return &BuildpackWriter{out: stdout, state: state}, &BuildpackWriter{out: stderr, state: state}
+}
+
+func (w *BuildpackWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// keep the partial line until the rest of it is written
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		if err := w.writeLine(line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush writes the last line when it does not end with a newline
+func (w *BuildpackWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	line := w.buf.String()
+	w.buf.Reset()
+	return w.writeLine(line)
+}
+
+func (w *BuildpackWriter) writeLine(line string) error {
+	buildpack := w.state.follow(line)
+	if buildpack != "" {
+		line = "[" + style.Prefix(buildpack) + "] " + line
+	}
+	_, err := io.WriteString(w.out, line)
+	return err
+}
+
+// follow returns the buildpack that wrote line, updating the current buildpack from the markers of
+// the lifecycle
+func (s *buildpackState) follow(line string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(line, "===> "):
+		s.current = ""
+	case buildpackFinishPattern.MatchString(line):
+		current := s.current
+		s.current = ""
+		return current
+	default:
+		if m := buildpackStartPattern.FindStringSubmatch(line); m != nil {
+			s.current = m[1]
+		}
+	}
+	return s.current
+}
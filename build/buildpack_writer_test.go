@@ -0,0 +1,59 @@
+package build_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBuildpackWriter(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "BuildpackWriter", testBuildpackWriter, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuildpackWriter(t *testing.T, when spec.G, it spec.S) {
+	var (
+		stdout, stderr bytes.Buffer
+		outWriter      *build.BuildpackWriter
+		errWriter      *build.BuildpackWriter
+	)
+
+	it.Before(func() {
+		stdout.Reset()
+		stderr.Reset()
+		outWriter, errWriter = build.NewBuildpackWriters(&stdout, &stderr)
+	})
+
+	it("prefixes the lines of each buildpack with its ID", func() {
+		fmt.Fprint(outWriter, "===> BUILDING\n")
+		fmt.Fprint(outWriter, "Running build for buildpack some/bp@1.0\nbuilding some\n")
+		fmt.Fprint(errWriter, "warning from some\n")
+		fmt.Fprint(outWriter, "Running build for buildpack other/bp@2.0\nbuilding ")
+		fmt.Fprint(outWriter, "other\nFinished running build for buildpack other/bp@2.0\n")
+		fmt.Fprint(outWriter, "===> EXPORTING\nexporting")
+		h.AssertNil(t, outWriter.Flush())
+		h.AssertNil(t, errWriter.Flush())
+
+		h.AssertEq(t, stdout.String(), `===> BUILDING
+[some/bp] Running build for buildpack some/bp@1.0
+[some/bp] building some
+[other/bp] Running build for buildpack other/bp@2.0
+[other/bp] building other
+[other/bp] Finished running build for buildpack other/bp@2.0
+===> EXPORTING
+exporting`)
+		h.AssertEq(t, stderr.String(), "[some/bp] warning from some\n")
+	})
+
+	it("does not prefix output without markers", func() {
+		fmt.Fprint(outWriter, "some output\n")
+		h.AssertEq(t, stdout.String(), "some output\n")
+	})
+}
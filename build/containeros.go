@@ -0,0 +1,101 @@
+package build
+
+import (
+	"github.com/buildpack/pack/archive"
+)
+
+// name of the directory the app is copied to, under the root of the container
+const appDirName = "workspace"
+
+// containerOS holds what differs between builds in linux containers and in windows containers,
+// which are used with builder images for windows stacks
+type containerOS struct {
+	name      string
+	separator string
+	// root is the directory the app directory is copied into
+	root          string
+	layersDir     string
+	buildpacksDir string
+	platformDir   string
+	orderPath     string
+	groupPath     string
+	planPath      string
+	metadataPath  string
+	appDir        string
+	lifecycleDir  string
+	// cacheDir is where caches kept in a volume or host directory are mounted
+	cacheDir string
+	// mountedAppDir is where the app directory is mounted to be copied into the app volume, see
+	// LifecycleConfig.MountApp
+	mountedAppDir string
+	// adminUser runs phases that need access to the docker daemon
+	adminUser string
+	// daemonSocket is bound into phases that need access to the docker daemon
+	daemonSocket string
+}
+
+var linuxContainers = containerOS{
+	name:          "linux",
+	separator:     "/",
+	root:          "/",
+	layersDir:     "/layers",
+	buildpacksDir: "/buildpacks",
+	platformDir:   "/platform",
+	orderPath:     "/buildpacks/order.toml",
+	groupPath:     "/layers/group.toml",
+	planPath:      "/layers/plan.toml",
+	metadataPath:  "/layers/config/metadata.toml",
+	appDir:        "/" + appDirName,
+	lifecycleDir:  "/lifecycle",
+	cacheDir:      "/cache",
+	mountedAppDir: "/pack-app",
+	adminUser:     "root",
+	daemonSocket:  "/var/run/docker.sock",
+}
+
+var windowsContainers = containerOS{
+	name:          "windows",
+	separator:     `\`,
+	root:          `c:\`,
+	layersDir:     `c:\layers`,
+	buildpacksDir: `c:\buildpacks`,
+	platformDir:   `c:\platform`,
+	orderPath:     `c:\buildpacks\order.toml`,
+	groupPath:     `c:\layers\group.toml`,
+	planPath:      `c:\layers\plan.toml`,
+	metadataPath:  `c:\layers\config\metadata.toml`,
+	appDir:        `c:\` + appDirName,
+	lifecycleDir:  `c:\lifecycle`,
+	cacheDir:      `c:\cache`,
+	adminUser:     "ContainerAdministrator",
+	daemonSocket:  `\\.\pipe\docker_engine`,
+}
+
+// containerOSOf returns the containerOS for the os of an image, as reported by the docker daemon
+func containerOSOf(imageOS string) containerOS {
+	if imageOS == "windows" {
+		return windowsContainers
+	}
+	return linuxContainers
+}
+
+func (o containerOS) isWindows() bool {
+	return o.name == "windows"
+}
+
+// layerPath returns the name of the entry for the linux style container path name, such as
+// /platform/env, in a layer added to the builder image
+func (o containerOS) layerPath(name string) string {
+	if o.isWindows() {
+		return archive.WindowsLayerPath(name)
+	}
+	return name
+}
+
+// layerOps returns the options for writing layers added to the builder image
+func (o containerOS) layerOps() []func(*archive.TarOptions) {
+	if o.isWindows() {
+		return []func(*archive.TarOptions){archive.WithWindowsLayerFormat()}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/buildpack/lifecycle/image/auth"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/keychain"
+	"github.com/buildpack/pack/style"
+)
+
+// ContainerRunner runs the containers of builds on a container runtime other than the docker
+// daemon, such as containerd through nerdctl, so that builds publishing their image can run where there is no
+// docker socket
+type ContainerRunner interface {
+	// CreateVolume creates a named volume, which takes the contents and ownership of the path it is
+	// first mounted at in an image, as docker volumes do
+	CreateVolume(ctx context.Context, name string) error
+	RemoveVolume(ctx context.Context, name string) error
+	// Run runs a container until it exits, writing stdin, when not nil, to its stdin and its output
+	// to out. An error is returned when it exits with another code than zero.
+	Run(ctx context.Context, c ContainerSpec, stdin io.Reader, out io.Writer) error
+}
+
+// ContainerSpec is a container run by a ContainerRunner
+type ContainerSpec struct {
+	Name  string
+	Image string
+	// User is the user and group the container runs as, in the form 'uid:gid'
+	User string
+	Cmd  []string
+	Env  []string
+	// Mounts are the volumes mounted into the container, keyed by the path they are mounted at
+	Mounts  map[string]string
+	Network string
+}
+
+// DaemonlessConfig is a build run by a ContainerRunner. The phases run one after another in
+// containers of the builder image, sharing the workspace and layers volumes. As there is no docker
+// daemon, the app image is always published to a registry, and the restore and cache phases, which
+// keep the cache in the daemon, are skipped, as for builds on Kubernetes.
+type DaemonlessConfig struct {
+	Builder        string
+	RunImage       string
+	RepoName       string
+	AdditionalTags []string
+	// LifecycleVersion is the version of the lifecycle of the builder, which selects the arguments
+	// of the phases, see PublishPhases
+	LifecycleVersion string
+	// UID and GID are the user and group of the builder, which the phases run as
+	UID     int
+	GID     int
+	Network string
+	Logger  Logger
+}
+
+// RunDaemonless runs a build with runner, extracting the tar of the app source read from source
+// into the workspace and writing the output of the phases to out. The analyzer and exporter are
+// given the credentials of keychain.Default for the registries of the images. The volumes of the
+// build are removed when it finishes, fails or ctx is canceled.
+func RunDaemonless(ctx context.Context, runner ContainerRunner, cfg DaemonlessConfig, source io.Reader, out io.Writer) error {
+	suffix := randString(10)
+	volumes := map[string]string{
+		"pack-app-" + suffix:    linuxContainers.appDir,
+		"pack-layers-" + suffix: linuxContainers.layersDir,
+	}
+	for name := range volumes {
+		// ctx may be canceled already, which must not keep the volumes from being removed
+		defer func(name string) {
+			if err := runner.RemoveVolume(context.Background(), name); err != nil {
+				cfg.Logger.Verbose("Failed to remove volume %s: %s", style.Symbol(name), err)
+			}
+		}(name)
+		if err := runner.CreateVolume(ctx, name); err != nil {
+			return errors.Wrapf(err, "creating volume %s", style.Symbol(name))
+		}
+	}
+	mounts := map[string]string{}
+	for name, path := range volumes {
+		mounts[path] = name
+	}
+
+	authEnv, err := auth.BuildEnvVar(keychain.Default, append([]string{cfg.RepoName, cfg.RunImage}, cfg.AdditionalTags...)...)
+	if err != nil {
+		return err
+	}
+	registryEnv := []string{"CNB_REGISTRY_AUTH=" + authEnv}
+
+	containers := daemonlessContainers(cfg, registryEnv)
+	for i, c := range containers {
+		c.Name = fmt.Sprintf("pack-%s-%s", c.Name, suffix)
+		c.Image = cfg.Builder
+		c.User = fmt.Sprintf("%d:%d", cfg.UID, cfg.GID)
+		c.Mounts = mounts
+		c.Network = cfg.Network
+
+		var stdin io.Reader
+		if i == 0 {
+			stdin = source
+		}
+		cfg.Logger.Debug("Running %s", style.Symbol(c.Name))
+		if err := runner.Run(ctx, c, stdin, out); err != nil {
+			return errors.Wrapf(err, "running %s", style.Symbol(c.Name))
+		}
+	}
+	return nil
+}
+
+// daemonlessContainers returns the containers of a daemon-less build, in the order they run, with
+// their name, command and environment
+func daemonlessContainers(cfg DaemonlessConfig, registryEnv []string) []ContainerSpec {
+	containers := []ContainerSpec{{
+		Name: "source",
+		Cmd:  []string{"tar", "-x", "-m", "--no-overwrite-dir", "-f", "-", "-C", linuxContainers.appDir},
+	}}
+	for _, phase := range PublishPhases(cfg.LifecycleVersion, cfg.RepoName, cfg.RunImage, cfg.AdditionalTags) {
+		c := ContainerSpec{Name: phase.Name, Cmd: append([]string{phase.Path}, phase.Args...)}
+		if phase.RegistryAccess {
+			c.Env = registryEnv
+		}
+		containers = append(containers, c)
+	}
+	return containers
+}
+
+// PublishPhase is a phase of a build publishing the app image without a docker daemon, see
+// PublishPhases
+type PublishPhase struct {
+	Name string
+	// Path is the path of the phase in the builder image, which runs with Args
+	Path string
+	Args []string
+	// RegistryAccess is whether the phase needs credentials for the registries of the images
+	RegistryAccess bool
+}
+
+// PublishPhases returns the phases, in the order they run in linux containers of the builder, of a
+// build publishing the app image as repoName, on runImage, with the lifecycle of the given version.
+// The arguments of the phases depend on the version as they do for builds in the docker daemon, and
+// tags are only exported by lifecycles supporting it (see SupportsExportTags). The restore and cache
+// phases, which keep the cache in the daemon, are left out. Daemon-less builds and builds on
+// Kubernetes both run these phases.
+func PublishPhases(lifecycleVersion, repoName, runImage string, tags []string) []PublishPhase {
+	l := &Lifecycle{version: lifecycleVersion, tags: tags, os: linuxContainers}
+	phase := func(name string, registryAccess bool, args ...string) PublishPhase {
+		return PublishPhase{
+			Name:           name,
+			Path:           fmt.Sprintf("%s/%s", l.os.lifecycleDir, name),
+			Args:           args,
+			RegistryAccess: registryAccess,
+		}
+	}
+	return []PublishPhase{
+		phase("detector", false,
+			"-buildpacks", l.os.buildpacksDir,
+			"-order", l.os.orderPath,
+			"-group", l.os.groupPath,
+			"-plan", l.os.planPath,
+			"-app", l.os.appDir,
+		),
+		phase("analyzer", true,
+			l.layersFlag(), l.os.layersDir,
+			"-group", l.os.groupPath,
+			repoName,
+		),
+		phase("builder", false,
+			"-buildpacks", l.os.buildpacksDir,
+			l.layersFlag(), l.os.layersDir,
+			"-app", l.os.appDir,
+			"-group", l.os.groupPath,
+			"-plan", l.os.planPath,
+			"-platform", l.os.platformDir,
+		),
+		phase("exporter", true, append([]string{
+			"-image", runImage,
+			l.layersFlag(), l.os.layersDir,
+			"-app", l.os.appDir,
+			"-group", l.os.groupPath,
+			repoName,
+		}, l.exportTags()...)...),
+	}
+}
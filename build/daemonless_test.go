@@ -0,0 +1,144 @@
+package build_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestDaemonless(t *testing.T) {
+	spec.Run(t, "Daemonless", testDaemonless, spec.Report(report.Terminal{}))
+}
+
+type fakeRunner struct {
+	volumes    map[string]bool
+	removed    []string
+	containers []build.ContainerSpec
+	stdin      []string
+	failOn     string
+}
+
+func (r *fakeRunner) CreateVolume(_ context.Context, name string) error {
+	r.volumes[name] = true
+	return nil
+}
+
+func (r *fakeRunner) RemoveVolume(_ context.Context, name string) error {
+	r.removed = append(r.removed, name)
+	return nil
+}
+
+func (r *fakeRunner) Run(_ context.Context, c build.ContainerSpec, stdin io.Reader, out io.Writer) error {
+	r.containers = append(r.containers, c)
+	var in string
+	if stdin != nil {
+		b, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		in = string(b)
+	}
+	r.stdin = append(r.stdin, in)
+	if r.failOn != "" && strings.Contains(c.Name, r.failOn) {
+		return errors.New("some error")
+	}
+	return nil
+}
+
+func testDaemonless(t *testing.T, when spec.G, it spec.S) {
+	var (
+		runner *fakeRunner
+		cfg    build.DaemonlessConfig
+		out    bytes.Buffer
+	)
+
+	it.Before(func() {
+		runner = &fakeRunner{volumes: map[string]bool{}}
+		cfg = build.DaemonlessConfig{
+			Builder:          "some/builder",
+			RunImage:         "some/run",
+			RepoName:         "some/app",
+			AdditionalTags:   []string{"some/app:v1"},
+			LifecycleVersion: "0.7.0",
+			UID:              1000,
+			GID:              1001,
+			Logger:           logging.NewLogger(&out, &out, false, false),
+		}
+	})
+
+	it("runs the phases one after another in the builder image, sharing the volumes", func() {
+		h.AssertNil(t, build.RunDaemonless(context.Background(), runner, cfg, strings.NewReader("some-tar"), &out))
+
+		var names []string
+		for _, c := range runner.containers {
+			names = append(names, c.Name[:strings.LastIndex(c.Name, "-")])
+			h.AssertEq(t, c.Image, "some/builder")
+			h.AssertEq(t, c.User, "1000:1001")
+			h.AssertEq(t, len(c.Mounts), 2)
+			h.AssertEq(t, runner.volumes[c.Mounts["/workspace"]], true)
+			h.AssertEq(t, runner.volumes[c.Mounts["/layers"]], true)
+		}
+		h.AssertEq(t, names, []string{"pack-source", "pack-detector", "pack-analyzer", "pack-builder", "pack-exporter"})
+		h.AssertEq(t, runner.stdin, []string{"some-tar", "", "", "", ""})
+		h.AssertEq(t, runner.containers[4].Cmd, []string{
+			"/lifecycle/exporter",
+			"-image", "some/run",
+			"-layers", "/layers",
+			"-app", "/workspace",
+			"-group", "/layers/group.toml",
+			"some/app", "some/app:v1",
+		})
+	})
+
+	it("runs the phases with the arguments of the lifecycle version", func() {
+		cfg.LifecycleVersion = "0.1.0"
+		h.AssertNil(t, build.RunDaemonless(context.Background(), runner, cfg, strings.NewReader(""), &out))
+
+		h.AssertEq(t, runner.containers[2].Cmd, []string{
+			"/lifecycle/analyzer",
+			"-launch", "/layers",
+			"-group", "/layers/group.toml",
+			"some/app",
+		})
+		h.AssertEq(t, runner.containers[4].Cmd, []string{
+			"/lifecycle/exporter",
+			"-image", "some/run",
+			"-launch", "/layers",
+			"-app", "/workspace",
+			"-group", "/layers/group.toml",
+			"some/app",
+		})
+	})
+
+	it("gives only the analyzer and exporter registry credentials", func() {
+		h.AssertNil(t, build.RunDaemonless(context.Background(), runner, cfg, strings.NewReader(""), &out))
+
+		for i, c := range runner.containers {
+			hasAuth := len(c.Env) == 1 && strings.HasPrefix(c.Env[0], "CNB_REGISTRY_AUTH=")
+			h.AssertEq(t, hasAuth, i == 2 || i == 4)
+		}
+	})
+
+	it("stops at the first failing phase and removes the volumes", func() {
+		runner.failOn = "detector"
+		err := build.RunDaemonless(context.Background(), runner, cfg, strings.NewReader(""), &out)
+		h.AssertError(t, err, "some error")
+
+		h.AssertEq(t, len(runner.containers), 2)
+		h.AssertEq(t, len(runner.removed), 2)
+		for _, name := range runner.removed {
+			h.AssertEq(t, runner.volumes[name], true)
+		}
+	})
+}
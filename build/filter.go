@@ -0,0 +1,93 @@
+package build
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/archive"
+	"github.com/buildpack/pack/style"
+)
+
+// PackIgnoreFile lists patterns of files in the app directory that are not copied into builds, one
+// per line, in addition to the Exclude patterns of the LifecycleConfig
+const PackIgnoreFile = ".packignore"
+
+// AppFilter selects the files of appDir copied into builds, or returns nil to copy all of them.
+// Patterns are globs as in path.Match, matching paths relative to appDir with forward slashes, or
+// just the base names of files when they contain no slash. Files are copied when they or one of
+// their directories match an include pattern, or when there are none, unless they or one of their
+// directories match an exclude pattern or a pattern of the .packignore file of appDir.
+func AppFilter(appDir string, include, exclude []string) (archive.Filter, error) {
+	ignored, err := readPackIgnore(appDir)
+	if err != nil {
+		return nil, err
+	}
+	exclude = append(append([]string{}, exclude...), ignored...)
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %s", style.Symbol(pattern))
+		}
+	}
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	return func(file string, isDir bool) bool {
+		if matchesAny(exclude, file) {
+			return false
+		}
+		// directories are walked in search of included files
+		return len(include) == 0 || isDir || matchesAny(include, file) || matchesAnyParent(include, file)
+	}, nil
+}
+
+func readPackIgnore(appDir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(appDir, PackIgnoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, strings.Trim(line, "/"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", style.Symbol(PackIgnoreFile))
+	}
+	return patterns, nil
+}
+
+func matchesAny(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		name := file
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(file)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyParent(patterns []string, file string) bool {
+	for dir := path.Dir(file); dir != "."; dir = path.Dir(dir) {
+		if matchesAny(patterns, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package build_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestFilter(t *testing.T) {
+	spec.Run(t, "Filter", testFilter, spec.Report(report.Terminal{}))
+}
+
+func testFilter(t *testing.T, when spec.G, it spec.S) {
+	when("#AppFilter", func() {
+		var appDir string
+
+		it.Before(func() {
+			var err error
+			appDir, err = ioutil.TempDir("", "pack.filter")
+			h.AssertNil(t, err)
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.RemoveAll(appDir))
+		})
+
+		it("copies all files without patterns", func() {
+			filter, err := build.AppFilter(appDir, nil, nil)
+			h.AssertNil(t, err)
+			h.AssertEq(t, filter == nil, true)
+		})
+
+		it("excludes files matching the exclude patterns and those of .packignore", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, ".packignore"), []byte("# build output\n/dist/\n\n*.log\n"), 0644))
+
+			filter, err := build.AppFilter(appDir, nil, []string{"node_modules"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, filter("node_modules", true), false)
+			h.AssertEq(t, filter("lib/node_modules", true), false)
+			h.AssertEq(t, filter("dist", true), false)
+			h.AssertEq(t, filter("src/debug.log", false), false)
+			h.AssertEq(t, filter("src", true), true)
+			h.AssertEq(t, filter("src/app.js", false), true)
+		})
+
+		it("includes only files matching the include patterns or in matching directories", func() {
+			filter, err := build.AppFilter(appDir, []string{"src", "*.json"}, []string{"src/*.test.js"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, filter("package.json", false), true)
+			h.AssertEq(t, filter("src/lib/app.js", false), true)
+			h.AssertEq(t, filter("src/app.test.js", false), false)
+			h.AssertEq(t, filter("docs", true), true)
+			h.AssertEq(t, filter("docs/README.md", false), false)
+		})
+
+		it("fails on invalid patterns", func() {
+			_, err := build.AppFilter(appDir, []string{"["}, nil)
+			h.AssertError(t, err, "invalid pattern '['")
+		})
+	})
+}
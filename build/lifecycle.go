@@ -8,9 +8,8 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"path"
 	"path/filepath"
-	"runtime"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,38 +26,169 @@ import (
 
 	"github.com/buildpack/pack/archive"
 	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/keychain"
 	"github.com/buildpack/pack/logging"
 	"github.com/buildpack/pack/style"
 )
 
+// Logger receives the output of a build. *logging.Logger implements it, and programs embedding
+// pack can implement it to route build output into their own logging framework.
+type Logger interface {
+	Debug(format string, a ...interface{})
+	Verbose(format string, a ...interface{})
+	Info(format string, a ...interface{})
+	Warn(format string, a ...interface{})
+}
+
 type Lifecycle struct {
 	BuilderImage string
-	Logger       *logging.Logger
+	Logger       Logger
 	Docker       Docker
 	LayersVolume string
 	AppVolume    string
+	appBind      string
+	appMount     string
 	uid, gid     int
 	appDir       string
+	appReader    io.Reader
+	appSymlinks  archive.SymlinkMode
+	appLimits    AppLimits
+	appFilter    archive.Filter
+	tarOps       []func(*archive.TarOptions)
+	volumes      []string
+	network      string
+	proxyEnv     []string
+	version      string
+	tags         []string
+	process      string
+	os           containerOS
 	appOnce      *sync.Once
+	containers   *containerSet
+}
+
+// containerSet holds the containers of the phases of a lifecycle that have not been removed
+type containerSet struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+func (s *containerSet) add(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[id] = true
+}
+
+func (s *containerSet) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ids, id)
+}
+
+func (s *containerSet) list() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for id := range s.ids {
+		ids = append(ids, id)
+	}
+	return ids
 }
 
+// cleanupTimeout bounds the removal of the containers, volumes and image of a lifecycle, which
+// does not use the context of the build so that it also happens once the build is canceled
+const cleanupTimeout = time.Minute
+
 type Docker interface {
 	RunContainer(ctx context.Context, id string, stdout io.Writer, stderr io.Writer) error
 	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error
+	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error)
 	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error)
 	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error
 	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
 	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
+	VolumeCreate(ctx context.Context, options volume.VolumeCreateBody) (types.Volume, error)
 	VolumeRemove(ctx context.Context, volumeID string, force bool) error
 	VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error)
 }
 
 type LifecycleConfig struct {
 	BuilderImage string
-	Logger       *logging.Logger
+	Logger       Logger
 	Env          map[string]string
-	Buildpacks   []string
-	AppDir       string
+	// PlatformFiles are written into the platform directory besides the env directory, keyed by their
+	// path relative to it (see PlatformFilePath), for buildpacks reading configuration files such as
+	// a maven settings.xml from there
+	PlatformFiles map[string][]byte
+	Buildpacks    []string
+	AppDir        string
+	// AppReader, when set, is read for a tar of the app, which may be gzip compressed, rather than
+	// copying AppDir into the build, so that the app can be piped from other tools. Its entries are
+	// filtered by Include and Exclude, but AppLimits do not apply and symlinks are always written as
+	// links.
+	AppReader io.Reader
+	// AppSymlinks determines how symlinks in the app directory are copied into the build
+	AppSymlinks archive.SymlinkMode
+	// AppLimits bounds the number and size of the files copied from the app directory
+	AppLimits AppLimits
+	// Include and Exclude select the files copied from the app directory, together with its
+	// .packignore file, see AppFilter
+	Include []string
+	Exclude []string
+	// MountApp bind mounts the app directory read-only into a container of the builder image, which
+	// copies it into the app volume, rather than sending a tar of it to the docker daemon. This saves
+	// sending large app directories, but requires the daemon to run on this host, and linux
+	// containers. The files are copied as they are, so it cannot be combined with Include, Exclude or
+	// a .packignore file, and neither AppSymlinks nor AppLimits apply.
+	MountApp bool
+	// Volumes are mounted into the detect and build containers, in the form
+	// '<host path or volume name>:<target>[:<options>]', read-only unless the options say otherwise
+	Volumes []string
+	// Network is the network mode of the detect and build containers, such as 'host', 'none' or the
+	// name of a docker network. The daemon's default network is used when empty
+	Network string
+	// ProxyEnv overrides the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables that the detect and build
+	// containers inherit from the host, see ProxyEnv
+	ProxyEnv map[string]string
+	// LifecycleVersion and PlatformAPI are those of the lifecycle of the builder, which select the
+	// arguments of the phases. They are empty when the builder does not record them.
+	LifecycleVersion string
+	PlatformAPI      string
+	// UserID and GroupID, when set, own the files copied into the build instead of the user of the
+	// builder, see BuilderUser
+	UserID  *int
+	GroupID *int
+	// AdditionalTags are exported together with the app image by lifecycles supporting it (see
+	// SupportsExportTags), so that published images are pushed to each of their registries at once
+	AdditionalTags []string
+	// DefaultProcess, when set, is the process type that the app image runs by default, which only
+	// lifecycles supporting it can set (see SupportsDefaultProcess)
+	DefaultProcess string
+	// VolumeDriver and VolumeDriverOpts create the layers and app volumes with another driver than
+	// the daemon's default one, such as 'local' with the options of a tmpfs or NFS mount
+	VolumeDriver     string
+	VolumeDriverOpts map[string]string
+	// VolumeLabels are given to the layers and app volumes besides 'author=pack', so that cleanup
+	// policies can identify them
+	VolumeLabels map[string]string
+	// Reproducible normalizes the permissions of the files copied from the app directory and of the
+	// buildpacks added to the builder, see archive.WithNormalization, so that builds of the same
+	// sources write the same files on any machine. ModTime, when set, is the modification time of
+	// those files rather than archive.NormalizedDateTime, see archive.WithModTime.
+	Reproducible bool
+	ModTime      time.Time
+}
+
+// tarOps returns the options of the tars of the app directory and buildpacks
+func (c LifecycleConfig) tarOps() []func(*archive.TarOptions) {
+	var ops []func(*archive.TarOptions)
+	if c.Reproducible {
+		ops = append(ops, archive.WithNormalization())
+	}
+	if !c.ModTime.IsZero() {
+		ops = append(ops, archive.WithModTime(c.ModTime))
+	}
+	return ops
 }
 
 func init() {
@@ -66,11 +196,46 @@ func init() {
 }
 
 func NewLifecycle(c LifecycleConfig) (*Lifecycle, error) {
-	client, err := docker.New()
+	if err := CheckLifecycleCompatibility(c.LifecycleVersion, c.PlatformAPI); err != nil {
+		return nil, err
+	}
+	if c.DefaultProcess != "" && !SupportsDefaultProcess(c.LifecycleVersion) {
+		return nil, errors.Errorf("the lifecycle of builder %s cannot set the default process type of app images, lifecycle %s or later is required", style.Symbol(c.BuilderImage), defaultProcessVersion)
+	}
+	appFilter, err := AppFilter(c.AppDir, c.Include, c.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	if c.MountApp && c.AppReader != nil {
+		return nil, errors.New("an app read from a tar cannot be mounted")
+	}
+	if c.MountApp && appFilter != nil {
+		return nil, errors.Errorf("app directory %s cannot be mounted when files are included or excluded, or it has a %s file", style.Symbol(c.AppDir), PackIgnoreFile)
+	}
+
+	platformFiles := map[string][]byte{}
+	for name, content := range c.PlatformFiles {
+		p, err := PlatformFilePath(name)
+		if err != nil {
+			return nil, err
+		}
+		platformFiles[p] = content
+	}
+
+	var volumes []string
+	for _, v := range c.Volumes {
+		bind, err := ParseVolume(v)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, bind)
+	}
+
+	client, err := docker.New(docker.WithLogger(logging.SubsystemLogger(c.Logger, logging.SubsystemDocker)))
 	if err != nil {
 		return nil, err
 	}
-	factory, err := image.NewFactory()
+	factory, err := image.NewFactory(client.FactoryOption(), keychain.FactoryOption)
 	if err != nil {
 		return nil, err
 	}
@@ -80,9 +245,32 @@ func NewLifecycle(c LifecycleConfig) (*Lifecycle, error) {
 		return nil, err
 	}
 	builder.Rename(fmt.Sprintf("pack.local/builder/%x", randString(10)))
-	uid, gid, err := packUidGid(builder)
+
+	inspect, _, err := client.ImageInspectWithRaw(context.Background(), c.BuilderImage)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "inspecting builder image %s", style.Symbol(c.BuilderImage))
+	}
+	containerOS := containerOSOf(inspect.Os)
+
+	// windows containers have no uids, so files are copied into them without changing ownership
+	var uid, gid int
+	if !containerOS.isWindows() {
+		if c.UserID == nil || c.GroupID == nil {
+			var imageUser string
+			if inspect.Config != nil {
+				imageUser = inspect.Config.User
+			}
+			uid, gid, err = BuilderUser(builder, imageUser)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if c.UserID != nil {
+			uid = *c.UserID
+		}
+		if c.GroupID != nil {
+			gid = *c.GroupID
+		}
 	}
 
 	tmpDir, err := ioutil.TempDir("", "pack.build.tars")
@@ -91,17 +279,17 @@ func NewLifecycle(c LifecycleConfig) (*Lifecycle, error) {
 		return nil, err
 	}
 
-	envTar, err := tarEnvFile(tmpDir, c.Env)
-	defer os.RemoveAll(envTar)
+	platformTar, err := tarPlatformDir(tmpDir, c.Env, platformFiles, containerOS)
+	defer os.RemoveAll(platformTar)
 	if err != nil {
 		return nil, err
 	}
-	if err := builder.AddLayer(envTar); err != nil {
+	if err := builder.AddLayer(platformTar); err != nil {
 		return nil, err
 	}
 
 	if len(c.Buildpacks) != 0 {
-		tars, err := createBuildpacksTars(tmpDir, c.Buildpacks, c.Logger, uid, gid)
+		tars, err := createBuildpacksTars(tmpDir, c.Buildpacks, c.Logger, uid, gid, containerOS, c.tarOps())
 		if err != nil {
 			return nil, err
 		}
@@ -117,28 +305,97 @@ func NewLifecycle(c LifecycleConfig) (*Lifecycle, error) {
 		return nil, err
 	}
 
-	return &Lifecycle{
+	var appMount string
+	if c.MountApp {
+		if containerOS.isWindows() {
+			return nil, errors.New("the app directory cannot be mounted into windows containers")
+		}
+		if client.IsRemote() {
+			return nil, errors.Errorf("the app directory cannot be mounted into the containers of docker daemon %s, which runs on another host", style.Symbol(client.DaemonHost()))
+		}
+		if appMount, err = filepath.Abs(c.AppDir); err != nil {
+			return nil, err
+		}
+	}
+
+	appVolume := "pack-app-" + randString(10)
+
+	l := &Lifecycle{
 		BuilderImage: builder.Name(),
 		Logger:       c.Logger,
 		Docker:       client,
 		LayersVolume: "pack-layers-" + randString(10),
-		AppVolume:    "pack-app-" + randString(10),
+		AppVolume:    appVolume,
+		appBind:      Bind(appVolume, containerOS.appDir),
+		appMount:     appMount,
 		appDir:       c.AppDir,
+		appReader:    c.AppReader,
+		appSymlinks:  c.AppSymlinks,
+		appLimits:    c.AppLimits,
+		appFilter:    appFilter,
+		tarOps:       c.tarOps(),
+		volumes:      volumes,
+		network:      c.Network,
+		proxyEnv:     ProxyEnv(c.ProxyEnv),
+		version:      c.LifecycleVersion,
+		tags:         c.AdditionalTags,
+		process:      c.DefaultProcess,
+		os:           containerOS,
 		uid:          uid,
 		gid:          gid,
 		appOnce:      &sync.Once{},
-	}, nil
+		containers:   &containerSet{ids: map[string]bool{}},
+	}
+	if err := l.createVolumes(c); err != nil {
+		l.Cleanup()
+		return nil, err
+	}
+	return l, nil
 }
 
+// createVolumes creates the layers and app volumes with the driver and labels of the config, rather
+// than leaving the daemon to create them with its defaults when the containers of the phases mount
+// them
+func (l *Lifecycle) createVolumes(c LifecycleConfig) error {
+	labels := map[string]string{"author": "pack"}
+	for k, v := range c.VolumeLabels {
+		labels[k] = v
+	}
+	for _, name := range []string{l.LayersVolume, l.AppVolume} {
+		if _, err := l.Docker.VolumeCreate(context.Background(), volume.VolumeCreateBody{
+			Name:       name,
+			Driver:     c.VolumeDriver,
+			DriverOpts: c.VolumeDriverOpts,
+			Labels:     labels,
+		}); err != nil {
+			return errors.Wrapf(err, "creating volume %s", style.Symbol(name))
+		}
+	}
+	return nil
+}
+
+// Cleanup removes the containers of phases that were not cleaned up, such as those of a canceled
+// build, and then the builder image and the volumes of the lifecycle. It carries on past errors,
+// returning the last one.
 func (l *Lifecycle) Cleanup() error {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
 	var reterr error
-	if _, err := l.Docker.ImageRemove(context.Background(), l.BuilderImage, types.ImageRemoveOptions{}); err != nil {
+	for _, id := range l.containers.list() {
+		if err := l.Docker.ContainerRemove(ctx, id, types.ContainerRemoveOptions{Force: true}); err != nil {
+			reterr = errors.Wrapf(err, "failed to clean up container %s", id)
+			continue
+		}
+		l.containers.remove(id)
+	}
+	if _, err := l.Docker.ImageRemove(ctx, l.BuilderImage, types.ImageRemoveOptions{}); err != nil {
 		reterr = errors.Wrapf(err, "failed to clean up builder image %s", l.BuilderImage)
 	}
-	if err := l.Docker.VolumeRemove(context.Background(), l.LayersVolume, true); err != nil {
+	if err := l.Docker.VolumeRemove(ctx, l.LayersVolume, true); err != nil {
 		reterr = errors.Wrapf(err, "failed to clean up layers volume %s", l.LayersVolume)
 	}
-	if err := l.Docker.VolumeRemove(context.Background(), l.AppVolume, true); err != nil {
+	if err := l.Docker.VolumeRemove(ctx, l.AppVolume, true); err != nil {
 		reterr = errors.Wrapf(err, "failed to clean up app volume %s", l.AppVolume)
 	}
 	return reterr
@@ -152,36 +409,23 @@ func randString(n int) string {
 	return string(b)
 }
 
-func packUidGid(builder image.Image) (int, int, error) {
-	sUID, err := builder.Env("CNB_USER_ID")
-	if err != nil {
-		return 0, 0, errors.Wrap(err, "reading builder env variables")
-	}
-	sGID, err := builder.Env("CNB_GROUP_ID")
-	if err != nil {
-		return 0, 0, errors.Wrap(err, "reading builder env variables")
-	}
-	var uid, gid int
-	uid, err = strconv.Atoi(sUID)
-	if err != nil {
-		return 0, 0, errors.Wrapf(err, "parsing pack uid: %s", sUID)
-	}
-	gid, err = strconv.Atoi(sGID)
-	if err != nil {
-		return 0, 0, errors.Wrapf(err, "parsing pack gid: %s", sGID)
-	}
-	return uid, gid, nil
-}
-
-func tarEnvFile(tmpDir string, env map[string]string) (string, error) {
+// tarPlatformDir writes the platform directory, with the env directory and the platform files, into
+// a layer
+func tarPlatformDir(tmpDir string, env map[string]string, files map[string][]byte, containerOS containerOS) (string, error) {
 	now := time.Now()
-	fh, err := os.Create(filepath.Join(tmpDir, "env.tar"))
+	fh, err := os.Create(filepath.Join(tmpDir, "platform.tar"))
 	defer fh.Close()
 	if err != nil {
 		return "", err
 	}
 	tw := tar.NewWriter(fh)
 	defer tw.Close()
+	if containerOS.isWindows() {
+		if err := archive.WriteWindowsLayerDirs(tw); err != nil {
+			return "", err
+		}
+	}
+	platformDir := containerOS.layerPath(linuxContainers.platformDir)
 	for k, v := range env {
 		if err := tw.WriteHeader(&tar.Header{Name: platformDir + "/env/" + k, Size: int64(len(v)), Mode: 0444, ModTime: now}); err != nil {
 			return "", err
@@ -193,22 +437,34 @@ func tarEnvFile(tmpDir string, env map[string]string) (string, error) {
 	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name:  platformDir + "/env", Mode: 0555, ModTime: now}); err != nil {
 		return "", err
 	}
+	dirs := map[string]bool{}
+	for name, content := range files {
+		for dir := path.Dir(name); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: platformDir + "/" + dir, Mode: 0555, ModTime: now}); err != nil {
+				return "", err
+			}
+			dirs[dir] = true
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: platformDir + "/" + name, Size: int64(len(content)), Mode: 0444, ModTime: now}); err != nil {
+			return "", err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return "", err
+		}
+	}
 	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name:  platformDir, Mode: 0555, ModTime: now}); err != nil {
 		return "", err
 	}
 	return fh.Name(), nil
 }
 
-func createBuildpacksTars(tmpDir string, buildpacks []string, logger *logging.Logger, uid int, gid int) ([]string, error) {
+func createBuildpacksTars(tmpDir string, buildpacks []string, logger Logger, uid int, gid int, containerOS containerOS, tarOps []func(*archive.TarOptions)) ([]string, error) {
 	tars := make([]string, 0, len(buildpacks)+1)
 
 	var buildpackGroup []*lifecycle.Buildpack
 	for _, bp := range buildpacks {
 		var id, version string
 		if _, err := os.Stat(filepath.Join(bp, "buildpack.toml")); !os.IsNotExist(err) {
-			if runtime.GOOS == "windows" {
-				return nil, fmt.Errorf("directory buildpacks are not implemented on windows")
-			}
 			var buildpackTOML struct {
 				Buildpack lifecycle.Buildpack
 			}
@@ -221,8 +477,9 @@ func createBuildpacksTars(tmpDir string, buildpacks []string, logger *logging.Lo
 			version = buildpackTOML.Buildpack.Version
 
 			tarFile := filepath.Join(tmpDir, fmt.Sprintf("%s.%s.tar", buildpackTOML.Buildpack.EscapedID(), version))
+			logging.SubsystemLogger(logger, logging.SubsystemFS).Debug("Creating tar of buildpack directory %s at %s", bp, tarFile)
 
-			if err := archive.CreateTar(tarFile, bp, filepath.Join(buildpacksDir, buildpackTOML.Buildpack.EscapedID(), version), uid, gid); err != nil {
+			if err := archive.CreateTar(tarFile, bp, path.Join(linuxContainers.buildpacksDir, buildpackTOML.Buildpack.EscapedID(), version), uid, gid, append(containerOS.layerOps(), tarOps...)...); err != nil {
 				return nil, err
 			}
 
@@ -236,7 +493,7 @@ func createBuildpacksTars(tmpDir string, buildpacks []string, logger *logging.Lo
 		)
 	}
 
-	orderTarPath, err := orderTar(tmpDir, buildpackGroup)
+	orderTarPath, err := orderTar(tmpDir, buildpackGroup, containerOS)
 	if err != nil {
 		return nil, err
 	}
@@ -244,7 +501,7 @@ func createBuildpacksTars(tmpDir string, buildpacks []string, logger *logging.Lo
 	return tars, nil
 }
 
-func orderTar(tmpDir string, buildpacks []*lifecycle.Buildpack) (string, error) {
+func orderTar(tmpDir string, buildpacks []*lifecycle.Buildpack, containerOS containerOS) (string, error) {
 	groups := lifecycle.BuildpackOrder{
 		lifecycle.BuildpackGroup{
 			Buildpacks: buildpacks,
@@ -259,8 +516,9 @@ func orderTar(tmpDir string, buildpacks []*lifecycle.Buildpack) (string, error)
 	orderToml := tomlBuilder.String()
 	err := archive.CreateSingleFileTar(
 		filepath.Join(tmpDir, "order.tar"),
-		orderPath,
+		linuxContainers.orderPath,
 		orderToml,
+		containerOS.layerOps()...,
 	)
 	if err != nil {
 		return "", errors.Wrap(err, "converting order TOML to tar reader")
@@ -268,7 +526,7 @@ func orderTar(tmpDir string, buildpacks []*lifecycle.Buildpack) (string, error)
 	return filepath.Join(tmpDir, "order.tar"), nil
 }
 
-func parseBuildpack(ref string, logger *logging.Logger) (string, string) {
+func parseBuildpack(ref string, logger Logger) (string, string) {
 	parts := strings.Split(ref, "@")
 	if len(parts) == 2 {
 		return parts[0], parts[1]
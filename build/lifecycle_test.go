@@ -8,7 +8,6 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -21,6 +20,7 @@ import (
 
 	"github.com/buildpack/pack/archive"
 	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/cache"
 	"github.com/buildpack/pack/docker"
 	"github.com/buildpack/pack/logging"
 	h "github.com/buildpack/pack/testhelpers"
@@ -142,6 +142,31 @@ func testLifecycle(t *testing.T, when spec.G, it spec.S) {
 					h.AssertContains(t, outBuf.String(), "failed to read file")
 				})
 
+				it("stops the container when the context is canceled", func() {
+					phase, err := lifecycle.NewPhase("phase", build.WithArgs("sleep"))
+					h.AssertNil(t, err)
+					ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+					defer cancel()
+
+					start := time.Now()
+					err = phase.Run(ctx)
+					h.AssertNotNil(t, err)
+					h.AssertContains(t, err.Error(), "context deadline exceeded")
+					if time.Since(start) > time.Minute {
+						t.Fatalf("expected the canceled phase to return once its container stopped, took %s", time.Since(start))
+					}
+
+					running, err := dockerCli.ContainerList(context.TODO(), dockertypes.ContainerListOptions{
+						Filters: filters.NewArgs(filters.Arg("ancestor", repoName), filters.Arg("status", "running")),
+					})
+					h.AssertNil(t, err)
+					for _, ctr := range running {
+						if strings.Contains(ctr.Command, "sleep") {
+							t.Fatalf("expected the container of the canceled phase to be stopped, but %s is running", ctr.ID)
+						}
+					}
+				})
+
 				it("preserves original order.toml", func() {
 					phase, err := lifecycle.NewPhase(
 						"phase",
@@ -174,6 +199,41 @@ func testLifecycle(t *testing.T, when spec.G, it spec.S) {
 					})
 				})
 
+				when("#WithLifecycleImage", func() {
+					it("runs the phase in the lifecycle image with the layers of the build", func() {
+						writePhase, err := lifecycle.NewPhase("phase", build.WithArgs("write", "/layers/test.txt", "test-layers"))
+						h.AssertNil(t, err)
+						assertRunSucceeds(t, writePhase, &outBuf, &errBuf)
+
+						readPhase, err := lifecycle.NewPhase(
+							"phase",
+							build.WithArgs("read", "/layers/test.txt"),
+							build.WithLifecycleImage(repoName),
+						)
+						h.AssertNil(t, err)
+						assertRunSucceeds(t, readPhase, &outBuf, &errBuf)
+						h.AssertContains(t, outBuf.String(), "[phase] file contents: test-layers")
+					})
+				})
+
+				when("#WithBinds", func() {
+					it("mounts the binds into the container", func() {
+						tmpDir, err := ioutil.TempDir("", "pack.lifecycle.binds")
+						h.AssertNil(t, err)
+						defer os.RemoveAll(tmpDir)
+						h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "some-file"), []byte("some-content"), 0644))
+
+						phase, err := lifecycle.NewPhase(
+							"phase",
+							build.WithArgs("read", "/some-mount/some-file"),
+							build.WithBinds(build.Bind(tmpDir, "/some-mount", "ro")),
+						)
+						h.AssertNil(t, err)
+						assertRunSucceeds(t, phase, &outBuf, &errBuf)
+						h.AssertContains(t, outBuf.String(), "[phase] file contents: some-content")
+					})
+				})
+
 				when("#WithRegistryAccess", func() {
 					var registry *h.TestRegistryConfig
 
@@ -197,13 +257,130 @@ func testLifecycle(t *testing.T, when spec.G, it spec.S) {
 					})
 				})
 			})
+
+			when("#NewCache", func() {
+				it("leaves a cache in a host directory to the user running pack, who can clear it", func() {
+					root, err := ioutil.TempDir("", "lifecycle-bind-cache")
+					h.AssertNil(t, err)
+					defer os.RemoveAll(root)
+					bindCache, err := cache.New("some/app", dockerCli, cache.TypeBind, cache.WithBindRoot(root))
+					h.AssertNil(t, err)
+
+					cacher, err := lifecycle.NewCache(build.CacheConfig{Dir: bindCache.Dir()})
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, cacher, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "[cacher] cache test")
+					_, err = os.Stat(filepath.Join(bindCache.Dir(), "some-layer", "some-file"))
+					h.AssertNil(t, err)
+
+					h.AssertNil(t, bindCache.Clear(context.TODO()))
+					_, err = os.Stat(bindCache.Dir())
+					h.AssertEq(t, os.IsNotExist(err), true)
+				})
+			})
+
+			when("#NewCreate", func() {
+				var cacheVolume string
+
+				it.Before(func() {
+					cacheVolume = "pack-cache-test-" + h.RandString(10)
+				})
+
+				it.After(func() {
+					// the volume is only created by the tests keeping the cache in it
+					dockerCli.VolumeRemove(context.TODO(), cacheVolume, true)
+				})
+
+				it("keeps a cache image of the daemon in a volume of the same name", func() {
+					create, err := lifecycle.NewCreate("some/app", "some/run", build.CacheConfig{Image: cacheVolume}, false, false)
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, create, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "received args [/lifecycle/creator -buildpacks /buildpacks -order /buildpacks/order.toml -app /workspace -layers /layers -platform /platform -run-image some/run -cache-dir /cache -daemon some/app]")
+
+					_, err = dockerCli.VolumeInspect(context.TODO(), cacheVolume)
+					h.AssertNil(t, err)
+				})
+
+				it("names a cache image at a registry", func() {
+					create, err := lifecycle.NewCreate("some/app", "some/run", build.CacheConfig{Image: "some-registry.io/some/cache", Remote: true}, true, false)
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, create, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "received args [/lifecycle/creator -buildpacks /buildpacks -order /buildpacks/order.toml -app /workspace -layers /layers -platform /platform -run-image some/run -cache-image some-registry.io/some/cache some/app]")
+				})
+
+				it("skips restoring the cache when clearing it", func() {
+					create, err := lifecycle.NewCreate("some/app", "some/run", build.CacheConfig{Dir: cacheVolume}, false, true)
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, create, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "-cache-dir /cache -skip-restore -daemon some/app]")
+				})
+
+				it("reaches the daemon but no registry when exporting to the daemon", func() {
+					create, err := lifecycle.NewCreate("some/app", "some/run", build.CacheConfig{Image: cacheVolume}, false, false)
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, create, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "[creator] registry access false")
+					h.AssertContains(t, outBuf.String(), "[creator] daemon access true")
+				})
+
+				it("reaches registries but not the daemon when publishing", func() {
+					create, err := lifecycle.NewCreate("some/app", "some/run", build.CacheConfig{Image: cacheVolume}, true, false)
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, create, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "[creator] registry access true")
+					h.AssertContains(t, outBuf.String(), "[creator] daemon access false")
+				})
+
+				it("reaches the registry of a remote cache when exporting to the daemon", func() {
+					create, err := lifecycle.NewCreate("some/app", "some/run", build.CacheConfig{Image: "some-registry.io/some/cache", Remote: true}, false, false)
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, create, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "[creator] registry access true")
+					h.AssertContains(t, outBuf.String(), "[creator] daemon access true")
+				})
+			})
+
+			when("the builder is untrusted", func() {
+				it("runs the analyzer and exporter in the lifecycle image with daemon access when exporting to the daemon", func() {
+					analyze, err := lifecycle.NewAnalyze("some/app", false, build.WithLifecycleImage(repoName))
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, analyze, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "[analyzer] registry access false")
+					h.AssertContains(t, outBuf.String(), "[analyzer] daemon access true")
+
+					export, err := lifecycle.NewExport("some/app", "some/run", false, build.WithLifecycleImage(repoName))
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, export, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "[exporter] registry access false")
+					h.AssertContains(t, outBuf.String(), "[exporter] daemon access true")
+				})
+
+				it("runs the analyzer and exporter in the lifecycle image with registry access when publishing", func() {
+					analyze, err := lifecycle.NewAnalyze("some/app", true, build.WithLifecycleImage(repoName))
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, analyze, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "[analyzer] registry access true")
+					h.AssertContains(t, outBuf.String(), "[analyzer] daemon access false")
+
+					export, err := lifecycle.NewExport("some/app", "some/run", true, build.WithLifecycleImage(repoName))
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, export, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "[exporter] registry access true")
+					h.AssertContains(t, outBuf.String(), "[exporter] daemon access false")
+				})
+
+				it("runs the builder without access to the daemon or registries", func() {
+					builder, err := lifecycle.NewBuild()
+					h.AssertNil(t, err)
+					assertRunSucceeds(t, builder, &outBuf, &errBuf)
+					h.AssertContains(t, outBuf.String(), "[builder] registry access false")
+					h.AssertContains(t, outBuf.String(), "[builder] daemon access false")
+				})
+			})
 		})
 
 		when("there are user provided custom buildpacks", func() {
 			it.Before(func() {
-				if runtime.GOOS == "windows" {
-					t.Skip("directory buildpacks are not implemented on windows")
-				}
 				var err error
 				lifecycle, err = build.NewLifecycle(
 					build.LifecycleConfig{
@@ -293,9 +470,83 @@ func testLifecycle(t *testing.T, when spec.G, it spec.S) {
 `)
 			})
 		})
+
+		when("the app directory is mounted", func() {
+			it.Before(func() {
+				var err error
+				lifecycle, err = build.NewLifecycle(
+					build.LifecycleConfig{
+						BuilderImage: repoName,
+						AppDir:       filepath.Join("testdata", "fake-app"),
+						MountApp:     true,
+						Logger:       logger,
+					},
+				)
+				h.AssertNil(t, err)
+			})
+
+			it("copies the mounted app directory into the app volume, where buildpacks can write", func() {
+				readPhase, err := lifecycle.NewPhase("phase", build.WithArgs("read", "/workspace/fake-app-file"))
+				h.AssertNil(t, err)
+				assertRunSucceeds(t, readPhase, &outBuf, &errBuf)
+				h.AssertContains(t, outBuf.String(), "[phase] file contents: fake-app-contents")
+				h.AssertNotContains(t, outBuf.String(), "Copying app directory")
+
+				deletePhase, err := lifecycle.NewPhase("phase", build.WithArgs("delete", "/workspace/fake-app-file"))
+				h.AssertNil(t, err)
+				assertRunSucceeds(t, deletePhase, &outBuf, &errBuf)
+				_, err = os.Stat(filepath.Join("testdata", "fake-app", "fake-app-file"))
+				h.AssertNil(t, err)
+			})
+		})
+	})
+
+	when("volume options are given", func() {
+		it("creates the volumes with the driver and labels", func() {
+			var outBuf, errBuf bytes.Buffer
+			subject, err := build.NewLifecycle(build.LifecycleConfig{
+				BuilderImage: repoName,
+				AppDir:       filepath.Join("testdata", "fake-app"),
+				Logger:       logging.NewLogger(&outBuf, &errBuf, true, false),
+				VolumeDriver: "local",
+				VolumeLabels: map[string]string{"org.example.cleanup": "daily"},
+			})
+			h.AssertNil(t, err)
+			defer subject.Cleanup()
+
+			for _, name := range []string{subject.LayersVolume, subject.AppVolume} {
+				vol, err := dockerCli.VolumeInspect(context.TODO(), name)
+				h.AssertNil(t, err)
+				h.AssertEq(t, vol.Driver, "local")
+				h.AssertEq(t, vol.Labels, map[string]string{"author": "pack", "org.example.cleanup": "daily"})
+			}
+		})
 	})
 
 	when("#Cleanup", func() {
+		it("removes the containers of phases that were not cleaned up", func() {
+			var outBuf, errBuf bytes.Buffer
+			subject, err := build.NewLifecycle(build.LifecycleConfig{
+				BuilderImage: repoName,
+				AppDir:       filepath.Join("testdata", "fake-app"),
+				Logger:       logging.NewLogger(&outBuf, &errBuf, true, false),
+			})
+			h.AssertNil(t, err)
+			phase, err := subject.NewPhase("phase")
+			h.AssertNil(t, err)
+			h.AssertNil(t, phase.Run(context.TODO()))
+			containers, err := dockerCli.ContainerList(context.TODO(), dockertypes.ContainerListOptions{
+				All:     true,
+				Filters: filters.NewArgs(filters.KeyValuePair{Key: "ancestor", Value: subject.BuilderImage}),
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(containers), 1)
+
+			h.AssertNil(t, subject.Cleanup())
+			_, err = dockerCli.ContainerInspect(context.TODO(), containers[0].ID)
+			h.AssertNotNil(t, err)
+		})
+
 		var (
 			subject        *build.Lifecycle
 			outBuf, errBuf bytes.Buffer
@@ -0,0 +1,100 @@
+package build
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/archive"
+	"github.com/buildpack/pack/style"
+)
+
+// number of the largest files listed when the app directory exceeds a limit
+const largestAppFilesShown = 10
+
+// AppLimits bounds the number and total size of the files copied from the app directory into
+// the build, so that unexpectedly large directories are noticed before a long build. A zero
+// limit is not checked.
+type AppLimits struct {
+	MaxFiles int
+	MaxSize  ByteSize
+	// Enforce fails the build when a limit is exceeded, rather than warning about it
+	Enforce bool
+}
+
+// ByteSize is a number of bytes that can be bound to a command line flag in a form like '500MB'.
+// Units are powers of 1024.
+type ByteSize int64
+
+var byteSizeUnits = []string{"B", "KB", "MB", "GB", "TB"}
+
+func (s ByteSize) String() string {
+	size, unit := float64(s), 0
+	for size >= 1024 && unit < len(byteSizeUnits)-1 {
+		size /= 1024
+		unit++
+	}
+	return strings.TrimSuffix(strconv.FormatFloat(size, 'f', 1, 64), ".0") + byteSizeUnits[unit]
+}
+
+// Set implements pflag.Value
+func (s *ByteSize) Set(value string) error {
+	upper := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for i := len(byteSizeUnits) - 1; i > 0; i-- {
+		// accept both 'MB' and 'M'
+		unit := byteSizeUnits[i]
+		if strings.HasSuffix(upper, unit) || strings.HasSuffix(upper, unit[:1]) {
+			upper = strings.TrimSuffix(strings.TrimSuffix(upper, unit), unit[:1])
+			multiplier = 1 << (10 * uint(i))
+			break
+		}
+	}
+	upper = strings.TrimSuffix(upper, "B")
+	size, err := strconv.ParseFloat(strings.TrimSpace(upper), 64)
+	if err != nil || size < 0 {
+		return fmt.Errorf("invalid size '%s', must be a number optionally followed by B, KB, MB, GB or TB", value)
+	}
+	*s = ByteSize(size * float64(multiplier))
+	return nil
+}
+
+func (s *ByteSize) Type() string {
+	return "size"
+}
+
+// check warns about, or when enforcing limits fails on, the files of an app directory accepted by
+// filter exceeding the limits
+func (l AppLimits) check(appDir string, filter archive.Filter, logger Logger) error {
+	if l.MaxFiles == 0 && l.MaxSize == 0 {
+		return nil
+	}
+	stats, err := archive.ScanDir(appDir, largestAppFilesShown, filter)
+	if err != nil {
+		return errors.Wrapf(err, "failed to scan app directory %s", appDir)
+	}
+
+	var exceeded []string
+	if l.MaxFiles > 0 && stats.Files > l.MaxFiles {
+		exceeded = append(exceeded, fmt.Sprintf("%d files, more than the limit of %d", stats.Files, l.MaxFiles))
+	}
+	if l.MaxSize > 0 && stats.Size > int64(l.MaxSize) {
+		exceeded = append(exceeded, fmt.Sprintf("%s of files, more than the limit of %s", ByteSize(stats.Size), l.MaxSize))
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("app directory %s contains %s", style.Symbol(appDir), strings.Join(exceeded, " and "))
+	msg += "\nLargest files:"
+	for _, f := range stats.Largest {
+		msg += fmt.Sprintf("\n  %10s  %s", ByteSize(f.Size), f.Path)
+	}
+	if l.Enforce {
+		return errors.New(msg)
+	}
+	logger.Warn("%s", msg)
+	return nil
+}
@@ -0,0 +1,48 @@
+package build_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestLimits(t *testing.T) {
+	spec.Run(t, "Limits", testLimits, spec.Report(report.Terminal{}))
+}
+
+func testLimits(t *testing.T, when spec.G, it spec.S) {
+	when("ByteSize#Set", func() {
+		it("parses sizes with and without units", func() {
+			for value, expected := range map[string]build.ByteSize{
+				"0":      0,
+				"512":    512,
+				"512B":   512,
+				"2k":     2 * 1024,
+				"500MB":  500 * 1024 * 1024,
+				"1.5 GB": 1536 * 1024 * 1024,
+			} {
+				var size build.ByteSize
+				h.AssertNil(t, size.Set(value))
+				h.AssertEq(t, size, expected)
+			}
+		})
+
+		it("fails on invalid sizes", func() {
+			var size build.ByteSize
+			h.AssertError(t, size.Set("lots"), "invalid size 'lots'")
+			h.AssertError(t, size.Set("-1MB"), "invalid size '-1MB'")
+		})
+	})
+
+	when("ByteSize#String", func() {
+		it("uses the largest whole unit", func() {
+			h.AssertEq(t, build.ByteSize(100).String(), "100B")
+			h.AssertEq(t, build.ByteSize(1024*1024*1024).String(), "1GB")
+			h.AssertEq(t, build.ByteSize(1536*1024).String(), "1.5MB")
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package build
+
+import (
+	"archive/tar"
+	"context"
+
+	"github.com/BurntSushi/toml"
+	"github.com/buildpack/lifecycle"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+// BuildMetadata reads the processes, buildpacks and bill-of-materials of the app that the builder
+// wrote to the layers volume, so it must be called after the build and before Cleanup. The file is
+// copied out of a container of the builder image that is created but never started.
+func (l *Lifecycle) BuildMetadata(ctx context.Context) (*lifecycle.BuildMetadata, error) {
+	var metadata lifecycle.BuildMetadata
+	if err := l.readLayersFile(ctx, l.os.metadataPath, &metadata); err != nil {
+		return nil, errors.Wrap(err, "reading build metadata")
+	}
+	return &metadata, nil
+}
+
+// BuildpackGroup reads the group of buildpacks that the detector selected, with their versions, from
+// the layers volume, so it must be called after detection and before Cleanup
+func (l *Lifecycle) BuildpackGroup(ctx context.Context) (*lifecycle.BuildpackGroup, error) {
+	var group lifecycle.BuildpackGroup
+	if err := l.readLayersFile(ctx, l.os.groupPath, &group); err != nil {
+		return nil, errors.Wrap(err, "reading buildpack group")
+	}
+	return &group, nil
+}
+
+// readLayersFile decodes the TOML file at path in the layers volume into v
+func (l *Lifecycle) readLayersFile(ctx context.Context, path string, v interface{}) error {
+	ctr, err := l.Docker.ContainerCreate(ctx, &container.Config{
+		Image:  l.BuilderImage,
+		Labels: map[string]string{"author": "pack"},
+	}, &container.HostConfig{
+		Binds: []string{Bind(l.LayersVolume, l.os.layersDir)},
+	}, nil, "")
+	if err != nil {
+		return errors.Wrap(err, "creating container")
+	}
+	l.containers.add(ctr.ID)
+	defer func() {
+		if err := l.Docker.ContainerRemove(ctx, ctr.ID, types.ContainerRemoveOptions{Force: true}); err == nil {
+			l.containers.remove(ctr.ID)
+		}
+	}()
+
+	rc, _, err := l.Docker.CopyFromContainer(ctx, ctr.ID, path)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	if _, err := tr.Next(); err != nil {
+		return err
+	}
+	if _, err := toml.DecodeReader(tr, v); err != nil {
+		return errors.Wrap(err, "parsing")
+	}
+	return nil
+}
@@ -1,31 +1,63 @@
 package build
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/buildpack/lifecycle/image/auth"
 
 	"github.com/buildpack/pack/archive"
+	"github.com/buildpack/pack/keychain"
 	"github.com/buildpack/pack/logging"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/pkg/errors"
 )
 
+// app directories larger than this are compressed while copying them into the build
+const appGzipThreshold = 100 * 1024 * 1024
+
+// stopTimeout is how long the lifecycle in the container of a canceled phase has to exit before it
+// is killed
+const stopTimeout = 10 * time.Second
+
 type Phase struct {
-	name     string
-	logger   *logging.Logger
-	docker   Docker
-	ctrConf  *container.Config
-	hostConf *container.HostConfig
-	ctr      container.ContainerCreateCreatedBody
-	uid, gid int
-	appDir   string
-	appOnce  *sync.Once
+	name        string
+	logger      Logger
+	docker      Docker
+	ctrConf     *container.Config
+	hostConf    *container.HostConfig
+	ctr         container.ContainerCreateCreatedBody
+	uid, gid    int
+	appDir      string
+	appMount    string
+	appBind     string
+	appReader   io.Reader
+	appSymlinks archive.SymlinkMode
+	appLimits   AppLimits
+	appFilter   archive.Filter
+	tarOps      []func(*archive.TarOptions)
+	appOnce     *sync.Once
+	containers  *containerSet
+	os          containerOS
+	observers   []io.Writer
+	// errObservers are copied the standard error of the phase, where the lifecycle logs its errors
+	errObservers []io.Writer
+	// buildpackPrefixes prefixes the output with the buildpack writing it, see BuildpackWriter
+	buildpackPrefixes bool
+	// builderImage runs the phase unless WithLifecycleImage runs it in the lifecycle image
+	builderImage string
+	// cacheBind is the host directory of the cache, which is chowned to the user running pack after
+	// the phase, see withCacheDir
+	cacheBind string
 }
 
 func (l *Lifecycle) NewPhase(name string, ops ...func(*Phase) (*Phase, error)) (*Phase, error) {
@@ -35,21 +67,32 @@ func (l *Lifecycle) NewPhase(name string, ops ...func(*Phase) (*Phase, error)) (
 	}
 	hostConf := &container.HostConfig{
 		Binds: []string{
-			fmt.Sprintf("%s:%s:", l.LayersVolume, layersDir),
-			fmt.Sprintf("%s:%s:", l.AppVolume, appDir),
+			Bind(l.LayersVolume, l.os.layersDir),
+			l.appBind,
 		},
 	}
-	ctrConf.Cmd = []string{"/lifecycle/" + name}
+	ctrConf.Cmd = []string{l.os.lifecycleDir + l.os.separator + name}
 	phase := &Phase{
-		ctrConf:  ctrConf,
-		hostConf: hostConf,
-		name:     name,
-		docker:   l.Docker,
-		logger:   l.Logger,
-		uid:      l.uid,
-		gid:      l.gid,
-		appDir:   l.appDir,
-		appOnce:  l.appOnce,
+		ctrConf:     ctrConf,
+		hostConf:    hostConf,
+		name:        name,
+		docker:      l.Docker,
+		logger:      l.Logger,
+		uid:         l.uid,
+		gid:         l.gid,
+		appDir:      l.appDir,
+		appMount:    l.appMount,
+		appBind:     l.appBind,
+		appReader:   l.appReader,
+		appSymlinks: l.appSymlinks,
+		appLimits:   l.appLimits,
+		appFilter:   l.appFilter,
+		tarOps:      l.tarOps,
+		appOnce:     l.appOnce,
+		containers:  l.containers,
+		os:          l.os,
+
+		builderImage: l.BuilderImage,
 	}
 	var err error
 	for _, op := range ops {
@@ -70,47 +113,288 @@ func WithArgs(args ...string) func(*Phase) (*Phase, error) {
 
 func WithDaemonAccess() func(*Phase) (*Phase, error) {
 	return func(phase *Phase) (*Phase, error) {
-		phase.ctrConf.User = "root"
-		phase.hostConf.Binds = append(phase.hostConf.Binds, "/var/run/docker.sock:/var/run/docker.sock")
+		phase.ctrConf.User = phase.os.adminUser
+		phase.hostConf.Binds = append(phase.hostConf.Binds, Bind(phase.os.daemonSocket, phase.os.daemonSocket))
 		return phase, nil
 	}
 }
 
 func WithRegistryAccess(repos ...string) func(*Phase) (*Phase, error) {
 	return func(phase *Phase) (*Phase, error) {
-		authHeader, err := auth.BuildEnvVar(authn.DefaultKeychain, repos...)
+		authHeader, err := auth.BuildEnvVar(keychain.Default, repos...)
 		if err != nil {
 			return nil, err
 		}
 		phase.ctrConf.Env = []string{fmt.Sprintf(`CNB_REGISTRY_AUTH=%s`, authHeader)}
-		phase.hostConf.NetworkMode = "host"
+		if !phase.os.isWindows() {
+			// windows containers do not support the host network, but share the host's DNS
+			phase.hostConf.NetworkMode = "host"
+		}
+		return phase, nil
+	}
+}
+
+// WithLifecycleImage runs the phase in an image containing the lifecycle rather than in the builder,
+// so that the binaries of an untrusted builder never get access to the docker daemon or registries
+func WithLifecycleImage(image string) func(*Phase) (*Phase, error) {
+	return func(phase *Phase) (*Phase, error) {
+		phase.ctrConf.Image = image
+		if phase.ctrConf.User == "" && !phase.os.isWindows() {
+			// the lifecycle image has no user of its own owning the layers
+			phase.ctrConf.User = fmt.Sprintf("%d:%d", phase.uid, phase.gid)
+		}
+		return phase, nil
+	}
+}
+
+// WithEnv sets environment variables, in the form 'NAME=VALUE', in the phase's container
+func WithEnv(env ...string) func(*Phase) (*Phase, error) {
+	return func(phase *Phase) (*Phase, error) {
+		phase.ctrConf.Env = append(phase.ctrConf.Env, env...)
+		return phase, nil
+	}
+}
+
+// WithBinds mounts additional bind specs, such as those returned by ParseVolume, into the phase
+func WithBinds(binds ...string) func(*Phase) (*Phase, error) {
+	return func(phase *Phase) (*Phase, error) {
+		phase.hostConf.Binds = append(phase.hostConf.Binds, binds...)
+		return phase, nil
+	}
+}
+
+// WithNetwork runs the phase in the given network mode, or in the daemon's default network when empty
+func WithNetwork(mode string) func(*Phase) (*Phase, error) {
+	return func(phase *Phase) (*Phase, error) {
+		if mode != "" {
+			phase.hostConf.NetworkMode = container.NetworkMode(mode)
+		}
 		return phase, nil
 	}
 }
 
-func (p *Phase) Run(context context.Context) error {
+// WithOutputObserver additionally copies the standard output of the phase to w
+func WithOutputObserver(w io.Writer) func(*Phase) (*Phase, error) {
+	return func(phase *Phase) (*Phase, error) {
+		phase.observers = append(phase.observers, w)
+		return phase, nil
+	}
+}
+
+// WithErrorObserver additionally copies the standard error of the phase to w
+func WithErrorObserver(w io.Writer) func(*Phase) (*Phase, error) {
+	return func(phase *Phase) (*Phase, error) {
+		phase.errObservers = append(phase.errObservers, w)
+		return phase, nil
+	}
+}
+
+// WithBuildpackPrefixes prefixes the lines that the phase logs with the ID of the buildpack that
+// wrote them, see BuildpackWriter
+func WithBuildpackPrefixes() func(*Phase) (*Phase, error) {
+	return func(phase *Phase) (*Phase, error) {
+		phase.buildpackPrefixes = true
+		return phase, nil
+	}
+}
+
+// appTar returns a tar of the app, rewritten from the app reader or created from the app directory,
+// together with a description of its source for errors. It fails when the app directory exceeds the
+// app limits.
+func (p *Phase) appTar() (io.ReadCloser, chan error, string, error) {
+	logger := logging.SubsystemLogger(p.logger, logging.SubsystemFS)
+	ops := append([]func(*archive.TarOptions){
+		archive.WithSymlinks(p.appSymlinks),
+		archive.WithFilter(p.appFilter),
+	}, p.tarOps...)
+	if p.appReader != nil {
+		logger.Debug("Copying app from tar stream to %s in '%s' container", p.os.appDir, p.name)
+		r, errChan := archive.RewriteTarReader(p.appReader, "/"+appDirName, p.uid, p.gid, ops...)
+		return r, errChan, "app from tar stream", nil
+	}
+
+	if err := p.appLimits.check(p.appDir, p.appFilter, p.logger); err != nil {
+		return nil, nil, "", err
+	}
+	logger.Debug("Copying app directory %s to %s in '%s' container", p.appDir, p.os.appDir, p.name)
+	r, errChan := archive.CreateTarReader(p.appDir, "/"+appDirName, p.uid, p.gid, append(ops, archive.WithParallelGzip(appGzipThreshold))...)
+	return r, errChan, "app directory " + p.appDir, nil
+}
+
+// Run runs the phase in a new container. When ctx is canceled, the container is stopped and the
+// error of ctx returned, leaving the removal of the container to Cleanup.
+func (p *Phase) Run(ctx context.Context) error {
 	var err error
-	p.ctr, err = p.docker.ContainerCreate(context, p.ctrConf, p.hostConf, nil, "")
+	p.logDebugConfig()
+	p.ctr, err = p.docker.ContainerCreate(ctx, p.ctrConf, p.hostConf, nil, "")
 	if err != nil {
 		return errors.Wrapf(err, "failed to create '%s' container", p.name)
 	}
+	p.containers.add(p.ctr.ID)
 	p.appOnce.Do(func() {
-		appReader, _ := archive.CreateTarReader(p.appDir, appDir, p.uid, p.gid)
-		if err := p.docker.CopyToContainer(context, p.ctr.ID, "/", appReader, types.CopyToContainerOptions{}); err != nil {
+		if p.appMount != "" {
+			err = p.copyMountedApp(ctx)
+			return
+		}
+		var (
+			appReader io.ReadCloser
+			errChan   chan error
+			source    string
+		)
+		if appReader, errChan, source, err = p.appTar(); err != nil {
+			return
+		}
+		if err = p.docker.CopyToContainer(ctx, p.ctr.ID, p.os.root, appReader, types.CopyToContainerOptions{}); err != nil {
+			// stop writing the tar, which may otherwise block on the pipe
+			appReader.Close()
 			err = errors.Wrapf(err, "failed to copy files to '%s' container", p.name)
+			return
+		}
+		if err = <-errChan; err != nil {
+			err = errors.Wrapf(err, "failed to create tar of %s", source)
 		}
 	})
 	if err != nil {
 		return errors.Wrapf(err, "run %s container", p.name)
 	}
-	return p.docker.RunContainer(
-		context,
-		p.ctr.ID,
-		p.logger.VerboseWriter().WithPrefix(p.name),
-		p.logger.VerboseErrorWriter().WithPrefix(p.name),
+	stdout := logging.VerboseWriter(p.logger, p.name)
+	stderr := logging.VerboseErrorWriter(p.logger, p.name)
+	if p.buildpackPrefixes {
+		bpStdout, bpStderr := NewBuildpackWriters(stdout, stderr)
+		defer bpStdout.Flush()
+		defer bpStderr.Flush()
+		stdout, stderr = bpStdout, bpStderr
+	}
+	if len(p.observers) > 0 {
+		stdout = io.MultiWriter(append([]io.Writer{stdout}, p.observers...)...)
+	}
+	if len(p.errObservers) > 0 {
+		stderr = io.MultiWriter(append([]io.Writer{stderr}, p.errObservers...)...)
+	}
+	err = p.docker.RunContainer(ctx, p.ctr.ID, stdout, stderr)
+	if ctx.Err() != nil {
+		p.stop()
+		return errors.Wrapf(ctx.Err(), "run %s container", p.name)
+	}
+	// a failed phase may still have written to the cache
+	if p.cacheBind != "" {
+		if chownErr := p.chownCacheDir(ctx); chownErr != nil && err == nil {
+			err = chownErr
+		}
+	}
+	return err
+}
+
+// copyMountedApp copies the app directory, bind mounted read-only into a container of the builder
+// image, into the app volume, which is much faster than sending a tar of a large app directory to
+// the docker daemon. The copies belong to the builder user, so that buildpacks can write to them.
+func (p *Phase) copyMountedApp(ctx context.Context) error {
+	logger := logging.SubsystemLogger(p.logger, logging.SubsystemFS)
+	logger.Debug("Copying mounted app directory %s to %s before running '%s' container", p.appMount, p.os.appDir, p.name)
+	output, err := p.runAdminShell(ctx,
+		fmt.Sprintf("cp -R -p %s/. %s && chown -R %d:%d %s", p.os.mountedAppDir, p.os.appDir, p.uid, p.gid, p.os.appDir),
+		Bind(p.appMount, p.os.mountedAppDir, "ro"), p.appBind,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy mounted app directory %s: %s", p.appMount, output)
+	}
+	return nil
+}
+
+// chownCacheDir hands the files that the phase left in the host directory of the cache, owned by
+// the admin user, to the user running pack. Nothing is changed when pack runs as root, or on hosts
+// without uids.
+func (p *Phase) chownCacheDir(ctx context.Context) error {
+	uid, gid := os.Getuid(), os.Getgid()
+	if uid <= 0 {
+		return nil
+	}
+	logger := logging.SubsystemLogger(p.logger, logging.SubsystemFS)
+	logger.Debug("Changing the owner of cache directory %s to %d:%d after running '%s' container", p.cacheBind, uid, gid, p.name)
+	output, err := p.runAdminShell(ctx,
+		fmt.Sprintf("chown -R %d:%d %s", uid, gid, p.os.cacheDir),
+		Bind(p.cacheBind, p.os.cacheDir),
 	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to change the owner of cache directory %s: %s", p.cacheBind, output)
+	}
+	return nil
+}
+
+// runAdminShell runs a shell command as the admin user in a container of the builder image with the
+// given binds, returning its combined output
+func (p *Phase) runAdminShell(ctx context.Context, cmd string, binds ...string) (string, error) {
+	ctr, err := p.docker.ContainerCreate(ctx, &container.Config{
+		Image:      p.builderImage,
+		User:       p.os.adminUser,
+		Entrypoint: []string{"/bin/sh", "-c"},
+		Cmd:        []string{cmd},
+		Labels:     map[string]string{"author": "pack"},
+	}, &container.HostConfig{
+		Binds: binds,
+	}, nil, "")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create container")
+	}
+	p.containers.add(ctr.ID)
+	defer func() {
+		removeCtx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+		if err := p.docker.ContainerRemove(removeCtx, ctr.ID, types.ContainerRemoveOptions{Force: true}); err == nil {
+			p.containers.remove(ctr.ID)
+		}
+	}()
+	var output bytes.Buffer
+	err = p.docker.RunContainer(ctx, ctr.ID, &output, &output)
+	return strings.TrimSpace(output.String()), err
+}
+
+// stop stops the container of a canceled phase, which keeps running when the build no longer waits for it
+func (p *Phase) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	timeout := stopTimeout
+	if err := p.docker.ContainerStop(ctx, p.ctr.ID, &timeout); err != nil {
+		p.logger.Debug("Failed to stop '%s' container: %s", p.name, err)
+	}
+}
+
+func (p *Phase) logDebugConfig() {
+	if !logging.IsDebugEnabled(p.logger) {
+		return
+	}
+	ctrConf := *p.ctrConf
+	ctrConf.Env = redactEnv(p.ctrConf.Env)
+	if b, err := json.Marshal(ctrConf); err == nil {
+		p.logger.Debug("Container config for '%s': %s", p.name, b)
+	}
+	if b, err := json.Marshal(p.hostConf); err == nil {
+		p.logger.Debug("Host config for '%s': %s", p.name, b)
+	}
+	p.logger.Debug("Running '%s' with args: %s", p.name, strings.Join(p.ctrConf.Cmd, " "))
+}
+
+var sensitiveEnv = []string{"CNB_REGISTRY_AUTH"}
+
+func redactEnv(env []string) []string {
+	redacted := make([]string, 0, len(env))
+	for _, e := range env {
+		for _, key := range sensitiveEnv {
+			if strings.HasPrefix(e, key+"=") {
+				e = key + "=<redacted>"
+			}
+		}
+		redacted = append(redacted, e)
+	}
+	return redacted
 }
 
 func (p *Phase) Cleanup() error {
-	return p.docker.ContainerRemove(context.Background(), p.ctr.ID, types.ContainerRemoveOptions{Force: true})
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	if err := p.docker.ContainerRemove(ctx, p.ctr.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+		return err
+	}
+	p.containers.remove(p.ctr.ID)
+	return nil
 }
@@ -1,61 +1,71 @@
 package build
 
-const (
-	layersDir     = "/layers"
-	buildpacksDir = "/buildpacks"
-	platformDir   = "/platform"
-	orderPath     = "/buildpacks/order.toml"
-	groupPath     = `/layers/group.toml`
-	planPath      = "/layers/plan.toml"
-	appDir        = "/workspace"
-)
+import "path/filepath"
 
 func (l *Lifecycle) NewDetect() (*Phase, error) {
-	 return l.NewPhase(
+	return l.NewPhase(
 		"detector",
+		WithBinds(l.volumes...),
+		WithNetwork(l.network),
+		WithEnv(l.proxyEnv...),
 		WithArgs(
-			"-buildpacks", buildpacksDir,
-			"-order", orderPath,
-			"-group", groupPath,
-			"-plan", planPath,
-			"-app", appDir,
+			"-buildpacks", l.os.buildpacksDir,
+			"-order", l.os.orderPath,
+			"-group", l.os.groupPath,
+			"-plan", l.os.planPath,
+			"-app", l.os.appDir,
 		),
 	)
 }
 
-func (l *Lifecycle) NewRestore(cacheImage string) (*Phase, error) {
+// CacheConfig is where the restorer, cacher and creator keep the cache of a build
+type CacheConfig struct {
+	// Image is the cache image, at a registry when Remote or else in the docker daemon
+	Image  string
+	Remote bool
+	// Dir, when set, is the volume or host directory the cache is kept in rather than an image,
+	// which the phases mount
+	Dir string
+}
+
+func (l *Lifecycle) NewRestore(cache CacheConfig, ops ...func(*Phase) (*Phase, error)) (*Phase, error) {
 	return l.NewPhase(
 		"restorer",
-		WithDaemonAccess(),
-		WithArgs(
-			"-image", cacheImage,
-			"-group", groupPath,
-			"-layers", layersDir,
-		),
+		append([]func(*Phase) (*Phase, error){
+			cacheAccess(cache),
+			WithArgs(append(l.cacheArgs(cache, "-path", "-image"),
+				"-group", l.os.groupPath,
+				l.layersFlag(), l.os.layersDir,
+			)...),
+		}, ops...)...,
 	)
 }
 
-func (l *Lifecycle) NewAnalyze(repoName string, publish bool) (*Phase, error) {
+func (l *Lifecycle) NewAnalyze(repoName string, publish bool, ops ...func(*Phase) (*Phase, error)) (*Phase, error) {
 	if publish {
 		return l.NewPhase(
 			"analyzer",
-			WithRegistryAccess(repoName),
-			WithArgs(
-				"-layers", layersDir,
-				"-group", groupPath,
-				repoName,
-			),
+			append([]func(*Phase) (*Phase, error){
+				WithRegistryAccess(repoName),
+				WithArgs(
+					l.layersFlag(), l.os.layersDir,
+					"-group", l.os.groupPath,
+					repoName,
+				),
+			}, ops...)...,
 		)
 	} else {
 		return l.NewPhase(
 			"analyzer",
-			WithDaemonAccess(),
-			WithArgs(
-				"-layers", layersDir,
-				"-group", groupPath,
-				"-daemon",
-				repoName,
-			),
+			append([]func(*Phase) (*Phase, error){
+				WithDaemonAccess(),
+				WithArgs(
+					l.layersFlag(), l.os.layersDir,
+					"-group", l.os.groupPath,
+					"-daemon",
+					repoName,
+				),
+			}, ops...)...,
 		)
 	}
 }
@@ -63,55 +73,150 @@ func (l *Lifecycle) NewAnalyze(repoName string, publish bool) (*Phase, error) {
 func (l *Lifecycle) NewBuild() (*Phase, error) {
 	return l.NewPhase(
 		"builder",
+		WithBinds(l.volumes...),
+		WithNetwork(l.network),
+		WithEnv(l.proxyEnv...),
+		WithBuildpackPrefixes(),
+		WithArgs(l.buildpackLogArgs()...),
 		WithArgs(
-			"-buildpacks", buildpacksDir,
-			"-layers", layersDir,
-			"-app", appDir,
-			"-group", groupPath,
-			"-plan", planPath,
-			"-platform", platformDir,
+			"-buildpacks", l.os.buildpacksDir,
+			l.layersFlag(), l.os.layersDir,
+			"-app", l.os.appDir,
+			"-group", l.os.groupPath,
+			"-plan", l.os.planPath,
+			"-platform", l.os.platformDir,
 		),
 	)
 }
 
-func (l *Lifecycle) NewExport(repoName, runImage string, publish bool) (*Phase, error) {
-	if publish {
-		return l.NewPhase(
-			"exporter",
-			WithRegistryAccess(repoName, runImage),
-			WithArgs(
-				"-image", runImage,
-				"-layers", layersDir,
-				"-app", appDir,
-				"-group", groupPath,
-				repoName,
-			),
-		)
-	} else {
-		return l.NewPhase(
-			"exporter",
-			WithDaemonAccess(),
-			WithArgs(
-				"-image", runImage,
-				"-layers", layersDir,
-				"-app", appDir,
-				"-group", groupPath,
-				"-daemon",
-				repoName,
-			),
-		)
+// NewExport exports the app image as repoName and the additional tags of the lifecycle. Published
+// images are pushed to the registry of each tag, with the credentials of each.
+func (l *Lifecycle) NewExport(repoName, runImage string, publish bool, ops ...func(*Phase) (*Phase, error)) (*Phase, error) {
+	tags := l.exportTags()
+	args := append([]string{
+		"-image", runImage,
+		l.layersFlag(), l.os.layersDir,
+		"-app", l.os.appDir,
+		"-group", l.os.groupPath,
+	}, l.processTypeArgs()...)
+	access := WithRegistryAccess(append([]string{repoName, runImage}, tags...)...)
+	if !publish {
+		args = append(args, "-daemon")
+		access = WithDaemonAccess()
 	}
+	return l.NewPhase(
+		"exporter",
+		append([]func(*Phase) (*Phase, error){
+			access,
+			WithArgs(append(append(args, repoName), tags...)...),
+		}, ops...)...,
+	)
 }
 
-
-func (l *Lifecycle) NewCache(cacheImage string) (*Phase, error) {
+func (l *Lifecycle) NewCache(cache CacheConfig, ops ...func(*Phase) (*Phase, error)) (*Phase, error) {
 	return l.NewPhase(
 		"cacher",
-		WithDaemonAccess(),
-		WithArgs(
-			"-image", cacheImage,
-			"-group", groupPath,
-			"-layers", layersDir,
-		),
+		append([]func(*Phase) (*Phase, error){
+			cacheAccess(cache),
+			WithArgs(append(l.cacheArgs(cache, "-path", "-image"),
+				"-group", l.os.groupPath,
+				l.layersFlag(), l.os.layersDir,
+			)...),
+		}, ops...)...,
+	)
+}
+
+// NewCreate runs detect, restore, analyze, build and export in a single container, which lifecycles
+// supporting it (see SupportsCreator) start much faster than a container per phase. The creator has
+// access to the docker daemon or registries, so it only runs in trusted builders. It reaches cache
+// images at registries only, so a cache image in the daemon is kept in a volume of the same name.
+func (l *Lifecycle) NewCreate(repoName, runImage string, cache CacheConfig, publish, clearCache bool, ops ...func(*Phase) (*Phase, error)) (*Phase, error) {
+	if cache.Dir == "" && !cache.Remote {
+		cache = CacheConfig{Dir: cache.Image}
+	}
+	args := append([]string{
+		"-buildpacks", l.os.buildpacksDir,
+		"-order", l.os.orderPath,
+		"-app", l.os.appDir,
+		l.layersFlag(), l.os.layersDir,
+		"-platform", l.os.platformDir,
+		"-run-image", runImage,
+	}, l.cacheArgs(cache, "-cache-dir", "-cache-image")...)
+	if clearCache {
+		args = append(args, "-skip-restore")
+	}
+	args = append(args, l.processTypeArgs()...)
+	args = append(args, l.buildpackLogArgs()...)
+	tags := l.exportTags()
+	for _, tag := range tags {
+		args = append(args, "-tag", tag)
+	}
+	if !publish {
+		args = append(args, "-daemon")
+	}
+
+	// the creator reaches the images at registries, or the app image in the daemon when not publishing
+	var registryImages []string
+	if publish {
+		registryImages = append([]string{repoName, runImage}, tags...)
+	}
+	if cache.Remote {
+		registryImages = append(registryImages, cache.Image)
+	}
+	var access []func(*Phase) (*Phase, error)
+	if len(registryImages) > 0 {
+		access = append(access, WithRegistryAccess(registryImages...))
+	}
+	if !publish {
+		access = append(access, WithDaemonAccess())
+	}
+	if cache.Dir != "" {
+		access = append(access, withCacheDir(cache.Dir))
+	}
+	return l.NewPhase(
+		"creator",
+		append(append(access,
+			WithBinds(l.volumes...),
+			WithNetwork(l.network),
+			WithEnv(l.proxyEnv...),
+			WithBuildpackPrefixes(),
+			WithArgs(append(args, repoName)...),
+		), ops...)...,
 	)
 }
+
+// cacheAccess gives the restorer and cacher access to a cache image at a registry or in the daemon,
+// or mounts the directory of the cache
+func cacheAccess(cache CacheConfig) func(*Phase) (*Phase, error) {
+	switch {
+	case cache.Dir != "":
+		return withCacheDir(cache.Dir)
+	case cache.Remote:
+		return WithRegistryAccess(cache.Image)
+	}
+	return WithDaemonAccess()
+}
+
+// withCacheDir mounts the volume or host directory of a cache at the cache directory of the phase.
+// The phase runs as the admin user, as the daemon creates missing volumes and host directories
+// owned by it. What it leaves in a host directory is handed to the user running pack afterwards,
+// who could otherwise not clear the cache.
+func withCacheDir(dir string) func(*Phase) (*Phase, error) {
+	return func(phase *Phase) (*Phase, error) {
+		phase.ctrConf.User = phase.os.adminUser
+		phase.hostConf.Binds = append(phase.hostConf.Binds, Bind(dir, phase.os.cacheDir))
+		if filepath.IsAbs(dir) && !phase.os.isWindows() {
+			phase.cacheBind = dir
+		}
+		return phase, nil
+	}
+}
+
+// cacheArgs returns the arguments of a phase naming its cache, with dirFlag when it is kept in a
+// directory or else imageFlag
+func (l *Lifecycle) cacheArgs(cache CacheConfig, dirFlag, imageFlag string) []string {
+	if cache.Dir != "" {
+		return []string{dirFlag, l.os.cacheDir}
+	}
+	return []string{imageFlag, cache.Image}
+}
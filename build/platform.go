@@ -0,0 +1,24 @@
+package build
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// PlatformFilePath returns the cleaned path of a file written into the platform directory, which
+// is relative to it, such as 'maven/settings.xml'. Files under 'env' are rejected, as the
+// environment variables of the build are written there.
+func PlatformFilePath(name string) (string, error) {
+	p := path.Clean(strings.Replace(name, `\`, "/", -1))
+	if path.IsAbs(p) || p == "." || p == ".." || strings.HasPrefix(p, "../") {
+		return "", errors.Errorf("platform file %s must be relative to the platform directory", style.Symbol(name))
+	}
+	if p == "env" || strings.HasPrefix(p, "env/") {
+		return "", errors.Errorf("platform file %s cannot be written under 'env', set environment variables of the build instead", style.Symbol(name))
+	}
+	return p, nil
+}
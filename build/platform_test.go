@@ -0,0 +1,44 @@
+package build_test
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestPlatformFilePath(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "PlatformFilePath", testPlatformFilePath, spec.Report(report.Terminal{}))
+}
+
+func testPlatformFilePath(t *testing.T, when spec.G, it spec.S) {
+	it("cleans paths relative to the platform directory", func() {
+		for name, expected := range map[string]string{
+			"settings.xml":             "settings.xml",
+			"maven/./settings.xml":     "maven/settings.xml",
+			`maven\settings.xml`:       "maven/settings.xml",
+			"maven/../other/some.conf": "other/some.conf",
+		} {
+			p, err := build.PlatformFilePath(name)
+			h.AssertNil(t, err)
+			h.AssertEq(t, p, expected)
+		}
+	})
+
+	it("rejects paths outside the platform directory", func() {
+		for _, name := range []string{"/etc/settings.xml", "../settings.xml", "maven/../../settings.xml", "."} {
+			_, err := build.PlatformFilePath(name)
+			h.AssertError(t, err, "must be relative to the platform directory")
+		}
+	})
+
+	it("rejects paths under the env directory", func() {
+		_, err := build.PlatformFilePath("env/SOME_VAR")
+		h.AssertError(t, err, "platform file 'env/SOME_VAR' cannot be written under 'env'")
+	})
+}
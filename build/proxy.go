@@ -0,0 +1,38 @@
+package build
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// proxyVars are the environment variables configuring proxies, which tools in build containers
+// read in either upper or lower case
+var proxyVars = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"}
+
+// ProxyEnv returns the environment of the detect and build containers configuring proxies, so that
+// buildpacks behind a proxy can fetch dependencies. Values default to those of the host, in either
+// case, and are overridden by those of overrides, keyed by upper case names, where an empty value
+// unsets the variable. Each variable is set in both cases.
+func ProxyEnv(overrides map[string]string) []string {
+	values := map[string]string{}
+	for _, name := range proxyVars {
+		if value, ok := overrides[name]; ok {
+			values[name] = value
+		} else if value := os.Getenv(name); value != "" {
+			values[name] = value
+		} else if value := os.Getenv(strings.ToLower(name)); value != "" {
+			values[name] = value
+		}
+	}
+
+	var env []string
+	for name, value := range values {
+		if value == "" {
+			continue
+		}
+		env = append(env, name+"="+value, strings.ToLower(name)+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
@@ -0,0 +1,69 @@
+package build_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestProxy(t *testing.T) {
+	spec.Run(t, "Proxy", testProxy, spec.Report(report.Terminal{}))
+}
+
+func testProxy(t *testing.T, when spec.G, it spec.S) {
+	when("#ProxyEnv", func() {
+		var saved map[string]string
+
+		it.Before(func() {
+			saved = map[string]string{}
+			for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"} {
+				saved[name] = os.Getenv(name)
+				h.AssertNil(t, os.Unsetenv(name))
+			}
+		})
+
+		it.After(func() {
+			for name, value := range saved {
+				if value != "" {
+					h.AssertNil(t, os.Setenv(name, value))
+				}
+			}
+		})
+
+		it("propagates the proxy variables of the host in both cases", func() {
+			h.AssertNil(t, os.Setenv("HTTP_PROXY", "http://proxy.example.com:3128"))
+			h.AssertNil(t, os.Setenv("no_proxy", "localhost"))
+
+			h.AssertEq(t, build.ProxyEnv(nil), []string{
+				"HTTP_PROXY=http://proxy.example.com:3128",
+				"NO_PROXY=localhost",
+				"http_proxy=http://proxy.example.com:3128",
+				"no_proxy=localhost",
+			})
+		})
+
+		it("applies overrides, where empty values unset variables", func() {
+			h.AssertNil(t, os.Setenv("HTTP_PROXY", "http://proxy.example.com:3128"))
+			h.AssertNil(t, os.Setenv("NO_PROXY", "localhost"))
+
+			h.AssertEq(t, build.ProxyEnv(map[string]string{
+				"HTTPS_PROXY": "http://other-proxy.example.com:3128",
+				"NO_PROXY":    "",
+			}), []string{
+				"HTTPS_PROXY=http://other-proxy.example.com:3128",
+				"HTTP_PROXY=http://proxy.example.com:3128",
+				"http_proxy=http://proxy.example.com:3128",
+				"https_proxy=http://other-proxy.example.com:3128",
+			})
+		})
+
+		it("is empty without proxies", func() {
+			h.AssertEq(t, len(build.ProxyEnv(nil)), 0)
+		})
+	})
+}
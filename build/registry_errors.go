@@ -0,0 +1,34 @@
+package build
+
+import (
+	"regexp"
+	"sync"
+)
+
+// transientRegistryError matches the errors the lifecycle logs when a registry is briefly
+// unavailable: rate limits, server errors, and connections dropped or timed out
+var transientRegistryError = regexp.MustCompile(`(?i)(429 Too Many Requests|TOOMANYREQUESTS|status(?: code)?:? 5\d\d|5\d\d (?:Internal Server Error|Bad Gateway|Service Unavailable|Gateway Timeout)|connection reset by peer|i/o timeout|TLS handshake timeout)`)
+
+// RegistryErrorDetector observes the output of a phase, such as with WithErrorObserver, to tell
+// whether it failed because of a transient registry error, in which case running it again may
+// succeed
+type RegistryErrorDetector struct {
+	mu        sync.Mutex
+	transient bool
+}
+
+func (d *RegistryErrorDetector) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.transient && transientRegistryError.Match(p) {
+		d.transient = true
+	}
+	return len(p), nil
+}
+
+// Transient reports whether the output had a transient registry error
+func (d *RegistryErrorDetector) Transient() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.transient
+}
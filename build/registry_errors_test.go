@@ -0,0 +1,40 @@
+package build_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestRegistryErrorDetector(t *testing.T) {
+	spec.Run(t, "RegistryErrorDetector", testRegistryErrorDetector, spec.Report(report.Terminal{}))
+}
+
+func testRegistryErrorDetector(t *testing.T, when spec.G, it spec.S) {
+	it("detects transient registry errors", func() {
+		for _, line := range []string{
+			"Error: failed to export: PUT https://registry.example.com/v2/some/app/manifests/latest: TOOMANYREQUESTS: rate limit exceeded",
+			"Error: failed to export: unexpected status code 429 Too Many Requests",
+			"Error: failed to analyze: GET https://registry.example.com/v2/: unexpected status code 503 Service Unavailable",
+			"Error: failed to export: Put https://registry.example.com/v2/some/app/blobs/uploads/: read tcp 10.0.0.1:443: read: connection reset by peer",
+			"Error: failed to analyze: Get https://registry.example.com/v2/: net/http: TLS handshake timeout",
+		} {
+			detector := &build.RegistryErrorDetector{}
+			fmt.Fprintln(detector, "[exporter] *** Images:")
+			fmt.Fprintln(detector, line)
+			h.AssertEq(t, detector.Transient(), true)
+		}
+	})
+
+	it("ignores other errors", func() {
+		detector := &build.RegistryErrorDetector{}
+		fmt.Fprintln(detector, "Error: failed to export: UNAUTHORIZED: authentication required")
+		fmt.Fprintln(detector, "Error: failed to analyze: unexpected status code 404 Not Found")
+		h.AssertEq(t, detector.Transient(), false)
+	})
+}
@@ -0,0 +1,67 @@
+package build
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SourceDigest returns the sha256 digest of the files of appDir copied into builds, as selected by
+// AppFilter. It covers the path, type, executable bit and contents of each file and the targets of
+// symlinks, but not modification times, so that fresh checkouts of the same source have the same
+// digest.
+func SourceDigest(appDir string, include, exclude []string) (string, error) {
+	filter, err := AppFilter(appDir, include, exclude)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	err = filepath.Walk(appDir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(appDir, file)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if filter != nil && !filter(rel, fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case fi.IsDir():
+			fmt.Fprintf(hash, "dir %s\x00", rel)
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(file)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "symlink %s %s\x00", rel, filepath.ToSlash(target))
+		case fi.Mode().IsRegular():
+			// the length is written first, so that the contents cannot be mistaken for the next file
+			fmt.Fprintf(hash, "file %s %t %d\x00", rel, fi.Mode()&0111 != 0, fi.Size())
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(hash, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
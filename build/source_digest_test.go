@@ -0,0 +1,69 @@
+package build_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestSourceDigest(t *testing.T) {
+	spec.Run(t, "SourceDigest", testSourceDigest, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testSourceDigest(t *testing.T, when spec.G, it spec.S) {
+	when("#SourceDigest", func() {
+		var appDir string
+
+		it.Before(func() {
+			var err error
+			appDir, err = ioutil.TempDir("", "pack.source-digest")
+			h.AssertNil(t, err)
+			h.AssertNil(t, os.Mkdir(filepath.Join(appDir, "logs"), 0755))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "app.go"), []byte("package main"), 0644))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "logs", "app.log"), []byte("some log"), 0644))
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.RemoveAll(appDir))
+		})
+
+		digest := func(exclude ...string) string {
+			digest, err := build.SourceDigest(appDir, nil, exclude)
+			h.AssertNil(t, err)
+			return digest
+		}
+
+		it("changes with the contents of files but not their modification time", func() {
+			before := digest()
+			h.AssertContains(t, before, "sha256:")
+
+			later := time.Now().Add(time.Hour)
+			h.AssertNil(t, os.Chtimes(filepath.Join(appDir, "app.go"), later, later))
+			h.AssertEq(t, digest(), before)
+
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "app.go"), []byte("package app"), 0644))
+			h.AssertNotEq(t, digest(), before)
+		})
+
+		it("ignores excluded files", func() {
+			before := digest("logs")
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "logs", "app.log"), []byte("another log"), 0644))
+			h.AssertEq(t, digest("logs"), before)
+		})
+
+		it("ignores files in .packignore", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, build.PackIgnoreFile), []byte("*.log\n"), 0644))
+			before := digest()
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "logs", "app.log"), []byte("another log"), 0644))
+			h.AssertEq(t, digest(), before)
+		})
+	})
+}
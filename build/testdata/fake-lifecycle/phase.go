@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/buildpack/lifecycle/image/auth"
 	"github.com/docker/docker/api/types"
@@ -17,6 +18,10 @@ import (
 func main() {
 	fmt.Println("running some-lifecycle-phase")
 	fmt.Printf("received args %+v\n", os.Args)
+	_, registryAccess := os.LookupEnv("CNB_REGISTRY_AUTH")
+	fmt.Printf("registry access %t\n", registryAccess)
+	_, err := os.Stat("/var/run/docker.sock")
+	fmt.Printf("daemon access %t\n", err == nil)
 	if len(os.Args) > 3 && os.Args[1] == "write" {
 		testWrite(os.Args[2], os.Args[3])
 	}
@@ -38,6 +43,13 @@ func main() {
 	if len(os.Args) > 1 && os.Args[1] == "buildpacks" {
 		testBuildpacks()
 	}
+	if filepath.Base(os.Args[0]) == "cacher" && len(os.Args) > 2 && os.Args[1] == "-path" {
+		testCache(os.Args[2])
+	}
+	if len(os.Args) > 1 && os.Args[1] == "sleep" {
+		fmt.Println("sleep test")
+		time.Sleep(time.Hour)
+	}
 }
 
 func testWrite(filename, contents string) {
@@ -55,6 +67,19 @@ func testWrite(filename, contents string) {
 	}
 }
 
+func testCache(dir string) {
+	fmt.Println("cache test")
+	layerDir := filepath.Join(dir, "some-layer")
+	if err := os.MkdirAll(layerDir, 0755); err != nil {
+		fmt.Printf("failed to create %s: %s\n", layerDir, err)
+		os.Exit(11)
+	}
+	if err := ioutil.WriteFile(filepath.Join(layerDir, "some-file"), []byte("some-contents"), 0644); err != nil {
+		fmt.Printf("failed to write to %s: %s\n", layerDir, err)
+		os.Exit(12)
+	}
+}
+
 func testDaemon() {
 	fmt.Println("daemon test")
 	cli, err := dockercli.NewClientWithOpts(dockercli.FromEnv, dockercli.WithVersion("1.38"))
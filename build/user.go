@@ -0,0 +1,43 @@
+package build
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/buildpack/lifecycle/image"
+	"github.com/pkg/errors"
+)
+
+// BuilderUser returns the uid and gid that run the build phases of the builder, from its CNB_USER_ID
+// and CNB_GROUP_ID, or else from imageUser, the USER of the builder image. A USER without a group
+// runs with gid 0, as docker does for users it cannot look up.
+func BuilderUser(builder image.Image, imageUser string) (int, int, error) {
+	sUID, err := builder.Env("CNB_USER_ID")
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "reading builder env variables")
+	}
+	sGID, err := builder.Env("CNB_GROUP_ID")
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "reading builder env variables")
+	}
+	if sUID == "" && sGID == "" {
+		if imageUser == "" {
+			return 0, 0, errors.New("builder has neither CNB_USER_ID and CNB_GROUP_ID nor a USER -- set them in the builder or give the user and group ids of the build")
+		}
+		sUID, sGID = imageUser, "0"
+		if parts := strings.SplitN(imageUser, ":", 2); len(parts) == 2 {
+			sUID, sGID = parts[0], parts[1]
+		}
+	}
+
+	var uid, gid int
+	uid, err = strconv.Atoi(sUID)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "parsing pack uid: %s -- users given by name cannot be looked up, give the user and group ids of the build", sUID)
+	}
+	gid, err = strconv.Atoi(sGID)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "parsing pack gid: %s", sGID)
+	}
+	return uid, gid, nil
+}
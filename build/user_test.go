@@ -0,0 +1,83 @@
+package build_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestUser(t *testing.T) {
+	spec.Run(t, "User", testUser, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testUser(t *testing.T, when spec.G, it spec.S) {
+	when("#BuilderUser", func() {
+		var (
+			mockController *gomock.Controller
+			mockBuilder    *mocks.MockImage
+		)
+
+		it.Before(func() {
+			mockController = gomock.NewController(t)
+			mockBuilder = mocks.NewMockImage(mockController)
+		})
+
+		it.After(func() {
+			mockController.Finish()
+		})
+
+		givenEnv := func(uid, gid string) {
+			mockBuilder.EXPECT().Env("CNB_USER_ID").Return(uid, nil)
+			mockBuilder.EXPECT().Env("CNB_GROUP_ID").Return(gid, nil)
+		}
+
+		it("uses the user and group of the builder env", func() {
+			givenEnv("1000", "1001")
+
+			uid, gid, err := build.BuilderUser(mockBuilder, "2000:2001")
+			h.AssertNil(t, err)
+			h.AssertEq(t, uid, 1000)
+			h.AssertEq(t, gid, 1001)
+		})
+
+		when("the builder env has no user", func() {
+			it("uses the user and group of the image", func() {
+				givenEnv("", "")
+
+				uid, gid, err := build.BuilderUser(mockBuilder, "2000:2001")
+				h.AssertNil(t, err)
+				h.AssertEq(t, uid, 2000)
+				h.AssertEq(t, gid, 2001)
+			})
+
+			it("uses group 0 when the image user has no group", func() {
+				givenEnv("", "")
+
+				uid, gid, err := build.BuilderUser(mockBuilder, "2000")
+				h.AssertNil(t, err)
+				h.AssertEq(t, uid, 2000)
+				h.AssertEq(t, gid, 0)
+			})
+
+			it("fails without an image user", func() {
+				givenEnv("", "")
+
+				_, _, err := build.BuilderUser(mockBuilder, "")
+				h.AssertError(t, err, "builder has neither CNB_USER_ID and CNB_GROUP_ID nor a USER")
+			})
+
+			it("fails for user names", func() {
+				givenEnv("", "")
+
+				_, _, err := build.BuilderUser(mockBuilder, "cnb")
+				h.AssertError(t, err, "parsing pack uid: cnb")
+			})
+		})
+	})
+}
@@ -0,0 +1,114 @@
+package build
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api/types/versions"
+
+	"github.com/buildpack/pack/style"
+)
+
+// SupportedPlatformAPIs are the versions of the platform API, between pack and the lifecycle, that
+// the phases are run with
+var SupportedPlatformAPIs = []string{"0.1", "0.2", "0.3"}
+
+// legacyLayersFlagVersion is the first lifecycle version naming the layers directory '-layers'
+// rather than '-launch'
+const legacyLayersFlagVersion = "0.2.0"
+
+// creatorVersion is the first lifecycle version with the creator, see NewCreate
+const creatorVersion = "0.7.0"
+
+// exportTagsVersion is the first lifecycle version exporting additional tags of the app image
+const exportTagsVersion = "0.5.0"
+
+// defaultProcessVersion is the first lifecycle version setting the default process type of the app
+// image
+const defaultProcessVersion = "0.7.0"
+
+// logLevelVersion is the first lifecycle version whose phases take the '-log-level' flag
+const logLevelVersion = "0.4.0"
+
+var lifecycleVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+`)
+
+// CheckLifecycleCompatibility returns an error when builds cannot run the lifecycle of the given
+// version, implementing the given platform API. Either may be empty when a builder does not record it.
+func CheckLifecycleCompatibility(version, platformAPI string) error {
+	if version != "" && !lifecycleVersionPattern.MatchString(version) {
+		return fmt.Errorf("invalid lifecycle version %s, must be of the form <major>.<minor>.<patch>", style.Symbol(version))
+	}
+	if platformAPI == "" {
+		return nil
+	}
+	for _, api := range SupportedPlatformAPIs {
+		if api == platformAPI {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"the lifecycle implements platform API %s, but pack supports platform API %s -- try upgrading pack or using another builder",
+		style.Symbol(platformAPI),
+		strings.Join(SupportedPlatformAPIs, ", "),
+	)
+}
+
+// SupportsCreator reports whether the lifecycle of the given version has the creator, which runs
+// all phases up to export in one container
+func SupportsCreator(version string) bool {
+	return version != "" && versions.GreaterThanOrEqualTo(version, creatorVersion)
+}
+
+// SupportsExportTags reports whether the lifecycle of the given version exports the app image with
+// additional tags, see LifecycleConfig
+func SupportsExportTags(version string) bool {
+	return version != "" && versions.GreaterThanOrEqualTo(version, exportTagsVersion)
+}
+
+// SupportsDefaultProcess reports whether the lifecycle of the given version sets the default process
+// type of the app image, see LifecycleConfig
+func SupportsDefaultProcess(version string) bool {
+	return version != "" && versions.GreaterThanOrEqualTo(version, defaultProcessVersion)
+}
+
+// SupportsLogLevel reports whether the phases of the lifecycle of the given version take a log
+// level, see buildpackLogArgs
+func SupportsLogLevel(version string) bool {
+	return version != "" && versions.GreaterThanOrEqualTo(version, logLevelVersion)
+}
+
+// buildpackLogArgs returns the arguments of the builder and creator logging when each buildpack
+// starts and finishes building, which BuildpackWriter follows the buildpacks by. The lifecycle only
+// logs those lines at debug level.
+func (l *Lifecycle) buildpackLogArgs() []string {
+	if !SupportsLogLevel(l.version) {
+		return nil
+	}
+	return []string{"-log-level", "debug"}
+}
+
+// processTypeArgs returns the arguments of the exporter setting the default process type of the app
+// image, if any
+func (l *Lifecycle) processTypeArgs() []string {
+	if l.process == "" {
+		return nil
+	}
+	return []string{"-process-type", l.process}
+}
+
+// exportTags returns the additional tags of the app image that the exporter applies
+func (l *Lifecycle) exportTags() []string {
+	if !SupportsExportTags(l.version) {
+		return nil
+	}
+	return l.tags
+}
+
+// layersFlag names the layers directory in the arguments of the phases
+func (l *Lifecycle) layersFlag() string {
+	if l.version != "" && versions.LessThan(l.version, legacyLayersFlagVersion) {
+		return "-launch"
+	}
+	return "-layers"
+}
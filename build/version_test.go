@@ -0,0 +1,82 @@
+package build_test
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestVersion(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Version", testVersion, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testVersion(t *testing.T, when spec.G, it spec.S) {
+	when("#CheckLifecycleCompatibility", func() {
+		it("accepts lifecycles implementing a supported platform API", func() {
+			h.AssertNil(t, build.CheckLifecycleCompatibility("0.4.0", "0.2"))
+		})
+
+		it("accepts builders that do not record their lifecycle", func() {
+			h.AssertNil(t, build.CheckLifecycleCompatibility("", ""))
+		})
+
+		it("fails for lifecycles implementing an unsupported platform API", func() {
+			h.AssertError(t, build.CheckLifecycleCompatibility("0.9.0", "0.9"), "the lifecycle implements platform API '0.9', but pack supports platform API 0.1, 0.2, 0.3")
+		})
+
+		it("fails for invalid lifecycle versions", func() {
+			h.AssertError(t, build.CheckLifecycleCompatibility("latest", ""), "invalid lifecycle version 'latest'")
+		})
+	})
+
+	when("#SupportsCreator", func() {
+		it("is true from the lifecycle version introducing the creator", func() {
+			h.AssertEq(t, build.SupportsCreator("0.7.0"), true)
+			h.AssertEq(t, build.SupportsCreator("0.10.1"), true)
+		})
+
+		it("is false for older or unknown lifecycle versions", func() {
+			h.AssertEq(t, build.SupportsCreator("0.6.1"), false)
+			h.AssertEq(t, build.SupportsCreator(""), false)
+		})
+	})
+	when("#SupportsExportTags", func() {
+		it("is true from the lifecycle version exporting additional tags", func() {
+			h.AssertEq(t, build.SupportsExportTags("0.5.0"), true)
+			h.AssertEq(t, build.SupportsExportTags("0.7.2"), true)
+		})
+
+		it("is false for older or unknown lifecycle versions", func() {
+			h.AssertEq(t, build.SupportsExportTags("0.4.0"), false)
+			h.AssertEq(t, build.SupportsExportTags(""), false)
+		})
+	})
+	when("#SupportsDefaultProcess", func() {
+		it("is true from the lifecycle version setting the default process type", func() {
+			h.AssertEq(t, build.SupportsDefaultProcess("0.7.0"), true)
+			h.AssertEq(t, build.SupportsDefaultProcess("0.9.1"), true)
+		})
+
+		it("is false for older or unknown lifecycle versions", func() {
+			h.AssertEq(t, build.SupportsDefaultProcess("0.6.1"), false)
+			h.AssertEq(t, build.SupportsDefaultProcess(""), false)
+		})
+	})
+	when("#SupportsLogLevel", func() {
+		it("is true from the lifecycle version taking a log level", func() {
+			h.AssertEq(t, build.SupportsLogLevel("0.4.0"), true)
+			h.AssertEq(t, build.SupportsLogLevel("0.7.0"), true)
+		})
+
+		it("is false for older or unknown lifecycle versions", func() {
+			h.AssertEq(t, build.SupportsLogLevel("0.3.0"), false)
+			h.AssertEq(t, build.SupportsLogLevel(""), false)
+		})
+	})
+}
@@ -0,0 +1,117 @@
+package build
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DefaultWatchInterval is how often watched app directories are scanned for changes
+	DefaultWatchInterval = 500 * time.Millisecond
+	// DefaultWatchDebounce is how long app directories must be left unchanged before changes are
+	// reported, so that saving several files triggers one build
+	DefaultWatchDebounce = time.Second
+)
+
+// fileState is what a change to a file is detected by
+type fileState struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+// WatchAppDir reports changes to the files of appDir copied into builds, as selected by AppFilter,
+// once appDir has been left unchanged for debounce. The directory is scanned every interval, rather
+// than watched with inotify, so that it works the same on every OS and for network file systems.
+// Changes made while the last ones are not yet received are reported once. The channel is closed
+// when ctx is done.
+func WatchAppDir(ctx context.Context, appDir string, include, exclude []string, interval, debounce time.Duration) (<-chan struct{}, error) {
+	last, err := scanAppDir(appDir, include, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var changedAt time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				// files being written may be missing for a moment, they are scanned again next time
+				current, err := scanAppDir(appDir, include, exclude)
+				if err != nil {
+					continue
+				}
+				if !sameFiles(last, current) {
+					last = current
+					changedAt = now
+					continue
+				}
+				if !changedAt.IsZero() && now.Sub(changedAt) >= debounce {
+					changedAt = time.Time{}
+					select {
+					case changes <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+func scanAppDir(appDir string, include, exclude []string) (map[string]fileState, error) {
+	// the filter is read each time, as the .packignore file may change too
+	filter, err := AppFilter(appDir, include, exclude)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]fileState{}
+	err = filepath.Walk(appDir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(appDir, file)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if filter != nil && !filter(rel, fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// directories change with the excluded files in them, so only whether they exist matters
+		if fi.IsDir() {
+			files[rel] = fileState{mode: fi.Mode()}
+		} else {
+			files[rel] = fileState{modTime: fi.ModTime(), size: fi.Size(), mode: fi.Mode()}
+		}
+		return nil
+	})
+	return files, err
+}
+
+func sameFiles(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, state := range a {
+		if other, ok := b[file]; !ok || !other.modTime.Equal(state.modTime) || other.size != state.size || other.mode != state.mode {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package build_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestWatch(t *testing.T) {
+	spec.Run(t, "Watch", testWatch, spec.Report(report.Terminal{}))
+}
+
+func testWatch(t *testing.T, when spec.G, it spec.S) {
+	when("#WatchAppDir", func() {
+		const (
+			interval = 10 * time.Millisecond
+			debounce = 50 * time.Millisecond
+		)
+
+		var (
+			appDir string
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+
+		it.Before(func() {
+			var err error
+			appDir, err = ioutil.TempDir("", "pack.watch")
+			h.AssertNil(t, err)
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "app.go"), []byte("package main"), 0644))
+			ctx, cancel = context.WithCancel(context.Background())
+		})
+
+		it.After(func() {
+			cancel()
+			h.AssertNil(t, os.RemoveAll(appDir))
+		})
+
+		received := func(changes <-chan struct{}, within time.Duration) bool {
+			select {
+			case <-changes:
+				return true
+			case <-time.After(within):
+				return false
+			}
+		}
+
+		it("reports changed files once the app dir is left unchanged", func() {
+			changes, err := build.WatchAppDir(ctx, appDir, nil, nil, interval, debounce)
+			h.AssertNil(t, err)
+			h.AssertEq(t, received(changes, 5*debounce), false)
+
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "app.go"), []byte("package main\n\nfunc main() {}"), 0644))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "other.go"), []byte("package main"), 0644))
+			h.AssertEq(t, received(changes, time.Second), true)
+			h.AssertEq(t, received(changes, 5*debounce), false)
+		})
+
+		it("ignores files that are not copied into builds", func() {
+			changes, err := build.WatchAppDir(ctx, appDir, nil, []string{"*.log"}, interval, debounce)
+			h.AssertNil(t, err)
+
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "app.log"), []byte("started"), 0644))
+			h.AssertEq(t, received(changes, 5*debounce), false)
+		})
+
+		it("closes the changes when the context is done", func() {
+			changes, err := build.WatchAppDir(ctx, appDir, nil, nil, interval, debounce)
+			h.AssertNil(t, err)
+
+			cancel()
+			select {
+			case _, ok := <-changes:
+				h.AssertEq(t, ok, false)
+			case <-time.After(time.Second):
+				t.Fatal("changes were not closed")
+			}
+		})
+	})
+}
@@ -0,0 +1,66 @@
+package pack
+
+import (
+	"os"
+
+	"github.com/buildpack/pack/logging"
+)
+
+// logSettings are the settings of the logger of a BuildFactory given by options such as WithVerbose
+type logSettings struct {
+	verbose    bool
+	quiet      bool
+	noColor    bool
+	timestamps bool
+}
+
+func (s logSettings) logger() *logging.Logger {
+	var ops []func(*logging.Logger)
+	if s.quiet {
+		ops = append(ops, logging.WithLevel(logging.LevelError))
+	}
+	if s.noColor {
+		ops = append(ops, logging.WithNoColor())
+	}
+	return logging.NewLogger(os.Stdout, os.Stderr, s.verbose, s.timestamps, ops...)
+}
+
+// withLogSetting applies set to the log settings of the factory, and replaces its logger with a
+// *logging.Logger writing to stdout and stderr with those settings
+func withLogSetting(set func(*logSettings)) func(*BuildFactory) {
+	return func(bf *BuildFactory) {
+		set(&bf.logSettings)
+		bf.Logger = bf.logSettings.logger()
+	}
+}
+
+// WithVerbose logs the output of the phases of builds, like the --verbose flag of pack. Like
+// WithNoColor and WithTimestamps, it replaces the logger of the factory with one writing to stdout
+// and stderr.
+func WithVerbose() func(*BuildFactory) {
+	return withLogSetting(func(s *logSettings) { s.verbose = true })
+}
+
+// WithQuiet only logs errors, taking precedence over WithVerbose. A *logging.Logger of the factory
+// is kept, with its writers, log file and format, and only shows fewer messages. Other loggers are
+// replaced with one writing to stdout and stderr.
+func WithQuiet() func(*BuildFactory) {
+	return func(bf *BuildFactory) {
+		bf.logSettings.quiet = true
+		if logger, ok := bf.Logger.(*logging.Logger); ok && logger != nil {
+			bf.Logger = logger.AtLevel(logging.LevelError)
+			return
+		}
+		bf.Logger = bf.logSettings.logger()
+	}
+}
+
+// WithNoColor logs without color, see logging.WithNoColor
+func WithNoColor() func(*BuildFactory) {
+	return withLogSetting(func(s *logSettings) { s.noColor = true })
+}
+
+// WithTimestamps prefixes each line logged with the time it was logged at
+func WithTimestamps() func(*BuildFactory) {
+	return withLogSetting(func(s *logSettings) { s.timestamps = true })
+}
@@ -0,0 +1,60 @@
+package pack_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBuildFactoryLogging(t *testing.T) {
+	spec.Run(t, "BuildFactoryLogging", testBuildFactoryLogging, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuildFactoryLogging(t *testing.T, when spec.G, it spec.S) {
+	level := func(ops ...func(*pack.BuildFactory)) logging.Level {
+		t.Helper()
+		bf := &pack.BuildFactory{}
+		for _, op := range ops {
+			op(bf)
+		}
+		logger, ok := bf.Logger.(*logging.Logger)
+		h.AssertEq(t, ok, true)
+		return logger.Level()
+	}
+
+	it("replaces the logger of the factory", func() {
+		h.AssertEq(t, level(pack.WithNoColor(), pack.WithTimestamps()), logging.LevelInfo)
+	})
+
+	it("logs the output of the phases when verbose", func() {
+		h.AssertEq(t, level(pack.WithVerbose()), logging.LevelDebug)
+	})
+
+	it("keeps a *logging.Logger of the factory when quiet, with its log file and format", func() {
+		var out, file bytes.Buffer
+		bf := &pack.BuildFactory{
+			Logger: logging.NewLogger(&out, &out, true, false, logging.WithLogFile(&file), logging.WithFormat(logging.JSON)),
+		}
+		pack.WithQuiet()(bf)
+
+		logger, ok := bf.Logger.(*logging.Logger)
+		h.AssertEq(t, ok, true)
+		h.AssertEq(t, logger.Level(), logging.LevelError)
+		h.AssertEq(t, logger.Format(), logging.JSON)
+		logger.Info("some message")
+		h.AssertEq(t, out.String(), "")
+		h.AssertContains(t, file.String(), "some message")
+	})
+
+	it("only logs errors when quiet, even when verbose", func() {
+		h.AssertEq(t, level(pack.WithQuiet()), logging.LevelError)
+		h.AssertEq(t, level(pack.WithVerbose(), pack.WithQuiet()), logging.LevelError)
+		h.AssertEq(t, level(pack.WithQuiet(), pack.WithVerbose()), logging.LevelError)
+	})
+}
@@ -0,0 +1,221 @@
+package pack
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/style"
+)
+
+// BuildResult identifies the app image a build produced, so that CI can deploy or promote exactly
+// that image
+type BuildResult struct {
+	// Image is the name the app image was built as
+	Image string `json:"image"`
+	// ImageID is the ID of the image in the docker daemon, which is empty when it was published
+	ImageID string `json:"imageId,omitempty"`
+	// Digest is the digest of the manifest of the image in its registry, which is empty for images in
+	// the docker daemon that were never pushed
+	Digest string `json:"digest,omitempty"`
+	// Tags are the names of the image in the docker daemon, or the name it was published as
+	Tags []string `json:"tags,omitempty"`
+	// Size is the size in bytes of an image in the docker daemon
+	Size int64 `json:"size,omitempty"`
+	// Builder and RunImage are the images the app image was built with and based on
+	Builder  string `json:"builder,omitempty"`
+	RunImage string `json:"runImage,omitempty"`
+	// Buildpacks are the buildpacks that detection selected for the app, in order
+	Buildpacks []BuildpackRef `json:"buildpacks,omitempty"`
+	// Phases are the lifecycle phases that ran, in order, with how long each took
+	Phases []PhaseTiming `json:"phases,omitempty"`
+	// BOM is the bill-of-materials of the image, which is nil when the builder did not record it
+	BOM *BOM `json:"bom,omitempty"`
+	// Processes are the process types of the image, which the launcher runs as their command
+	Processes []Process `json:"processes,omitempty"`
+}
+
+// Process is a process type of an app image, such as 'web' or 'worker'
+type Process struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	// Default is true for the process type that the image runs by default, when it was set
+	Default bool `json:"default,omitempty"`
+}
+
+// BuildpackRef is a buildpack that built an app image
+type BuildpackRef struct {
+	ID      string `json:"id"`
+	Version string `json:"version,omitempty"`
+}
+
+// PhaseTiming is the wall-clock time a lifecycle phase of a build took
+type PhaseTiming struct {
+	Phase    string        `json:"phase"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Reference returns the name of the image pinned to its digest, such as
+// 'index.docker.io/org/app@sha256:...', or its name when the digest is unknown
+func (r *BuildResult) Reference() string {
+	if r.Digest == "" {
+		return r.Image
+	}
+	ref, err := name.ParseReference(r.Image, name.WeakValidation)
+	if err != nil {
+		return r.Image
+	}
+	return ref.Context().Name() + "@" + r.Digest
+}
+
+// DefaultProcess returns the process type that the image runs by default: the one set when it was
+// exported, or else the 'web' process, which the launcher runs when no other is set. It returns nil
+// when the image has neither.
+func (r *BuildResult) DefaultProcess() *Process {
+	var web *Process
+	for i := range r.Processes {
+		if r.Processes[i].Default {
+			return &r.Processes[i]
+		}
+		if r.Processes[i].Type == "web" {
+			web = &r.Processes[i]
+		}
+	}
+	return web
+}
+
+// result resolves the app image exported by the build. The digest of published images is the one
+// logged by the exporter, which is looked up in the registry when it was not logged.
+func (b *BuildConfig) result(ctx context.Context, exportedDigest string) (*BuildResult, error) {
+	ref, err := name.ParseReference(b.RepoName, name.WeakValidation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image name %s", style.Symbol(b.RepoName))
+	}
+	result := &BuildResult{Image: b.RepoName, Builder: b.Builder, RunImage: b.RunImage}
+
+	if b.Publish {
+		result.Tags = []string{ref.Name()}
+		for _, tag := range b.AdditionalTags {
+			if t, err := name.NewTag(tag, name.WeakValidation); err == nil {
+				result.Tags = append(result.Tags, t.Name())
+			}
+		}
+		result.Digest = exportedDigest
+		if result.Digest == "" {
+			img, err := remoteImage(ref, registryTransport(b.Config))
+			if err != nil {
+				return nil, errors.Wrapf(err, "fetching image %s", style.Symbol(b.RepoName))
+			}
+			digest, err := img.Digest()
+			if err != nil {
+				return nil, err
+			}
+			result.Digest = digest.String()
+		}
+		return result, nil
+	}
+
+	inspect, _, err := b.Cli.ImageInspectWithRaw(ctx, b.RepoName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "inspecting image %s", style.Symbol(b.RepoName))
+	}
+	result.ImageID = inspect.ID
+	result.Tags = inspect.RepoTags
+	result.Size = inspect.Size
+	for _, repoDigest := range inspect.RepoDigests {
+		if digest, err := name.NewDigest(repoDigest, name.WeakValidation); err == nil && digest.Context() == ref.Context() {
+			result.Digest = digest.DigestStr()
+		}
+	}
+	return result, nil
+}
+
+// readBuildMetadata sets the bill-of-materials, the process types and the buildpacks of result from
+// the metadata that the builder and the detector wrote, which builds only require when writing out the
+// bill-of-materials or the report
+func (b *BuildConfig) readBuildMetadata(ctx context.Context, lifecycle *build.Lifecycle, result *BuildResult) error {
+	metadata, err := lifecycle.BuildMetadata(ctx)
+	switch {
+	case err == nil:
+		result.BOM = newBOM(metadata)
+		for _, process := range metadata.Processes {
+			result.Processes = append(result.Processes, Process{
+				Type:    process.Type,
+				Command: process.Command,
+				Default: process.Type == b.LifecycleConfig.DefaultProcess,
+			})
+		}
+	case b.BOMFormat != "":
+		return err
+	default:
+		b.Logger.Verbose("Skipping build metadata: %s", err)
+	}
+
+	group, err := lifecycle.BuildpackGroup(ctx)
+	switch {
+	case err == nil:
+		for _, bp := range group.Buildpacks {
+			result.Buildpacks = append(result.Buildpacks, BuildpackRef{ID: bp.ID, Version: bp.Version})
+		}
+	case b.ReportPath != "":
+		return err
+	default:
+		b.Logger.Verbose("Skipping buildpack group: %s", err)
+	}
+	return nil
+}
+
+// writeReport writes the report of the build to ReportPath
+func (b *BuildConfig) writeReport(result *BuildResult) error {
+	b.Logger.Verbose("Writing build report to %s", style.Symbol(b.ReportPath))
+	f, err := os.Create(b.ReportPath)
+	if err != nil {
+		return err
+	}
+	if err := result.WriteReport(f); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "writing build report %s", style.Symbol(b.ReportPath))
+	}
+	return f.Close()
+}
+
+// WriteReport writes the result as a JSON document, which identifies the image, the builder, run image
+// and buildpacks it was built with, and how long each phase took, in nanoseconds
+func (r *BuildResult) WriteReport(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// logResult logs the image exported by the build
+func (b *BuildConfig) logResult(result *BuildResult) {
+	if result.ImageID != "" {
+		b.Logger.Info("Image ID: %s", result.ImageID)
+	}
+	if result.Digest != "" {
+		b.Logger.Info("Digest: %s", result.Digest)
+		b.Logger.Info("Reference: %s", result.Reference())
+	}
+	if len(result.Tags) > 0 {
+		b.Logger.Info("Tags: %s", strings.Join(result.Tags, ", "))
+	}
+	if len(result.Processes) > 0 {
+		var types []string
+		defaultProcess := result.DefaultProcess()
+		for _, process := range result.Processes {
+			if defaultProcess != nil && process.Type == defaultProcess.Type {
+				types = append(types, process.Type+" (default)")
+			} else {
+				types = append(types, process.Type)
+			}
+		}
+		b.Logger.Info("Processes: %s", strings.Join(types, ", "))
+	}
+}
@@ -0,0 +1,85 @@
+package pack_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBuildResult(t *testing.T) {
+	spec.Run(t, "BuildResult", testBuildResult, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuildResult(t *testing.T, when spec.G, it spec.S) {
+	when("#Reference", func() {
+		it("pins the image to its digest", func() {
+			result := &pack.BuildResult{Image: "some/app:some-tag", Digest: "sha256:some-digest"}
+			h.AssertEq(t, result.Reference(), "index.docker.io/some/app@sha256:some-digest")
+		})
+
+		it("keeps the registry of the image", func() {
+			result := &pack.BuildResult{Image: "registry.example.com:5000/some/app", Digest: "sha256:some-digest"}
+			h.AssertEq(t, result.Reference(), "registry.example.com:5000/some/app@sha256:some-digest")
+		})
+
+		it("is the image name when the digest is unknown", func() {
+			result := &pack.BuildResult{Image: "some/app:some-tag", ImageID: "sha256:some-id"}
+			h.AssertEq(t, result.Reference(), "some/app:some-tag")
+		})
+	})
+
+	when("#DefaultProcess", func() {
+		it("is the process set as the default", func() {
+			result := &pack.BuildResult{Processes: []pack.Process{
+				{Type: "web", Command: "npm start"},
+				{Type: "worker", Command: "npm run worker", Default: true},
+			}}
+			h.AssertEq(t, result.DefaultProcess(), &pack.Process{Type: "worker", Command: "npm run worker", Default: true})
+		})
+
+		it("is the web process when no default was set", func() {
+			result := &pack.BuildResult{Processes: []pack.Process{
+				{Type: "worker", Command: "npm run worker"},
+				{Type: "web", Command: "npm start"},
+			}}
+			h.AssertEq(t, result.DefaultProcess(), &pack.Process{Type: "web", Command: "npm start"})
+		})
+
+		it("is nil when there is neither", func() {
+			result := &pack.BuildResult{Processes: []pack.Process{{Type: "worker", Command: "npm run worker"}}}
+			h.AssertNil(t, result.DefaultProcess())
+		})
+	})
+	when("#WriteReport", func() {
+		it("writes the image, the builder, run image and buildpacks, and the phase timings as JSON", func() {
+			result := &pack.BuildResult{
+				Image:      "some/app",
+				Digest:     "sha256:some-digest",
+				Builder:    "some/builder",
+				RunImage:   "some/run",
+				Buildpacks: []pack.BuildpackRef{{ID: "some/bp", Version: "1.2.3"}},
+				Phases:     []pack.PhaseTiming{{Phase: "detector", Duration: 2 * time.Second}},
+			}
+			buf := &bytes.Buffer{}
+			h.AssertNil(t, result.WriteReport(buf))
+
+			var report map[string]interface{}
+			h.AssertNil(t, json.Unmarshal(buf.Bytes(), &report))
+			h.AssertEq(t, report, map[string]interface{}{
+				"image":      "some/app",
+				"digest":     "sha256:some-digest",
+				"builder":    "some/builder",
+				"runImage":   "some/run",
+				"buildpacks": []interface{}{map[string]interface{}{"id": "some/bp", "version": "1.2.3"}},
+				"phases":     []interface{}{map[string]interface{}{"phase": "detector", "duration": float64(2000000000)}},
+			})
+		})
+	})
+}
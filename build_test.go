@@ -3,12 +3,23 @@ package pack_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/buildpack/lifecycle/image"
+	"github.com/docker/docker/api/types"
 	"github.com/fatih/color"
 
 	"github.com/buildpack/pack/config"
@@ -19,6 +30,7 @@ import (
 	"github.com/sclevine/spec/report"
 
 	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/buildpack"
 	"github.com/buildpack/pack/mocks"
 	h "github.com/buildpack/pack/testhelpers"
 )
@@ -52,13 +64,15 @@ func testBuildFactory(t *testing.T, when spec.G, it spec.S) {
 			factory = &pack.BuildFactory{
 				Fetcher: mockFetcher,
 				Config: &config.Config{
-					DefaultBuilder: "some/builder",
+					DefaultBuilder:  "some/builder",
+					TrustedBuilders: []string{"some/builder", "custom/builder"},
 				},
 				Logger: logger,
 				Cache:  mockCache,
 			}
 
 			mockCache.EXPECT().Image().AnyTimes()
+			mockCache.EXPECT().Dir().AnyTimes()
 		})
 
 		it.After(func() {
@@ -83,6 +97,49 @@ func testBuildFactory(t *testing.T, when spec.G, it spec.S) {
 			h.AssertEq(t, config.Builder, "some/builder")
 		})
 
+		when("the config has insecure registries", func() {
+			it.Before(func() {
+				factory.Config.InsecureRegistries = []string{"registry.internal:5000", "localhost:5000"}
+				mockCache.EXPECT().Remote().AnyTimes()
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+				mockRunImage := mocks.NewMockImage(mockController)
+				mockRunImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchRemoteImage("some/run").Return(mockRunImage, nil)
+			})
+
+			it("does not publish to them, as the lifecycle cannot reach them over http", func() {
+				_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName: "registry.internal:5000/some/app",
+					Builder:  "some/builder",
+					Publish:  true,
+				})
+				h.AssertError(t, err, "image 'registry.internal:5000/some/app' is on insecure registry 'registry.internal:5000'")
+			})
+
+			it("publishes to those on localhost, which the lifecycle reaches over http", func() {
+				_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName: "localhost:5000/some/app",
+					Builder:  "some/builder",
+					Publish:  true,
+				})
+				h.AssertNil(t, err)
+			})
+		})
+
+		it("suggests builders when there is no builder", func() {
+			factory.Config.DefaultBuilder = ""
+
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+			})
+			h.AssertError(t, err, "no builder selected")
+			h.AssertError(t, err, "pack set-default-builder <builder image>")
+			h.AssertError(t, err, pack.SuggestedBuilders[0].Image)
+		})
+
 		it("respects builder from flags", func() {
 			mockBuilderImage := mocks.NewMockImage(mockController)
 			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
@@ -101,7 +158,69 @@ func testBuildFactory(t *testing.T, when spec.G, it spec.S) {
 			h.AssertEq(t, config.Builder, "custom/builder")
 		})
 
-		it("doesn't pull builder or run images when --no-pull is passed", func() {
+		it("emits an event for each pulled image", func() {
+			var events []pack.Event
+			factory.OnEvent = func(e pack.Event) { events = append(events, e) }
+
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(events), 2)
+			h.AssertEq(t, events[0].Type, pack.ImagePulled)
+			h.AssertEq(t, events[0].Image, "some/builder")
+			h.AssertEq(t, events[1].Type, pack.ImagePulled)
+			h.AssertEq(t, events[1].Image, "some/run")
+		})
+
+		it("pulls the builder and a user-provided run image concurrently", func() {
+			var events []pack.Event
+			factory.OnEvent = func(e pack.Event) { events = append(events, e) }
+			runPulling := make(chan struct{})
+
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ io.Writer, _ ...func(*pack.FetchOptions)) (image.Image, error) {
+					select {
+					case <-runPulling:
+						return mockBuilderImage, nil
+					case <-time.After(10 * time.Second):
+						return nil, errors.New("run image was not pulled while pulling the builder")
+					}
+				})
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "override/run", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, stdout io.Writer, _ ...func(*pack.FetchOptions)) (image.Image, error) {
+					close(runPulling)
+					fmt.Fprintln(stdout, "some run image progress")
+					return mockRunImage, nil
+				})
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+				Builder:  "some/builder",
+				RunImage: "override/run",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.RunImage, "override/run")
+			h.AssertContains(t, outBuf.String(), "some run image progress")
+			h.AssertEq(t, len(events), 2)
+			h.AssertEq(t, events[0].Image, "some/builder")
+			h.AssertEq(t, events[1].Image, "override/run")
+		})
+
+		it("doesn't pull builder or run images when the pull policy is never", func() {
 			mockBuilderImage := mocks.NewMockImage(mockController)
 			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
 			mockFetcher.EXPECT().FetchLocalImage("custom/builder").Return(mockBuilderImage, nil)
@@ -111,15 +230,93 @@ func testBuildFactory(t *testing.T, when spec.G, it spec.S) {
 			mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
 
 			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
-				NoPull:   true,
-				RepoName: "some/app",
-				Builder:  "custom/builder",
+				PullPolicy: pack.PullNever,
+				RepoName:   "some/app",
+				Builder:    "custom/builder",
 			})
 			h.AssertNil(t, err)
 			h.AssertEq(t, config.RunImage, "some/run")
 			h.AssertEq(t, config.Builder, "custom/builder")
 		})
 
+		it("only pulls the images missing from the daemon when the pull policy is if-not-present", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Found().Return(true, nil)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchLocalImage("custom/builder").Return(mockBuilderImage, nil)
+
+			mockLocalRunImage := mocks.NewMockImage(mockController)
+			mockLocalRunImage.EXPECT().Found().Return(false, nil)
+			mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockLocalRunImage, nil)
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				PullPolicy: pack.PullIfNotPresent,
+				RepoName:   "some/app",
+				Builder:    "custom/builder",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.RunImage, "some/run")
+		})
+
+		it("parses pull policies", func() {
+			var policy pack.PullPolicy
+			h.AssertEq(t, policy, pack.PullAlways)
+			h.AssertNil(t, policy.Set("if-not-present"))
+			h.AssertEq(t, policy, pack.PullIfNotPresent)
+			h.AssertNil(t, policy.Set("never"))
+			h.AssertEq(t, policy.String(), "never")
+			h.AssertError(t, policy.Set("sometimes"), "unknown pull policy 'sometimes'")
+		})
+
+		when("a platform is requested", func() {
+			var mockDocker *mocks.MockDocker
+
+			it.Before(func() {
+				mockDocker = mocks.NewMockDocker(mockController)
+				factory.Cli = mockDocker
+
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any(), gomock.Any()).Return(mockBuilderImage, nil)
+
+				mockRunImage := mocks.NewMockImage(mockController)
+				mockRunImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any(), gomock.Any()).Return(mockRunImage, nil)
+			})
+
+			it("pulls builder and run images for the platform", func() {
+				mockDocker.EXPECT().ImageInspectWithRaw(gomock.Any(), "some/builder").Return(types.ImageInspect{Os: "linux", Architecture: "arm64"}, nil, nil)
+				mockDocker.EXPECT().ImageInspectWithRaw(gomock.Any(), "some/run").Return(types.ImageInspect{Os: "linux", Architecture: "arm64"}, nil, nil)
+
+				_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName: "some/app",
+					Platform: "linux/arm64",
+				})
+				h.AssertNil(t, err)
+			})
+
+			it("returns an error when the pulled images are for another platform", func() {
+				mockDocker.EXPECT().ImageInspectWithRaw(gomock.Any(), "some/builder").Return(types.ImageInspect{Os: "linux", Architecture: "amd64"}, nil, nil)
+
+				_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName: "some/app",
+					Platform: "linux/arm64",
+				})
+				h.AssertError(t, err, "image 'some/builder' is for platform 'linux/amd64', not 'linux/arm64'")
+			})
+		})
+
+		it("returns an error when the platform is invalid", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+				Platform: "arm64",
+			})
+			h.AssertError(t, err, "invalid platform 'arm64'")
+		})
+
 		it("selects run images with matching registry", func() {
 			mockBuilderImage := mocks.NewMockImage(mockController)
 			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").
@@ -205,6 +402,7 @@ func testBuildFactory(t *testing.T, when spec.G, it spec.S) {
 
 		it("allows run-image from flags if the stacks match", func() {
 			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
 			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
 
 			mockRunImage := mocks.NewMockImage(mockController)
@@ -243,6 +441,584 @@ func testBuildFactory(t *testing.T, when spec.G, it spec.S) {
 			h.AssertEq(t, config.LifecycleConfig.AppDir, os.Getenv("PWD"))
 		})
 
+		it("passes volumes and the network to the lifecycle", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchRemoteImage("some/run").Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+				Builder:  "some/builder",
+				Publish:  true,
+				Volumes:  []string{"/some/.m2:/home/cnb/.m2"},
+				Network:  "none",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.LifecycleConfig.Volumes, []string{"/some/.m2:/home/cnb/.m2"})
+			h.AssertEq(t, config.LifecycleConfig.Network, "none")
+		})
+
+		it("skips the cache when NoCache is set", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchLocalImage("some/builder").Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:   "some/app",
+				Builder:    "some/builder",
+				PullPolicy: pack.PullNever,
+				NoCache:    true,
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.NoCache, true)
+		})
+
+		it("passes the default process to the lifecycle", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}, "lifecycle": {"version": "0.7.0"}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchLocalImage("some/builder").Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:       "some/app",
+				Builder:        "some/builder",
+				PullPolicy:     pack.PullNever,
+				DefaultProcess: "worker",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.LifecycleConfig.DefaultProcess, "worker")
+		})
+
+		it("passes the phase hook of the factory to the build", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchLocalImage("some/builder").Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+			hook := &fakePhaseHook{}
+			factory.PhaseHook = hook
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:   "some/app",
+				Builder:    "some/builder",
+				PullPolicy: pack.PullNever,
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.PhaseHook, pack.PhaseHook(hook))
+		})
+
+		when("the builder is not trusted", func() {
+			var mockRunImage *mocks.MockImage
+
+			it.Before(func() {
+				mockRunImage = mocks.NewMockImage(mockController)
+				mockRunImage.EXPECT().Found().Return(true, nil)
+			})
+
+			it("pulls the lifecycle image of the builder's lifecycle version", func() {
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}, "lifecycle": {"version": "0.3.0"}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "untrusted/builder", gomock.Any()).Return(mockBuilderImage, nil)
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+				mockLifecycleImage := mocks.NewMockImage(mockController)
+				mockLifecycleImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "buildpacksio/lifecycle:0.3.0", gomock.Any()).Return(mockLifecycleImage, nil)
+
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName: "some/app",
+					Builder:  "untrusted/builder",
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, config.LifecycleImage, "buildpacksio/lifecycle:0.3.0")
+				h.AssertEq(t, config.LifecycleConfig.LifecycleVersion, "0.3.0")
+			})
+
+			it("uses the latest lifecycle image when the builder has no lifecycle version", func() {
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchLocalImage("untrusted/builder").Return(mockBuilderImage, nil)
+				mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+				mockLifecycleImage := mocks.NewMockImage(mockController)
+				mockLifecycleImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchLocalImage("buildpacksio/lifecycle:latest").Return(mockLifecycleImage, nil)
+
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "untrusted/builder",
+					PullPolicy: pack.PullNever,
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, config.LifecycleImage, "buildpacksio/lifecycle:latest")
+			})
+
+			it("fails when the lifecycle image does not exist", func() {
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchLocalImage("untrusted/builder").Return(mockBuilderImage, nil)
+				mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+				mockLifecycleImage := mocks.NewMockImage(mockController)
+				mockLifecycleImage.EXPECT().Found().Return(false, nil)
+				mockFetcher.EXPECT().FetchLocalImage("buildpacksio/lifecycle:latest").Return(mockLifecycleImage, nil)
+
+				_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "untrusted/builder",
+					PullPolicy: pack.PullNever,
+				})
+				h.AssertError(t, err, "lifecycle image 'buildpacksio/lifecycle:latest' does not exist")
+			})
+
+			it("fails to mount the app dir", func() {
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchLocalImage("untrusted/builder").Return(mockBuilderImage, nil)
+				mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+				_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "untrusted/builder",
+					PullPolicy: pack.PullNever,
+					MountApp:   true,
+				})
+				h.AssertError(t, err, "app directory can only be mounted into builds with trusted builders, builder 'untrusted/builder' is not trusted")
+			})
+
+			it("trusts the builder when the trust prompt trusts it", func() {
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchLocalImage("untrusted/builder").Return(mockBuilderImage, nil)
+				mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+				var asked []string
+				pack.WithTrustPrompt(func(builder string) (bool, error) {
+					asked = append(asked, builder)
+					return true, nil
+				})(factory)
+
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "untrusted/builder",
+					PullPolicy: pack.PullNever,
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, asked, []string{"untrusted/builder"})
+				h.AssertEq(t, config.LifecycleImage, "")
+				h.AssertEq(t, factory.Config.IsTrustedBuilder("untrusted/builder"), true)
+			})
+
+			it("runs the privileged phases in the lifecycle image when the trust prompt does not trust the builder", func() {
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchLocalImage("untrusted/builder").Return(mockBuilderImage, nil)
+				mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+				mockLifecycleImage := mocks.NewMockImage(mockController)
+				mockLifecycleImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchLocalImage("buildpacksio/lifecycle:latest").Return(mockLifecycleImage, nil)
+
+				pack.WithTrustPrompt(func(builder string) (bool, error) {
+					return false, nil
+				})(factory)
+
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "untrusted/builder",
+					PullPolicy: pack.PullNever,
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, config.LifecycleImage, "buildpacksio/lifecycle:latest")
+				h.AssertEq(t, factory.Config.IsTrustedBuilder("untrusted/builder"), false)
+			})
+		})
+
+		it("leaves the lifecycle image unset for trusted builders", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+				Builder:  "some/builder",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.LifecycleImage, "")
+		})
+
+		it("mounts the app dir into builds with trusted builders", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchLocalImage("some/builder").Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:   "some/app",
+				Builder:    "some/builder",
+				PullPolicy: pack.PullNever,
+				MountApp:   true,
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.LifecycleConfig.MountApp, true)
+		})
+
+		it("passes the platform API of the builder's lifecycle to the lifecycle", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}, "lifecycle": {"version": "0.4.0", "api": {"platform": "0.2"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+				Builder:  "some/builder",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.LifecycleConfig.LifecycleVersion, "0.4.0")
+			h.AssertEq(t, config.LifecycleConfig.PlatformAPI, "0.2")
+		})
+
+		it("returns an error when the builder's lifecycle implements an unsupported platform API", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}, "lifecycle": {"version": "0.9.0", "api": {"platform": "0.9"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+				Builder:  "some/builder",
+			})
+			h.AssertError(t, err, "builder 'some/builder' is incompatible: the lifecycle implements platform API '0.9'")
+		})
+
+		it("passes the output to the build config", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:     "some/app",
+				Builder:      "some/builder",
+				OutputFormat: pack.OutputOCI,
+				OutputPath:   "some/dir",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.OutputFormat, "oci")
+			h.AssertEq(t, config.OutputPath, "some/dir")
+		})
+
+		it("returns an error for an unknown output format", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:     "some/app",
+				Builder:      "some/builder",
+				OutputFormat: "some-format",
+				OutputPath:   "some/dir",
+			})
+			h.AssertError(t, err, "unknown output format 'some-format'")
+		})
+
+		it("returns an error when saving a published image", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:     "some/app",
+				Builder:      "some/builder",
+				Publish:      true,
+				OutputFormat: pack.OutputDockerArchive,
+				OutputPath:   "some/app.tar",
+			})
+			h.AssertError(t, err, "saving the image is not supported when publishing it")
+		})
+
+		it("returns an error for an unknown bill-of-materials format", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:  "some/app",
+				Builder:   "some/builder",
+				BOMFormat: "some-format",
+				BOMPath:   "some/bom.json",
+			})
+			h.AssertError(t, err, "unknown bill-of-materials format 'some-format'")
+		})
+
+		it("returns an error for a bill-of-materials format without a path", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:  "some/app",
+				Builder:   "some/builder",
+				BOMFormat: pack.BOMSPDX,
+			})
+			h.AssertError(t, err, "a path is required to write the bill-of-materials as 'spdx'")
+		})
+
+		it("passes the additional tags to the build config", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:       "some/app",
+				Builder:        "some/builder",
+				AdditionalTags: []string{"some/app:v1", "registry.example.com/some/app:latest"},
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.AdditionalTags, []string{"some/app:v1", "registry.example.com/some/app:latest"})
+			h.AssertEq(t, config.LifecycleConfig.AdditionalTags, []string{"some/app:v1", "registry.example.com/some/app:latest"})
+		})
+
+		it("returns an error for an invalid additional tag", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:       "some/app",
+				Builder:        "some/builder",
+				AdditionalTags: []string{"Some/App"},
+			})
+			h.AssertError(t, err, "invalid tag 'Some/App'")
+		})
+
+		it("passes the labels to the build config", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+				Builder:  "some/builder",
+				Labels:   map[string]string{"org.example.commit": "abc123"},
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.Labels, map[string]string{"org.example.commit": "abc123"})
+		})
+
+		it("passes the exposed ports, args and working dir to the build config", func() {
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:     "some/app",
+				Builder:      "some/builder",
+				ExposedPorts: []string{"8080", "53/udp", "9000-9001", "8080/tcp"},
+				Args:         []string{"worker", "--verbose"},
+				WorkingDir:   "/workspace/src",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.ExposedPorts, []string{"8080/tcp", "53/udp", "9000/tcp", "9001/tcp"})
+			h.AssertEq(t, config.Args, []string{"worker", "--verbose"})
+			h.AssertEq(t, config.WorkingDir, "/workspace/src")
+		})
+
+		it("returns an error for an invalid port", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName:     "some/app",
+				Builder:      "some/builder",
+				ExposedPorts: []string{"80:8080"},
+			})
+			h.AssertError(t, err, "invalid port '80:8080', must be of the form <port>[/<protocol>]")
+		})
+
+		it("requires an image name for apps read from stdin", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				AppDir:  "-",
+				Builder: "some/builder",
+			})
+			h.AssertError(t, err, "an image name is required to build an app read from stdin")
+		})
+
+		it("returns an error for a label reserved for buildpacks", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+				Builder:  "some/builder",
+				Labels:   map[string]string{"io.buildpacks.stack.id": "some.stack"},
+			})
+			h.AssertError(t, err, "label 'io.buildpacks.stack.id' is reserved for buildpacks")
+		})
+
+		when("buildpacks are given as archives or URLs", func() {
+			var tmpDir string
+
+			it.Before(func() {
+				var err error
+				tmpDir, err = ioutil.TempDir("", "pack.build.buildpacks")
+				h.AssertNil(t, err)
+				factory.BuildpackFetcher = buildpack.NewFetcher(logger, tmpDir)
+
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+				mockRunImage := mocks.NewMockImage(mockController)
+				mockRunImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchRemoteImage("some/run").Return(mockRunImage, nil)
+			})
+
+			it.After(func() {
+				h.AssertNil(t, os.RemoveAll(tmpDir))
+			})
+
+			assertBuildpackDir := func(dir string) {
+				t.Helper()
+				contents, err := ioutil.ReadFile(filepath.Join(dir, "buildpack.toml"))
+				h.AssertNil(t, err)
+				h.AssertContains(t, string(contents), `id = "some-buildpack-id"`)
+			}
+
+			it("extracts .tgz archives", func() {
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "some/builder",
+					Publish:    true,
+					Buildpacks: []string{filepath.Join("testdata", "buildpack.tgz"), "some/bp@1.2.3"},
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, len(config.LifecycleConfig.Buildpacks), 2)
+				assertBuildpackDir(config.LifecycleConfig.Buildpacks[0])
+				h.AssertEq(t, config.LifecycleConfig.Buildpacks[1], "some/bp@1.2.3")
+			})
+
+			it("downloads buildpacks from URLs", func() {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, filepath.Join("testdata", "buildpack.tgz"))
+				}))
+				defer server.Close()
+
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "some/builder",
+					Publish:    true,
+					Buildpacks: []string{server.URL + "/buildpack.tgz"},
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, len(config.LifecycleConfig.Buildpacks), 1)
+				assertBuildpackDir(config.LifecycleConfig.Buildpacks[0])
+			})
+
+			it("verifies the digests of archives", func() {
+				contents, err := ioutil.ReadFile(filepath.Join("testdata", "buildpack.tgz"))
+				h.AssertNil(t, err)
+				digest := fmt.Sprintf("%x", sha256.Sum256(contents))
+
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "some/builder",
+					Publish:    true,
+					Buildpacks: []string{filepath.Join("testdata", "buildpack.tgz") + "@sha256:" + digest},
+				})
+				h.AssertNil(t, err)
+				assertBuildpackDir(config.LifecycleConfig.Buildpacks[0])
+			})
+
+			it("fails for archives with another digest", func() {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, filepath.Join("testdata", "buildpack.tgz"))
+				}))
+				defer server.Close()
+
+				_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "some/builder",
+					Publish:    true,
+					Buildpacks: []string{server.URL + "/buildpack.tgz@sha256:" + strings.Repeat("0", 64)},
+				})
+				h.AssertError(t, err, "expected sha256:"+strings.Repeat("0", 64))
+			})
+
+			when("a buildpack registry is configured", func() {
+				var server *httptest.Server
+
+				it.Before(func() {
+					mux := http.NewServeMux()
+					mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprintf(w, `{"buildpacks": [{"id": "some-buildpack-id", "version": "some-buildpack-version", "uri": "%s/buildpack.tgz"}]}`, server.URL)
+					})
+					mux.HandleFunc("/buildpack.tgz", func(w http.ResponseWriter, r *http.Request) {
+						http.ServeFile(w, r, filepath.Join("testdata", "buildpack.tgz"))
+					})
+					server = httptest.NewServer(mux)
+					factory.Config.BuildpackRegistry = server.URL + "/index.json"
+				})
+
+				it.After(func() {
+					server.Close()
+				})
+
+				it("downloads the buildpacks missing from the builder from the registry", func() {
+					config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+						RepoName:   "some/app",
+						Builder:    "some/builder",
+						Publish:    true,
+						Buildpacks: []string{"some-buildpack-id@some-buildpack-version", "some/bp"},
+					})
+					h.AssertNil(t, err)
+					h.AssertEq(t, len(config.LifecycleConfig.Buildpacks), 2)
+					assertBuildpackDir(config.LifecycleConfig.Buildpacks[0])
+					h.AssertEq(t, config.LifecycleConfig.Buildpacks[1], "some/bp")
+				})
+
+				it("fails for buildpacks missing from the registry", func() {
+					_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+						RepoName:   "some/app",
+						Builder:    "some/builder",
+						Publish:    true,
+						Buildpacks: []string{"other/bp@1.0"},
+					})
+					h.AssertError(t, err, "buildpack 'other/bp@1.0' is not in registry")
+				})
+			})
+
+			it("fails for archives without a buildpack.toml", func() {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, filepath.Join("testdata", "empty.tgz"))
+				}))
+				defer server.Close()
+
+				_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					RepoName:   "some/app",
+					Builder:    "some/builder",
+					Publish:    true,
+					Buildpacks: []string{server.URL + "/empty.tgz"},
+				})
+				h.AssertError(t, err, "reading buildpack.toml of buildpack")
+			})
+		})
+
 		it("returns an error when the builder metadata label is missing", func() {
 			mockBuilderImage := mocks.NewMockImage(mockController)
 			mockBuilderImage.EXPECT().Name().Return("some/builder")
@@ -344,6 +1120,7 @@ func testBuildFactory(t *testing.T, when spec.G, it spec.S) {
 			defer os.Remove(envFile.Name())
 
 			_, err = envFile.Write([]byte(`
+# some comment
 VAR1=value1
 VAR2=value2 with spaces
 PATH
@@ -400,5 +1177,219 @@ PATH
 			})
 			h.AssertNotEq(t, os.Getenv("PATH"), "")
 		})
+
+		it("fails with the lines of invalid variables in EnvFile", func() {
+			envFile, err := ioutil.TempFile("", "pack.build.envfile")
+			h.AssertNil(t, err)
+			defer os.Remove(envFile.Name())
+
+			_, err = envFile.Write([]byte("VAR1=value1\nexport VAR2=value2\n=value3\n"))
+			h.AssertNil(t, err)
+			envFile.Close()
+
+			_, err = factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				RepoName: "some/app",
+				Builder:  "some/builder",
+				EnvFile:  envFile.Name(),
+			})
+			h.AssertError(t, err, envFile.Name()+":2: export VAR2: invalid variable name")
+			h.AssertError(t, err, envFile.Name()+":3: invalid variable name")
+		})
+
+		when("the app source is a Git repository with a project descriptor", func() {
+			var repoDir string
+
+			it.Before(func() {
+				if _, err := exec.LookPath("git"); err != nil {
+					t.Skip("git is not installed")
+				}
+				var err error
+				repoDir, err = ioutil.TempDir("", "pack.build.git")
+				h.AssertNil(t, err)
+				appRepo := filepath.Join(repoDir, "app.git")
+				h.AssertNil(t, os.Mkdir(appRepo, 0755))
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(appRepo, "project.toml"), []byte(`
+[image]
+  name = "project/app"
+
+[build]
+  exclude = ["node_modules"]
+
+[[build.env]]
+  name = "VAR1"
+  value = "project1"
+`), 0644))
+				for _, args := range [][]string{{"init", "--quiet"}, {"add", "."}, {"commit", "--quiet", "-m", "some commit"}} {
+					cmd := exec.Command("git", append([]string{"-c", "user.name=pack", "-c", "user.email=pack@example.com"}, args...)...)
+					cmd.Dir = appRepo
+					if out, err := cmd.CombinedOutput(); err != nil {
+						t.Fatalf("git %v: %s: %s", args, err, out)
+					}
+				}
+
+				// the https URL of the app is cloned from the local repository instead
+				h.AssertNil(t, os.Setenv("GIT_CONFIG_COUNT", "1"))
+				h.AssertNil(t, os.Setenv("GIT_CONFIG_KEY_0", "url.file://"+filepath.ToSlash(repoDir)+"/.insteadOf"))
+				h.AssertNil(t, os.Setenv("GIT_CONFIG_VALUE_0", "https://git.example.com/"))
+
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+				mockRunImage := mocks.NewMockImage(mockController)
+				mockRunImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+			})
+
+			it.After(func() {
+				for _, key := range []string{"GIT_CONFIG_COUNT", "GIT_CONFIG_KEY_0", "GIT_CONFIG_VALUE_0"} {
+					os.Unsetenv(key)
+				}
+				h.AssertNil(t, os.RemoveAll(repoDir))
+			})
+
+			it("uses the descriptor of the cloned repository", func() {
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					AppDir:  "https://git.example.com/app.git",
+					Builder: "some/builder",
+				})
+				h.AssertNil(t, err)
+				defer os.RemoveAll(config.LifecycleConfig.AppDir)
+
+				h.AssertEq(t, config.RepoName, "project/app")
+				h.AssertEq(t, config.LifecycleConfig.Exclude, []string{"node_modules"})
+				h.AssertEq(t, config.LifecycleConfig.Env, map[string]string{"VAR1": "project1"})
+				_, err = os.Stat(filepath.Join(config.LifecycleConfig.AppDir, "project.toml"))
+				h.AssertNil(t, err)
+				h.AssertContains(t, outBuf.String(), "Using project descriptor")
+			})
+		})
+
+		when("the app has a project descriptor", func() {
+			var appDir string
+
+			it.Before(func() {
+				var err error
+				appDir, err = ioutil.TempDir("", "pack.build.project")
+				h.AssertNil(t, err)
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "project.toml"), []byte(`
+[image]
+  name = "project/app"
+
+[build]
+  exclude = ["node_modules"]
+
+[[build.buildpacks]]
+  id = "some.buildpack"
+
+[[build.buildpacks]]
+  uri = "some-dir"
+
+[[build.env]]
+  name = "VAR1"
+  value = "project1"
+
+[[build.env]]
+  name = "VAR2"
+  value = "project2"
+`), 0644))
+
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
+
+				mockRunImage := mocks.NewMockImage(mockController)
+				mockRunImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil)
+			})
+
+			it.After(func() {
+				h.AssertNil(t, os.RemoveAll(appDir))
+			})
+
+			it("uses the image name, buildpacks and env of the descriptor", func() {
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					AppDir:  appDir,
+					Builder: "some/builder",
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, config.RepoName, "project/app")
+				h.AssertEq(t, config.LifecycleConfig.Buildpacks, []string{"some.buildpack", filepath.Join(appDir, "some-dir")})
+				h.AssertEq(t, config.LifecycleConfig.Exclude, []string{"node_modules"})
+				h.AssertEq(t, config.LifecycleConfig.Env, map[string]string{
+					"VAR1": "project1",
+					"VAR2": "project2",
+				})
+			})
+
+			it("gives precedence to the flags", func() {
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					AppDir:     appDir,
+					Builder:    "some/builder",
+					RepoName:   "some/app",
+					Buildpacks: []string{"other.buildpack"},
+					Env:        []string{"VAR1=override1"},
+					Exclude:    []string{"dist"},
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, config.RepoName, "some/app")
+				h.AssertEq(t, config.LifecycleConfig.Exclude, []string{"dist"})
+				h.AssertEq(t, config.LifecycleConfig.Buildpacks, []string{"other.buildpack"})
+				h.AssertEq(t, config.LifecycleConfig.Env, map[string]string{
+					"VAR1": "override1",
+					"VAR2": "project2",
+				})
+			})
+		})
+
+		when("the app has a project configuration", func() {
+			var appDir string
+
+			it.Before(func() {
+				var err error
+				appDir, err = ioutil.TempDir("", "pack.build.project")
+				h.AssertNil(t, err)
+				h.AssertNil(t, os.MkdirAll(filepath.Join(appDir, ".pack"), 0755))
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, ".pack", "config.toml"), []byte(`
+default-builder-image = "project/builder"
+trusted-builders = ["project/builder"]
+`), 0644))
+			})
+
+			it.After(func() {
+				h.AssertNil(t, os.RemoveAll(appDir))
+			})
+
+			it("uses the default builder of the project, which it cannot trust", func() {
+				mockBuilderImage := mocks.NewMockImage(mockController)
+				mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+				mockFetcher.EXPECT().FetchLocalImage("project/builder").Return(mockBuilderImage, nil)
+
+				mockRunImage := mocks.NewMockImage(mockController)
+				mockRunImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchLocalImage("some/run").Return(mockRunImage, nil)
+
+				mockLifecycleImage := mocks.NewMockImage(mockController)
+				mockLifecycleImage.EXPECT().Found().Return(true, nil)
+				mockFetcher.EXPECT().FetchLocalImage("buildpacksio/lifecycle:latest").Return(mockLifecycleImage, nil)
+
+				config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+					AppDir:     appDir,
+					RepoName:   "some/app",
+					PullPolicy: pack.PullNever,
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, config.Builder, "project/builder")
+				h.AssertEq(t, config.LifecycleImage, "buildpacksio/lifecycle:latest")
+				h.AssertEq(t, factory.Config.DefaultBuilder, "some/builder")
+				h.AssertContains(t, outBuf.String(), filepath.Join(appDir, ".pack", "config.toml"))
+			})
+		})
 	}, spec.Parallel())
 }
+
+type fakePhaseHook struct{}
+
+func (f *fakePhaseHook) BeforePhase(name string) {}
+
+func (f *fakePhaseHook) AfterPhase(name string, err error, duration time.Duration) {}
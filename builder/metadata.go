@@ -10,9 +10,19 @@ import (
 const MetadataLabel = "io.buildpacks.builder.metadata"
 
 type TOML struct {
-	Buildpacks []buildpack.Buildpack      `toml:"buildpacks"`
-	Groups     []lifecycle.BuildpackGroup `toml:"groups"`
-	Stack      Stack
+	// Description tells users what the builder builds, such as when it is suggested to them
+	Description string                     `toml:"description"`
+	Buildpacks  []buildpack.Buildpack      `toml:"buildpacks"`
+	Groups      []lifecycle.BuildpackGroup `toml:"groups"`
+	Stack       Stack
+	Lifecycle   Lifecycle `toml:"lifecycle"`
+}
+
+// Lifecycle selects the lifecycle of a builder, by version or by the URI of a tgz of its binaries.
+// When neither is set, the builder keeps the lifecycle of its build image.
+type Lifecycle struct {
+	Version string `toml:"version"`
+	URI     string `toml:"uri"`
 }
 
 type Stack struct {
@@ -23,9 +33,22 @@ type Stack struct {
 }
 
 type Metadata struct {
-	Buildpacks []BuildpackMetadata `json:"buildpacks"`
-	Groups     []GroupMetadata     `json:"groups"`
-	Stack      stack.Metadata      `json:"stack"`
+	Description string              `json:"description,omitempty"`
+	Buildpacks  []BuildpackMetadata `json:"buildpacks"`
+	Groups      []GroupMetadata     `json:"groups"`
+	Stack       stack.Metadata      `json:"stack"`
+	Lifecycle   *LifecycleMetadata  `json:"lifecycle,omitempty"`
+}
+
+type LifecycleMetadata struct {
+	Version string `json:"version"`
+	// API holds the APIs the lifecycle implements, when the builder records them
+	API *LifecycleAPIMetadata `json:"api,omitempty"`
+}
+
+type LifecycleAPIMetadata struct {
+	Platform  string `json:"platform"`
+	Buildpack string `json:"buildpack"`
 }
 
 type BuildpackMetadata struct {
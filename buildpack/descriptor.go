@@ -0,0 +1,184 @@
+package buildpack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+
+	"github.com/buildpack/lifecycle"
+
+	"github.com/buildpack/pack/descriptor"
+)
+
+// DescriptorName is the file describing a buildpack at the root of its directory
+const DescriptorName = "buildpack.toml"
+
+// Descriptor is the buildpack.toml of a buildpack
+type Descriptor struct {
+	API       string                 `toml:"api"`
+	Buildpack Info                   `toml:"buildpack"`
+	Stacks    []Stack                `toml:"stacks"`
+	Order     []OrderEntry           `toml:"order"`
+	Metadata  map[string]interface{} `toml:"metadata"`
+}
+
+type Info struct {
+	ID          string   `toml:"id"`
+	Version     string   `toml:"version"`
+	Name        string   `toml:"name"`
+	Homepage    string   `toml:"homepage"`
+	ClearEnv    bool     `toml:"clear-env"`
+	Description string   `toml:"description"`
+	Keywords    []string `toml:"keywords"`
+	Licenses    []struct {
+		Type string `toml:"type"`
+		URI  string `toml:"uri"`
+	} `toml:"licenses"`
+}
+
+type Stack struct {
+	ID     string   `toml:"id"`
+	Mixins []string `toml:"mixins"`
+}
+
+// OrderEntry is a group of the buildpacks that a meta-buildpack detects together
+type OrderEntry struct {
+	Group []lifecycle.Buildpack `toml:"group"`
+}
+
+var apiPattern = regexp.MustCompile(`^\d+\.\d+$`)
+
+// ReadDescriptor reads the buildpack.toml in dir, checking the fields pack needs to use the
+// buildpack. Problems with the descriptor are returned as descriptor.ValidationErrors, or as a
+// *descriptor.ValidationError when it isn't valid TOML.
+func ReadDescriptor(dir string) (*Descriptor, error) {
+	bp, _, errs, err := readDescriptor(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return bp, nil
+}
+
+// Validate checks the buildpack in dir more thoroughly than ReadDescriptor, for the authors of
+// buildpacks: it must have a version, the keys of its descriptor must all be known, outside of
+// metadata, and it must provide executable bin/detect and bin/build unless it is a meta-buildpack. All the problems found
+// are returned as descriptor.ValidationErrors.
+func Validate(dir string) error {
+	bp, t, errs, err := readDescriptor(dir)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, t.Undecoded("metadata")...)
+	if bp.Buildpack.Version == "" {
+		errs = append(errs, t.Errorf("buildpack.version", "is required"))
+	}
+
+	switch {
+	case len(bp.Stacks) == 0 && len(bp.Order) == 0:
+		errs = append(errs, t.Errorf("", "buildpack must declare either stacks or an order"))
+	case len(bp.Stacks) > 0 && len(bp.Order) > 0:
+		errs = append(errs, t.Errorf("order", "buildpack may not declare both stacks and an order"))
+	case len(bp.Stacks) > 0:
+		for _, bin := range []string{"detect", "build"} {
+			if msg := checkExecutable(filepath.Join(dir, "bin", bin)); msg != "" {
+				errs = append(errs, &descriptor.ValidationError{File: filepath.Join("bin", bin), Message: msg})
+			}
+		}
+	}
+	return errs.Err()
+}
+
+func readDescriptor(dir string) (*Descriptor, *descriptor.TOML, descriptor.ValidationErrors, error) {
+	path := filepath.Join(dir, DescriptorName)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bp := &Descriptor{}
+	t, err := descriptor.DecodeTOML(path, data, bp)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var errs descriptor.ValidationErrors
+	if bp.API != "" && !apiPattern.MatchString(bp.API) {
+		errs = append(errs, t.Errorf("api", "must be of the form <major>.<minor>, such as '0.2'"))
+	}
+	switch bp.Buildpack.ID {
+	case "":
+		errs = append(errs, t.Errorf("buildpack.id", "is required"))
+	case "app", "config":
+		errs = append(errs, t.Errorf("buildpack.id", "'%s' is reserved", bp.Buildpack.ID))
+	}
+	for i, stack := range bp.Stacks {
+		if stack.ID == "" {
+			errs = append(errs, t.Errorf(indexed("stacks", i)+".id", "is required"))
+		}
+	}
+	for i, entry := range bp.Order {
+		for j, group := range entry.Group {
+			if group.ID == "" {
+				errs = append(errs, t.Errorf(indexed(indexed("order", i)+".group", j)+".id", "is required"))
+			}
+		}
+	}
+	return bp, t, errs, nil
+}
+
+// DecodeOrder decodes data, the order.toml of a builder in file, into its groups of buildpacks
+func DecodeOrder(file string, data []byte) ([]lifecycle.BuildpackGroup, error) {
+	var order struct {
+		Groups []lifecycle.BuildpackGroup `toml:"groups"`
+	}
+	t, err := descriptor.DecodeTOML(file, data, &order)
+	if err != nil {
+		return nil, err
+	}
+	var errs descriptor.ValidationErrors
+	if len(order.Groups) == 0 {
+		errs = append(errs, t.Errorf("groups", "must have at least one group"))
+	}
+	for i, group := range order.Groups {
+		for j, bp := range group.Buildpacks {
+			if bp.ID == "" {
+				errs = append(errs, t.Errorf(indexed(indexed("groups", i)+".buildpacks", j)+".id", "is required"))
+			}
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return order.Groups, nil
+}
+
+func indexed(key string, i int) string {
+	return key + "[" + strconv.Itoa(i) + "]"
+}
+
+// checkExecutable returns why the buildpack executable at path can't be run, or an empty string
+// when it can. On Windows, executables are found by their extension, such as bin/detect.bat.
+func checkExecutable(path string) string {
+	if runtime.GOOS == "windows" {
+		if matches, _ := filepath.Glob(path + ".*"); len(matches) > 0 {
+			return ""
+		}
+	}
+	fi, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return "is missing"
+	case err != nil:
+		return err.Error()
+	case fi.IsDir():
+		return "is a directory"
+	case runtime.GOOS != "windows" && fi.Mode()&0111 == 0:
+		return "is not executable"
+	}
+	return ""
+}
@@ -0,0 +1,201 @@
+package buildpack_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/buildpack"
+	"github.com/buildpack/pack/descriptor"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestDescriptor(t *testing.T) {
+	spec.Run(t, "Descriptor", testDescriptor, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testDescriptor(t *testing.T, when spec.G, it spec.S) {
+	var dir string
+
+	it.Before(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "buildpack-descriptor")
+		h.AssertNil(t, err)
+		h.AssertNil(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(dir, "bin", "detect"), []byte("#!/bin/sh\n"), 0755))
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(dir, "bin", "build"), []byte("#!/bin/sh\n"), 0755))
+	})
+
+	it.After(func() {
+		os.RemoveAll(dir)
+	})
+
+	writeDescriptor := func(contents string) {
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(dir, "buildpack.toml"), []byte(contents), 0644))
+	}
+
+	when("#ReadDescriptor", func() {
+		it("reads the buildpack.toml", func() {
+			writeDescriptor(`
+api = "0.2"
+
+[buildpack]
+id = "some-buildpack"
+version = "1.2.3"
+
+[[stacks]]
+id = "some-stack"
+`)
+			bp, err := buildpack.ReadDescriptor(dir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, bp.Buildpack.ID, "some-buildpack")
+			h.AssertEq(t, bp.Buildpack.Version, "1.2.3")
+			h.AssertEq(t, bp.Stacks[0].ID, "some-stack")
+		})
+
+		it("returns each missing field with the line of its table", func() {
+			writeDescriptor(`
+[buildpack]
+name = "Some Buildpack"
+
+[[stacks]]
+id = "some-stack"
+
+[[stacks]]
+mixins = ["some-mixin"]
+`)
+			_, err := buildpack.ReadDescriptor(dir)
+			errs, ok := err.(descriptor.ValidationErrors)
+			if !ok {
+				t.Fatalf("expected descriptor.ValidationErrors, got %#v", err)
+			}
+			h.AssertEq(t, len(errs), 2)
+			file := filepath.Join(dir, "buildpack.toml")
+			h.AssertError(t, err, file+":2: buildpack.id: is required")
+			h.AssertError(t, err, file+":8: stacks[1].id: is required")
+		})
+
+		it("reads buildpacks without a version", func() {
+			writeDescriptor("[buildpack]\nid = \"some-buildpack\"\n[[stacks]]\nid = \"some-stack\"\n")
+			bp, err := buildpack.ReadDescriptor(dir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, bp.Buildpack.Version, "")
+		})
+
+		it("returns invalid TOML with its line", func() {
+			writeDescriptor("[buildpack]\nid = \"some-buildpack\"\nversion = \n")
+			_, err := buildpack.ReadDescriptor(dir)
+			verr, ok := err.(*descriptor.ValidationError)
+			if !ok {
+				t.Fatalf("expected a *descriptor.ValidationError, got %#v", err)
+			}
+			h.AssertEq(t, verr.Line, 3)
+		})
+
+		it("rejects reserved ids and malformed apis", func() {
+			writeDescriptor("api = \"2\"\n[buildpack]\nid = \"app\"\nversion = \"1.2.3\"\n")
+			_, err := buildpack.ReadDescriptor(dir)
+			h.AssertError(t, err, ":1: api: must be of the form <major>.<minor>")
+			h.AssertError(t, err, ":3: buildpack.id: 'app' is reserved")
+		})
+	})
+
+	when("#Validate", func() {
+		it("accepts a valid buildpack", func() {
+			writeDescriptor(`
+[buildpack]
+id = "some-buildpack"
+version = "1.2.3"
+
+[[stacks]]
+id = "some-stack"
+
+[metadata]
+anything = "goes"
+`)
+			h.AssertNil(t, buildpack.Validate(dir))
+		})
+
+		it("returns unknown keys", func() {
+			writeDescriptor(`
+[buildpack]
+id = "some-buildpack"
+verison = "1.2.3"
+version = "1.2.3"
+
+[[stacks]]
+id = "some-stack"
+`)
+			h.AssertError(t, buildpack.Validate(dir), ":4: buildpack.verison: unknown key")
+		})
+
+		it("requires a version", func() {
+			writeDescriptor("[buildpack]\nid = \"some-buildpack\"\n[[stacks]]\nid = \"some-stack\"\n")
+			h.AssertError(t, buildpack.Validate(dir), ":1: buildpack.version: is required")
+		})
+
+		it("requires stacks or an order", func() {
+			writeDescriptor("[buildpack]\nid = \"some-buildpack\"\nversion = \"1.2.3\"\n")
+			h.AssertError(t, buildpack.Validate(dir), "buildpack must declare either stacks or an order")
+		})
+
+		it("accepts meta-buildpacks without executables", func() {
+			h.AssertNil(t, os.RemoveAll(filepath.Join(dir, "bin")))
+			writeDescriptor(`
+[buildpack]
+id = "some-meta-buildpack"
+version = "1.2.3"
+
+[[order]]
+[[order.group]]
+id = "some-buildpack"
+version = "1.2.3"
+`)
+			h.AssertNil(t, buildpack.Validate(dir))
+		})
+
+		it("returns missing and non-executable executables", func() {
+			if runtime.GOOS == "windows" {
+				t.Skip("executables are found by their extension on windows")
+			}
+			h.AssertNil(t, os.Remove(filepath.Join(dir, "bin", "detect")))
+			h.AssertNil(t, os.Chmod(filepath.Join(dir, "bin", "build"), 0644))
+			writeDescriptor("[buildpack]\nid = \"some-buildpack\"\nversion = \"1.2.3\"\n[[stacks]]\nid = \"some-stack\"\n")
+
+			err := buildpack.Validate(dir)
+			h.AssertError(t, err, filepath.Join("bin", "detect")+": is missing")
+			h.AssertError(t, err, filepath.Join("bin", "build")+": is not executable")
+		})
+	})
+
+	when("#DecodeOrder", func() {
+		it("decodes the groups", func() {
+			groups, err := buildpack.DecodeOrder("order.toml", []byte(`
+[[groups]]
+  [[groups.buildpacks]]
+  id = "some-buildpack"
+  version = "1.2.3"
+`))
+			h.AssertNil(t, err)
+			h.AssertEq(t, groups[0].Buildpacks[0].ID, "some-buildpack")
+		})
+
+		it("returns buildpacks without an id with their line", func() {
+			_, err := buildpack.DecodeOrder("order.toml", []byte(`
+[[groups]]
+  [[groups.buildpacks]]
+  id = "some-buildpack"
+
+[[groups]]
+  [[groups.buildpacks]]
+  version = "1.2.3"
+`))
+			h.AssertError(t, err, "order.toml:7: groups[1].buildpacks[0].id: is required")
+		})
+	})
+}
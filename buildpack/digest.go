@@ -0,0 +1,59 @@
+package buildpack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// digestSuffix separates the URI of a buildpack archive from the digest it must have
+const digestSuffix = "@sha256:"
+
+var sha256Pattern = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// SplitDigest splits a buildpack given as <uri>@sha256:<hex digest> into the URI and the digest,
+// which is empty when none is given
+func SplitDigest(bp string) (string, string, error) {
+	i := strings.LastIndex(bp, digestSuffix)
+	if i < 0 {
+		return bp, "", nil
+	}
+	uri, digest := bp[:i], strings.ToLower(bp[i+len(digestSuffix):])
+	if !sha256Pattern.MatchString(digest) {
+		return "", "", fmt.Errorf("invalid digest %q of buildpack %q, must be 64 hex characters", digest, uri)
+	}
+	return uri, digest, nil
+}
+
+// digestReader computes the sha256 digest of what is read from it
+type digestReader struct {
+	io.Reader
+	hash hash.Hash
+}
+
+func newDigestReader(r io.Reader) *digestReader {
+	h := sha256.New()
+	return &digestReader{Reader: io.TeeReader(r, h), hash: h}
+}
+
+// digest reads what remains, such as the padding after the end of a tar, and returns the digest
+// of all that was read
+func (r *digestReader) digest() (string, error) {
+	if _, err := io.Copy(ioutil.Discard, r.Reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(r.hash.Sum(nil)), nil
+}
+
+// checkDigest returns an error when the archive at uri has another digest than the expected one, if any
+func checkDigest(uri, expected, actual string) error {
+	if expected == "" || actual == expected {
+		return nil
+	}
+	return fmt.Errorf("buildpack archive %q has digest sha256:%s, expected sha256:%s", uri, actual, expected)
+}
@@ -0,0 +1,41 @@
+package buildpack_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/buildpack"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestDigest(t *testing.T) {
+	spec.Run(t, "Digest", testDigest, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testDigest(t *testing.T, when spec.G, it spec.S) {
+	when("#SplitDigest", func() {
+		digest := strings.Repeat("ab", 32)
+
+		it("splits the digest from the uri", func() {
+			uri, actual, err := buildpack.SplitDigest("https://user@example.com/bp.tgz@sha256:" + strings.ToUpper(digest))
+			h.AssertNil(t, err)
+			h.AssertEq(t, uri, "https://user@example.com/bp.tgz")
+			h.AssertEq(t, actual, digest)
+		})
+
+		it("returns buildpacks without a digest as they are", func() {
+			uri, actual, err := buildpack.SplitDigest("some/bp@1.2.3")
+			h.AssertNil(t, err)
+			h.AssertEq(t, uri, "some/bp@1.2.3")
+			h.AssertEq(t, actual, "")
+		})
+
+		it("fails for invalid digests", func() {
+			_, _, err := buildpack.SplitDigest("bp.tgz@sha256:abc")
+			h.AssertError(t, err, `invalid digest "abc" of buildpack "bp.tgz"`)
+		})
+	})
+}
@@ -34,6 +34,7 @@ func (f *Fetcher) FetchBuildpack(localSearchPath string, bp Buildpack) (out Buil
 		ID:      bp.ID,
 		URI:     bp.URI,
 		Latest:  bp.Latest,
+		SHA256:  bp.SHA256,
 		Version: bp.Version,
 	}
 
@@ -44,7 +45,7 @@ func (f *Fetcher) FetchBuildpack(localSearchPath string, bp Buildpack) (out Buil
 
 	switch bpURL.Scheme {
 	case "", "file":
-		out.Dir, err = f.handleFile(localSearchPath, bpURL)
+		out.Dir, err = f.handleFile(localSearchPath, bpURL, bp.SHA256)
 	case "http", "https":
 		out.Dir, err = f.handleHTTP(bp)
 	default:
@@ -54,7 +55,7 @@ func (f *Fetcher) FetchBuildpack(localSearchPath string, bp Buildpack) (out Buil
 	return out, err
 }
 
-func (f *Fetcher) handleFile(localSearchPath string, bpURL *url.URL) (string, error) {
+func (f *Fetcher) handleFile(localSearchPath string, bpURL *url.URL, digest string) (string, error) {
 	path := bpURL.Path
 
 	if !bpURL.IsAbs() && !filepath.IsAbs(path) {
@@ -62,6 +63,9 @@ func (f *Fetcher) handleFile(localSearchPath string, bpURL *url.URL) (string, er
 	}
 
 	if filepath.Ext(path) != ".tgz" {
+		if digest != "" {
+			return "", fmt.Errorf("buildpack %q is a directory, only the digests of .tgz archives are verified", path)
+		}
 		return path, nil
 	}
 
@@ -76,9 +80,20 @@ func (f *Fetcher) handleFile(localSearchPath string, bpURL *url.URL) (string, er
 		return "", fmt.Errorf(`failed to create temporary directory: %s`, err)
 	}
 
-	if err = archive.ExtractTarGZ(file, tmpDir); err != nil {
+	reader := newDigestReader(file)
+	if err = archive.ExtractTarGZ(reader, tmpDir); err != nil {
 		return "", err
 	}
+	if digest != "" {
+		actual, err := reader.digest()
+		if err == nil {
+			err = checkDigest(path, digest, actual)
+		}
+		if err != nil {
+			os.RemoveAll(tmpDir)
+			return "", err
+		}
+	}
 
 	return tmpDir, nil
 }
@@ -104,6 +119,14 @@ func (f *Fetcher) handleHTTP(bp Buildpack) (string, error) {
 		etag = string(bytes)
 	}
 
+	// the archive is downloaded again unless the cached one was verified to have the digest
+	digestFile := bpCache + ".sha256"
+	if bp.SHA256 != "" {
+		if cached, err := ioutil.ReadFile(digestFile); err != nil || string(cached) != bp.SHA256 {
+			etag = ""
+		}
+	}
+
 	reader, etag, err := f.downloadAsStream(bp.URI, etag)
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to download from %q", bp.URI)
@@ -112,13 +135,27 @@ func (f *Fetcher) handleHTTP(bp Buildpack) (string, error) {
 	}
 	defer reader.Close()
 
-	if err = archive.ExtractTarGZ(reader, bpCache); err != nil {
+	digestReader := newDigestReader(reader)
+	if err = archive.ExtractTarGZ(digestReader, bpCache); err != nil {
+		return "", err
+	}
+	actual, err := digestReader.digest()
+	if err != nil {
+		return "", err
+	}
+	if err := checkDigest(bp.URI, bp.SHA256, actual); err != nil {
+		os.RemoveAll(bpCache)
+		os.Remove(etagFile)
+		os.Remove(digestFile)
 		return "", err
 	}
 
 	if err = ioutil.WriteFile(etagFile, []byte(etag), 0744); err != nil {
 		return "", err
 	}
+	if err = ioutil.WriteFile(digestFile, []byte(actual), 0744); err != nil {
+		return "", err
+	}
 
 	return bpCache, nil
 }
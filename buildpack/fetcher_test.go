@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/fatih/color"
@@ -145,6 +146,37 @@ func testBuildpackFetcher(t *testing.T, when spec.G, it spec.S) {
 			h.AssertDirContainsFileWithContents(t, out.Dir, "bin/build", "I come from an archive\n")
 		})
 
+		it("verifies the digest of a tgz", func() {
+			bp := buildpack.Buildpack{
+				ID:     "bp.one",
+				URI:    filepath.Join("testdata", "buildpack.tgz"),
+				SHA256: strings.Repeat("0", 64),
+			}
+
+			_, err := subject.FetchBuildpack(".", bp)
+			h.AssertError(t, err, "expected sha256:"+strings.Repeat("0", 64))
+		})
+
+		it("does not cache downloads with another digest", func() {
+			server := ghttp.NewServer()
+			server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, filepath.Join("testdata", r.URL.Path))
+			})
+			defer server.Close()
+
+			bp := buildpack.Buildpack{
+				ID:     "bp.one",
+				URI:    server.URL() + "/buildpack.tgz",
+				SHA256: strings.Repeat("0", 64),
+			}
+
+			_, err := subject.FetchBuildpack(".", bp)
+			h.AssertError(t, err, "expected sha256:"+strings.Repeat("0", 64))
+			entries, err := ioutil.ReadDir(filepath.Join(cacheDir, "dl-cache"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(entries), 0)
+		})
+
 		it("fetches from a 'http(s)://' URI tgz", func() {
 			server := ghttp.NewServer()
 			server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
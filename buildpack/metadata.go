@@ -3,9 +3,11 @@ package buildpack
 import "strings"
 
 type Buildpack struct {
-	ID      string `toml:"id"`
-	URI     string `toml:"uri"`
-	Latest  bool   `toml:"latest"`
+	ID     string `toml:"id"`
+	URI    string `toml:"uri"`
+	Latest bool   `toml:"latest"`
+	// SHA256 is the hex encoded sha256 digest that the .tgz archive at URI must have, when set
+	SHA256  string `toml:"sha256"`
 	Dir     string
 	Version string
 }
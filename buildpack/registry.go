@@ -0,0 +1,74 @@
+package buildpack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Registry resolves buildpacks by ID and version against an index, a JSON document at a URL listing
+// where the .tgz archive of each version of each buildpack is downloaded from:
+//
+//	{"buildpacks": [{"id": "org/bp", "version": "1.0.0", "uri": "https://...", "sha256": "..."}]}
+type Registry struct {
+	URL string
+	// Timeout bounds fetching the index, so that builds do not hang on an unresponsive registry
+	Timeout time.Duration
+
+	once  sync.Once
+	index []Buildpack
+	err   error
+}
+
+func NewRegistry(url string) *Registry {
+	return &Registry{URL: url, Timeout: 30 * time.Second}
+}
+
+// Resolve returns the buildpack with the ID and version in the index, whose URI and SHA256 a Fetcher
+// downloads and verifies. The index is downloaded once, on first use.
+func (r *Registry) Resolve(id, version string) (Buildpack, error) {
+	r.once.Do(func() {
+		r.index, r.err = r.fetchIndex()
+	})
+	if r.err != nil {
+		return Buildpack{}, r.err
+	}
+	for _, bp := range r.index {
+		if bp.ID == id && bp.Version == version {
+			return bp, nil
+		}
+	}
+	return Buildpack{}, fmt.Errorf("buildpack '%s@%s' is not in registry %q", id, version, r.URL)
+}
+
+func (r *Registry) fetchIndex() ([]Buildpack, error) {
+	resp, err := (&http.Client{Timeout: r.Timeout}).Get(r.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching buildpack registry %q", r.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("could not fetch buildpack registry %q, code http status %d", r.URL, resp.StatusCode)
+	}
+
+	var index struct {
+		Buildpacks []struct {
+			ID      string `json:"id"`
+			Version string `json:"version"`
+			URI     string `json:"uri"`
+			SHA256  string `json:"sha256"`
+		} `json:"buildpacks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return nil, errors.Wrapf(err, "parsing buildpack registry %q", r.URL)
+	}
+	var buildpacks []Buildpack
+	for _, bp := range index.Buildpacks {
+		buildpacks = append(buildpacks, Buildpack{ID: bp.ID, Version: bp.Version, URI: bp.URI, SHA256: bp.SHA256})
+	}
+	return buildpacks, nil
+}
@@ -0,0 +1,68 @@
+package buildpack_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/buildpack"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestRegistry(t *testing.T) {
+	spec.Run(t, "Registry", testRegistry, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testRegistry(t *testing.T, when spec.G, it spec.S) {
+	var server *ghttp.Server
+
+	it.Before(func() {
+		server = ghttp.NewServer()
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	when("#Resolve", func() {
+		it("returns the buildpack with the ID and version from the index", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, `{"buildpacks": [
+  {"id": "some/bp", "version": "1.0", "uri": "https://example.com/some-bp-1.0.tgz"},
+  {"id": "some/bp", "version": "2.0", "uri": "https://example.com/some-bp-2.0.tgz", "sha256": "some-digest"}
+]}`))
+			subject := buildpack.NewRegistry(server.URL() + "/index.json")
+
+			bp, err := subject.Resolve("some/bp", "2.0")
+			h.AssertNil(t, err)
+			h.AssertEq(t, bp, buildpack.Buildpack{ID: "some/bp", Version: "2.0", URI: "https://example.com/some-bp-2.0.tgz", SHA256: "some-digest"})
+
+			_, err = subject.Resolve("some/bp", "3.0")
+			h.AssertError(t, err, "buildpack 'some/bp@3.0' is not in registry")
+			h.AssertEq(t, len(server.ReceivedRequests()), 1)
+		})
+
+		it("fails when the index cannot be fetched", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, ""))
+			subject := buildpack.NewRegistry(server.URL() + "/index.json")
+
+			_, err := subject.Resolve("some/bp", "1.0")
+			h.AssertError(t, err, "code http status 404")
+		})
+
+		it("fails when the index takes longer than the timeout", func() {
+			server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			})
+			subject := buildpack.NewRegistry(server.URL() + "/index.json")
+			subject.Timeout = 10 * time.Millisecond
+
+			_, err := subject.Resolve("some/bp", "1.0")
+			h.AssertError(t, err, "fetching buildpack registry")
+			h.AssertError(t, err, "Client.Timeout exceeded")
+		})
+	})
+}
@@ -0,0 +1,117 @@
+package buildpackage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/archive"
+	"github.com/buildpack/pack/buildpack"
+	"github.com/buildpack/pack/style"
+)
+
+// MetadataLabel describes the buildpacks in a buildpackage and the stacks they support
+const MetadataLabel = "io.buildpacks.buildpackage.metadata"
+
+// Config is the package.toml describing a buildpackage
+type Config struct {
+	Buildpacks []buildpack.Buildpack `toml:"buildpacks"`
+	Stacks     []Stack               `toml:"stacks"`
+}
+
+type Stack struct {
+	ID string `toml:"id" json:"id"`
+}
+
+type Metadata struct {
+	Buildpacks []BuildpackMetadata `json:"buildpacks"`
+	Stacks     []Stack             `json:"stacks"`
+}
+
+type BuildpackMetadata struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// ReadConfig reads and validates the package.toml at path
+func ReadConfig(path string) (Config, error) {
+	var config Config
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		return Config{}, errors.Wrapf(err, "failed to decode package config from file %s", style.Symbol(path))
+	}
+
+	if len(config.Buildpacks) == 0 {
+		return Config{}, errors.New("package config must contain at least one buildpack")
+	}
+	for _, bp := range config.Buildpacks {
+		if bp.URI == "" {
+			return Config{}, errors.New("buildpacks.uri is required")
+		}
+	}
+	if len(config.Stacks) == 0 {
+		return Config{}, errors.New("package config must contain at least one stack")
+	}
+	for _, s := range config.Stacks {
+		if s.ID == "" {
+			return Config{}, errors.New("stacks.id is required")
+		}
+	}
+	return config, nil
+}
+
+// NewImage creates a buildpackage image with a layer for each of the fetched buildpacks at
+// /buildpacks/<id>/<version>, as in builders. The layers are written to tmpDir, which must exist
+// until the image has been written.
+func NewImage(tmpDir string, buildpacks []buildpack.Buildpack, stacks []Stack) (v1.Image, *Metadata, error) {
+	img := empty.Image
+	metadata := &Metadata{Stacks: stacks}
+	for _, bp := range buildpacks {
+		descriptor, err := buildpack.ReadDescriptor(bp.Dir)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "reading buildpack.toml from buildpack: %s", bp.Dir)
+		}
+		if bp.ID != "" && bp.ID != descriptor.Buildpack.ID {
+			return nil, nil, fmt.Errorf("buildpack IDs did not match: %s != %s", bp.ID, descriptor.Buildpack.ID)
+		}
+		bp.ID, bp.Version = descriptor.Buildpack.ID, descriptor.Buildpack.Version
+
+		tarFile := filepath.Join(tmpDir, fmt.Sprintf("%s.%s.tar", bp.EscapedID(), bp.Version))
+		if err := archive.CreateTar(tarFile, bp.Dir, filepath.Join("/buildpacks", bp.EscapedID(), bp.Version), 0, 0); err != nil {
+			return nil, nil, errors.Wrapf(err, "creating layer for buildpack %s", style.Symbol(bp.ID))
+		}
+		layer, err := tarball.LayerFromFile(tarFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if img, err = mutate.AppendLayers(img, layer); err != nil {
+			return nil, nil, errors.Wrapf(err, "adding layer for buildpack %s", style.Symbol(bp.ID))
+		}
+		metadata.Buildpacks = append(metadata.Buildpacks, BuildpackMetadata{ID: bp.ID, Version: bp.Version})
+	}
+
+	label, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return nil, nil, err
+	}
+	config := configFile.Config
+	config.Labels = map[string]string{MetadataLabel: string(label)}
+	if img, err = mutate.Config(img, config); err != nil {
+		return nil, nil, err
+	}
+	if img, err = mutate.CreatedAt(img, v1.Time{Time: time.Now()}); err != nil {
+		return nil, nil, err
+	}
+	return img, metadata, nil
+}
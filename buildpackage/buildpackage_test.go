@@ -0,0 +1,98 @@
+package buildpackage_test
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/buildpack"
+	"github.com/buildpack/pack/buildpackage"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBuildpackage(t *testing.T) {
+	spec.Run(t, "Buildpackage", testBuildpackage, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuildpackage(t *testing.T, when spec.G, it spec.S) {
+	when("#ReadConfig", func() {
+		it("reads the buildpacks and stacks", func() {
+			config, err := buildpackage.ReadConfig(filepath.Join("testdata", "package.toml"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.Buildpacks, []buildpack.Buildpack{{URI: "../../testdata/buildpack"}})
+			h.AssertEq(t, config.Stacks, []buildpackage.Stack{{ID: "some.stack.id"}})
+		})
+
+		it("requires stacks", func() {
+			_, err := buildpackage.ReadConfig(filepath.Join("testdata", "no-stacks.toml"))
+			h.AssertError(t, err, "package config must contain at least one stack")
+		})
+	})
+
+	when("#NewImage", func() {
+		var tmpDir string
+
+		it.Before(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "buildpackage-test")
+			h.AssertNil(t, err)
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.RemoveAll(tmpDir))
+		})
+
+		it("adds a layer for each buildpack and the metadata label", func() {
+			img, metadata, err := buildpackage.NewImage(
+				tmpDir,
+				[]buildpack.Buildpack{{Dir: filepath.Join("..", "testdata", "buildpack")}},
+				[]buildpackage.Stack{{ID: "some.stack.id"}},
+			)
+			h.AssertNil(t, err)
+			h.AssertEq(t, metadata, &buildpackage.Metadata{
+				Buildpacks: []buildpackage.BuildpackMetadata{{ID: "some-buildpack-id", Version: "some-buildpack-version"}},
+				Stacks:     []buildpackage.Stack{{ID: "some.stack.id"}},
+			})
+
+			configFile, err := img.ConfigFile()
+			h.AssertNil(t, err)
+			var label buildpackage.Metadata
+			h.AssertNil(t, json.Unmarshal([]byte(configFile.Config.Labels[buildpackage.MetadataLabel]), &label))
+			h.AssertEq(t, &label, metadata)
+
+			layers, err := img.Layers()
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(layers), 1)
+			rc, err := layers[0].Uncompressed()
+			h.AssertNil(t, err)
+			defer rc.Close()
+			var names []string
+			tr := tar.NewReader(rc)
+			for {
+				header, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				h.AssertNil(t, err)
+				names = append(names, header.Name)
+			}
+			h.AssertSliceContains(t, names, "/buildpacks/some-buildpack-id/some-buildpack-version/buildpack.toml")
+		})
+
+		it("fails when the buildpack ID does not match its buildpack.toml", func() {
+			_, _, err := buildpackage.NewImage(
+				tmpDir,
+				[]buildpack.Buildpack{{ID: "other-id", Dir: filepath.Join("..", "testdata", "buildpack")}},
+				nil,
+			)
+			h.AssertError(t, err, "buildpack IDs did not match: other-id != some-buildpack-id")
+		})
+	})
+}
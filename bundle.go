@@ -0,0 +1,278 @@
+package pack
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/buildpack/lifecycle/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/keychain"
+	"github.com/buildpack/pack/style"
+)
+
+// bundleMetadataFile is added to the `docker save` archive of a bundle to describe its images
+const bundleMetadataFile = "pack-bundle.json"
+
+// BundleFactory saves a builder, its run image and the buildpacks in the builder into a single
+// archive, and loads such archives on machines without access to the registries they came from
+type BundleFactory struct {
+	Logger  Logger
+	Config  *config.Config
+	Fetcher Fetcher
+	Docker  Docker
+}
+
+type SaveBundleFlags struct {
+	Builder string
+	NoPull  bool
+}
+
+type LoadBundleFlags struct {
+	// Registry, when set, is the registry the images are pushed to rather than loaded into the
+	// docker daemon
+	Registry string
+}
+
+// BundleInfo describes the images in a bundle
+type BundleInfo struct {
+	Builder    string          `json:"builder"`
+	RunImage   string          `json:"runImage"`
+	Buildpacks []BuildpackInfo `json:"buildpacks"`
+}
+
+// Save writes the builder and its run image to path as an archive that `docker load` also accepts
+func (f *BundleFactory) Save(ctx context.Context, path string, flags SaveBundleFlags) (*BundleInfo, error) {
+	builderName, err := bundleImageName(flags.Builder)
+	if err != nil {
+		return nil, err
+	}
+	builderImage, err := f.fetchImage(ctx, builderName, flags.NoPull)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := builder.NewBuilder(builderImage, f.Config).GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	runImageName, err := bundleImageName(metadata.Stack.RunImage.Image)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.fetchImage(ctx, runImageName, flags.NoPull); err != nil {
+		return nil, err
+	}
+
+	info := &BundleInfo{Builder: builderName, RunImage: runImageName}
+	for _, bp := range metadata.Buildpacks {
+		info.Buildpacks = append(info.Buildpacks, buildpackMetadataToInfo(bp))
+	}
+
+	f.Logger.Verbose("Saving images %s and %s", style.Symbol(builderName), style.Symbol(runImageName))
+	images, err := f.Docker.ImageSave(ctx, []string{builderName, runImageName})
+	if err != nil {
+		return nil, errors.Wrap(err, "saving images")
+	}
+	defer images.Close()
+
+	// write to a temporary file beside path, so that a failed save does not leave a partial bundle
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmpFile.Name())
+	if err := writeBundle(tmpFile, images, info); err != nil {
+		tmpFile.Close()
+		return nil, errors.Wrapf(err, "writing bundle %s", style.Symbol(path))
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Load loads the images of the bundle at path into the docker daemon, or pushes them to
+// flags.Registry. When pushed, the copy of the run image is configured as a local mirror of the
+// run image, so that builds with the builder use it.
+func (f *BundleFactory) Load(ctx context.Context, path string, flags LoadBundleFlags) (*BundleInfo, error) {
+	info, err := readBundleInfo(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags.Registry == "" {
+		return info, f.loadIntoDaemon(ctx, path)
+	}
+
+	loaded := &BundleInfo{Buildpacks: info.Buildpacks}
+	if loaded.Builder, err = pushBundleImage(path, info.Builder, flags.Registry, registryTransport(f.Config)); err != nil {
+		return nil, err
+	}
+	f.Logger.Verbose("Pushed builder to %s", style.Symbol(loaded.Builder))
+	if loaded.RunImage, err = pushBundleImage(path, info.RunImage, flags.Registry, registryTransport(f.Config)); err != nil {
+		return nil, err
+	}
+	f.Logger.Verbose("Pushed run image to %s", style.Symbol(loaded.RunImage))
+
+	mirrors := []string{loaded.RunImage}
+	if runImage := f.Config.GetRunImage(info.RunImage); runImage != nil {
+		for _, mirror := range runImage.Mirrors {
+			if mirror != loaded.RunImage {
+				mirrors = append(mirrors, mirror)
+			}
+		}
+	}
+	if err := f.Config.SetRunImageMirrors(info.RunImage, mirrors); err != nil {
+		return nil, errors.Wrap(err, "saving run image mirrors")
+	}
+	return loaded, nil
+}
+
+func (f *BundleFactory) fetchImage(ctx context.Context, name string, noPull bool) (image.Image, error) {
+	var (
+		img image.Image
+		err error
+	)
+	if noPull {
+		img, err = f.Fetcher.FetchLocalImage(name)
+	} else {
+		img, err = f.Fetcher.FetchUpdatedLocalImage(ctx, name, ioutil.Discard)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if found, err := img.Found(); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, fmt.Errorf("image %s does not exist", style.Symbol(name))
+	}
+	return img, nil
+}
+
+func (f *BundleFactory) loadIntoDaemon(ctx context.Context, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	res, err := f.Docker.ImageLoad(ctx, file, true)
+	if err != nil {
+		return errors.Wrapf(err, "loading bundle %s", style.Symbol(path))
+	}
+	defer res.Body.Close()
+	if err := jsonmessage.DisplayJSONMessagesStream(res.Body, ioutil.Discard, 0, false, nil); err != nil {
+		return errors.Wrapf(err, "loading bundle %s", style.Symbol(path))
+	}
+	return nil
+}
+
+// bundleImageName returns the fully qualified name of image, so that saving it does not save
+// every tag of its repository
+func bundleImageName(image string) (string, error) {
+	tag, err := name.NewTag(image, name.WeakValidation)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid image name %s", style.Symbol(image))
+	}
+	return tag.Name(), nil
+}
+
+// writeBundle copies the `docker save` archive images to w, followed by the bundle metadata
+func writeBundle(w io.Writer, images io.Reader, info *BundleInfo) error {
+	tr := tar.NewReader(images)
+	tw := tar.NewWriter(w)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: bundleMetadataFile, Mode: 0644, Size: int64(len(b))}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(b); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func readBundleInfo(path string) (*BundleInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s is not a bundle created by 'pack bundle save'", style.Symbol(path))
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "reading bundle %s", style.Symbol(path))
+		}
+		if header.Name != bundleMetadataFile {
+			continue
+		}
+		var info BundleInfo
+		if err := json.NewDecoder(tr).Decode(&info); err != nil {
+			return nil, errors.Wrapf(err, "parsing metadata of bundle %s", style.Symbol(path))
+		}
+		return &info, nil
+	}
+}
+
+// pushBundleImage pushes the image named imageName in the bundle to the same repository and tag
+// on registry, returning its new name
+func pushBundleImage(path, imageName, registry string, transport http.RoundTripper) (string, error) {
+	tag, err := name.NewTag(imageName, name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+	img, err := tarball.ImageFromPath(path, &tag)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading image %s from bundle", style.Symbol(imageName))
+	}
+
+	target, err := name.NewTag(fmt.Sprintf("%s/%s:%s", registry, tag.RepositoryStr(), tag.TagStr()), name.WeakValidation)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid registry %s", style.Symbol(registry))
+	}
+	auth, err := keychain.Default.Resolve(target.Context().Registry)
+	if err != nil {
+		return "", err
+	}
+	if err := remote.Write(target, img, auth, transport); err != nil {
+		return "", errors.Wrapf(err, "pushing image %s", style.Symbol(target.String()))
+	}
+	return target.String(), nil
+}
@@ -0,0 +1,167 @@
+package pack_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	imgtest "github.com/buildpack/lifecycle/testhelpers"
+	"github.com/docker/docker/api/types"
+	"github.com/fatih/color"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBundle(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Bundle", testBundle, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBundle(t *testing.T, when spec.G, it spec.S) {
+	var (
+		mockController *gomock.Controller
+		mockFetcher    *mocks.MockFetcher
+		mockDocker     *mocks.MockDocker
+		factory        *pack.BundleFactory
+		tmpDir         string
+		builderImage   *imgtest.FakeImage
+		runImage       *imgtest.FakeImage
+		outBuf         bytes.Buffer
+	)
+
+	const (
+		builderName  = "index.docker.io/some/builder:latest"
+		runImageName = "index.docker.io/some/run:latest"
+	)
+
+	it.Before(func() {
+		var err error
+		mockController = gomock.NewController(t)
+		mockFetcher = mocks.NewMockFetcher(mockController)
+		mockDocker = mocks.NewMockDocker(mockController)
+		factory = &pack.BundleFactory{
+			Logger:  logging.NewLogger(&outBuf, &outBuf, false, false),
+			Config:  &config.Config{},
+			Fetcher: mockFetcher,
+			Docker:  mockDocker,
+		}
+		tmpDir, err = ioutil.TempDir("", "pack.bundle.test")
+		h.AssertNil(t, err)
+
+		builderImage = imgtest.NewFakeImage(t, builderName, "", "")
+		h.AssertNil(t, builderImage.SetLabel("io.buildpacks.builder.metadata", `{
+  "stack": {"runImage": {"image": "some/run"}},
+  "buildpacks": [{"id": "some.bp", "version": "1.2.3"}]
+}`))
+		runImage = imgtest.NewFakeImage(t, runImageName, "", "")
+	})
+
+	it.After(func() {
+		mockController.Finish()
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	// dockerSave returns a `docker save` archive of random images with the given names
+	dockerSave := func(names ...string) *bytes.Buffer {
+		t.Helper()
+		images := map[name.Tag]v1.Image{}
+		for _, n := range names {
+			tag, err := name.NewTag(n, name.WeakValidation)
+			h.AssertNil(t, err)
+			images[tag], err = random.Image(1024, 1)
+			h.AssertNil(t, err)
+		}
+		var buf bytes.Buffer
+		h.AssertNil(t, tarball.MultiWrite(images, &buf))
+		return &buf
+	}
+
+	when("#Save", func() {
+		var bundlePath string
+
+		it.Before(func() {
+			bundlePath = filepath.Join(tmpDir, "bundle.tar")
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), builderName, gomock.Any()).Return(builderImage, nil)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), runImageName, gomock.Any()).Return(runImage, nil)
+		})
+
+		it("saves the builder and its run image", func() {
+			mockDocker.EXPECT().
+				ImageSave(gomock.Any(), []string{builderName, runImageName}).
+				Return(ioutil.NopCloser(dockerSave(builderName, runImageName)), nil)
+
+			info, err := factory.Save(context.TODO(), bundlePath, pack.SaveBundleFlags{Builder: "some/builder"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, info.Builder, builderName)
+			h.AssertEq(t, info.RunImage, runImageName)
+			h.AssertEq(t, info.Buildpacks, []pack.BuildpackInfo{{ID: "some.bp", Version: "1.2.3"}})
+
+			for _, n := range []string{builderName, runImageName} {
+				tag, err := name.NewTag(n, name.WeakValidation)
+				h.AssertNil(t, err)
+				_, err = tarball.ImageFromPath(bundlePath, &tag)
+				h.AssertNil(t, err)
+			}
+		})
+
+		it("leaves no archive when saving fails", func() {
+			mockDocker.EXPECT().
+				ImageSave(gomock.Any(), gomock.Any()).
+				Return(ioutil.NopCloser(strings.NewReader("not a tar")), nil)
+
+			_, err := factory.Save(context.TODO(), bundlePath, pack.SaveBundleFlags{Builder: "some/builder"})
+			h.AssertError(t, err, "writing bundle")
+
+			files, err := ioutil.ReadDir(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(files), 0)
+		})
+	})
+
+	when("#Load", func() {
+		it("loads a saved bundle into the daemon", func() {
+			bundlePath := filepath.Join(tmpDir, "bundle.tar")
+			mockFetcher.EXPECT().FetchLocalImage(builderName).Return(builderImage, nil)
+			mockFetcher.EXPECT().FetchLocalImage(runImageName).Return(runImage, nil)
+			mockDocker.EXPECT().
+				ImageSave(gomock.Any(), gomock.Any()).
+				Return(ioutil.NopCloser(dockerSave(builderName, runImageName)), nil)
+			_, err := factory.Save(context.TODO(), bundlePath, pack.SaveBundleFlags{Builder: "some/builder", NoPull: true})
+			h.AssertNil(t, err)
+
+			mockDocker.EXPECT().
+				ImageLoad(gomock.Any(), gomock.Any(), true).
+				Return(types.ImageLoadResponse{
+					Body: ioutil.NopCloser(strings.NewReader(`{"stream":"Loaded image: some/builder:latest\n"}`)),
+				}, nil)
+
+			info, err := factory.Load(context.TODO(), bundlePath, pack.LoadBundleFlags{})
+			h.AssertNil(t, err)
+			h.AssertEq(t, info.Builder, builderName)
+			h.AssertEq(t, info.RunImage, runImageName)
+		})
+
+		it("fails for archives not created by save", func() {
+			archivePath := filepath.Join(tmpDir, "images.tar")
+			h.AssertNil(t, ioutil.WriteFile(archivePath, dockerSave(builderName).Bytes(), 0644))
+
+			_, err := factory.Load(context.TODO(), archivePath, pack.LoadBundleFlags{})
+			h.AssertError(t, err, "is not a bundle created by 'pack bundle save'")
+		})
+	})
+}
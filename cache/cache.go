@@ -4,6 +4,11 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
@@ -13,12 +18,59 @@ import (
 	"github.com/buildpack/pack/docker"
 )
 
+// namePrefix starts the names of the cache images and volumes kept in the docker daemon, and of the
+// directories of bind caches
+const namePrefix = "pack-cache-"
+
+// Type is how a cache of builds is kept by the docker daemon
+type Type string
+
+const (
+	// TypeImage keeps the cache in an image that each build commits, which works with any daemon,
+	// including remote daemons and those of docker-machine
+	TypeImage Type = "image"
+	// TypeVolume keeps the cache in a volume, which saves committing an image after each build
+	TypeVolume Type = "volume"
+	// TypeBind keeps the cache in a directory of the host, within the directory given to
+	// WithBindRoot. The daemon must run on the same machine as pack.
+	TypeBind Type = "bind"
+)
+
+func (t Type) String() string {
+	if t == "" {
+		return string(TypeImage)
+	}
+	return string(t)
+}
+
+// Set implements pflag.Value so the type can be bound directly to a command line flag
+func (t *Type) Set(s string) error {
+	switch Type(s) {
+	case TypeImage, TypeVolume, TypeBind:
+		*t = Type(s)
+		return nil
+	}
+	return fmt.Errorf("unknown cache type '%s', must be one of 'image', 'volume' or 'bind'", s)
+}
+
+func (t *Type) Type() string {
+	return "type"
+}
+
 type Cache struct {
 	docker *docker.Client
-	image  string
+	typ    Type
+	// name is the name of the cache image or volume, or of the directory of a bind cache
+	name string
+	// bindRoot is the host directory holding the directories of bind caches
+	bindRoot string
+	// dir is the host directory of a bind cache
+	dir string
 }
 
-func New(repoName string, dockerClient *docker.Client) (*Cache, error) {
+// New returns the cache of the builds of repoName, kept as cacheType says, which defaults to
+// TypeImage when empty
+func New(repoName string, dockerClient *docker.Client, cacheType Type, ops ...func(*Cache)) (*Cache, error) {
 	ref, err := name.ParseReference(repoName, name.WeakValidation)
 	if err != nil {
 		return nil, errors.Wrap(err, "bad image identifier")
@@ -26,22 +78,305 @@ func New(repoName string, dockerClient *docker.Client) (*Cache, error) {
 
 	sum := sha256.Sum256([]byte(ref.String()))
 
-	return &Cache{
-		image:  fmt.Sprintf("pack-cache-%x", sum[:6]),
+	c := &Cache{
+		typ:    cacheType,
+		name:   fmt.Sprintf("%s%x", namePrefix, sum[:6]),
 		docker: dockerClient,
-	}, nil
+	}
+	for _, op := range ops {
+		op(c)
+	}
+	switch c.typ {
+	case "":
+		c.typ = TypeImage
+	case TypeImage, TypeVolume:
+	case TypeBind:
+		if c.bindRoot == "" {
+			return nil, errors.New("bind caches need a host directory to be kept in")
+		}
+		if c.dir, err = filepath.Abs(filepath.Join(c.bindRoot, c.name)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown cache type '%s'", c.typ)
+	}
+	return c, nil
+}
+
+// WithBindRoot keeps bind caches in a directory of their own within root, a directory of the host
+func WithBindRoot(root string) func(*Cache) {
+	return func(c *Cache) {
+		c.bindRoot = root
+	}
 }
 
+func (c *Cache) Type() Type {
+	return c.typ
+}
+
+// Image is the name of the cache image, or empty when the cache is kept in a directory
 func (c *Cache) Image() string {
-	return c.image
+	if c.typ != TypeImage {
+		return ""
+	}
+	return c.name
+}
+
+// Dir is the volume or host directory that the phases mount to reach the cache, or empty when the
+// cache is kept in an image
+func (c *Cache) Dir() string {
+	switch c.typ {
+	case TypeVolume:
+		return c.name
+	case TypeBind:
+		return c.dir
+	}
+	return ""
+}
+
+// Remote is false, as the phases reach the cache image through the docker daemon
+func (c *Cache) Remote() bool {
+	return false
+}
+
+// Lock waits until no other build in this process uses the cache, returning the function that
+// releases it
+func (c *Cache) Lock(ctx context.Context) (func(), error) {
+	return lock(ctx, c.Image()+c.Dir())
 }
 
+// Clear removes the cache image, volume or host directory, so that the next build starts over
+// without cached layers. Cache images are removed together with the volume of the same name, which
+// builds running the creator keep the cache in. It succeeds when there is no cache.
 func (c *Cache) Clear(ctx context.Context) error {
-	_, err := c.docker.ImageRemove(ctx, c.Image(), types.ImageRemoveOptions{
-		Force: true,
-	})
+	var err error
+	switch c.typ {
+	case TypeVolume:
+		err = c.docker.VolumeRemove(ctx, c.name, true)
+	case TypeBind:
+		return os.RemoveAll(c.dir)
+	default:
+		_, err = c.docker.ImageRemove(ctx, c.Image(), types.ImageRemoveOptions{
+			Force: true,
+		})
+		if err == nil || client.IsErrNotFound(err) {
+			// the creator keeps the cache in a volume named after the image
+			err = c.docker.VolumeRemove(ctx, c.name, true)
+		}
+	}
 	if err != nil && !client.IsErrNotFound(err) {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// Exists reports whether there is a cache image, volume or host directory. Unlike Size, it does not
+// ask the daemon for the disk usage of every volume.
+func (c *Cache) Exists(ctx context.Context) (bool, error) {
+	var err error
+	switch c.typ {
+	case TypeVolume:
+		_, err = c.docker.VolumeInspect(ctx, c.name)
+	case TypeBind:
+		_, err = os.Stat(c.dir)
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return err == nil, err
+	default:
+		_, _, err = c.docker.ImageInspectWithRaw(ctx, c.Image())
+	}
+	if client.IsErrNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Size returns the size of the cache, or zero when there is none. The size of a volume is only
+// known when the daemon reports it when inspecting the volume, and is -1 otherwise.
+func (c *Cache) Size(ctx context.Context) (int64, error) {
+	switch c.typ {
+	case TypeVolume:
+		vol, err := c.docker.VolumeInspect(ctx, c.name)
+		if client.IsErrNotFound(err) {
+			return 0, nil
+		} else if err != nil {
+			return 0, err
+		}
+		if vol.UsageData == nil {
+			return -1, nil
+		}
+		return vol.UsageData.Size, nil
+	case TypeBind:
+		size, _, err := walkDir(c.dir)
+		return size, err
+	}
+	inspect, _, err := c.docker.ImageInspectWithRaw(ctx, c.Image())
+	if client.IsErrNotFound(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return inspect.Size, nil
+}
+
+// Prune clears the cache when it was last written more than olderThan ago. Volumes are pruned by
+// when they were created, as the daemon does not record when they were last written.
+func (c *Cache) Prune(ctx context.Context, olderThan time.Duration) error {
+	written, err := c.written(ctx)
+	if err != nil || written.IsZero() {
+		return err
+	}
+	if time.Since(written) < olderThan {
+		return nil
+	}
+	return c.Clear(ctx)
+}
+
+// written returns when the cache was last written, or the zero time when there is no cache
+func (c *Cache) written(ctx context.Context) (time.Time, error) {
+	switch c.typ {
+	case TypeVolume:
+		vol, err := c.docker.VolumeInspect(ctx, c.name)
+		if client.IsErrNotFound(err) {
+			return time.Time{}, nil
+		} else if err != nil {
+			return time.Time{}, err
+		}
+		created, err := time.Parse(time.RFC3339, vol.CreatedAt)
+		return created, errors.Wrapf(err, "parsing creation time of cache volume %s", c.name)
+	case TypeBind:
+		_, modTime, err := walkDir(c.dir)
+		return modTime, err
+	}
+	inspect, _, err := c.docker.ImageInspectWithRaw(ctx, c.Image())
+	if client.IsErrNotFound(err) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	created, err := time.Parse(time.RFC3339Nano, inspect.Created)
+	return created, errors.Wrapf(err, "parsing creation time of cache image %s", c.Image())
+}
+
+// walkDir returns the size of the files in dir and when the most recently written of them, or dir
+// itself, was modified. Both are zero when dir does not exist.
+func walkDir(dir string) (int64, time.Time, error) {
+	var (
+		size    int64
+		modTime time.Time
+	)
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() {
+			size += fi.Size()
+		}
+		if fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, time.Time{}, nil
+	}
+	return size, modTime, err
+}
+
+// Info describes a build cache
+type Info struct {
+	Type Type
+	// Name is the name of the cache image or volume, or the host directory of a bind cache
+	Name string
+	// Size is zero when the daemon does not report the size of a volume
+	Size int64
+	// Created is when the cache was last written, as each build recreates cache images. Volumes
+	// report when they were created, as the daemon does not record when they were last written.
+	Created time.Time
+}
+
+// Lister reports the disk usage of the images and volumes in the docker daemon, which
+// *docker.Client implements
+type Lister interface {
+	DiskUsage(ctx context.Context) (types.DiskUsage, error)
+}
+
+// List returns the cache images and volumes that pack created in the docker daemon, and the bind
+// caches within bindRoot when it is set
+func List(ctx context.Context, dockerClient Lister, bindRoot string) ([]Info, error) {
+	usage, err := dockerClient.DiskUsage(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing cache images and volumes")
+	}
+	var infos []Info
+	for _, img := range usage.Images {
+		for _, tag := range img.RepoTags {
+			if !strings.HasPrefix(tag, namePrefix) {
+				continue
+			}
+			infos = append(infos, Info{
+				Type:    TypeImage,
+				Name:    strings.TrimSuffix(tag, ":latest"),
+				Size:    img.Size,
+				Created: time.Unix(img.Created, 0),
+			})
+		}
+	}
+	for _, vol := range usage.Volumes {
+		if !strings.HasPrefix(vol.Name, namePrefix) {
+			continue
+		}
+		info := Info{Type: TypeVolume, Name: vol.Name}
+		// daemons that cannot tell the size of a volume report -1
+		if vol.UsageData != nil && vol.UsageData.Size > 0 {
+			info.Size = vol.UsageData.Size
+		}
+		// daemons that do not record when volumes were created leave CreatedAt empty
+		info.Created, _ = time.Parse(time.RFC3339, vol.CreatedAt)
+		infos = append(infos, info)
+	}
+	if bindRoot == "" {
+		return infos, nil
+	}
+
+	dirs, err := ioutil.ReadDir(bindRoot)
+	if os.IsNotExist(err) {
+		return infos, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "listing bind caches")
+	}
+	for _, fi := range dirs {
+		if !fi.IsDir() || !strings.HasPrefix(fi.Name(), namePrefix) {
+			continue
+		}
+		dir, err := filepath.Abs(filepath.Join(bindRoot, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		size, written, err := walkDir(dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading bind cache %s", dir)
+		}
+		infos = append(infos, Info{Type: TypeBind, Name: dir, Size: size, Created: written})
+	}
+	return infos, nil
+}
+
+// Remover removes images and volumes from the docker daemon, which *docker.Client implements
+type Remover interface {
+	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+	VolumeRemove(ctx context.Context, volumeID string, force bool) error
+}
+
+// Remove removes a cache returned by List
+func Remove(ctx context.Context, dockerClient Remover, info Info) error {
+	switch info.Type {
+	case TypeVolume:
+		return dockerClient.VolumeRemove(ctx, info.Name, true)
+	case TypeBind:
+		return os.RemoveAll(info.Name)
+	}
+	_, err := dockerClient.ImageRemove(ctx, info.Name, types.ImageRemoveOptions{Force: true})
+	return err
+}
@@ -3,12 +3,17 @@ package cache_test
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
 	"github.com/fatih/color"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
@@ -26,6 +31,10 @@ func TestCache(t *testing.T) {
 	spec.Run(t, "cache", testCache, spec.Parallel(), spec.Report(report.Terminal{}))
 }
 
+func TestBindCache(t *testing.T) {
+	spec.Run(t, "bind cache", testBindCache, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
 func testCache(t *testing.T, when spec.G, it spec.S) {
 	when("#New", func() {
 		var dockerClient *docker.Client
@@ -36,45 +45,45 @@ func testCache(t *testing.T, when spec.G, it spec.S) {
 		})
 
 		it("reusing the same cache for the same repo name", func() {
-			subject, err := cache.New("my/repo", dockerClient)
+			subject, err := cache.New("my/repo", dockerClient, cache.TypeImage)
 			h.AssertNil(t, err)
-			expected, _ := cache.New("my/repo", dockerClient)
+			expected, _ := cache.New("my/repo", dockerClient, cache.TypeImage)
 			if subject.Image() != expected.Image() {
 				t.Fatalf("The same repo name should result in the same volume")
 			}
 		})
 
 		it("supplies different volumes for different tags", func() {
-			subject, err := cache.New("my/repo:other-tag", dockerClient)
+			subject, err := cache.New("my/repo:other-tag", dockerClient, cache.TypeImage)
 			h.AssertNil(t, err)
-			notExpected, _ := cache.New("my/repo", dockerClient)
+			notExpected, _ := cache.New("my/repo", dockerClient, cache.TypeImage)
 			if subject.Image() == notExpected.Image() {
 				t.Fatalf("Different image tags should result in different volumes")
 			}
 		})
 
 		it("supplies different volumes for different registries", func() {
-			subject, err := cache.New("registry.com/my/repo:other-tag", dockerClient)
+			subject, err := cache.New("registry.com/my/repo:other-tag", dockerClient, cache.TypeImage)
 			h.AssertNil(t, err)
-			notExpected, _ := cache.New("my/repo", dockerClient)
+			notExpected, _ := cache.New("my/repo", dockerClient, cache.TypeImage)
 			if subject.Image() == notExpected.Image() {
 				t.Fatalf("Different image registries should result in different volumes")
 			}
 		})
 
 		it("resolves implied tag", func() {
-			subject, err := cache.New("my/repo:latest", dockerClient)
+			subject, err := cache.New("my/repo:latest", dockerClient, cache.TypeImage)
 			h.AssertNil(t, err)
-			expected, _ := cache.New("my/repo", dockerClient)
+			expected, _ := cache.New("my/repo", dockerClient, cache.TypeImage)
 			if subject.Image() != expected.Image() {
 				t.Fatalf("The same repo name should result in the same volume")
 			}
 		})
 
 		it("resolves implied registry", func() {
-			subject, err := cache.New("index.docker.io/my/repo", dockerClient)
+			subject, err := cache.New("index.docker.io/my/repo", dockerClient, cache.TypeImage)
 			h.AssertNil(t, err)
-			expected, _ := cache.New("my/repo", dockerClient)
+			expected, _ := cache.New("my/repo", dockerClient, cache.TypeImage)
 			if subject.Image() != expected.Image() {
 				t.Fatalf("The same repo name should result in the same volume")
 			}
@@ -95,7 +104,7 @@ func testCache(t *testing.T, when spec.G, it spec.S) {
 			h.AssertNil(t, err)
 			ctx = context.TODO()
 
-			subject, err = cache.New(h.RandString(10), dockerClient)
+			subject, err = cache.New(h.RandString(10), dockerClient, cache.TypeImage)
 			h.AssertNil(t, err)
 			imageName = subject.Image()
 		})
@@ -128,5 +137,279 @@ LABEL repo_name_for_randomisation=%s
 				h.AssertNil(t, err)
 			})
 		})
+
+		when("the cache is a volume", func() {
+			it.Before(func() {
+				var err error
+				subject, err = cache.New(h.RandString(10), dockerClient, cache.TypeVolume)
+				h.AssertNil(t, err)
+			})
+
+			it("removes the volume", func() {
+				_, err := dockerClient.VolumeCreate(ctx, volume.VolumeCreateBody{Name: subject.Dir()})
+				h.AssertNil(t, err)
+
+				h.AssertNil(t, subject.Clear(ctx))
+				_, err = dockerClient.VolumeInspect(ctx, subject.Dir())
+				h.AssertEq(t, client.IsErrNotFound(err), true)
+			})
+
+			it("does not fail when there is no volume", func() {
+				h.AssertNil(t, subject.Clear(ctx))
+			})
+		})
+	})
+
+	when("#Exists, #Size, #Prune and List", func() {
+		var (
+			dockerClient *docker.Client
+			subject      *cache.Cache
+			ctx          context.Context
+		)
+
+		it.Before(func() {
+			var err error
+			dockerClient, err = docker.New()
+			h.AssertNil(t, err)
+			ctx = context.TODO()
+
+			subject, err = cache.New(h.RandString(10), dockerClient, cache.TypeImage)
+			h.AssertNil(t, err)
+		})
+
+		it.After(func() {
+			h.AssertNil(t, subject.Clear(ctx))
+		})
+
+		when("there is no cache image", func() {
+			it("does not exist", func() {
+				exists, err := subject.Exists(ctx)
+				h.AssertNil(t, err)
+				h.AssertEq(t, exists, false)
+			})
+
+			it("has no size", func() {
+				size, err := subject.Size(ctx)
+				h.AssertNil(t, err)
+				h.AssertEq(t, size, int64(0))
+			})
+
+			it("prunes nothing", func() {
+				h.AssertNil(t, subject.Prune(ctx, 0))
+			})
+		})
+
+		when("there is a cache image", func() {
+			it.Before(func() {
+				h.CreateImageOnLocal(t, dockerClient, subject.Image(), fmt.Sprintf(`
+FROM busybox
+LABEL repo_name_for_randomisation=%s
+`, subject.Image()))
+			})
+
+			it("exists", func() {
+				exists, err := subject.Exists(ctx)
+				h.AssertNil(t, err)
+				h.AssertEq(t, exists, true)
+			})
+
+			it("returns its size", func() {
+				size, err := subject.Size(ctx)
+				h.AssertNil(t, err)
+				if size == 0 {
+					t.Fatalf("expected the cache image to have a size")
+				}
+			})
+
+			it("lists it", func() {
+				infos, err := cache.List(ctx, dockerClient, "")
+				h.AssertNil(t, err)
+				var found bool
+				for _, info := range infos {
+					if info.Type == cache.TypeImage && info.Name == subject.Image() {
+						found = true
+					}
+				}
+				h.AssertEq(t, found, true)
+			})
+
+			it("keeps it when it was written recently", func() {
+				h.AssertNil(t, subject.Prune(ctx, time.Hour))
+				_, _, err := dockerClient.ImageInspectWithRaw(ctx, subject.Image())
+				h.AssertNil(t, err)
+			})
+
+			it("removes it when it is older than the given age", func() {
+				h.AssertNil(t, subject.Prune(ctx, 0))
+				size, err := subject.Size(ctx)
+				h.AssertNil(t, err)
+				h.AssertEq(t, size, int64(0))
+			})
+		})
+
+		when("the cache is kept in a volume", func() {
+			it.Before(func() {
+				var err error
+				subject, err = cache.New(h.RandString(10), dockerClient, cache.TypeVolume)
+				h.AssertNil(t, err)
+			})
+
+			it("exists once the volume is created", func() {
+				exists, err := subject.Exists(ctx)
+				h.AssertNil(t, err)
+				h.AssertEq(t, exists, false)
+
+				_, err = dockerClient.VolumeCreate(ctx, volume.VolumeCreateBody{Name: subject.Dir()})
+				h.AssertNil(t, err)
+				exists, err = subject.Exists(ctx)
+				h.AssertNil(t, err)
+				h.AssertEq(t, exists, true)
+			})
+
+			it("has no size when there is no volume", func() {
+				size, err := subject.Size(ctx)
+				h.AssertNil(t, err)
+				h.AssertEq(t, size, int64(0))
+			})
+
+			when("there is a cache volume", func() {
+				it.Before(func() {
+					_, err := dockerClient.VolumeCreate(ctx, volume.VolumeCreateBody{Name: subject.Dir()})
+					h.AssertNil(t, err)
+				})
+
+				it("lists it", func() {
+					infos, err := cache.List(ctx, dockerClient, "")
+					h.AssertNil(t, err)
+					var found bool
+					for _, info := range infos {
+						if info.Type == cache.TypeVolume && info.Name == subject.Dir() {
+							found = true
+						}
+					}
+					h.AssertEq(t, found, true)
+				})
+
+				it("removes it", func() {
+					h.AssertNil(t, cache.Remove(ctx, dockerClient, cache.Info{Type: cache.TypeVolume, Name: subject.Dir()}))
+					exists, err := subject.Exists(ctx)
+					h.AssertNil(t, err)
+					h.AssertEq(t, exists, false)
+				})
+			})
+		})
+	})
+}
+
+func testBindCache(t *testing.T, when spec.G, it spec.S) {
+	var (
+		root    string
+		subject *cache.Cache
+		ctx     = context.TODO()
+	)
+
+	it.Before(func() {
+		var err error
+		root, err = ioutil.TempDir("", "bind-cache")
+		h.AssertNil(t, err)
+		subject, err = cache.New("my/repo", nil, cache.TypeBind, cache.WithBindRoot(root))
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		os.RemoveAll(root)
+	})
+
+	it("keeps the cache in a directory of its own within the root", func() {
+		h.AssertEq(t, filepath.Dir(subject.Dir()), root)
+		h.AssertEq(t, subject.Image(), "")
+		other, err := cache.New("my/other-repo", nil, cache.TypeBind, cache.WithBindRoot(root))
+		h.AssertNil(t, err)
+		if other.Dir() == subject.Dir() {
+			t.Fatalf("Different repo names should result in different directories")
+		}
+	})
+
+	it("needs a root", func() {
+		_, err := cache.New("my/repo", nil, cache.TypeBind)
+		h.AssertError(t, err, "bind caches need a host directory to be kept in")
+	})
+
+	when("there is no cache directory", func() {
+		it("does not exist", func() {
+			exists, err := subject.Exists(ctx)
+			h.AssertNil(t, err)
+			h.AssertEq(t, exists, false)
+		})
+
+		it("has no size", func() {
+			size, err := subject.Size(ctx)
+			h.AssertNil(t, err)
+			h.AssertEq(t, size, int64(0))
+		})
+
+		it("clears and prunes nothing", func() {
+			h.AssertNil(t, subject.Clear(ctx))
+			h.AssertNil(t, subject.Prune(ctx, 0))
+		})
+	})
+
+	when("there is a cache directory", func() {
+		it.Before(func() {
+			h.AssertNil(t, os.MkdirAll(filepath.Join(subject.Dir(), "layer"), 0755))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(subject.Dir(), "layer", "file"), []byte("some-content"), 0644))
+		})
+
+		it("exists", func() {
+			exists, err := subject.Exists(ctx)
+			h.AssertNil(t, err)
+			h.AssertEq(t, exists, true)
+		})
+
+		it("returns the size of its files", func() {
+			size, err := subject.Size(ctx)
+			h.AssertNil(t, err)
+			h.AssertEq(t, size, int64(len("some-content")))
+		})
+
+		it("removes it when cleared", func() {
+			h.AssertNil(t, subject.Clear(ctx))
+			_, err := os.Stat(subject.Dir())
+			h.AssertEq(t, os.IsNotExist(err), true)
+		})
+
+		it("keeps it when it was written recently", func() {
+			h.AssertNil(t, subject.Prune(ctx, time.Hour))
+			_, err := os.Stat(subject.Dir())
+			h.AssertNil(t, err)
+		})
+
+		it("removes it when it is older than the given age", func() {
+			h.AssertNil(t, subject.Prune(ctx, 0))
+			_, err := os.Stat(subject.Dir())
+			h.AssertEq(t, os.IsNotExist(err), true)
+		})
+
+		it("lists it", func() {
+			infos, err := cache.List(ctx, fakeLister{}, root)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(infos), 1)
+			h.AssertEq(t, infos[0].Type, cache.TypeBind)
+			h.AssertEq(t, infos[0].Name, subject.Dir())
+			h.AssertEq(t, infos[0].Size, int64(len("some-content")))
+		})
+
+		it("removes it", func() {
+			h.AssertNil(t, cache.Remove(ctx, nil, cache.Info{Type: cache.TypeBind, Name: subject.Dir()}))
+			_, err := os.Stat(subject.Dir())
+			h.AssertEq(t, os.IsNotExist(err), true)
+		})
 	})
 }
+
+// fakeLister is a daemon without cache images or volumes
+type fakeLister struct{}
+
+func (fakeLister) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	return types.DiskUsage{}, nil
+}
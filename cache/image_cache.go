@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pkg/errors"
+)
+
+// ImageCache keeps the cache of builds in an image at a registry rather than in the docker daemon,
+// so that builds on other machines, such as ephemeral CI runners, reuse it
+type ImageCache struct {
+	ref       name.Reference
+	keychain  authn.Keychain
+	transport http.RoundTripper
+}
+
+func NewImageCache(imageName string, keychain authn.Keychain, ops ...func(*ImageCache)) (*ImageCache, error) {
+	ref, err := name.ParseReference(imageName, name.WeakValidation)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad image identifier")
+	}
+	c := &ImageCache{ref: ref, keychain: keychain, transport: http.DefaultTransport}
+	for _, op := range ops {
+		op(c)
+	}
+	return c, nil
+}
+
+// WithTransport reaches the registry of the cache image with transport, such as one returned by
+// registry.NewTransport, rather than http.DefaultTransport
+func WithTransport(transport http.RoundTripper) func(*ImageCache) {
+	return func(c *ImageCache) {
+		c.transport = transport
+	}
+}
+
+// Dir is empty, as the cache is kept in an image
+func (c *ImageCache) Dir() string {
+	return ""
+}
+
+func (c *ImageCache) Image() string {
+	return c.ref.Name()
+}
+
+// Remote is true, as the phases reach the cache image through the registry
+func (c *ImageCache) Remote() bool {
+	return true
+}
+
+// Lock waits until no other build in this process uses the cache, returning the function that
+// releases it. Builds on other machines are not kept from using the cache image at the same time.
+func (c *ImageCache) Lock(ctx context.Context) (func(), error) {
+	return lock(ctx, c.Image())
+}
+
+// Clear deletes the cache image from the registry, if it exists
+func (c *ImageCache) Clear(ctx context.Context) error {
+	img, err := remote.Image(c.ref, remote.WithAuthFromKeychain(c.keychain), remote.WithTransport(c.transport))
+	if err != nil {
+		return err
+	}
+	digest, err := img.Digest()
+	if isNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	auth, err := c.keychain.Resolve(c.ref.Context().Registry)
+	if err != nil {
+		return err
+	}
+	// registries only delete manifests by digest
+	byDigest, err := name.NewDigest(c.ref.Context().Name()+"@"+digest.String(), name.WeakValidation)
+	if err != nil {
+		return err
+	}
+	if err := remote.Delete(byDigest, auth, c.transport); err != nil && !isNotFound(err) {
+		return errors.Wrapf(err, "deleting cache image %s", c.ref.Name())
+	}
+	return nil
+}
+
+// Exists reports whether there is a cache image at the registry
+func (c *ImageCache) Exists(ctx context.Context) (bool, error) {
+	img, err := remote.Image(c.ref, remote.WithAuthFromKeychain(c.keychain), remote.WithTransport(c.transport))
+	if err != nil {
+		return false, err
+	}
+	if _, err := img.RawManifest(); isNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Size returns the compressed size of the layers of the cache image, or zero when there is none
+func (c *ImageCache) Size(ctx context.Context) (int64, error) {
+	img, err := remote.Image(c.ref, remote.WithAuthFromKeychain(c.keychain), remote.WithTransport(c.transport))
+	if err != nil {
+		return 0, err
+	}
+	manifest, err := img.Manifest()
+	if isNotFound(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+	return size, nil
+}
+
+// Prune deletes the cache image when it was last written more than olderThan ago
+func (c *ImageCache) Prune(ctx context.Context, olderThan time.Duration) error {
+	img, err := remote.Image(c.ref, remote.WithAuthFromKeychain(c.keychain), remote.WithTransport(c.transport))
+	if err != nil {
+		return err
+	}
+	config, err := img.ConfigFile()
+	if isNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if time.Since(config.Created.Time) < olderThan {
+		return nil
+	}
+	return c.Clear(ctx)
+}
+
+func isNotFound(err error) bool {
+	if terr, ok := err.(*transport.Error); ok {
+		for _, d := range terr.Errors {
+			if d.Code == transport.ManifestUnknownErrorCode || d.Code == transport.NameUnknownErrorCode {
+				return true
+			}
+		}
+	}
+	return false
+}
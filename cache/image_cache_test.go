@@ -0,0 +1,201 @@
+package cache_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/cache"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestImageCache(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "ImageCache", testImageCache, spec.Report(report.Terminal{}))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func testImageCache(t *testing.T, when spec.G, it spec.S) {
+	var (
+		registry *httptest.Server
+		manifest []byte
+		config   []byte
+		deleted  []string
+	)
+
+	it.Before(func() {
+		manifest = nil
+		config = nil
+		deleted = nil
+		registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/v2/":
+				w.WriteHeader(http.StatusOK)
+			case r.Method == http.MethodGet && r.URL.Path == "/v2/some/cache/manifests/latest":
+				if manifest == nil {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+					return
+				}
+				w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+				w.Write(manifest)
+			case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v2/some/cache/blobs/"):
+				w.Write(config)
+			case r.Method == http.MethodDelete:
+				deleted = append(deleted, r.URL.Path)
+				w.WriteHeader(http.StatusAccepted)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	})
+
+	it.After(func() {
+		registry.Close()
+	})
+
+	newCache := func() *cache.ImageCache {
+		t.Helper()
+		subject, err := cache.NewImageCache(strings.TrimPrefix(registry.URL, "http://")+"/some/cache", authn.DefaultKeychain)
+		h.AssertNil(t, err)
+		return subject
+	}
+
+	it("is a remote cache named after the image", func() {
+		subject := newCache()
+		h.AssertEq(t, subject.Remote(), true)
+		h.AssertEq(t, subject.Image(), strings.TrimPrefix(registry.URL, "http://")+"/some/cache:latest")
+	})
+
+	it("reaches the registry with the given transport", func() {
+		var requests int
+		transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		subject, err := cache.NewImageCache(strings.TrimPrefix(registry.URL, "http://")+"/some/cache", authn.DefaultKeychain, cache.WithTransport(transport))
+		h.AssertNil(t, err)
+
+		exists, err := subject.Exists(context.Background())
+		h.AssertNil(t, err)
+		h.AssertEq(t, exists, false)
+		h.AssertNotEq(t, requests, 0)
+	})
+
+	setImage := func(img v1.Image) v1.Hash {
+		t.Helper()
+		var err error
+		manifest, err = img.RawManifest()
+		h.AssertNil(t, err)
+		config, err = img.RawConfigFile()
+		h.AssertNil(t, err)
+		digest, err := img.Digest()
+		h.AssertNil(t, err)
+		return digest
+	}
+
+	when("#Exists", func() {
+		it("is true when there is a cache image", func() {
+			img, err := random.Image(10, 1)
+			h.AssertNil(t, err)
+			setImage(img)
+
+			exists, err := newCache().Exists(context.Background())
+			h.AssertNil(t, err)
+			h.AssertEq(t, exists, true)
+		})
+
+		it("is false when there is no cache image", func() {
+			exists, err := newCache().Exists(context.Background())
+			h.AssertNil(t, err)
+			h.AssertEq(t, exists, false)
+		})
+	})
+
+	when("#Size", func() {
+		it("adds up the sizes of the layers", func() {
+			img, err := random.Image(10, 2)
+			h.AssertNil(t, err)
+			setImage(img)
+			layers, err := img.Layers()
+			h.AssertNil(t, err)
+			var expected int64
+			for _, layer := range layers {
+				size, err := layer.Size()
+				h.AssertNil(t, err)
+				expected += size
+			}
+
+			size, err := newCache().Size(context.Background())
+			h.AssertNil(t, err)
+			h.AssertEq(t, size, expected)
+		})
+
+		it("is zero when there is no cache image", func() {
+			size, err := newCache().Size(context.Background())
+			h.AssertNil(t, err)
+			h.AssertEq(t, size, int64(0))
+		})
+	})
+
+	when("#Prune", func() {
+		it("deletes a cache image written before the given age", func() {
+			img, err := random.Image(10, 1)
+			h.AssertNil(t, err)
+			img, err = mutate.CreatedAt(img, v1.Time{Time: time.Now().Add(-48 * time.Hour)})
+			h.AssertNil(t, err)
+			digest := setImage(img)
+
+			h.AssertNil(t, newCache().Prune(context.Background(), 24*time.Hour))
+			h.AssertEq(t, deleted, []string{"/v2/some/cache/manifests/" + digest.String()})
+		})
+
+		it("keeps a recently written cache image", func() {
+			img, err := random.Image(10, 1)
+			h.AssertNil(t, err)
+			img, err = mutate.CreatedAt(img, v1.Time{Time: time.Now()})
+			h.AssertNil(t, err)
+			setImage(img)
+
+			h.AssertNil(t, newCache().Prune(context.Background(), 24*time.Hour))
+			h.AssertEq(t, len(deleted), 0)
+		})
+
+		it("succeeds when there is no cache image", func() {
+			h.AssertNil(t, newCache().Prune(context.Background(), 24*time.Hour))
+			h.AssertEq(t, len(deleted), 0)
+		})
+	})
+
+	when("#Clear", func() {
+		it("deletes the cache image by digest", func() {
+			img, err := random.Image(10, 1)
+			h.AssertNil(t, err)
+			digest := setImage(img)
+
+			h.AssertNil(t, newCache().Clear(context.Background()))
+			h.AssertEq(t, deleted, []string{"/v2/some/cache/manifests/" + digest.String()})
+		})
+
+		it("succeeds when there is no cache image", func() {
+			h.AssertNil(t, newCache().Clear(context.Background()))
+			h.AssertEq(t, len(deleted), 0)
+		})
+	})
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// locks are held by the builds using each cache image in this process, keyed by the image name, so
+// that concurrent builds of the same image take turns rather than overwrite each other's cache
+var (
+	locksMu sync.Mutex
+	locks   = map[string]chan struct{}{}
+)
+
+// lock waits until no other build in this process holds the cache image, returning the function that
+// releases it. It takes the lock without waiting when it is free, even when ctx is done, so a done
+// context only tries to take it.
+func lock(ctx context.Context, image string) (func(), error) {
+	locksMu.Lock()
+	l, ok := locks[image]
+	if !ok {
+		l = make(chan struct{}, 1)
+		locks[image] = l
+	}
+	locksMu.Unlock()
+
+	unlock := func() { <-l }
+	select {
+	case l <- struct{}{}:
+		return unlock, nil
+	default:
+	}
+	select {
+	case l <- struct{}{}:
+		return unlock, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,80 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/cache"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestLock(t *testing.T) {
+	spec.Run(t, "Lock", testLock, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testLock(t *testing.T, when spec.G, it spec.S) {
+	var (
+		subject  *cache.Cache
+		repoName string
+	)
+
+	it.Before(func() {
+		var err error
+		repoName = "some/" + h.RandString(10)
+		subject, err = cache.New(repoName, nil, cache.TypeImage)
+		h.AssertNil(t, err)
+	})
+
+	it("waits until the other build releases the cache", func() {
+		unlock, err := subject.Lock(context.TODO())
+		h.AssertNil(t, err)
+
+		other, err := cache.New(repoName, nil, cache.TypeImage)
+		h.AssertNil(t, err)
+		locked := make(chan error)
+		go func() {
+			otherUnlock, err := other.Lock(context.TODO())
+			if err == nil {
+				otherUnlock()
+			}
+			locked <- err
+		}()
+
+		select {
+		case <-locked:
+			t.Fatal("expected the cache to stay locked")
+		case <-time.After(50 * time.Millisecond):
+		}
+		unlock()
+		h.AssertNil(t, <-locked)
+	})
+
+	it("takes a free cache even when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		unlock, err := subject.Lock(ctx)
+		h.AssertNil(t, err)
+		_, err = subject.Lock(ctx)
+		h.AssertError(t, err, "context canceled")
+		unlock()
+	})
+
+	it("does not wait for the builds of other images", func() {
+		unlock, err := subject.Lock(context.TODO())
+		h.AssertNil(t, err)
+		defer unlock()
+
+		other, err := cache.New("other/"+h.RandString(10), nil, cache.TypeImage)
+		h.AssertNil(t, err)
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		otherUnlock, err := other.Lock(ctx)
+		h.AssertNil(t, err)
+		otherUnlock()
+	})
+}
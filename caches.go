@@ -0,0 +1,60 @@
+package pack
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/cache"
+	"github.com/buildpack/pack/style"
+)
+
+// ListCaches returns the cache images and volumes that builds created in the docker daemon, and the
+// bind caches within cacheDir when it is set
+func (c *Client) ListCaches(ctx context.Context, cacheDir string) ([]cache.Info, error) {
+	return cache.List(ctx, c.docker, cacheDir)
+}
+
+// ClearCache removes the cache image and cache volume in the docker daemon of the builds of
+// repoName, and its bind cache within cacheDir when it is set, so that its next build starts over
+// without cached layers
+func (c *Client) ClearCache(ctx context.Context, repoName, cacheDir string) error {
+	cacheTypes := []cache.Type{cache.TypeImage, cache.TypeVolume}
+	if cacheDir != "" {
+		cacheTypes = append(cacheTypes, cache.TypeBind)
+	}
+	for _, typ := range cacheTypes {
+		cacheObj, err := cache.New(repoName, c.docker, typ, cache.WithBindRoot(cacheDir))
+		if err != nil {
+			return err
+		}
+		name := cacheObj.Image() + cacheObj.Dir()
+		if err := cacheObj.Clear(ctx); err != nil {
+			return errors.Wrapf(err, "removing cache %s %s", typ, style.Symbol(name))
+		}
+		c.logger.Verbose("Cache %s %s cleared", typ, style.Symbol(name))
+	}
+	return nil
+}
+
+// PruneCaches removes the caches listed by ListCaches which were last written more than olderThan
+// ago, returning those it removed. Builds of the images they belong to start over without cached
+// layers.
+func (c *Client) PruneCaches(ctx context.Context, olderThan time.Duration, cacheDir string) ([]cache.Info, error) {
+	infos, err := cache.List(ctx, c.docker, cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	var pruned []cache.Info
+	for _, info := range infos {
+		if time.Since(info.Created) < olderThan {
+			continue
+		}
+		if err := cache.Remove(ctx, c.docker, info); err != nil {
+			return pruned, errors.Wrapf(err, "removing cache %s %s", info.Type, style.Symbol(info.Name))
+		}
+		pruned = append(pruned, info)
+	}
+	return pruned, nil
+}
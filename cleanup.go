@@ -0,0 +1,196 @@
+package pack
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/cache"
+	"github.com/buildpack/pack/style"
+)
+
+// DefaultCleanupAge is how old the containers, volumes and builder images of builds must be before
+// Cleanup removes them, which leaves those of running builds alone
+const DefaultCleanupAge = time.Hour
+
+// the kinds of resources removed by Cleanup
+const (
+	ResourceContainer = "container"
+	ResourceVolume    = "volume"
+	ResourceImage     = "image"
+	ResourceCache     = "cache"
+)
+
+type CleanupOptions struct {
+	// OlderThan is how long ago the containers, volumes and builder images left behind by builds must
+	// have been created to be removed, DefaultCleanupAge when zero
+	OlderThan time.Duration
+	// CachesOlderThan, when set, also removes the caches last written longer ago, see
+	// Client.PruneCaches
+	CachesOlderThan time.Duration
+	// CacheDir is the host directory holding the bind caches removed with CachesOlderThan
+	CacheDir string
+	// DryRun returns the resources that would be removed without removing them
+	DryRun bool
+}
+
+// Resource is a container, volume or image in the docker daemon removed by Cleanup
+type Resource struct {
+	// Kind is one of ResourceContainer, ResourceVolume, ResourceImage or ResourceCache
+	Kind    string
+	Name    string
+	Created time.Time
+}
+
+// Cleanup removes the resources that builds leave behind in the docker daemon when pack crashes or
+// is killed: the stopped containers of phases, the volumes of the layers and app directory that no
+// container uses, and the builder images with the buildpacks of a build. Caches are only removed
+// when opts.CachesOlderThan is set. It returns the resources it removed.
+func (c *Client) Cleanup(ctx context.Context, opts CleanupOptions) ([]Resource, error) {
+	return Cleanup(ctx, c.docker, opts)
+}
+
+// Cleanup removes the resources left behind by builds in the daemon of the given docker client, see
+// Client.Cleanup
+func Cleanup(ctx context.Context, docker Docker, opts CleanupOptions) ([]Resource, error) {
+	if opts.OlderThan == 0 {
+		opts.OlderThan = DefaultCleanupAge
+	}
+	cl := &cleanup{docker: docker, opts: opts}
+	// the containers are removed first, so that the volumes they used are no longer in use
+	for _, step := range []func(context.Context) error{cl.containers, cl.volumes, cl.images, cl.caches} {
+		if err := step(ctx); err != nil {
+			return cl.removed, err
+		}
+	}
+	return cl.removed, nil
+}
+
+type cleanup struct {
+	docker  Docker
+	opts    CleanupOptions
+	removed []Resource
+}
+
+// stale reports whether a resource created at created is old enough to be removed. Resources whose
+// creation time is unknown are removed.
+func (cl *cleanup) stale(created time.Time) bool {
+	return created.IsZero() || time.Since(created) >= cl.opts.OlderThan
+}
+
+func (cl *cleanup) remove(r Resource, remove func() error) error {
+	if !cl.opts.DryRun {
+		if err := remove(); err != nil {
+			return errors.Wrapf(err, "removing %s %s", r.Kind, style.Symbol(r.Name))
+		}
+	}
+	cl.removed = append(cl.removed, r)
+	return nil
+}
+
+func (cl *cleanup) containers(ctx context.Context) error {
+	containers, err := cl.docker.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", "author=pack")),
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing containers")
+	}
+	for _, ctr := range containers {
+		created := time.Unix(ctr.Created, 0)
+		if ctr.State == "running" || !cl.stale(created) {
+			continue
+		}
+		r := Resource{Kind: ResourceContainer, Name: ctr.ID, Created: created}
+		if err := cl.remove(r, func() error {
+			return cl.docker.ContainerRemove(ctx, ctr.ID, types.ContainerRemoveOptions{Force: true})
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// volumes removes the layers and app volumes that no container uses, which are labeled 'author=pack'
+// or, when created by older versions of pack, named after their purpose
+func (cl *cleanup) volumes(ctx context.Context) error {
+	body, err := cl.docker.VolumeList(ctx, filters.NewArgs(filters.Arg("dangling", "true")))
+	if err != nil {
+		return errors.Wrap(err, "listing volumes")
+	}
+	sort.Slice(body.Volumes, func(i, j int) bool { return body.Volumes[i].Name < body.Volumes[j].Name })
+	for _, vol := range body.Volumes {
+		if vol.Labels["author"] != "pack" && !strings.HasPrefix(vol.Name, "pack-layers-") && !strings.HasPrefix(vol.Name, "pack-app-") {
+			continue
+		}
+		// daemons that do not record when volumes were created leave CreatedAt empty
+		created, _ := time.Parse(time.RFC3339, vol.CreatedAt)
+		if !cl.stale(created) {
+			continue
+		}
+		name := vol.Name
+		if err := cl.remove(Resource{Kind: ResourceVolume, Name: name, Created: created}, func() error {
+			return cl.docker.VolumeRemove(ctx, name, true)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// images removes the builder images that each build creates with the buildpacks given to it
+func (cl *cleanup) images(ctx context.Context) error {
+	images, err := cl.docker.ImageList(ctx, types.ImageListOptions{
+		Filters: filters.NewArgs(filters.Arg("reference", "pack.local/builder/*")),
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing builder images")
+	}
+	for _, img := range images {
+		created := time.Unix(img.Created, 0)
+		if !cl.stale(created) {
+			continue
+		}
+		for _, tag := range img.RepoTags {
+			if !strings.HasPrefix(tag, "pack.local/builder/") {
+				continue
+			}
+			if err := cl.remove(Resource{Kind: ResourceImage, Name: tag, Created: created}, func() error {
+				_, err := cl.docker.ImageRemove(ctx, tag, types.ImageRemoveOptions{Force: true})
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// caches removes the caches last written longer ago than opts.CachesOlderThan, like
+// Client.PruneCaches
+func (cl *cleanup) caches(ctx context.Context) error {
+	if cl.opts.CachesOlderThan == 0 {
+		return nil
+	}
+	infos, err := cache.List(ctx, cl.docker, cl.opts.CacheDir)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if time.Since(info.Created) < cl.opts.CachesOlderThan {
+			continue
+		}
+		info := info
+		if err := cl.remove(Resource{Kind: ResourceCache, Name: info.Name, Created: info.Created}, func() error {
+			return cache.Remove(ctx, cl.docker, info)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
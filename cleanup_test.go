@@ -0,0 +1,129 @@
+package pack_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestCleanup(t *testing.T) {
+	spec.Run(t, "Cleanup", testCleanup, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testCleanup(t *testing.T, when spec.G, it spec.S) {
+	var (
+		mockController *gomock.Controller
+		mockDocker     *mocks.MockDocker
+		old, recent    time.Time
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockDocker = mocks.NewMockDocker(mockController)
+		old = time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+		recent = time.Now().Add(-time.Minute).Truncate(time.Second)
+
+		mockDocker.EXPECT().ContainerList(gomock.Any(), types.ContainerListOptions{
+			All:     true,
+			Filters: filters.NewArgs(filters.Arg("label", "author=pack")),
+		}).Return([]types.Container{
+			{ID: "old-ctr", State: "exited", Created: old.Unix()},
+			{ID: "running-ctr", State: "running", Created: old.Unix()},
+			{ID: "recent-ctr", State: "exited", Created: recent.Unix()},
+		}, nil)
+		mockDocker.EXPECT().VolumeList(gomock.Any(), filters.NewArgs(filters.Arg("dangling", "true"))).Return(volume.VolumeListOKBody{
+			Volumes: []*types.Volume{
+				{Name: "pack-layers-old", Labels: map[string]string{"author": "pack"}, CreatedAt: old.Format(time.RFC3339)},
+				{Name: "pack-app-legacy"},
+				{Name: "pack-layers-recent", Labels: map[string]string{"author": "pack"}, CreatedAt: recent.Format(time.RFC3339)},
+				{Name: "other-volume", CreatedAt: old.Format(time.RFC3339)},
+			},
+		}, nil)
+		mockDocker.EXPECT().ImageList(gomock.Any(), types.ImageListOptions{
+			Filters: filters.NewArgs(filters.Arg("reference", "pack.local/builder/*")),
+		}).Return([]types.ImageSummary{
+			{RepoTags: []string{"pack.local/builder/old"}, Created: old.Unix()},
+			{RepoTags: []string{"pack.local/builder/recent"}, Created: recent.Unix()},
+		}, nil)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("removes the stopped containers, unused volumes and builder images of builds", func() {
+		mockDocker.EXPECT().ContainerRemove(gomock.Any(), "old-ctr", types.ContainerRemoveOptions{Force: true})
+		mockDocker.EXPECT().VolumeRemove(gomock.Any(), "pack-app-legacy", true)
+		mockDocker.EXPECT().VolumeRemove(gomock.Any(), "pack-layers-old", true)
+		mockDocker.EXPECT().ImageRemove(gomock.Any(), "pack.local/builder/old", types.ImageRemoveOptions{Force: true})
+
+		removed, err := pack.Cleanup(context.TODO(), mockDocker, pack.CleanupOptions{})
+		h.AssertNil(t, err)
+		h.AssertEq(t, removed, []pack.Resource{
+			{Kind: pack.ResourceContainer, Name: "old-ctr", Created: old},
+			{Kind: pack.ResourceVolume, Name: "pack-app-legacy"},
+			{Kind: pack.ResourceVolume, Name: "pack-layers-old", Created: old},
+			{Kind: pack.ResourceImage, Name: "pack.local/builder/old", Created: old},
+		})
+	})
+
+	it("removes the caches last written longer ago than given", func() {
+		cacheDir, err := ioutil.TempDir("", "pack.cleanup.caches")
+		h.AssertNil(t, err)
+		defer os.RemoveAll(cacheDir)
+		bindCache := filepath.Join(cacheDir, "pack-cache-cccccccccccc")
+		h.AssertNil(t, os.Mkdir(bindCache, 0755))
+		written := old.Add(-7 * 24 * time.Hour)
+		h.AssertNil(t, os.Chtimes(bindCache, written, written))
+
+		mockDocker.EXPECT().ContainerRemove(gomock.Any(), gomock.Any(), gomock.Any())
+		mockDocker.EXPECT().VolumeRemove(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+		mockDocker.EXPECT().ImageRemove(gomock.Any(), "pack.local/builder/old", gomock.Any())
+		mockDocker.EXPECT().DiskUsage(gomock.Any()).Return(types.DiskUsage{
+			Images: []*types.ImageSummary{
+				{RepoTags: []string{"pack-cache-aaaaaaaaaaaa:latest"}, Created: written.Unix()},
+				{RepoTags: []string{"pack-cache-bbbbbbbbbbbb:latest"}, Created: old.Unix()},
+				{RepoTags: []string{"some/app:latest"}, Created: written.Unix()},
+			},
+			Volumes: []*types.Volume{
+				{Name: "pack-cache-dddddddddddd", CreatedAt: written.Format(time.RFC3339)},
+				{Name: "pack-cache-eeeeeeeeeeee", CreatedAt: old.Format(time.RFC3339)},
+				{Name: "some-volume", CreatedAt: written.Format(time.RFC3339)},
+			},
+		}, nil)
+		mockDocker.EXPECT().ImageRemove(gomock.Any(), "pack-cache-aaaaaaaaaaaa", types.ImageRemoveOptions{Force: true})
+		mockDocker.EXPECT().VolumeRemove(gomock.Any(), "pack-cache-dddddddddddd", true)
+
+		removed, err := pack.Cleanup(context.TODO(), mockDocker, pack.CleanupOptions{CachesOlderThan: 7 * 24 * time.Hour, CacheDir: cacheDir})
+		h.AssertNil(t, err)
+		h.AssertEq(t, removed[len(removed)-3:], []pack.Resource{
+			{Kind: pack.ResourceCache, Name: "pack-cache-aaaaaaaaaaaa", Created: written},
+			{Kind: pack.ResourceCache, Name: "pack-cache-dddddddddddd", Created: written},
+			{Kind: pack.ResourceCache, Name: bindCache, Created: written},
+		})
+		_, err = os.Stat(bindCache)
+		h.AssertEq(t, os.IsNotExist(err), true)
+	})
+
+	it("only lists the resources on a dry run", func() {
+		removed, err := pack.Cleanup(context.TODO(), mockDocker, pack.CleanupOptions{OlderThan: 24 * time.Hour, DryRun: true})
+		h.AssertNil(t, err)
+		h.AssertEq(t, removed, []pack.Resource{
+			{Kind: pack.ResourceVolume, Name: "pack-app-legacy"},
+		})
+	})
+}
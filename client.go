@@ -1,38 +1,261 @@
 package pack
 
 import (
+	"context"
+	"os"
+
+	lcimg "github.com/buildpack/lifecycle/image"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/buildpack"
+	"github.com/buildpack/pack/buildpackage"
+	"github.com/buildpack/pack/cache"
 	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/git"
+	"github.com/buildpack/pack/keychain"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/registry"
+	"github.com/buildpack/pack/style"
 )
 
+// Client is the entrypoint for programs embedding pack. It builds, runs and rebases app images and
+// creates and inspects builders, wiring up the docker client, image fetching and caches the same
+// way the pack CLI does.
 type Client struct {
-	config  *config.Config
-	fetcher Fetcher
+	logger           *logging.Logger
+	config           *config.Config
+	docker           *docker.Client
+	imageFactory     ImageFactory
+	fetcher          Fetcher
+	buildpackFetcher BuildpackFetcher
 }
 
-func NewClient(config *config.Config, fetcher Fetcher) *Client {
-	return &Client{
-		config:  config,
-		fetcher: fetcher,
+// WithLogger sets the logger receiving the output of the client. Defaults to a logger writing to
+// stdout and stderr.
+func WithLogger(logger *logging.Logger) func(*Client) {
+	return func(c *Client) {
+		c.logger = logger
 	}
 }
 
-//// TODO : move to build.go
-//func (c *Client) Build() {
-//
-//}
-//
-//// TODO : move to create_builder.go
-//func (c *Client) CreateBuilder() {
-//
-//}
-//
+// WithConfig sets the pack configuration. Defaults to the configuration in the pack home directory.
+func WithConfig(config *config.Config) func(*Client) {
+	return func(c *Client) {
+		c.config = config
+	}
+}
 
-//// TODO : move to run.go
-//func (c *Client) Run() {
-//
-//}
+// WithDocker sets the docker client. Defaults to a client configured from the environment.
+func WithDocker(docker *docker.Client) func(*Client) {
+	return func(c *Client) {
+		c.docker = docker
+	}
+}
+
+// WithImageFactory sets the factory of the local and remote images that the default fetcher reads,
+// such as to reach registries with other credentials. Defaults to a factory using the docker client.
+// It has no effect together with WithFetcher.
+func WithImageFactory(factory ImageFactory) func(*Client) {
+	return func(c *Client) {
+		c.imageFactory = factory
+	}
+}
+
+// WithFetcher sets the fetcher of builder, run and app images. Defaults to an ImageFetcher using
+// the docker client.
+func WithFetcher(fetcher Fetcher) func(*Client) {
+	return func(c *Client) {
+		c.fetcher = fetcher
+	}
+}
+
+// WithBuildpackFetcher sets the fetcher of the buildpacks of created builders, and of buildpacks
+// given to builds as archives or URLs. Defaults to a fetcher caching downloads in the pack home
+// directory.
+func WithBuildpackFetcher(fetcher BuildpackFetcher) func(*Client) {
+	return func(c *Client) {
+		c.buildpackFetcher = fetcher
+	}
+}
+
+// NewClient returns a client configured by options such as WithLogger, defaulting to what the pack
+// CLI uses. The insecure registries of its configuration are reached over HTTP by the registry
+// operations of the client, see registry.NewTransport.
+func NewClient(ops ...func(*Client)) (*Client, error) {
+	c := &Client{}
+	for _, op := range ops {
+		op(c)
+	}
+
+	var err error
+	if c.logger == nil {
+		c.logger = logging.NewLogger(os.Stdout, os.Stderr, true, false)
+	}
+	if c.config == nil {
+		if c.config, err = config.NewDefault(); err != nil {
+			return nil, err
+		}
+	}
+	if c.docker == nil {
+		if c.docker, err = docker.New(docker.WithLogger(c.logger.Subsystem(logging.SubsystemDocker))); err != nil {
+			return nil, err
+		}
+	}
+	if c.fetcher == nil {
+		if c.imageFactory == nil {
+			factory, err := lcimg.NewFactory(lcimg.WithOutWriter(c.logger.RawVerboseWriter()), c.docker.FactoryOption(), keychain.FactoryOption)
+			if err != nil {
+				return nil, err
+			}
+			c.imageFactory = &registry.ImageFactory{Factory: factory, Transport: registryTransport(c.config)}
+		}
+		c.fetcher = &ImageFetcher{
+			Docker:  c.docker,
+			Factory: c.imageFactory,
+			Logger:  c.logger.Subsystem(logging.SubsystemRegistry),
+		}
+	}
+	if c.buildpackFetcher == nil {
+		c.buildpackFetcher = buildpack.NewFetcher(c.logger, c.config.Path())
+	}
+	return c, nil
+}
+
+// WithEventHandler receives the events of a build started by the client
+func WithEventHandler(handler EventHandler) func(*BuildFactory) {
+	return func(bf *BuildFactory) {
+		bf.OnEvent = EventHandlers(bf.OnEvent, handler)
+	}
+}
+
+// WithPhaseHook notifies hook of the phases of a build started by the client
+func WithPhaseHook(hook PhaseHook) func(*BuildFactory) {
+	return func(bf *BuildFactory) {
+		bf.PhaseHook = hook
+	}
+}
+
+// WithBuildLogger sets the logger receiving the output of a build started by the client, rather
+// than the logger of the client
+func WithBuildLogger(logger Logger) func(*BuildFactory) {
+	return func(bf *BuildFactory) {
+		bf.Logger = logger
+	}
+}
 
-// TODO : move to rebase.go
-//func (c *Client) Rebase() {
-//
-//}
+// Build builds an app image from the app directory in flags, caching its layers in a volume
+// named after the image
+func (c *Client) Build(ctx context.Context, flags BuildFlags, ops ...func(*BuildFactory)) (*BuildResult, error) {
+	b, err := c.buildConfig(ctx, &flags, ops)
+	if err != nil {
+		return nil, err
+	}
+	return b.Build(ctx)
+}
+
+// projectConfig returns the configuration of builds of the app in appDir, see
+// config.Config.ForProject
+func (c *Client) projectConfig(appDir string) (*config.Config, error) {
+	if _, _, ok := git.ParseURL(appDir); ok || appDir == AppDirStdin {
+		return c.config, nil
+	}
+	if appDir == "" {
+		appDir = "."
+	}
+	cfg, err := c.config.ForProject(appDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading configuration of project %s", style.Symbol(appDir))
+	}
+	return cfg, nil
+}
+
+// Run builds an app image like Build, then runs it until ctx is canceled
+func (c *Client) Run(ctx context.Context, flags RunFlags, ops ...func(*BuildFactory)) error {
+	bf, err := c.buildFactory(&flags.BuildFlags, ops)
+	if err != nil {
+		return err
+	}
+	r, err := bf.RunConfigFromFlags(ctx, &flags)
+	if err != nil {
+		return err
+	}
+	return r.Run(ctx)
+}
+
+// Rebase replaces the run image layers of an app image with those of the latest run image
+func (c *Client) Rebase(ctx context.Context, flags RebaseFlags) error {
+	f := &RebaseFactory{
+		Logger:  c.logger,
+		Config:  c.config,
+		Fetcher: c.fetcher,
+	}
+	cfg, err := f.RebaseConfigFromFlags(ctx, flags)
+	if err != nil {
+		return err
+	}
+	return f.Rebase(cfg)
+}
+
+// CreateBuilder creates a builder image from the builder.toml in flags
+func (c *Client) CreateBuilder(ctx context.Context, flags CreateBuilderFlags) error {
+	f := &BuilderFactory{
+		Logger:           c.logger,
+		Config:           c.config,
+		Fetcher:          c.fetcher,
+		BuildpackFetcher: c.buildpackFetcher,
+	}
+	cfg, err := f.BuilderConfigFromFlags(ctx, flags)
+	if err != nil {
+		return err
+	}
+	return f.Create(cfg)
+}
+
+// CreatePackage packages the buildpacks of the package.toml in flags into a buildpackage image
+func (c *Client) CreatePackage(ctx context.Context, flags CreatePackageFlags) (*buildpackage.Metadata, error) {
+	f := &PackageFactory{
+		Logger:           c.logger,
+		Docker:           c.docker,
+		BuildpackFetcher: c.buildpackFetcher,
+		Config:           c.config,
+	}
+	return f.Create(ctx, flags)
+}
+
+func (c *Client) buildConfig(ctx context.Context, flags *BuildFlags, ops []func(*BuildFactory)) (*BuildConfig, error) {
+	bf, err := c.buildFactory(flags, ops)
+	if err != nil {
+		return nil, err
+	}
+	return bf.BuildConfigFromFlags(ctx, flags)
+}
+
+// buildFactory returns the factory of the builds of flags, with the cache of their app image
+func (c *Client) buildFactory(flags *BuildFlags, ops []func(*BuildFactory)) (*BuildFactory, error) {
+	repoName, err := RepositoryName(c.logger, flags)
+	if err != nil {
+		return nil, err
+	}
+	var cacheObj Cache
+	if flags.CacheImage != "" {
+		cacheObj, err = cache.NewImageCache(flags.CacheImage, keychain.Default, cache.WithTransport(registryTransport(c.config)))
+	} else {
+		cacheObj, err = cache.New(repoName, c.docker, flags.CacheType, cache.WithBindRoot(flags.CacheDir))
+	}
+	if err != nil {
+		return nil, err
+	}
+	bf := &BuildFactory{
+		Cli:              c.docker,
+		Logger:           c.logger,
+		Config:           c.config,
+		Cache:            cacheObj,
+		Fetcher:          c.fetcher,
+		BuildpackFetcher: c.buildpackFetcher,
+	}
+	for _, op := range ops {
+		op(bf)
+	}
+	return bf, nil
+}
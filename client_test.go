@@ -0,0 +1,107 @@
+package pack_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	imgtest "github.com/buildpack/lifecycle/testhelpers"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/keychain"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestClient(t *testing.T) {
+	spec.Run(t, "Client", testClient, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testClient(t *testing.T, when spec.G, it spec.S) {
+	var (
+		mockController *gomock.Controller
+		mockFactory    *mocks.MockImageFactory
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockFactory = mocks.NewMockImageFactory(mockController)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("fetches images with the given image factory", func() {
+		client, err := pack.NewClient(
+			pack.WithConfig(&config.Config{}),
+			pack.WithDocker(&docker.Client{}),
+			pack.WithImageFactory(mockFactory),
+		)
+		h.AssertNil(t, err)
+
+		appImage := imgtest.NewFakeImage(t, "some/app", "", "")
+		h.AssertNil(t, appImage.SetLabel("io.buildpacks.lifecycle.metadata", `{"stack": {"runImage": {"image": "some/run"}}}`))
+		mockFactory.EXPECT().NewRemote("some/app").Return(appImage, nil)
+
+		info, err := client.InspectImage("some/app", false)
+		h.AssertNil(t, err)
+		h.AssertEq(t, info.Stack.RunImage, "some/run")
+	})
+	when("the registry requires credentials", func() {
+		var (
+			registry  *httptest.Server
+			authSeen  []string
+			savedAuth string
+		)
+
+		it.Before(func() {
+			authSeen = nil
+			registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") == "" {
+					w.Header().Set("WWW-Authenticate", `Basic realm="some-realm"`)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				authSeen = append(authSeen, r.Header.Get("Authorization"))
+				if r.URL.Path == "/v2/" {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+			}))
+			savedAuth = os.Getenv(keychain.EnvRegistryAuth)
+		})
+
+		it.After(func() {
+			registry.Close()
+			h.AssertNil(t, os.Setenv(keychain.EnvRegistryAuth, savedAuth))
+		})
+
+		it("fetches remote images with the credentials of PACK_REGISTRY_AUTH", func() {
+			host := strings.TrimPrefix(registry.URL, "http://")
+			h.AssertNil(t, os.Setenv(keychain.EnvRegistryAuth, fmt.Sprintf(`{"%s": {"username": "some-user", "password": "some-password"}}`, host)))
+
+			client, err := pack.NewClient(
+				pack.WithConfig(&config.Config{}),
+				pack.WithDocker(&docker.Client{}),
+			)
+			h.AssertNil(t, err)
+
+			info, err := client.InspectImage(host+"/some/app", false)
+			h.AssertNil(t, err)
+			h.AssertNil(t, info)
+			h.AssertContains(t, strings.Join(authSeen, " "), "Basic "+base64.StdEncoding.EncodeToString([]byte("some-user:some-password")))
+		})
+	})
+}
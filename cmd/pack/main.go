@@ -2,22 +2,36 @@ package main
 
 import (
 	"os"
+	"time"
 
 	"github.com/buildpack/pack"
 	"github.com/buildpack/pack/buildpack"
 	"github.com/buildpack/pack/commands"
 	"github.com/buildpack/pack/config"
 	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/keychain"
 	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/registry"
+	"github.com/buildpack/pack/style"
 
 	"github.com/buildpack/lifecycle/image"
 	"github.com/fatih/color"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+const defaultCITimeout = time.Hour
+
 var (
 	Version           = "0.0.0"
 	timestamps, quiet bool
+	ci                bool
+	logFormat         logging.Format
+	logLevel          = logging.LevelInfo
+	timestampFormat   logging.TimestampFormat
+	logFile           string
+	logOut            *os.File
+	debugSubsystems   []string
 	logger            logging.Logger
 	cfg               config.Config
 	client            pack.Client
@@ -30,35 +44,116 @@ func main() {
 	rootCmd := &cobra.Command{
 		Use: "pack",
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			logger = *logging.NewLogger(os.Stdout, os.Stderr, !quiet, timestamps)
+			level, err := initLogLevel(cmd)
+			if err != nil {
+				exitError(*logging.NewLogger(os.Stdout, os.Stderr, true, false), err)
+			}
+			loggerOps := []func(*logging.Logger){
+				logging.WithFormat(logFormat),
+				logging.WithLevel(level),
+				logging.WithCIProvider(logging.DetectCIProvider()),
+			}
+			if len(debugSubsystems) > 0 {
+				if err := logging.ValidateSubsystems(debugSubsystems); err != nil {
+					exitError(*logging.NewLogger(os.Stdout, os.Stderr, true, false), err)
+				}
+				loggerOps = append(loggerOps, logging.WithDebugSubsystems(debugSubsystems...))
+			}
+			if logFile != "" {
+				logOut = initLogFile(logFile)
+				loggerOps = append(loggerOps, logging.WithLogFile(logOut))
+			}
+			if cmd.Flags().Changed("timestamp-format") {
+				loggerOps = append(loggerOps, logging.WithTimestampFormat(timestampFormat))
+			}
+			if !cmd.Flags().Changed("no-color") {
+				color.NoColor = !style.ColorEnabled(os.Stdout)
+			}
+			if logFormat == logging.JSON {
+				color.NoColor = true
+			}
+			if ci {
+				color.NoColor = true
+				loggerOps = append(loggerOps, logging.WithNonInteractive())
+				commands.NonInteractive = true
+				if commands.Timeout == 0 {
+					commands.Timeout = defaultCITimeout
+				}
+			}
+			logger = *logging.NewLogger(os.Stdout, os.Stderr, true, timestamps, loggerOps...)
 			cfg = initConfig(logger)
-			imageFetcher = initImageFetcher(logger)
+			dockerClient := initDockerClient(logger)
+			imageFetcher = initImageFetcher(logger, dockerClient)
 			buildpackFetcher = initBuildpackFetcher(logger)
-			client = *pack.NewClient(&cfg, &imageFetcher)
+			client = initClient(&logger, dockerClient)
 		},
 	}
-	rootCmd.PersistentFlags().BoolVar(&color.NoColor, "no-color", false, "Disable color output")
+	rootCmd.PersistentFlags().BoolVar(&color.NoColor, "no-color", false, "Disable color output\nDefaults to color on terminals unless $NO_COLOR is set or $CLICOLOR is 0")
 	rootCmd.PersistentFlags().BoolVar(&timestamps, "timestamps", false, "Enable timestamps in output")
-	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Show less output")
+	rootCmd.PersistentFlags().Var(&timestampFormat, "timestamp-format", "Timestamp format, one of 'default', 'rfc3339' or 'elapsed' (implies --timestamps)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Show less output (same as --log-level warn). Builds print only the reference of\n  the app image")
+	rootCmd.PersistentFlags().Var(&logLevel, "log-level", "Minimum level of log output: debug, info, warn or error. Debug also shows the output\n  of the build phases. Defaults to $PACK_LOG_LEVEL when it is set")
+	rootCmd.PersistentFlags().StringSliceVar(&debugSubsystems, "debug", nil, "Show debug output of the given subsystems regardless of --log-level: docker, registry or fs"+"\n  (comma-separated list)")
+	rootCmd.PersistentFlags().BoolVar(&ci, "ci", false, "Run non-interactively: no color, no progress redraws and a default timeout of "+defaultCITimeout.String())
+	rootCmd.PersistentFlags().Var(&logFormat, "log-format", "Output format for logs, either 'text' or 'json'")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Also write the complete debug log to the given file")
+	rootCmd.PersistentFlags().DurationVar(&commands.Timeout, "timeout", 0, "Abort long running commands after the given duration (e.g. 30m)")
 	commands.AddHelpFlag(rootCmd, "pack")
 
-	rootCmd.AddCommand(commands.Build(&logger, &imageFetcher))
-	rootCmd.AddCommand(commands.Run(&logger, &imageFetcher))
-	rootCmd.AddCommand(commands.Rebase(&logger, &imageFetcher))
+	rootCmd.AddCommand(commands.Build(&logger, &cfg, &client))
+	rootCmd.AddCommand(commands.BuildAll(&logger, &cfg, &client))
+	rootCmd.AddCommand(commands.Run(&logger, &cfg, &client))
+	rootCmd.AddCommand(commands.Rebase(&logger, &client))
 
-	rootCmd.AddCommand(commands.CreateBuilder(&logger, &imageFetcher, &buildpackFetcher))
+	rootCmd.AddCommand(commands.CreateBuilder(&logger, &client))
+	rootCmd.AddCommand(commands.CreatePackage(&logger, &client))
 	rootCmd.AddCommand(commands.SetRunImagesMirrors(&logger))
 	rootCmd.AddCommand(commands.InspectBuilder(&logger, &cfg, &client))
-	rootCmd.AddCommand(commands.SetDefaultBuilder(&logger))
+	rootCmd.AddCommand(commands.ValidateBuilder(&logger, &client))
+	rootCmd.AddCommand(commands.ValidateBuildpack(&logger, &client))
+	rootCmd.AddCommand(commands.InspectImage(&logger, &client))
+	rootCmd.AddCommand(commands.SetDefaultBuilder(&logger, &client))
+	rootCmd.AddCommand(commands.TrustBuilder(&logger, &cfg))
+	rootCmd.AddCommand(commands.UntrustBuilder(&logger, &cfg))
+	rootCmd.AddCommand(commands.Manifest(&logger, &client))
+	rootCmd.AddCommand(commands.Cache(&logger, &client))
+	rootCmd.AddCommand(commands.UpdateStack(&logger, &client))
+	rootCmd.AddCommand(commands.Images(&logger, &client))
+	rootCmd.AddCommand(commands.Cleanup(&logger, &client))
+	rootCmd.AddCommand(commands.Bundle(&logger, &imageFetcher))
+	rootCmd.AddCommand(commands.Serve(&logger, &client))
 
 	rootCmd.AddCommand(commands.Version(&logger, Version))
 
 	if err := rootCmd.Execute(); err != nil {
 		if commands.IsSoftError(err) {
-			os.Exit(2)
+			exit(2)
 		}
-		os.Exit(1)
+		exit(1)
+	}
+	exit(0)
+}
+
+func initLogLevel(cmd *cobra.Command) (logging.Level, error) {
+	if cmd.Flags().Changed("log-level") {
+		return logLevel, nil
+	}
+	if quiet {
+		return logging.LevelWarn, nil
+	}
+	if env := os.Getenv("PACK_LOG_LEVEL"); env != "" {
+		level, err := logging.ParseLevel(env)
+		return level, errors.Wrap(err, "invalid PACK_LOG_LEVEL")
+	}
+	return logLevel, nil
+}
+
+func initLogFile(path string) *os.File {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		exitError(*logging.NewLogger(os.Stdout, os.Stderr, true, false), err)
 	}
+	return f
 }
 
 func initConfig(logger logging.Logger) config.Config {
@@ -69,20 +164,24 @@ func initConfig(logger logging.Logger) config.Config {
 	return *cfg
 }
 
-func initImageFetcher(logger logging.Logger) pack.ImageFetcher {
-	factory, err := image.NewFactory()
+func initDockerClient(logger logging.Logger) *docker.Client {
+	dockerClient, err := docker.New(docker.WithLogger(logger.Subsystem(logging.SubsystemDocker)))
 	if err != nil {
 		exitError(logger, err)
 	}
+	return dockerClient
+}
 
-	dockerClient, err := docker.New()
+func initImageFetcher(logger logging.Logger, dockerClient *docker.Client) pack.ImageFetcher {
+	factory, err := image.NewFactory(dockerClient.FactoryOption(), keychain.FactoryOption)
 	if err != nil {
 		exitError(logger, err)
 	}
 
 	return pack.ImageFetcher{
-		Factory: factory,
+		Factory: &registry.ImageFactory{Factory: factory, Transport: registry.NewTransport(cfg.InsecureRegistries...)},
 		Docker:  dockerClient,
+		Logger:  logger.Subsystem(logging.SubsystemRegistry),
 	}
 }
 
@@ -90,7 +189,29 @@ func initBuildpackFetcher(logger logging.Logger) buildpack.Fetcher {
 	return *buildpack.NewFetcher(&logger, cfg.Path())
 }
 
+func initClient(logger *logging.Logger, dockerClient *docker.Client) pack.Client {
+	client, err := pack.NewClient(
+		pack.WithLogger(logger),
+		pack.WithConfig(&cfg),
+		pack.WithDocker(dockerClient),
+		pack.WithFetcher(&imageFetcher),
+		pack.WithBuildpackFetcher(&buildpackFetcher),
+	)
+	if err != nil {
+		exitError(*logger, err)
+	}
+	return *client
+}
+
 func exitError(logger logging.Logger, err error) {
-	logger.Error(err.Error())
-	os.Exit(1)
+	logger.Error("%s", err)
+	exit(1)
+}
+
+// exit closes the log file, if there is one, as os.Exit runs no deferred calls
+func exit(code int) {
+	if logOut != nil {
+		logOut.Close()
+	}
+	os.Exit(code)
 }
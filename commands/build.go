@@ -1,103 +1,205 @@
 package commands
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/buildpack/pack"
-	"github.com/buildpack/pack/cache"
-	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/config"
 	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/metrics"
+	"github.com/buildpack/pack/nerdctl"
 	"github.com/buildpack/pack/style"
+	"github.com/buildpack/pack/tracing"
 )
 
-type suggestedBuilder struct {
-	name  string
-	image string
-	info string
-}
+// by default, builds warn about app directories large enough to noticeably slow them down
+const (
+	defaultMaxAppFiles = 100000
+	defaultMaxAppSize  = 1024 * 1024 * 1024
+)
 
-var suggestedBuilders = [][]suggestedBuilder{
-	{
-		{"Cloud Foundry", "cloudfoundry/cnb:bionic", "small base image with Java & Node.js"},
-		{"Cloud Foundry", "cloudfoundry/cnb:cflinuxfs3", "larger base image with Java, Node.js & Python"},
-	},
-	{
-		{"Heroku", "heroku/buildpacks", "heroku-18 base image with official Heroku buildpacks"},
-	},
-}
+// envDefaultPlatform sets the default platform of images, as it does for the docker CLI
+const envDefaultPlatform = "DOCKER_DEFAULT_PLATFORM"
 
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-func Build(logger *logging.Logger, fetcher pack.Fetcher) *cobra.Command {
-	var buildFlags pack.BuildFlags
-	ctx := createCancellableContext()
+//go:generate mockgen -package mocks -destination mocks/app_builder.go github.com/buildpack/pack/commands AppBuilder
+type AppBuilder interface {
+	Build(ctx context.Context, flags pack.BuildFlags, ops ...func(*pack.BuildFactory)) (*pack.BuildResult, error)
+	Watch(ctx context.Context, flags pack.BuildFlags, ops ...func(*pack.BuildFactory)) error
+	BuildOnKubernetes(ctx context.Context, flags pack.BuildFlags, opts pack.KubernetesOptions) error
+	BuildWithRunner(ctx context.Context, flags pack.BuildFlags, runner build.ContainerRunner) error
+	Detect(ctx context.Context, flags pack.BuildFlags, ops ...func(*pack.BuildFactory)) ([]pack.BuildpackRef, error)
+}
+
+func Build(logger *logging.Logger, cfg *config.Config, appBuilder AppBuilder) *cobra.Command {
+	var (
+		buildFlags pack.BuildFlags
+		onKube     bool
+		onCtrd     bool
+		watch      bool
+		detectOnly bool
+		kubeOpts   pack.KubernetesOptions
+		ctrdRunner nerdctl.Runner
+	)
 
 	cmd := &cobra.Command{
 		Use:   "build <image-name>",
 		Args:  cobra.ExactArgs(1),
 		Short: "Generate app image from source code",
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			ctx := createCancellableContext()
 			buildFlags.RepoName = args[0]
 
-			dockerClient, err := docker.New()
-			if err != nil {
-				return err
+			if buildFlags.Builder == "" && defaultBuilder(cfg, buildFlags.AppDir) == "" {
+				suggestSettingBuilder(logger)
+				return MakeSoftError()
 			}
-			cacheObj, err := cache.New(buildFlags.RepoName, dockerClient)
-			if err != nil {
-				return err
+
+			if detectOnly {
+				if onKube || onCtrd || watch {
+					return errors.New("--detect-only cannot be combined with --kubernetes, --containerd or --watch")
+				}
+				ops := append(interactiveBuildOps(logger), pack.WithEventHandler(buildEventHandler(logger)))
+				buildpacks, err := appBuilder.Detect(ctx, buildFlags, ops...)
+				if err != nil {
+					return err
+				}
+				logger.Info("Buildpacks that would build %s:\n%s", style.Symbol(buildFlags.RepoName), buildpackTable(buildpacks))
+				return nil
 			}
 
-			bf, err := pack.DefaultBuildFactory(logger, cacheObj, dockerClient, fetcher)
-			if err != nil {
-				return err
+			if onKube {
+				if err := appBuilder.BuildOnKubernetes(ctx, buildFlags, kubeOpts); err != nil {
+					return err
+				}
+				logger.Info("Successfully built image %s", style.Symbol(buildFlags.RepoName))
+				return nil
 			}
 
-			if bf.Config.DefaultBuilder == "" && buildFlags.Builder == "" {
-				suggestSettingBuilder(logger)
-				return MakeSoftError()
+			if onCtrd {
+				if onKube || watch {
+					return errors.New("--containerd cannot be combined with --kubernetes or --watch")
+				}
+				if err := appBuilder.BuildWithRunner(ctx, buildFlags, &ctrdRunner); err != nil {
+					return err
+				}
+				logger.Info("Successfully built image %s", style.Symbol(buildFlags.RepoName))
+				return nil
 			}
 
-			b, err := bf.BuildConfigFromFlags(ctx, &buildFlags)
-			if err != nil {
-				return err
+			if watch {
+				return appBuilder.Watch(ctx, buildFlags, append(interactiveBuildOps(logger), pack.WithEventHandler(buildEventHandler(logger)))...)
+			}
+
+			if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+				// the reference of the image is all that is printed, so that it can be piped to other tools
+				silent := logging.NewLogger(ioutil.Discard, ioutil.Discard, false, false)
+				result, err := appBuilder.Build(ctx, buildFlags, pack.WithQuiet(), pack.WithEventHandler(buildEventHandler(silent)))
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(logger.RawWriter(), result.Reference())
+				return nil
 			}
-			if err := b.Run(ctx); err != nil {
+
+			onEvent := buildEventHandler(logger, (&buildTimings{logger: logger}).HandleEvent)
+			if _, err := appBuilder.Build(ctx, buildFlags, append(interactiveBuildOps(logger), pack.WithEventHandler(onEvent))...); err != nil {
 				return err
 			}
-			logger.Info("Successfully built image %s", style.Symbol(b.RepoName))
+			logger.Info("Successfully built image %s", style.Symbol(buildFlags.RepoName))
 			return nil
 		}),
 	}
 	buildCommandFlags(cmd, &buildFlags)
 	cmd.Flags().BoolVar(&buildFlags.Publish, "publish", false, "Publish to registry")
+	cmd.Flags().BoolVar(&detectOnly, "detect-only", false, "Only run detection, listing the buildpacks that would build the app, without building it")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Rebuild the image whenever files in the app dir change, until interrupted")
+	cmd.Flags().BoolVar(&onKube, "kubernetes", false, "Build in a job in the cluster of the current kubectl context, rather than in\n  the docker daemon. Requires --publish and a trusted builder")
+	cmd.Flags().StringVar(&kubeOpts.Namespace, "kubernetes-namespace", "", "Namespace of the build job (defaults to the namespace of the kubectl context)")
+	cmd.Flags().StringVar(&kubeOpts.RegistrySecret, "kubernetes-registry-secret", "", "Secret whose 'auth' key holds the CNB_REGISTRY_AUTH of the build job")
+	cmd.Flags().StringVar(&kubeOpts.ServiceAccount, "kubernetes-service-account", "", "Service account running the build job")
+	cmd.Flags().StringVar(&kubeOpts.WorkspaceClaim, "kubernetes-workspace-claim", "", "Persistent volume claim holding the workspace of the build job (defaults to an\n  empty dir)")
+	cmd.Flags().StringVar(&kubeOpts.Git, "kubernetes-git", "", "Git repository the build job clones the app source from, rather than uploading\n  the app dir")
+	cmd.Flags().StringVar(&kubeOpts.GitRef, "kubernetes-git-ref", "", "Branch or tag of --kubernetes-git")
+	cmd.Flags().BoolVar(&onCtrd, "containerd", false, "Build with nerdctl in containerd, rather than in the docker daemon. Requires --publish\n  and a trusted builder")
+	cmd.Flags().StringVar(&ctrdRunner.Address, "containerd-address", "", "Address of the containerd socket (defaults to the default of nerdctl)")
+	cmd.Flags().StringVar(&ctrdRunner.Namespace, "containerd-namespace", "", "Containerd namespace of the build containers (defaults to the default of nerdctl)")
 	AddHelpFlag(cmd, "build")
 	return cmd
 }
 
+// buildEventHandler returns the handler for events of builds started from the command line,
+// which also passes events to any of the given handlers
+func buildEventHandler(logger *logging.Logger, handlers ...pack.EventHandler) pack.EventHandler {
+	handlers = append(handlers, func(e pack.Event) {
+		switch e.Type {
+		case pack.PhaseStarted:
+			logger.StartGroup(e.Phase)
+		case pack.PhaseFinished:
+			logger.EndGroup(e.Phase)
+		}
+	})
+	if tracer := tracing.NewTracerFromEnv(logger); tracer != nil {
+		handlers = append(handlers, tracer.HandleEvent)
+	}
+	if sinks := metrics.SinksFromEnv(); len(sinks) > 0 {
+		handlers = append(handlers, metrics.NewRecorder(logger, sinks...).HandleEvent)
+	}
+	return pack.EventHandlers(handlers...)
+}
+
+func buildpackTable(buildpacks []pack.BuildpackRef) string {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
+	fmt.Fprint(tabWriter, "  ID\tVERSION\t")
+	for _, bp := range buildpacks {
+		fmt.Fprintf(tabWriter, "\n  %s\t%s\t", bp.ID, bp.Version)
+	}
+	tabWriter.Flush()
+	return buf.String()
+}
+
 func suggestSettingBuilder(logger *logging.Logger) {
 	logger.Info("Please select a default builder with:\n")
 	logger.Info("\tpack set-default-builder <builder image>\n")
-	suggestBuilders(logger)
+	suggestBuilders(logger, pack.SuggestedBuilders)
 }
 
-func suggestBuilders(logger *logging.Logger) {
+// suggestBuilders lists the builders, with the builders of each vendor together and vendors in
+// random order, so that no vendor is favored
+func suggestBuilders(logger *logging.Logger, builders []pack.SuggestedBuilder) {
+	var vendors [][]pack.SuggestedBuilder
+	for _, builder := range builders {
+		if n := len(vendors); n > 0 && vendors[n-1][0].Vendor == builder.Vendor {
+			vendors[n-1] = append(vendors[n-1], builder)
+		} else {
+			vendors = append(vendors, []pack.SuggestedBuilder{builder})
+		}
+	}
+
 	logger.Info("Suggested builders:\n")
 	tw := tabwriter.NewWriter(logger.RawWriter(), 10, 10, 5, ' ', tabwriter.TabIndent)
-	for len(suggestedBuilders) > 0 {
-		n := rand.Intn(len(suggestedBuilders))
-		builders := suggestedBuilders[n]
-		for _, builder := range builders {
-			tw.Write([]byte(fmt.Sprintf("\t%s:\t%s\t%s\t\n", builder.name, style.Symbol(builder.image), builder.info)))
+	for _, n := range rand.Perm(len(vendors)) {
+		for _, builder := range vendors[n] {
+			tw.Write([]byte(fmt.Sprintf("\t%s:\t%s\t%s\t\n", builder.Vendor, style.Symbol(builder.Image), builder.Description)))
 		}
-		suggestedBuilders = append(suggestedBuilders[:n], suggestedBuilders[n+1:]...)
 	}
 	tw.Flush()
 	logger.Info("")
@@ -106,12 +208,125 @@ func suggestBuilders(logger *logging.Logger) {
 }
 
 func buildCommandFlags(cmd *cobra.Command, buildFlags *pack.BuildFlags) {
-	cmd.Flags().StringVarP(&buildFlags.AppDir, "path", "p", "", "Path to app dir (defaults to current working directory)")
+	cmd.Flags().StringVarP(&buildFlags.AppDir, "path", "p", "", "Path to app dir (defaults to current working directory), URL of a Git repository\n  to clone it from, such as 'https://github.com/org/app.git#branch', or '-' to read\n  a tar of it from stdin")
 	cmd.Flags().StringVar(&buildFlags.Builder, "builder", "", "Builder (defaults to builder configured by 'set-default-builder')")
 	cmd.Flags().StringVar(&buildFlags.RunImage, "run-image", "", "Run image (defaults to default stack's run image)")
 	cmd.Flags().StringArrayVarP(&buildFlags.Env, "env", "e", []string{}, "Build-time environment variable, in the form 'VAR=VALUE' or 'VAR'.\nWhen using latter value-less form, value will be taken from current\n  environment at the time this command is executed.\nThis flag may be specified multiple times and will override\n  individual values defined by --env-file.")
-	cmd.Flags().StringVar(&buildFlags.EnvFile, "env-file", "", "Build-time environment variables file\nOne variable per line, of the form 'VAR=VALUE' or 'VAR'\nWhen using latter value-less form, value will be taken from current\n  environment at the time this command is executed")
-	cmd.Flags().BoolVar(&buildFlags.NoPull, "no-pull", false, "Skip pulling builder and run images before use")
+	cmd.Flags().Var(labelsFlag{&buildFlags.Labels}, "label", "Label to set on the app image, in the form 'KEY=VALUE'.\nThis flag may be specified multiple times")
+	cmd.Flags().StringArrayVar(&buildFlags.ExposedPorts, "expose", nil, "Port that the app image exposes, such as '8080' or '53/udp'.\nThis flag may be specified multiple times")
+	cmd.Flags().StringArrayVar(&buildFlags.Args, "arg", nil, "Argument that the app image passes to its launcher, such as the process type to run.\nThis flag may be specified multiple times")
+	cmd.Flags().StringVar(&buildFlags.WorkingDir, "workdir", "", "Working directory of the processes of the app image")
+	cmd.Flags().StringVar(&buildFlags.EnvFile, "env-file", "", "Build-time environment variables file\nOne variable per line, of the form 'VAR=VALUE' or 'VAR', skipping lines starting with '#'\nWhen using latter value-less form, value will be taken from current\n  environment at the time this command is executed")
+	cmd.Flags().Var(&buildFlags.PullPolicy, "pull-policy", "When to pull the builder, run and lifecycle images: 'always',\n  'if-not-present' in the docker daemon, or 'never'")
+	noPull := cmd.Flags().VarPF(noPullFlag{&buildFlags.PullPolicy}, "no-pull", "", "Skip pulling builder and run images before use")
+	noPull.NoOptDefVal = "true"
+	cmd.Flags().MarkDeprecated("no-pull", "use --pull-policy never instead")
 	cmd.Flags().BoolVar(&buildFlags.ClearCache, "clear-cache", false, "Clear image's associated cache before building")
-	cmd.Flags().StringSliceVar(&buildFlags.Buildpacks, "buildpack", nil, "Buildpack ID or path to a buildpack directory"+multiValueHelp("buildpack"))
+	cmd.Flags().BoolVar(&buildFlags.NoCache, "no-cache", false, "Build without restoring or updating the image's associated cache")
+	cmd.Flags().StringVar(&buildFlags.CacheImage, "cache-image", "", "Image at a registry to keep the build cache in, rather than in the docker daemon,\n  so that builds on other machines reuse it")
+	cmd.Flags().Var(&buildFlags.CacheType, "cache-type", "How the docker daemon keeps the build cache: in an 'image' committed by each build,\n  which works with remote daemons, in a 'volume', or in a 'bind' mounted host directory")
+	cmd.Flags().StringVar(&buildFlags.CacheDir, "cache-dir", "", "Host directory holding the build caches of '--cache-type bind'")
+	cmd.Flags().BoolVar(&buildFlags.SkipUnchanged, "skip-unchanged", false, "Skip the build and tag the image already built from the same app source and build\n  inputs, found in the docker daemon or, when publishing, at the image name")
+	cmd.Flags().Var(&buildFlags.AppSymlinks, "symlinks", "How to copy symlinks in the app dir: 'preserve' them as links, 'follow' them,\n  or 'reject-escaping' links that point outside of the app dir")
+	buildFlags.AppLimits.MaxSize = defaultMaxAppSize
+	cmd.Flags().StringSliceVar(&buildFlags.Include, "include", nil, "Glob of the files in the app dir to copy into the build, such as 'src' or '*.go'\n  (defaults to all files).\nThis flag may be specified multiple times")
+	cmd.Flags().StringSliceVar(&buildFlags.Exclude, "exclude", nil, "Glob of the files in the app dir not to copy into the build, such as 'node_modules',\n  in addition to those in its .packignore file.\nThis flag may be specified multiple times")
+	cmd.Flags().BoolVar(&buildFlags.MountApp, "mount-app", false, "Mount the app dir into a container of the build that copies it, rather than sending it\n  to the docker daemon, for trusted builders and local daemons. Files cannot be\n  included or excluded")
+	cmd.Flags().IntVar(&buildFlags.AppLimits.MaxFiles, "max-app-files", defaultMaxAppFiles, "Warn when the app dir contains more files than this (0 for no limit)")
+	cmd.Flags().Var(&buildFlags.AppLimits.MaxSize, "max-app-size", "Warn when the files in the app dir add up to more than this size, such as '500MB' (0 for no limit)")
+	cmd.Flags().BoolVar(&buildFlags.AppLimits.Enforce, "enforce-app-limits", false, "Fail, rather than warn, when the app dir exceeds --max-app-files or --max-app-size")
+	cmd.Flags().StringVar(&buildFlags.Platform, "platform", os.Getenv(envDefaultPlatform), "Platform of the builder and run images, such as 'linux/arm64'. Phases run under emulation\n  when the docker daemon runs on another platform and supports it (defaults to $"+envDefaultPlatform+")")
+	cmd.Flags().StringArrayVar(&buildFlags.Volumes, "volume", nil, "Mount a host path or volume into the detect and build containers, in the form\n  '<source>:<target>[:<options>]', read-only unless the comma-separated options include 'rw'.\nThis flag may be specified multiple times")
+	cmd.Flags().StringVar(&buildFlags.Network, "network", "", "Network of the detect and build containers, such as 'host', 'none' or the name of\n  a docker network (defaults to the docker daemon's default network)")
+	cmd.Flags().StringVar(&buildFlags.OutputFormat, "output-format", "", "Also save the app image to --output, as an OCI image layout directory ('oci')\n  or as a tarball that 'docker load' accepts ('docker-archive')")
+	cmd.Flags().StringVar(&buildFlags.OutputPath, "output", "", "Path to save the app image to in --output-format")
+	cmd.Flags().StringVar(&buildFlags.BOMFormat, "bom-format", "", "Write the bill-of-materials of the app image to --bom-output, as CycloneDX\n  ('cyclonedx') or SPDX ('spdx') JSON")
+	cmd.Flags().StringVar(&buildFlags.BOMPath, "bom-output", "", "Path to write the bill-of-materials to in --bom-format")
+	cmd.Flags().StringVar(&buildFlags.ReportPath, "report", "", "Path to write a JSON report of the build to, with the app image and its digest, the builder,\n  run image and buildpacks it was built with, and how long each phase took")
+	cmd.Flags().IntVar(&buildFlags.RegistryRetries, "registry-retries", 3, "Number of times to retry analyzing and exporting a published image after transient registry errors,\n  such as rate limits or server errors")
+	cmd.Flags().Var(idFlag{&buildFlags.UserID}, "uid", "User id owning the files copied into the build (defaults to CNB_USER_ID of the builder,\n  or else its USER)")
+	cmd.Flags().Var(idFlag{&buildFlags.GroupID}, "gid", "Group id owning the files copied into the build (defaults to CNB_GROUP_ID of the builder,\n  or else the group of its USER)")
+	cmd.Flags().StringVar(&buildFlags.DefaultProcess, "default-process", "", "Process type that the app image runs by default, such as 'worker'")
+	cmd.Flags().StringSliceVarP(&buildFlags.AdditionalTags, "tag", "t", nil, "Additional tag of the app image, which is also pushed when publishing, such as\n  'example/app:v1.2'."+multiValueHelp("tag"))
+	cmd.Flags().StringSliceVar(&buildFlags.Buildpacks, "buildpack", nil, "Buildpack ID, path to a buildpack directory or .tgz archive, or http(s) URL of\n  a .tgz archive"+multiValueHelp("buildpack"))
+}
+
+// noPullFlag is the deprecated --no-pull flag, which sets the pull policy to never
+type noPullFlag struct {
+	policy *pack.PullPolicy
+}
+
+func (f noPullFlag) String() string {
+	return strconv.FormatBool(*f.policy == pack.PullNever)
+}
+
+func (f noPullFlag) Set(s string) error {
+	noPull, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if noPull {
+		*f.policy = pack.PullNever
+	}
+	return nil
+}
+
+func (f noPullFlag) Type() string {
+	return "bool"
+}
+
+// idFlag is a user or group id, which is nil until it is set
+type idFlag struct {
+	id **int
+}
+
+func (f idFlag) String() string {
+	if *f.id == nil {
+		return ""
+	}
+	return strconv.Itoa(**f.id)
+}
+
+func (f idFlag) Set(s string) error {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 {
+		return fmt.Errorf("id '%s' must be a non-negative integer", s)
+	}
+	*f.id = &id
+	return nil
+}
+
+func (f idFlag) Type() string {
+	return "int"
+}
+
+// labelsFlag adds a label in the form 'KEY=VALUE' each time it is set, with values that may contain
+// commas and '=' unlike a string to string flag
+type labelsFlag struct {
+	labels *map[string]string
+}
+
+func (f labelsFlag) String() string {
+	var labels []string
+	for key, value := range *f.labels {
+		labels = append(labels, key+"="+value)
+	}
+	sort.Strings(labels)
+	return "[" + strings.Join(labels, ",") + "]"
+}
+
+func (f labelsFlag) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("label '%s' must be in the form 'KEY=VALUE'", s)
+	}
+	if *f.labels == nil {
+		*f.labels = map[string]string{}
+	}
+	(*f.labels)[parts[0]] = parts[1]
+	return nil
+}
+
+func (f labelsFlag) Type() string {
+	return "stringArray"
 }
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+)
+
+//go:generate mockgen -package mocks -destination mocks/workspace_builder.go github.com/buildpack/pack/commands WorkspaceBuilder
+type WorkspaceBuilder interface {
+	BuildWorkspace(ctx context.Context, path string, flags pack.BuildFlags, ops ...func(*pack.Batch)) (pack.BatchResults, error)
+}
+
+func BuildAll(logger *logging.Logger, cfg *config.Config, workspaceBuilder WorkspaceBuilder) *cobra.Command {
+	var (
+		buildFlags  pack.BuildFlags
+		workspace   string
+		parallelism int
+		failFast    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "build-all",
+		Args:  cobra.NoArgs,
+		Short: "Generate the app images of every app in a workspace, such as the services of a monorepo",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if buildFlags.Builder == "" && defaultBuilder(cfg, workspaceDir(workspace)) == "" {
+				suggestSettingBuilder(logger)
+				return MakeSoftError()
+			}
+
+			var ops []func(*pack.Batch)
+			if parallelism > 0 {
+				ops = append(ops, pack.WithParallelism(parallelism))
+			}
+			if failFast {
+				ops = append(ops, pack.WithFailFast())
+			}
+			results, err := workspaceBuilder.BuildWorkspace(createCancellableContext(), workspace, buildFlags, ops...)
+			if err != nil {
+				return err
+			}
+			logger.Info("Builds:\n%s", batchResultTable(results))
+			return results.Err()
+		}),
+	}
+	cmd.Flags().StringVar(&workspace, "workspace", ".", "Workspace descriptor, or directory holding its "+pack.WorkspaceDescriptorName)
+	cmd.Flags().StringVar(&buildFlags.Builder, "builder", "", "Builder (defaults to builder configured by 'set-default-builder')")
+	cmd.Flags().StringVar(&buildFlags.RunImage, "run-image", "", "Run image (defaults to default stack's run image)")
+	cmd.Flags().Var(&buildFlags.PullPolicy, "pull-policy", "When to pull the builder, run and lifecycle images: 'always',\n  'if-not-present' in the docker daemon, or 'never'")
+	cmd.Flags().BoolVar(&buildFlags.Publish, "publish", false, "Publish to registry")
+	cmd.Flags().IntVar(&parallelism, "parallelism", 0, "Number of apps built at once (defaults to the parallelism of the workspace, or 4)")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "Cancel the remaining builds once one fails")
+	AddHelpFlag(cmd, "build-all")
+	return cmd
+}
+
+func batchResultTable(results pack.BatchResults) string {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
+	fmt.Fprint(tabWriter, "  IMAGE\tSTATUS\tDURATION\t")
+	for _, result := range results {
+		status := "succeeded"
+		switch {
+		case errors.Cause(result.Err) == context.Canceled:
+			status = "canceled"
+		case result.Err != nil:
+			status = "failed"
+		}
+		fmt.Fprintf(tabWriter, "\n  %s\t%s\t%s\t", result.RepoName, status, result.Duration.Round(time.Second))
+	}
+	tabWriter.Flush()
+	return buf.String()
+}
+
+// workspaceDir returns the directory of the workspace, which may be given as its descriptor
+func workspaceDir(workspace string) string {
+	if fi, err := os.Stat(workspace); err == nil && !fi.IsDir() {
+		return filepath.Dir(workspace)
+	}
+	return workspace
+}
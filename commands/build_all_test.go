@@ -0,0 +1,76 @@
+package commands_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBuildAllCommand(t *testing.T) {
+	spec.Run(t, "Commands", testBuildAllCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuildAllCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command              *cobra.Command
+		outBuf               bytes.Buffer
+		mockController       *gomock.Controller
+		mockWorkspaceBuilder *cmdmocks.MockWorkspaceBuilder
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockWorkspaceBuilder = cmdmocks.NewMockWorkspaceBuilder(mockController)
+		cfg := &config.Config{DefaultBuilder: "some/builder"}
+		command = commands.BuildAll(logging.NewLogger(&outBuf, &outBuf, false, false), cfg, mockWorkspaceBuilder)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("builds the workspace and lists the outcome of each build", func() {
+		mockWorkspaceBuilder.EXPECT().BuildWorkspace(gomock.Any(), "services", pack.BuildFlags{Publish: true}).Return(pack.BatchResults{
+			{RepoName: "some/api"},
+			{RepoName: "some/web", Err: errors.New("some error")},
+			{RepoName: "some/worker", Err: context.Canceled},
+		}, nil)
+
+		command.SetArgs([]string{"--workspace", "services", "--publish"})
+		err := command.Execute()
+		h.AssertError(t, err, "2 of 3 builds failed")
+
+		h.AssertContains(t, outBuf.String(), "some/api           succeeded")
+		h.AssertContains(t, outBuf.String(), "some/web           failed")
+		h.AssertContains(t, outBuf.String(), "some/worker        canceled")
+	})
+
+	it("passes the parallelism and fail fast options", func() {
+		mockWorkspaceBuilder.EXPECT().BuildWorkspace(gomock.Any(), ".", pack.BuildFlags{}, gomock.Any(), gomock.Any()).Return(pack.BatchResults{
+			{RepoName: "some/api"},
+		}, nil)
+
+		command.SetArgs([]string{"--parallelism", "2", "--fail-fast"})
+		h.AssertNil(t, command.Execute())
+	})
+
+	it("fails when the workspace cannot be read", func() {
+		mockWorkspaceBuilder.EXPECT().BuildWorkspace(gomock.Any(), ".", gomock.Any()).Return(nil, errors.New("some error"))
+
+		command.SetArgs([]string{})
+		h.AssertError(t, command.Execute(), "some error")
+	})
+}
@@ -0,0 +1,226 @@
+package commands_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/cache"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/nerdctl"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBuildCommand(t *testing.T) {
+	spec.Run(t, "Commands", testBuildCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuildCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockAppBuilder *cmdmocks.MockAppBuilder
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockAppBuilder = cmdmocks.NewMockAppBuilder(mockController)
+		cfg := &config.Config{DefaultBuilder: "some/builder"}
+		command = commands.Build(logging.NewLogger(&outBuf, &outBuf, false, false), cfg, mockAppBuilder)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("--detect-only", func() {
+		it("lists the buildpacks that would build the app", func() {
+			mockAppBuilder.EXPECT().Detect(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, flags pack.BuildFlags, _ ...func(*pack.BuildFactory)) ([]pack.BuildpackRef, error) {
+					h.AssertEq(t, flags.RepoName, "some/app")
+					return []pack.BuildpackRef{
+						{ID: "org.example.node", Version: "1.2.3"},
+						{ID: "org.example.npm", Version: "0.1.0"},
+					}, nil
+				})
+
+			command.SetArgs([]string{"some/app", "--detect-only"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), "Buildpacks that would build 'some/app':")
+			h.AssertContains(t, outBuf.String(), "org.example.node        1.2.3")
+			h.AssertContains(t, outBuf.String(), "org.example.npm         0.1.0")
+		})
+
+		it("cannot be combined with --watch", func() {
+			command.SetArgs([]string{"some/app", "--detect-only", "--watch"})
+			h.AssertError(t, command.Execute(), "--detect-only cannot be combined with --kubernetes, --containerd or --watch")
+		})
+	})
+
+	when("--containerd", func() {
+		it("builds with nerdctl in the given namespace", func() {
+			mockAppBuilder.EXPECT().BuildWithRunner(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, flags pack.BuildFlags, runner build.ContainerRunner) error {
+					h.AssertEq(t, flags.Publish, true)
+					h.AssertEq(t, runner, &nerdctl.Runner{Namespace: "some-namespace"})
+					return nil
+				})
+
+			command.SetArgs([]string{"some/app", "--publish", "--containerd", "--containerd-namespace", "some-namespace"})
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "Successfully built image 'some/app'")
+		})
+
+		it("cannot be combined with --watch", func() {
+			command.SetArgs([]string{"some/app", "--containerd", "--watch"})
+			h.AssertError(t, command.Execute(), "--containerd cannot be combined with --kubernetes or --watch")
+		})
+	})
+
+	it("sets the labels given with --label", func() {
+		mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, flags pack.BuildFlags, _ ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+				h.AssertEq(t, flags.Labels, map[string]string{"some.key": "some=value,with commas", "other.key": ""})
+				return &pack.BuildResult{Image: "some/app"}, nil
+			})
+
+		command.SetArgs([]string{"some/app", "--label", "some.key=some=value,with commas", "--label", "other.key="})
+		h.AssertNil(t, command.Execute())
+	})
+
+	when("--uid and --gid", func() {
+		it("sets the user and group of the build", func() {
+			mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, flags pack.BuildFlags, _ ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+					h.AssertEq(t, *flags.UserID, 2000)
+					h.AssertEq(t, *flags.GroupID, 0)
+					return &pack.BuildResult{Image: "some/app"}, nil
+				})
+
+			command.SetArgs([]string{"some/app", "--uid", "2000", "--gid", "0"})
+			h.AssertNil(t, command.Execute())
+		})
+
+		it("leaves the user and group of the builder when they are not given", func() {
+			mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, flags pack.BuildFlags, _ ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+					h.AssertEq(t, flags.UserID == nil, true)
+					h.AssertEq(t, flags.GroupID == nil, true)
+					return &pack.BuildResult{Image: "some/app"}, nil
+				})
+
+			command.SetArgs([]string{"some/app"})
+			h.AssertNil(t, command.Execute())
+		})
+
+		it("fails for ids that are not numbers", func() {
+			command.SetArgs([]string{"some/app", "--uid", "cnb"})
+			h.AssertError(t, command.Execute(), "id 'cnb' must be a non-negative integer")
+		})
+	})
+
+	when("there is no default builder", func() {
+		var appDir string
+
+		it.Before(func() {
+			var err error
+			appDir, err = ioutil.TempDir("", "pack.build")
+			h.AssertNil(t, err)
+			command = commands.Build(logging.NewLogger(&outBuf, &outBuf, false, false), &config.Config{}, mockAppBuilder)
+		})
+
+		it.After(func() {
+			os.RemoveAll(appDir)
+		})
+
+		it("builds with the default builder of the project", func() {
+			h.AssertNil(t, os.MkdirAll(filepath.Join(appDir, config.ProjectDir), 0777))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, config.ProjectDir, "config.toml"), []byte(`default-builder-image = "project/builder"`), 0666))
+			mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any()).Return(&pack.BuildResult{Image: "some/app"}, nil)
+
+			command.SetArgs([]string{"some/app", "--path", appDir})
+			h.AssertNil(t, command.Execute())
+		})
+
+		it("suggests builders when the project has none", func() {
+			command.SetArgs([]string{"some/app", "--path", appDir})
+			h.AssertNotNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "Please select a default builder")
+		})
+	})
+
+	when("--cache-type", func() {
+		it("keeps the cache as given", func() {
+			mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, flags pack.BuildFlags, _ ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+					h.AssertEq(t, flags.CacheType, cache.TypeBind)
+					h.AssertEq(t, flags.CacheDir, "/some/dir")
+					return &pack.BuildResult{Image: "some/app"}, nil
+				})
+
+			command.SetArgs([]string{"some/app", "--cache-type", "bind", "--cache-dir", "/some/dir"})
+			h.AssertNil(t, command.Execute())
+		})
+
+		it("rejects unknown types", func() {
+			command.SetArgs([]string{"some/app", "--cache-type", "some-type"})
+			h.AssertContains(t, command.Execute().Error(), "unknown cache type 'some-type', must be one of 'image', 'volume' or 'bind'")
+		})
+	})
+
+	it("skips unchanged builds with --skip-unchanged", func() {
+		mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, flags pack.BuildFlags, _ ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+				h.AssertEq(t, flags.SkipUnchanged, true)
+				return &pack.BuildResult{Image: "some/app"}, nil
+			})
+
+		command.SetArgs([]string{"some/app", "--skip-unchanged"})
+		h.AssertNil(t, command.Execute())
+	})
+
+	it("prints nothing but the reference of the image when quiet", func() {
+		command.Flags().BoolP("quiet", "q", false, "")
+		mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&pack.BuildResult{Image: "some/app"}, nil)
+
+		command.SetArgs([]string{"some/app", "-q"})
+		h.AssertNil(t, command.Execute())
+		h.AssertEq(t, outBuf.String(), "some/app\n")
+	})
+
+	it("still writes the log file when quiet", func() {
+		var fileBuf bytes.Buffer
+		logger := logging.NewLogger(&outBuf, &outBuf, false, false, logging.WithLogFile(&fileBuf))
+		command = commands.Build(logger, &config.Config{DefaultBuilder: "some/builder"}, mockAppBuilder)
+		command.Flags().BoolP("quiet", "q", false, "")
+		mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ pack.BuildFlags, ops ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+				// the client builds with a factory holding its logger, which is the logger of the command
+				bf := &pack.BuildFactory{Logger: logger}
+				for _, op := range ops {
+					op(bf)
+				}
+				bf.Logger.Info("some build output")
+				return &pack.BuildResult{Image: "some/app"}, nil
+			})
+
+		command.SetArgs([]string{"some/app", "-q"})
+		h.AssertNil(t, command.Execute())
+		h.AssertEq(t, outBuf.String(), "some/app\n")
+		h.AssertContains(t, fileBuf.String(), "some build output")
+	})
+}
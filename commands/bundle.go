@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+func Bundle(logger *logging.Logger, fetcher pack.Fetcher) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Move a builder and its run image to machines without internet access",
+	}
+	cmd.AddCommand(bundleSave(logger, fetcher))
+	cmd.AddCommand(bundleLoad(logger, fetcher))
+	AddHelpFlag(cmd, "bundle")
+	return cmd
+}
+
+func bundleSave(logger *logging.Logger, fetcher pack.Fetcher) *cobra.Command {
+	var flags pack.SaveBundleFlags
+
+	cmd := &cobra.Command{
+		Use:   "save <archive>",
+		Short: "Save a builder, its run image and its buildpacks into an archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			ctx := createCancellableContext()
+			factory, err := newBundleFactory(logger, fetcher)
+			if err != nil {
+				return err
+			}
+			if flags.Builder == "" {
+				flags.Builder = factory.Config.DefaultBuilder
+			}
+			if flags.Builder == "" {
+				suggestSettingBuilder(logger)
+				return MakeSoftError()
+			}
+
+			info, err := factory.Save(ctx, args[0], flags)
+			if err != nil {
+				return err
+			}
+			logger.Info("Saved builder %s with run image %s and %d buildpack(s) to %s",
+				style.Symbol(info.Builder), style.Symbol(info.RunImage), len(info.Buildpacks), style.Symbol(args[0]))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&flags.Builder, "builder", "", "Builder to save (defaults to the default builder)")
+	cmd.Flags().BoolVar(&flags.NoPull, "no-pull", false, "Skip pulling the builder and run image before saving them")
+	AddHelpFlag(cmd, "bundle save")
+	return cmd
+}
+
+func bundleLoad(logger *logging.Logger, fetcher pack.Fetcher) *cobra.Command {
+	var flags pack.LoadBundleFlags
+
+	cmd := &cobra.Command{
+		Use:   "load <archive>",
+		Short: "Load the images of an archive created by 'pack bundle save' into the docker daemon or a registry",
+		Args:  cobra.ExactArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			ctx := createCancellableContext()
+			factory, err := newBundleFactory(logger, fetcher)
+			if err != nil {
+				return err
+			}
+
+			info, err := factory.Load(ctx, args[0], flags)
+			if err != nil {
+				return err
+			}
+			logger.Info("Loaded builder %s with run image %s", style.Symbol(info.Builder), style.Symbol(info.RunImage))
+			if flags.Registry != "" {
+				logger.Info("Run image mirror %s configured for builds with the builder", style.Symbol(info.RunImage))
+			}
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&flags.Registry, "registry", "", "Push the images to this registry rather than loading them into the docker daemon")
+	AddHelpFlag(cmd, "bundle load")
+	return cmd
+}
+
+func newBundleFactory(logger *logging.Logger, fetcher pack.Fetcher) (*pack.BundleFactory, error) {
+	cfg, err := config.NewDefault()
+	if err != nil {
+		return nil, err
+	}
+	dockerClient, err := docker.New(docker.WithLogger(logger.Subsystem(logging.SubsystemDocker)))
+	if err != nil {
+		return nil, err
+	}
+	return &pack.BundleFactory{
+		Logger:  logger,
+		Config:  cfg,
+		Fetcher: fetcher,
+		Docker:  dockerClient,
+	}, nil
+}
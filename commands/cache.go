@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/cache"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+//go:generate mockgen -package mocks -destination mocks/cache_manager.go github.com/buildpack/pack/commands CacheManager
+type CacheManager interface {
+	ListCaches(ctx context.Context, cacheDir string) ([]cache.Info, error)
+	PruneCaches(ctx context.Context, olderThan time.Duration, cacheDir string) ([]cache.Info, error)
+	ClearCache(ctx context.Context, repoName, cacheDir string) error
+}
+
+func Cache(logger *logging.Logger, manager CacheManager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "List, prune and clear the build caches kept in the docker daemon or host directories",
+	}
+	cmd.AddCommand(cacheList(logger, manager))
+	cmd.AddCommand(cachePrune(logger, manager))
+	cmd.AddCommand(cacheClear(logger, manager))
+	AddHelpFlag(cmd, "cache")
+	return cmd
+}
+
+func cacheList(logger *logging.Logger, manager CacheManager) *cobra.Command {
+	var cacheDir string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List build caches with their sizes",
+		Args:  cobra.NoArgs,
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			infos, err := manager.ListCaches(createCancellableContext(), cacheDir)
+			if err != nil {
+				return err
+			}
+			if len(infos) == 0 {
+				logger.Info("No build caches")
+				return nil
+			}
+			logger.Info(cacheTable(infos))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Also list the build caches of '--cache-type bind' kept in this host directory")
+	AddHelpFlag(cmd, "cache list")
+	return cmd
+}
+
+func cachePrune(logger *logging.Logger, manager CacheManager) *cobra.Command {
+	var (
+		olderThan time.Duration
+		cacheDir  string
+	)
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove build caches to reclaim space",
+		Args:  cobra.NoArgs,
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			pruned, err := manager.PruneCaches(createCancellableContext(), olderThan, cacheDir)
+			if len(pruned) > 0 {
+				logger.Info("Removed build caches:\n%s", cacheTable(pruned))
+			}
+			if err != nil {
+				return err
+			}
+			logger.Info("Reclaimed %s", build.ByteSize(totalSize(pruned)))
+			return nil
+		}),
+	}
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "Only remove caches last written longer ago than this, such as '168h' (defaults to all caches)")
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Also prune the build caches of '--cache-type bind' kept in this host directory")
+	AddHelpFlag(cmd, "cache prune")
+	return cmd
+}
+
+func cacheClear(logger *logging.Logger, manager CacheManager) *cobra.Command {
+	var cacheDir string
+	cmd := &cobra.Command{
+		Use:   "clear <image-name>",
+		Short: "Remove the build cache of an app image, without building it",
+		Args:  cobra.ExactArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if err := manager.ClearCache(createCancellableContext(), args[0], cacheDir); err != nil {
+				return err
+			}
+			logger.Info("Cleared the build cache of %s", style.Symbol(args[0]))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Also clear the build cache of '--cache-type bind' kept in this host directory")
+	AddHelpFlag(cmd, "cache clear")
+	return cmd
+}
+
+func cacheTable(infos []cache.Info) string {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
+	fmt.Fprint(tabWriter, "  CACHE\tTYPE\tSIZE\tLAST WRITTEN\t")
+	for _, info := range infos {
+		written := "unknown"
+		if !info.Created.IsZero() {
+			written = info.Created.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tabWriter, "\n  %s\t%s\t%s\t%s\t", info.Name, info.Type, build.ByteSize(info.Size), written)
+	}
+	fmt.Fprintf(tabWriter, "\n  TOTAL\t\t%s\t\t", build.ByteSize(totalSize(infos)))
+	tabWriter.Flush()
+	return buf.String()
+}
+
+func totalSize(infos []cache.Info) int64 {
+	var total int64
+	for _, info := range infos {
+		total += info.Size
+	}
+	return total
+}
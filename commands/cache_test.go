@@ -0,0 +1,122 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack/cache"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestCacheCommand(t *testing.T) {
+	spec.Run(t, "Commands", testCacheCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testCacheCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockManager    *cmdmocks.MockCacheManager
+		infos          []cache.Info
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockManager = cmdmocks.NewMockCacheManager(mockController)
+		command = commands.Cache(logging.NewLogger(&outBuf, &outBuf, false, false), mockManager)
+		infos = []cache.Info{
+			{Type: cache.TypeImage, Name: "pack-cache-aaaaaaaaaaaa", Size: 3 * 1024 * 1024, Created: time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)},
+			{Type: cache.TypeVolume, Name: "pack-cache-bbbbbbbbbbbb", Size: 1024 * 1024, Created: time.Date(2019, 3, 2, 12, 0, 0, 0, time.UTC)},
+		}
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#list", func() {
+		it("lists the caches with their sizes", func() {
+			mockManager.EXPECT().ListCaches(gomock.Any(), "").Return(infos, nil)
+
+			command.SetArgs([]string{"list"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), "pack-cache-aaaaaaaaaaaa        image         3MB         2019-03-01T12:00:00Z")
+			h.AssertContains(t, outBuf.String(), "pack-cache-bbbbbbbbbbbb        volume        1MB         2019-03-02T12:00:00Z")
+			h.AssertContains(t, outBuf.String(), "TOTAL                                        4MB")
+		})
+
+		it("lists the bind caches in the cache dir", func() {
+			mockManager.EXPECT().ListCaches(gomock.Any(), "/some/cache-dir").Return([]cache.Info{
+				{Type: cache.TypeBind, Name: "/some/cache-dir/pack-cache-cccccccccccc", Size: 1024},
+			}, nil)
+
+			command.SetArgs([]string{"list", "--cache-dir", "/some/cache-dir"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), "/some/cache-dir/pack-cache-cccccccccccc        bind        1KB         unknown")
+		})
+
+		it("tells when there are no caches", func() {
+			mockManager.EXPECT().ListCaches(gomock.Any(), "").Return(nil, nil)
+
+			command.SetArgs([]string{"list"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), "No build caches")
+		})
+	})
+
+	when("#prune", func() {
+		it("prunes caches older than the given age", func() {
+			mockManager.EXPECT().PruneCaches(gomock.Any(), 168*time.Hour, "/some/cache-dir").Return(infos[:1], nil)
+
+			command.SetArgs([]string{"prune", "--older-than", "168h", "--cache-dir", "/some/cache-dir"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), "pack-cache-aaaaaaaaaaaa")
+			h.AssertContains(t, outBuf.String(), "Reclaimed 3MB")
+		})
+
+		it("prunes all caches by default", func() {
+			mockManager.EXPECT().PruneCaches(gomock.Any(), time.Duration(0), "").Return(nil, nil)
+
+			command.SetArgs([]string{"prune"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), "Reclaimed 0B")
+		})
+
+		it("reports the caches removed before failing", func() {
+			mockManager.EXPECT().PruneCaches(gomock.Any(), time.Duration(0), "").Return(infos[:1], errors.New("some error"))
+
+			command.SetArgs([]string{"prune"})
+			h.AssertNotNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), "pack-cache-aaaaaaaaaaaa")
+			h.AssertContains(t, outBuf.String(), "ERROR: some error")
+		})
+	})
+
+	when("#clear", func() {
+		it("clears the cache of the image", func() {
+			mockManager.EXPECT().ClearCache(gomock.Any(), "some/app", "").Return(nil)
+
+			command.SetArgs([]string{"clear", "some/app"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), "Cleared the build cache of 'some/app'")
+		})
+	})
+}
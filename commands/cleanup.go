@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+)
+
+//go:generate mockgen -package mocks -destination mocks/cleaner.go github.com/buildpack/pack/commands Cleaner
+type Cleaner interface {
+	Cleanup(ctx context.Context, opts pack.CleanupOptions) ([]pack.Resource, error)
+}
+
+func Cleanup(logger *logging.Logger, cleaner Cleaner) *cobra.Command {
+	var opts pack.CleanupOptions
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Args:  cobra.NoArgs,
+		Short: "Remove the containers, volumes and images left behind by builds that crashed",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			removed, err := cleaner.Cleanup(createCancellableContext(), opts)
+			if len(removed) > 0 {
+				verb := "Removed"
+				if opts.DryRun {
+					verb = "Would remove"
+				}
+				logger.Info("%s:\n%s", verb, resourceTable(removed))
+			} else if err == nil {
+				logger.Info("Nothing to clean up")
+			}
+			return err
+		}),
+	}
+	cmd.Flags().DurationVar(&opts.OlderThan, "older-than", pack.DefaultCleanupAge, "Only remove containers, volumes and builder images created longer ago than this,\n  so that running builds are left alone")
+	cmd.Flags().DurationVar(&opts.CachesOlderThan, "caches-older-than", 0, "Also remove build caches last written longer ago than this, such as '168h'")
+	cmd.Flags().StringVar(&opts.CacheDir, "cache-dir", "", "Host directory holding the build caches of '--cache-type bind' removed with --caches-older-than")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "List what would be removed without removing it")
+	AddHelpFlag(cmd, "cleanup")
+	return cmd
+}
+
+func resourceTable(resources []pack.Resource) string {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
+	fmt.Fprint(tabWriter, "  KIND\tNAME\tCREATED\t")
+	for _, r := range resources {
+		created := "unknown"
+		if !r.Created.IsZero() {
+			created = r.Created.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tabWriter, "\n  %s\t%s\t%s\t", r.Kind, r.Name, created)
+	}
+	tabWriter.Flush()
+	return buf.String()
+}
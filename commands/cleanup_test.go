@@ -0,0 +1,75 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestCleanupCommand(t *testing.T) {
+	spec.Run(t, "Commands", testCleanupCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testCleanupCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockCleaner    *cmdmocks.MockCleaner
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockCleaner = cmdmocks.NewMockCleaner(mockController)
+		command = commands.Cleanup(logging.NewLogger(&outBuf, &outBuf, false, false), mockCleaner)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("lists the removed resources", func() {
+		mockCleaner.EXPECT().Cleanup(gomock.Any(), pack.CleanupOptions{OlderThan: time.Hour}).Return([]pack.Resource{
+			{Kind: pack.ResourceContainer, Name: "some-ctr", Created: time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)},
+			{Kind: pack.ResourceVolume, Name: "pack-app-some"},
+		}, nil)
+
+		command.SetArgs([]string{})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "Removed:")
+		h.AssertContains(t, outBuf.String(), "container        some-ctr             2019-03-01T12:00:00Z")
+		h.AssertContains(t, outBuf.String(), "volume           pack-app-some        unknown")
+	})
+
+	it("passes the ages and dry run", func() {
+		mockCleaner.EXPECT().Cleanup(gomock.Any(), pack.CleanupOptions{OlderThan: 24 * time.Hour, CachesOlderThan: 168 * time.Hour, DryRun: true}).Return([]pack.Resource{
+			{Kind: pack.ResourceCache, Name: "pack-cache-aaaaaaaaaaaa"},
+		}, nil)
+
+		command.SetArgs([]string{"--older-than", "24h", "--caches-older-than", "168h", "--dry-run"})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "Would remove:")
+	})
+
+	it("tells when there is nothing to clean up", func() {
+		mockCleaner.EXPECT().Cleanup(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		command.SetArgs([]string{})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "Nothing to clean up")
+	})
+}
@@ -6,12 +6,22 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/git"
 	"github.com/buildpack/pack/logging"
 )
 
+// Timeout bounds the duration of commands that talk to the daemon or a registry. Zero means no timeout.
+var Timeout time.Duration
+
+// NonInteractive keeps commands from prompting, as with --ci, even when stdin is a terminal
+var NonInteractive bool
+
 // TODO: Check if most recent cobra version fixed bug in help strings. It was not always capitalizing the first
 // letter in the help string. If it's fixed, we can remove this.
 func AddHelpFlag(cmd *cobra.Command, commandName string) {
@@ -25,7 +35,7 @@ func logError(logger *logging.Logger, f func(cmd *cobra.Command, args []string)
 		err := f(cmd, args)
 		if err != nil {
 			if !IsSoftError(err) {
-				logger.Error(err.Error())
+				logger.Error("%s", err)
 			}
 			return err
 		}
@@ -33,14 +43,38 @@ func logError(logger *logging.Logger, f func(cmd *cobra.Command, args []string)
 	}
 }
 
+// defaultBuilder returns the default builder of builds of the app in appDir, which the project's
+// .pack/config.toml may set (see config.Config.ForProject). Projects whose configuration cannot
+// be read are left to fail their builds.
+func defaultBuilder(cfg *config.Config, appDir string) string {
+	if _, _, ok := git.ParseURL(appDir); ok || appDir == pack.AppDirStdin {
+		return cfg.DefaultBuilder
+	}
+	if appDir == "" {
+		appDir = "."
+	}
+	if projectCfg, err := cfg.ForProject(appDir); err == nil {
+		return projectCfg.DefaultBuilder
+	}
+	return cfg.DefaultBuilder
+}
+
 func multiValueHelp(name string) string {
 	return fmt.Sprintf("\nRepeat for each %s in order,\n  or supply once by comma-separated list", name)
 }
 
 func createCancellableContext() context.Context {
-	signals := make(chan os.Signal)
+	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
-	ctx, cancel := context.WithCancel(context.Background())
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
 
 	go func() {
 		<-signals
@@ -1,50 +1,39 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 
 	"github.com/spf13/cobra"
 
 	"github.com/buildpack/pack"
-	"github.com/buildpack/pack/config"
 	"github.com/buildpack/pack/logging"
 	"github.com/buildpack/pack/style"
 )
 
-func CreateBuilder(logger *logging.Logger, fetcher pack.Fetcher, bpFetcher pack.BuildpackFetcher) *cobra.Command {
+type BuilderCreator interface {
+	CreateBuilder(ctx context.Context, flags pack.CreateBuilderFlags) error
+}
+
+func CreateBuilder(logger *logging.Logger, creator BuilderCreator) *cobra.Command {
 	var flags pack.CreateBuilderFlags
-	ctx := createCancellableContext()
 	cmd := &cobra.Command{
 		Use:   "create-builder <image-name> --builder-config <builder-config-path>",
 		Args:  cobra.ExactArgs(1),
 		Short: "Create builder image",
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			ctx := createCancellableContext()
 			flags.RepoName = args[0]
 			if runtime.GOOS == "windows" {
 				return fmt.Errorf("%s is not implemented on Windows", style.Symbol("create-builder"))
 			}
 
-			cfg, err := config.NewDefault()
-			if err != nil {
-				return err
-			}
-			builderFactory := pack.BuilderFactory{
-				Logger:           logger,
-				Config:           cfg,
-				Fetcher:          fetcher,
-				BuildpackFetcher: bpFetcher,
-			}
-			builderConfig, err := builderFactory.BuilderConfigFromFlags(ctx, flags)
-			if err != nil {
-				return err
-			}
-			if err := builderFactory.Create(builderConfig); err != nil {
+			if err := creator.CreateBuilder(ctx, flags); err != nil {
 				return err
 			}
-			imageName := builderConfig.Repo.Name()
-			logger.Info("Successfully created builder image %s", style.Symbol(imageName))
-			logger.Tip("Run %s to use this builder", style.Symbol(fmt.Sprintf("pack build <image-name> --builder %s", imageName)))
+			logger.Info("Successfully created builder image %s", style.Symbol(flags.RepoName))
+			logger.Tip("Run %s to use this builder", style.Symbol(fmt.Sprintf("pack build <image-name> --builder %s", flags.RepoName)))
 			return nil
 		}),
 	}
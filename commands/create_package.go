@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/buildpackage"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+type PackageCreator interface {
+	CreatePackage(ctx context.Context, flags pack.CreatePackageFlags) (*buildpackage.Metadata, error)
+}
+
+func CreatePackage(logger *logging.Logger, creator PackageCreator) *cobra.Command {
+	var flags pack.CreatePackageFlags
+	cmd := &cobra.Command{
+		Use:   "create-package <image-name> --package-config <package-config-path>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Create buildpackage image from buildpacks",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			ctx := createCancellableContext()
+			flags.RepoName = args[0]
+
+			metadata, err := creator.CreatePackage(ctx, flags)
+			if err != nil {
+				return err
+			}
+			logger.Info("Successfully created buildpackage image %s with %d buildpack(s)", style.Symbol(flags.RepoName), len(metadata.Buildpacks))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVarP(&flags.PackageTomlPath, "package-config", "p", "", "Path to package TOML file (required)")
+	cmd.MarkFlagRequired("package-config")
+	cmd.Flags().BoolVar(&flags.Publish, "publish", false, "Publish to registry")
+	AddHelpFlag(cmd, "create-package")
+	return cmd
+}
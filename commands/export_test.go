@@ -0,0 +1,9 @@
+package commands
+
+// SetStdinIsTerminal makes commands treat stdin as a terminal or not, returning a func that restores
+// the check of stdin
+func SetStdinIsTerminal(isTerm bool) func() {
+	saved := stdinIsTerminal
+	stdinIsTerminal = func() bool { return isTerm }
+	return func() { stdinIsTerminal = saved }
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+)
+
+//go:generate mockgen -package mocks -destination mocks/app_image_lister.go github.com/buildpack/pack/commands AppImageLister
+type AppImageLister interface {
+	AppImages(ctx context.Context) ([]pack.AppImage, error)
+}
+
+func Images(logger *logging.Logger, lister AppImageLister) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "images",
+		Args:  cobra.NoArgs,
+		Short: "List the app images built by pack in the docker daemon",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			images, err := lister.AppImages(createCancellableContext())
+			if err != nil {
+				return err
+			}
+			if len(images) == 0 {
+				logger.Info("No app images")
+				return nil
+			}
+			logger.Info(appImageTable(images))
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "images")
+	return cmd
+}
+
+// appImageTable lists each tag of the images on its own row, like 'docker images'
+func appImageTable(images []pack.AppImage) string {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
+	fmt.Fprint(tabWriter, "  IMAGE\tID\tRUN IMAGE\tSTACK\tCREATED\t")
+	for _, image := range images {
+		tags := image.Tags
+		if len(tags) == 0 {
+			tags = []string{"<none>"}
+		}
+		for _, tag := range tags {
+			fmt.Fprintf(tabWriter, "\n  %s\t%s\t%s\t%s\t%s\t", tag, shortID(image.ID), image.RunImage, image.Stack, image.Created.Format(time.RFC3339))
+		}
+	}
+	tabWriter.Flush()
+	return buf.String()
+}
+
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
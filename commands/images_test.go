@@ -0,0 +1,74 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestImagesCommand(t *testing.T) {
+	spec.Run(t, "Commands", testImagesCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testImagesCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockLister     *cmdmocks.MockAppImageLister
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockLister = cmdmocks.NewMockAppImageLister(mockController)
+		command = commands.Images(logging.NewLogger(&outBuf, &outBuf, false, false), mockLister)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("lists each tag of the app images", func() {
+		mockLister.EXPECT().AppImages(gomock.Any()).Return([]pack.AppImage{
+			{
+				ID:       "sha256:0123456789abcdef",
+				Tags:     []string{"some/app:latest", "some/app:v2"},
+				RunImage: "some/run",
+				Stack:    "some.stack",
+				Created:  time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC),
+			},
+			{
+				ID:       "sha256:fedcba9876543210",
+				RunImage: "some/run",
+				Created:  time.Date(2019, 2, 1, 12, 0, 0, 0, time.UTC),
+			},
+		}, nil)
+
+		command.SetArgs([]string{})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "some/app:latest        0123456789ab        some/run         some.stack        2019-03-01T12:00:00Z")
+		h.AssertContains(t, outBuf.String(), "some/app:v2            0123456789ab        some/run         some.stack        2019-03-01T12:00:00Z")
+		h.AssertContains(t, outBuf.String(), "<none>                 fedcba987654        some/run                           2019-02-01T12:00:00Z")
+	})
+
+	it("tells when there are no app images", func() {
+		mockLister.EXPECT().AppImages(gomock.Any()).Return(nil, nil)
+
+		command.SetArgs([]string{})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "No app images")
+	})
+}
@@ -23,17 +23,18 @@ func InspectBuilder(logger *logging.Logger, cfg *config.Config, inspector Builde
 		Short: "Show information about a builder",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
-			if cfg.DefaultBuilder == "" && len(args) == 0 {
+			defaultBuilderName := defaultBuilder(cfg, "")
+			if defaultBuilderName == "" && len(args) == 0 {
 				suggestSettingBuilder(logger)
 				return MakeSoftError()
 			}
 
-			imageName := cfg.DefaultBuilder
+			imageName := defaultBuilderName
 			if len(args) >= 1 {
 				imageName = args[0]
 			}
 
-			if imageName == cfg.DefaultBuilder {
+			if imageName == defaultBuilderName {
 				logger.Info("Inspecting default builder: %s\n", style.Symbol(imageName))
 			} else {
 				logger.Info("Inspecting builder: %s\n", style.Symbol(imageName))
@@ -55,7 +56,7 @@ func InspectBuilder(logger *logging.Logger, cfg *config.Config, inspector Builde
 func inspectBuilderOutput(logger *logging.Logger, inspector BuilderInspector, imageName string, local bool) {
 	info, err := inspector.InspectBuilder(imageName, local)
 	if err != nil {
-		logger.Error(errors.Wrapf(err, "failed to inspect image %s", style.Symbol(imageName)).Error())
+		logger.Error("%s", errors.Wrapf(err, "failed to inspect image %s", style.Symbol(imageName)))
 		return
 	}
 
@@ -65,6 +66,9 @@ func inspectBuilderOutput(logger *logging.Logger, inspector BuilderInspector, im
 	}
 
 	logger.Info("Stack: %s\n", info.Stack)
+	if info.LifecycleVersion != "" {
+		logger.Info("Lifecycle Version: %s\n", info.LifecycleVersion)
+	}
 
 	logger.Info("Run Images:")
 	for _, r := range info.LocalRunImageMirrors {
@@ -94,17 +98,17 @@ func logBuildpacksInfo(logger *logging.Logger, info *pack.BuilderInfo) {
 	buf := &bytes.Buffer{}
 	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
 	if _, err := fmt.Fprint(tabWriter, "\n  ID\tVERSION\tLATEST\t"); err != nil {
-		logger.Error(err.Error())
+		logger.Error("%s", err)
 	}
 
 	for _, bp := range info.Buildpacks {
 		if _, err := fmt.Fprint(tabWriter, fmt.Sprintf("\n  %s\t%s\t%t\t", bp.ID, bp.Version, bp.Latest)); err != nil {
-			logger.Error(err.Error())
+			logger.Error("%s", err)
 		}
 	}
 
 	if err := tabWriter.Flush(); err != nil {
-		logger.Error(err.Error())
+		logger.Error("%s", err)
 	}
 
 	logger.Info("\nBuildpacks:" + buf.String())
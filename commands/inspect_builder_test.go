@@ -124,6 +124,7 @@ ERROR: failed to inspect image 'some/image': some local error
 
 				localInfo := &pack.BuilderInfo{
 					Stack:                "test.stack.id",
+					LifecycleVersion:     "0.1.0",
 					RunImage:             "some/run-image",
 					RunImageMirrors:      []string{"first/local-default", "second/local-default"},
 					LocalRunImageMirrors: []string{"first/local", "second/local"},
@@ -179,6 +180,8 @@ Local
 
 Stack: test.stack.id
 
+Lifecycle Version: 0.1.0
+
 Run Images:
   first/local (user-configured)
   second/local (user-configured)
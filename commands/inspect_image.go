@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+//go:generate mockgen -package mocks -destination mocks/inspect_image.go github.com/buildpack/pack/commands ImageInspector
+type ImageInspector interface {
+	InspectImage(name string, daemon bool) (*pack.ImageInfo, error)
+}
+
+func InspectImage(logger *logging.Logger, inspector ImageInspector) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inspect-image <image-name>",
+		Short: "Show information about an app image built by pack",
+		Args:  cobra.ExactArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			imageName := args[0]
+			logger.Info("Inspecting image: %s\n", style.Symbol(imageName))
+
+			logger.Info("Remote\n------\n")
+			inspectImageOutput(logger, inspector, imageName, false)
+
+			logger.Info("\nLocal\n-----\n")
+			inspectImageOutput(logger, inspector, imageName, true)
+
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "inspect-image")
+	return cmd
+}
+
+func inspectImageOutput(logger *logging.Logger, inspector ImageInspector, imageName string, daemon bool) {
+	info, err := inspector.InspectImage(imageName, daemon)
+	if err != nil {
+		logger.Error("%s", errors.Wrapf(err, "failed to inspect image %s", style.Symbol(imageName)))
+		return
+	}
+
+	if info == nil {
+		logger.Info("Not present")
+		return
+	}
+
+	logger.Info("Stack:")
+	logger.Info("  Run Image: %s", info.Stack.RunImage)
+	for _, m := range info.Stack.Mirrors {
+		logger.Info("  Run Image Mirror: %s", m)
+	}
+
+	logger.Info("\nBase Image:")
+	logger.Info("  Top Layer: %s", info.RunImage.TopLayer)
+	logger.Info("  Digest: %s", info.RunImage.SHA)
+
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
+	fmt.Fprint(tabWriter, "\n  ID\tVERSION\t")
+	for _, bp := range info.Buildpacks {
+		fmt.Fprintf(tabWriter, "\n  %s\t%s\t", bp.ID, bp.Version)
+	}
+	tabWriter.Flush()
+	logger.Info("\nBuildpacks:" + buf.String())
+
+	if len(info.Layers) == 0 {
+		return
+	}
+	buf.Reset()
+	tabWriter.Init(buf, 0, 0, 8, ' ', 0)
+	fmt.Fprint(tabWriter, "\n  BUILDPACK\tLAYER\tUSED FOR\tSHA\t")
+	for _, layer := range info.Layers {
+		fmt.Fprintf(tabWriter, "\n  %s\t%s\t%s\t%s\t", layer.Buildpack, layer.Name, layerUses(layer), layer.SHA)
+	}
+	tabWriter.Flush()
+	logger.Info("\nLayers:" + buf.String())
+}
+
+func layerUses(layer pack.LayerInfo) string {
+	var uses []string
+	if layer.Build {
+		uses = append(uses, "build")
+	}
+	if layer.Launch {
+		uses = append(uses, "launch")
+	}
+	if layer.Cache {
+		uses = append(uses, "cache")
+	}
+	if len(uses) == 0 {
+		return "-"
+	}
+	return strings.Join(uses, ",")
+}
@@ -0,0 +1,95 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestInspectImageCommand(t *testing.T) {
+	spec.Run(t, "Commands", testInspectImageCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testInspectImageCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockInspector  *cmdmocks.MockImageInspector
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockInspector = cmdmocks.NewMockImageInspector(mockController)
+		command = commands.InspectImage(logging.NewLogger(&outBuf, &outBuf, false, false), mockInspector)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#InspectImage", func() {
+		when("image cannot be found", func() {
+			it("logs 'Not present'", func() {
+				mockInspector.EXPECT().InspectImage("some/image", false).Return(nil, nil)
+				mockInspector.EXPECT().InspectImage("some/image", true).Return(nil, nil)
+
+				command.SetArgs([]string{"some/image"})
+				h.AssertNil(t, command.Execute())
+
+				h.AssertContains(t, outBuf.String(), "Remote\n------\n\nNot present\n\nLocal\n-----\n\nNot present\n")
+			})
+		})
+
+		when("inspector returns an error", func() {
+			it("logs the error message", func() {
+				mockInspector.EXPECT().InspectImage("some/image", false).Return(nil, errors.New("some remote error"))
+				mockInspector.EXPECT().InspectImage("some/image", true).Return(nil, nil)
+
+				command.SetArgs([]string{"some/image"})
+				h.AssertNil(t, command.Execute())
+
+				h.AssertContains(t, outBuf.String(), "ERROR: failed to inspect image 'some/image': some remote error")
+			})
+		})
+
+		when("the image is present", func() {
+			it("displays its stack, base image, buildpacks and layers", func() {
+				mockInspector.EXPECT().InspectImage("some/image", false).Return(nil, nil)
+				mockInspector.EXPECT().InspectImage("some/image", true).Return(&pack.ImageInfo{
+					Stack:      pack.StackInfo{RunImage: "some/run", Mirrors: []string{"gcr.io/some/run"}},
+					RunImage:   pack.RunImageInfo{TopLayer: "some-top-layer", SHA: "some-run-image-sha"},
+					Buildpacks: []pack.BuildpackInfo{{ID: "some.bp", Version: "1.2.3"}},
+					Layers: []pack.LayerInfo{
+						{Buildpack: "some.bp", Name: "some-layer", SHA: "some-layer-sha", Launch: true, Cache: true},
+					},
+				}, nil)
+
+				command.SetArgs([]string{"some/image"})
+				h.AssertNil(t, command.Execute())
+
+				h.AssertContains(t, outBuf.String(), `Stack:
+  Run Image: some/run
+  Run Image Mirror: gcr.io/some/run
+
+Base Image:
+  Top Layer: some-top-layer
+  Digest: some-run-image-sha
+`)
+				h.AssertContains(t, outBuf.String(), "some.bp        1.2.3")
+				h.AssertContains(t, outBuf.String(), "some.bp          some-layer        launch,cache        some-layer-sha")
+			})
+		})
+	})
+}
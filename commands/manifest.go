@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/manifest"
+	"github.com/buildpack/pack/style"
+)
+
+type ManifestManager interface {
+	CreateManifest(listName string, images []string) error
+	AddToManifest(listName, image string) error
+	AnnotateManifest(listName, image string, annotations manifest.Annotations) error
+	PushManifest(listName string, purge bool) (string, error)
+}
+
+func Manifest(logger *logging.Logger, manager ManifestManager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Assemble images built for several platforms into a manifest list and push it",
+	}
+	cmd.AddCommand(manifestCreate(logger, manager))
+	cmd.AddCommand(manifestAdd(logger, manager))
+	cmd.AddCommand(manifestAnnotate(logger, manager))
+	cmd.AddCommand(manifestPush(logger, manager))
+	AddHelpFlag(cmd, "manifest")
+	return cmd
+}
+
+func manifestCreate(logger *logging.Logger, manager ManifestManager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <manifest-list> <image>...",
+		Short: "Create a local manifest list from images in a registry",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if err := manager.CreateManifest(args[0], args[1:]); err != nil {
+				return err
+			}
+			logger.Info("Created manifest list %s", style.Symbol(args[0]))
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "manifest create")
+	return cmd
+}
+
+func manifestAdd(logger *logging.Logger, manager ManifestManager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <manifest-list> <image>",
+		Short: "Add an image in a registry to a local manifest list",
+		Args:  cobra.ExactArgs(2),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if err := manager.AddToManifest(args[0], args[1]); err != nil {
+				return err
+			}
+			logger.Info("Added %s to manifest list %s", style.Symbol(args[1]), style.Symbol(args[0]))
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "manifest add")
+	return cmd
+}
+
+func manifestAnnotate(logger *logging.Logger, manager ManifestManager) *cobra.Command {
+	var annotations manifest.Annotations
+
+	cmd := &cobra.Command{
+		Use:   "annotate <manifest-list> <image>",
+		Short: "Change the platform or annotations of an image in a local manifest list",
+		Args:  cobra.ExactArgs(2),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if err := manager.AnnotateManifest(args[0], args[1], annotations); err != nil {
+				return err
+			}
+			logger.Info("Annotated %s in manifest list %s", style.Symbol(args[1]), style.Symbol(args[0]))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&annotations.OS, "os", "", "Operating system of the image")
+	cmd.Flags().StringVar(&annotations.Arch, "arch", "", "Architecture of the image")
+	cmd.Flags().StringVar(&annotations.Variant, "variant", "", "Variant of the architecture of the image (e.g. 'v7' for arm)")
+	cmd.Flags().StringVar(&annotations.OSVersion, "os-version", "", "Version of the operating system of the image")
+	cmd.Flags().StringSliceVar(&annotations.OSFeatures, "os-features", nil, "Operating system feature required by the image"+multiValueHelp("feature"))
+	cmd.Flags().StringToStringVar(&annotations.Annotations, "annotations", nil, "Annotation of the image in the form 'key=value'"+multiValueHelp("annotation"))
+	AddHelpFlag(cmd, "manifest annotate")
+	return cmd
+}
+
+func manifestPush(logger *logging.Logger, manager ManifestManager) *cobra.Command {
+	var purge bool
+
+	cmd := &cobra.Command{
+		Use:   "push <manifest-list>",
+		Short: "Push a local manifest list to its registry",
+		Args:  cobra.ExactArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			digest, err := manager.PushManifest(args[0], purge)
+			if err != nil {
+				return err
+			}
+			logger.Info("Pushed manifest list %s with digest %s", style.Symbol(args[0]), style.Symbol(digest))
+			return nil
+		}),
+	}
+	cmd.Flags().BoolVar(&purge, "purge", false, "Delete the local manifest list after pushing it")
+	AddHelpFlag(cmd, "manifest push")
+	return cmd
+}
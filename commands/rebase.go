@@ -1,41 +1,33 @@
 package commands
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 
 	"github.com/buildpack/pack"
-	"github.com/buildpack/pack/config"
 	"github.com/buildpack/pack/logging"
 	"github.com/buildpack/pack/style"
 )
 
-func Rebase(logger *logging.Logger, fetcher pack.Fetcher) *cobra.Command {
+type ImageRebaser interface {
+	Rebase(ctx context.Context, flags pack.RebaseFlags) error
+}
+
+func Rebase(logger *logging.Logger, rebaser ImageRebaser) *cobra.Command {
 	var flags pack.RebaseFlags
-	ctx := createCancellableContext()
 
 	cmd := &cobra.Command{
 		Use:   "rebase <image-name>",
 		Args:  cobra.ExactArgs(1),
 		Short: "Rebase app image with latest run image",
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			ctx := createCancellableContext()
 			flags.RepoName = args[0]
-			cfg, err := config.NewDefault()
-			if err != nil {
-				return err
-			}
-			factory := pack.RebaseFactory{
-				Logger:  logger,
-				Config:  cfg,
-				Fetcher: fetcher,
-			}
-			rebaseConfig, err := factory.RebaseConfigFromFlags(ctx, flags)
-			if err != nil {
-				return err
-			}
-			if err := factory.Rebase(rebaseConfig); err != nil {
+			if err := rebaser.Rebase(ctx, flags); err != nil {
 				return err
 			}
-			logger.Info("Successfully rebased image %s", style.Symbol(rebaseConfig.Image.Name()))
+			logger.Info("Successfully rebased image %s", style.Symbol(flags.RepoName))
 			return nil
 		}),
 	}
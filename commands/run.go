@@ -1,50 +1,35 @@
 package commands
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 
 	"github.com/buildpack/pack"
-	"github.com/buildpack/pack/cache"
-	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/config"
 	"github.com/buildpack/pack/logging"
 )
 
-func Run(logger *logging.Logger, fetcher pack.Fetcher) *cobra.Command {
+type AppRunner interface {
+	Run(ctx context.Context, flags pack.RunFlags, ops ...func(*pack.BuildFactory)) error
+}
+
+func Run(logger *logging.Logger, cfg *config.Config, appRunner AppRunner) *cobra.Command {
 	var runFlags pack.RunFlags
-	ctx := createCancellableContext()
 
 	cmd := &cobra.Command{
 		Use:   "run",
 		Args:  cobra.NoArgs,
 		Short: "Build and run app image (recommended for development only)",
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
-			repoName, err := pack.RepositoryName(logger, &runFlags.BuildFlags)
-			if err != nil {
-				return err
-			}
-			dockerClient, err := docker.New()
-			if err != nil {
-				return err
-			}
-			cacheObj, err := cache.New(repoName, dockerClient)
-			if err != nil {
-				return err
-			}
-			bf, err := pack.DefaultBuildFactory(logger, cacheObj, dockerClient, fetcher)
-			if err != nil {
-				return err
-			}
-
-			if bf.Config.DefaultBuilder == "" && runFlags.BuildFlags.Builder == "" {
+			ctx := createCancellableContext()
+			if runFlags.BuildFlags.Builder == "" && defaultBuilder(cfg, runFlags.BuildFlags.AppDir) == "" {
 				suggestSettingBuilder(logger)
 				return MakeSoftError()
 			}
 
-			r, err := bf.RunConfigFromFlags(ctx, &runFlags)
-			if err != nil {
-				return err
-			}
-			return r.Run(ctx)
+			onEvent := buildEventHandler(logger, (&buildTimings{logger: logger}).HandleEvent)
+			return appRunner.Run(ctx, runFlags, append(interactiveBuildOps(logger), pack.WithEventHandler(onEvent))...)
 		}),
 	}
 
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/server"
+	"github.com/buildpack/pack/style"
+)
+
+func Serve(logger *logging.Logger, builder server.Builder) *cobra.Command {
+	var (
+		listen        string
+		workDir       string
+		maxConcurrent int
+		buildTTL      time.Duration
+		maxSourceSize = build.ByteSize(1024 * 1024 * 1024)
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Args:  cobra.NoArgs,
+		Short: "Run a server accepting builds over HTTP",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			ctx := createCancellableContext()
+			srv := server.New(builder, workDir,
+				server.WithMaxConcurrentBuilds(maxConcurrent),
+				server.WithBuildTTL(buildTTL),
+				server.WithMaxSourceSize(int64(maxSourceSize)),
+				server.WithLogger(logger),
+				server.WithLogFormat(logger.Format()),
+			)
+			httpServer := &http.Server{Addr: listen, Handler: srv.Handler()}
+
+			errs := make(chan error, 1)
+			go func() {
+				errs <- httpServer.ListenAndServe()
+			}()
+			logger.Info("Listening for builds on %s", style.Symbol(listen))
+
+			select {
+			case err := <-errs:
+				srv.Close()
+				return err
+			case <-ctx.Done():
+			}
+			logger.Info("Shutting down, canceling running builds")
+			srv.Close()
+			return httpServer.Shutdown(context.Background())
+		}),
+	}
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:8080", "Address to listen on")
+	cmd.Flags().StringVar(&workDir, "work-dir", filepath.Join(os.TempDir(), "pack-serve"), "Directory holding app sources while they are built")
+	cmd.Flags().IntVar(&maxConcurrent, "max-concurrent-builds", 2, "Maximum number of builds running at once")
+	cmd.Flags().DurationVar(&buildTTL, "build-ttl", time.Hour, "How long the status and output of finished builds are kept")
+	cmd.Flags().Var(&maxSourceSize, "max-source-size", "Reject app sources uploaded with a build larger than this size, such as '500MB'")
+	AddHelpFlag(cmd, "serve")
+	return cmd
+}
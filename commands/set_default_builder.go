@@ -2,13 +2,19 @@ package commands
 
 import (
 	"fmt"
+	"github.com/buildpack/pack"
 	"github.com/buildpack/pack/config"
 	"github.com/buildpack/pack/logging"
 	"github.com/buildpack/pack/style"
 	"github.com/spf13/cobra"
 )
 
-func SetDefaultBuilder(logger *logging.Logger) *cobra.Command {
+//go:generate mockgen -package mocks -destination mocks/builder_suggester.go github.com/buildpack/pack/commands BuilderSuggester
+type BuilderSuggester interface {
+	SuggestBuilders() []pack.SuggestedBuilder
+}
+
+func SetDefaultBuilder(logger *logging.Logger, suggester BuilderSuggester) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "set-default-builder <builder-name>",
 		Short: "Set default builder used by other commands",
@@ -17,7 +23,7 @@ func SetDefaultBuilder(logger *logging.Logger) *cobra.Command {
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 || args[0] == "" {
 				logger.Info(fmt.Sprintf("Usage:\n\t%s\n", cmd.UseLine()))
-				suggestBuilders(logger)
+				suggestBuilders(logger, suggester.SuggestBuilders())
 				return nil
 			}
 
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 	"github.com/spf13/cobra"
 
+	"github.com/buildpack/pack"
 	"github.com/buildpack/pack/commands"
+	"github.com/buildpack/pack/commands/mocks"
 	"github.com/buildpack/pack/logging"
 	h "github.com/buildpack/pack/testhelpers"
 )
@@ -23,11 +26,21 @@ func testSetDefaultBuilderCommand(t *testing.T, when spec.G, it spec.S) {
 		command        *cobra.Command
 		logger         *logging.Logger
 		outBuf         bytes.Buffer
+		mockController *gomock.Controller
 	)
 
 	it.Before(func() {
+		mockController = gomock.NewController(t)
+		suggester := mocks.NewMockBuilderSuggester(mockController)
+		suggester.EXPECT().SuggestBuilders().Return([]pack.SuggestedBuilder{
+			{Vendor: "Some Vendor", Image: "some/builder", Description: "some description"},
+		}).AnyTimes()
 		logger = logging.NewLogger(&outBuf, &outBuf, false, false)
-		command = commands.SetDefaultBuilder(logger)
+		command = commands.SetDefaultBuilder(logger, suggester)
+	})
+
+	it.After(func() {
+		mockController.Finish()
 	})
 
 	when("#SetDefaultBuilder", func() {
@@ -36,6 +49,8 @@ func testSetDefaultBuilderCommand(t *testing.T, when spec.G, it spec.S) {
 				command.SetArgs([]string{})
 				h.AssertNil(t, command.Execute())
 				h.AssertContains(t, outBuf.String(), "Suggested builders:")
+				h.AssertContains(t, outBuf.String(), "Some Vendor:")
+				h.AssertContains(t, outBuf.String(), "some description")
 			})
 		})
 
@@ -24,7 +24,9 @@ func SetRunImagesMirrors(logger *logging.Logger) *cobra.Command {
 			}
 
 			builder := args[0]
-			cfg.SetRunImageMirrors(builder, runImages)
+			if err := cfg.SetRunImageMirrors(builder, runImages); err != nil {
+				return err
+			}
 			logger.Info("Run Image %s configured with mirror '%s'", style.Symbol(builder), strings.Join(runImages, ","))
 			return nil
 		}),
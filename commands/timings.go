@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+)
+
+var timingSteps = []struct {
+	phase string
+	label string
+}{
+	{"", "pull"},
+	{"creator", "create"},
+	{"detector", "detect"},
+	{"restorer", "restore"},
+	{"analyzer", "analyze"},
+	{"builder", "build"},
+	{"exporter", "export"},
+	{"cacher", "cache"},
+}
+
+// buildTimings records the wall-clock time spent pulling images and in each phase of a build,
+// and prints a summary once the build completes
+type buildTimings struct {
+	logger *logging.Logger
+	pull   time.Duration
+	phases map[string]time.Duration
+}
+
+func (t *buildTimings) HandleEvent(e pack.Event) {
+	switch e.Type {
+	case pack.ImagePulled:
+		t.pull += e.Duration
+	case pack.PhaseFinished:
+		if t.phases == nil {
+			t.phases = map[string]time.Duration{}
+		}
+		t.phases[e.Phase] = e.Duration
+	case pack.BuildCompleted:
+		t.print()
+	}
+}
+
+// print logs a table of the recorded timings. Phases that did not run are left out.
+func (t *buildTimings) print() {
+	if len(t.phases) == 0 {
+		return
+	}
+
+	t.logger.Info("Timing summary:")
+	var total time.Duration
+	for _, step := range timingSteps {
+		d, ok := t.phases[step.phase]
+		if step.phase == "" {
+			d, ok = t.pull, t.pull > 0
+		}
+		if !ok {
+			continue
+		}
+		total += d
+		t.logger.Info(formatTiming(step.label, d))
+	}
+	t.logger.Info(formatTiming("total", total))
+}
+
+func formatTiming(label string, d time.Duration) string {
+	return fmt.Sprintf("  %-9s%8.1fs", label, d.Seconds())
+}
@@ -0,0 +1,111 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBuildTimings(t *testing.T) {
+	spec.Run(t, "Commands", testBuildTimings, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuildTimings(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockAppBuilder *cmdmocks.MockAppBuilder
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockAppBuilder = cmdmocks.NewMockAppBuilder(mockController)
+		cfg := &config.Config{DefaultBuilder: "some/builder"}
+		command = commands.Build(logging.NewLogger(&outBuf, &outBuf, false, false), cfg, mockAppBuilder)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	// buildEmitting expects a build which emits events to the event handler of the command
+	buildEmitting := func(events ...pack.Event) {
+		mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ pack.BuildFlags, ops ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+				bf := &pack.BuildFactory{}
+				for _, op := range ops {
+					op(bf)
+				}
+				for _, e := range events {
+					bf.OnEvent(e)
+				}
+				return &pack.BuildResult{Image: "some/app"}, nil
+			})
+	}
+
+	when("the build completes", func() {
+		it("prints the time spent pulling images and in each phase, in the order they run", func() {
+			buildEmitting(
+				pack.Event{Type: pack.ImagePulled, Image: "some/builder", Duration: 1500 * time.Millisecond},
+				pack.Event{Type: pack.ImagePulled, Image: "some/run", Duration: 500 * time.Millisecond},
+				pack.Event{Type: pack.PhaseFinished, Phase: "builder", Duration: 3300 * time.Millisecond},
+				pack.Event{Type: pack.PhaseFinished, Phase: "detector", Duration: 1200 * time.Millisecond},
+				pack.Event{Type: pack.PhaseFinished, Phase: "exporter", Duration: 800 * time.Millisecond},
+				pack.Event{Type: pack.BuildCompleted, Image: "some/app"},
+			)
+
+			command.SetArgs([]string{"some/app"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), `Timing summary:
+  pull          2.0s
+  detect        1.2s
+  build         3.3s
+  export        0.8s
+  total         7.3s
+`)
+			h.AssertNotContains(t, outBuf.String(), "restore")
+		})
+
+		it("leaves out pulling when no image was pulled", func() {
+			buildEmitting(
+				pack.Event{Type: pack.PhaseFinished, Phase: "creator", Duration: 12 * time.Second},
+				pack.Event{Type: pack.BuildCompleted, Image: "some/app"},
+			)
+
+			command.SetArgs([]string{"some/app"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertContains(t, outBuf.String(), `Timing summary:
+  create       12.0s
+  total        12.0s
+`)
+			h.AssertNotContains(t, outBuf.String(), "pull")
+		})
+
+		it("prints no summary when no phase ran", func() {
+			buildEmitting(
+				pack.Event{Type: pack.ImagePulled, Image: "some/builder", Duration: time.Second},
+				pack.Event{Type: pack.BuildCompleted, Image: "some/app"},
+			)
+
+			command.SetArgs([]string{"some/app"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertNotContains(t, outBuf.String(), "Timing summary")
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/pkg/term"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+func TrustBuilder(logger *logging.Logger, cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trust-builder <builder-image-name>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Trust a builder to run every phase of its builds, with access to the docker daemon and registries",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if err := cfg.TrustBuilder(args[0]); err != nil {
+				return err
+			}
+			logger.Info("Builder %s is now trusted", style.Symbol(args[0]))
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "trust-builder")
+	return cmd
+}
+
+func UntrustBuilder(logger *logging.Logger, cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "untrust-builder <builder-image-name>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Stop trusting a builder, running the phases of its builds with access to the docker daemon or registries in the lifecycle image",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if err := cfg.UntrustBuilder(args[0]); err != nil {
+				return err
+			}
+			logger.Info("Builder %s is no longer trusted", style.Symbol(args[0]))
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "untrust-builder")
+	return cmd
+}
+
+// stdinIsTerminal reports whether stdin is a terminal, which builds may prompt on
+var stdinIsTerminal = func() bool {
+	_, isTerm := term.GetFdInfo(os.Stdin)
+	return isTerm
+}
+
+// interactiveBuildOps returns the options of builds started from the command line, which ask
+// whether to trust builders that are not trusted when stdin is a terminal, unless NonInteractive is
+// set. Builders that are not trusted otherwise run their phases with the lifecycle image.
+func interactiveBuildOps(logger *logging.Logger) []func(*pack.BuildFactory) {
+	if NonInteractive || !stdinIsTerminal() {
+		return nil
+	}
+	return []func(*pack.BuildFactory){pack.WithTrustPrompt(trustPrompt(logger, os.Stdin))}
+}
+
+// trustPrompt asks whether to trust builders, reading the answer from in
+func trustPrompt(logger *logging.Logger, in io.Reader) pack.TrustPrompt {
+	reader := bufio.NewReader(in)
+	return func(builder string) (bool, error) {
+		logger.Info("Builder %s is not trusted. Trusted builders run every phase of their builds themselves, with access\n  to the docker daemon and registry credentials.", style.Symbol(builder))
+		fmt.Fprint(logger.RawWriter(), "Trust it for this and later builds? [y/N] ")
+		answer, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true, nil
+		}
+		return false, nil
+	}
+}
@@ -0,0 +1,135 @@
+package commands_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestTrustBuilderCommands(t *testing.T) {
+	spec.Run(t, "Commands", testTrustBuilderCommands, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+// TestTrustPrompt changes whether commands are non-interactive and see a terminal, so it does not
+// run in parallel
+func TestTrustPrompt(t *testing.T) {
+	spec.Run(t, "Commands", testTrustPrompt, spec.Report(report.Terminal{}))
+}
+
+func testTrustBuilderCommands(t *testing.T, when spec.G, it spec.S) {
+	var (
+		logger *logging.Logger
+		outBuf bytes.Buffer
+		tmpDir string
+		cfg    *config.Config
+	)
+
+	it.Before(func() {
+		var err error
+		logger = logging.NewLogger(&outBuf, &outBuf, false, false)
+		tmpDir, err = ioutil.TempDir("", "pack.trust-builder")
+		h.AssertNil(t, err)
+		cfg, err = config.New(tmpDir)
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	it("trusts and untrusts the builder", func() {
+		trust := commands.TrustBuilder(logger, cfg)
+		trust.SetArgs([]string{"some/builder"})
+		h.AssertNil(t, trust.Execute())
+		h.AssertContains(t, outBuf.String(), "Builder 'some/builder' is now trusted")
+
+		saved, err := config.New(tmpDir)
+		h.AssertNil(t, err)
+		h.AssertEq(t, saved.IsTrustedBuilder("some/builder"), true)
+
+		untrust := commands.UntrustBuilder(logger, cfg)
+		untrust.SetArgs([]string{"some/builder"})
+		h.AssertNil(t, untrust.Execute())
+		h.AssertContains(t, outBuf.String(), "Builder 'some/builder' is no longer trusted")
+		h.AssertEq(t, cfg.IsTrustedBuilder("some/builder"), false)
+	})
+
+	it("fails to untrust a builder that is not trusted", func() {
+		untrust := commands.UntrustBuilder(logger, cfg)
+		untrust.SetArgs([]string{"some/builder"})
+		h.AssertError(t, untrust.Execute(), "builder 'some/builder' is not trusted")
+	})
+}
+
+func testTrustPrompt(t *testing.T, when spec.G, it spec.S) {
+	var (
+		mockController *gomock.Controller
+		mockAppBuilder *cmdmocks.MockAppBuilder
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		restore        func()
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockAppBuilder = cmdmocks.NewMockAppBuilder(mockController)
+		command = commands.Build(logging.NewLogger(&outBuf, &outBuf, false, false), &config.Config{DefaultBuilder: "some/builder"}, mockAppBuilder)
+		restore = commands.SetStdinIsTerminal(true)
+	})
+
+	it.After(func() {
+		restore()
+		commands.NonInteractive = false
+		mockController.Finish()
+	})
+
+	// expectTrustPrompt expects a build whose factory has a trust prompt when prompt is true
+	expectTrustPrompt := func(prompt bool) {
+		mockAppBuilder.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ pack.BuildFlags, ops ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+				bf := &pack.BuildFactory{}
+				for _, op := range ops {
+					op(bf)
+				}
+				h.AssertEq(t, bf.TrustPrompt != nil, prompt)
+				return &pack.BuildResult{Image: "some/app"}, nil
+			})
+	}
+
+	it("asks whether to trust builders when stdin is a terminal", func() {
+		expectTrustPrompt(true)
+
+		command.SetArgs([]string{"some/app"})
+		h.AssertNil(t, command.Execute())
+	})
+
+	it("does not ask whether to trust builders when stdin is not a terminal", func() {
+		restore()
+		restore = commands.SetStdinIsTerminal(false)
+		expectTrustPrompt(false)
+
+		command.SetArgs([]string{"some/app"})
+		h.AssertNil(t, command.Execute())
+	})
+
+	it("does not ask whether to trust builders when non-interactive, as in CI", func() {
+		commands.NonInteractive = true
+		expectTrustPrompt(false)
+
+		command.SetArgs([]string{"some/app"})
+		h.AssertNil(t, command.Execute())
+	})
+}
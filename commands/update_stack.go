@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+)
+
+//go:generate mockgen -package mocks -destination mocks/stack_updater.go github.com/buildpack/pack/commands StackUpdater
+type StackUpdater interface {
+	UpdateStack(ctx context.Context, flags pack.UpdateStackFlags) ([]pack.StaleImage, error)
+}
+
+func UpdateStack(logger *logging.Logger, updater StackUpdater) *cobra.Command {
+	var flags pack.UpdateStackFlags
+
+	cmd := &cobra.Command{
+		Use:   "update-stack",
+		Args:  cobra.NoArgs,
+		Short: "Pull the latest run images and list the app images to rebase onto them",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			stale, err := updater.UpdateStack(createCancellableContext(), flags)
+			if len(stale) > 0 {
+				logger.Info(staleImageTable(stale))
+			} else if err == nil {
+				logger.Info("All app images are based on the latest run images")
+			}
+			return err
+		}),
+	}
+	cmd.Flags().BoolVar(&flags.NoPull, "no-pull", false, "Compare app images with the run images in the daemon, without pulling them")
+	cmd.Flags().BoolVar(&flags.Rebase, "rebase", false, "Rebase the app images onto the latest run images")
+	AddHelpFlag(cmd, "update-stack")
+	return cmd
+}
+
+func staleImageTable(stale []pack.StaleImage) string {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
+	fmt.Fprint(tabWriter, "  IMAGE\tRUN IMAGE\tSTATUS\t")
+	for _, image := range stale {
+		status := "stale"
+		if image.Rebased {
+			status = "rebased"
+		}
+		fmt.Fprintf(tabWriter, "\n  %s\t%s\t%s\t", image.Image, image.RunImage, status)
+	}
+	tabWriter.Flush()
+	return buf.String()
+}
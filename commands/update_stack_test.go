@@ -0,0 +1,71 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestUpdateStackCommand(t *testing.T) {
+	spec.Run(t, "Commands", testUpdateStackCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testUpdateStackCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockUpdater    *cmdmocks.MockStackUpdater
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockUpdater = cmdmocks.NewMockStackUpdater(mockController)
+		command = commands.UpdateStack(logging.NewLogger(&outBuf, &outBuf, false, false), mockUpdater)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("lists the stale app images", func() {
+		mockUpdater.EXPECT().UpdateStack(gomock.Any(), pack.UpdateStackFlags{}).Return([]pack.StaleImage{
+			{Image: "some/app:latest", RunImage: "some/run"},
+		}, nil)
+
+		command.SetArgs([]string{})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "some/app:latest        some/run         stale")
+	})
+
+	it("lists the rebased app images", func() {
+		mockUpdater.EXPECT().UpdateStack(gomock.Any(), pack.UpdateStackFlags{NoPull: true, Rebase: true}).Return([]pack.StaleImage{
+			{Image: "some/app:latest", RunImage: "some/run", Rebased: true},
+		}, nil)
+
+		command.SetArgs([]string{"--no-pull", "--rebase"})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "some/app:latest        some/run         rebased")
+	})
+
+	it("tells when no app image is stale", func() {
+		mockUpdater.EXPECT().UpdateStack(gomock.Any(), pack.UpdateStackFlags{}).Return(nil, nil)
+
+		command.SetArgs([]string{})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "All app images are based on the latest run images")
+	})
+}
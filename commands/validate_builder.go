@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+//go:generate mockgen -package mocks -destination mocks/builder_validator.go github.com/buildpack/pack/commands BuilderValidator
+type BuilderValidator interface {
+	ValidateBuilder(ctx context.Context, name string) ([]pack.BuilderFinding, error)
+}
+
+func ValidateBuilder(logger *logging.Logger, validator BuilderValidator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate-builder <builder-image-name>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Check a builder image in the docker daemon before building with it",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			findings, err := validator.ValidateBuilder(createCancellableContext(), args[0])
+			if err != nil {
+				return err
+			}
+			if len(findings) == 0 {
+				logger.Info("Builder %s is valid", style.Symbol(args[0]))
+				return nil
+			}
+			logger.Info(findingTable(findings))
+			for _, finding := range findings {
+				if finding.Fatal {
+					return errors.Errorf("builds with builder %s would fail", style.Symbol(args[0]))
+				}
+			}
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "validate-builder")
+	return cmd
+}
+
+func findingTable(findings []pack.BuilderFinding) string {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 4, ' ', 0)
+	fmt.Fprint(tabWriter, "  CHECK\tSEVERITY\tFINDING\t")
+	for _, finding := range findings {
+		severity := "warning"
+		if finding.Fatal {
+			severity = "error"
+		}
+		fmt.Fprintf(tabWriter, "\n  %s\t%s\t%s\t", finding.Check, severity, finding.Message)
+	}
+	tabWriter.Flush()
+	return buf.String()
+}
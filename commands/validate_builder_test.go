@@ -0,0 +1,74 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestValidateBuilderCommand(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Commands", testValidateBuilderCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testValidateBuilderCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockValidator  *cmdmocks.MockBuilderValidator
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockValidator = cmdmocks.NewMockBuilderValidator(mockController)
+		command = commands.ValidateBuilder(logging.NewLogger(&outBuf, &outBuf, false, false), mockValidator)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("tells when the builder is valid", func() {
+		mockValidator.EXPECT().ValidateBuilder(gomock.Any(), "some/builder").Return(nil, nil)
+
+		command.SetArgs([]string{"some/builder"})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "Builder 'some/builder' is valid")
+	})
+
+	it("lists the findings", func() {
+		mockValidator.EXPECT().ValidateBuilder(gomock.Any(), "some/builder").Return([]pack.BuilderFinding{
+			{Check: pack.CheckUser, Message: "some warning"},
+		}, nil)
+
+		command.SetArgs([]string{"some/builder"})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "user     warning     some warning")
+	})
+
+	it("fails when a finding is fatal", func() {
+		mockValidator.EXPECT().ValidateBuilder(gomock.Any(), "some/builder").Return([]pack.BuilderFinding{
+			{Check: pack.CheckLifecycle, Message: "some error", Fatal: true},
+		}, nil)
+
+		command.SetArgs([]string{"some/builder"})
+		h.AssertNotNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "lifecycle    error       some error")
+		h.AssertContains(t, outBuf.String(), "builds with builder 'some/builder' would fail")
+	})
+}
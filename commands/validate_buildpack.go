@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack/descriptor"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+//go:generate mockgen -package mocks -destination mocks/buildpack_validator.go github.com/buildpack/pack/commands BuildpackValidator
+type BuildpackValidator interface {
+	ValidateBuildpack(dir string) error
+}
+
+func ValidateBuildpack(logger *logging.Logger, validator BuildpackValidator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate-buildpack [<buildpack-dir>]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Check the buildpack.toml and executables of a buildpack directory",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			err := validator.ValidateBuildpack(dir)
+			if errs, ok := err.(descriptor.ValidationErrors); ok {
+				for _, verr := range errs {
+					logger.Info("  %s", verr)
+				}
+				return errors.Errorf("buildpack %s is invalid", style.Symbol(dir))
+			}
+			if err != nil {
+				return err
+			}
+			logger.Info("Buildpack %s is valid", style.Symbol(dir))
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "validate-buildpack")
+	return cmd
+}
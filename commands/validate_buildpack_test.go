@@ -0,0 +1,62 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/descriptor"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestValidateBuildpackCommand(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Commands", testValidateBuildpackCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testValidateBuildpackCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockValidator  *cmdmocks.MockBuildpackValidator
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockValidator = cmdmocks.NewMockBuildpackValidator(mockController)
+		command = commands.ValidateBuildpack(logging.NewLogger(&outBuf, &outBuf, false, false), mockValidator)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("validates the current directory by default", func() {
+		mockValidator.EXPECT().ValidateBuildpack(".").Return(nil)
+
+		command.SetArgs([]string{})
+		h.AssertNil(t, command.Execute())
+
+		h.AssertContains(t, outBuf.String(), "Buildpack '.' is valid")
+	})
+
+	it("lists the problems found", func() {
+		mockValidator.EXPECT().ValidateBuildpack("some/buildpack").Return(descriptor.ValidationErrors{
+			{File: "buildpack.toml", Key: "buildpack.id", Line: 2, Message: "is required"},
+		})
+
+		command.SetArgs([]string{"some/buildpack"})
+		h.AssertError(t, command.Execute(), "buildpack 'some/buildpack' is invalid")
+
+		h.AssertContains(t, outBuf.String(), "buildpack.toml:2: buildpack.id: is required")
+	})
+}
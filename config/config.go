@@ -2,8 +2,10 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/BurntSushi/toml"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -12,7 +14,19 @@ import (
 type Config struct {
 	RunImages      []RunImage `toml:"run-images"`
 	DefaultBuilder string     `toml:"default-builder-image,omitempty"`
-	configPath     string
+	// TrustedBuilders run every phase of their builds themselves. Other builders only run detect and
+	// build, while the phases with access to the docker daemon or registries run in a lifecycle image.
+	TrustedBuilders []string `toml:"trusted-builders,omitempty"`
+	// BuildpackRegistry is the URL of the index of a buildpack registry, which builds download the
+	// buildpacks given as 'id@version' from when their builder does not contain them
+	BuildpackRegistry string `toml:"buildpack-registry,omitempty"`
+	// InsecureRegistries are the registries, given as <host>[:<port>], that builds, rebases and
+	// other registry operations reach over HTTP rather than HTTPS, such as internal registries
+	// without TLS
+	InsecureRegistries []string `toml:"insecure-registries,omitempty"`
+	configPath         string
+	// project is the directory of the project whose configuration overrides this one, see ForProject
+	project string
 }
 
 type RunImage struct {
@@ -45,6 +59,9 @@ func New(path string) (*Config, error) {
 }
 
 func (c *Config) save() error {
+	if c.project != "" {
+		return fmt.Errorf("the configuration of project '%s' cannot be changed by pack", c.project)
+	}
 	if err := os.MkdirAll(filepath.Dir(c.configPath), 0777); err != nil {
 		return err
 	}
@@ -79,6 +96,48 @@ func (c *Config) SetDefaultBuilder(builder string) error {
 	return c.save()
 }
 
+// IsTrustedBuilder reports whether the builder image is one of the TrustedBuilders
+func (c *Config) IsTrustedBuilder(builder string) bool {
+	for _, trusted := range c.TrustedBuilders {
+		if trusted == builder {
+			return true
+		}
+	}
+	return false
+}
+
+// TrustBuilder adds the builder image to the TrustedBuilders, so that its builds run every phase
+// themselves
+func (c *Config) TrustBuilder(builder string) error {
+	if c.IsTrustedBuilder(builder) {
+		return nil
+	}
+	c.TrustedBuilders = append(c.TrustedBuilders, builder)
+	sort.Strings(c.TrustedBuilders)
+	return c.save()
+}
+
+// UntrustBuilder removes the builder image from the TrustedBuilders
+func (c *Config) UntrustBuilder(builder string) error {
+	if !c.IsTrustedBuilder(builder) {
+		return fmt.Errorf("builder '%s' is not trusted", builder)
+	}
+	var trusted []string
+	for _, b := range c.TrustedBuilders {
+		if b != builder {
+			trusted = append(trusted, b)
+		}
+	}
+	c.TrustedBuilders = trusted
+	return c.save()
+}
+
+// IsInsecureRegistry reports whether the registry, given as <host>[:<port>], is one of the
+// InsecureRegistries
+func (c *Config) IsInsecureRegistry(registry string) bool {
+	return contains(c.InsecureRegistries, registry)
+}
+
 func (c *Config) GetRunImage(runImageTag string) *RunImage {
 	for i := range c.RunImages {
 		runImage := &c.RunImages[i]
@@ -89,13 +148,64 @@ func (c *Config) GetRunImage(runImageTag string) *RunImage {
 	return nil
 }
 
-func (c *Config) SetRunImageMirrors(image string, mirrors []string) {
+// SetRunImageMirrors replaces the locally configured mirrors of the run image, which builds and
+// rebases prefer over the mirrors in builders
+func (c *Config) SetRunImageMirrors(image string, mirrors []string) error {
 	if runImage := c.GetRunImage(image); runImage != nil {
 		runImage.Mirrors = mirrors
 	} else {
 		c.RunImages = append(c.RunImages, RunImage{Image: image, Mirrors: mirrors})
 	}
-	c.save()
+	return c.save()
+}
+
+// ListRunImageMirrors returns the run images with locally configured mirrors, sorted by image
+func (c *Config) ListRunImageMirrors() []RunImage {
+	runImages := append([]RunImage{}, c.RunImages...)
+	sort.Slice(runImages, func(i, j int) bool {
+		return runImages[i].Image < runImages[j].Image
+	})
+	return runImages
+}
+
+// RemoveRunImageMirrors removes the given mirrors of the run image, or all of them when none are
+// given. The run image is no longer configured once it has no mirrors left.
+func (c *Config) RemoveRunImageMirrors(image string, mirrors ...string) error {
+	runImage := c.GetRunImage(image)
+	if runImage == nil {
+		return fmt.Errorf("run image '%s' has no mirrors configured", image)
+	}
+	if len(mirrors) > 0 {
+		var kept []string
+		for _, mirror := range runImage.Mirrors {
+			if !contains(mirrors, mirror) {
+				kept = append(kept, mirror)
+			}
+		}
+		runImage.Mirrors = kept
+	} else {
+		runImage.Mirrors = nil
+	}
+
+	if len(runImage.Mirrors) == 0 {
+		var runImages []RunImage
+		for _, ri := range c.RunImages {
+			if ri.Image != image {
+				runImages = append(runImages, ri)
+			}
+		}
+		c.RunImages = runImages
+	}
+	return c.save()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 func ImageByRegistry(registry string, images []string) (string, error) {
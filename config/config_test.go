@@ -142,6 +142,57 @@ default-builder-image = "old/builder"
 		})
 	})
 
+	when("Config#IsTrustedBuilder", func() {
+		it("reports whether the builder is trusted", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "config.toml"), []byte(`
+trusted-builders = ["some/builder"]
+`), 0666))
+			subject, err := config.New(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, subject.IsTrustedBuilder("some/builder"), true)
+			h.AssertEq(t, subject.IsTrustedBuilder("some-other/builder"), false)
+		})
+	})
+
+	when("Config#IsInsecureRegistry", func() {
+		it("reports whether the registry is reached over http", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "config.toml"), []byte(`
+insecure-registries = ["registry.internal:5000"]
+`), 0666))
+			subject, err := config.New(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, subject.IsInsecureRegistry("registry.internal:5000"), true)
+			h.AssertEq(t, subject.IsInsecureRegistry("registry.internal"), false)
+		})
+	})
+
+	when("Config#TrustBuilder", func() {
+		it("saves the builder among the trusted builders once", func() {
+			subject, err := config.New(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertNil(t, subject.TrustBuilder("some/builder"))
+			h.AssertNil(t, subject.TrustBuilder("other/builder"))
+			h.AssertNil(t, subject.TrustBuilder("some/builder"))
+
+			b, err := ioutil.ReadFile(filepath.Join(tmpDir, "config.toml"))
+			h.AssertNil(t, err)
+			h.AssertContains(t, string(b), `trusted-builders = ["other/builder", "some/builder"]`)
+		})
+	})
+
+	when("Config#UntrustBuilder", func() {
+		it("removes the builder from the trusted builders", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "config.toml"), []byte(`
+trusted-builders = ["some/builder", "other/builder"]
+`), 0666))
+			subject, err := config.New(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertNil(t, subject.UntrustBuilder("some/builder"))
+			h.AssertEq(t, subject.TrustedBuilders, []string{"other/builder"})
+			h.AssertError(t, subject.UntrustBuilder("some/builder"), "builder 'some/builder' is not trusted")
+		})
+	})
+
 	when("Config#SetRunImageMirrors", func() {
 		var subject *config.Config
 
@@ -158,7 +209,7 @@ default-builder-image = "old/builder"
 			})
 
 			it("updates the run image", func() {
-				subject.SetRunImageMirrors("some/run-image", []string{"some-other/run"})
+				h.AssertNil(t, subject.SetRunImageMirrors("some/run-image", []string{"some-other/run"}))
 
 				reloadedConfig, err := config.New(tmpDir)
 				h.AssertNil(t, err)
@@ -181,7 +232,7 @@ default-builder-image = "old/builder"
 			})
 
 			it("adds the run image", func() {
-				subject.SetRunImageMirrors("some/run-image", []string{"some-other/run"})
+				h.AssertNil(t, subject.SetRunImageMirrors("some/run-image", []string{"some-other/run"}))
 
 				reloadedConfig, err := config.New(tmpDir)
 				h.AssertNil(t, err)
@@ -196,6 +247,188 @@ default-builder-image = "old/builder"
 		})
 	})
 
+	when("Config#ListRunImageMirrors", func() {
+		it("returns the run images sorted by image", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "config.toml"), []byte(`
+[[run-images]]
+  image = "some/run-image"
+  mirrors = ["some/run"]
+
+[[run-images]]
+  image = "other/run-image"
+  mirrors = ["other/run"]
+`), 0666))
+			subject, err := config.New(tmpDir)
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, subject.ListRunImageMirrors(), []config.RunImage{
+				{Image: "other/run-image", Mirrors: []string{"other/run"}},
+				{Image: "some/run-image", Mirrors: []string{"some/run"}},
+			})
+		})
+	})
+
+	when("Config#ForProject", func() {
+		var (
+			subject    *config.Config
+			projectDir string
+		)
+
+		it.Before(func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "config.toml"), []byte(`
+default-builder-image = "some/builder"
+trusted-builders = ["other/builder", "some/builder"]
+
+[[run-images]]
+  image = "some/run-image"
+  mirrors = ["some/run"]
+
+[[run-images]]
+  image = "other/run-image"
+  mirrors = ["other/run"]
+`), 0666))
+			var err error
+			subject, err = config.New(tmpDir)
+			h.AssertNil(t, err)
+			projectDir = filepath.Join(tmpDir, "project")
+			h.AssertNil(t, os.MkdirAll(filepath.Join(projectDir, ".pack"), 0777))
+		})
+
+		it("returns the config itself when the project has no config", func() {
+			projectConfig, err := subject.ForProject(filepath.Join(tmpDir, "other-project"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, projectConfig == subject, true)
+		})
+
+		it("overrides the values set by the project", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(projectDir, ".pack", "config.toml"), []byte(`
+default-builder-image = "project/builder"
+
+[[run-images]]
+  image = "some/run-image"
+  mirrors = ["project/run"]
+
+[[run-images]]
+  image = "project/run-image"
+  mirrors = ["project/mirror"]
+`), 0666))
+
+			projectConfig, err := subject.ForProject(projectDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, projectConfig.DefaultBuilder, "project/builder")
+			h.AssertEq(t, projectConfig.TrustedBuilders, []string{"other/builder", "some/builder"})
+			h.AssertEq(t, projectConfig.ListRunImageMirrors(), []config.RunImage{
+				{Image: "other/run-image", Mirrors: []string{"other/run"}},
+				{Image: "project/run-image", Mirrors: []string{"project/mirror"}},
+				{Image: "some/run-image", Mirrors: []string{"project/run"}},
+			})
+			h.AssertEq(t, projectConfig.Path(), subject.Path())
+
+			h.AssertEq(t, subject.DefaultBuilder, "some/builder")
+			h.AssertEq(t, subject.GetRunImage("some/run-image").Mirrors, []string{"some/run"})
+		})
+
+		it("only narrows the trusted builders", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(projectDir, ".pack", "config.toml"), []byte(`
+trusted-builders = ["project/builder", "some/builder"]
+`), 0666))
+
+			projectConfig, err := subject.ForProject(projectDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, projectConfig.TrustedBuilders, []string{"some/builder"})
+			h.AssertEq(t, projectConfig.IsTrustedBuilder("project/builder"), false)
+			h.AssertEq(t, projectConfig.IsTrustedBuilder("other/builder"), false)
+		})
+
+		it("keeps the buildpack registry", func() {
+			subject.BuildpackRegistry = "https://some.example.com/index.json"
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(projectDir, ".pack", "config.toml"), []byte(`
+buildpack-registry = "https://project.example.com/index.json"
+`), 0666))
+
+			projectConfig, err := subject.ForProject(projectDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, projectConfig.BuildpackRegistry, "https://some.example.com/index.json")
+		})
+
+		it("cannot be saved", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(projectDir, ".pack", "config.toml"), []byte(`
+default-builder-image = "project/builder"
+`), 0666))
+
+			projectConfig, err := subject.ForProject(projectDir)
+			h.AssertNil(t, err)
+			h.AssertError(t, projectConfig.SetDefaultBuilder("other/builder"), "cannot be changed")
+			h.AssertError(t, projectConfig.TrustBuilder("project/builder"), "cannot be changed")
+
+			reloadedConfig, err := config.New(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, reloadedConfig.DefaultBuilder, "some/builder")
+			h.AssertEq(t, reloadedConfig.TrustedBuilders, []string{"other/builder", "some/builder"})
+		})
+
+		it("keeps the insecure registries from the project", func() {
+			subject.InsecureRegistries = []string{"registry.internal:5000"}
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(projectDir, ".pack", "config.toml"), []byte(`
+insecure-registries = ["registry.example.com"]
+`), 0666))
+
+			projectConfig, err := subject.ForProject(projectDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, projectConfig.InsecureRegistries, []string{"registry.internal:5000"})
+		})
+
+		it("fails when the project config is invalid", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(projectDir, ".pack", "config.toml"), []byte(`default-builder-image = [`), 0666))
+
+			_, err := subject.ForProject(projectDir)
+			h.AssertNotNil(t, err)
+		})
+	})
+
+	when("Config#RemoveRunImageMirrors", func() {
+		var subject *config.Config
+
+		it.Before(func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "config.toml"), []byte(`
+[[run-images]]
+  image = "some/run-image"
+  mirrors = ["some/run", "some.registry/some/run"]
+`), 0666))
+			var err error
+			subject, err = config.New(tmpDir)
+			h.AssertNil(t, err)
+		})
+
+		it("removes the given mirrors", func() {
+			h.AssertNil(t, subject.RemoveRunImageMirrors("some/run-image", "some/run"))
+
+			reloadedConfig, err := config.New(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, reloadedConfig.GetRunImage("some/run-image").Mirrors, []string{"some.registry/some/run"})
+		})
+
+		it("removes the run image when no mirrors are given", func() {
+			h.AssertNil(t, subject.RemoveRunImageMirrors("some/run-image"))
+
+			reloadedConfig, err := config.New(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertNil(t, reloadedConfig.GetRunImage("some/run-image"))
+		})
+
+		it("removes the run image when its last mirrors are removed", func() {
+			h.AssertNil(t, subject.RemoveRunImageMirrors("some/run-image", "some/run", "some.registry/some/run"))
+
+			reloadedConfig, err := config.New(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertNil(t, reloadedConfig.GetRunImage("some/run-image"))
+		})
+
+		it("returns an error when the run image has no mirrors", func() {
+			h.AssertError(t, subject.RemoveRunImageMirrors("other/run-image"), "run image 'other/run-image' has no mirrors configured")
+		})
+	})
+
 	when("ImageByRegistry", func() {
 		var images []string
 		it.Before(func() {
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProjectDir is the directory of a project holding its config.toml, which overrides the
+// configuration in the pack home directory for builds of the project
+const ProjectDir = ".pack"
+
+// ForProject returns the configuration of builds of the project in dir, in which the default
+// builder and the mirrors of each run image set in the project's .pack/config.toml replace those
+// of c. It returns c itself when the project has no such file. As projects are checked out from
+// anywhere, their configuration cannot widen what c grants: trusted builders set by the project
+// only narrow those of c to the builders trusted by both, and the buildpack registry and insecure
+// registries of c are kept, as the registry provides the buildpacks run with the app and insecure
+// registries would have registry credentials sent over HTTP. The returned configuration cannot be
+// changed, as saving it would copy the project's values to the pack home directory.
+func (c *Config) ForProject(dir string) (*Config, error) {
+	project := &Config{}
+	if _, err := toml.DecodeFile(filepath.Join(dir, ProjectDir, "config.toml"), project); err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+
+	config := &Config{
+		RunImages:          append([]RunImage{}, c.RunImages...),
+		DefaultBuilder:     c.DefaultBuilder,
+		TrustedBuilders:    c.TrustedBuilders,
+		BuildpackRegistry:  c.BuildpackRegistry,
+		InsecureRegistries: c.InsecureRegistries,
+		configPath:         c.configPath,
+		project:            dir,
+	}
+	if project.DefaultBuilder != "" {
+		config.DefaultBuilder = project.DefaultBuilder
+	}
+	if len(project.TrustedBuilders) > 0 {
+		config.TrustedBuilders = nil
+		for _, builder := range project.TrustedBuilders {
+			if c.IsTrustedBuilder(builder) {
+				config.TrustedBuilders = append(config.TrustedBuilders, builder)
+			}
+		}
+	}
+	for _, runImage := range project.RunImages {
+		if existing := config.GetRunImage(runImage.Image); existing != nil {
+			existing.Mirrors = runImage.Mirrors
+		} else {
+			config.RunImages = append(config.RunImages, runImage)
+		}
+	}
+	return config, nil
+}
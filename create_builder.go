@@ -23,14 +23,21 @@ import (
 )
 
 type BuilderConfig struct {
+	Description     string
 	Buildpacks      []buildpack.Buildpack
 	Groups          []lifecycle.BuildpackGroup
 	Repo            lcimg.Image
 	BuilderDir      string // original location of builder.toml, used for interpreting relative paths in buildpack URIs
 	RunImage        string
 	RunImageMirrors []string
+	// LifecycleDir holds the lifecycle binaries replacing those of the build image, when set
+	LifecycleDir     string
+	LifecycleVersion string
 }
 
+// lifecycleURI is the release of a lifecycle version, for a builder.toml that gives no lifecycle uri
+const lifecycleURI = "https://github.com/buildpack/lifecycle/releases/download/v%[1]s/lifecycle-v%[1]s+linux.x86-64.tgz"
+
 type BuilderFactory struct {
 	Logger           *logging.Logger
 	Config           *config.Config
@@ -60,6 +67,7 @@ func (f *BuilderFactory) BuilderConfigFromFlags(ctx context.Context, flags Creat
 	}
 
 	baseImage := builderTOML.Stack.BuildImage
+	builderConfig.Description = builderTOML.Description
 	builderConfig.RunImage = builderTOML.Stack.RunImage
 	builderConfig.RunImageMirrors = builderTOML.Stack.RunImageMirrors
 	if flags.Publish {
@@ -85,6 +93,20 @@ func (f *BuilderFactory) BuilderConfigFromFlags(ctx context.Context, flags Creat
 		}
 		builderConfig.Buildpacks = append(builderConfig.Buildpacks, fetchedBuildpack)
 	}
+
+	if lc := builderTOML.Lifecycle; lc.Version != "" || lc.URI != "" {
+		uri := lc.URI
+		if uri == "" {
+			uri = fmt.Sprintf(lifecycleURI, lc.Version)
+		}
+		// the buildpack fetcher downloads and extracts any tgz, caching it like buildpacks
+		fetched, err := f.BuildpackFetcher.FetchBuildpack(builderConfig.BuilderDir, buildpack.Buildpack{ID: "lifecycle", URI: uri})
+		if err != nil {
+			return BuilderConfig{}, errors.Wrapf(err, "fetching lifecycle from %s", style.Symbol(uri))
+		}
+		builderConfig.LifecycleDir = fetched.Dir
+		builderConfig.LifecycleVersion = lc.Version
+	}
 	return builderConfig, nil
 }
 
@@ -95,6 +117,16 @@ func (f *BuilderFactory) Create(config BuilderConfig) error {
 	}
 	defer os.RemoveAll(tmpDir)
 
+	if config.LifecycleDir != "" {
+		lifecycleTar, err := f.lifecycleLayer(tmpDir, config.LifecycleDir)
+		if err != nil {
+			return fmt.Errorf(`failed to generate lifecycle layer: %s`, err)
+		}
+		if err := config.Repo.AddLayer(lifecycleTar); err != nil {
+			return fmt.Errorf(`failed append lifecycle layer to image: %s`, err)
+		}
+	}
+
 	orderTar, err := f.orderLayer(tmpDir, config.Groups)
 	if err != nil {
 		return fmt.Errorf(`failed to generate order.toml layer: %s`, err)
@@ -132,7 +164,8 @@ func (f *BuilderFactory) Create(config BuilderConfig) error {
 		groupsMetadata = append(groupsMetadata, builder.GroupMetadata{Buildpacks: groupBuildpacks})
 	}
 
-	jsonBytes, err := json.Marshal(&builder.Metadata{
+	metadata := &builder.Metadata{
+		Description: config.Description,
 		Stack: stack.Metadata{
 			RunImage: stack.RunImageMetadata{
 				Image:   config.RunImage,
@@ -141,7 +174,11 @@ func (f *BuilderFactory) Create(config BuilderConfig) error {
 		},
 		Buildpacks: buildpacksMetadata,
 		Groups:     groupsMetadata,
-	})
+	}
+	if config.LifecycleVersion != "" {
+		metadata.Lifecycle = &builder.LifecycleMetadata{Version: config.LifecycleVersion}
+	}
+	jsonBytes, err := json.Marshal(metadata)
 	if err != nil {
 		return fmt.Errorf(`failed marshal builder image metadata: %s`, err)
 	}
@@ -169,6 +206,25 @@ func (f *BuilderFactory) Create(config BuilderConfig) error {
 	return nil
 }
 
+// lifecycleLayer creates a tar of the lifecycle binaries in dir, or in its lifecycle directory as
+// found in lifecycle releases, at /lifecycle
+func (f *BuilderFactory) lifecycleLayer(dest, dir string) (layerTar string, err error) {
+	if fi, err := os.Stat(filepath.Join(dir, "lifecycle")); err == nil && fi.IsDir() {
+		dir = filepath.Join(dir, "lifecycle")
+	}
+	for _, phase := range []string{"detector", "analyzer", "builder", "exporter"} {
+		if _, err := os.Stat(filepath.Join(dir, phase)); err != nil {
+			return "", errors.Wrapf(err, "lifecycle is missing %s", style.Symbol(phase))
+		}
+	}
+
+	layerTar = filepath.Join(dest, "lifecycle.tar")
+	if err := archive.CreateTar(layerTar, dir, "/lifecycle", 0, 0); err != nil {
+		return "", err
+	}
+	return layerTar, nil
+}
+
 type order struct {
 	Groups []lifecycle.BuildpackGroup `toml:"groups"`
 }
@@ -247,24 +303,22 @@ func (f *BuilderFactory) buildpackLayer(dest string, buildpack *buildpack.Buildp
 	if buildpack.ID != bp.ID {
 		return "", fmt.Errorf("buildpack IDs did not match: %s != %s", buildpack.ID, bp.ID)
 	}
-	if bp.Version == "" {
-		return "", fmt.Errorf("buildpack.toml must provide version: %s", filepath.Join(buildpack.Dir, "buildpack.toml"))
-	}
-
 	buildpack.Version = bp.Version
 	tarFile := filepath.Join(dest, fmt.Sprintf("%s.%s.tar", buildpack.EscapedID(), bp.Version))
+	f.Logger.Subsystem(logging.SubsystemFS).Debug("Creating tar of buildpack directory %s at %s", dir, tarFile)
 	if err := archive.CreateTar(tarFile, dir, filepath.Join("/buildpacks", buildpack.EscapedID(), bp.Version), 0, 0); err != nil {
 		return "", err
 	}
 	return tarFile, err
 }
 
-func (f *BuilderFactory) buildpackData(buildpack buildpack.Buildpack, dir string) (*BuildpackData, error) {
-	data := &BuildpackData{}
-	_, err := toml.DecodeFile(filepath.Join(dir, "buildpack.toml"), &data)
+func (f *BuilderFactory) buildpackData(bp buildpack.Buildpack, dir string) (*BuildpackData, error) {
+	descriptor, err := buildpack.ReadDescriptor(dir)
 	if err != nil {
 		return nil, errors.Wrapf(err, "reading buildpack.toml from buildpack: %s", dir)
 	}
+	data := &BuildpackData{}
+	data.BP.ID, data.BP.Version = descriptor.Buildpack.ID, descriptor.Buildpack.Version
 	return data, nil
 }
 
@@ -91,6 +91,7 @@ func testBuilderFactory(t *testing.T, when spec.G, it spec.S) {
 				checkBuildpacks(t, cfg.Buildpacks)
 				checkGroups(t, cfg.Groups)
 				h.AssertEq(t, cfg.BuilderDir, "testdata")
+				h.AssertEq(t, cfg.Description, "some description")
 				h.AssertEq(t, cfg.RunImage, "some/run")
 				h.AssertEq(t, cfg.RunImageMirrors, []string{"gcr.io/some/run2"})
 			})
@@ -203,6 +204,39 @@ build-image = "packs/build:v3alpha2"
 				})
 				h.AssertError(t, err, "stack.run-image is required")
 			})
+
+			it("fetches the lifecycle in the builder.toml", func() {
+				mockBaseImage := mocks.NewMockImage(mockController)
+				mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/build", gomock.Any()).Return(mockBaseImage, nil)
+				mockBaseImage.EXPECT().Rename("some/image")
+
+				lifecycleDir, err := filepath.Abs(filepath.Join("testdata", "lifecycle"))
+				h.AssertNil(t, err)
+				file, err := ioutil.TempFile("", "builder.toml")
+				h.AssertNil(t, err)
+				defer os.Remove(file.Name())
+
+				_, err = file.WriteString(fmt.Sprintf(`
+[stack]
+id = "some.id"
+build-image = "some/build"
+run-image = "some/run"
+
+[lifecycle]
+version = "0.3.0"
+uri = %q
+`, lifecycleDir))
+				h.AssertNil(t, err)
+				file.Close()
+
+				config, err := factory.BuilderConfigFromFlags(context.TODO(), pack.CreateBuilderFlags{
+					RepoName:        "some/image",
+					BuilderTomlPath: file.Name(),
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, config.LifecycleDir, lifecycleDir)
+				h.AssertEq(t, config.LifecycleVersion, "0.3.0")
+			})
 		})
 
 		when("#Create", func() {
@@ -287,6 +321,34 @@ build-image = "packs/build:v3alpha2"
 				})
 			})
 
+			it("stores the description in the builder label", func() {
+				builderConfig.Description = "some description"
+				h.AssertNil(t, factory.Create(builderConfig))
+				h.AssertContains(t, labels["io.buildpacks.builder.metadata"], `{"description":"some description",`)
+			})
+
+			when("builder config contains a lifecycle", func() {
+				it.Before(func() {
+					builderConfig.LifecycleDir = filepath.Join("testdata", "lifecycle")
+					builderConfig.LifecycleVersion = "0.3.0"
+				})
+
+				it("adds the lifecycle binaries at /lifecycle", func() {
+					h.AssertNil(t, factory.Create(builderConfig))
+					buf, exists := savedLayers["lifecycle.tar"]
+					h.AssertEq(t, exists, true)
+
+					contents, err := h.UntarSingleFile(buf, "/lifecycle/detector")
+					h.AssertNil(t, err)
+					h.AssertContains(t, string(contents), "#!/usr/bin/env bash")
+				})
+
+				it("stores the lifecycle version in the builder label", func() {
+					h.AssertNil(t, factory.Create(builderConfig))
+					h.AssertContains(t, labels["io.buildpacks.builder.metadata"], `"lifecycle":{"version":"0.3.0"}`)
+				})
+			})
+
 			when("builder config contains groups", func() {
 				it.Before(func() {
 					builderConfig.Groups = []lifecycle.BuildpackGroup{{Buildpacks: []*lifecycle.Buildpack{{ID: "bpId", Version: "bpVersion"}}}}
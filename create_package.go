@@ -0,0 +1,106 @@
+package pack
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/buildpack"
+	"github.com/buildpack/pack/buildpackage"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/keychain"
+	"github.com/buildpack/pack/style"
+)
+
+// PackageFactory packages buildpacks into buildpackage images, which distribute buildpacks
+// independently of builders
+type PackageFactory struct {
+	Logger           Logger
+	Docker           Docker
+	BuildpackFetcher BuildpackFetcher
+	// Config holds the insecure registries that published packages are pushed to over HTTP
+	Config *config.Config
+}
+
+type CreatePackageFlags struct {
+	RepoName        string
+	PackageTomlPath string
+	Publish         bool
+}
+
+// Create packages the buildpacks of the package.toml in flags, then publishes the buildpackage
+// image or loads it into the docker daemon
+func (f *PackageFactory) Create(ctx context.Context, flags CreatePackageFlags) (*buildpackage.Metadata, error) {
+	tag, err := name.NewTag(flags.RepoName, name.WeakValidation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image name %s", style.Symbol(flags.RepoName))
+	}
+	config, err := buildpackage.ReadConfig(flags.PackageTomlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	packageDir := filepath.Dir(flags.PackageTomlPath)
+	var buildpacks []buildpack.Buildpack
+	for _, bp := range config.Buildpacks {
+		fetched, err := f.BuildpackFetcher.FetchBuildpack(packageDir, bp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetching buildpack %s", style.Symbol(bp.URI))
+		}
+		buildpacks = append(buildpacks, fetched)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "create-package")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	img, metadata, err := buildpackage.NewImage(tmpDir, buildpacks, config.Stacks)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags.Publish {
+		f.Logger.Verbose("Publishing buildpackage %s", style.Symbol(tag.String()))
+		auth, err := keychain.Default.Resolve(tag.Context().Registry)
+		if err != nil {
+			return nil, err
+		}
+		if err := remote.Write(tag, img, auth, registryTransport(f.Config)); err != nil {
+			return nil, errors.Wrapf(err, "pushing image %s", style.Symbol(tag.String()))
+		}
+		return metadata, nil
+	}
+
+	f.Logger.Verbose("Loading buildpackage %s into the docker daemon", style.Symbol(tag.String()))
+	if err := f.loadIntoDaemon(ctx, tag, img); err != nil {
+		return nil, errors.Wrapf(err, "loading image %s", style.Symbol(tag.String()))
+	}
+	return metadata, nil
+}
+
+// loadIntoDaemon streams img to the docker daemon as a `docker save` archive
+func (f *PackageFactory) loadIntoDaemon(ctx context.Context, tag name.Tag, img v1.Image) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(tarball.Write(tag, img, pw))
+	}()
+	defer pr.Close()
+
+	res, err := f.Docker.ImageLoad(ctx, pr, true)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return jsonmessage.DisplayJSONMessagesStream(res.Body, ioutil.Discard, 0, false, nil)
+}
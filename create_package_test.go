@@ -0,0 +1,154 @@
+package pack_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/buildpack"
+	"github.com/buildpack/pack/buildpackage"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestPackageFactory(t *testing.T) {
+	spec.Run(t, "package_factory", testPackageFactory, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testPackageFactory(t *testing.T, when spec.G, it spec.S) {
+	when("#PackageFactory", func() {
+		var (
+			mockController *gomock.Controller
+			mockDocker     *mocks.MockDocker
+			factory        pack.PackageFactory
+			tmpDir         string
+			outBuf         bytes.Buffer
+			errBuf         bytes.Buffer
+		)
+
+		writePackageToml := func(uri string) string {
+			path := filepath.Join(tmpDir, "package.toml")
+			content := fmt.Sprintf("[[buildpacks]]\n  uri = %q\n\n[[stacks]]\n  id = \"some.stack.id\"\n", uri)
+			h.AssertNil(t, ioutil.WriteFile(path, []byte(content), 0644))
+			return path
+		}
+
+		it.Before(func() {
+			mockController = gomock.NewController(t)
+			mockDocker = mocks.NewMockDocker(mockController)
+
+			var err error
+			tmpDir, err = ioutil.TempDir("", "create-package-test")
+			h.AssertNil(t, err)
+
+			logger := logging.NewLogger(&outBuf, &errBuf, true, false)
+			factory = pack.PackageFactory{
+				Logger:           logger,
+				Docker:           mockDocker,
+				BuildpackFetcher: buildpack.NewFetcher(logger, tmpDir),
+			}
+		})
+
+		it.After(func() {
+			mockController.Finish()
+			os.RemoveAll(tmpDir)
+		})
+
+		when("#Create", func() {
+			var packageToml string
+
+			it.Before(func() {
+				buildpackDir, err := filepath.Abs(filepath.Join("testdata", "buildpack"))
+				h.AssertNil(t, err)
+				packageToml = writePackageToml(buildpackDir)
+			})
+
+			it("loads the buildpackage image into the docker daemon", func() {
+				var files []string
+				mockDocker.EXPECT().ImageLoad(gomock.Any(), gomock.Any(), true).DoAndReturn(
+					func(_ context.Context, r io.Reader, _ bool) (types.ImageLoadResponse, error) {
+						tr := tar.NewReader(r)
+						for {
+							hdr, err := tr.Next()
+							if err == io.EOF {
+								break
+							}
+							h.AssertNil(t, err)
+							files = append(files, hdr.Name)
+							if hdr.Name == "manifest.json" {
+								manifest, err := ioutil.ReadAll(tr)
+								h.AssertNil(t, err)
+								h.AssertContains(t, string(manifest), "index.docker.io/some/package:latest")
+							}
+						}
+						return types.ImageLoadResponse{Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+					})
+
+				metadata, err := factory.Create(context.TODO(), pack.CreatePackageFlags{
+					RepoName:        "some/package",
+					PackageTomlPath: packageToml,
+				})
+				h.AssertNil(t, err)
+				h.AssertEq(t, metadata.Buildpacks, []buildpackage.BuildpackMetadata{
+					{ID: "some-buildpack-id", Version: "some-buildpack-version"},
+				})
+				h.AssertEq(t, metadata.Stacks, []buildpackage.Stack{{ID: "some.stack.id"}})
+				h.AssertContains(t, strings.Join(files, "\n"), "manifest.json")
+			})
+
+			it("fails when the image cannot be loaded into the docker daemon", func() {
+				mockDocker.EXPECT().ImageLoad(gomock.Any(), gomock.Any(), true).DoAndReturn(
+					func(_ context.Context, r io.Reader, _ bool) (types.ImageLoadResponse, error) {
+						return types.ImageLoadResponse{}, errors.New("some load error")
+					})
+
+				_, err := factory.Create(context.TODO(), pack.CreatePackageFlags{
+					RepoName:        "some/package",
+					PackageTomlPath: packageToml,
+				})
+				h.AssertError(t, err, "loading image 'index.docker.io/some/package:latest': some load error")
+			})
+
+			it("fails for invalid image names", func() {
+				_, err := factory.Create(context.TODO(), pack.CreatePackageFlags{
+					RepoName:        "Some/Package",
+					PackageTomlPath: packageToml,
+				})
+				h.AssertError(t, err, "invalid image name 'Some/Package'")
+			})
+
+			it("fails when the package config cannot be read", func() {
+				_, err := factory.Create(context.TODO(), pack.CreatePackageFlags{
+					RepoName:        "some/package",
+					PackageTomlPath: filepath.Join(tmpDir, "missing.toml"),
+				})
+				h.AssertError(t, err, "failed to decode package config")
+			})
+
+			it("fails when a buildpack cannot be read", func() {
+				packageToml = writePackageToml(filepath.Join(tmpDir, "missing-buildpack"))
+
+				_, err := factory.Create(context.TODO(), pack.CreatePackageFlags{
+					RepoName:        "some/package",
+					PackageTomlPath: packageToml,
+				})
+				h.AssertError(t, err, "reading buildpack.toml from buildpack")
+			})
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package pack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/archive"
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/git"
+	"github.com/buildpack/pack/style"
+)
+
+// BuildWithRunner builds an app image with runner, such as a nerdctl.Runner, rather than in
+// the docker daemon, see build.RunDaemonless. The build publishes the image, as there is no daemon
+// to export it to, and does not cache layers. Every phase runs in the builder image, and the
+// phases publishing the image are given registry credentials, so the builder must be trusted.
+func (c *Client) BuildWithRunner(ctx context.Context, flags BuildFlags, runner build.ContainerRunner) error {
+	if !flags.Publish {
+		return errors.New("builds without a docker daemon must publish the image")
+	}
+	if len(flags.Env) > 0 || flags.EnvFile != "" || len(flags.Buildpacks) > 0 {
+		return errors.New("builds without a docker daemon do not support build-time environment variables or buildpacks")
+	}
+	if flags.AppDir == AppDirStdin {
+		return errors.New("builds without a docker daemon cannot read the app from stdin")
+	}
+	if _, _, ok := git.ParseURL(flags.AppDir); ok {
+		return errors.Errorf("builds without a docker daemon cannot clone app source %s, which is a Git repository", style.Symbol(flags.AppDir))
+	}
+	if err := checkOutput(&flags); err != nil {
+		return err
+	}
+
+	cfg, err := c.projectConfig(flags.AppDir)
+	if err != nil {
+		return err
+	}
+	builderName := flags.Builder
+	if builderName == "" {
+		builderName = cfg.DefaultBuilder
+	}
+	if !cfg.IsTrustedBuilder(builderName) {
+		return errors.Errorf("builds without a docker daemon run every phase in the builder image, so builder %s must be trusted. Trust it with 'pack trust-builder %s'.", style.Symbol(builderName), builderName)
+	}
+	img, err := c.fetcher.FetchRemoteImage(builderName)
+	if err != nil {
+		return err
+	}
+	if found, err := img.Found(); err != nil {
+		return errors.Wrapf(err, "invalid builder %s", style.Symbol(builderName))
+	} else if !found {
+		return fmt.Errorf("remote builder %s does not exist", style.Symbol(builderName))
+	}
+
+	bldr := builder.NewBuilder(img, cfg)
+	lifecycleVersion, err := builderLifecycleVersion(bldr, builderName)
+	if err != nil {
+		return err
+	}
+	if len(flags.AdditionalTags) > 0 && !build.SupportsExportTags(lifecycleVersion) {
+		return errors.Errorf("the lifecycle of builder %s cannot export additional tags without a docker daemon", style.Symbol(builderName))
+	}
+	runImage := flags.RunImage
+	if runImage == "" {
+		if runImage, err = bldr.GetRunImageByRepoName(flags.RepoName); err != nil {
+			return err
+		}
+	}
+	if err := checkInsecureRegistries(cfg, append([]string{flags.RepoName, runImage}, flags.AdditionalTags...)...); err != nil {
+		return err
+	}
+	uid, gid, err := builderUidGid(img)
+	if err != nil {
+		return err
+	}
+
+	appDir := flags.AppDir
+	if appDir == "" {
+		if appDir, err = os.Getwd(); err != nil {
+			return err
+		}
+	}
+	if appDir, err = filepath.Abs(appDir); err != nil {
+		return err
+	}
+	source, errChan := archive.CreateTarReader(appDir, ".", uid, gid, archive.WithSymlinks(flags.AppSymlinks))
+	defer source.Close()
+	err = build.RunDaemonless(ctx, runner, build.DaemonlessConfig{
+		Builder:          builderName,
+		RunImage:         runImage,
+		RepoName:         flags.RepoName,
+		AdditionalTags:   flags.AdditionalTags,
+		LifecycleVersion: lifecycleVersion,
+		UID:              uid,
+		GID:              gid,
+		Network:          flags.Network,
+		Logger:           c.logger,
+	}, source, c.logger.RawWriter())
+	if err != nil {
+		return err
+	}
+	source.Close()
+	if err := <-errChan; err != nil && err != io.ErrClosedPipe {
+		return errors.Wrapf(err, "reading app dir %s", style.Symbol(appDir))
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Package descriptor decodes the files describing buildpacks and builds, such as buildpack.toml,
+// order.toml and env files, reporting each problem with the file, key and line it is at
+package descriptor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ValidationError is a problem with a descriptor
+type ValidationError struct {
+	File string
+	// Key is the dotted key of the value at fault, such as 'buildpack.id' or 'stacks[0].id', or empty
+	// when the problem is with the file as a whole
+	Key string
+	// Line is the line of the value at fault, or of its table when the value is missing, or zero
+	// when it is unknown
+	Line    int
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	s := e.File
+	if e.Line > 0 {
+		s += ":" + strconv.Itoa(e.Line)
+	}
+	if e.Key != "" {
+		s += ": " + e.Key
+	}
+	return s + ": " + e.Message
+}
+
+// ValidationErrors are all the problems found with a descriptor, in the order they were found
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	var lines []string
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Err returns the errors, or nil when there are none
+func (errs ValidationErrors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// TOML is a decoded TOML descriptor, which locates its keys for errors
+type TOML struct {
+	File string
+	meta toml.MetaData
+	// lines are the lines of the keys and tables of the file, by key both with the indexes of arrays
+	// of tables, such as 'stacks[1].id', and without them, such as 'stacks.id', for the first one
+	lines map[string]int
+}
+
+// parseErrorPattern matches the syntax errors of the toml package, such as "Near line 3 (last key
+// parsed 'buildpack.id'): expected value but found '\n' instead"
+var parseErrorPattern = regexp.MustCompile(`^Near line (\d+) \(last key parsed '([^']*)'\): (.*)$`)
+
+// DecodeTOML decodes data, the contents of file, into v. Syntax errors and values of the wrong type
+// are returned as a *ValidationError.
+func DecodeTOML(file string, data []byte, v interface{}) (*TOML, error) {
+	meta, err := toml.Decode(string(data), v)
+	if err != nil {
+		verr := &ValidationError{File: file, Message: strings.TrimPrefix(err.Error(), "toml: ")}
+		if m := parseErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+			verr.Line, _ = strconv.Atoi(m[1])
+			verr.Key, verr.Message = m[2], m[3]
+		}
+		return nil, verr
+	}
+	return &TOML{File: file, meta: meta, lines: keyLines(string(data))}, nil
+}
+
+// Errorf returns a ValidationError at key, which may be a key missing from the file
+func (t *TOML) Errorf(key, format string, a ...interface{}) *ValidationError {
+	return &ValidationError{File: t.File, Key: key, Line: t.Line(key), Message: fmt.Sprintf(format, a...)}
+}
+
+// Line returns the line of key or, when the key is missing, of the closest table holding it, or
+// zero when none is in the file
+func (t *TOML) Line(key string) int {
+	for key != "" {
+		if line, ok := t.lines[key]; ok {
+			return line
+		}
+		key = key[:strings.LastIndexAny(key, ".[")+1]
+		key = strings.TrimRight(key, ".[")
+	}
+	return 0
+}
+
+// Undecoded returns an error for each key of the file that was not decoded, which is most often a
+// misspelled key, apart from the keys within the given tables, which may hold anything
+func (t *TOML) Undecoded(freeform ...string) ValidationErrors {
+	var errs ValidationErrors
+	for _, key := range t.meta.Undecoded() {
+		if !within(key, freeform) {
+			errs = append(errs, t.Errorf(key.String(), "unknown key"))
+		}
+	}
+	return errs
+}
+
+func within(key toml.Key, tables []string) bool {
+	for _, table := range tables {
+		if key[0] == table {
+			return true
+		}
+	}
+	return false
+}
+
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// keyLines returns the lines of the keys and tables of a TOML file. It reads table headers and the
+// first line of each key and value, which is enough to locate the keys of descriptors.
+func keyLines(data string) map[string]int {
+	var (
+		lines = map[string]int{}
+		// counts are the number of tables of each array of tables seen so far
+		counts = map[string]int{}
+		table  string
+	)
+	add := func(key string, line int) {
+		for _, k := range []string{key, indexPattern.ReplaceAllString(key, "")} {
+			if _, ok := lines[k]; !ok {
+				lines[k] = line
+			}
+		}
+	}
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "[[") && strings.Contains(line, "]]"):
+			key := resolveTable(line[2:strings.Index(line, "]]")], counts)
+			table = fmt.Sprintf("%s[%d]", key, counts[key])
+			counts[key]++
+			add(table, i+1)
+		case strings.HasPrefix(line, "[") && strings.Contains(line, "]"):
+			table = resolveTable(line[1:strings.Index(line, "]")], counts)
+			add(table, i+1)
+		case strings.HasPrefix(line, "#"):
+		case strings.Contains(line, "="):
+			key := strings.Trim(strings.TrimSpace(line[:strings.Index(line, "=")]), `"'`)
+			if table != "" {
+				key = table + "." + key
+			}
+			add(key, i+1)
+		}
+	}
+	return lines
+}
+
+// resolveTable returns the key of the table named name, with the indexes of the current tables of
+// the arrays of tables it is within
+func resolveTable(name string, counts map[string]int) string {
+	parts := strings.Split(strings.TrimSpace(name), ".")
+	var key string
+	for i, part := range parts {
+		if i > 0 {
+			key += "."
+		}
+		key += strings.Trim(strings.TrimSpace(part), `"'`)
+		if n := counts[key]; n > 0 && i < len(parts)-1 {
+			key = fmt.Sprintf("%s[%d]", key, n-1)
+		}
+	}
+	return key
+}
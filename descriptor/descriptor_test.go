@@ -0,0 +1,83 @@
+package descriptor_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/descriptor"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestDescriptor(t *testing.T) {
+	spec.Run(t, "Descriptor", testDescriptor, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+type someDescriptor struct {
+	Buildpack struct {
+		ID string `toml:"id"`
+	} `toml:"buildpack"`
+	Stacks []struct {
+		ID string `toml:"id"`
+	} `toml:"stacks"`
+	Metadata map[string]interface{} `toml:"metadata"`
+}
+
+func testDescriptor(t *testing.T, when spec.G, it spec.S) {
+	when("#DecodeTOML", func() {
+		it("returns syntax errors with their line", func() {
+			_, err := descriptor.DecodeTOML("some.toml", []byte("[buildpack]\nid = \n"), &someDescriptor{})
+			verr, ok := err.(*descriptor.ValidationError)
+			if !ok {
+				t.Fatalf("expected a *descriptor.ValidationError, got %#v", err)
+			}
+			h.AssertEq(t, verr.File, "some.toml")
+			h.AssertEq(t, verr.Line, 2)
+			h.AssertError(t, err, "some.toml:2: ")
+		})
+
+		it("locates keys, with the indexes of arrays of tables", func() {
+			toml, err := descriptor.DecodeTOML("some.toml", []byte(`
+[buildpack]
+id = "some-id"
+
+[[stacks]]
+id = "some-stack"
+
+[[stacks]]
+mixins = []
+`), &someDescriptor{})
+			h.AssertNil(t, err)
+			h.AssertEq(t, toml.Line("buildpack.id"), 3)
+			h.AssertEq(t, toml.Line("stacks[0].id"), 6)
+			h.AssertEq(t, toml.Line("stacks[1]"), 8)
+			h.AssertEq(t, toml.Line("buildpack.version"), 2)
+			h.AssertEq(t, toml.Line("missing"), 0)
+			h.AssertError(t, toml.Errorf("stacks[1].id", "is required"), "some.toml:8: stacks[1].id: is required")
+		})
+	})
+
+	when("#Undecoded", func() {
+		it("returns the unknown keys outside of the freeform tables", func() {
+			toml, err := descriptor.DecodeTOML("some.toml", []byte(`
+[buildpack]
+id = "some-id"
+idd = "typo"
+
+[metadata]
+anything = "goes"
+`), &someDescriptor{})
+			h.AssertNil(t, err)
+			errs := toml.Undecoded("metadata")
+			h.AssertEq(t, len(errs), 1)
+			h.AssertError(t, errs.Err(), "some.toml:4: buildpack.idd: unknown key")
+		})
+	})
+
+	when("ValidationErrors#Err", func() {
+		it("is nil without errors", func() {
+			h.AssertNil(t, descriptor.ValidationErrors{}.Err())
+		})
+	})
+}
@@ -0,0 +1,47 @@
+package pack
+
+import (
+	"context"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/style"
+)
+
+// DetectOnly runs only the detector, returning the buildpacks that a build of the app would run, in
+// order, without building or exporting an image
+func (b *BuildConfig) DetectOnly(ctx context.Context) ([]BuildpackRef, error) {
+	defer b.cleanup()
+	lifecycle, err := build.NewLifecycle(b.LifecycleConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := lifecycle.Cleanup(); err != nil {
+			b.Logger.Verbose("Failed to clean up build: %s", err)
+		}
+	}()
+
+	b.Logger.Verbose(style.Step("DETECTING"))
+	if err := b.runPhase(ctx, "detector", lifecycle, b.detect); err != nil {
+		return nil, err
+	}
+	group, err := lifecycle.BuildpackGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var buildpacks []BuildpackRef
+	for _, bp := range group.Buildpacks {
+		buildpacks = append(buildpacks, BuildpackRef{ID: bp.ID, Version: bp.Version})
+	}
+	return buildpacks, nil
+}
+
+// Detect returns the buildpacks that a build of the app directory in flags would run, see
+// BuildConfig.DetectOnly
+func (c *Client) Detect(ctx context.Context, flags BuildFlags, ops ...func(*BuildFactory)) ([]BuildpackRef, error) {
+	b, err := c.buildConfig(ctx, &flags, ops)
+	if err != nil {
+		return nil, err
+	}
+	return b.DetectOnly(ctx)
+}
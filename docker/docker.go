@@ -5,18 +5,18 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/buildpack/lifecycle/image/auth"
 	dockertypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	dockercli "github.com/docker/docker/client"
-	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/stdcopy"
-	"github.com/docker/docker/pkg/term"
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/pkg/errors"
 
+	"github.com/buildpack/pack/keychain"
 	"github.com/buildpack/pack/style"
 )
 
@@ -24,12 +24,60 @@ type Client struct {
 	*dockercli.Client
 }
 
-func New() (*Client, error) {
-	cli, err := dockercli.NewClientWithOpts(dockercli.FromEnv, dockercli.WithVersion("1.38"))
+// New creates a client of the daemon at DOCKER_HOST, which may be a unix socket, a tcp:// host,
+// using TLS as configured by DOCKER_TLS_VERIFY and DOCKER_CERT_PATH, or an ssh:// host. Without
+// DOCKER_HOST, the default docker socket is used, or else the socket of podman.
+func New(ops ...func(*Client)) (*Client, error) {
+	hostOps, err := hostOptions()
+	if err != nil {
+		return nil, err
+	}
+	cliOps := append([]func(*dockercli.Client) error{dockercli.FromEnv}, hostOps...)
+	cli, err := dockercli.NewClientWithOpts(append(cliOps, dockercli.WithVersion("1.38"))...)
 	if err != nil {
 		return nil, errors.Wrap(err, "new docker client")
 	}
-	return &Client{cli}, nil
+	c := &Client{Client: cli}
+	for _, op := range ops {
+		op(c)
+	}
+	return c, nil
+}
+
+// Logger receives debug messages about requests to the docker daemon
+type Logger interface {
+	Debug(format string, a ...interface{})
+}
+
+// WithLogger logs each request to the docker daemon as a debug message of the given logger
+func WithLogger(logger Logger) func(*Client) {
+	return func(c *Client) {
+		if logger == nil {
+			return
+		}
+		httpClient := c.HTTPClient()
+		transport := httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		httpClient.Transport = &loggingTransport{transport: transport, logger: logger}
+	}
+}
+
+type loggingTransport struct {
+	transport http.RoundTripper
+	logger    Logger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		t.logger.Debug("%s %s failed after %s: %s", req.Method, req.URL.Path, time.Since(start), err)
+		return nil, err
+	}
+	t.logger.Debug("%s %s: %s (%s)", req.Method, req.URL.Path, resp.Status, time.Since(start))
+	return resp, nil
 }
 
 func (d *Client) RunContainer(ctx context.Context, id string, stdout io.Writer, stderr io.Writer) error {
@@ -64,28 +112,30 @@ func (d *Client) RunContainer(ctx context.Context, id string, stdout io.Writer,
 	return <-copyErr
 }
 
-func (d *Client) PullImage(ctx context.Context, imageID string, stdout io.Writer) error {
+// PullImage pulls imageID, writing progress to stdout as DisplayPullProgress does. Options may set further pull options,
+// such as the platform to pull the image for.
+func (d *Client) PullImage(ctx context.Context, imageID string, stdout io.Writer, ops ...func(*dockertypes.ImagePullOptions)) error {
 	regAuth, err := d.registryAuth(imageID)
 	if err != nil {
 		return errors.Wrap(err, "auth for docker pull")
 	}
 
-	rc, err := d.Client.ImagePull(ctx, imageID, dockertypes.ImagePullOptions{
+	options := dockertypes.ImagePullOptions{
 		RegistryAuth: regAuth,
-	})
+	}
+	for _, op := range ops {
+		op(&options)
+	}
+	rc, err := d.Client.ImagePull(ctx, imageID, options)
 	if err != nil {
 		// Retry
-		rc, err = d.Client.ImagePull(ctx, imageID, dockertypes.ImagePullOptions{
-			RegistryAuth: regAuth,
-		})
+		rc, err = d.Client.ImagePull(ctx, imageID, options)
 		if err != nil {
 			return err
 		}
 	}
 
-	termFd, isTerm := term.GetFdInfo(stdout)
-	err = jsonmessage.DisplayJSONMessagesStream(rc, &colorizedWriter{stdout}, termFd, isTerm, nil)
-	if err != nil {
+	if err := DisplayPullProgress(rc, stdout); err != nil {
 		return err
 	}
 
@@ -94,7 +144,7 @@ func (d *Client) PullImage(ctx context.Context, imageID string, stdout io.Writer
 
 func (d *Client) registryAuth(ref string) (string, error) {
 	var regAuth string
-	_, a, err := auth.ReferenceForRepoName(authn.DefaultKeychain, ref)
+	_, a, err := auth.ReferenceForRepoName(keychain.Default, ref)
 	if err != nil {
 		return "", errors.Wrapf(err, "resolve auth for ref %s", ref)
 	}
@@ -0,0 +1,214 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buildpack/lifecycle/image"
+	dockercli "github.com/docker/docker/client"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// defaultSocket is the socket of the docker daemon used when DOCKER_HOST is unset
+const defaultSocket = "/var/run/docker.sock"
+
+// sshHost is the host of the requests to daemons reached over ssh, which go through the connection
+// to the daemon however they are addressed
+const sshHost = "http://docker"
+
+// hostOptions configures the client for the daemons that the environment options of the docker
+// client do not support:
+//   - ssh://[user@]host[:port] hosts, reached through 'docker system dial-stdio' on the host
+//   - tcp:// hosts with DOCKER_TLS_VERIFY set and the certificates in ~/.docker, as the docker CLI does
+//   - podman's rootful or rootless socket, when DOCKER_HOST is unset and there is no docker socket
+func hostOptions() ([]func(*dockercli.Client) error, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		if socket := podmanSocket(); socket != "" {
+			return []func(*dockercli.Client) error{dockercli.WithHost("unix://" + socket)}, nil
+		}
+		return nil, nil
+	}
+
+	hostURL, err := url.Parse(host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid DOCKER_HOST %s", style.Symbol(host))
+	}
+	switch hostURL.Scheme {
+	case "ssh":
+		if hostURL.Hostname() == "" {
+			return nil, fmt.Errorf("invalid DOCKER_HOST %s, must be of the form ssh://[user@]host[:port]", style.Symbol(host))
+		}
+		return []func(*dockercli.Client) error{
+			dockercli.WithHost(sshHost),
+			dockercli.WithDialContext(sshDialer(hostURL)),
+		}, nil
+	case "tcp":
+		if os.Getenv("DOCKER_TLS_VERIFY") != "" && os.Getenv("DOCKER_CERT_PATH") == "" {
+			certPath := filepath.Join(os.Getenv("HOME"), ".docker")
+			return []func(*dockercli.Client) error{dockercli.WithTLSClientConfig(
+				filepath.Join(certPath, "ca.pem"),
+				filepath.Join(certPath, "cert.pem"),
+				filepath.Join(certPath, "key.pem"),
+			)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// podmanSocket returns the socket of podman's docker compatible API when there is no docker socket,
+// preferring the socket of rootless podman
+func podmanSocket() string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	if _, err := os.Stat(defaultSocket); err == nil {
+		return ""
+	}
+	var sockets []string
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append(sockets, filepath.Join(dir, "podman", "podman.sock"))
+	}
+	sockets = append(sockets, "/run/podman/podman.sock")
+	for _, socket := range sockets {
+		if fi, err := os.Stat(socket); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			return socket
+		}
+	}
+	return ""
+}
+
+// SSHArgs returns the arguments of the ssh command connecting to the daemon of an ssh:// host
+func SSHArgs(hostURL *url.URL) []string {
+	var args []string
+	if user := hostURL.User.Username(); user != "" {
+		args = append(args, "-l", user)
+	}
+	if port := hostURL.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	return append(args, "--", hostURL.Hostname(), "docker", "system", "dial-stdio")
+}
+
+// sshDialer connects to the daemon of an ssh:// host through the standard input and output of an
+// ssh command. The command is not bound to the context of the dial, as the connection outlives it.
+func sshDialer(hostURL *url.URL) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		cmd := exec.Command("ssh", SSHArgs(hostURL)...)
+		conn := &commandConn{cmd: cmd}
+		var err error
+		if conn.stdin, err = cmd.StdinPipe(); err != nil {
+			return nil, err
+		}
+		if conn.stdout, err = cmd.StdoutPipe(); err != nil {
+			return nil, err
+		}
+		cmd.Stderr = &conn.stderr
+		if err := cmd.Start(); err != nil {
+			return nil, errors.Wrapf(err, "connecting to %s", style.Symbol(hostURL.String()))
+		}
+		return conn, nil
+	}
+}
+
+// commandConn is a connection through the standard input and output of a command
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr lockedBuffer
+}
+
+// lockedBuffer collects the standard error of a command, which is written while the connection is read
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (c *commandConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if err == io.EOF {
+		if stderr := strings.TrimSpace(c.stderr.String()); stderr != "" {
+			return n, fmt.Errorf("connection closed: %s", stderr)
+		}
+	}
+	return n, err
+}
+
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+func (c *commandConn) Close() error {
+	c.stdin.Close()
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+	c.cmd.Wait()
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr              { return commandAddr{} }
+func (c *commandConn) RemoteAddr() net.Addr             { return commandAddr{} }
+func (c *commandConn) SetDeadline(time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(time.Time) error { return nil }
+
+type commandAddr struct{}
+
+func (commandAddr) Network() string { return "command" }
+func (commandAddr) String() string  { return "command" }
+
+// IsRemote reports whether the daemon runs on another host, so that the directories of this host
+// cannot be bind mounted into its containers. Daemons reached over TCP are remote unless they
+// listen on a loopback address.
+func (c *Client) IsRemote() bool {
+	hostURL, err := url.Parse(c.DaemonHost())
+	if err != nil {
+		return true
+	}
+	switch hostURL.Scheme {
+	case "unix", "npipe":
+		return false
+	case "tcp":
+		if hostURL.Hostname() == "localhost" {
+			return false
+		}
+		ip := net.ParseIP(hostURL.Hostname())
+		return ip == nil || !ip.IsLoopback()
+	}
+	return true
+}
+
+// FactoryOption makes an image factory use the daemon of c, rather than a client of its own
+// configured from the environment, which cannot reach ssh hosts or podman sockets
+func (c *Client) FactoryOption() func(*image.Factory) {
+	return func(f *image.Factory) {
+		f.Docker = c.Client
+	}
+}
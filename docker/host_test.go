@@ -0,0 +1,98 @@
+package docker_test
+
+import (
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/docker"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestHost(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Host", testHost, spec.Report(report.Terminal{}))
+}
+
+func testHost(t *testing.T, when spec.G, it spec.S) {
+	when("#SSHArgs", func() {
+		it("connects to the daemon of the host through docker system dial-stdio", func() {
+			hostURL, err := url.Parse("ssh://some-user@some-host:2222")
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, docker.SSHArgs(hostURL), []string{
+				"-l", "some-user", "-p", "2222", "--", "some-host", "docker", "system", "dial-stdio",
+			})
+		})
+
+		it("leaves the user and port to the ssh config when they are not given", func() {
+			hostURL, err := url.Parse("ssh://some-host")
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, docker.SSHArgs(hostURL), []string{"--", "some-host", "docker", "system", "dial-stdio"})
+		})
+	})
+
+	when("#New", func() {
+		var dockerHost string
+
+		it.Before(func() {
+			dockerHost = os.Getenv("DOCKER_HOST")
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.Setenv("DOCKER_HOST", dockerHost))
+		})
+
+		when("DOCKER_HOST is an ssh host", func() {
+			it("sends requests through the ssh connection", func() {
+				h.AssertNil(t, os.Setenv("DOCKER_HOST", "ssh://some-user@some-host"))
+
+				client, err := docker.New()
+				h.AssertNil(t, err)
+				h.AssertEq(t, client.DaemonHost(), "http://docker")
+				h.AssertEq(t, client.IsRemote(), true)
+			})
+
+			it("fails without a host", func() {
+				h.AssertNil(t, os.Setenv("DOCKER_HOST", "ssh://"))
+
+				_, err := docker.New()
+				h.AssertError(t, err, "invalid DOCKER_HOST 'ssh://'")
+			})
+		})
+
+		when("DOCKER_HOST is a tcp host", func() {
+			it("uses the host", func() {
+				h.AssertNil(t, os.Setenv("DOCKER_HOST", "tcp://some-host:2375"))
+
+				client, err := docker.New()
+				h.AssertNil(t, err)
+				h.AssertEq(t, client.DaemonHost(), "tcp://some-host:2375")
+				h.AssertEq(t, client.IsRemote(), true)
+			})
+
+			it("is local on a loopback address", func() {
+				h.AssertNil(t, os.Setenv("DOCKER_HOST", "tcp://127.0.0.1:2375"))
+
+				client, err := docker.New()
+				h.AssertNil(t, err)
+				h.AssertEq(t, client.IsRemote(), false)
+			})
+		})
+
+		when("DOCKER_HOST is a unix socket", func() {
+			it("is local", func() {
+				h.AssertNil(t, os.Setenv("DOCKER_HOST", "unix:///var/run/docker.sock"))
+
+				client, err := docker.New()
+				h.AssertNil(t, err)
+				h.AssertEq(t, client.IsRemote(), false)
+			})
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/term"
+)
+
+// progressStep is the percentage by which the download progress of all layers advances between
+// the lines reporting it when progress bars cannot be drawn
+const progressStep = 10
+
+// DisplayPullProgress writes the messages of an image pull to out. Terminals show a progress bar for
+// each layer, while other outputs, such as log files and CI logs, show the download progress of all
+// layers together every 10 percent rather than no progress at all.
+func DisplayPullProgress(in io.Reader, out io.Writer) error {
+	if termFd, isTerm := term.GetFdInfo(out); isTerm {
+		return jsonmessage.DisplayJSONMessagesStream(in, &colorizedWriter{out}, termFd, isTerm, nil)
+	}
+
+	w := &colorizedWriter{out}
+	progress := &pullProgress{layers: map[string]float64{}}
+	dec := json.NewDecoder(in)
+	for {
+		var jm jsonmessage.JSONMessage
+		if err := dec.Decode(&jm); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if jm.Aux != nil {
+			continue
+		}
+
+		var (
+			percent  int
+			advanced bool
+		)
+		switch {
+		case jm.Status == "Pulling fs layer":
+			progress.layers[jm.ID] = 0
+		case jm.Status == "Downloading" && jm.Progress != nil && jm.Progress.Total > 0:
+			percent, advanced = progress.update(jm.ID, float64(jm.Progress.Current)/float64(jm.Progress.Total))
+		case jm.Status == "Download complete":
+			percent, advanced = progress.update(jm.ID, 1)
+		}
+		if advanced {
+			fmt.Fprintf(w, "Downloading %d%%\n", percent)
+		}
+
+		// progress messages are not displayed outside of terminals
+		if err := jm.Display(w, false); err != nil {
+			return err
+		}
+	}
+}
+
+// pullProgress averages the downloaded fractions of the layers of a pull, so that layers waiting
+// for their download to start, whose size is unknown until then, count as not downloaded
+type pullProgress struct {
+	layers   map[string]float64
+	reported int
+}
+
+// update records the downloaded fraction of a layer, returning the progress of all layers when it
+// advanced by at least one step since it was last reported
+func (p *pullProgress) update(id string, fraction float64) (int, bool) {
+	if _, ok := p.layers[id]; !ok {
+		return 0, false
+	}
+	p.layers[id] = fraction
+
+	var sum float64
+	for _, f := range p.layers {
+		sum += f
+	}
+	percent := int(sum*100/float64(len(p.layers))) / progressStep * progressStep
+	if percent <= p.reported {
+		return 0, false
+	}
+	p.reported = percent
+	return percent, true
+}
@@ -0,0 +1,61 @@
+package docker_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/docker"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestProgress(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Progress", testProgress, spec.Report(report.Terminal{}))
+}
+
+func testProgress(t *testing.T, when spec.G, it spec.S) {
+	when("#DisplayPullProgress", func() {
+		it("shows the download progress of all layers outside of terminals", func() {
+			messages := strings.Join([]string{
+				`{"status":"Pulling from some/image","id":"latest"}`,
+				`{"status":"Pulling fs layer","progressDetail":{},"id":"layer1"}`,
+				`{"status":"Pulling fs layer","progressDetail":{},"id":"layer2"}`,
+				`{"status":"Already exists","progressDetail":{},"id":"layer3"}`,
+				`{"status":"Downloading","progressDetail":{"current":50,"total":100},"id":"layer1"}`,
+				`{"status":"Downloading","progressDetail":{"current":55,"total":100},"id":"layer1"}`,
+				`{"status":"Download complete","progressDetail":{},"id":"layer1"}`,
+				`{"status":"Downloading","progressDetail":{"current":500,"total":1000},"id":"layer2"}`,
+				`{"status":"Download complete","progressDetail":{},"id":"layer2"}`,
+				`{"status":"Pull complete","progressDetail":{},"id":"layer1"}`,
+				`{"status":"Status: Downloaded newer image for some/image:latest"}`,
+			}, "\n")
+
+			var out bytes.Buffer
+			h.AssertNil(t, docker.DisplayPullProgress(strings.NewReader(messages), &out))
+			h.AssertEq(t, out.String(), `latest: Pulling from some/image
+layer1: Pulling fs layer
+layer2: Pulling fs layer
+layer3: Already exists
+Downloading 20%
+Downloading 50%
+layer1: Download complete
+Downloading 70%
+Downloading 100%
+layer2: Download complete
+layer1: Pull complete
+Status: Downloaded newer image for some/image:latest
+`)
+		})
+
+		it("fails on errors of the pull", func() {
+			var out bytes.Buffer
+			err := docker.DisplayPullProgress(strings.NewReader(`{"errorDetail":{"message":"some error"},"error":"some error"}`), &out)
+			h.AssertError(t, err, "some error")
+		})
+	})
+}
@@ -0,0 +1,95 @@
+package pack
+
+import (
+	"bytes"
+	"regexp"
+	"time"
+)
+
+type EventType string
+
+const (
+	PhaseStarted   EventType = "phase-started"
+	PhaseFinished  EventType = "phase-finished"
+	ImagePulled    EventType = "image-pulled"
+	LayerExported  EventType = "layer-exported"
+	BuildCompleted EventType = "build-completed"
+)
+
+// Event describes progress of a build. Only the fields relevant to the event type are set.
+type Event struct {
+	Type     EventType
+	Time     time.Time
+	Phase    string        // phase-started, phase-finished
+	Image    string        // image-pulled, build-completed
+	Layer    string        // layer-exported
+	Reused   bool          // layer-exported
+	Digest   string        // layer-exported (diff ID), build-completed (image digest)
+	Duration time.Duration // phase-finished, image-pulled
+	Size     int64         // build-completed (size in bytes of a local image)
+	Err      error         // phase-finished, build-completed
+}
+
+// EventHandler receives build events as they happen. It is called synchronously, so
+// handlers that do slow work should hand events off, e.g. to a buffered channel.
+type EventHandler func(Event)
+
+// EventHandlers returns a handler that passes each event to all of the given non-nil handlers in order
+func EventHandlers(handlers ...EventHandler) EventHandler {
+	return func(e Event) {
+		for _, h := range handlers {
+			if h != nil {
+				h(e)
+			}
+		}
+	}
+}
+
+// PhaseHook is notified before and after each phase of a build, so that tools embedding pack can
+// show the progress of builds in their own way rather than by reading the logger output
+type PhaseHook interface {
+	BeforePhase(name string)
+	AfterPhase(name string, err error, duration time.Duration)
+}
+
+func (h EventHandler) emit(e Event) {
+	if h == nil {
+		return
+	}
+	e.Time = time.Now()
+	h(e)
+}
+
+var (
+	exportedLayerRegexp = regexp.MustCompile(`^(adding|reusing) layer '([^']+)' with diffID '([^']+)'`)
+	exportedImageRegexp = regexp.MustCompile(`^\*\*\* Image: \S+@(\S+)`)
+)
+
+// exportObserver watches exporter output for exported layers and the resulting image digest
+type exportObserver struct {
+	handler EventHandler
+	digest  string
+	buf     bytes.Buffer
+}
+
+func (o *exportObserver) Write(p []byte) (n int, err error) {
+	o.buf.Write(p)
+	for {
+		line, err := o.buf.ReadString('\n')
+		if err != nil {
+			// keep the partial line until the rest of it is written
+			o.buf.Reset()
+			o.buf.WriteString(line)
+			return len(p), nil
+		}
+		o.observe(line)
+	}
+}
+
+func (o *exportObserver) observe(line string) {
+	if m := exportedLayerRegexp.FindStringSubmatch(line); m != nil {
+		o.handler.emit(Event{Type: LayerExported, Layer: m[2], Reused: m[1] == "reusing", Digest: m[3]})
+	} else if m := exportedImageRegexp.FindStringSubmatch(line); m != nil {
+		o.digest = m[1]
+	}
+}
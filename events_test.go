@@ -0,0 +1,105 @@
+package pack_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestEvents(t *testing.T) {
+	spec.Run(t, "events", testEvents, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testEvents(t *testing.T, when spec.G, it spec.S) {
+	when("#EventHandlers", func() {
+		it("passes each event to the handlers that are not nil, in order", func() {
+			var received []string
+			handler := pack.EventHandlers(
+				func(e pack.Event) { received = append(received, "first "+e.Phase) },
+				nil,
+				func(e pack.Event) { received = append(received, "second "+e.Phase) },
+			)
+
+			handler(pack.Event{Type: pack.PhaseStarted, Phase: "detector"})
+			h.AssertEq(t, received, []string{"first detector", "second detector"})
+		})
+	})
+
+	when("exporter output", func() {
+		var events []pack.Event
+
+		it.Before(func() {
+			events = nil
+		})
+
+		handler := func(e pack.Event) {
+			events = append(events, e)
+		}
+
+		it("emits the added and reused layers", func() {
+			observer := pack.NewExportObserver(handler)
+			before := time.Now()
+
+			fmt.Fprint(observer, "adding layer 'app' with diffID 'sha256:app-diff-id'\n"+
+				"reusing layer 'org.example.node:node' with diffID 'sha256:node-diff-id'\n")
+
+			h.AssertEq(t, len(events), 2)
+			h.AssertEq(t, events[0].Type, pack.LayerExported)
+			h.AssertEq(t, events[0].Layer, "app")
+			h.AssertEq(t, events[0].Digest, "sha256:app-diff-id")
+			h.AssertEq(t, events[0].Reused, false)
+			h.AssertEq(t, events[0].Time.Before(before), false)
+			h.AssertEq(t, events[1].Type, pack.LayerExported)
+			h.AssertEq(t, events[1].Layer, "org.example.node:node")
+			h.AssertEq(t, events[1].Digest, "sha256:node-diff-id")
+			h.AssertEq(t, events[1].Reused, true)
+		})
+
+		it("emits layers once their line is complete, when it is written in parts", func() {
+			observer := pack.NewExportObserver(handler)
+
+			fmt.Fprint(observer, "adding layer 'app' with ")
+			h.AssertEq(t, len(events), 0)
+
+			fmt.Fprint(observer, "diffID 'sha256:app-diff-id'\nadding layer 'launcher' ")
+			h.AssertEq(t, len(events), 1)
+			h.AssertEq(t, events[0].Layer, "app")
+
+			fmt.Fprint(observer, "with diffID 'sha256:launcher-diff-id'\n")
+			h.AssertEq(t, len(events), 2)
+			h.AssertEq(t, events[1].Layer, "launcher")
+			h.AssertEq(t, events[1].Digest, "sha256:launcher-diff-id")
+		})
+
+		it("records the digest of the exported image without emitting an event", func() {
+			observer := pack.NewExportObserver(handler)
+
+			fmt.Fprint(observer, "*** Image: index.docker.io/some/app:latest@sha256:image-digest\n")
+
+			h.AssertEq(t, observer.Digest(), "sha256:image-digest")
+			h.AssertEq(t, len(events), 0)
+		})
+
+		it("ignores other output", func() {
+			observer := pack.NewExportObserver(handler)
+
+			fmt.Fprint(observer, "writing metadata for uncached layer 'org.example.node:node'\n*** Images:\n")
+
+			h.AssertEq(t, observer.Digest(), "")
+			h.AssertEq(t, len(events), 0)
+		})
+
+		it("does nothing without a handler", func() {
+			observer := pack.NewExportObserver(nil)
+
+			_, err := fmt.Fprint(observer, "adding layer 'app' with diffID 'sha256:app-diff-id'\n")
+			h.AssertNil(t, err)
+		})
+	})
+}
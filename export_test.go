@@ -0,0 +1,20 @@
+package pack
+
+import "context"
+
+// NewExportObserver returns the writer that BuildConfig#Build gives the exporter output to, which
+// emits the exported layers to handler
+func NewExportObserver(handler EventHandler) *exportObserver {
+	return &exportObserver{handler: handler}
+}
+
+// Digest returns the digest of the exported image, once the exporter has written it
+func (o *exportObserver) Digest() string {
+	return o.digest
+}
+
+// ConfigureImage sets the labels, exposed ports, args and working directory of the build on the app
+// image, as BuildConfig#Build does once the image is exported
+func (b *BuildConfig) ConfigureImage(ctx context.Context) (string, error) {
+	return b.configureImage(ctx)
+}
@@ -0,0 +1,48 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// ParseURL splits app sources of the form <repository>#<ref> into the URL of a Git repository
+// and a branch or tag, returning false when source is not a Git repository. Repositories are
+// recognized by an ssh, git or scp-like URL, such as git@github.com:org/app.git, or by an http(s)
+// URL ending in .git.
+func ParseURL(source string) (url, ref string, ok bool) {
+	url = source
+	if i := strings.LastIndex(source, "#"); i >= 0 {
+		url, ref = source[:i], source[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(url, "git@"), strings.HasPrefix(url, "ssh://"), strings.HasPrefix(url, "git://"):
+		return url, ref, true
+	case strings.HasPrefix(url, "https://") && strings.HasSuffix(url, ".git"),
+		strings.HasPrefix(url, "http://") && strings.HasSuffix(url, ".git"):
+		return url, ref, true
+	}
+	return "", "", false
+}
+
+// Clone clones the repository at url into dir, checking out ref when set
+func Clone(ctx context.Context, url, ref, dir string) error {
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", url, dir)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "cloning %s: %s", style.Symbol(url), strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
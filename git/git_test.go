@@ -0,0 +1,94 @@
+package git_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/git"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestGit(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Git", testGit, spec.Report(report.Terminal{}))
+}
+
+func testGit(t *testing.T, when spec.G, it spec.S) {
+	when("#ParseURL", func() {
+		for _, tc := range []struct {
+			source, url, ref string
+			ok               bool
+		}{
+			{"https://github.com/org/app.git", "https://github.com/org/app.git", "", true},
+			{"https://github.com/org/app.git#some-branch", "https://github.com/org/app.git", "some-branch", true},
+			{"git@github.com:org/app.git#v1.2.3", "git@github.com:org/app.git", "v1.2.3", true},
+			{"ssh://git@example.com/org/app", "ssh://git@example.com/org/app", "", true},
+			{"https://example.com/some/app.tgz", "", "", false},
+			{"some/app#dir", "", "", false},
+			{"/some/app", "", "", false},
+		} {
+			tc := tc
+			it("parses "+tc.source, func() {
+				url, ref, ok := git.ParseURL(tc.source)
+				h.AssertEq(t, ok, tc.ok)
+				h.AssertEq(t, url, tc.url)
+				h.AssertEq(t, ref, tc.ref)
+			})
+		}
+	})
+
+	when("#Clone", func() {
+		var tmpDir string
+
+		it.Before(func() {
+			if _, err := exec.LookPath("git"); err != nil {
+				t.Skip("git is not installed")
+			}
+			var err error
+			tmpDir, err = ioutil.TempDir("", "pack.git.test")
+			h.AssertNil(t, err)
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.RemoveAll(tmpDir))
+		})
+
+		run := func(dir string, args ...string) {
+			t.Helper()
+			cmd := exec.Command("git", append([]string{"-c", "user.name=pack", "-c", "user.email=pack@example.com"}, args...)...)
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %s: %s", args, err, out)
+			}
+		}
+
+		it("clones the ref of a repository", func() {
+			repo := filepath.Join(tmpDir, "repo")
+			h.AssertNil(t, os.Mkdir(repo, 0755))
+			run(repo, "init", "--quiet")
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(repo, "some-file.txt"), []byte("some-content"), 0644))
+			run(repo, "add", ".")
+			run(repo, "commit", "--quiet", "-m", "some commit")
+			run(repo, "tag", "some-tag")
+
+			dest := filepath.Join(tmpDir, "dest")
+			h.AssertNil(t, git.Clone(context.Background(), "file://"+repo, "some-tag", dest))
+			contents, err := ioutil.ReadFile(filepath.Join(dest, "some-file.txt"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(contents), "some-content")
+		})
+
+		it("fails for missing repositories", func() {
+			err := git.Clone(context.Background(), "file://"+filepath.Join(tmpDir, "missing"), "", filepath.Join(tmpDir, "dest"))
+			h.AssertError(t, err, "cloning")
+		})
+	})
+}
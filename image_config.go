@@ -0,0 +1,184 @@
+package pack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/go-connections/nat"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/archive"
+	"github.com/buildpack/pack/keychain"
+	"github.com/buildpack/pack/style"
+)
+
+// reservedLabelPrefix is the prefix of the labels the lifecycle and stacks set on app images, which
+// builds cannot override
+const reservedLabelPrefix = "io.buildpacks."
+
+// checkLabels validates the labels of the build
+func checkLabels(f *BuildFlags) error {
+	for key := range f.Labels {
+		if key == "" {
+			return errors.New("labels must have a key")
+		}
+		if strings.HasPrefix(key, reservedLabelPrefix) {
+			return fmt.Errorf("label %s is reserved for buildpacks, use another prefix than %s", style.Symbol(key), style.Symbol(reservedLabelPrefix))
+		}
+	}
+	return nil
+}
+
+// exposedPorts returns the ports of the build, given as '<port>[/<protocol>]' or as ranges such as
+// '8000-8010', as '<port>/<protocol>' with tcp when no protocol is given
+func exposedPorts(f *BuildFlags) ([]string, error) {
+	var ports []string
+	seen := map[nat.Port]bool{}
+	for _, spec := range f.ExposedPorts {
+		mappings, err := nat.ParsePortSpec(spec)
+		if err == nil && len(mappings) > 0 && mappings[0].Binding.HostPort != "" {
+			err = errors.New("host ports cannot be published by the image")
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid port %s, must be of the form <port>[/<protocol>]", style.Symbol(spec))
+		}
+		for _, mapping := range mappings {
+			if !seen[mapping.Port] {
+				seen[mapping.Port] = true
+				ports = append(ports, string(mapping.Port))
+			}
+		}
+	}
+	return ports, nil
+}
+
+// configuresImage reports whether the build changes the config of the exported app image
+func (b *BuildConfig) configuresImage() bool {
+	return len(b.Labels) > 0 || len(b.ExposedPorts) > 0 || len(b.Args) > 0 || b.WorkingDir != ""
+}
+
+// configureImage sets the labels, exposed ports, args and working directory of the build on the
+// exported app image, in the daemon or, when published, by pushing the image again with the new
+// config. It returns the digest of the published image, which the config changes.
+func (b *BuildConfig) configureImage(ctx context.Context) (string, error) {
+	if !b.Publish {
+		return "", b.configureLocal(ctx)
+	}
+
+	ref, err := name.ParseReference(b.RepoName, name.WeakValidation)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid image name %s", style.Symbol(b.RepoName))
+	}
+	transport := registryTransport(b.Config)
+	img, err := remoteImage(ref, transport)
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching image %s", style.Symbol(b.RepoName))
+	}
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return "", errors.Wrapf(err, "reading config of image %s", style.Symbol(b.RepoName))
+	}
+	config := configFile.Config
+	if config.Labels == nil {
+		config.Labels = map[string]string{}
+	}
+	for key, value := range b.Labels {
+		config.Labels[key] = value
+	}
+	if len(b.ExposedPorts) > 0 && config.ExposedPorts == nil {
+		config.ExposedPorts = map[string]struct{}{}
+	}
+	for _, port := range b.ExposedPorts {
+		config.ExposedPorts[port] = struct{}{}
+	}
+	if len(b.Args) > 0 {
+		config.Cmd = b.Args
+	}
+	if b.WorkingDir != "" {
+		config.WorkingDir = b.WorkingDir
+	}
+	if img, err = mutate.Config(img, config); err != nil {
+		return "", err
+	}
+
+	b.Logger.Verbose("Pushing image %s with its new config", style.Symbol(b.RepoName))
+	auth, err := keychain.Default.Resolve(ref.Context().Registry)
+	if err != nil {
+		return "", err
+	}
+	if err := remote.Write(ref, img, auth, transport); err != nil {
+		return "", errors.Wrapf(err, "pushing image %s", style.Symbol(b.RepoName))
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	return digest.String(), nil
+}
+
+// configureLocal rebuilds the app image in the daemon from a Dockerfile changing its config
+func (b *BuildConfig) configureLocal(ctx context.Context) error {
+	dockerfile, err := b.configDockerfile()
+	if err != nil {
+		return err
+	}
+	buildContext, err := archive.CreateSingleFileTarReader("Dockerfile", dockerfile)
+	if err != nil {
+		return err
+	}
+	b.Logger.Verbose("Configuring image %s", style.Symbol(b.RepoName))
+	res, err := b.Cli.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Tags:        []string{b.RepoName},
+		Remove:      true,
+		ForceRemove: true,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "configuring image %s", style.Symbol(b.RepoName))
+	}
+	defer res.Body.Close()
+	if err := jsonmessage.DisplayJSONMessagesStream(res.Body, ioutil.Discard, 0, false, nil); err != nil {
+		return errors.Wrapf(err, "configuring image %s", style.Symbol(b.RepoName))
+	}
+	return nil
+}
+
+// configDockerfile returns a Dockerfile setting the config of the build on the app image, with the
+// label keys and values quoted so that they may contain spaces and quotes
+func (b *BuildConfig) configDockerfile() (string, error) {
+	var keys []string
+	for key := range b.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "FROM %s\n", b.RepoName)
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "LABEL %s=%s\n", strconv.Quote(key), strconv.Quote(b.Labels[key]))
+	}
+	if len(b.ExposedPorts) > 0 {
+		fmt.Fprintf(&sb, "EXPOSE %s\n", strings.Join(b.ExposedPorts, " "))
+	}
+	if b.WorkingDir != "" {
+		fmt.Fprintf(&sb, "WORKDIR %s\n", b.WorkingDir)
+	}
+	if len(b.Args) > 0 {
+		// the JSON form passes the args to the entrypoint as they are, rather than to a shell
+		args, err := json.Marshal(b.Args)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "CMD %s\n", args)
+	}
+	return sb.String(), nil
+}
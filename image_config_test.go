@@ -0,0 +1,237 @@
+package pack_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestConfigureImage(t *testing.T) {
+	spec.Run(t, "configure_image", testConfigureImage, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testConfigureImage(t *testing.T, when spec.G, it spec.S) {
+	var (
+		outBuf, errBuf bytes.Buffer
+		subject        *pack.BuildConfig
+	)
+
+	it.Before(func() {
+		subject = &pack.BuildConfig{
+			RepoName:     "some/app",
+			Labels:       map[string]string{"org.example.commit": "abc123", "org.example.title": `some "quoted" title`},
+			ExposedPorts: []string{"8080/tcp", "53/udp"},
+			Args:         []string{"--some-flag", "some arg"},
+			WorkingDir:   "/workspace/src",
+			Logger:       logging.NewLogger(&outBuf, &errBuf, true, false),
+			Config:       &config.Config{},
+		}
+	})
+
+	when("the image is in the daemon", func() {
+		var (
+			mockController *gomock.Controller
+			mockDocker     *mocks.MockDocker
+		)
+
+		it.Before(func() {
+			mockController = gomock.NewController(t)
+			mockDocker = mocks.NewMockDocker(mockController)
+			subject.Cli = mockDocker
+		})
+
+		it.After(func() {
+			mockController.Finish()
+		})
+
+		it("rebuilds the image from a Dockerfile setting its config", func() {
+			var dockerfile string
+			mockDocker.EXPECT().ImageBuild(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+					h.AssertEq(t, options.Tags, []string{"some/app"})
+					tr := tar.NewReader(buildContext)
+					hdr, err := tr.Next()
+					h.AssertNil(t, err)
+					h.AssertEq(t, hdr.Name, "Dockerfile")
+					contents, err := ioutil.ReadAll(tr)
+					h.AssertNil(t, err)
+					dockerfile = string(contents)
+					return types.ImageBuildResponse{Body: ioutil.NopCloser(strings.NewReader(`{"stream":"Successfully built"}`))}, nil
+				})
+
+			digest, err := subject.ConfigureImage(context.TODO())
+			h.AssertNil(t, err)
+			h.AssertEq(t, digest, "")
+			h.AssertEq(t, dockerfile, `FROM some/app
+LABEL "org.example.commit"="abc123"
+LABEL "org.example.title"="some \"quoted\" title"
+EXPOSE 8080/tcp 53/udp
+WORKDIR /workspace/src
+CMD ["--some-flag","some arg"]
+`)
+		})
+
+		it("leaves out what the build does not configure", func() {
+			subject.ExposedPorts = nil
+			subject.Args = nil
+			subject.WorkingDir = ""
+			var dockerfile string
+			mockDocker.EXPECT().ImageBuild(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, buildContext io.Reader, _ types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+					tr := tar.NewReader(buildContext)
+					_, err := tr.Next()
+					h.AssertNil(t, err)
+					contents, err := ioutil.ReadAll(tr)
+					h.AssertNil(t, err)
+					dockerfile = string(contents)
+					return types.ImageBuildResponse{Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+				})
+
+			_, err := subject.ConfigureImage(context.TODO())
+			h.AssertNil(t, err)
+			h.AssertNotContains(t, dockerfile, "EXPOSE")
+			h.AssertNotContains(t, dockerfile, "WORKDIR")
+			h.AssertNotContains(t, dockerfile, "CMD")
+		})
+
+		it("fails when the daemon cannot build the image", func() {
+			mockDocker.EXPECT().ImageBuild(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(types.ImageBuildResponse{}, errors.New("some build error"))
+
+			_, err := subject.ConfigureImage(context.TODO())
+			h.AssertError(t, err, "configuring image 'some/app': some build error")
+		})
+
+		it("fails when building the image fails", func() {
+			mockDocker.EXPECT().ImageBuild(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(types.ImageBuildResponse{Body: ioutil.NopCloser(strings.NewReader(`{"errorDetail":{"message":"some step error"}}`))}, nil)
+
+			_, err := subject.ConfigureImage(context.TODO())
+			h.AssertError(t, err, "configuring image 'some/app': some step error")
+		})
+	})
+
+	when("the image is published", func() {
+		var fakeRegistry *h.FakeRegistry
+
+		it.Before(func() {
+			fakeRegistry = h.NewFakeRegistry()
+			subject.Publish = true
+			subject.RepoName = fakeRegistry.Host() + "/some/app"
+		})
+
+		it.After(func() {
+			fakeRegistry.Close()
+		})
+
+		push := func(config v1.Config) v1.Image {
+			t.Helper()
+			img, err := random.Image(100, 1)
+			h.AssertNil(t, err)
+			img, err = mutate.Config(img, config)
+			h.AssertNil(t, err)
+			ref, err := name.ParseReference(subject.RepoName, name.WeakValidation)
+			h.AssertNil(t, err)
+			h.AssertNil(t, remote.Write(ref, img, authn.Anonymous, http.DefaultTransport))
+			return img
+		}
+
+		fetch := func() v1.Image {
+			t.Helper()
+			ref, err := name.ParseReference(subject.RepoName, name.WeakValidation)
+			h.AssertNil(t, err)
+			img, err := remote.Image(ref)
+			h.AssertNil(t, err)
+			return img
+		}
+
+		it("pushes the image again with the config of the build, keeping its layers and config", func() {
+			original := push(v1.Config{
+				Labels:       map[string]string{"io.buildpacks.stack.id": "some.stack", "org.example.commit": "old"},
+				Env:          []string{"SOME_KEY=some-value"},
+				ExposedPorts: map[string]struct{}{"9000/tcp": {}},
+				Entrypoint:   []string{"/cnb/lifecycle/launcher"},
+				WorkingDir:   "/workspace",
+			})
+
+			digest, err := subject.ConfigureImage(context.TODO())
+			h.AssertNil(t, err)
+
+			pushed := fetch()
+			pushedDigest, err := pushed.Digest()
+			h.AssertNil(t, err)
+			h.AssertEq(t, digest, pushedDigest.String())
+			configFile, err := pushed.ConfigFile()
+			h.AssertNil(t, err)
+			h.AssertEq(t, configFile.Config.Labels, map[string]string{
+				"io.buildpacks.stack.id": "some.stack",
+				"org.example.commit":     "abc123",
+				"org.example.title":      `some "quoted" title`,
+			})
+			h.AssertEq(t, configFile.Config.Env, []string{"SOME_KEY=some-value"})
+			h.AssertEq(t, configFile.Config.ExposedPorts, map[string]struct{}{"9000/tcp": {}, "8080/tcp": {}, "53/udp": {}})
+			h.AssertEq(t, configFile.Config.Entrypoint, []string{"/cnb/lifecycle/launcher"})
+			h.AssertEq(t, configFile.Config.Cmd, []string{"--some-flag", "some arg"})
+			h.AssertEq(t, configFile.Config.WorkingDir, "/workspace/src")
+
+			originalLayers, err := original.Layers()
+			h.AssertNil(t, err)
+			pushedLayers, err := pushed.Layers()
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(pushedLayers), len(originalLayers))
+			originalDiffID, err := originalLayers[0].DiffID()
+			h.AssertNil(t, err)
+			pushedDiffID, err := pushedLayers[0].DiffID()
+			h.AssertNil(t, err)
+			h.AssertEq(t, pushedDiffID, originalDiffID)
+		})
+
+		it("keeps the command and working directory of the image when the build does not set them", func() {
+			subject.Args = nil
+			subject.WorkingDir = ""
+			push(v1.Config{Cmd: []string{"some-cmd"}, WorkingDir: "/workspace"})
+
+			_, err := subject.ConfigureImage(context.TODO())
+			h.AssertNil(t, err)
+
+			configFile, err := fetch().ConfigFile()
+			h.AssertNil(t, err)
+			h.AssertEq(t, configFile.Config.Cmd, []string{"some-cmd"})
+			h.AssertEq(t, configFile.Config.WorkingDir, "/workspace")
+		})
+
+		it("fails when the image does not exist", func() {
+			_, err := subject.ConfigureImage(context.TODO())
+			h.AssertError(t, err, "reading config of image '"+subject.RepoName+"': MANIFEST_UNKNOWN")
+		})
+
+		it("fails for invalid image names", func() {
+			subject.RepoName = "Some/App"
+			_, err := subject.ConfigureImage(context.TODO())
+			h.AssertError(t, err, "invalid image name 'Some/App'")
+		})
+	})
+}
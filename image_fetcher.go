@@ -5,14 +5,37 @@ import (
 	"io"
 
 	"github.com/buildpack/lifecycle/image"
+	"github.com/docker/docker/api/types"
+
+	"github.com/buildpack/pack/logging"
 )
 
 type ImageFetcher struct {
 	Docker  Docker
 	Factory ImageFactory
+	// Logger optionally receives debug messages about registry interactions
+	Logger *logging.Logger
+}
+
+// FetchOptions holds the settings applied by options such as WithPlatform
+type FetchOptions struct {
+	platform string
+}
+
+// WithPlatform pulls images for the given platform, in the form os/arch[/variant], rather than
+// for the platform of the docker daemon
+func WithPlatform(platform string) func(*FetchOptions) {
+	return func(o *FetchOptions) {
+		o.platform = platform
+	}
 }
 
-func (f *ImageFetcher) FetchUpdatedLocalImage(ctx context.Context, imageName string, stdout io.Writer) (image.Image, error) {
+func (f *ImageFetcher) FetchUpdatedLocalImage(ctx context.Context, imageName string, stdout io.Writer, ops ...func(*FetchOptions)) (image.Image, error) {
+	var opts FetchOptions
+	for _, op := range ops {
+		op(&opts)
+	}
+
 	expectedImage, err := f.FetchRemoteImage(imageName)
 	if err != nil {
 		return nil, err
@@ -21,10 +44,19 @@ func (f *ImageFetcher) FetchUpdatedLocalImage(ctx context.Context, imageName str
 	if found, err := expectedImage.Found(); err != nil {
 		return nil, err
 	} else if found {
-		err = f.Docker.PullImage(ctx, imageName, stdout)
+		f.debug("Image %s found in registry, pulling", imageName)
+		var pullOps []func(*types.ImagePullOptions)
+		if opts.platform != "" {
+			pullOps = append(pullOps, func(o *types.ImagePullOptions) {
+				o.Platform = opts.platform
+			})
+		}
+		err = f.Docker.PullImage(ctx, imageName, stdout, pullOps...)
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		f.debug("Image %s not found in registry, using local image", imageName)
 	}
 
 	return f.FetchLocalImage(imageName)
@@ -35,5 +67,12 @@ func (f *ImageFetcher) FetchLocalImage(imageName string) (image.Image, error) {
 }
 
 func (f *ImageFetcher) FetchRemoteImage(imageName string) (image.Image, error) {
+	f.debug("Fetching remote image %s", imageName)
 	return f.Factory.NewRemote(imageName)
 }
+
+func (f *ImageFetcher) debug(format string, a ...interface{}) {
+	if f.Logger != nil {
+		f.Logger.Debug(format, a...)
+	}
+}
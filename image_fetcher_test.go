@@ -2,9 +2,11 @@ package pack_test
 
 import (
 	"context"
+	"io"
 	"io/ioutil"
 	"testing"
 
+	"github.com/docker/docker/api/types"
 	"github.com/fatih/color"
 	"github.com/golang/mock/gomock"
 	"github.com/sclevine/spec"
@@ -60,6 +62,19 @@ func testImageFetcher(t *testing.T, when spec.G, it spec.S) {
 				h.AssertNil(t, err)
 				h.AssertSameInstance(t, img, mockLocalImage)
 			})
+
+			it("pulls the image for the requested platform", func() {
+				var options types.ImagePullOptions
+				mockDocker.EXPECT().PullImage(gomock.Any(), "some/image", gomock.Any(), gomock.Any()).
+					Do(func(_ context.Context, _ string, _ io.Writer, ops ...func(*types.ImagePullOptions)) {
+						for _, op := range ops {
+							op(&options)
+						}
+					})
+				_, err := fetcher.FetchUpdatedLocalImage(context.TODO(), "some/image", ioutil.Discard, pack.WithPlatform("linux/arm64"))
+				h.AssertNil(t, err)
+				h.AssertEq(t, options.Platform, "linux/arm64")
+			})
 		})
 
 		when("remote image does not exist", func() {
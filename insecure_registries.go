@@ -0,0 +1,52 @@
+package pack
+
+import (
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/registry"
+	"github.com/buildpack/pack/style"
+)
+
+// registryImages returns the images that the phases of the build reach at a registry rather than
+// in the docker daemon
+func (b *BuildConfig) registryImages() []string {
+	var images []string
+	if b.Publish {
+		images = append(append(images, b.RepoName, b.RunImage), b.AdditionalTags...)
+	}
+	if b.Cache != nil && b.Cache.Remote() {
+		images = append(images, b.Cache.Image())
+	}
+	return images
+}
+
+// registryTransport returns the transport of the registry operations of pack, reaching the insecure
+// registries of cfg over HTTP
+func registryTransport(cfg *config.Config) http.RoundTripper {
+	if cfg == nil {
+		return registry.NewTransport()
+	}
+	return registry.NewTransport(cfg.InsecureRegistries...)
+}
+
+// checkInsecureRegistries returns an error when one of the images is on one of the insecure
+// registries of cfg. The lifecycles pack supports reach registries over HTTPS, except for those on
+// localhost or private addresses, so the phases cannot reach the others that pack reaches over HTTP.
+func checkInsecureRegistries(cfg *config.Config, images ...string) error {
+	for _, image := range images {
+		ref, err := name.ParseReference(image, name.WeakValidation)
+		if err != nil {
+			// invalid image names are reported by the phases
+			continue
+		}
+		registry := ref.Context().Registry
+		if cfg.IsInsecureRegistry(registry.RegistryStr()) && registry.Scheme() != "http" {
+			return errors.Errorf("image %s is on insecure registry %s, which the lifecycle cannot reach over HTTP. Use a registry served over HTTPS, or build without --publish and push the image with 'docker push'.", style.Symbol(image), style.Symbol(registry.RegistryStr()))
+		}
+	}
+	return nil
+}
@@ -13,6 +13,9 @@ type BuilderInfo struct {
 	LocalRunImageMirrors []string
 	Buildpacks           []BuildpackInfo
 	Groups               [][]BuildpackInfo
+	// LifecycleVersion is the version of the lifecycle added by create-builder, or empty when the
+	// builder image provides its own
+	LifecycleVersion string
 }
 
 type BuildpackInfo struct {
@@ -71,6 +74,11 @@ func (c *Client) InspectBuilder(name string, daemon bool) (*BuilderInfo, error)
 		}
 	}
 
+	var lifecycleVersion string
+	if metadata.Lifecycle != nil {
+		lifecycleVersion = metadata.Lifecycle.Version
+	}
+
 	return &BuilderInfo{
 		Stack:                stackID,
 		RunImage:             metadata.Stack.RunImage.Image,
@@ -78,6 +86,7 @@ func (c *Client) InspectBuilder(name string, daemon bool) (*BuilderInfo, error)
 		LocalRunImageMirrors: localMirrors,
 		Buildpacks:           buildpacks,
 		Groups:               groups,
+		LifecycleVersion:     lifecycleVersion,
 	}, nil
 }
 
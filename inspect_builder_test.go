@@ -35,11 +35,16 @@ func testInspectBuilder(t *testing.T, when spec.G, it spec.S) {
 	it.Before(func() {
 		mockController = gomock.NewController(t)
 		mockFetcher = mocks.NewMockFetcher(mockController)
-		client = pack.NewClient(&config.Config{
-			RunImages: []config.RunImage{
-				{Image: "some/run-image", Mirrors: []string{"some/local-mirror"}},
-			},
-		}, mockFetcher)
+		var err error
+		client, err = pack.NewClient(
+			pack.WithConfig(&config.Config{
+				RunImages: []config.RunImage{
+					{Image: "some/run-image", Mirrors: []string{"some/local-mirror"}},
+				},
+			}),
+			pack.WithFetcher(mockFetcher),
+		)
+		h.AssertNil(t, err)
 		builderImage = imgtest.NewFakeImage(t, "some/builder", "", "")
 	})
 
@@ -62,6 +67,9 @@ func testInspectBuilder(t *testing.T, when spec.G, it spec.S) {
 					it.Before(func() {
 						testhelpers.AssertNil(t, builderImage.SetLabel("io.buildpacks.stack.id", "test.stack.id"))
 						testhelpers.AssertNil(t, builderImage.SetLabel("io.buildpacks.builder.metadata", `{
+  "lifecycle": {
+    "version": "0.1.0"
+  },
   "stack": {
     "runImage": {
       "image": "some/run-image",
@@ -97,6 +105,12 @@ func testInspectBuilder(t *testing.T, when spec.G, it spec.S) {
 						h.AssertEq(t, builderInfo.RunImage, "some/run-image")
 					})
 
+					it("sets the lifecycle version", func() {
+						builderInfo, err := client.InspectBuilder("some/builder", useDaemon)
+						h.AssertNil(t, err)
+						h.AssertEq(t, builderInfo.LifecycleVersion, "0.1.0")
+					})
+
 					it("set the local run image mirrors", func() {
 						builderInfo, err := client.InspectBuilder("some/builder", useDaemon)
 						h.AssertNil(t, err)
@@ -0,0 +1,120 @@
+package pack
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/buildpack/lifecycle"
+	"github.com/buildpack/lifecycle/image"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+type ImageInfo struct {
+	Stack      StackInfo
+	RunImage   RunImageInfo
+	Buildpacks []BuildpackInfo
+	// Layers are the layers contributed by buildpacks, in the order of the buildpacks
+	Layers []LayerInfo
+	// AppLayer, ConfigLayer and LauncherLayer are the SHAs of the layers the lifecycle adds
+	AppLayer      string
+	ConfigLayer   string
+	LauncherLayer string
+}
+
+// StackInfo names the run image the app image was built for, and its mirrors
+type StackInfo struct {
+	RunImage string
+	Mirrors  []string
+}
+
+// RunImageInfo identifies the run image the app image is based on
+type RunImageInfo struct {
+	TopLayer string
+	SHA      string
+}
+
+type LayerInfo struct {
+	Buildpack string
+	Name      string
+	SHA       string
+	Build     bool
+	Launch    bool
+	Cache     bool
+	// Metadata is the metadata the buildpack stored with the layer
+	Metadata interface{}
+}
+
+// InspectImage returns information about an app image built by pack, or nil when the image does
+// not exist
+func (c *Client) InspectImage(name string, daemon bool) (*ImageInfo, error) {
+	var (
+		img image.Image
+		err error
+	)
+
+	if daemon {
+		img, err = c.fetcher.FetchLocalImage(name)
+	} else {
+		img, err = c.fetcher.FetchRemoteImage(name)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get image %s", style.Symbol(name))
+	}
+
+	if found, err := img.Found(); err != nil {
+		return nil, errors.Wrapf(err, "failed to find image %s", style.Symbol(name))
+	} else if !found {
+		return nil, nil
+	}
+
+	label, err := img.Label(lifecycle.MetadataLabel)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get metadata of image %s", style.Symbol(name))
+	}
+	if label == "" {
+		return nil, errors.Errorf("image %s was not built by pack, it is missing label %s", style.Symbol(name), style.Symbol(lifecycle.MetadataLabel))
+	}
+
+	var metadata lifecycle.AppImageMetadata
+	if err := json.Unmarshal([]byte(label), &metadata); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse metadata of image %s", style.Symbol(name))
+	}
+
+	info := &ImageInfo{
+		Stack: StackInfo{
+			RunImage: metadata.Stack.RunImage.Image,
+			Mirrors:  metadata.Stack.RunImage.Mirrors,
+		},
+		RunImage: RunImageInfo{
+			TopLayer: metadata.RunImage.TopLayer,
+			SHA:      metadata.RunImage.SHA,
+		},
+		AppLayer:      metadata.App.SHA,
+		ConfigLayer:   metadata.Config.SHA,
+		LauncherLayer: metadata.Launcher.SHA,
+	}
+	for _, bp := range metadata.Buildpacks {
+		info.Buildpacks = append(info.Buildpacks, BuildpackInfo{ID: bp.ID, Version: bp.Version})
+
+		var names []string
+		for name := range bp.Layers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			layer := bp.Layers[name]
+			info.Layers = append(info.Layers, LayerInfo{
+				Buildpack: bp.ID,
+				Name:      name,
+				SHA:       layer.SHA,
+				Build:     layer.Build,
+				Launch:    layer.Launch,
+				Cache:     layer.Cache,
+				Metadata:  layer.Data,
+			})
+		}
+	}
+	return info, nil
+}
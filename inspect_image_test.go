@@ -0,0 +1,92 @@
+package pack_test
+
+import (
+	"testing"
+
+	imgtest "github.com/buildpack/lifecycle/testhelpers"
+	"github.com/fatih/color"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestInspectImage(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "InspectImage", testInspectImage, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testInspectImage(t *testing.T, when spec.G, it spec.S) {
+	var (
+		client         *pack.Client
+		mockFetcher    *mocks.MockFetcher
+		mockController *gomock.Controller
+		appImage       *imgtest.FakeImage
+	)
+
+	it.Before(func() {
+		var err error
+		mockController = gomock.NewController(t)
+		mockFetcher = mocks.NewMockFetcher(mockController)
+		client, err = pack.NewClient(pack.WithConfig(&config.Config{}), pack.WithFetcher(mockFetcher))
+		h.AssertNil(t, err)
+		appImage = imgtest.NewFakeImage(t, "some/app", "", "")
+		mockFetcher.EXPECT().FetchLocalImage("some/app").Return(appImage, nil)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("the image has lifecycle metadata", func() {
+		it.Before(func() {
+			h.AssertNil(t, appImage.SetLabel("io.buildpacks.lifecycle.metadata", `{
+  "app": {"sha": "some-app-sha"},
+  "config": {"sha": "some-config-sha"},
+  "launcher": {"sha": "some-launcher-sha"},
+  "runImage": {"topLayer": "some-top-layer", "sha": "some-run-image-sha"},
+  "stack": {"runImage": {"image": "some/run", "mirrors": ["gcr.io/some/run"]}},
+  "buildpacks": [{"key": "some.bp", "version": "1.2.3", "layers": {
+    "some-layer": {"sha": "some-layer-sha", "data": {"some-key": "some-value"}, "launch": true},
+    "other-layer": {"sha": "other-layer-sha", "build": true, "cache": true}
+  }}]
+}`))
+		})
+
+		it("returns the run image and buildpacks", func() {
+			info, err := client.InspectImage("some/app", true)
+			h.AssertNil(t, err)
+			h.AssertEq(t, info.RunImage, pack.RunImageInfo{TopLayer: "some-top-layer", SHA: "some-run-image-sha"})
+			h.AssertEq(t, info.Buildpacks, []pack.BuildpackInfo{{ID: "some.bp", Version: "1.2.3"}})
+		})
+
+		it("returns the stack", func() {
+			info, err := client.InspectImage("some/app", true)
+			h.AssertNil(t, err)
+			h.AssertEq(t, info.Stack, pack.StackInfo{RunImage: "some/run", Mirrors: []string{"gcr.io/some/run"}})
+		})
+
+		it("returns the layers", func() {
+			info, err := client.InspectImage("some/app", true)
+			h.AssertNil(t, err)
+			h.AssertEq(t, info.AppLayer, "some-app-sha")
+			h.AssertEq(t, info.ConfigLayer, "some-config-sha")
+			h.AssertEq(t, info.LauncherLayer, "some-launcher-sha")
+			h.AssertEq(t, info.Layers, []pack.LayerInfo{
+				{Buildpack: "some.bp", Name: "other-layer", SHA: "other-layer-sha", Build: true, Cache: true},
+				{Buildpack: "some.bp", Name: "some-layer", SHA: "some-layer-sha", Launch: true, Metadata: map[string]interface{}{"some-key": "some-value"}},
+			})
+		})
+	})
+
+	when("the image has no lifecycle metadata", func() {
+		it("fails", func() {
+			_, err := client.InspectImage("some/app", true)
+			h.AssertError(t, err, "image 'some/app' was not built by pack")
+		})
+	})
+}
@@ -8,23 +8,40 @@ import (
 	"github.com/buildpack/lifecycle/image"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/google/go-containerregistry/pkg/v1"
 )
 
+// Logger receives the output of builds and runs. *logging.Logger implements it, and programs
+// embedding pack can implement it to route output into their own logging framework.
+type Logger interface {
+	Debug(format string, a ...interface{})
+	Verbose(format string, a ...interface{})
+	Info(format string, a ...interface{})
+	Warn(format string, a ...interface{})
+}
+
 //go:generate mockgen -package mocks -destination mocks/docker.go github.com/buildpack/pack Docker
 type Docker interface {
 	RunContainer(ctx context.Context, id string, stdout io.Writer, stderr io.Writer) error
 	VolumeRemove(ctx context.Context, volumeID string, force bool) error
+	VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error)
 	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error)
 	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
 	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
 	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error
 	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error)
+	DiskUsage(ctx context.Context) (types.DiskUsage, error)
 	ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error)
 	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
 	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
-	PullImage(ctx context.Context, imageID string, stdout io.Writer) error
+	ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error)
+	ImageTag(ctx context.Context, source, target string) error
+	ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error)
+	PullImage(ctx context.Context, imageID string, stdout io.Writer, ops ...func(*types.ImagePullOptions)) error
 }
 
 //go:generate mockgen -package mocks -destination mocks/task.go github.com/buildpack/pack Task
@@ -45,7 +62,7 @@ type ImageFactory interface {
 
 //go:generate mockgen -package mocks -destination mocks/fetcher.go github.com/buildpack/pack Fetcher
 type Fetcher interface {
-	FetchUpdatedLocalImage(context.Context, string, io.Writer) (image.Image, error)
+	FetchUpdatedLocalImage(context.Context, string, io.Writer, ...func(*FetchOptions)) (image.Image, error)
 	FetchLocalImage(string) (image.Image, error)
 	FetchRemoteImage(string) (image.Image, error)
 }
@@ -0,0 +1,139 @@
+// Package keychain resolves the credentials of registries that images are pulled from and published
+// to, so that builds in CI can authenticate without writing a docker config file.
+package keychain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/buildpack/lifecycle/image"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+const (
+	// EnvRegistryAuth holds a JSON object of registries to their credentials, given either as the
+	// value of an Authorization header, such as "Basic ...", or as an object with a username and
+	// password, for example {"gcr.io": {"username": "_json_key", "password": "..."}}
+	EnvRegistryAuth = "PACK_REGISTRY_AUTH"
+	// EnvCredentialHelpers holds a comma separated list of registries and the docker credential
+	// helpers providing their credentials, for example 'gcr.io=gcloud,ghcr.io=pass'. Helpers are
+	// run as docker-credential-<helper>.
+	EnvCredentialHelpers = "PACK_CREDENTIAL_HELPERS"
+)
+
+// helperNotFound is the output of credential helpers without credentials for a registry
+const helperNotFound = "credentials not found in native keychain"
+
+// Default looks up the credentials of a registry in PACK_REGISTRY_AUTH, then by the credential
+// helper given for it in PACK_CREDENTIAL_HELPERS, then in the docker config file, including its
+// credential helpers. Registries without credentials are accessed anonymously.
+var Default authn.Keychain = authn.NewMultiKeychain(envKeychain{}, helperKeychain{}, authn.DefaultKeychain)
+
+// FactoryOption makes an image factory resolve the credentials of registries with Default, rather
+// than only from the docker config file
+func FactoryOption(f *image.Factory) {
+	f.Keychain = Default
+}
+
+type envKeychain struct{}
+
+func (envKeychain) Resolve(registry name.Registry) (authn.Authenticator, error) {
+	env := os.Getenv(EnvRegistryAuth)
+	if env == "" {
+		return authn.Anonymous, nil
+	}
+	auths := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(env), &auths); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", EnvRegistryAuth)
+	}
+	for key, value := range auths {
+		if !matches(key, registry) {
+			continue
+		}
+		var header string
+		if err := json.Unmarshal(value, &header); err == nil {
+			return headerAuth(header), nil
+		}
+		var basic struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := json.Unmarshal(value, &basic); err != nil || basic.Username == "" {
+			return nil, fmt.Errorf("credentials of registry %s in %s must be a string or have a username and password", style.Symbol(key), EnvRegistryAuth)
+		}
+		return &authn.Basic{Username: basic.Username, Password: basic.Password}, nil
+	}
+	return authn.Anonymous, nil
+}
+
+type helperKeychain struct{}
+
+func (helperKeychain) Resolve(registry name.Registry) (authn.Authenticator, error) {
+	for _, entry := range strings.Split(os.Getenv(EnvCredentialHelpers), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid credential helper %s in %s, must be of the form <registry>=<helper>", style.Symbol(entry), EnvCredentialHelpers)
+		}
+		if matches(strings.TrimSpace(parts[0]), registry) {
+			return helperCredentials(strings.TrimSpace(parts[1]), registry)
+		}
+	}
+	return authn.Anonymous, nil
+}
+
+// helperCredentials runs 'docker-credential-<helper> get' with the registry as its input, as the
+// docker CLI does
+func helperCredentials(helper string, registry name.Registry) (authn.Authenticator, error) {
+	command := "docker-credential-" + helper
+	cmd := exec.Command(command, "get")
+	cmd.Stdin = strings.NewReader(registry.Name())
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	output := strings.TrimSpace(stdout.String())
+	if output == helperNotFound {
+		return authn.Anonymous, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "running credential helper %s: %s", style.Symbol(command), strings.TrimSpace(stderr.String()))
+	}
+	var creds struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal([]byte(output), &creds); err != nil {
+		return nil, errors.Wrapf(err, "parsing output of credential helper %s", style.Symbol(command))
+	}
+	return &authn.Basic{Username: creds.Username, Password: creds.Secret}, nil
+}
+
+// matches reports whether key names the registry, with or without a scheme, so that 'docker.io'
+// and 'https://index.docker.io/v1/' both name Docker Hub
+func matches(key string, registry name.Registry) bool {
+	for _, prefix := range []string{"https://", "http://"} {
+		key = strings.TrimPrefix(key, prefix)
+	}
+	key = strings.SplitN(key, "/", 2)[0]
+	other, err := name.NewRegistry(key, name.WeakValidation)
+	return err == nil && other.Name() == registry.Name()
+}
+
+// headerAuth is the value of an Authorization header
+type headerAuth string
+
+func (h headerAuth) Authorization() (string, error) {
+	return string(h), nil
+}
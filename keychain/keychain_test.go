@@ -0,0 +1,129 @@
+package keychain_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/keychain"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestKeychain(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Keychain", testKeychain, spec.Report(report.Terminal{}))
+}
+
+func testKeychain(t *testing.T, when spec.G, it spec.S) {
+	when("#Default", func() {
+		var (
+			env    = map[string]string{}
+			tmpDir string
+		)
+
+		setEnv := func(key, value string) {
+			if _, ok := env[key]; !ok {
+				env[key] = os.Getenv(key)
+			}
+			h.AssertNil(t, os.Setenv(key, value))
+		}
+
+		resolve := func(reg string) string {
+			t.Helper()
+			registry, err := name.NewRegistry(reg, name.WeakValidation)
+			h.AssertNil(t, err)
+			auth, err := keychain.Default.Resolve(registry)
+			h.AssertNil(t, err)
+			header, err := auth.Authorization()
+			h.AssertNil(t, err)
+			return header
+		}
+
+		it.Before(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "pack.keychain")
+			h.AssertNil(t, err)
+			// no docker config file is read
+			setEnv("DOCKER_CONFIG", tmpDir)
+			setEnv(keychain.EnvRegistryAuth, "")
+			setEnv(keychain.EnvCredentialHelpers, "")
+		})
+
+		it.After(func() {
+			for key, value := range env {
+				h.AssertNil(t, os.Setenv(key, value))
+			}
+			h.AssertNil(t, os.RemoveAll(tmpDir))
+		})
+
+		it("accesses registries without credentials anonymously", func() {
+			anonymous, err := authn.Anonymous.Authorization()
+			h.AssertNil(t, err)
+			h.AssertEq(t, resolve("gcr.io"), anonymous)
+		})
+
+		when("PACK_REGISTRY_AUTH is set", func() {
+			it("uses the authorization header of the registry", func() {
+				setEnv(keychain.EnvRegistryAuth, `{"gcr.io": "Bearer some-token"}`)
+
+				h.AssertEq(t, resolve("gcr.io"), "Bearer some-token")
+			})
+
+			it("uses the username and password of the registry", func() {
+				setEnv(keychain.EnvRegistryAuth, `{"https://index.docker.io/v1/": {"username": "some-user", "password": "some-password"}}`)
+
+				h.AssertEq(t, resolve("docker.io"), "Basic c29tZS11c2VyOnNvbWUtcGFzc3dvcmQ=")
+			})
+
+			it("fails for invalid JSON", func() {
+				setEnv(keychain.EnvRegistryAuth, `gcr.io`)
+
+				_, err := keychain.Default.Resolve(name.Registry{})
+				h.AssertError(t, err, "parsing PACK_REGISTRY_AUTH")
+			})
+		})
+
+		when("PACK_CREDENTIAL_HELPERS is set", func() {
+			it.Before(func() {
+				if runtime.GOOS == "windows" {
+					t.Skip("credential helper scripts are shell scripts")
+				}
+				script := "#!/bin/sh\nread registry\nif [ \"$registry\" = gcr.io ]; then\n  echo '{\"Username\": \"some-user\", \"Secret\": \"some-password\"}'\nelse\n  echo 'credentials not found in native keychain'\n  exit 1\nfi\n"
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "docker-credential-some-helper"), []byte(script), 0755))
+				setEnv("PATH", tmpDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+				setEnv(keychain.EnvCredentialHelpers, "gcr.io=some-helper,ghcr.io=some-helper")
+			})
+
+			it("uses the credentials of the helper of the registry", func() {
+				h.AssertEq(t, resolve("gcr.io"), "Basic c29tZS11c2VyOnNvbWUtcGFzc3dvcmQ=")
+			})
+
+			it("accesses the registry anonymously when the helper has no credentials", func() {
+				anonymous, err := authn.Anonymous.Authorization()
+				h.AssertNil(t, err)
+				h.AssertEq(t, resolve("ghcr.io"), anonymous)
+			})
+
+			it("prefers PACK_REGISTRY_AUTH", func() {
+				setEnv(keychain.EnvRegistryAuth, `{"gcr.io": "Bearer some-token"}`)
+
+				h.AssertEq(t, resolve("gcr.io"), "Bearer some-token")
+			})
+
+			it("fails for invalid helpers", func() {
+				setEnv(keychain.EnvCredentialHelpers, "gcr.io")
+
+				_, err := keychain.Default.Resolve(name.Registry{})
+				h.AssertError(t, err, "invalid credential helper 'gcr.io' in PACK_CREDENTIAL_HELPERS")
+			})
+		})
+	})
+}
@@ -0,0 +1,188 @@
+package pack
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/archive"
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/git"
+	"github.com/buildpack/pack/kubernetes"
+	"github.com/buildpack/pack/style"
+)
+
+// KubernetesOptions configure builds running as jobs in a Kubernetes cluster
+type KubernetesOptions struct {
+	// Kubectl is the path of the kubectl binary, whose current context selects the cluster
+	Kubectl        string
+	Namespace      string
+	RegistrySecret string
+	ServiceAccount string
+	// WorkspaceClaim is a persistent volume claim the job keeps the workspace on, rather than an
+	// empty dir
+	WorkspaceClaim string
+	// Git is a repository the job clones the app source from, at GitRef when set, rather than
+	// uploading the app directory
+	Git    string
+	GitRef string
+}
+
+// BuildOnKubernetes builds an app image in a Kubernetes job rather than in the docker daemon. The
+// job publishes the image, as it has no daemon to export it to, and does not cache layers. Every
+// phase runs in the builder image, and the phases publishing the image are given the registry
+// secret, so the builder must be trusted.
+func (c *Client) BuildOnKubernetes(ctx context.Context, flags BuildFlags, opts KubernetesOptions) error {
+	if !flags.Publish {
+		return errors.New("builds on kubernetes must publish the image")
+	}
+	if len(flags.Env) > 0 || flags.EnvFile != "" || len(flags.Buildpacks) > 0 {
+		return errors.New("builds on kubernetes do not support build-time environment variables or buildpacks")
+	}
+	if flags.AppDir == AppDirStdin {
+		return errors.New("builds on kubernetes cannot read the app from stdin")
+	}
+	if err := checkOutput(&flags); err != nil {
+		return err
+	}
+
+	cfg, err := c.projectConfig(flags.AppDir)
+	if err != nil {
+		return err
+	}
+	builderName := flags.Builder
+	if builderName == "" {
+		builderName = cfg.DefaultBuilder
+	}
+	if !cfg.IsTrustedBuilder(builderName) {
+		return errors.Errorf("builds on kubernetes run every phase in the builder image, so builder %s must be trusted. Trust it with 'pack trust-builder %s'.", style.Symbol(builderName), builderName)
+	}
+	img, err := c.fetcher.FetchRemoteImage(builderName)
+	if err != nil {
+		return err
+	}
+	if found, err := img.Found(); err != nil {
+		return errors.Wrapf(err, "invalid builder %s", style.Symbol(builderName))
+	} else if !found {
+		return fmt.Errorf("remote builder %s does not exist", style.Symbol(builderName))
+	}
+
+	bldr := builder.NewBuilder(img, cfg)
+	lifecycleVersion, err := builderLifecycleVersion(bldr, builderName)
+	if err != nil {
+		return err
+	}
+	runImage := flags.RunImage
+	if runImage == "" {
+		if runImage, err = bldr.GetRunImageByRepoName(flags.RepoName); err != nil {
+			return err
+		}
+	}
+	if err := checkInsecureRegistries(cfg, flags.RepoName, runImage); err != nil {
+		return err
+	}
+
+	uid, gid, err := builderUidGid(img)
+	if err != nil {
+		return err
+	}
+	if url, ref, ok := git.ParseURL(flags.AppDir); ok && opts.Git == "" {
+		opts.Git, opts.GitRef = url, ref
+	}
+	name, err := jobName()
+	if err != nil {
+		return err
+	}
+	job := kubernetes.NewJob(kubernetes.JobConfig{
+		Name:             name,
+		Namespace:        opts.Namespace,
+		Builder:          builderName,
+		RunImage:         runImage,
+		RepoName:         flags.RepoName,
+		LifecycleVersion: lifecycleVersion,
+		UID:              uid,
+		GID:              gid,
+		Git:              opts.Git,
+		GitRef:           opts.GitRef,
+		UploadSource:     opts.Git == "",
+		RegistrySecret:   opts.RegistrySecret,
+		ServiceAccount:   opts.ServiceAccount,
+		WorkspaceClaim:   opts.WorkspaceClaim,
+	})
+
+	runner := &kubernetes.Runner{Kubectl: opts.Kubectl}
+	c.logger.Verbose("Running build as job %s", style.Symbol(name))
+	if opts.Git != "" {
+		return runner.Run(ctx, job, nil, c.logger.RawWriter())
+	}
+
+	appDir := flags.AppDir
+	if appDir == "" {
+		if appDir, err = os.Getwd(); err != nil {
+			return err
+		}
+	}
+	if appDir, err = filepath.Abs(appDir); err != nil {
+		return err
+	}
+	source, errChan := archive.CreateTarReader(appDir, ".", uid, gid, archive.WithSymlinks(flags.AppSymlinks))
+	defer source.Close()
+	if err := runner.Run(ctx, job, source, c.logger.RawWriter()); err != nil {
+		return err
+	}
+	source.Close()
+	if err := <-errChan; err != nil && err != io.ErrClosedPipe {
+		return errors.Wrapf(err, "reading app dir %s", style.Symbol(appDir))
+	}
+	return nil
+}
+
+// builderLifecycleVersion returns the version of the lifecycle of a builder, which is empty when the
+// builder does not record it, once builds are known to be able to run that lifecycle
+func builderLifecycleVersion(b *builder.Builder, name string) (string, error) {
+	metadata, err := b.GetMetadata()
+	if err != nil {
+		return "", err
+	}
+	var version, platformAPI string
+	if metadata.Lifecycle != nil {
+		version = metadata.Lifecycle.Version
+		if metadata.Lifecycle.API != nil {
+			platformAPI = metadata.Lifecycle.API.Platform
+		}
+	}
+	if err := build.CheckLifecycleCompatibility(version, platformAPI); err != nil {
+		return "", errors.Wrapf(err, "builder %s is incompatible", style.Symbol(name))
+	}
+	return version, nil
+}
+
+func builderUidGid(img interface{ Env(string) (string, error) }) (int, int, error) {
+	var ids [2]int
+	for i, key := range []string{"CNB_USER_ID", "CNB_GROUP_ID"} {
+		value, err := img.Env(key)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "reading builder env variables")
+		}
+		if ids[i], err = strconv.Atoi(value); err != nil {
+			return 0, 0, errors.Wrapf(err, "parsing %s of builder", key)
+		}
+	}
+	return ids[0], ids[1], nil
+}
+
+func jobName() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "pack-build-" + hex.EncodeToString(b), nil
+}
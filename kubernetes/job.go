@@ -0,0 +1,240 @@
+package kubernetes
+
+import (
+	"github.com/buildpack/pack/build"
+)
+
+// paths of the volumes in builder images, as used by the phases of build.PublishPhases
+const (
+	layersDir    = "/layers"
+	workspaceDir = "/workspace"
+)
+
+// SourceContainer is the name of the first container of a job, which puts the app source into
+// the workspace
+const SourceContainer = "source"
+
+// DefaultGitImage is the image cloning the app source when it comes from a Git repository
+const DefaultGitImage = "alpine/git"
+
+// JobConfig describes a build running as a Kubernetes job. The lifecycle phases run one after
+// another in containers of a single pod, sharing the workspace and layers volumes. As there is no
+// docker daemon in the pod, the app image is always published to a registry, and the restore and
+// cache phases, which keep the cache in the daemon, are skipped.
+type JobConfig struct {
+	Name      string
+	Namespace string
+	Builder   string
+	RunImage  string
+	RepoName  string
+	// LifecycleVersion is the version of the lifecycle of the builder, which selects the arguments
+	// of the phases
+	LifecycleVersion string
+	// UID and GID are the user and group of the builder, which the phases run as
+	UID int
+	GID int
+	// Git is the URL of a repository the app source is cloned from, at GitRef when set. Otherwise
+	// the app source is extracted from a tar archive written to the stdin of the source container,
+	// unless UploadSource is false, in which case the workspace volume already holds it.
+	Git          string
+	GitRef       string
+	GitImage     string
+	UploadSource bool
+	// WorkspaceClaim is a persistent volume claim holding the workspace. Defaults to an empty dir.
+	WorkspaceClaim string
+	// RegistrySecret is a secret with an 'auth' key, holding the value of CNB_REGISTRY_AUTH that
+	// grants access to the registries of the run image and the app image
+	RegistrySecret string
+	// ServiceAccount runs the pod, when set
+	ServiceAccount string
+}
+
+// Job is a Kubernetes batch/v1 Job manifest
+type Job struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   objectMeta `json:"metadata"`
+	Spec       jobSpec    `json:"spec"`
+}
+
+type objectMeta struct {
+	Name      string            `json:"name,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type jobSpec struct {
+	BackoffLimit int             `json:"backoffLimit"`
+	Template     podTemplateSpec `json:"template"`
+}
+
+type podTemplateSpec struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     podSpec    `json:"spec"`
+}
+
+type podSpec struct {
+	RestartPolicy      string             `json:"restartPolicy"`
+	ServiceAccountName string             `json:"serviceAccountName,omitempty"`
+	SecurityContext    podSecurityContext `json:"securityContext"`
+	InitContainers     []Container        `json:"initContainers"`
+	Containers         []Container        `json:"containers"`
+	Volumes            []volume           `json:"volumes"`
+}
+
+type podSecurityContext struct {
+	RunAsUser  int `json:"runAsUser"`
+	RunAsGroup int `json:"runAsGroup"`
+	FSGroup    int `json:"fsGroup"`
+}
+
+// Container is a container of the pod of a Job
+type Container struct {
+	Name         string        `json:"name"`
+	Image        string        `json:"image"`
+	Command      []string      `json:"command,omitempty"`
+	Args         []string      `json:"args,omitempty"`
+	Env          []envVar      `json:"env,omitempty"`
+	VolumeMounts []volumeMount `json:"volumeMounts"`
+	Stdin        bool          `json:"stdin,omitempty"`
+	StdinOnce    bool          `json:"stdinOnce,omitempty"`
+}
+
+type envVar struct {
+	Name      string        `json:"name"`
+	Value     string        `json:"value,omitempty"`
+	ValueFrom *envVarSource `json:"valueFrom,omitempty"`
+}
+
+type envVarSource struct {
+	SecretKeyRef secretKeySelector `json:"secretKeyRef"`
+}
+
+type secretKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+type volumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+}
+
+type volume struct {
+	Name                  string                 `json:"name"`
+	EmptyDir              *struct{}              `json:"emptyDir,omitempty"`
+	PersistentVolumeClaim *persistentVolumeClaim `json:"persistentVolumeClaim,omitempty"`
+}
+
+type persistentVolumeClaim struct {
+	ClaimName string `json:"claimName"`
+}
+
+// NewJob returns the manifest of a job running a build
+func NewJob(cfg JobConfig) *Job {
+	labels := map[string]string{"author": "pack"}
+
+	workspace := volume{Name: "workspace", EmptyDir: &struct{}{}}
+	if cfg.WorkspaceClaim != "" {
+		workspace = volume{Name: "workspace", PersistentVolumeClaim: &persistentVolumeClaim{ClaimName: cfg.WorkspaceClaim}}
+	}
+
+	var containers []Container
+	if source, ok := sourceContainer(cfg); ok {
+		containers = append(containers, source)
+	}
+	for _, p := range build.PublishPhases(cfg.LifecycleVersion, cfg.RepoName, cfg.RunImage, nil) {
+		containers = append(containers, phase(cfg, p))
+	}
+
+	return &Job{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Metadata: objectMeta{
+			Name:      cfg.Name,
+			Namespace: cfg.Namespace,
+			Labels:    labels,
+		},
+		Spec: jobSpec{
+			// a failed build fails again when retried
+			BackoffLimit: 0,
+			Template: podTemplateSpec{
+				Metadata: objectMeta{Labels: labels},
+				Spec: podSpec{
+					RestartPolicy:      "Never",
+					ServiceAccountName: cfg.ServiceAccount,
+					SecurityContext: podSecurityContext{
+						RunAsUser:  cfg.UID,
+						RunAsGroup: cfg.GID,
+						FSGroup:    cfg.GID,
+					},
+					InitContainers: containers[:len(containers)-1],
+					Containers:     containers[len(containers)-1:],
+					Volumes: []volume{
+						workspace,
+						{Name: "layers", EmptyDir: &struct{}{}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Containers returns the init containers and containers of the job, in the order they run
+func (j *Job) Containers() []Container {
+	spec := j.Spec.Template.Spec
+	return append(append([]Container{}, spec.InitContainers...), spec.Containers...)
+}
+
+func sourceContainer(cfg JobConfig) (Container, bool) {
+	mounts := []volumeMount{{Name: "workspace", MountPath: workspaceDir}}
+	switch {
+	case cfg.Git != "":
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if cfg.GitRef != "" {
+			args = append(args, "--branch", cfg.GitRef)
+		}
+		image := cfg.GitImage
+		if image == "" {
+			image = DefaultGitImage
+		}
+		return Container{
+			Name:         SourceContainer,
+			Image:        image,
+			Command:      []string{"git"},
+			Args:         append(args, "--", cfg.Git, workspaceDir),
+			Env:          []envVar{{Name: "HOME", Value: "/tmp"}},
+			VolumeMounts: mounts,
+		}, true
+	case cfg.UploadSource:
+		return Container{
+			Name:         SourceContainer,
+			Image:        cfg.Builder,
+			Command:      []string{"tar", "-x", "-m", "--no-overwrite-dir", "-f", "-", "-C", workspaceDir},
+			VolumeMounts: mounts,
+			Stdin:        true,
+			StdinOnce:    true,
+		}, true
+	}
+	return Container{}, false
+}
+
+func phase(cfg JobConfig, p build.PublishPhase) Container {
+	c := Container{
+		Name:    p.Name,
+		Image:   cfg.Builder,
+		Command: []string{p.Path},
+		Args:    p.Args,
+		VolumeMounts: []volumeMount{
+			{Name: "workspace", MountPath: workspaceDir},
+			{Name: "layers", MountPath: layersDir},
+		},
+	}
+	if p.RegistryAccess && cfg.RegistrySecret != "" {
+		c.Env = []envVar{{
+			Name:      "CNB_REGISTRY_AUTH",
+			ValueFrom: &envVarSource{SecretKeyRef: secretKeySelector{Name: cfg.RegistrySecret, Key: "auth"}},
+		}}
+	}
+	return c
+}
@@ -0,0 +1,100 @@
+package kubernetes_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/kubernetes"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestJob(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Job", testJob, spec.Report(report.Terminal{}))
+}
+
+func testJob(t *testing.T, when spec.G, it spec.S) {
+	var cfg kubernetes.JobConfig
+
+	it.Before(func() {
+		cfg = kubernetes.JobConfig{
+			Name:         "some-job",
+			Namespace:    "some-namespace",
+			Builder:      "some/builder",
+			RunImage:     "some/run",
+			RepoName:     "some/app",
+			UID:          1000,
+			GID:          1001,
+			UploadSource: true,
+		}
+	})
+
+	names := func(job *kubernetes.Job) []string {
+		var names []string
+		for _, c := range job.Containers() {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	it("runs the phases one after another in the builder image", func() {
+		job := kubernetes.NewJob(cfg)
+		h.AssertEq(t, names(job), []string{"source", "detector", "analyzer", "builder", "exporter"})
+
+		containers := job.Containers()
+		h.AssertEq(t, containers[0].Stdin, true)
+		for _, c := range containers {
+			h.AssertEq(t, c.Image, "some/builder")
+		}
+		h.AssertEq(t, containers[4].Command, []string{"/lifecycle/exporter"})
+		h.AssertEq(t, containers[4].Args, []string{"-image", "some/run", "-layers", "/layers", "-app", "/workspace", "-group", "/layers/group.toml", "some/app"})
+	})
+
+	it("runs the phases with the arguments of the lifecycle version", func() {
+		cfg.LifecycleVersion = "0.1.0"
+		containers := kubernetes.NewJob(cfg).Containers()
+		h.AssertEq(t, containers[2].Args, []string{"-launch", "/layers", "-group", "/layers/group.toml", "some/app"})
+		h.AssertEq(t, containers[3].Args[2:4], []string{"-launch", "/layers"})
+	})
+
+	it("runs the pod as the builder user", func() {
+		b, err := json.Marshal(kubernetes.NewJob(cfg))
+		h.AssertNil(t, err)
+		h.AssertContains(t, string(b), `"securityContext":{"runAsUser":1000,"runAsGroup":1001,"fsGroup":1001}`)
+		h.AssertContains(t, string(b), `"metadata":{"name":"some-job","namespace":"some-namespace"`)
+		h.AssertContains(t, string(b), `"restartPolicy":"Never"`)
+	})
+
+	it("clones git repositories", func() {
+		cfg.Git = "https://example.com/some/repo.git"
+		cfg.GitRef = "some-branch"
+		source := kubernetes.NewJob(cfg).Containers()[0]
+		h.AssertEq(t, source.Image, kubernetes.DefaultGitImage)
+		h.AssertEq(t, source.Stdin, false)
+		h.AssertEq(t, source.Args, []string{"clone", "--quiet", "--depth", "1", "--branch", "some-branch", "--", "https://example.com/some/repo.git", "/workspace"})
+	})
+
+	it("skips the source container when the workspace holds the app source", func() {
+		cfg.UploadSource = false
+		cfg.WorkspaceClaim = "some-claim"
+		job := kubernetes.NewJob(cfg)
+		h.AssertEq(t, names(job), []string{"detector", "analyzer", "builder", "exporter"})
+
+		b, err := json.Marshal(job)
+		h.AssertNil(t, err)
+		h.AssertContains(t, string(b), `{"name":"workspace","persistentVolumeClaim":{"claimName":"some-claim"}}`)
+	})
+
+	it("gives the phases accessing registries the registry secret", func() {
+		cfg.RegistrySecret = "some-secret"
+		b, err := json.Marshal(kubernetes.NewJob(cfg).Containers()[2])
+		h.AssertNil(t, err)
+		h.AssertContains(t, string(b), `"env":[{"name":"CNB_REGISTRY_AUTH","valueFrom":{"secretKeyRef":{"name":"some-secret","key":"auth"}}}]`)
+
+		h.AssertEq(t, len(kubernetes.NewJob(cfg).Containers()[1].Env), 0)
+	})
+}
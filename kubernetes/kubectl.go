@@ -0,0 +1,195 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// Runner runs jobs with kubectl, using its current context unless a job sets a namespace
+type Runner struct {
+	// Kubectl is the path of the kubectl binary. Defaults to 'kubectl' on the PATH.
+	Kubectl string
+	// PollInterval is how often the state of the pod of a job is checked. Defaults to a second.
+	PollInterval time.Duration
+}
+
+type podStatus struct {
+	Status struct {
+		Phase                 string            `json:"phase"`
+		InitContainerStatuses []containerStatus `json:"initContainerStatuses"`
+		ContainerStatuses     []containerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type containerStatus struct {
+	Name  string `json:"name"`
+	State struct {
+		Waiting *struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"waiting"`
+		Running    *struct{} `json:"running"`
+		Terminated *struct {
+			ExitCode int    `json:"exitCode"`
+			Reason   string `json:"reason"`
+		} `json:"terminated"`
+	} `json:"state"`
+}
+
+// Run creates the job, writes source, when not nil, to the stdin of its source container, and
+// streams the output of its containers to out until the job finishes. The job is deleted when it
+// finishes, fails or ctx is canceled.
+func (r *Runner) Run(ctx context.Context, job *Job, source io.Reader, out io.Writer) error {
+	manifest, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if _, err := r.kubectl(ctx, job, bytes.NewReader(manifest), nil, "create", "-f", "-"); err != nil {
+		return errors.Wrapf(err, "creating job %s", style.Symbol(job.Metadata.Name))
+	}
+	defer func() {
+		// the context may be canceled already, which must not keep the job from being deleted
+		r.kubectl(context.Background(), job, nil, nil, "delete", "job", job.Metadata.Name, "--ignore-not-found", "--wait=false")
+	}()
+
+	pod, err := r.findPod(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range job.Containers() {
+		status, err := r.waitForContainer(ctx, job, pod, c.Name)
+		if err != nil {
+			return err
+		}
+		if c.Stdin && status.State.Running != nil {
+			if source == nil {
+				return fmt.Errorf("container %s expects the app source on its stdin", style.Symbol(c.Name))
+			}
+			if _, err := r.kubectl(ctx, job, source, nil, "attach", "--stdin", pod, "-c", c.Name); err != nil {
+				return errors.Wrapf(err, "uploading app source to %s", style.Symbol(pod))
+			}
+		}
+		if _, err := r.kubectl(ctx, job, nil, out, "logs", "--follow", pod, "-c", c.Name); err != nil {
+			return errors.Wrapf(err, "reading logs of %s", style.Symbol(c.Name))
+		}
+		if status, err = r.waitForContainer(ctx, job, pod, c.Name, terminated); err != nil {
+			return err
+		}
+		if code := status.State.Terminated.ExitCode; code != 0 {
+			return fmt.Errorf("%s failed with exit code %d", style.Symbol(c.Name), code)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) findPod(ctx context.Context, job *Job) (string, error) {
+	for {
+		name, err := r.kubectl(ctx, job, nil, nil, "get", "pods",
+			"--selector", "job-name="+job.Metadata.Name,
+			"--output", "jsonpath={.items[0].metadata.name}",
+		)
+		if err != nil && !strings.Contains(err.Error(), "array index out of bounds") {
+			return "", errors.Wrapf(err, "finding pod of job %s", style.Symbol(job.Metadata.Name))
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			return name, nil
+		}
+		if err := r.sleep(ctx); err != nil {
+			return "", err
+		}
+	}
+}
+
+func terminated(s containerStatus) bool {
+	return s.State.Terminated != nil
+}
+
+func started(s containerStatus) bool {
+	return s.State.Running != nil || s.State.Terminated != nil
+}
+
+// waitForContainer waits until the container has started, or until done returns true when given
+func (r *Runner) waitForContainer(ctx context.Context, job *Job, pod, container string, done ...func(containerStatus) bool) (containerStatus, error) {
+	until := started
+	if len(done) > 0 {
+		until = done[0]
+	}
+	for {
+		output, err := r.kubectl(ctx, job, nil, nil, "get", "pod", pod, "--output", "json")
+		if err != nil {
+			return containerStatus{}, errors.Wrapf(err, "getting status of %s", style.Symbol(pod))
+		}
+		var p podStatus
+		if err := json.Unmarshal([]byte(output), &p); err != nil {
+			return containerStatus{}, errors.Wrapf(err, "parsing status of %s", style.Symbol(pod))
+		}
+		for _, s := range append(p.Status.InitContainerStatuses, p.Status.ContainerStatuses...) {
+			if s.Name != container {
+				continue
+			}
+			if until(s) {
+				return s, nil
+			}
+			if w := s.State.Waiting; w != nil && (strings.HasPrefix(w.Reason, "Err") || strings.HasSuffix(w.Reason, "BackOff")) {
+				return s, fmt.Errorf("%s cannot start: %s %s", style.Symbol(container), w.Reason, w.Message)
+			}
+		}
+		if p.Status.Phase == "Failed" {
+			return containerStatus{}, fmt.Errorf("pod %s failed before %s finished", style.Symbol(pod), style.Symbol(container))
+		}
+		if err := r.sleep(ctx); err != nil {
+			return containerStatus{}, err
+		}
+	}
+}
+
+func (r *Runner) sleep(ctx context.Context) error {
+	interval := r.PollInterval
+	if interval == 0 {
+		interval = time.Second
+	}
+	select {
+	case <-time.After(interval):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// kubectl runs kubectl in the namespace of job, returning its output unless out is set
+func (r *Runner) kubectl(ctx context.Context, job *Job, stdin io.Reader, out io.Writer, args ...string) (string, error) {
+	path := r.Kubectl
+	if path == "" {
+		path = "kubectl"
+	}
+	if ns := job.Metadata.Namespace; ns != "" {
+		args = append([]string{"--namespace", ns}, args...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	if out != nil {
+		cmd.Stdout = out
+	}
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", errors.Wrap(err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,235 @@
+package kubernetes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/kubernetes"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestRunner(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Runner", testRunner, spec.Report(report.Terminal{}))
+}
+
+// fakeKubectl logs its arguments to calls, keeps the job it creates and the source attached to its
+// pod, and reports the status of pod 'some-pod' from status.json, or from status-before-upload.json
+// until the source is attached when that file exists. Creating the job fails with the contents of
+// create-error when that file exists.
+const fakeKubectl = `#!/bin/sh
+dir=$(dirname "$0")
+echo "$*" >> "$dir/calls"
+for last; do :; done
+case "$*" in
+*"create -f -"*)
+  cat > "$dir/job.json"
+  if [ -f "$dir/create-error" ]; then cat "$dir/create-error" >&2; exit 1; fi ;;
+*"get pods"*)
+  echo some-pod ;;
+*"get pod some-pod"*)
+  if [ -f "$dir/source" ] || [ ! -f "$dir/status-before-upload.json" ]; then cat "$dir/status.json"; else cat "$dir/status-before-upload.json"; fi ;;
+*"attach --stdin"*)
+  cat > "$dir/source" ;;
+*"logs --follow"*)
+  echo "output of $last" ;;
+esac
+`
+
+const (
+	running = `{"running":{}}`
+	exited  = `{"terminated":{"exitCode":0}}`
+)
+
+func testRunner(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+		runner *kubernetes.Runner
+		cfg    kubernetes.JobConfig
+		out    bytes.Buffer
+	)
+
+	it.Before(func() {
+		if runtime.GOOS == "windows" {
+			t.Skip("kubectl is faked with a shell script")
+		}
+		var err error
+		tmpDir, err = ioutil.TempDir("", "kubectl")
+		h.AssertNil(t, err)
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "kubectl"), []byte(fakeKubectl), 0755))
+		runner = &kubernetes.Runner{
+			Kubectl:      filepath.Join(tmpDir, "kubectl"),
+			PollInterval: 10 * time.Millisecond,
+		}
+		cfg = kubernetes.JobConfig{
+			Name:      "some-job",
+			Namespace: "some-namespace",
+			Builder:   "some/builder",
+			RunImage:  "some/run",
+			RepoName:  "some/app",
+			Git:       "https://example.com/some/app.git",
+		}
+		out.Reset()
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	// writeStatus writes the status of the pod with the states of its containers, which are exited
+	// unless given
+	writeStatus := func(file, phase string, job *kubernetes.Job, states map[string]string) {
+		t.Helper()
+		var statuses []string
+		for _, c := range job.Containers() {
+			state, ok := states[c.Name]
+			if !ok {
+				state = exited
+			}
+			statuses = append(statuses, fmt.Sprintf(`{"name":%q,"state":%s}`, c.Name, state))
+		}
+		status := fmt.Sprintf(`{"status":{"phase":%q,"containerStatuses":[%s]}}`, phase, strings.Join(statuses, ","))
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, file), []byte(status), 0644))
+	}
+
+	calls := func() []string {
+		t.Helper()
+		b, err := ioutil.ReadFile(filepath.Join(tmpDir, "calls"))
+		h.AssertNil(t, err)
+		return strings.Split(strings.TrimSpace(string(b)), "\n")
+	}
+
+	when("#Run", func() {
+		it("creates the job in its namespace, streams the output of each container and deletes the job", func() {
+			job := kubernetes.NewJob(cfg)
+			writeStatus("status.json", "Succeeded", job, nil)
+
+			h.AssertNil(t, runner.Run(context.TODO(), job, nil, &out))
+
+			var expected string
+			for _, c := range job.Containers() {
+				expected += "output of " + c.Name + "\n"
+			}
+			h.AssertEq(t, out.String(), expected)
+
+			var created kubernetes.Job
+			b, err := ioutil.ReadFile(filepath.Join(tmpDir, "job.json"))
+			h.AssertNil(t, err)
+			h.AssertNil(t, json.Unmarshal(b, &created))
+			h.AssertEq(t, created.Metadata.Name, "some-job")
+
+			calls := calls()
+			h.AssertEq(t, calls[0], "--namespace some-namespace create -f -")
+			h.AssertEq(t, calls[1], "--namespace some-namespace get pods --selector job-name=some-job --output jsonpath={.items[0].metadata.name}")
+			h.AssertContains(t, strings.Join(calls, "\n"), "--namespace some-namespace logs --follow some-pod -c "+kubernetes.SourceContainer)
+			h.AssertEq(t, calls[len(calls)-1], "--namespace some-namespace delete job some-job --ignore-not-found --wait=false")
+		})
+
+		it("uses the current namespace of kubectl when the job has none", func() {
+			cfg.Namespace = ""
+			job := kubernetes.NewJob(cfg)
+			writeStatus("status.json", "Succeeded", job, nil)
+
+			h.AssertNil(t, runner.Run(context.TODO(), job, nil, &out))
+
+			h.AssertEq(t, calls()[0], "create -f -")
+		})
+
+		it("uploads the source to the stdin of the source container once it runs", func() {
+			cfg.Git = ""
+			cfg.UploadSource = true
+			job := kubernetes.NewJob(cfg)
+			writeStatus("status-before-upload.json", "Pending", job, map[string]string{kubernetes.SourceContainer: running})
+			writeStatus("status.json", "Succeeded", job, nil)
+
+			h.AssertNil(t, runner.Run(context.TODO(), job, strings.NewReader("some-source"), &out))
+
+			source, err := ioutil.ReadFile(filepath.Join(tmpDir, "source"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(source), "some-source")
+			h.AssertContains(t, strings.Join(calls(), "\n"), "--namespace some-namespace attach --stdin some-pod -c "+kubernetes.SourceContainer)
+		})
+
+		it("fails when the source container expects a source that is not given", func() {
+			cfg.Git = ""
+			cfg.UploadSource = true
+			job := kubernetes.NewJob(cfg)
+			writeStatus("status.json", "Pending", job, map[string]string{kubernetes.SourceContainer: running})
+
+			err := runner.Run(context.TODO(), job, nil, &out)
+			h.AssertError(t, err, "container '"+kubernetes.SourceContainer+"' expects the app source on its stdin")
+		})
+
+		it("fails with the exit code of a failed container, without running the next ones", func() {
+			job := kubernetes.NewJob(cfg)
+			failed := job.Containers()[1].Name
+			next := job.Containers()[2].Name
+			writeStatus("status.json", "Failed", job, map[string]string{
+				failed: `{"terminated":{"exitCode":3,"reason":"Error"}}`,
+				next:   `{"waiting":{"reason":"PodInitializing"}}`,
+			})
+
+			err := runner.Run(context.TODO(), job, nil, &out)
+			h.AssertError(t, err, fmt.Sprintf("'%s' failed with exit code 3", failed))
+			h.AssertContains(t, out.String(), "output of "+failed)
+			h.AssertNotContains(t, out.String(), "output of "+next)
+			calls := calls()
+			h.AssertEq(t, calls[len(calls)-1], "--namespace some-namespace delete job some-job --ignore-not-found --wait=false")
+		})
+
+		it("fails when a container cannot start", func() {
+			job := kubernetes.NewJob(cfg)
+			writeStatus("status.json", "Pending", job, map[string]string{
+				kubernetes.SourceContainer: `{"waiting":{"reason":"ErrImagePull","message":"some pull error"}}`,
+			})
+
+			err := runner.Run(context.TODO(), job, nil, &out)
+			h.AssertError(t, err, "'"+kubernetes.SourceContainer+"' cannot start: ErrImagePull some pull error")
+		})
+
+		it("fails when the pod fails before the container finishes", func() {
+			job := kubernetes.NewJob(cfg)
+			writeStatus("status.json", "Failed", job, map[string]string{
+				kubernetes.SourceContainer: `{"waiting":{"reason":"PodInitializing"}}`,
+			})
+
+			err := runner.Run(context.TODO(), job, nil, &out)
+			h.AssertError(t, err, "pod 'some-pod' failed before '"+kubernetes.SourceContainer+"' finished")
+		})
+
+		it("returns the error output of kubectl", func() {
+			job := kubernetes.NewJob(cfg)
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "create-error"), []byte("some create error"), 0644))
+
+			err := runner.Run(context.TODO(), job, nil, &out)
+			h.AssertError(t, err, "creating job 'some-job': some create error")
+		})
+
+		it("stops waiting and deletes the job when the context is canceled", func() {
+			job := kubernetes.NewJob(cfg)
+			writeStatus("status.json", "Pending", job, map[string]string{
+				kubernetes.SourceContainer: `{"waiting":{"reason":"ContainerCreating"}}`,
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			err := runner.Run(ctx, job, nil, &out)
+			h.AssertError(t, err, context.DeadlineExceeded.Error())
+			calls := calls()
+			h.AssertEq(t, calls[len(calls)-1], "--namespace some-namespace delete job some-job --ignore-not-found --wait=false")
+		})
+	})
+}
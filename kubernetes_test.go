@@ -0,0 +1,214 @@
+package pack_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	imgtest "github.com/buildpack/lifecycle/testhelpers"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/kubernetes"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestBuildOnKubernetes(t *testing.T) {
+	spec.Run(t, "BuildOnKubernetes", testBuildOnKubernetes, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuildOnKubernetes(t *testing.T, when spec.G, it spec.S) {
+	var (
+		mockController *gomock.Controller
+		mockFetcher    *mocks.MockFetcher
+		cfg            *config.Config
+		appDir         string
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockFetcher = mocks.NewMockFetcher(mockController)
+		cfg = &config.Config{}
+
+		var err error
+		appDir, err = ioutil.TempDir("", "pack.kubernetes.test")
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+		os.RemoveAll(appDir)
+	})
+
+	newClient := func() *pack.Client {
+		client, err := pack.NewClient(
+			pack.WithConfig(cfg),
+			pack.WithDocker(&docker.Client{}),
+			pack.WithFetcher(mockFetcher),
+		)
+		h.AssertNil(t, err)
+		return client
+	}
+
+	it("requires a trusted builder", func() {
+		err := newClient().BuildOnKubernetes(context.TODO(), pack.BuildFlags{
+			AppDir:   appDir,
+			Builder:  "some/builder",
+			RepoName: "some/app",
+			Publish:  true,
+		}, pack.KubernetesOptions{})
+		h.AssertError(t, err, "builder 'some/builder' must be trusted")
+	})
+
+	it("requires publishing the image", func() {
+		err := newClient().BuildOnKubernetes(context.TODO(), pack.BuildFlags{
+			AppDir:   appDir,
+			Builder:  "some/builder",
+			RepoName: "some/app",
+		}, pack.KubernetesOptions{})
+		h.AssertError(t, err, "builds on kubernetes must publish the image")
+	})
+
+	when("kubectl runs the job", func() {
+		var (
+			kubectlDir string
+			outBuf     bytes.Buffer
+		)
+
+		it.Before(func() {
+			if runtime.GOOS == "windows" {
+				t.Skip("kubectl is faked with a shell script")
+			}
+			var err error
+			kubectlDir, err = ioutil.TempDir("", "pack.kubernetes.kubectl")
+			h.AssertNil(t, err)
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(kubectlDir, "kubectl"), []byte(fakeKubectl), 0755))
+
+			cfg.TrustedBuilders = []string{"some/builder"}
+			builderImage := imgtest.NewFakeImage(t, "some/builder", "", "")
+			h.AssertNil(t, builderImage.SetLabel("io.buildpacks.builder.metadata", `{"stack":{"runImage":{"image":"some/run"}},"lifecycle":{"version":"0.6.0"}}`))
+			h.AssertNil(t, builderImage.SetEnv("CNB_USER_ID", "1000"))
+			h.AssertNil(t, builderImage.SetEnv("CNB_GROUP_ID", "1001"))
+			mockFetcher.EXPECT().FetchRemoteImage("some/builder").Return(builderImage, nil)
+			outBuf.Reset()
+		})
+
+		it.After(func() {
+			os.RemoveAll(kubectlDir)
+		})
+
+		build := func(flags pack.BuildFlags, opts pack.KubernetesOptions) error {
+			client, err := pack.NewClient(
+				pack.WithConfig(cfg),
+				pack.WithDocker(&docker.Client{}),
+				pack.WithFetcher(mockFetcher),
+				pack.WithLogger(logging.NewLogger(&outBuf, &outBuf, false, false)),
+			)
+			h.AssertNil(t, err)
+			flags.Builder = "some/builder"
+			flags.RepoName = "some/app"
+			flags.Publish = true
+			opts.Kubectl = filepath.Join(kubectlDir, "kubectl")
+			return client.BuildOnKubernetes(context.TODO(), flags, opts)
+		}
+
+		createdJob := func() kubernetes.Job {
+			t.Helper()
+			b, err := ioutil.ReadFile(filepath.Join(kubectlDir, "job.json"))
+			h.AssertNil(t, err)
+			var job kubernetes.Job
+			h.AssertNil(t, json.Unmarshal(b, &job))
+			return job
+		}
+
+		it("runs the phases of the builder in a job, streaming their output", func() {
+			h.AssertNil(t, build(pack.BuildFlags{AppDir: "https://example.com/some/app.git#some-branch"}, pack.KubernetesOptions{
+				Namespace:      "some-namespace",
+				RegistrySecret: "some-secret",
+			}))
+
+			job := createdJob()
+			h.AssertEq(t, job.Metadata.Namespace, "some-namespace")
+			containers := job.Containers()
+			h.AssertEq(t, containers[0].Args, []string{"clone", "--quiet", "--depth", "1", "--branch", "some-branch", "--", "https://example.com/some/app.git", "/workspace"})
+			exporter := containers[len(containers)-1]
+			h.AssertEq(t, exporter.Image, "some/builder")
+			h.AssertContains(t, strings.Join(exporter.Args, " "), "-image some/run")
+			h.AssertContains(t, strings.Join(exporter.Args, " "), "some/app")
+			h.AssertEq(t, job.Spec.Template.Spec.SecurityContext.RunAsUser, 1000)
+			h.AssertContains(t, outBuf.String(), "output of detector\n")
+			h.AssertContains(t, outBuf.String(), "output of exporter\n")
+		})
+
+		it("uploads the app directory to the job", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "some-file"), []byte("some-contents"), 0644))
+
+			h.AssertNil(t, build(pack.BuildFlags{AppDir: appDir}, pack.KubernetesOptions{}))
+
+			f, err := os.Open(filepath.Join(kubectlDir, "source"))
+			h.AssertNil(t, err)
+			defer f.Close()
+			tr := tar.NewReader(f)
+			var found bool
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				h.AssertNil(t, err)
+				if filepath.Base(hdr.Name) == "some-file" {
+					found = true
+					h.AssertEq(t, hdr.Uid, 1000)
+					h.AssertEq(t, hdr.Gid, 1001)
+				}
+			}
+			h.AssertEq(t, found, true)
+		})
+
+		it("fails when a phase of the job fails", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(kubectlDir, "exporter-exit-code"), []byte("1"), 0644))
+
+			err := build(pack.BuildFlags{AppDir: appDir}, pack.KubernetesOptions{})
+			h.AssertError(t, err, "'exporter' failed with exit code 1")
+		})
+	})
+}
+
+// fakeKubectl keeps the job it creates and the source attached to its pod, whose containers finish
+// at once, the exporter with the exit code in exporter-exit-code when that file exists, and writes
+// 'output of <container>' as the logs of each
+const fakeKubectl = `#!/bin/sh
+dir=$(dirname "$0")
+for last; do :; done
+case "$*" in
+*"create -f -"*)
+  cat > "$dir/job.json" ;;
+*"get pods"*)
+  echo some-pod ;;
+*"get pod some-pod"*)
+  src='{"terminated":{"exitCode":0}}'
+  if grep -q '"stdin":true' "$dir/job.json" && [ ! -f "$dir/source" ]; then src='{"running":{}}'; fi
+  code=0
+  if [ -f "$dir/exporter-exit-code" ]; then code=$(cat "$dir/exporter-exit-code"); fi
+  done='{"terminated":{"exitCode":0}}'
+  echo "{\"status\":{\"phase\":\"Running\",\"containerStatuses\":[{\"name\":\"source\",\"state\":$src},{\"name\":\"detector\",\"state\":$done},{\"name\":\"analyzer\",\"state\":$done},{\"name\":\"builder\",\"state\":$done},{\"name\":\"exporter\",\"state\":{\"terminated\":{\"exitCode\":$code}}}]}}" ;;
+*"attach --stdin"*)
+  cat > "$dir/source" ;;
+*"logs --follow"*)
+  echo "output of $last" ;;
+esac
+`
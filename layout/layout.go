@@ -0,0 +1,112 @@
+package layout
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+)
+
+// RefNameAnnotation names the image in the index of a layout
+const RefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// Write writes img to dir as an OCI image layout, naming it refName in the index. Layers are
+// compressed as they are written, so images read from a `docker save` tarball are only compressed once.
+func Write(dir string, img v1.Image, refName string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755); err != nil {
+		return err
+	}
+
+	rawConfig, err := img.RawConfigFile()
+	if err != nil {
+		return errors.Wrap(err, "reading image config")
+	}
+	config, err := writeBlob(dir, bytes.NewReader(rawConfig), types.OCIConfigJSON)
+	if err != nil {
+		return err
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return errors.Wrap(err, "reading image layers")
+	}
+	manifest := v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config:        config,
+		Layers:        []v1.Descriptor{},
+	}
+	for _, layer := range layers {
+		desc, err := writeLayer(dir, layer)
+		if err != nil {
+			return err
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+
+	rawManifest, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	manifestDesc, err := writeBlob(dir, bytes.NewReader(rawManifest), types.OCIManifestSchema1)
+	if err != nil {
+		return err
+	}
+	if refName != "" {
+		manifestDesc.Annotations = map[string]string{RefNameAnnotation: refName}
+	}
+
+	index, err := json.Marshal(v1.IndexManifest{
+		SchemaVersion: 2,
+		Manifests:     []v1.Descriptor{manifestDesc},
+	})
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "index.json"), index, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0644)
+}
+
+func writeLayer(dir string, layer v1.Layer) (v1.Descriptor, error) {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return v1.Descriptor{}, errors.Wrap(err, "compressing layer")
+	}
+	defer rc.Close()
+	return writeBlob(dir, rc, types.OCILayer)
+}
+
+// writeBlob stores the contents of r under their digest, returning their descriptor
+func writeBlob(dir string, r io.Reader, mediaType types.MediaType) (v1.Descriptor, error) {
+	blobs := filepath.Join(dir, "blobs", "sha256")
+	tmp, err := ioutil.TempFile(blobs, "blob")
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), r)
+	if err != nil {
+		return v1.Descriptor{}, errors.Wrap(err, "writing blob")
+	}
+	if err := tmp.Close(); err != nil {
+		return v1.Descriptor{}, err
+	}
+	digest := v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(hasher.Sum(nil))}
+	if err := os.Rename(tmp.Name(), filepath.Join(blobs, digest.Hex)); err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{MediaType: mediaType, Size: size, Digest: digest}, nil
+}
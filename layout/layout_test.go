@@ -0,0 +1,90 @@
+package layout_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/layout"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestLayout(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Layout", testLayout, spec.Report(report.Terminal{}))
+}
+
+func testLayout(t *testing.T, when spec.G, it spec.S) {
+	var tmpDir string
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "pack.layout.test")
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	readJSON := func(path string, v interface{}) {
+		t.Helper()
+		contents, err := ioutil.ReadFile(path)
+		h.AssertNil(t, err)
+		h.AssertNil(t, json.Unmarshal(contents, v))
+	}
+
+	blob := func(digest v1.Hash) string {
+		return filepath.Join(tmpDir, "blobs", digest.Algorithm, digest.Hex)
+	}
+
+	when("#Write", func() {
+		it("writes the image as an OCI image layout", func() {
+			img, err := random.Image(100, 2)
+			h.AssertNil(t, err)
+
+			h.AssertNil(t, layout.Write(tmpDir, img, "some/app"))
+
+			var version map[string]string
+			readJSON(filepath.Join(tmpDir, "oci-layout"), &version)
+			h.AssertEq(t, version["imageLayoutVersion"], "1.0.0")
+
+			var index v1.IndexManifest
+			readJSON(filepath.Join(tmpDir, "index.json"), &index)
+			h.AssertEq(t, len(index.Manifests), 1)
+			h.AssertEq(t, index.Manifests[0].MediaType, types.OCIManifestSchema1)
+			h.AssertEq(t, index.Manifests[0].Annotations[layout.RefNameAnnotation], "some/app")
+
+			var manifest v1.Manifest
+			readJSON(blob(index.Manifests[0].Digest), &manifest)
+			h.AssertEq(t, manifest.Config.MediaType, types.OCIConfigJSON)
+
+			rawConfig, err := img.RawConfigFile()
+			h.AssertNil(t, err)
+			config, err := ioutil.ReadFile(blob(manifest.Config.Digest))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(config), string(rawConfig))
+
+			layers, err := img.Layers()
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(manifest.Layers), len(layers))
+			for i, layer := range layers {
+				digest, err := layer.Digest()
+				h.AssertNil(t, err)
+				h.AssertEq(t, manifest.Layers[i].Digest, digest)
+				h.AssertEq(t, manifest.Layers[i].MediaType, types.OCILayer)
+				_, err = os.Stat(blob(digest))
+				h.AssertNil(t, err)
+			}
+		})
+	})
+}
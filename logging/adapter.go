@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// The functions below let code that accepts any logger with the usual methods, rather than a
+// *Logger, still write streams of output such as container logs and pull progress. A *Logger
+// provides its own writers; other loggers receive each complete line as a verbose message.
+
+type verboseLogger interface {
+	Verbose(format string, a ...interface{})
+}
+
+type debugLogger interface {
+	Debug(format string, a ...interface{})
+}
+
+// VerboseWriter returns a writer for verbose output, with each line prefixed by prefix when not empty
+func VerboseWriter(l verboseLogger, prefix string) io.Writer {
+	if logger, ok := l.(*Logger); ok {
+		if prefix == "" {
+			return logger.VerboseWriter()
+		}
+		return logger.VerboseWriter().WithPrefix(prefix)
+	}
+	return newLineWriter(l, prefix)
+}
+
+// VerboseErrorWriter is like VerboseWriter, but writes to the error output of a *Logger
+func VerboseErrorWriter(l verboseLogger, prefix string) io.Writer {
+	if logger, ok := l.(*Logger); ok {
+		if prefix == "" {
+			return logger.VerboseErrorWriter()
+		}
+		return logger.VerboseErrorWriter().WithPrefix(prefix)
+	}
+	return newLineWriter(l, prefix)
+}
+
+// RawVerboseWriter returns a writer for undecorated verbose output, such as progress displays
+func RawVerboseWriter(l verboseLogger) io.Writer {
+	if logger, ok := l.(*Logger); ok {
+		return logger.RawVerboseWriter()
+	}
+	return newLineWriter(l, "")
+}
+
+// SubsystemLogger returns the logger for debug messages of the named subsystem. Other loggers
+// than *Logger receive them as debug messages prefixed with the subsystem name.
+func SubsystemLogger(l debugLogger, name string) interface {
+	Debug(format string, a ...interface{})
+} {
+	switch logger := l.(type) {
+	case *Logger:
+		if logger == nil {
+			return nil
+		}
+		return logger.Subsystem(name)
+	case nil:
+		return nil
+	default:
+		return &subsystemLogger{logger: l, name: name}
+	}
+}
+
+// IsDebugEnabled reports whether debug messages may be shown by l, so that callers can skip
+// preparing expensive ones. It is always true for other loggers than *Logger.
+func IsDebugEnabled(l interface{}) bool {
+	if logger, ok := l.(*Logger); ok {
+		return logger.IsEnabled(LevelDebug)
+	}
+	return true
+}
+
+type subsystemLogger struct {
+	logger debugLogger
+	name   string
+}
+
+func (l *subsystemLogger) Debug(format string, a ...interface{}) {
+	l.logger.Debug(l.name+": "+format, a...)
+}
+
+// lineWriter logs each complete line written to it as a verbose message
+type lineWriter struct {
+	mu     sync.Mutex
+	logger verboseLogger
+	prefix string
+	buf    bytes.Buffer
+}
+
+func newLineWriter(l verboseLogger, prefix string) *lineWriter {
+	if prefix != "" {
+		prefix = "[" + prefix + "] "
+	}
+	return &lineWriter{logger: l, prefix: prefix}
+}
+
+func (w *lineWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// keep the partial line until the rest of it is written
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		w.logger.Verbose("%s%s", w.prefix, line[:len(line)-1])
+	}
+}
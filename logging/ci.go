@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// CIProvider identifies a CI service whose log viewer understands grouping markers in output
+type CIProvider int
+
+const (
+	NoCIProvider CIProvider = iota
+	GitHubActions
+	GitLabCI
+)
+
+// DetectCIProvider returns the CI service pack is running under, based on the environment
+// variables those services set
+func DetectCIProvider() CIProvider {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return GitHubActions
+	case os.Getenv("GITLAB_CI") == "true":
+		return GitLabCI
+	default:
+		return NoCIProvider
+	}
+}
+
+// WithCIProvider makes StartGroup and EndGroup emit the provider's log grouping markers, and
+// formats errors as annotations where the provider supports them
+func WithCIProvider(provider CIProvider) func(*Logger) {
+	return func(l *Logger) {
+		l.ci = provider
+	}
+}
+
+// StartGroup starts a collapsible section of output with the given title. It does nothing
+// unless a CI provider is set, or when logging in JSON format.
+func (l *Logger) StartGroup(title string) {
+	switch l.ci {
+	case GitHubActions:
+		l.rawLine("::group::" + title)
+	case GitLabCI:
+		l.rawLine(fmt.Sprintf("\x1b[0Ksection_start:%d:%s[collapsed=true]\r\x1b[0K%s", time.Now().Unix(), sectionName(title), title))
+	}
+}
+
+// EndGroup ends the section started by StartGroup with the same title
+func (l *Logger) EndGroup(title string) {
+	switch l.ci {
+	case GitHubActions:
+		l.rawLine("::endgroup::")
+	case GitLabCI:
+		l.rawLine(fmt.Sprintf("\x1b[0Ksection_end:%d:%s\r\x1b[0K", time.Now().Unix(), sectionName(title)))
+	}
+}
+
+func (l *Logger) rawLine(line string) {
+	if l.format == JSON {
+		return
+	}
+	fmt.Fprintln(l.out.dest, line)
+}
+
+// sectionName turns a title into a GitLab section name, which may only contain letters,
+// digits, '_', '.' and '-'
+func sectionName(title string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, title)
+}
+
+// githubAnnotation formats msg as a GitHub Actions workflow command, such as an error annotation
+func githubAnnotation(command, msg string) string {
+	msg = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(strings.TrimSuffix(msg, "\n"))
+	return fmt.Sprintf("::%s::%s", command, msg)
+}
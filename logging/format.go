@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Format int
+
+const (
+	Text Format = iota
+	JSON
+)
+
+var formatNames = map[Format]string{
+	Text: "text",
+	JSON: "json",
+}
+
+// WithFormat selects how log lines are rendered. In JSON format every line is written as a
+// single JSON object carrying a timestamp, level, phase and message.
+func WithFormat(format Format) func(*Logger) {
+	return func(l *Logger) {
+		l.format = format
+	}
+}
+
+func (f Format) String() string {
+	return formatNames[f]
+}
+
+// Set implements pflag.Value so the format can be bound directly to a command line flag
+func (f *Format) Set(s string) error {
+	for format, name := range formatNames {
+		if name == s {
+			*f = format
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log format '%s', must be one of 'text' or 'json'", s)
+}
+
+func (f *Format) Type() string {
+	return "format"
+}
+
+type event struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Phase     string `json:"phase,omitempty"`
+	Message   string `json:"message"`
+}
+
+var ansiCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func writeEvents(out io.Writer, level, phase, msg string) error {
+	msg = ansiCodes.ReplaceAllString(strings.TrimRight(msg, "\n"), "")
+	for _, line := range strings.Split(msg, "\n") {
+		b, err := json.Marshal(event{
+			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+			Level:     level,
+			Phase:     phase,
+			Message:   line,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// WithLevel sets the minimum level of messages that are shown. It takes precedence over
+// the verbose argument of NewLogger, which maps to LevelDebug when true and LevelInfo when false.
+// The messages of Logger.Verbose are shown at LevelDebug, and those of Logger.Info at LevelInfo.
+func WithLevel(level Level) func(*Logger) {
+	return func(l *Logger) {
+		l.level = level
+	}
+}
+
+// AtLevel returns a copy of the logger showing messages at the given level and above. It writes
+// to the same writers and log file, in the same format.
+func (l *Logger) AtLevel(level Level) *Logger {
+	if l == nil {
+		return nil
+	}
+	ll := *l
+	ll.level = level
+	return &ll
+}
+
+func ParseLevel(s string) (Level, error) {
+	var level Level
+	err := level.Set(s)
+	return level, err
+}
+
+func (lvl Level) String() string {
+	return levelNames[lvl]
+}
+
+// Set implements pflag.Value so the level can be bound directly to a command line flag
+func (lvl *Level) Set(s string) error {
+	for level, name := range levelNames {
+		if name == strings.ToLower(s) {
+			*lvl = level
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level '%s', must be one of 'debug', 'info', 'warn' or 'error'", s)
+}
+
+func (lvl *Level) Type() string {
+	return "level"
+}
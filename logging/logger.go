@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"strings"
 
 	"github.com/fatih/color"
 
@@ -12,16 +13,86 @@ import (
 )
 
 type Logger struct {
-	verbose bool
-	out     *logWriter
-	err     *logWriter
+	level           Level
+	timestamps      bool
+	timestampFormat TimestampFormat
+	format          Format
+	stdout          io.Writer
+	stderr          io.Writer
+	file            io.Writer
+	out             *logWriter
+	err             *logWriter
+	hiddenOut       *logWriter
+	hiddenErr       *logWriter
+	debugSubsystems map[string]bool
+	subsystem       string
+	ci              CIProvider
+	noColor         bool
 }
 
-func NewLogger(stdout, stderr io.Writer, verbose, timestamps bool) *Logger {
-	return &Logger{
-		verbose: verbose,
-		out:     newLogWriter(stdout, timestamps),
-		err:     newLogWriter(stderr, timestamps),
+// NewLogger returns a logger writing to stdout and stderr, at LevelDebug when verbose and otherwise
+// at LevelInfo, unless WithLevel is given
+func NewLogger(stdout, stderr io.Writer, verbose, timestamps bool, ops ...func(*Logger)) *Logger {
+	level := LevelInfo
+	if verbose {
+		level = LevelDebug
+	}
+	l := &Logger{
+		level:      level,
+		timestamps: timestamps,
+		stdout:     stdout,
+		stderr:     stderr,
+	}
+	for _, op := range ops {
+		op(l)
+	}
+	if l.noColor {
+		// messages may have been colored by the style package before they reach the logger
+		l.stdout = &colorStripper{l.stdout}
+		l.stderr = &colorStripper{l.stderr}
+	}
+	colored := !color.NoColor && !l.noColor
+	var clock func() string
+	if l.timestamps {
+		// both writers share a clock so elapsed timestamps agree
+		clock = newClock(l.timestampFormat)
+	}
+	l.out = newLogWriter(l.stdout, clock, l.format, colored)
+	l.err = newLogWriter(l.stderr, clock, l.format, colored)
+	l.hiddenOut, l.hiddenErr = nullLogWriter, nullLogWriter
+
+	if l.file != nil {
+		fileWriter := newLogWriter(&colorStripper{l.file}, newClock(TimestampRFC3339), l.format, false)
+		l.out = l.out.teeTo(fileWriter)
+		l.err = l.err.teeTo(fileWriter)
+		l.hiddenOut = newLogWriter(ioutil.Discard, nil, l.format, false).teeTo(fileWriter)
+		l.hiddenErr = l.hiddenOut
+	}
+	return l
+}
+
+// WithNonInteractive hides the terminal behind the logger's writers, so that consumers such as
+// the docker pull progress display never query the terminal or redraw lines in place.
+func WithNonInteractive() func(*Logger) {
+	return func(l *Logger) {
+		l.stdout = &nonInteractiveWriter{l.stdout}
+		l.stderr = &nonInteractiveWriter{l.stderr}
+	}
+}
+
+// WithNoColor writes the output of the logger without color, whether or not color.NoColor is set,
+// such as for programs embedding pack that log to files
+func WithNoColor() func(*Logger) {
+	return func(l *Logger) {
+		l.noColor = true
+	}
+}
+
+// WithLogFile additionally writes every message to w regardless of the logger's level,
+// with timestamps and without color, so that complete detail is available after a failure.
+func WithLogFile(w io.Writer) func(*Logger) {
+	return func(l *Logger) {
+		l.file = w
 	}
 }
 
@@ -29,36 +100,95 @@ func (l *Logger) printf(w *logWriter, format string, a ...interface{}) {
 	w.Write([]byte(fmt.Sprintf(format+"\n", a...)))
 }
 
+func (l *Logger) print(w *logWriter, level Level, prefix, format string, a ...interface{}) {
+	w.writeMessage(level, prefix, fmt.Sprintf(format+"\n", a...))
+}
+
+// writer returns w when messages at the given level are shown, and otherwise a writer
+// that only reaches the log file (if any)
+func (l *Logger) writer(level Level, w, hidden *logWriter) *logWriter {
+	if l.IsEnabled(level) {
+		return w
+	}
+	return hidden
+}
+
+// Level returns the minimum level of messages shown by the logger
+func (l *Logger) Level() Level {
+	return l.level
+}
+
+// Format returns how the logger renders lines
+func (l *Logger) Format() Format {
+	return l.format
+}
+
+// IsEnabled reports whether messages at the given level are shown
+func (l *Logger) IsEnabled(level Level) bool {
+	return level >= l.level
+}
+
+func (l *Logger) Debug(format string, a ...interface{}) {
+	w, prefix := l.writer(LevelDebug, l.out, l.hiddenOut), ""
+	if l.subsystem != "" {
+		if l.debugSubsystems[l.subsystem] {
+			w = l.out
+		}
+		prefix = l.subsystem + ": "
+	}
+	l.print(w, LevelDebug, prefix, format, a...)
+}
+
+// Info prints the primary output of a command, which is shown at LevelInfo
 func (l *Logger) Info(format string, a ...interface{}) {
-	l.printf(l.out, format, a...)
+	l.printf(l.writer(LevelInfo, l.out, l.hiddenOut), format, a...)
 }
 
+// Verbose prints the details of what a command does, such as the output of the build phases,
+// which are shown at LevelDebug like the messages of Debug
 func (l *Logger) Verbose(format string, a ...interface{}) {
-	if l.verbose {
-		l.printf(l.out, format, a...)
+	l.printf(l.writer(LevelDebug, l.out, l.hiddenOut), format, a...)
+}
+
+func (l *Logger) Warn(format string, a ...interface{}) {
+	w := l.writer(LevelWarn, l.out, l.hiddenOut)
+	prefix := style.Warn("Warning: ")
+	if !l.annotate(w, "warning", LevelWarn, prefix, format, a...) {
+		l.print(w, LevelWarn, prefix, format, a...)
 	}
 }
 
 func (l *Logger) Error(format string, a ...interface{}) {
-	l.printf(l.err, style.Error("ERROR: ")+format, a...)
+	prefix := style.Error("ERROR: ")
+	if !l.annotate(l.err, "error", LevelError, prefix, format, a...) {
+		l.print(l.err, LevelError, prefix, format, a...)
+	}
+}
+
+// annotate writes the message as a GitHub Actions annotation when running under GitHub Actions
+// in text format, and reports whether it did so. The log file still receives the prefixed message.
+func (l *Logger) annotate(w *logWriter, command string, level Level, prefix, format string, a ...interface{}) bool {
+	if l.ci != GitHubActions || l.format == JSON || w.dest == ioutil.Discard {
+		return false
+	}
+	msg := fmt.Sprintf(format, a...)
+	fmt.Fprintln(w.dest, githubAnnotation(command, msg))
+	if w.tee != nil {
+		w.tee.writeMessage(level, prefix, msg+"\n")
+	}
+	return true
 }
 
 func (l *Logger) Tip(format string, a ...interface{}) {
-	l.printf(l.out, style.Tip("Tip: ")+format, a...)
+	l.printf(l.writer(LevelWarn, l.out, l.hiddenOut), style.Tip("Tip: ")+format, a...)
 }
 
 func (l *Logger) VerboseWriter() *logWriter {
-	if !l.verbose {
-		return nullLogWriter
-	}
-	return l.out
+	return l.writer(LevelDebug, l.out, l.hiddenOut)
 }
 
 func (l *Logger) RawVerboseWriter() io.Writer {
-	if !l.verbose {
-		return ioutil.Discard
-	}
-	return l.out.rawOut
+	return l.writer(LevelDebug, l.out, l.hiddenOut).rawOut
 }
 
 func (l *Logger) RawWriter() io.Writer {
@@ -66,51 +196,129 @@ func (l *Logger) RawWriter() io.Writer {
 }
 
 func (l *Logger) VerboseErrorWriter() *logWriter {
-	if !l.verbose {
-		return nullLogWriter
+	return l.writer(LevelDebug, l.err, l.hiddenErr)
+}
+
+type nonInteractiveWriter struct {
+	io.Writer
+}
+
+type colorStripper struct {
+	io.Writer
+}
+
+func (w *colorStripper) Write(p []byte) (n int, err error) {
+	if _, err := w.Writer.Write(ansiCodes.ReplaceAll(p, nil)); err != nil {
+		return 0, err
 	}
-	return l.err
+	return len(p), nil
 }
 
 type logWriter struct {
+	clock  func() string
 	prefix string
+	phase  string
+	format Format
 	log    *log.Logger
+	dest   io.Writer
 	rawOut io.Writer
+	tee    *logWriter
 }
 
-var nullLogWriter = newLogWriter(ioutil.Discard, false)
+var nullLogWriter = newLogWriter(ioutil.Discard, nil, Text, false)
 
-func newLogWriter(out io.Writer, timestamps bool) *logWriter {
-	flags := 0
+func newLogWriter(out io.Writer, clock func() string, format Format, colored bool) *logWriter {
 	timestampStart := ""
 	timestampEnd := ""
-	if !color.NoColor {
-		// Go logger prefixes appear before timestamp, so insert color start/end sequences around timestamp
+	if colored {
+		// insert color start/end sequences around timestamp
 		timestampStart = fmt.Sprintf("\x1b[%dm", style.TimestampColorCode)
 		timestampEnd = fmt.Sprintf("\x1b[%dm", color.Reset)
 	}
 	prefix := ""
-	if timestamps {
-		flags = log.LstdFlags
+	if clock != nil {
 		prefix = " "
+		if colored {
+			// colored timestamps are followed by a space on both sides of the color end sequence
+			stamp := clock
+			clock = func() string { return stamp() + " " }
+		}
+	} else {
+		clock = func() string { return "" }
 	}
 
-	return &logWriter{
+	w := &logWriter{
+		clock:  clock,
 		prefix: timestampEnd + prefix,
-		log:    log.New(out, timestampStart, flags),
-		rawOut: out,
+		format: format,
+		log:    log.New(out, timestampStart, 0),
+		dest:   out,
 	}
+	w.setRawOut()
+	return w
+}
+
+func (w *logWriter) setRawOut() {
+	switch {
+	case w.format == JSON:
+		// raw output bypasses text decoration, but must still be structured in JSON format
+		w.rawOut = w
+	case w.tee != nil:
+		w.rawOut = io.MultiWriter(w.dest, w.tee.rawOut)
+	default:
+		w.rawOut = w.dest
+	}
+}
+
+func (w *logWriter) teeTo(tee *logWriter) *logWriter {
+	tw := *w
+	tw.tee = tee
+	tw.setRawOut()
+	return &tw
 }
 
 func (w *logWriter) WithPrefix(prefix string) *logWriter {
-	return &logWriter{
+	pw := &logWriter{
+		clock:  w.clock,
 		log:    w.log,
 		prefix: fmt.Sprintf("%s[%s] ", w.prefix, style.Prefix(prefix)),
-		rawOut: w.rawOut,
+		phase:  prefix,
+		format: w.format,
+		dest:   w.dest,
+	}
+	if w.tee != nil {
+		pw.tee = w.tee.WithPrefix(prefix)
 	}
+	pw.setRawOut()
+	return pw
 }
 
 func (w *logWriter) Write(p []byte) (n int, err error) {
-	w.log.Print(w.prefix + string(p))
+	if err := w.writeMessage(LevelInfo, "", string(p)); err != nil {
+		return 0, err
+	}
 	return len(p), nil
 }
+
+// writeMessage writes msg at the given level. The prefix only decorates text output.
+func (w *logWriter) writeMessage(level Level, prefix, msg string) error {
+	if w.format == JSON {
+		if err := writeEvents(w.dest, level.String(), w.phase, msg); err != nil {
+			return err
+		}
+	} else if w.dest != ioutil.Discard {
+		// decorate every line so that interleaved output can always be attributed
+		linePrefix := prefix
+		for _, line := range strings.SplitAfter(msg, "\n") {
+			if line == "" {
+				continue
+			}
+			w.log.Print(w.clock() + w.prefix + linePrefix + line)
+			linePrefix = ""
+		}
+	}
+	if w.tee != nil {
+		return w.tee.writeMessage(level, prefix, msg)
+	}
+	return nil
+}
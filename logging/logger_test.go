@@ -2,7 +2,9 @@ package logging_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 
@@ -91,6 +93,195 @@ func testLogging(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
+	when("adapters", func() {
+		it("uses the writers of a *Logger", func() {
+			logger = logging.NewLogger(&outBuf, &errBuf, true, false)
+			logging.VerboseWriter(logger, "detector").Write([]byte("Some text\n"))
+
+			h.AssertContains(t, outBuf.String(), "] Some text\n")
+		})
+
+		it("logs complete lines as verbose messages of other loggers", func() {
+			rec := &recordingLogger{}
+			w := logging.VerboseWriter(rec, "detector")
+			w.Write([]byte("first line\nsecond "))
+			w.Write([]byte("line\n"))
+			logging.SubsystemLogger(rec, logging.SubsystemFS).Debug("Creating tar")
+
+			h.AssertEq(t, rec.messages, []string{
+				"verbose: [detector] first line",
+				"verbose: [detector] second line",
+				"debug: fs: Creating tar",
+			})
+		})
+	})
+
+	when("ci provider", func() {
+		when("GitHub Actions", func() {
+			it.Before(func() {
+				logger = logging.NewLogger(&outBuf, &errBuf, true, false, logging.WithCIProvider(logging.GitHubActions))
+			})
+
+			it("wraps groups in workflow commands", func() {
+				logger.StartGroup("detector")
+				logger.EndGroup("detector")
+
+				h.AssertEq(t, outBuf.String(), "::group::detector\n::endgroup::\n")
+			})
+
+			it("writes errors and warnings as annotations", func() {
+				logger.Error("failed: 100%%\nof it")
+				logger.Warn("careful")
+
+				h.AssertEq(t, errBuf.String(), "::error::failed: 100%25%0Aof it\n")
+				h.AssertEq(t, outBuf.String(), "::warning::careful\n")
+			})
+		})
+
+		when("GitLab CI", func() {
+			it("wraps groups in collapsed sections", func() {
+				logger = logging.NewLogger(&outBuf, &errBuf, true, false, logging.WithCIProvider(logging.GitLabCI))
+				logger.StartGroup("detector")
+				logger.EndGroup("detector")
+
+				h.AssertMatch(t, outBuf.String(), `^\x1b\[0Ksection_start:\d+:detector\[collapsed=true\]\r\x1b\[0Kdetector\n\x1b\[0Ksection_end:\d+:detector\r\x1b\[0K\n$`)
+			})
+		})
+
+		it("does not write markers without a provider", func() {
+			logger = logging.NewLogger(&outBuf, &errBuf, true, false)
+			logger.StartGroup("detector")
+			logger.EndGroup("detector")
+
+			h.AssertEq(t, outBuf.String(), "")
+		})
+	})
+
+	when("subsystems", func() {
+		it.Before(func() {
+			logger = logging.NewLogger(&outBuf, &errBuf, false, false, logging.WithDebugSubsystems(logging.SubsystemDocker))
+		})
+
+		it("shows debug output of enabled subsystems with the subsystem name", func() {
+			logger.Subsystem(logging.SubsystemDocker).Debug("GET /info")
+
+			h.AssertEq(t, ignoreEmptyTimestampColorCodes(outBuf.String()), "docker: GET /info\n")
+		})
+
+		it("hides debug output of other subsystems", func() {
+			logger.Subsystem(logging.SubsystemFS).Debug("Creating tar")
+			logger.Debug("Some debug output")
+
+			h.AssertEq(t, outBuf.String(), "")
+		})
+
+		it("validates subsystem names", func() {
+			h.AssertNil(t, logging.ValidateSubsystems([]string{"docker", "registry", "fs"}))
+			h.AssertError(t, logging.ValidateSubsystems([]string{"docker", "network"}), "unknown subsystem 'network'")
+		})
+	})
+
+	when("levels", func() {
+		when("level is debug", func() {
+			it.Before(func() {
+				logger = logging.NewLogger(&outBuf, &errBuf, true, false, logging.WithLevel(logging.LevelDebug))
+			})
+
+			it("shows debug output", func() {
+				logger.Debug("Some debug output")
+
+				h.AssertEq(t, ignoreEmptyTimestampColorCodes(outBuf.String()), "Some debug output\n")
+			})
+
+			it("shows verbose output", func() {
+				logger.Verbose("Some verbose output")
+
+				h.AssertEq(t, ignoreEmptyTimestampColorCodes(outBuf.String()), "Some verbose output\n")
+			})
+		})
+
+		when("level is info", func() {
+			it.Before(func() {
+				logger = logging.NewLogger(&outBuf, &errBuf, false, false, logging.WithLevel(logging.LevelInfo))
+			})
+
+			it("does not show debug or verbose output", func() {
+				logger.Debug("Some debug output")
+				logger.Verbose("Some verbose output")
+
+				h.AssertEq(t, outBuf.String(), "")
+			})
+
+			it("shows info output", func() {
+				logger.Info("Some info")
+
+				h.AssertEq(t, ignoreEmptyTimestampColorCodes(outBuf.String()), "Some info\n")
+			})
+		})
+
+		when("level is warn", func() {
+			it.Before(func() {
+				logger = logging.NewLogger(&outBuf, &errBuf, true, false, logging.WithLevel(logging.LevelWarn))
+			})
+
+			it("shows styled warnings", func() {
+				logger.Warn("Careful")
+
+				h.AssertEq(t, ignoreEmptyTimestampColorCodes(outBuf.String()), style.Warn("Warning: ")+"Careful\n")
+			})
+
+			it("does not show info or verbose output", func() {
+				logger.Info("Some info")
+				logger.Verbose("Some verbose output")
+
+				h.AssertEq(t, outBuf.String(), "")
+			})
+		})
+
+		when("level is error", func() {
+			it.Before(func() {
+				logger = logging.NewLogger(&outBuf, &errBuf, true, false, logging.WithLevel(logging.LevelError))
+			})
+
+			it("only shows errors", func() {
+				logger.Info("Some info")
+				logger.Warn("Careful")
+				logger.Error("Something went wrong!")
+
+				h.AssertEq(t, outBuf.String(), "")
+				h.AssertEq(t, ignoreEmptyTimestampColorCodes(errBuf.String()), style.Error("ERROR: ")+"Something went wrong!\n")
+			})
+		})
+
+		when("#AtLevel", func() {
+			it("shows the messages of the new level to the same writers and log file", func() {
+				var fileBuf bytes.Buffer
+				logger = logging.NewLogger(&outBuf, &errBuf, true, false, logging.WithLogFile(&fileBuf))
+				quiet := logger.AtLevel(logging.LevelError)
+				quiet.Verbose("Some verbose output")
+				quiet.Error("Something went wrong!")
+
+				h.AssertEq(t, outBuf.String(), "")
+				h.AssertEq(t, ignoreEmptyTimestampColorCodes(errBuf.String()), style.Error("ERROR: ")+"Something went wrong!\n")
+				h.AssertContains(t, fileBuf.String(), "Some verbose output")
+				h.AssertEq(t, logger.Level(), logging.LevelDebug)
+			})
+		})
+
+		when("#ParseLevel", func() {
+			it("parses level names", func() {
+				level, err := logging.ParseLevel("WARN")
+				h.AssertNil(t, err)
+				h.AssertEq(t, level, logging.LevelWarn)
+			})
+
+			it("fails for unknown levels", func() {
+				_, err := logging.ParseLevel("loud")
+				h.AssertError(t, err, "unknown log level 'loud'")
+			})
+		})
+	})
+
 	when("timestamps", func() {
 		when("logger has timestamps enabled", func() {
 			it.Before(func() {
@@ -103,6 +294,28 @@ func testLogging(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("logger has RFC3339 timestamps", func() {
+			it.Before(func() {
+				logger = logging.NewLogger(&outBuf, &errBuf, false, false, logging.WithTimestampFormat(logging.TimestampRFC3339))
+			})
+
+			it("prefixes logging with RFC3339 timestamp", func() {
+				logger.Info("Some text")
+				h.AssertMatch(t, outBuf.String(), fmt.Sprintf(`^\x1b\[%dm\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(Z|[+-]\d{2}:\d{2}) \x1b\[%dm Some text`, style.TimestampColorCode, color.Reset))
+			})
+		})
+
+		when("logger has elapsed timestamps", func() {
+			it.Before(func() {
+				logger = logging.NewLogger(&outBuf, &errBuf, false, false, logging.WithTimestampFormat(logging.TimestampElapsed))
+			})
+
+			it("prefixes logging with time since the logger was created", func() {
+				logger.Info("Some text")
+				h.AssertMatch(t, outBuf.String(), fmt.Sprintf(`^\x1b\[%dm +0\.\ds \x1b\[%dm Some text`, style.TimestampColorCode, color.Reset))
+			})
+		})
+
 		when("logger has timestamps disabled", func() {
 			it.Before(func() {
 				logger = logging.NewLogger(&outBuf, &errBuf, false, false)
@@ -154,12 +367,140 @@ func testLogging(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
+	when("#WithNonInteractive", func() {
+		it("does not expose the underlying file to writer consumers", func() {
+			logger = logging.NewLogger(os.Stdout, os.Stderr, true, false, logging.WithNonInteractive())
+
+			_, isFile := logger.RawWriter().(*os.File)
+			h.AssertEq(t, isFile, false)
+		})
+
+		it("still writes to the underlying writer", func() {
+			logger = logging.NewLogger(&outBuf, &errBuf, true, false, logging.WithNonInteractive())
+			logger.Info("Some text")
+
+			h.AssertEq(t, ignoreEmptyTimestampColorCodes(outBuf.String()), "Some text\n")
+		})
+	})
+
+	when("#WithFormat", func() {
+		when("JSON", func() {
+			it.Before(func() {
+				logger = logging.NewLogger(&outBuf, &errBuf, true, false, logging.WithFormat(logging.JSON))
+			})
+
+			it("writes each line as a JSON event", func() {
+				logger.Info("first %s\nsecond", style.Symbol("line"))
+
+				lines := strings.Split(strings.TrimSpace(outBuf.String()), "\n")
+				h.AssertEq(t, len(lines), 2)
+				var e map[string]string
+				h.AssertNil(t, json.Unmarshal([]byte(lines[0]), &e))
+				h.AssertEq(t, e["level"], "info")
+				h.AssertEq(t, e["message"], "first line")
+				h.AssertMatch(t, e["timestamp"], `^\d{4}-\d{2}-\d{2}T`)
+			})
+
+			it("writes errors with error level and no styled prefix", func() {
+				logger.Error("Something went wrong!")
+
+				var e map[string]string
+				h.AssertNil(t, json.Unmarshal(errBuf.Bytes(), &e))
+				h.AssertEq(t, e["level"], "error")
+				h.AssertEq(t, e["message"], "Something went wrong!")
+			})
+
+			it("includes the phase of prefixed writers", func() {
+				logger.VerboseWriter().WithPrefix("detector").Write([]byte("some output\n"))
+
+				var e map[string]string
+				h.AssertNil(t, json.Unmarshal(outBuf.Bytes(), &e))
+				h.AssertEq(t, e["phase"], "detector")
+				h.AssertEq(t, e["message"], "some output")
+			})
+
+			it("structures raw output", func() {
+				logger.RawWriter().Write([]byte("raw output\n"))
+
+				var e map[string]string
+				h.AssertNil(t, json.Unmarshal(outBuf.Bytes(), &e))
+				h.AssertEq(t, e["message"], "raw output")
+			})
+		})
+	})
+
+	when("#WithNoColor", func() {
+		it("writes messages and timestamps without color", func() {
+			logger = logging.NewLogger(&outBuf, &errBuf, true, true, logging.WithNoColor())
+
+			logger.Info("Some %s output", style.Symbol("info"))
+			logger.Error("Some error")
+
+			h.AssertMatch(t, outBuf.String(), `^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} Some info output\n$`)
+			h.AssertMatch(t, errBuf.String(), `^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} ERROR: Some error\n$`)
+		})
+	})
+
+	when("#WithLogFile", func() {
+		var fileBuf bytes.Buffer
+
+		it.Before(func() {
+			logger = logging.NewLogger(&outBuf, &errBuf, false, false, logging.WithLogFile(&fileBuf))
+		})
+
+		it("writes messages hidden from the console to the file", func() {
+			logger.Verbose("Some %s output", style.Symbol("verbose"))
+
+			h.AssertEq(t, outBuf.String(), "")
+			h.AssertMatch(t, fileBuf.String(), `^\d{4}-\d{2}-\d{2}T\S+ Some verbose output\n$`)
+		})
+
+		it("writes shown messages to both the console and the file", func() {
+			logger.Info("Some info")
+
+			h.AssertEq(t, ignoreEmptyTimestampColorCodes(outBuf.String()), "Some info\n")
+			h.AssertContains(t, fileBuf.String(), " Some info\n")
+		})
+
+		it("writes the prefix of multi-line messages to both the console and the file", func() {
+			logger.Warn("first\nsecond")
+
+			h.AssertContains(t, outBuf.String(), "Warning: ")
+			h.AssertContains(t, fileBuf.String(), " Warning: first\n")
+		})
+
+		it("writes hidden phase output with its prefix to the file", func() {
+			logger.VerboseErrorWriter().WithPrefix("builder").Write([]byte("some error\n"))
+
+			h.AssertEq(t, errBuf.String(), "")
+			h.AssertContains(t, fileBuf.String(), " [builder] some error\n")
+		})
+
+		it("writes raw output to the file", func() {
+			logger.RawVerboseWriter().Write([]byte("pulling\n"))
+
+			h.AssertEq(t, outBuf.String(), "")
+			h.AssertEq(t, fileBuf.String(), "pulling\n")
+		})
+	})
+
 	when("#WithPrefix", func() {
 		it("returns prefixed writer", func() {
 			writer := logging.NewLogger(&outBuf, &errBuf, true, false).VerboseWriter()
 			writer.WithPrefix("Some prefix").Write([]byte("Some text\n"))
 			h.AssertEq(t, ignoreEmptyTimestampColorCodes(outBuf.String()), fmt.Sprintf("[%s] Some text\n", style.Prefix("Some prefix")))
 		})
+
+		it("prefixes every line of multi-line output", func() {
+			writer := logging.NewLogger(&outBuf, &errBuf, true, false).VerboseWriter()
+			writer.WithPrefix("builder").Write([]byte("first\nsecond\n"))
+
+			lines := strings.Split(strings.TrimSuffix(outBuf.String(), "\n"), "\n")
+			h.AssertEq(t, len(lines), 2)
+			for _, line := range lines {
+				h.AssertContains(t, line, fmt.Sprintf("[%s] ", style.Prefix("builder")))
+			}
+		})
 	})
 }
 
@@ -167,3 +508,15 @@ func ignoreEmptyTimestampColorCodes(s string) string {
 	// These codes are inserted, but have no timestamp between them
 	return strings.TrimPrefix(s, fmt.Sprintf("\x1b[%dm\x1b[0m", style.TimestampColorCode))
 }
+
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Debug(format string, a ...interface{}) {
+	l.messages = append(l.messages, "debug: "+fmt.Sprintf(format, a...))
+}
+
+func (l *recordingLogger) Verbose(format string, a ...interface{}) {
+	l.messages = append(l.messages, "verbose: "+fmt.Sprintf(format, a...))
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Subsystems whose debug output can be enabled independently of the logger's level
+const (
+	SubsystemDocker   = "docker"
+	SubsystemRegistry = "registry"
+	SubsystemFS       = "fs"
+)
+
+var subsystems = []string{SubsystemDocker, SubsystemRegistry, SubsystemFS}
+
+// WithDebugSubsystems shows debug messages of the given subsystems regardless of the logger's level
+func WithDebugSubsystems(names ...string) func(*Logger) {
+	return func(l *Logger) {
+		l.debugSubsystems = map[string]bool{}
+		for _, name := range names {
+			l.debugSubsystems[name] = true
+		}
+	}
+}
+
+// ValidateSubsystems returns an error naming the first unknown subsystem in names
+func ValidateSubsystems(names []string) error {
+	for _, name := range names {
+		known := false
+		for _, s := range subsystems {
+			known = known || s == name
+		}
+		if !known {
+			return fmt.Errorf("unknown subsystem '%s', must be one of '%s'", name, strings.Join(subsystems, "', '"))
+		}
+	}
+	return nil
+}
+
+// Subsystem returns a logger for the named subsystem. Its debug messages are prefixed with the
+// subsystem name, and are shown when either the logger's level or WithDebugSubsystems enable them.
+func (l *Logger) Subsystem(name string) *Logger {
+	if l == nil {
+		return nil
+	}
+	sl := *l
+	sl.subsystem = name
+	return &sl
+}
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"fmt"
+	"time"
+)
+
+type TimestampFormat int
+
+const (
+	// TimestampDefault prints the local date and time, e.g. 2019/03/28 14:02:11
+	TimestampDefault TimestampFormat = iota
+	// TimestampRFC3339 prints the local date and time in RFC3339 format, e.g. 2019-03-28T14:02:11-04:00
+	TimestampRFC3339
+	// TimestampElapsed prints the time elapsed since the logger was created, e.g.   12.3s
+	TimestampElapsed
+)
+
+var timestampFormatNames = map[TimestampFormat]string{
+	TimestampDefault: "default",
+	TimestampRFC3339: "rfc3339",
+	TimestampElapsed: "elapsed",
+}
+
+// WithTimestampFormat enables timestamps on every log line using the given format
+func WithTimestampFormat(format TimestampFormat) func(*Logger) {
+	return func(l *Logger) {
+		l.timestamps = true
+		l.timestampFormat = format
+	}
+}
+
+func (f TimestampFormat) String() string {
+	return timestampFormatNames[f]
+}
+
+// Set implements pflag.Value so the timestamp format can be bound directly to a command line flag
+func (f *TimestampFormat) Set(s string) error {
+	for format, name := range timestampFormatNames {
+		if name == s {
+			*f = format
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown timestamp format '%s', must be one of 'default', 'rfc3339' or 'elapsed'", s)
+}
+
+func (f *TimestampFormat) Type() string {
+	return "format"
+}
+
+func newClock(format TimestampFormat) func() string {
+	switch format {
+	case TimestampRFC3339:
+		return func() string {
+			return time.Now().Format(time.RFC3339)
+		}
+	case TimestampElapsed:
+		start := time.Now()
+		return func() string {
+			return fmt.Sprintf("%7.1fs", time.Since(start).Seconds())
+		}
+	default:
+		return func() string {
+			return time.Now().Format("2006/01/02 15:04:05")
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package pack
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/keychain"
+	"github.com/buildpack/pack/manifest"
+	"github.com/buildpack/pack/style"
+)
+
+// CreateManifest creates a local manifest list named listName, containing the given images
+// from their registries
+func (c *Client) CreateManifest(listName string, images []string) error {
+	store := c.manifestStore()
+	list, err := store.Create(listName)
+	if err != nil {
+		return err
+	}
+	for _, image := range images {
+		if err := addRemoteImage(list, image, registryTransport(c.config)); err != nil {
+			return err
+		}
+	}
+	return store.Save(list)
+}
+
+// AddToManifest adds an image from its registry to a local manifest list
+func (c *Client) AddToManifest(listName, image string) error {
+	store := c.manifestStore()
+	list, err := store.Load(listName)
+	if err != nil {
+		return err
+	}
+	if err := addRemoteImage(list, image, registryTransport(c.config)); err != nil {
+		return err
+	}
+	return store.Save(list)
+}
+
+// AnnotateManifest changes the platform or annotations of an image in a local manifest list
+func (c *Client) AnnotateManifest(listName, image string, annotations manifest.Annotations) error {
+	store := c.manifestStore()
+	list, err := store.Load(listName)
+	if err != nil {
+		return err
+	}
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return err
+	}
+
+	var hash v1.Hash
+	if digest, ok := ref.(name.Digest); ok {
+		hash, err = v1.NewHash(digest.DigestStr())
+	} else {
+		var img v1.Image
+		img, err = remoteImage(ref, registryTransport(c.config))
+		if err != nil {
+			return errors.Wrapf(err, "fetching image %s", style.Symbol(image))
+		}
+		hash, err = img.Digest()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := list.Annotate(hash, annotations); err != nil {
+		return err
+	}
+	return store.Save(list)
+}
+
+// PushManifest pushes a local manifest list as an image index, also copying its images into the
+// repository of the list where needed. It returns the digest of the index. With purge, the local
+// list is deleted once pushed.
+func (c *Client) PushManifest(listName string, purge bool) (string, error) {
+	store := c.manifestStore()
+	list, err := store.Load(listName)
+	if err != nil {
+		return "", err
+	}
+	ref, err := parseImageReference(listName)
+	if err != nil {
+		return "", err
+	}
+	auth, err := keychain.Default.Resolve(ref.Context().Registry)
+	if err != nil {
+		return "", err
+	}
+
+	transport := registryTransport(c.config)
+	index := list.Index(func(ref name.Reference) (v1.Image, error) {
+		return remoteImage(ref, transport)
+	})
+	if err := remote.WriteIndex(ref, index, auth, transport); err != nil {
+		return "", errors.Wrapf(err, "pushing manifest list %s", style.Symbol(listName))
+	}
+	digest, err := index.Digest()
+	if err != nil {
+		return "", err
+	}
+
+	if purge {
+		if err := store.Delete(listName); err != nil {
+			return "", err
+		}
+	}
+	return digest.String(), nil
+}
+
+func (c *Client) manifestStore() *manifest.Store {
+	return manifest.NewStore(filepath.Join(c.config.Path(), "manifests"))
+}
+
+func addRemoteImage(list *manifest.List, image string, transport http.RoundTripper) error {
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return err
+	}
+	img, err := remoteImage(ref, transport)
+	if err != nil {
+		return errors.Wrapf(err, "fetching image %s", style.Symbol(image))
+	}
+	return list.Add(ref, img)
+}
+
+func parseImageReference(image string) (name.Reference, error) {
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image reference %s", style.Symbol(image))
+	}
+	return ref, nil
+}
+
+// remoteImage fetches an image from its registry with the credentials of keychain.Default
+func remoteImage(ref name.Reference, transport http.RoundTripper) (v1.Image, error) {
+	return remote.Image(ref, remote.WithAuthFromKeychain(keychain.Default), remote.WithTransport(transport))
+}
@@ -0,0 +1,170 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+)
+
+// List is a manifest list assembled locally from images in registries, which is pushed as an
+// OCI image index so that each platform pulls the image built for it
+type List struct {
+	Name      string  `json:"name"`
+	Manifests []Entry `json:"manifests"`
+}
+
+// Entry is an image of a List, along with the reference it was added from
+type Entry struct {
+	// Image references the image by digest, so that it can be read when pushing the list
+	Image      string        `json:"image"`
+	Descriptor v1.Descriptor `json:"descriptor"`
+}
+
+// Annotations change the platform and annotations of an image in a List. Empty fields are left
+// unchanged.
+type Annotations struct {
+	OS          string
+	Arch        string
+	Variant     string
+	OSVersion   string
+	OSFeatures  []string
+	Annotations map[string]string
+}
+
+// Add adds img, read from ref, to the list. Its platform is taken from the image config. An image
+// already in the list with the same digest is replaced.
+func (l *List) Add(ref name.Reference, img v1.Image) error {
+	digest, err := img.Digest()
+	if err != nil {
+		return errors.Wrapf(err, "getting digest of %s", ref)
+	}
+	mediaType, err := img.MediaType()
+	if err != nil {
+		return errors.Wrapf(err, "getting media type of %s", ref)
+	}
+	raw, err := img.RawManifest()
+	if err != nil {
+		return errors.Wrapf(err, "getting manifest of %s", ref)
+	}
+	config, err := img.ConfigFile()
+	if err != nil {
+		return errors.Wrapf(err, "getting config of %s", ref)
+	}
+
+	entry := Entry{
+		Image: fmt.Sprintf("%s@%s", ref.Context(), digest),
+		Descriptor: v1.Descriptor{
+			MediaType: mediaType,
+			Size:      int64(len(raw)),
+			Digest:    digest,
+			Platform: &v1.Platform{
+				OS:           config.OS,
+				Architecture: config.Architecture,
+				OSVersion:    config.OSVersion,
+			},
+		},
+	}
+	for i, e := range l.Manifests {
+		if e.Descriptor.Digest == digest {
+			l.Manifests[i] = entry
+			return nil
+		}
+	}
+	l.Manifests = append(l.Manifests, entry)
+	return nil
+}
+
+// Annotate changes the platform and annotations of the image in the list with the given digest
+func (l *List) Annotate(digest v1.Hash, a Annotations) error {
+	for i := range l.Manifests {
+		desc := &l.Manifests[i].Descriptor
+		if desc.Digest != digest {
+			continue
+		}
+		if desc.Platform == nil {
+			desc.Platform = &v1.Platform{}
+		}
+		setIfNotEmpty(&desc.Platform.OS, a.OS)
+		setIfNotEmpty(&desc.Platform.Architecture, a.Arch)
+		setIfNotEmpty(&desc.Platform.Variant, a.Variant)
+		setIfNotEmpty(&desc.Platform.OSVersion, a.OSVersion)
+		if len(a.OSFeatures) > 0 {
+			desc.Platform.OSFeatures = a.OSFeatures
+		}
+		for k, v := range a.Annotations {
+			if desc.Annotations == nil {
+				desc.Annotations = map[string]string{}
+			}
+			desc.Annotations[k] = v
+		}
+		return nil
+	}
+	return fmt.Errorf("manifest list %s does not contain an image with digest %s", l.Name, digest)
+}
+
+func setIfNotEmpty(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+// Index returns the list as an image index. Its images are read through fetch from the
+// references they were added from, when pushing them to the repository of the list.
+func (l *List) Index(fetch func(ref name.Reference) (v1.Image, error)) v1.ImageIndex {
+	return &index{list: l, fetch: fetch}
+}
+
+type index struct {
+	list  *List
+	fetch func(ref name.Reference) (v1.Image, error)
+}
+
+func (i *index) MediaType() (types.MediaType, error) {
+	return types.OCIImageIndex, nil
+}
+
+func (i *index) Digest() (v1.Hash, error) {
+	return partial.Digest(i)
+}
+
+func (i *index) IndexManifest() (*v1.IndexManifest, error) {
+	manifest := &v1.IndexManifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIImageIndex,
+		Manifests:     []v1.Descriptor{},
+	}
+	for _, e := range i.list.Manifests {
+		manifest.Manifests = append(manifest.Manifests, e.Descriptor)
+	}
+	return manifest, nil
+}
+
+func (i *index) RawManifest() ([]byte, error) {
+	manifest, err := i.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(manifest)
+}
+
+func (i *index) Image(digest v1.Hash) (v1.Image, error) {
+	for _, e := range i.list.Manifests {
+		if e.Descriptor.Digest == digest {
+			ref, err := name.ParseReference(e.Image, name.WeakValidation)
+			if err != nil {
+				return nil, err
+			}
+			return i.fetch(ref)
+		}
+	}
+	return nil, fmt.Errorf("manifest list %s does not contain an image with digest %s", i.list.Name, digest)
+}
+
+func (i *index) ImageIndex(digest v1.Hash) (v1.ImageIndex, error) {
+	return nil, fmt.Errorf("manifest list %s does not contain nested indexes", i.list.Name)
+}
@@ -0,0 +1,131 @@
+package manifest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/manifest"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestList(t *testing.T) {
+	spec.Run(t, "List", testList, spec.Report(report.Terminal{}))
+}
+
+func testList(t *testing.T, when spec.G, it spec.S) {
+	var (
+		list *manifest.List
+		img  v1.Image
+		ref  name.Reference
+	)
+
+	it.Before(func() {
+		var err error
+		list = &manifest.List{Name: "some/list"}
+		img, err = random.Image(1024, 1)
+		h.AssertNil(t, err)
+		ref, err = name.ParseReference("some/image:latest", name.WeakValidation)
+		h.AssertNil(t, err)
+	})
+
+	digestOf := func(img v1.Image) v1.Hash {
+		t.Helper()
+		digest, err := img.Digest()
+		h.AssertNil(t, err)
+		return digest
+	}
+
+	when("#Add", func() {
+		it("adds the image by digest", func() {
+			h.AssertNil(t, list.Add(ref, img))
+
+			h.AssertEq(t, len(list.Manifests), 1)
+			h.AssertEq(t, list.Manifests[0].Image, fmt.Sprintf("index.docker.io/some/image@%s", digestOf(img)))
+			h.AssertEq(t, list.Manifests[0].Descriptor.Digest, digestOf(img))
+			h.AssertEq(t, list.Manifests[0].Descriptor.MediaType, types.DockerManifestSchema2)
+		})
+
+		it("replaces an image with the same digest", func() {
+			h.AssertNil(t, list.Add(ref, img))
+			h.AssertNil(t, list.Add(ref, img))
+
+			h.AssertEq(t, len(list.Manifests), 1)
+		})
+	})
+
+	when("#Annotate", func() {
+		it.Before(func() {
+			h.AssertNil(t, list.Add(ref, img))
+		})
+
+		it("changes the non empty fields of the platform", func() {
+			h.AssertNil(t, list.Annotate(digestOf(img), manifest.Annotations{OS: "linux", Arch: "arm"}))
+			h.AssertNil(t, list.Annotate(digestOf(img), manifest.Annotations{
+				Variant:     "v7",
+				Annotations: map[string]string{"some-key": "some-value"},
+			}))
+
+			desc := list.Manifests[0].Descriptor
+			h.AssertEq(t, desc.Platform.OS, "linux")
+			h.AssertEq(t, desc.Platform.Architecture, "arm")
+			h.AssertEq(t, desc.Platform.Variant, "v7")
+			h.AssertEq(t, desc.Annotations, map[string]string{"some-key": "some-value"})
+		})
+
+		it("fails when the image is not in the list", func() {
+			other, err := random.Image(1024, 1)
+			h.AssertNil(t, err)
+
+			err = list.Annotate(digestOf(other), manifest.Annotations{OS: "linux"})
+			h.AssertError(t, err, "does not contain an image with digest")
+		})
+	})
+
+	when("#Index", func() {
+		var fetched []string
+
+		it.Before(func() {
+			fetched = nil
+			h.AssertNil(t, list.Add(ref, img))
+			h.AssertNil(t, list.Annotate(digestOf(img), manifest.Annotations{OS: "linux", Arch: "amd64"}))
+		})
+
+		fetch := func(ref name.Reference) (v1.Image, error) {
+			fetched = append(fetched, ref.Name())
+			return img, nil
+		}
+
+		it("returns an OCI image index of the images", func() {
+			index := list.Index(fetch)
+
+			mediaType, err := index.MediaType()
+			h.AssertNil(t, err)
+			h.AssertEq(t, mediaType, types.OCIImageIndex)
+
+			raw, err := index.RawManifest()
+			h.AssertNil(t, err)
+			var manifest v1.IndexManifest
+			h.AssertNil(t, json.Unmarshal(raw, &manifest))
+			h.AssertEq(t, len(manifest.Manifests), 1)
+			h.AssertEq(t, manifest.Manifests[0].Digest, digestOf(img))
+			h.AssertEq(t, manifest.Manifests[0].Platform.OS, "linux")
+			h.AssertEq(t, manifest.Manifests[0].Platform.Architecture, "amd64")
+		})
+
+		it("reads images from the references they were added from", func() {
+			index := list.Index(fetch)
+
+			_, err := index.Image(digestOf(img))
+			h.AssertNil(t, err)
+			h.AssertEq(t, fetched, []string{fmt.Sprintf("index.docker.io/some/image@%s", digestOf(img))})
+		})
+	})
+}
@@ -0,0 +1,66 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// Store keeps manifest lists as JSON files in a directory until they are pushed
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Create returns a new, empty list with the given name. It fails when the list already exists.
+func (s *Store) Create(name string) (*List, error) {
+	if _, err := os.Stat(s.path(name)); err == nil {
+		return nil, fmt.Errorf("manifest list %s already exists", style.Symbol(name))
+	}
+	return &List{Name: name}, nil
+}
+
+func (s *Store) Load(name string) (*List, error) {
+	b, err := ioutil.ReadFile(s.path(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("manifest list %s does not exist, create it with 'pack manifest create'", style.Symbol(name))
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "reading manifest list %s", style.Symbol(name))
+	}
+	var list List
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, errors.Wrapf(err, "parsing manifest list %s", style.Symbol(name))
+	}
+	return &list, nil
+}
+
+func (s *Store) Save(list *List) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path(list.Name), b, 0644)
+}
+
+func (s *Store) Delete(name string) error {
+	return os.Remove(s.path(name))
+}
+
+// path returns the file of the named list, with characters that may not appear in file names
+// replaced, as docker does for its manifest lists
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, strings.NewReplacer("/", "_", ":", "-").Replace(name)+".json")
+}
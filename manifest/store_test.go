@@ -0,0 +1,86 @@
+package manifest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/manifest"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestStore(t *testing.T) {
+	spec.Run(t, "Store", testStore, spec.Report(report.Terminal{}))
+}
+
+func testStore(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+		store  *manifest.Store
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "manifest-store")
+		h.AssertNil(t, err)
+		store = manifest.NewStore(filepath.Join(tmpDir, "manifests"))
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	it("saves and loads lists", func() {
+		list, err := store.Create("registry.example.com/some/list:latest")
+		h.AssertNil(t, err)
+		digest, err := v1.NewHash("sha256:" + strings.Repeat("a", 64))
+		h.AssertNil(t, err)
+		list.Manifests = append(list.Manifests, manifest.Entry{
+			Image:      "some/image@" + digest.String(),
+			Descriptor: v1.Descriptor{Digest: digest},
+		})
+		h.AssertNil(t, store.Save(list))
+
+		loaded, err := store.Load("registry.example.com/some/list:latest")
+		h.AssertNil(t, err)
+		h.AssertEq(t, loaded.Name, "registry.example.com/some/list:latest")
+		h.AssertEq(t, loaded.Manifests, list.Manifests)
+	})
+
+	when("#Create", func() {
+		it("fails when the list already exists", func() {
+			list, err := store.Create("some/list")
+			h.AssertNil(t, err)
+			h.AssertNil(t, store.Save(list))
+
+			_, err = store.Create("some/list")
+			h.AssertError(t, err, "manifest list 'some/list' already exists")
+		})
+	})
+
+	when("#Load", func() {
+		it("fails when the list does not exist", func() {
+			_, err := store.Load("some/list")
+			h.AssertError(t, err, "manifest list 'some/list' does not exist")
+		})
+	})
+
+	when("#Delete", func() {
+		it("removes the list", func() {
+			list, err := store.Create("some/list")
+			h.AssertNil(t, err)
+			h.AssertNil(t, store.Save(list))
+
+			h.AssertNil(t, store.Delete("some/list"))
+
+			_, err = store.Load("some/list")
+			h.AssertError(t, err, "does not exist")
+		})
+	})
+}
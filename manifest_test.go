@@ -0,0 +1,258 @@
+package pack_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/docker"
+	"github.com/buildpack/pack/manifest"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestManifest(t *testing.T) {
+	spec.Run(t, "Manifest", testManifest, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testManifest(t *testing.T, when spec.G, it spec.S) {
+	var (
+		fakeRegistry *h.FakeRegistry
+		client       *pack.Client
+		tmpDir       string
+		listName     string
+	)
+
+	it.Before(func() {
+		fakeRegistry = h.NewFakeRegistry()
+		var err error
+		tmpDir, err = ioutil.TempDir("", "manifest-test")
+		h.AssertNil(t, err)
+		cfg, err := config.New(filepath.Join(tmpDir, "config.toml"))
+		h.AssertNil(t, err)
+		client, err = pack.NewClient(pack.WithConfig(cfg), pack.WithDocker(&docker.Client{}))
+		h.AssertNil(t, err)
+		listName = fakeRegistry.Host() + "/some/list"
+	})
+
+	it.After(func() {
+		fakeRegistry.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	repoName := func(repo string) string {
+		return fakeRegistry.Host() + "/" + repo
+	}
+
+	// push writes an image without layers, built for the given platform, to the registry, returning
+	// its digest
+	push := func(repo, platformOS, arch string) string {
+		t.Helper()
+		config, err := json.Marshal(v1.ConfigFile{OS: platformOS, Architecture: arch, RootFS: v1.RootFS{Type: "layers"}})
+		h.AssertNil(t, err)
+		configDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(config))
+		res, err := http.Post(fmt.Sprintf("%s/v2/%s/blobs/uploads/", fakeRegistry.URL, repo), "", nil)
+		h.AssertNil(t, err)
+		res.Body.Close()
+		put := func(url, contentType string, body []byte) {
+			t.Helper()
+			req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+			h.AssertNil(t, err)
+			req.Header.Set("Content-Type", contentType)
+			res, err := http.DefaultClient.Do(req)
+			h.AssertNil(t, err)
+			res.Body.Close()
+			h.AssertEq(t, res.StatusCode, http.StatusCreated)
+		}
+		put(fakeRegistry.URL+res.Header.Get("Location")+"?digest="+configDigest, "application/octet-stream", config)
+
+		raw, err := json.Marshal(v1.Manifest{
+			SchemaVersion: 2,
+			MediaType:     types.DockerManifestSchema2,
+			Config:        v1.Descriptor{MediaType: types.DockerConfigJSON, Size: int64(len(config)), Digest: mustHash(t, configDigest)},
+			Layers:        []v1.Descriptor{},
+		})
+		h.AssertNil(t, err)
+		put(fmt.Sprintf("%s/v2/%s/manifests/latest", fakeRegistry.URL, repo), string(types.DockerManifestSchema2), raw)
+		return fmt.Sprintf("sha256:%x", sha256.Sum256(raw))
+	}
+
+	// pushedIndex returns the index that PushManifest pushed for the list, by its returned digest
+	pushedIndex := func(digest string) v1.IndexManifest {
+		t.Helper()
+		raw, ok := fakeRegistry.Manifest("some/list", digest)
+		h.AssertEq(t, ok, true)
+		var index v1.IndexManifest
+		h.AssertNil(t, json.Unmarshal(raw, &index))
+		return index
+	}
+
+	when("#CreateManifest", func() {
+		it("creates a list of the images with their platforms", func() {
+			amd64 := push("some/app-amd64", "linux", "amd64")
+			arm64 := push("some/app-arm64", "linux", "arm64")
+
+			h.AssertNil(t, client.CreateManifest(listName, []string{repoName("some/app-amd64"), repoName("some/app-arm64")}))
+			digest, err := client.PushManifest(listName, false)
+			h.AssertNil(t, err)
+
+			index := pushedIndex(digest)
+			h.AssertEq(t, len(index.Manifests), 2)
+			h.AssertEq(t, index.Manifests[0].Digest.String(), amd64)
+			h.AssertEq(t, index.Manifests[0].Platform.OS, "linux")
+			h.AssertEq(t, index.Manifests[0].Platform.Architecture, "amd64")
+			h.AssertEq(t, index.Manifests[1].Digest.String(), arm64)
+			h.AssertEq(t, index.Manifests[1].Platform.Architecture, "arm64")
+		})
+
+		it("fails when the list exists", func() {
+			push("some/app", "linux", "amd64")
+			h.AssertNil(t, client.CreateManifest(listName, []string{repoName("some/app")}))
+
+			err := client.CreateManifest(listName, []string{repoName("some/app")})
+			h.AssertError(t, err, "manifest list '"+listName+"' already exists")
+		})
+
+		it("fails without saving the list when an image is not in the registry", func() {
+			err := client.CreateManifest(listName, []string{repoName("some/missing")})
+			h.AssertError(t, err, repoName("some/missing"))
+
+			h.AssertError(t, client.AddToManifest(listName, repoName("some/missing")), "manifest list '"+listName+"' does not exist")
+		})
+
+		it("fails for invalid image references", func() {
+			err := client.CreateManifest(listName, []string{"Some/App"})
+			h.AssertError(t, err, "invalid image reference 'Some/App'")
+		})
+	})
+
+	when("#AddToManifest", func() {
+		it("adds the image to the list", func() {
+			amd64 := push("some/app-amd64", "linux", "amd64")
+			windows := push("some/app-windows", "windows", "amd64")
+			h.AssertNil(t, client.CreateManifest(listName, []string{repoName("some/app-amd64")}))
+
+			h.AssertNil(t, client.AddToManifest(listName, repoName("some/app-windows")))
+			digest, err := client.PushManifest(listName, false)
+			h.AssertNil(t, err)
+
+			index := pushedIndex(digest)
+			h.AssertEq(t, len(index.Manifests), 2)
+			h.AssertEq(t, index.Manifests[0].Digest.String(), amd64)
+			h.AssertEq(t, index.Manifests[1].Digest.String(), windows)
+			h.AssertEq(t, index.Manifests[1].Platform.OS, "windows")
+		})
+
+		it("fails when the list does not exist", func() {
+			push("some/app", "linux", "amd64")
+
+			err := client.AddToManifest(listName, repoName("some/app"))
+			h.AssertError(t, err, "manifest list '"+listName+"' does not exist, create it with 'pack manifest create'")
+		})
+	})
+
+	when("#AnnotateManifest", func() {
+		var arm string
+
+		it.Before(func() {
+			arm = push("some/app-arm", "linux", "arm")
+			h.AssertNil(t, client.CreateManifest(listName, []string{repoName("some/app-arm")}))
+		})
+
+		it("changes the platform and annotations of an image given by tag", func() {
+			h.AssertNil(t, client.AnnotateManifest(listName, repoName("some/app-arm"), manifest.Annotations{
+				Variant:     "v7",
+				Annotations: map[string]string{"org.example.note": "some-note"},
+			}))
+			digest, err := client.PushManifest(listName, false)
+			h.AssertNil(t, err)
+
+			index := pushedIndex(digest)
+			h.AssertEq(t, index.Manifests[0].Platform.OS, "linux")
+			h.AssertEq(t, index.Manifests[0].Platform.Architecture, "arm")
+			h.AssertEq(t, index.Manifests[0].Platform.Variant, "v7")
+			h.AssertEq(t, index.Manifests[0].Annotations, map[string]string{"org.example.note": "some-note"})
+		})
+
+		it("finds an image given by digest without reaching the registry", func() {
+			requests := len(fakeRegistry.Requests())
+
+			h.AssertNil(t, client.AnnotateManifest(listName, repoName("some/app-arm")+"@"+arm, manifest.Annotations{Variant: "v6"}))
+			h.AssertEq(t, len(fakeRegistry.Requests()), requests)
+		})
+
+		it("fails when the image is not in the list", func() {
+			other := push("some/other", "linux", "amd64")
+
+			err := client.AnnotateManifest(listName, repoName("some/other"), manifest.Annotations{Variant: "v7"})
+			h.AssertError(t, err, "manifest list "+listName+" does not contain an image with digest "+other)
+		})
+	})
+
+	when("#PushManifest", func() {
+		it.Before(func() {
+			push("some/app", "linux", "amd64")
+			h.AssertNil(t, client.CreateManifest(listName, []string{repoName("some/app")}))
+		})
+
+		it("pushes the list as an image index, returning its digest", func() {
+			digest, err := client.PushManifest(listName, false)
+			h.AssertNil(t, err)
+
+			tagged, ok := fakeRegistry.Manifest("some/list", "latest")
+			h.AssertEq(t, ok, true)
+			byDigest, ok := fakeRegistry.Manifest("some/list", digest)
+			h.AssertEq(t, ok, true)
+			h.AssertEq(t, string(tagged), string(byDigest))
+			h.AssertEq(t, string(pushedIndex(digest).MediaType), "application/vnd.oci.image.index.v1+json")
+		})
+
+		it("copies the images into the repository of the list", func() {
+			digest, err := client.PushManifest(listName, false)
+			h.AssertNil(t, err)
+
+			_, ok := fakeRegistry.Manifest("some/list", pushedIndex(digest).Manifests[0].Digest.String())
+			h.AssertEq(t, ok, true)
+		})
+
+		it("keeps the local list", func() {
+			_, err := client.PushManifest(listName, false)
+			h.AssertNil(t, err)
+
+			_, err = client.PushManifest(listName, false)
+			h.AssertNil(t, err)
+		})
+
+		it("deletes the local list when purging", func() {
+			_, err := client.PushManifest(listName, true)
+			h.AssertNil(t, err)
+
+			_, err = client.PushManifest(listName, false)
+			h.AssertError(t, err, "manifest list '"+listName+"' does not exist")
+		})
+
+		it("fails when the list does not exist", func() {
+			_, err := client.PushManifest(repoName("some/other-list"), false)
+			h.AssertError(t, err, "manifest list '"+repoName("some/other-list")+"' does not exist")
+		})
+	})
+}
+
+func mustHash(t *testing.T, digest string) v1.Hash {
+	t.Helper()
+	hash, err := v1.NewHash(digest)
+	h.AssertNil(t, err)
+	return hash
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"os"
+	"time"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+)
+
+// Build holds the metrics of a single completed build
+type Build struct {
+	Image          string
+	Success        bool
+	Duration       time.Duration
+	PullDuration   time.Duration
+	PhaseDurations map[string]time.Duration
+	LayersExported int
+	LayersReused   int
+	ImageSize      int64
+}
+
+// CacheHitRatio is the fraction of exported layers that were reused from a previous image
+func (b *Build) CacheHitRatio() float64 {
+	if b.LayersExported == 0 {
+		return 0
+	}
+	return float64(b.LayersReused) / float64(b.LayersExported)
+}
+
+// Sink sends the metrics of a completed build to a metrics backend
+type Sink interface {
+	Send(b *Build) error
+}
+
+// SinksFromEnv returns a sink for each backend configured by PACK_STATSD_ADDR and PACK_PUSHGATEWAY_URL
+func SinksFromEnv() []Sink {
+	var sinks []Sink
+	if addr := os.Getenv("PACK_STATSD_ADDR"); addr != "" {
+		sinks = append(sinks, &StatsdSink{Addr: addr})
+	}
+	if url := os.Getenv("PACK_PUSHGATEWAY_URL"); url != "" {
+		sinks = append(sinks, &PushgatewaySink{URL: url})
+	}
+	return sinks
+}
+
+// Recorder collects metrics from build events and sends them to its sinks when the build completes
+type Recorder struct {
+	sinks  []Sink
+	logger *logging.Logger
+	start  time.Time
+	build  Build
+}
+
+func NewRecorder(logger *logging.Logger, sinks ...Sink) *Recorder {
+	return &Recorder{
+		sinks:  sinks,
+		logger: logger,
+		start:  time.Now(),
+		build:  Build{PhaseDurations: map[string]time.Duration{}},
+	}
+}
+
+// HandleEvent records e. It can be used as a pack.EventHandler.
+func (r *Recorder) HandleEvent(e pack.Event) {
+	switch e.Type {
+	case pack.ImagePulled:
+		r.build.PullDuration += e.Duration
+	case pack.PhaseFinished:
+		r.build.PhaseDurations[e.Phase] = e.Duration
+	case pack.LayerExported:
+		r.build.LayersExported++
+		if e.Reused {
+			r.build.LayersReused++
+		}
+	case pack.BuildCompleted:
+		r.build.Image = e.Image
+		r.build.Success = e.Err == nil
+		r.build.Duration = e.Time.Sub(r.start)
+		r.build.ImageSize = e.Size
+		for _, sink := range r.sinks {
+			if err := sink.Send(&r.build); err != nil {
+				r.logger.Warn("Failed to send build metrics: %s", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package metrics_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/metrics"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestMetrics(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "metrics", testMetrics, spec.Report(report.Terminal{}))
+}
+
+type fakeSink struct {
+	builds []metrics.Build
+}
+
+func (s *fakeSink) Send(b *metrics.Build) error {
+	s.builds = append(s.builds, *b)
+	return nil
+}
+
+func testMetrics(t *testing.T, when spec.G, it spec.S) {
+	var outBuf bytes.Buffer
+
+	build := &metrics.Build{
+		Image:          "some/app",
+		Success:        true,
+		Duration:       90 * time.Second,
+		PhaseDurations: map[string]time.Duration{"detector": 2 * time.Second, "builder": 60 * time.Second},
+		LayersExported: 4,
+		LayersReused:   3,
+		ImageSize:      1024,
+	}
+
+	when("Recorder", func() {
+		it("sends the metrics of a build to each sink when it completes", func() {
+			sink := &fakeSink{}
+			recorder := metrics.NewRecorder(logging.NewLogger(&outBuf, &outBuf, true, false), sink)
+
+			recorder.HandleEvent(pack.Event{Type: pack.ImagePulled, Duration: time.Second})
+			recorder.HandleEvent(pack.Event{Type: pack.PhaseFinished, Phase: "builder", Duration: time.Minute})
+			recorder.HandleEvent(pack.Event{Type: pack.LayerExported, Reused: true})
+			recorder.HandleEvent(pack.Event{Type: pack.LayerExported})
+			h.AssertEq(t, len(sink.builds), 0)
+
+			recorder.HandleEvent(pack.Event{Type: pack.BuildCompleted, Time: time.Now(), Image: "some/app", Size: 1024, Err: errors.New("failed")})
+			h.AssertEq(t, len(sink.builds), 1)
+			b := sink.builds[0]
+			h.AssertEq(t, b.Image, "some/app")
+			h.AssertEq(t, b.Success, false)
+			h.AssertEq(t, b.PullDuration, time.Second)
+			h.AssertEq(t, b.PhaseDurations["builder"], time.Minute)
+			h.AssertEq(t, b.CacheHitRatio(), 0.5)
+			h.AssertEq(t, b.ImageSize, int64(1024))
+		})
+	})
+
+	when("StatsdSink", func() {
+		it("sends metrics in the statsd line protocol", func() {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			h.AssertNil(t, err)
+			defer conn.Close()
+
+			sink := &metrics.StatsdSink{Addr: conn.LocalAddr().String()}
+			h.AssertNil(t, sink.Send(build))
+
+			buf := make([]byte, 1024)
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, _, err := conn.ReadFrom(buf)
+			h.AssertNil(t, err)
+			h.AssertEq(t, strings.Split(string(buf[:n]), "\n"), []string{
+				"pack.build.success:1|c",
+				"pack.build.duration:90000|ms",
+				"pack.pull.duration:0|ms",
+				"pack.phase.builder.duration:60000|ms",
+				"pack.phase.detector.duration:2000|ms",
+				"pack.cache.hit_ratio:0.75|g",
+				"pack.image.size:1024|g",
+			})
+		})
+	})
+
+	when("PushgatewaySink", func() {
+		it("pushes metrics grouped by image", func() {
+			var (
+				path string
+				body string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				path, body = r.URL.Path, string(b)
+			}))
+			defer server.Close()
+
+			sink := &metrics.PushgatewaySink{URL: server.URL}
+			h.AssertNil(t, sink.Send(build))
+
+			h.AssertEq(t, path, "/metrics/job/pack/image@base64/c29tZS9hcHA")
+			h.AssertContains(t, body, "pack_build_success 1\n")
+			h.AssertContains(t, body, `pack_phase_duration_seconds{phase="builder"} 60`+"\n")
+			h.AssertContains(t, body, "pack_cache_hit_ratio 0.75\n")
+			h.AssertContains(t, body, "pack_image_size_bytes 1024\n")
+		})
+	})
+}
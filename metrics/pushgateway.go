@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PushgatewaySink pushes metrics to a Prometheus pushgateway, grouped by job "pack" and image.
+// The pushgateway keeps only the last value of each metric, so success and failure are pushed
+// as a gauge along with the completion time rather than as counters.
+type PushgatewaySink struct {
+	URL string
+}
+
+func (s *PushgatewaySink) Send(b *Build) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# TYPE pack_build_success gauge\npack_build_success %d\n", boolToInt(b.Success))
+	fmt.Fprintf(&buf, "# TYPE pack_build_last_completion_timestamp_seconds gauge\npack_build_last_completion_timestamp_seconds %d\n", time.Now().Unix())
+	fmt.Fprintf(&buf, "# TYPE pack_build_duration_seconds gauge\npack_build_duration_seconds %g\n", b.Duration.Seconds())
+	fmt.Fprintf(&buf, "# TYPE pack_pull_duration_seconds gauge\npack_pull_duration_seconds %g\n", b.PullDuration.Seconds())
+	fmt.Fprintf(&buf, "# TYPE pack_phase_duration_seconds gauge\n")
+	for _, phase := range sortedPhases(b) {
+		fmt.Fprintf(&buf, "pack_phase_duration_seconds{phase=%q} %g\n", phase, b.PhaseDurations[phase].Seconds())
+	}
+	if b.LayersExported > 0 {
+		fmt.Fprintf(&buf, "# TYPE pack_cache_hit_ratio gauge\npack_cache_hit_ratio %g\n", b.CacheHitRatio())
+	}
+	if b.ImageSize > 0 {
+		fmt.Fprintf(&buf, "# TYPE pack_image_size_bytes gauge\npack_image_size_bytes %d\n", b.ImageSize)
+	}
+
+	// image names contain slashes, so the grouping label value is base64 encoded
+	url := fmt.Sprintf("%s/metrics/job/pack/image@base64/%s",
+		strings.TrimRight(s.URL, "/"), base64.RawURLEncoding.EncodeToString([]byte(b.Image)))
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest(http.MethodPut, url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("pushing metrics to %s: unexpected status %s", s.URL, resp.Status)
+	}
+	return nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
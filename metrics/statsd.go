@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+)
+
+// StatsdSink sends metrics over UDP in the statsd line protocol, prefixed with "pack."
+type StatsdSink struct {
+	Addr string
+}
+
+func (s *StatsdSink) Send(b *Build) error {
+	conn, err := net.Dial("udp", s.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var buf bytes.Buffer
+	if b.Success {
+		fmt.Fprintf(&buf, "pack.build.success:1|c\n")
+	} else {
+		fmt.Fprintf(&buf, "pack.build.failure:1|c\n")
+	}
+	fmt.Fprintf(&buf, "pack.build.duration:%d|ms\n", milliseconds(b.Duration))
+	fmt.Fprintf(&buf, "pack.pull.duration:%d|ms\n", milliseconds(b.PullDuration))
+	for _, phase := range sortedPhases(b) {
+		fmt.Fprintf(&buf, "pack.phase.%s.duration:%d|ms\n", phase, milliseconds(b.PhaseDurations[phase]))
+	}
+	if b.LayersExported > 0 {
+		fmt.Fprintf(&buf, "pack.cache.hit_ratio:%g|g\n", b.CacheHitRatio())
+	}
+	if b.ImageSize > 0 {
+		fmt.Fprintf(&buf, "pack.image.size:%d|g\n", b.ImageSize)
+	}
+
+	_, err = conn.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+func milliseconds(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
+
+func sortedPhases(b *Build) []string {
+	var phases []string
+	for phase := range b.PhaseDurations {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	return phases
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockCache is a mock of Cache interface
@@ -45,6 +46,31 @@ func (mr *MockCacheMockRecorder) Clear(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockCache)(nil).Clear), arg0)
 }
 
+// Dir mocks base method
+func (m *MockCache) Dir() string {
+	ret := m.ctrl.Call(m, "Dir")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Dir indicates an expected call of Dir
+func (mr *MockCacheMockRecorder) Dir() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dir", reflect.TypeOf((*MockCache)(nil).Dir))
+}
+
+// Exists mocks base method
+func (m *MockCache) Exists(arg0 context.Context) (bool, error) {
+	ret := m.ctrl.Call(m, "Exists", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists
+func (mr *MockCacheMockRecorder) Exists(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockCache)(nil).Exists), arg0)
+}
+
 // Image mocks base method
 func (m *MockCache) Image() string {
 	ret := m.ctrl.Call(m, "Image")
@@ -56,3 +82,53 @@ func (m *MockCache) Image() string {
 func (mr *MockCacheMockRecorder) Image() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Image", reflect.TypeOf((*MockCache)(nil).Image))
 }
+
+// Lock mocks base method
+func (m *MockCache) Lock(arg0 context.Context) (func(), error) {
+	ret := m.ctrl.Call(m, "Lock", arg0)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock
+func (mr *MockCacheMockRecorder) Lock(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockCache)(nil).Lock), arg0)
+}
+
+// Prune mocks base method
+func (m *MockCache) Prune(arg0 context.Context, arg1 time.Duration) error {
+	ret := m.ctrl.Call(m, "Prune", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Prune indicates an expected call of Prune
+func (mr *MockCacheMockRecorder) Prune(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockCache)(nil).Prune), arg0, arg1)
+}
+
+// Remote mocks base method
+func (m *MockCache) Remote() bool {
+	ret := m.ctrl.Call(m, "Remote")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Remote indicates an expected call of Remote
+func (mr *MockCacheMockRecorder) Remote() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remote", reflect.TypeOf((*MockCache)(nil).Remote))
+}
+
+// Size mocks base method
+func (m *MockCache) Size(arg0 context.Context) (int64, error) {
+	ret := m.ctrl.Call(m, "Size", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Size indicates an expected call of Size
+func (mr *MockCacheMockRecorder) Size(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Size", reflect.TypeOf((*MockCache)(nil).Size), arg0)
+}
@@ -8,7 +8,9 @@ import (
 	context "context"
 	types "github.com/docker/docker/api/types"
 	container "github.com/docker/docker/api/types/container"
+	filters "github.com/docker/docker/api/types/filters"
 	network "github.com/docker/docker/api/types/network"
+	volume "github.com/docker/docker/api/types/volume"
 	gomock "github.com/golang/mock/gomock"
 	io "io"
 	reflect "reflect"
@@ -101,6 +103,19 @@ func (mr *MockDockerMockRecorder) CopyToContainer(arg0, arg1, arg2, arg3, arg4 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyToContainer", reflect.TypeOf((*MockDocker)(nil).CopyToContainer), arg0, arg1, arg2, arg3, arg4)
 }
 
+// DiskUsage mocks base method
+func (m *MockDocker) DiskUsage(arg0 context.Context) (types.DiskUsage, error) {
+	ret := m.ctrl.Call(m, "DiskUsage", arg0)
+	ret0, _ := ret[0].(types.DiskUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiskUsage indicates an expected call of DiskUsage
+func (mr *MockDockerMockRecorder) DiskUsage(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiskUsage", reflect.TypeOf((*MockDocker)(nil).DiskUsage), arg0)
+}
+
 // ImageBuild mocks base method
 func (m *MockDocker) ImageBuild(arg0 context.Context, arg1 io.Reader, arg2 types.ImageBuildOptions) (types.ImageBuildResponse, error) {
 	ret := m.ctrl.Call(m, "ImageBuild", arg0, arg1, arg2)
@@ -128,6 +143,32 @@ func (mr *MockDockerMockRecorder) ImageInspectWithRaw(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageInspectWithRaw", reflect.TypeOf((*MockDocker)(nil).ImageInspectWithRaw), arg0, arg1)
 }
 
+// ImageList mocks base method
+func (m *MockDocker) ImageList(arg0 context.Context, arg1 types.ImageListOptions) ([]types.ImageSummary, error) {
+	ret := m.ctrl.Call(m, "ImageList", arg0, arg1)
+	ret0, _ := ret[0].([]types.ImageSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImageList indicates an expected call of ImageList
+func (mr *MockDockerMockRecorder) ImageList(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageList", reflect.TypeOf((*MockDocker)(nil).ImageList), arg0, arg1)
+}
+
+// ImageLoad mocks base method
+func (m *MockDocker) ImageLoad(arg0 context.Context, arg1 io.Reader, arg2 bool) (types.ImageLoadResponse, error) {
+	ret := m.ctrl.Call(m, "ImageLoad", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.ImageLoadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImageLoad indicates an expected call of ImageLoad
+func (mr *MockDockerMockRecorder) ImageLoad(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageLoad", reflect.TypeOf((*MockDocker)(nil).ImageLoad), arg0, arg1, arg2)
+}
+
 // ImageRemove mocks base method
 func (m *MockDocker) ImageRemove(arg0 context.Context, arg1 string, arg2 types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
 	ret := m.ctrl.Call(m, "ImageRemove", arg0, arg1, arg2)
@@ -141,16 +182,46 @@ func (mr *MockDockerMockRecorder) ImageRemove(arg0, arg1, arg2 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageRemove", reflect.TypeOf((*MockDocker)(nil).ImageRemove), arg0, arg1, arg2)
 }
 
+// ImageSave mocks base method
+func (m *MockDocker) ImageSave(arg0 context.Context, arg1 []string) (io.ReadCloser, error) {
+	ret := m.ctrl.Call(m, "ImageSave", arg0, arg1)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImageSave indicates an expected call of ImageSave
+func (mr *MockDockerMockRecorder) ImageSave(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageSave", reflect.TypeOf((*MockDocker)(nil).ImageSave), arg0, arg1)
+}
+
+// ImageTag mocks base method
+func (m *MockDocker) ImageTag(arg0 context.Context, arg1, arg2 string) error {
+	ret := m.ctrl.Call(m, "ImageTag", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImageTag indicates an expected call of ImageTag
+func (mr *MockDockerMockRecorder) ImageTag(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageTag", reflect.TypeOf((*MockDocker)(nil).ImageTag), arg0, arg1, arg2)
+}
+
 // PullImage mocks base method
-func (m *MockDocker) PullImage(arg0 context.Context, arg1 string, arg2 io.Writer) error {
-	ret := m.ctrl.Call(m, "PullImage", arg0, arg1, arg2)
+func (m *MockDocker) PullImage(arg0 context.Context, arg1 string, arg2 io.Writer, arg3 ...func(*types.ImagePullOptions)) error {
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PullImage", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PullImage indicates an expected call of PullImage
-func (mr *MockDockerMockRecorder) PullImage(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PullImage", reflect.TypeOf((*MockDocker)(nil).PullImage), arg0, arg1, arg2)
+func (mr *MockDockerMockRecorder) PullImage(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PullImage", reflect.TypeOf((*MockDocker)(nil).PullImage), varargs...)
 }
 
 // RunContainer mocks base method
@@ -165,6 +236,19 @@ func (mr *MockDockerMockRecorder) RunContainer(arg0, arg1, arg2, arg3 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunContainer", reflect.TypeOf((*MockDocker)(nil).RunContainer), arg0, arg1, arg2, arg3)
 }
 
+// VolumeList mocks base method
+func (m *MockDocker) VolumeList(arg0 context.Context, arg1 filters.Args) (volume.VolumeListOKBody, error) {
+	ret := m.ctrl.Call(m, "VolumeList", arg0, arg1)
+	ret0, _ := ret[0].(volume.VolumeListOKBody)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VolumeList indicates an expected call of VolumeList
+func (mr *MockDockerMockRecorder) VolumeList(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VolumeList", reflect.TypeOf((*MockDocker)(nil).VolumeList), arg0, arg1)
+}
+
 // VolumeRemove mocks base method
 func (m *MockDocker) VolumeRemove(arg0 context.Context, arg1 string, arg2 bool) error {
 	ret := m.ctrl.Call(m, "VolumeRemove", arg0, arg1, arg2)
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	image "github.com/buildpack/lifecycle/image"
+	pack "github.com/buildpack/pack"
 	gomock "github.com/golang/mock/gomock"
 	io "io"
 	reflect "reflect"
@@ -62,14 +63,19 @@ func (mr *MockFetcherMockRecorder) FetchRemoteImage(arg0 interface{}) *gomock.Ca
 }
 
 // FetchUpdatedLocalImage mocks base method
-func (m *MockFetcher) FetchUpdatedLocalImage(arg0 context.Context, arg1 string, arg2 io.Writer) (image.Image, error) {
-	ret := m.ctrl.Call(m, "FetchUpdatedLocalImage", arg0, arg1, arg2)
+func (m *MockFetcher) FetchUpdatedLocalImage(arg0 context.Context, arg1 string, arg2 io.Writer, arg3 ...func(*pack.FetchOptions)) (image.Image, error) {
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FetchUpdatedLocalImage", varargs...)
 	ret0, _ := ret[0].(image.Image)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FetchUpdatedLocalImage indicates an expected call of FetchUpdatedLocalImage
-func (mr *MockFetcherMockRecorder) FetchUpdatedLocalImage(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUpdatedLocalImage", reflect.TypeOf((*MockFetcher)(nil).FetchUpdatedLocalImage), arg0, arg1, arg2)
+func (mr *MockFetcherMockRecorder) FetchUpdatedLocalImage(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUpdatedLocalImage", reflect.TypeOf((*MockFetcher)(nil).FetchUpdatedLocalImage), varargs...)
 }
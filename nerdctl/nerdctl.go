@@ -0,0 +1,110 @@
+// Package nerdctl runs the containers of builds with nerdctl on containerd, for hosts with a
+// container runtime but no docker daemon
+package nerdctl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/build"
+)
+
+// Runner runs containers with nerdctl, the docker compatible CLI of containerd. It implements
+// build.ContainerRunner.
+type Runner struct {
+	// Nerdctl is the path of the nerdctl binary. Defaults to 'nerdctl' on the PATH.
+	Nerdctl string
+	// Address is the address of the containerd socket. Defaults to the default of nerdctl.
+	Address string
+	// Namespace is the containerd namespace the containers and volumes are in. Defaults to the
+	// default of nerdctl.
+	Namespace string
+}
+
+var _ build.ContainerRunner = (*Runner)(nil)
+
+func (r *Runner) CreateVolume(ctx context.Context, name string) error {
+	_, err := r.nerdctl(ctx, nil, nil, "volume", "create", "--label", "author=pack", name)
+	return err
+}
+
+func (r *Runner) RemoveVolume(ctx context.Context, name string) error {
+	_, err := r.nerdctl(ctx, nil, nil, "volume", "rm", "--force", name)
+	return err
+}
+
+// Run runs the container and removes it once it exits
+func (r *Runner) Run(ctx context.Context, c build.ContainerSpec, stdin io.Reader, out io.Writer) error {
+	_, err := r.nerdctl(ctx, stdin, out, RunArgs(c, stdin != nil)...)
+	return err
+}
+
+// RunArgs returns the arguments of nerdctl that run the container, attached to its stdin when
+// interactive
+func RunArgs(c build.ContainerSpec, interactive bool) []string {
+	args := []string{"run", "--rm", "--name", c.Name}
+	if interactive {
+		args = append(args, "--interactive")
+	}
+	if c.User != "" {
+		args = append(args, "--user", c.User)
+	}
+	if c.Network != "" {
+		args = append(args, "--network", c.Network)
+	}
+	for _, env := range c.Env {
+		args = append(args, "--env", env)
+	}
+	// mounts are sorted, so that containers are always run with the same arguments
+	var paths []string
+	for path := range c.Mounts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		args = append(args, "--volume", c.Mounts[path]+":"+path)
+	}
+	if len(c.Cmd) > 0 {
+		args = append(args, "--entrypoint", c.Cmd[0], c.Image)
+		return append(args, c.Cmd[1:]...)
+	}
+	return append(args, c.Image)
+}
+
+// nerdctl runs nerdctl, returning its output unless out is set
+func (r *Runner) nerdctl(ctx context.Context, stdin io.Reader, out io.Writer, args ...string) (string, error) {
+	path := r.Nerdctl
+	if path == "" {
+		path = "nerdctl"
+	}
+	if r.Namespace != "" {
+		args = append([]string{"--namespace", r.Namespace}, args...)
+	}
+	if r.Address != "" {
+		args = append([]string{"--address", r.Address}, args...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	if out != nil {
+		cmd.Stdout = out
+		cmd.Stderr = io.MultiWriter(out, &stderr)
+	} else {
+		cmd.Stderr = &stderr
+	}
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", errors.Wrap(err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,94 @@
+package nerdctl_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/nerdctl"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestRunner(t *testing.T) {
+	spec.Run(t, "Runner", testRunner, spec.Report(report.Terminal{}))
+}
+
+func testRunner(t *testing.T, when spec.G, it spec.S) {
+	container := build.ContainerSpec{
+		Name:    "some-container",
+		Image:   "some/builder",
+		User:    "1000:1001",
+		Cmd:     []string{"/lifecycle/detector", "-app", "/workspace"},
+		Env:     []string{"SOME_KEY=some-value"},
+		Mounts:  map[string]string{"/workspace": "some-app", "/layers": "some-layers"},
+		Network: "host",
+	}
+
+	when("#RunArgs", func() {
+		it("runs the command of the container as its entrypoint", func() {
+			h.AssertEq(t, nerdctl.RunArgs(container, false), []string{
+				"run", "--rm", "--name", "some-container",
+				"--user", "1000:1001",
+				"--network", "host",
+				"--env", "SOME_KEY=some-value",
+				"--volume", "some-layers:/layers",
+				"--volume", "some-app:/workspace",
+				"--entrypoint", "/lifecycle/detector", "some/builder", "-app", "/workspace",
+			})
+		})
+
+		it("attaches to stdin when interactive", func() {
+			h.AssertContains(t, strings.Join(nerdctl.RunArgs(container, true), " "), "--rm --name some-container --interactive --user")
+		})
+	})
+
+	when("#Run", func() {
+		var (
+			tmpDir string
+			runner *nerdctl.Runner
+		)
+
+		it.Before(func() {
+			if runtime.GOOS == "windows" {
+				t.Skip("nerdctl is faked with a shell script")
+			}
+			var err error
+			tmpDir, err = ioutil.TempDir("", "nerdctl")
+			h.AssertNil(t, err)
+			// the fake nerdctl prints its arguments and stdin, and fails for images named 'fail'
+			script := "#!/bin/sh\necho \"$@\"\ncat\ncase \"$*\" in *' fail'*) echo 'some failure' >&2; exit 1;; esac\n"
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "nerdctl"), []byte(script), 0755))
+			runner = &nerdctl.Runner{
+				Nerdctl:   filepath.Join(tmpDir, "nerdctl"),
+				Address:   "/some/containerd.sock",
+				Namespace: "some-namespace",
+			}
+		})
+
+		it.After(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		it("runs nerdctl in the namespace, writing stdin to it", func() {
+			var out bytes.Buffer
+			container := build.ContainerSpec{Name: "some-container", Image: "some/builder"}
+			h.AssertNil(t, runner.Run(context.Background(), container, strings.NewReader("some-input"), &out))
+			h.AssertEq(t, out.String(), "--address /some/containerd.sock --namespace some-namespace run --rm --name some-container --interactive some/builder\nsome-input")
+		})
+
+		it("returns the error output of failed containers", func() {
+			var out bytes.Buffer
+			container := build.ContainerSpec{Name: "some-container", Image: "fail"}
+			h.AssertError(t, runner.Run(context.Background(), container, nil, &out), "some failure")
+		})
+	})
+}
@@ -0,0 +1,83 @@
+package pack
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/layout"
+	"github.com/buildpack/pack/style"
+)
+
+const (
+	// OutputOCI saves the app image as an OCI image layout directory
+	OutputOCI = "oci"
+	// OutputDockerArchive saves the app image as a tarball that `docker load` accepts
+	OutputDockerArchive = "docker-archive"
+)
+
+func checkOutput(f *BuildFlags) error {
+	switch f.OutputFormat {
+	case "":
+		if f.OutputPath != "" {
+			return errors.Errorf("an output format of %s or %s is required to save the image", style.Symbol(OutputOCI), style.Symbol(OutputDockerArchive))
+		}
+		return nil
+	case OutputOCI, OutputDockerArchive:
+	default:
+		return errors.Errorf("unknown output format %s, expected %s or %s", style.Symbol(f.OutputFormat), style.Symbol(OutputOCI), style.Symbol(OutputDockerArchive))
+	}
+	if f.OutputPath == "" {
+		return errors.Errorf("an output path is required to save the image as %s", style.Symbol(f.OutputFormat))
+	}
+	if f.Publish {
+		return errors.New("saving the image is not supported when publishing it")
+	}
+	return nil
+}
+
+// save writes the exported app image from the daemon to OutputPath in OutputFormat
+func (b *BuildConfig) save(ctx context.Context) error {
+	b.Logger.Verbose("Saving image %s to %s", style.Symbol(b.RepoName), style.Symbol(b.OutputPath))
+	rc, err := b.Cli.ImageSave(ctx, []string{b.RepoName})
+	if err != nil {
+		return errors.Wrapf(err, "saving image %s", style.Symbol(b.RepoName))
+	}
+	defer rc.Close()
+
+	if b.OutputFormat == OutputDockerArchive {
+		return writeFile(b.OutputPath, rc)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "pack.save")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	archive := filepath.Join(tmpDir, "image.tar")
+	if err := writeFile(archive, rc); err != nil {
+		return err
+	}
+	img, err := tarball.ImageFromPath(archive, nil)
+	if err != nil {
+		return errors.Wrapf(err, "reading image %s", style.Symbol(b.RepoName))
+	}
+	return errors.Wrapf(layout.Write(b.OutputPath, img, b.RepoName), "writing OCI layout %s", style.Symbol(b.OutputPath))
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "writing %s", style.Symbol(path))
+	}
+	return f.Close()
+}
@@ -0,0 +1,93 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/git"
+	"github.com/buildpack/pack/style"
+)
+
+// ProjectDescriptorName is the file in the app directory declaring how the app is built, so that
+// teams can commit the build configuration alongside the source
+const ProjectDescriptorName = "project.toml"
+
+// ProjectDescriptor is the project.toml of an app. Build flags take precedence over it.
+type ProjectDescriptor struct {
+	Image struct {
+		// Name is the name of the app image, when none is given to the build
+		Name string `toml:"name"`
+	} `toml:"image"`
+	Build struct {
+		// Buildpacks are used when no buildpacks are given to the build
+		Buildpacks []ProjectBuildpack `toml:"buildpacks"`
+		// Env is set before the env file and env vars given to the build
+		Env []ProjectEnvVar `toml:"env"`
+		// Include and Exclude are globs selecting the files of the app directory copied into the build,
+		// see build.AppFilter
+		Include []string `toml:"include"`
+		Exclude []string `toml:"exclude"`
+	} `toml:"build"`
+}
+
+// ProjectBuildpack is a buildpack in a builder, given by ID, or a buildpack directory, .tgz archive
+// or URL, given by URI. Paths are relative to the app directory.
+type ProjectBuildpack struct {
+	ID  string `toml:"id"`
+	URI string `toml:"uri"`
+}
+
+type ProjectEnvVar struct {
+	Name  string `toml:"name"`
+	Value string `toml:"value"`
+}
+
+// ReadProjectDescriptor reads the project.toml in appDir, returning nil when there is none. App
+// directories that are Git URLs are cloned during the build, and apps read from stdin are only read
+// then, so their descriptors are not read.
+func ReadProjectDescriptor(appDir string) (*ProjectDescriptor, error) {
+	if _, _, ok := git.ParseURL(appDir); ok || appDir == AppDirStdin {
+		return nil, nil
+	}
+	path := filepath.Join(appDir, ProjectDescriptorName)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var project ProjectDescriptor
+	if _, err := toml.DecodeFile(path, &project); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode project descriptor %s", style.Symbol(path))
+	}
+	for _, bp := range project.Build.Buildpacks {
+		if (bp.ID == "") == (bp.URI == "") {
+			return nil, errors.Errorf("buildpacks in project descriptor %s must have either an id or a uri", style.Symbol(path))
+		}
+	}
+	for _, env := range project.Build.Env {
+		if env.Name == "" {
+			return nil, errors.Errorf("env vars in project descriptor %s must have a name", style.Symbol(path))
+		}
+	}
+	return &project, nil
+}
+
+// buildpacks returns the buildpacks of the descriptor as given to --buildpack, with relative paths
+// resolved against appDir
+func (p *ProjectDescriptor) buildpacks(appDir string) []string {
+	var out []string
+	for _, bp := range p.Build.Buildpacks {
+		switch {
+		case bp.ID != "":
+			out = append(out, bp.ID)
+		case strings.HasPrefix(bp.URI, "http://"), strings.HasPrefix(bp.URI, "https://"), filepath.IsAbs(bp.URI):
+			out = append(out, bp.URI)
+		default:
+			out = append(out, filepath.Join(appDir, bp.URI))
+		}
+	}
+	return out
+}
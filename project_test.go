@@ -0,0 +1,65 @@
+package pack_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestProjectDescriptor(t *testing.T) {
+	spec.Run(t, "ProjectDescriptor", testProjectDescriptor, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testProjectDescriptor(t *testing.T, when spec.G, it spec.S) {
+	when("#ReadProjectDescriptor", func() {
+		var appDir string
+
+		it.Before(func() {
+			var err error
+			appDir, err = ioutil.TempDir("", "pack.project")
+			h.AssertNil(t, err)
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.RemoveAll(appDir))
+		})
+
+		it("returns nil without a project.toml", func() {
+			project, err := pack.ReadProjectDescriptor(appDir)
+			h.AssertNil(t, err)
+			h.AssertNil(t, project)
+		})
+
+		it("reads the include and exclude globs", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "project.toml"), []byte(`
+[build]
+  include = ["src/**"]
+  exclude = ["node_modules"]
+`), 0644))
+
+			project, err := pack.ReadProjectDescriptor(appDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, project.Build.Include, []string{"src/**"})
+			h.AssertEq(t, project.Build.Exclude, []string{"node_modules"})
+		})
+
+		it("fails for buildpacks with both an id and a uri", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "project.toml"), []byte(`
+[[build.buildpacks]]
+  id = "some.buildpack"
+  uri = "some-dir"
+`), 0644))
+
+			_, err := pack.ReadProjectDescriptor(appDir)
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "must have either an id or a uri")
+		})
+	})
+}
@@ -0,0 +1,42 @@
+package pack
+
+import (
+	"fmt"
+)
+
+// PullPolicy determines when the builder, run and lifecycle images of a build are pulled
+type PullPolicy int
+
+const (
+	// PullAlways pulls the images before every build, so that they are up to date
+	PullAlways PullPolicy = iota
+	// PullIfNotPresent pulls the images only when they are not in the docker daemon
+	PullIfNotPresent
+	// PullNever uses the images in the docker daemon, failing when they are not there
+	PullNever
+)
+
+var pullPolicyNames = map[PullPolicy]string{
+	PullAlways:       "always",
+	PullIfNotPresent: "if-not-present",
+	PullNever:        "never",
+}
+
+func (p PullPolicy) String() string {
+	return pullPolicyNames[p]
+}
+
+// Set implements pflag.Value so the policy can be bound directly to a command line flag
+func (p *PullPolicy) Set(s string) error {
+	for policy, name := range pullPolicyNames {
+		if name == s {
+			*p = policy
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown pull policy '%s', must be one of 'always', 'if-not-present' or 'never'", s)
+}
+
+func (p *PullPolicy) Type() string {
+	return "policy"
+}
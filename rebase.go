@@ -66,20 +66,9 @@ func (f *RebaseFactory) RebaseConfigFromFlags(ctx context.Context, flags RebaseF
 			return RebaseConfig{}, err
 		}
 
-		registry, err := config.Registry(flags.RepoName)
+		runImageName, err = f.runImageName(flags.RepoName, appImageMetadata)
 		if err != nil {
-			return RebaseConfig{}, errors.Wrapf(err, "parsing registry from reference '%s'", flags.RepoName)
-		}
-
-		mirrors := make([]string, 0)
-		if localRunImage := f.Config.GetRunImage(appImageMetadata.Stack.RunImage.Image); localRunImage != nil {
-			mirrors = append(mirrors, localRunImage.Mirrors...)
-		}
-		mirrors = append(mirrors, appImageMetadata.Stack.RunImage.Image)
-		mirrors = append(mirrors, appImageMetadata.Stack.RunImage.Mirrors...)
-		runImageName, err = config.ImageByRegistry(registry, mirrors)
-		if err != nil {
-			return RebaseConfig{}, errors.Wrapf(err, "find image by registry")
+			return RebaseConfig{}, err
 		}
 	}
 
@@ -98,8 +87,29 @@ func (f *RebaseFactory) RebaseConfigFromFlags(ctx context.Context, flags RebaseF
 	}, nil
 }
 
+// runImageName returns the run image of the stack of an app image, or the mirror of it in the
+// registry of the app image, preferring the mirrors configured locally
+func (f *RebaseFactory) runImageName(repoName string, metadata lifecycle.AppImageMetadata) (string, error) {
+	registry, err := config.Registry(repoName)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing registry from reference '%s'", repoName)
+	}
+
+	mirrors := make([]string, 0)
+	if localRunImage := f.Config.GetRunImage(metadata.Stack.RunImage.Image); localRunImage != nil {
+		mirrors = append(mirrors, localRunImage.Mirrors...)
+	}
+	mirrors = append(mirrors, metadata.Stack.RunImage.Image)
+	mirrors = append(mirrors, metadata.Stack.RunImage.Mirrors...)
+	runImageName, err := config.ImageByRegistry(registry, mirrors)
+	if err != nil {
+		return "", errors.Wrapf(err, "find image by registry")
+	}
+	return runImageName, nil
+}
+
 func (f *RebaseFactory) Rebase(cfg RebaseConfig) error {
-	label, err := cfg.Image.Label("io.buildpacks.lifecycle.metadata")
+	label, err := cfg.Image.Label(lifecycle.MetadataLabel)
 	if err != nil {
 		return err
 	}
@@ -121,7 +131,10 @@ func (f *RebaseFactory) Rebase(cfg RebaseConfig) error {
 		return err
 	}
 	newLabel, err := json.Marshal(metadata)
-	if err := cfg.Image.SetLabel("io.buildpacks.lifecycle.metadata", string(newLabel)); err != nil {
+	if err != nil {
+		return err
+	}
+	if err := cfg.Image.SetLabel(lifecycle.MetadataLabel, string(newLabel)); err != nil {
 		return err
 	}
 
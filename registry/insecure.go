@@ -0,0 +1,45 @@
+// Package registry lets pack reach image registries served over plain HTTP, such as internal
+// registries without TLS
+package registry
+
+import (
+	"net/http"
+)
+
+// InsecureTransport sends the requests to the given registries over HTTP rather than HTTPS, and the
+// other requests as they are with Base
+type InsecureTransport struct {
+	Base http.RoundTripper
+	// Registries are given as <host>[:<port>], such as 'registry.internal:5000'
+	Registries []string
+}
+
+func (t *InsecureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" && t.IsInsecure(req.URL.Host) {
+		insecure := new(http.Request)
+		*insecure = *req
+		u := *req.URL
+		u.Scheme = "http"
+		insecure.URL = &u
+		req = insecure
+	}
+	return t.Base.RoundTrip(req)
+}
+
+// IsInsecure reports whether the registry, given as <host>[:<port>], is reached over HTTP
+func (t *InsecureTransport) IsInsecure(registry string) bool {
+	for _, insecure := range t.Registries {
+		if insecure == registry {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTransport returns the transport of the registry clients of pack, such as those pushing images
+// and the remote images of ImageFactory, reaching the given insecure registries over HTTP. The
+// other requests are sent with http.DefaultTransport, which is not replaced, so that the other
+// HTTP traffic of the process is left as it is.
+func NewTransport(insecureRegistries ...string) http.RoundTripper {
+	return &InsecureTransport{Base: http.DefaultTransport, Registries: insecureRegistries}
+}
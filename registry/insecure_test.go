@@ -0,0 +1,101 @@
+package registry_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/registry"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestInsecure(t *testing.T) {
+	spec.Run(t, "Insecure", testInsecure, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+type recordingTransport struct {
+	urls []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.urls = append(t.urls, req.URL.String())
+	return &http.Response{StatusCode: http.StatusOK, Request: req}, nil
+}
+
+func testInsecure(t *testing.T, when spec.G, it spec.S) {
+	when("InsecureTransport#RoundTrip", func() {
+		var (
+			base      *recordingTransport
+			transport *registry.InsecureTransport
+		)
+
+		it.Before(func() {
+			base = &recordingTransport{}
+			transport = &registry.InsecureTransport{Base: base, Registries: []string{"registry.internal:5000"}}
+		})
+
+		roundTrip := func(url string) {
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			h.AssertNil(t, err)
+			_, err = transport.RoundTrip(req)
+			h.AssertNil(t, err)
+			h.AssertEq(t, req.URL.String(), url)
+		}
+
+		it("reaches insecure registries over http", func() {
+			roundTrip("https://registry.internal:5000/v2/some/app/manifests/latest")
+			h.AssertEq(t, base.urls, []string{"http://registry.internal:5000/v2/some/app/manifests/latest"})
+		})
+
+		it("leaves the requests to other registries as they are", func() {
+			roundTrip("https://registry.internal/v2/")
+			roundTrip("https://index.docker.io/v2/")
+			h.AssertEq(t, base.urls, []string{"https://registry.internal/v2/", "https://index.docker.io/v2/"})
+		})
+	})
+	when("#NewTransport", func() {
+		var server *httptest.Server
+
+		it.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		get := func(transport http.RoundTripper, url string) error {
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			h.AssertNil(t, err)
+			resp, err := transport.RoundTrip(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		}
+
+		it("reaches the given insecure registries over http", func() {
+			host := strings.TrimPrefix(server.URL, "http://")
+			h.AssertNil(t, get(registry.NewTransport(host), "https://"+host+"/v2/"))
+		})
+
+		it("does not reach the insecure registries of other transports over http", func() {
+			host := strings.TrimPrefix(server.URL, "http://")
+			registry.NewTransport(host)
+			h.AssertNotNil(t, get(registry.NewTransport(), "https://"+host+"/v2/"))
+			h.AssertNotNil(t, get(registry.NewTransport("other.registry:5000"), "https://"+host+"/v2/"))
+		})
+
+		it("leaves http.DefaultTransport as it is", func() {
+			registry.NewTransport(strings.TrimPrefix(server.URL, "http://"))
+			_, ok := http.DefaultTransport.(*registry.InsecureTransport)
+			h.AssertEq(t, ok, false)
+		})
+	})
+}
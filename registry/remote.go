@@ -0,0 +1,296 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buildpack/lifecycle/image"
+	"github.com/buildpack/lifecycle/image/auth"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	v1remote "github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+)
+
+// ImageFactory is an image factory of the lifecycle whose remote images are reached with
+// Transport, such as one returned by NewTransport. The remote images of the lifecycle always use
+// http.DefaultTransport, so they are replaced with a copy that does not.
+type ImageFactory struct {
+	*image.Factory
+	// Transport defaults to http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// NewRemote returns the remote image of repoName, as image.Factory#NewRemote does
+func (f *ImageFactory) NewRemote(repoName string) (image.Image, error) {
+	transport := f.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	img, err := newV1Image(f.Keychain, transport, repoName)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteImage{
+		keychain:  f.Keychain,
+		transport: transport,
+		repoName:  repoName,
+		image:     img,
+		prevOnce:  &sync.Once{},
+	}, nil
+}
+
+type remoteImage struct {
+	keychain   authn.Keychain
+	transport  http.RoundTripper
+	repoName   string
+	image      v1.Image
+	prevLayers []v1.Layer
+	prevOnce   *sync.Once
+}
+
+func newV1Image(keychain authn.Keychain, transport http.RoundTripper, repoName string) (v1.Image, error) {
+	ref, auth, err := auth.ReferenceForRepoName(keychain, repoName)
+	if err != nil {
+		return nil, err
+	}
+	img, err := v1remote.Image(ref, v1remote.WithAuth(auth), v1remote.WithTransport(transport))
+	if err != nil {
+		return nil, fmt.Errorf("connect to repo store '%s': %s", repoName, err.Error())
+	}
+	return img, nil
+}
+
+func (r *remoteImage) Label(key string) (string, error) {
+	cfg, err := r.image.ConfigFile()
+	if err != nil || cfg == nil {
+		return "", fmt.Errorf("failed to get label, image '%s' does not exist", r.repoName)
+	}
+	return cfg.Config.Labels[key], nil
+}
+
+func (r *remoteImage) Env(key string) (string, error) {
+	cfg, err := r.image.ConfigFile()
+	if err != nil || cfg == nil {
+		return "", fmt.Errorf("failed to get env var, image '%s' does not exist", r.repoName)
+	}
+	for _, envVar := range cfg.Config.Env {
+		parts := strings.Split(envVar, "=")
+		if parts[0] == key {
+			return parts[1], nil
+		}
+	}
+	return "", nil
+}
+
+func (r *remoteImage) Rename(name string) {
+	r.repoName = name
+}
+
+func (r *remoteImage) Name() string {
+	return r.repoName
+}
+
+func (r *remoteImage) Found() (bool, error) {
+	if _, err := r.image.RawManifest(); err != nil {
+		if transportErr, ok := err.(*transport.Error); ok && len(transportErr.Errors) > 0 {
+			switch transportErr.Errors[0].Code {
+			case transport.UnauthorizedErrorCode, transport.ManifestUnknownErrorCode:
+				return false, nil
+			}
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *remoteImage) Digest() (string, error) {
+	hash, err := r.image.Digest()
+	if err != nil {
+		return "", fmt.Errorf("failed to get digest for image '%s': %s", r.repoName, err)
+	}
+	return hash.String(), nil
+}
+
+func (r *remoteImage) Rebase(baseTopLayer string, newBase image.Image) error {
+	newBaseRemote, ok := newBase.(*remoteImage)
+	if !ok {
+		return errors.New("expected new base to be a remote image")
+	}
+
+	newImage, err := mutate.Rebase(r.image, &subImage{img: r.image, topSHA: baseTopLayer}, newBaseRemote.image)
+	if err != nil {
+		return errors.Wrap(err, "rebase")
+	}
+	r.image = newImage
+	return nil
+}
+
+func (r *remoteImage) SetLabel(key, val string) error {
+	return r.mutateConfig(func(config *v1.Config) {
+		if config.Labels == nil {
+			config.Labels = map[string]string{}
+		}
+		config.Labels[key] = val
+	})
+}
+
+func (r *remoteImage) SetEnv(key, val string) error {
+	return r.mutateConfig(func(config *v1.Config) {
+		for i, e := range config.Env {
+			if strings.Split(e, "=")[0] == key {
+				config.Env[i] = fmt.Sprintf("%s=%s", key, val)
+				return
+			}
+		}
+		config.Env = append(config.Env, fmt.Sprintf("%s=%s", key, val))
+	})
+}
+
+func (r *remoteImage) SetEntrypoint(ep ...string) error {
+	return r.mutateConfig(func(config *v1.Config) {
+		config.Entrypoint = ep
+	})
+}
+
+func (r *remoteImage) SetCmd(cmd ...string) error {
+	return r.mutateConfig(func(config *v1.Config) {
+		config.Cmd = cmd
+	})
+}
+
+// mutateConfig replaces the image with one whose config is changed by mutateFn
+func (r *remoteImage) mutateConfig(mutateFn func(*v1.Config)) error {
+	configFile, err := r.image.ConfigFile()
+	if err != nil {
+		return err
+	}
+	config := *configFile.Config.DeepCopy()
+	mutateFn(&config)
+	r.image, err = mutate.Config(r.image, config)
+	return err
+}
+
+func (r *remoteImage) TopLayer() (string, error) {
+	all, err := r.image.Layers()
+	if err != nil {
+		return "", err
+	}
+	hex, err := all[len(all)-1].DiffID()
+	if err != nil {
+		return "", err
+	}
+	return hex.String(), nil
+}
+
+func (r *remoteImage) GetLayer(string) (io.ReadCloser, error) {
+	return nil, errors.New("remote image does not implement GetLayer")
+}
+
+func (r *remoteImage) AddLayer(path string) error {
+	layer, err := tarball.LayerFromFile(path)
+	if err != nil {
+		return err
+	}
+	r.image, err = mutate.AppendLayers(r.image, layer)
+	if err != nil {
+		return errors.Wrap(err, "add layer")
+	}
+	return nil
+}
+
+func (r *remoteImage) ReuseLayer(sha string) error {
+	var outerErr error
+	r.prevOnce.Do(func() {
+		prevImage, err := newV1Image(r.keychain, r.transport, r.repoName)
+		if err != nil {
+			outerErr = err
+			return
+		}
+		r.prevLayers, err = prevImage.Layers()
+		if err != nil {
+			outerErr = fmt.Errorf("failed to get layers for previous image with repo name '%s': %s", r.repoName, err)
+		}
+	})
+	if outerErr != nil {
+		return outerErr
+	}
+
+	for _, layer := range r.prevLayers {
+		diffID, err := layer.DiffID()
+		if err != nil {
+			return errors.Wrap(err, "get diff ID for previous image layer")
+		}
+		if sha == diffID.String() {
+			r.image, err = mutate.AppendLayers(r.image, layer)
+			return err
+		}
+	}
+	return fmt.Errorf(`previous image did not have layer with sha '%s'`, sha)
+}
+
+func (r *remoteImage) Save() (string, error) {
+	ref, auth, err := auth.ReferenceForRepoName(r.keychain, r.repoName)
+	if err != nil {
+		return "", err
+	}
+
+	r.image, err = mutate.CreatedAt(r.image, v1.Time{Time: time.Now()})
+	if err != nil {
+		return "", err
+	}
+	if err := v1remote.Write(ref, r.image, auth, r.transport); err != nil {
+		return "", err
+	}
+
+	hex, err := r.image.Digest()
+	if err != nil {
+		return "", err
+	}
+	return hex.String(), nil
+}
+
+func (r *remoteImage) Delete() error {
+	return errors.New("remote image does not implement Delete")
+}
+
+// subImage is the image up to and including the layer topSHA, the base of an image being rebased
+type subImage struct {
+	img    v1.Image
+	topSHA string
+}
+
+func (si *subImage) Layers() ([]v1.Layer, error) {
+	all, err := si.img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	for i, l := range all {
+		d, err := l.DiffID()
+		if err != nil {
+			return nil, err
+		}
+		if d.String() == si.topSHA {
+			return all[:i+1], nil
+		}
+	}
+	return nil, errors.New("could not find base layer in image")
+}
+func (si *subImage) BlobSet() (map[v1.Hash]struct{}, error)  { panic("Not Implemented") }
+func (si *subImage) MediaType() (types.MediaType, error)     { panic("Not Implemented") }
+func (si *subImage) ConfigName() (v1.Hash, error)            { panic("Not Implemented") }
+func (si *subImage) ConfigFile() (*v1.ConfigFile, error)     { panic("Not Implemented") }
+func (si *subImage) RawConfigFile() ([]byte, error)          { panic("Not Implemented") }
+func (si *subImage) Digest() (v1.Hash, error)                { panic("Not Implemented") }
+func (si *subImage) Manifest() (*v1.Manifest, error)         { panic("Not Implemented") }
+func (si *subImage) RawManifest() ([]byte, error)            { panic("Not Implemented") }
+func (si *subImage) LayerByDigest(v1.Hash) (v1.Layer, error) { panic("Not Implemented") }
+func (si *subImage) LayerByDiffID(v1.Hash) (v1.Layer, error) { panic("Not Implemented") }
@@ -0,0 +1,316 @@
+package registry_test
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/buildpack/lifecycle/image"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/registry"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestRemoteImage(t *testing.T) {
+	spec.Run(t, "RemoteImage", testRemoteImage, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+// countingTransport counts the requests it sends with http.DefaultTransport
+type countingTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests++
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func testRemoteImage(t *testing.T, when spec.G, it spec.S) {
+	var (
+		fakeRegistry *h.FakeRegistry
+		transport    *countingTransport
+		factory      *registry.ImageFactory
+	)
+
+	it.Before(func() {
+		fakeRegistry = h.NewFakeRegistry()
+		transport = &countingTransport{}
+		factory = &registry.ImageFactory{
+			Factory:   &image.Factory{Keychain: authn.DefaultKeychain},
+			Transport: transport,
+		}
+	})
+
+	it.After(func() {
+		fakeRegistry.Close()
+	})
+
+	repoName := func(repo string) string {
+		return fakeRegistry.Host() + "/" + repo
+	}
+
+	// push writes an image with the given number of random layers and config to the registry
+	push := func(repo string, layers int64, config v1.Config) v1.Image {
+		t.Helper()
+		img, err := random.Image(100, layers)
+		h.AssertNil(t, err)
+		img, err = mutate.Config(img, config)
+		h.AssertNil(t, err)
+		ref, err := name.ParseReference(repoName(repo), name.WeakValidation)
+		h.AssertNil(t, err)
+		h.AssertNil(t, remote.Write(ref, img, authn.Anonymous, http.DefaultTransport))
+		return img
+	}
+
+	fetch := func(repo string) v1.Image {
+		t.Helper()
+		ref, err := name.ParseReference(repoName(repo), name.WeakValidation)
+		h.AssertNil(t, err)
+		img, err := remote.Image(ref)
+		h.AssertNil(t, err)
+		return img
+	}
+
+	diffIDs := func(img v1.Image) []string {
+		t.Helper()
+		layers, err := img.Layers()
+		h.AssertNil(t, err)
+		var ids []string
+		for _, layer := range layers {
+			id, err := layer.DiffID()
+			h.AssertNil(t, err)
+			ids = append(ids, id.String())
+		}
+		return ids
+	}
+
+	when("#NewRemote", func() {
+		it("reaches the registry with the transport of the factory", func() {
+			push("some/app", 1, v1.Config{})
+
+			img, err := factory.NewRemote(repoName("some/app"))
+			h.AssertNil(t, err)
+			found, err := img.Found()
+			h.AssertNil(t, err)
+			h.AssertEq(t, found, true)
+			h.AssertNotEq(t, transport.requests, 0)
+		})
+
+		it("defaults to http.DefaultTransport", func() {
+			push("some/app", 1, v1.Config{})
+			factory.Transport = nil
+
+			img, err := factory.NewRemote(repoName("some/app"))
+			h.AssertNil(t, err)
+			found, err := img.Found()
+			h.AssertNil(t, err)
+			h.AssertEq(t, found, true)
+			h.AssertEq(t, transport.requests, 0)
+		})
+	})
+
+	when("#Found", func() {
+		it("is false when the image does not exist", func() {
+			img, err := factory.NewRemote(repoName("some/missing"))
+			h.AssertNil(t, err)
+			found, err := img.Found()
+			h.AssertNil(t, err)
+			h.AssertEq(t, found, false)
+		})
+	})
+
+	when("#Label and #Env", func() {
+		it("read the config of the image", func() {
+			push("some/app", 1, v1.Config{
+				Labels: map[string]string{"some-label": "some-value"},
+				Env:    []string{"SOME_KEY=some-value", "OTHER_KEY=other-value"},
+			})
+
+			img, err := factory.NewRemote(repoName("some/app"))
+			h.AssertNil(t, err)
+			label, err := img.Label("some-label")
+			h.AssertNil(t, err)
+			h.AssertEq(t, label, "some-value")
+			env, err := img.Env("OTHER_KEY")
+			h.AssertNil(t, err)
+			h.AssertEq(t, env, "other-value")
+			env, err = img.Env("MISSING_KEY")
+			h.AssertNil(t, err)
+			h.AssertEq(t, env, "")
+		})
+
+		it("fail when the image does not exist", func() {
+			img, err := factory.NewRemote(repoName("some/missing"))
+			h.AssertNil(t, err)
+			_, err = img.Label("some-label")
+			h.AssertError(t, err, "failed to get label, image '"+repoName("some/missing")+"' does not exist")
+			_, err = img.Env("SOME_KEY")
+			h.AssertError(t, err, "failed to get env var, image '"+repoName("some/missing")+"' does not exist")
+		})
+	})
+
+	when("#Save", func() {
+		it("pushes the changed config to the registry, returning the digest", func() {
+			push("some/app", 1, v1.Config{Env: []string{"SOME_KEY=some-value"}})
+
+			img, err := factory.NewRemote(repoName("some/app"))
+			h.AssertNil(t, err)
+			h.AssertNil(t, img.SetLabel("some-label", "some-value"))
+			h.AssertNil(t, img.SetEnv("SOME_KEY", "new-value"))
+			h.AssertNil(t, img.SetEnv("OTHER_KEY", "other-value"))
+			h.AssertNil(t, img.SetEntrypoint("some-entrypoint"))
+			h.AssertNil(t, img.SetCmd("some-arg"))
+			digest, err := img.Save()
+			h.AssertNil(t, err)
+
+			saved := fetch("some/app")
+			savedDigest, err := saved.Digest()
+			h.AssertNil(t, err)
+			h.AssertEq(t, savedDigest.String(), digest)
+			configFile, err := saved.ConfigFile()
+			h.AssertNil(t, err)
+			h.AssertEq(t, configFile.Config.Labels["some-label"], "some-value")
+			h.AssertEq(t, configFile.Config.Env, []string{"SOME_KEY=new-value", "OTHER_KEY=other-value"})
+			h.AssertEq(t, configFile.Config.Entrypoint, []string{"some-entrypoint"})
+			h.AssertEq(t, configFile.Config.Cmd, []string{"some-arg"})
+		})
+
+		it("saves the image under its new name when renamed", func() {
+			push("some/run", 1, v1.Config{})
+
+			img, err := factory.NewRemote(repoName("some/run"))
+			h.AssertNil(t, err)
+			img.Rename(repoName("some/app"))
+			h.AssertEq(t, img.Name(), repoName("some/app"))
+			_, err = img.Save()
+			h.AssertNil(t, err)
+
+			_, ok := fakeRegistry.Manifest("some/app", "latest")
+			h.AssertEq(t, ok, true)
+		})
+	})
+
+	when("#AddLayer", func() {
+		it("appends the layer of a tar file", func() {
+			base := push("some/app", 1, v1.Config{})
+			tmpDir, err := ioutil.TempDir("", "remote-image-test")
+			h.AssertNil(t, err)
+			defer os.RemoveAll(tmpDir)
+			layerPath := filepath.Join(tmpDir, "layer.tar")
+			f, err := os.Create(layerPath)
+			h.AssertNil(t, err)
+			tw := tar.NewWriter(f)
+			h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "some-file", Mode: 0644, Size: 4}))
+			_, err = tw.Write([]byte("some"))
+			h.AssertNil(t, err)
+			h.AssertNil(t, tw.Close())
+			h.AssertNil(t, f.Close())
+
+			img, err := factory.NewRemote(repoName("some/app"))
+			h.AssertNil(t, err)
+			h.AssertNil(t, img.AddLayer(layerPath))
+			topLayer, err := img.TopLayer()
+			h.AssertNil(t, err)
+			_, err = img.Save()
+			h.AssertNil(t, err)
+
+			saved := diffIDs(fetch("some/app"))
+			h.AssertEq(t, saved[:1], diffIDs(base))
+			h.AssertEq(t, len(saved), 2)
+			h.AssertEq(t, saved[1], topLayer)
+		})
+	})
+
+	when("#ReuseLayer", func() {
+		it("appends a layer of the previous image of the same name", func() {
+			run := push("some/run", 1, v1.Config{})
+			prev := push("some/app", 2, v1.Config{})
+
+			img, err := factory.NewRemote(repoName("some/run"))
+			h.AssertNil(t, err)
+			img.Rename(repoName("some/app"))
+			h.AssertNil(t, img.ReuseLayer(diffIDs(prev)[1]))
+			_, err = img.Save()
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, diffIDs(fetch("some/app")), append(diffIDs(run), diffIDs(prev)[1]))
+		})
+
+		it("fails when the previous image does not have the layer", func() {
+			push("some/app", 1, v1.Config{})
+
+			img, err := factory.NewRemote(repoName("some/app"))
+			h.AssertNil(t, err)
+			h.AssertError(t, img.ReuseLayer("sha256:some-missing-layer"), "previous image did not have layer with sha 'sha256:some-missing-layer'")
+		})
+	})
+
+	when("#Rebase", func() {
+		it("replaces the layers up to the top layer of the old base with the new base", func() {
+			oldBase := push("some/old-run", 1, v1.Config{})
+			app, err := mutate.AppendLayers(oldBase, mustLayer(t))
+			h.AssertNil(t, err)
+			ref, err := name.ParseReference(repoName("some/app"), name.WeakValidation)
+			h.AssertNil(t, err)
+			h.AssertNil(t, remote.Write(ref, app, authn.Anonymous, http.DefaultTransport))
+			newBase := push("some/new-run", 2, v1.Config{})
+
+			img, err := factory.NewRemote(repoName("some/app"))
+			h.AssertNil(t, err)
+			newBaseImg, err := factory.NewRemote(repoName("some/new-run"))
+			h.AssertNil(t, err)
+			h.AssertNil(t, img.Rebase(diffIDs(oldBase)[0], newBaseImg))
+			_, err = img.Save()
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, diffIDs(fetch("some/app")), append(diffIDs(newBase), diffIDs(app)[1]))
+		})
+
+		it("fails when the new base is not a remote image", func() {
+			push("some/app", 1, v1.Config{})
+
+			img, err := factory.NewRemote(repoName("some/app"))
+			h.AssertNil(t, err)
+			h.AssertError(t, img.Rebase("some-layer", nil), "expected new base to be a remote image")
+		})
+	})
+
+	it("records the digest of the pushed manifest", func() {
+		push("some/app", 1, v1.Config{})
+		img, err := factory.NewRemote(repoName("some/app"))
+		h.AssertNil(t, err)
+		digest, err := img.Digest()
+		h.AssertNil(t, err)
+
+		manifest, ok := fakeRegistry.Manifest("some/app", digest)
+		h.AssertEq(t, ok, true)
+		var m v1.Manifest
+		h.AssertNil(t, json.Unmarshal(manifest, &m))
+		h.AssertEq(t, len(m.Layers), 1)
+	})
+}
+
+func mustLayer(t *testing.T) v1.Layer {
+	t.Helper()
+	img, err := random.Image(100, 1)
+	h.AssertNil(t, err)
+	layers, err := img.Layers()
+	h.AssertNil(t, err)
+	return layers[0]
+}
@@ -9,14 +9,11 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/buildpack/lifecycle/image"
 	dockertypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/go-connections/nat"
 	"github.com/pkg/errors"
 
-	"github.com/buildpack/pack/cache"
-	"github.com/buildpack/pack/docker"
 	"github.com/buildpack/pack/logging"
 	"github.com/buildpack/pack/style"
 )
@@ -38,7 +35,7 @@ type RunConfig struct {
 	// All below are from BuildConfig
 	RepoName string
 	Cli      Docker
-	Logger   *logging.Logger
+	Logger   Logger
 }
 
 func (bf *BuildFactory) RunConfigFromFlags(ctx context.Context, f *RunFlags) (*RunConfig, error) {
@@ -58,42 +55,20 @@ func (bf *BuildFactory) RunConfigFromFlags(ctx context.Context, f *RunFlags) (*R
 	return rc, nil
 }
 
+// Run builds and runs an app image with a Client writing to outWriter and errWriter
 func Run(ctx context.Context, outWriter, errWriter io.Writer, appDir, buildImage, runImage string, ports []string) error {
-	// TODO: Receive Cache and docker client as an argument of this function
-	dockerClient, err := docker.New()
+	client, err := NewClient(WithLogger(logging.NewLogger(outWriter, errWriter, true, false)))
 	if err != nil {
 		return err
 	}
-	c, err := cache.New(runImage, dockerClient)
-	if err != nil {
-		return err
-	}
-	imageFactory, err := image.NewFactory(image.WithOutWriter(outWriter))
-	if err != nil {
-		return err
-	}
-	imageFetcher := &ImageFetcher{
-		Factory: imageFactory,
-		Docker:  dockerClient,
-	}
-	logger := logging.NewLogger(outWriter, errWriter, true, false)
-	bf, err := DefaultBuildFactory(logger, c, dockerClient, imageFetcher)
-	if err != nil {
-		return err
-	}
-	r, err := bf.RunConfigFromFlags(ctx,
-		&RunFlags{
-			BuildFlags: BuildFlags{
-				AppDir:   appDir,
-				Builder:  buildImage,
-				RunImage: runImage,
-			},
-			Ports: ports,
-		})
-	if err != nil {
-		return err
-	}
-	return r.Run(ctx)
+	return client.Run(ctx, RunFlags{
+		BuildFlags: BuildFlags{
+			AppDir:   appDir,
+			Builder:  buildImage,
+			RunImage: runImage,
+		},
+		Ports: ports,
+	})
 }
 
 func (r *RunConfig) Run(ctx context.Context) error {
@@ -129,7 +104,12 @@ func (r *RunConfig) Run(ctx context.Context) error {
 	defer r.Cli.ContainerRemove(context.Background(), ctr.ID, dockertypes.ContainerRemoveOptions{Force: true})
 
 	logContainerListening(r.Logger, portBindings)
-	if err = r.Cli.RunContainer(ctx, ctr.ID, r.Logger.VerboseWriter(), r.Logger.VerboseErrorWriter()); err != nil {
+	if err = r.Cli.RunContainer(ctx, ctr.ID, logging.VerboseWriter(r.Logger, ""), logging.VerboseErrorWriter(r.Logger, "")); err != nil {
+		if ctx.Err() != nil {
+			// interrupted by the user, the deferred remove stops the container
+			r.Logger.Info("Stopping container %s", style.Symbol(ctr.ID))
+			return nil
+		}
 		return errors.Wrap(err, "run container")
 	}
 
@@ -161,7 +141,7 @@ func parsePorts(ports []string) (nat.PortSet, nat.PortMap, error) {
 	return nat.ParsePortSpecs(ports)
 }
 
-func logContainerListening(logger *logging.Logger, portBindings nat.PortMap) {
+func logContainerListening(logger Logger, portBindings nat.PortMap) {
 	// TODO handle case with multiple ports, for now when there is more than
 	// one port we assume you know what you're doing and don't need guidance
 	if len(portBindings) == 1 {
@@ -73,10 +73,11 @@ func testRun(t *testing.T, when spec.G, it spec.S) {
 				Logger:  logger,
 				Fetcher: mockFetcher,
 				Cache:   mockCache,
-				Config:  &config.Config{},
+				Config:  &config.Config{TrustedBuilders: []string{"some/builder"}},
 			}
 
 			mockCache.EXPECT().Image().Return("some-volume").AnyTimes()
+			mockCache.EXPECT().Dir().AnyTimes()
 		})
 
 		it.After(func() {
@@ -85,6 +86,7 @@ func testRun(t *testing.T, when spec.G, it spec.S) {
 
 		it("creates args RunConfig derived from args BuildConfig", func() {
 			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
 			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil)
 
 			mockRunImage := mocks.NewMockImage(mockController)
@@ -208,6 +210,23 @@ func testRun(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("the container is interrupted", func() {
+			it("removes the container without reporting the interruption as an error", func() {
+				mockBuild.EXPECT().Run(ctx).Return(nil)
+				mockDocker.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), nil, "").Return(ctr, nil)
+				mockDocker.EXPECT().
+					RunContainer(gomock.Any(), ctr.ID, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id string, stdout io.Writer, stderr io.Writer) error {
+						cancel()
+						return ctx.Err()
+					})
+				mockDocker.EXPECT().ContainerRemove(gomock.Any(), ctr.ID, types.ContainerRemoveOptions{Force: true}).Return(nil)
+
+				h.AssertNil(t, subject.Run(ctx))
+				h.AssertContains(t, outBuf.String(), "Stopping container '29aef5a011dd'")
+			})
+		})
+
 		when("the port is not specified", func() {
 			it.Before(func() {
 				subject.Ports = nil
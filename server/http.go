@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/buildpack/pack/archive"
+	"github.com/buildpack/pack/git"
+)
+
+// Handler returns the HTTP API of the server:
+//
+//	POST   /builds             start a build, returning its status. The query parameters 'image'
+//	                           (required), 'builder', 'run-image', 'publish', 'git' and 'ref'
+//	                           describe the build. 'git' must be the https or ssh URL of a remote
+//	                           repository. Unless it is set, the body is a tar archive, optionally
+//	                           gzipped, of the app source, of at most the max source size.
+//	GET    /builds             list the status of all builds
+//	GET    /builds/<id>        get the status of a build
+//	DELETE /builds/<id>        cancel a build
+//	GET    /builds/<id>/logs   stream the output of a build until it finishes
+//	GET    /builds/<id>/events stream the events of a build as JSON lines until it finishes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/builds", s.handleBuilds)
+	mux.HandleFunc("/builds/", s.handleBuild)
+	return mux
+}
+
+func (s *Server) handleBuilds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.list())
+	case http.MethodPost:
+		s.createBuild(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+	}
+}
+
+func (s *Server) createBuild(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := Request{
+		Image:    query.Get("image"),
+		Builder:  query.Get("builder"),
+		RunImage: query.Get("run-image"),
+		Git:      query.Get("git"),
+		GitRef:   query.Get("ref"),
+	}
+	if req.Image == "" {
+		writeError(w, http.StatusBadRequest, "query parameter 'image' is required")
+		return
+	}
+	if req.Git != "" {
+		// only remote repositories are cloned, so that clients cannot build from the files of the host
+		url, ref, ok := git.ParseURL(req.Git)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "query parameter 'git' must be the https or ssh URL of a remote repository")
+			return
+		}
+		req.Git = url
+		if req.GitRef == "" {
+			req.GitRef = ref
+		}
+	}
+	if publish := query.Get("publish"); publish != "" {
+		var err error
+		if req.Publish, err = strconv.ParseBool(publish); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid value '%s' of query parameter 'publish'", publish)
+			return
+		}
+	}
+
+	appDir, err := s.newSourceDir()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "creating app directory: %s", err)
+		return
+	}
+	if req.Git == "" {
+		if err := extractSource(http.MaxBytesReader(w, r.Body, s.maxSourceSize), appDir); err != nil {
+			os.RemoveAll(appDir)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, "app source is larger than %d bytes", tooLarge.Limit)
+				return
+			}
+			writeError(w, http.StatusBadRequest, "extracting app source: %s", err)
+			return
+		}
+	}
+
+	status := s.start(req, appDir)
+	w.Header().Set("Location", "/builds/"+status.ID)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+func (s *Server) handleBuild(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/builds/"), "/")
+	b, status, ok := s.get(parts[0])
+	if !ok || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, "build '%s' not found", parts[0])
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, status)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		b.cancel()
+		w.WriteHeader(http.StatusAccepted)
+	case len(parts) == 2 && parts[1] == "logs" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		followStream(w, r, b.logs)
+	case len(parts) == 2 && parts[1] == "events" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		followStream(w, r, b.events)
+	default:
+		writeError(w, http.StatusNotFound, "%s %s not found", r.Method, r.URL.Path)
+	}
+}
+
+func followStream(w http.ResponseWriter, r *http.Request, s *stream) {
+	flush := func() {}
+	if f, ok := w.(http.Flusher); ok {
+		flush = f.Flush
+	}
+	w.WriteHeader(http.StatusOK)
+	flush()
+	s.follow(r.Context(), w, flush)
+}
+
+// extractSource extracts the tar archive, gzipped or not, in body to dir
+func extractSource(body io.Reader, dir string) error {
+	br := bufio.NewReader(body)
+	magic, err := br.Peek(2)
+	if err != nil {
+		return fmt.Errorf("request body must be a tar archive of the app source")
+	}
+	if magic[0] == 0x1f && magic[1] == 0x8b {
+		return archive.ExtractTarGZ(br, dir)
+	}
+	return archive.ExtractTar(br, dir)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, format string, a ...interface{}) {
+	writeJSON(w, code, map[string]string{"error": fmt.Sprintf(format, a...)})
+}
@@ -0,0 +1,363 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/git"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+// Builder runs the builds of a Server. *pack.Client implements it.
+type Builder interface {
+	Build(ctx context.Context, flags pack.BuildFlags, ops ...func(*pack.BuildFactory)) (*pack.BuildResult, error)
+}
+
+type Status string
+
+const (
+	Queued    Status = "queued"
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+	Canceled  Status = "canceled"
+)
+
+// Request describes a build. The app source is either uploaded with the request or cloned from Git.
+type Request struct {
+	Image    string
+	Builder  string
+	RunImage string
+	Publish  bool
+	// Git is the URL of a repository to clone the app source from, at GitRef when set
+	Git    string
+	GitRef string
+}
+
+// BuildStatus is the state of a build as reported by the API
+type BuildStatus struct {
+	ID       string     `json:"id"`
+	Image    string     `json:"image"`
+	Status   Status     `json:"status"`
+	Error    string     `json:"error,omitempty"`
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	// Result is the image exported by a succeeded build
+	Result *pack.BuildResult `json:"result,omitempty"`
+}
+
+// Server accepts build requests and runs them with a Builder. Builds of different images run
+// concurrently up to a limit, while builds of the same image, which share a cache, run one at a time.
+// Finished builds are forgotten once their TTL has passed.
+type Server struct {
+	builder       Builder
+	workDir       string
+	logger        *logging.Logger
+	logFormat     logging.Format
+	slots         chan struct{}
+	buildTTL      time.Duration
+	maxSourceSize int64
+	ctx           context.Context
+	cancelAll     context.CancelFunc
+	wg            sync.WaitGroup
+
+	mu     sync.Mutex
+	builds map[string]*build
+	// locks holds a lock per image with builds queued or running
+	locks map[string]*imageLock
+}
+
+// imageLock is held while building an image
+type imageLock struct {
+	// ch is buffered to one and sent to while building the image
+	ch chan struct{}
+	// builds is the number of builds of the image queued or running, which remove the lock when
+	// the last of them finishes
+	builds int
+}
+
+type build struct {
+	status BuildStatus
+	cancel context.CancelFunc
+	logs   *stream
+	events *stream
+}
+
+// WithMaxConcurrentBuilds limits the number of builds running at once. Defaults to 2.
+func WithMaxConcurrentBuilds(n int) func(*Server) {
+	return func(s *Server) {
+		s.slots = make(chan struct{}, n)
+	}
+}
+
+// WithBuildTTL sets how long the status and output of finished builds are kept. Defaults to an hour.
+func WithBuildTTL(ttl time.Duration) func(*Server) {
+	return func(s *Server) {
+		s.buildTTL = ttl
+	}
+}
+
+// WithMaxSourceSize limits the size of the app sources uploaded with build requests. Defaults to 1GB.
+func WithMaxSourceSize(size int64) func(*Server) {
+	return func(s *Server) {
+		s.maxSourceSize = size
+	}
+}
+
+// WithLogFormat sets the format of the logs of builds. Defaults to text.
+func WithLogFormat(format logging.Format) func(*Server) {
+	return func(s *Server) {
+		s.logFormat = format
+	}
+}
+
+// WithLogger sets the logger receiving a line about each build started and finished
+func WithLogger(logger *logging.Logger) func(*Server) {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// New returns a server running builds with builder, keeping app sources in workDir while building
+func New(builder Builder, workDir string, ops ...func(*Server)) *Server {
+	s := &Server{
+		builder:       builder,
+		workDir:       workDir,
+		slots:         make(chan struct{}, 2),
+		buildTTL:      time.Hour,
+		maxSourceSize: 1024 * 1024 * 1024,
+		builds:        map[string]*build{},
+		locks:         map[string]*imageLock{},
+	}
+	s.ctx, s.cancelAll = context.WithCancel(context.Background())
+	for _, op := range ops {
+		op(s)
+	}
+	return s
+}
+
+// Close cancels all builds and waits for them to finish
+func (s *Server) Close() {
+	s.cancelAll()
+	s.wg.Wait()
+}
+
+// newSourceDir returns a new directory for the app source of a build
+func (s *Server) newSourceDir() (string, error) {
+	if err := os.MkdirAll(s.workDir, 0755); err != nil {
+		return "", err
+	}
+	return ioutil.TempDir(s.workDir, "app")
+}
+
+// start queues a build of the app source in appDir, which is removed once the build finishes
+func (s *Server) start(req Request, appDir string) BuildStatus {
+	ctx, cancel := context.WithCancel(s.ctx)
+	b := &build{
+		status: BuildStatus{
+			ID:      newBuildID(),
+			Image:   req.Image,
+			Status:  Queued,
+			Created: time.Now(),
+		},
+		cancel: cancel,
+		logs:   newStream(),
+		events: newStream(),
+	}
+
+	s.mu.Lock()
+	s.removeExpired()
+	s.builds[b.status.ID] = b
+	lock, ok := s.locks[req.Image]
+	if !ok {
+		lock = &imageLock{ch: make(chan struct{}, 1)}
+		s.locks[req.Image] = lock
+	}
+	lock.builds++
+	status := b.status
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+
+		result, err := s.run(ctx, b, req, appDir, lock.ch)
+		os.RemoveAll(appDir)
+		s.mu.Lock()
+		if lock.builds--; lock.builds == 0 {
+			delete(s.locks, req.Image)
+		}
+		s.mu.Unlock()
+		// the build is only reported finished once it has released the image and removed its source
+		s.finish(ctx, b, result, err)
+	}()
+	return status
+}
+
+// run runs the build once no other build of the image is running and there is a free slot
+func (s *Server) run(ctx context.Context, b *build, req Request, appDir string, lock chan struct{}) (*pack.BuildResult, error) {
+	for _, sem := range []chan struct{}{lock, s.slots} {
+		select {
+		case sem <- struct{}{}:
+			defer func(sem chan struct{}) { <-sem }(sem)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	status := s.update(b, func(status *BuildStatus) {
+		now := time.Now()
+		status.Status = Running
+		status.Started = &now
+	})
+	s.info("Started build %s of %s", status.ID, style.Symbol(req.Image))
+
+	if req.Git != "" {
+		if err := git.Clone(ctx, req.Git, req.GitRef, appDir); err != nil {
+			return nil, err
+		}
+	}
+	logger := logging.NewLogger(b.logs, b.logs, true, false, logging.WithNonInteractive(), logging.WithFormat(s.logFormat))
+	return s.builder.Build(ctx,
+		pack.BuildFlags{
+			AppDir:   appDir,
+			Builder:  req.Builder,
+			RunImage: req.RunImage,
+			RepoName: req.Image,
+			Publish:  req.Publish,
+		},
+		pack.WithBuildLogger(logger),
+		pack.WithEventHandler(func(e pack.Event) {
+			writeEvent(b.events, e)
+		}),
+	)
+}
+
+func (s *Server) finish(ctx context.Context, b *build, result *pack.BuildResult, err error) {
+	status := s.update(b, func(status *BuildStatus) {
+		now := time.Now()
+		status.Finished = &now
+		switch {
+		case ctx.Err() == context.Canceled:
+			status.Status = Canceled
+		case err != nil:
+			status.Status = Failed
+			status.Error = err.Error()
+		default:
+			status.Status = Succeeded
+			status.Result = result
+		}
+	})
+	if status.Status == Failed {
+		fmt.Fprintf(b.logs, "ERROR: %s\n", err)
+	}
+	b.logs.Close()
+	b.events.Close()
+	s.info("Build %s of %s %s", status.ID, style.Symbol(status.Image), status.Status)
+}
+
+// removeExpired forgets the builds that finished longer ago than the TTL. s.mu must be held.
+func (s *Server) removeExpired() {
+	for id, b := range s.builds {
+		if b.status.Finished != nil && time.Since(*b.status.Finished) > s.buildTTL {
+			delete(s.builds, id)
+		}
+	}
+}
+
+// update changes the status of b, returning the changed status
+func (s *Server) update(b *build, f func(*BuildStatus)) BuildStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(&b.status)
+	return b.status
+}
+
+func (s *Server) get(id string) (*build, BuildStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+	b, ok := s.builds[id]
+	if !ok {
+		return nil, BuildStatus{}, false
+	}
+	return b, b.status, true
+}
+
+func (s *Server) list() []BuildStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+	statuses := make([]BuildStatus, 0, len(s.builds))
+	for _, b := range s.builds {
+		statuses = append(statuses, b.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Created.Before(statuses[j].Created)
+	})
+	return statuses
+}
+
+func (s *Server) info(format string, a ...interface{}) {
+	if s.logger != nil {
+		s.logger.Info(format, a...)
+	}
+}
+
+// event is the JSON form of a pack.Event
+type event struct {
+	Type     pack.EventType `json:"type"`
+	Time     time.Time      `json:"time"`
+	Phase    string         `json:"phase,omitempty"`
+	Image    string         `json:"image,omitempty"`
+	Layer    string         `json:"layer,omitempty"`
+	Reused   bool           `json:"reused,omitempty"`
+	Digest   string         `json:"digest,omitempty"`
+	Duration time.Duration  `json:"duration,omitempty"`
+	Size     int64          `json:"size,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+func writeEvent(events *stream, e pack.Event) {
+	je := event{
+		Type:     e.Type,
+		Time:     e.Time,
+		Phase:    e.Phase,
+		Image:    e.Image,
+		Layer:    e.Layer,
+		Reused:   e.Reused,
+		Digest:   e.Digest,
+		Duration: e.Duration,
+		Size:     e.Size,
+	}
+	if e.Err != nil {
+		je.Error = e.Err.Error()
+	}
+	b, err := json.Marshal(je)
+	if err != nil {
+		return
+	}
+	events.Write(append(b, '\n'))
+}
+
+func newBuildID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(errors.Wrap(err, "generating build id"))
+	}
+	return hex.EncodeToString(b)
+}
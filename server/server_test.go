@@ -0,0 +1,288 @@
+package server_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/server"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestServer(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "Server", testServer, spec.Report(report.Terminal{}))
+}
+
+// fakeBuilder records the builds it runs, blocking each until it is released or canceled
+type fakeBuilder struct {
+	started chan pack.BuildFlags
+	release chan error
+}
+
+func (f *fakeBuilder) Build(ctx context.Context, flags pack.BuildFlags, ops ...func(*pack.BuildFactory)) (*pack.BuildResult, error) {
+	bf := &pack.BuildFactory{}
+	for _, op := range ops {
+		op(bf)
+	}
+	contents, _ := ioutil.ReadFile(filepath.Join(flags.AppDir, "some-file.txt"))
+	bf.Logger.Info("building %s from %s", flags.RepoName, contents)
+	bf.OnEvent(pack.Event{Type: pack.PhaseStarted, Phase: "detector"})
+
+	f.started <- flags
+	select {
+	case err := <-f.release:
+		if err != nil {
+			return nil, err
+		}
+		return &pack.BuildResult{Image: flags.RepoName, Digest: "sha256:some-digest"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func testServer(t *testing.T, when spec.G, it spec.S) {
+	var (
+		builder *fakeBuilder
+		srv     *server.Server
+		ts      *httptest.Server
+		tmpDir  string
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "pack.server.test")
+		h.AssertNil(t, err)
+		builder = &fakeBuilder{started: make(chan pack.BuildFlags, 10), release: make(chan error, 10)}
+		srv = server.New(builder, tmpDir, server.WithMaxConcurrentBuilds(2))
+		ts = httptest.NewServer(srv.Handler())
+	})
+
+	it.After(func() {
+		ts.Close()
+		srv.Close()
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	appSource := func() *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "some-file.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 12}))
+		_, err := tw.Write([]byte("some-content"))
+		h.AssertNil(t, err)
+		h.AssertNil(t, tw.Close())
+		return &buf
+	}
+
+	startBuild := func(image string) server.BuildStatus {
+		t.Helper()
+		res, err := http.Post(ts.URL+"/builds?image="+image, "application/x-tar", appSource())
+		h.AssertNil(t, err)
+		defer res.Body.Close()
+		h.AssertEq(t, res.StatusCode, http.StatusAccepted)
+		var status server.BuildStatus
+		h.AssertNil(t, json.NewDecoder(res.Body).Decode(&status))
+		return status
+	}
+
+	getStatus := func(id string) server.BuildStatus {
+		t.Helper()
+		res, err := http.Get(ts.URL + "/builds/" + id)
+		h.AssertNil(t, err)
+		defer res.Body.Close()
+		var status server.BuildStatus
+		h.AssertNil(t, json.NewDecoder(res.Body).Decode(&status))
+		return status
+	}
+
+	getStream := func(path string) string {
+		t.Helper()
+		res, err := http.Get(ts.URL + path)
+		h.AssertNil(t, err)
+		defer res.Body.Close()
+		b, err := ioutil.ReadAll(res.Body)
+		h.AssertNil(t, err)
+		return string(b)
+	}
+
+	waitForBuild := func() pack.BuildFlags {
+		t.Helper()
+		select {
+		case flags := <-builder.started:
+			return flags
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a build to start")
+			return pack.BuildFlags{}
+		}
+	}
+
+	it("builds uploaded app sources and streams their output", func() {
+		status := startBuild("some/image")
+		h.AssertEq(t, status.Image, "some/image")
+
+		flags := waitForBuild()
+		h.AssertEq(t, flags.RepoName, "some/image")
+		builder.release <- nil
+
+		h.AssertContains(t, getStream("/builds/"+status.ID+"/logs"), "building some/image from some-content")
+		h.AssertContains(t, getStream("/builds/"+status.ID+"/events"), `"type":"phase-started","time":"0001-01-01T00:00:00Z","phase":"detector"`)
+		h.AssertEq(t, getStatus(status.ID).Status, server.Succeeded)
+		h.AssertEq(t, getStatus(status.ID).Result.Digest, "sha256:some-digest")
+
+		_, err := os.Stat(flags.AppDir)
+		h.AssertEq(t, os.IsNotExist(err), true)
+	})
+
+	it("formats build logs", func() {
+		ts.Close()
+		srv.Close()
+		srv = server.New(builder, tmpDir, server.WithLogFormat(logging.JSON))
+		ts = httptest.NewServer(srv.Handler())
+
+		status := startBuild("some/image")
+		waitForBuild()
+		builder.release <- nil
+
+		h.AssertContains(t, getStream("/builds/"+status.ID+"/logs"), `"level":"info","message":"building some/image from some-content"}`)
+	})
+
+	it("reports failed builds", func() {
+		status := startBuild("some/image")
+		waitForBuild()
+		builder.release <- context.DeadlineExceeded
+
+		h.AssertContains(t, getStream("/builds/"+status.ID+"/logs"), "ERROR: context deadline exceeded")
+		h.AssertEq(t, getStatus(status.ID).Error, "context deadline exceeded")
+		h.AssertEq(t, getStatus(status.ID).Status, server.Failed)
+	})
+
+	it("builds one image at a time", func() {
+		first := startBuild("some/image")
+		second := startBuild("some/image")
+		waitForBuild()
+		h.AssertEq(t, getStatus(second.ID).Status, server.Queued)
+
+		builder.release <- nil
+		getStream("/builds/" + first.ID + "/logs")
+		waitForBuild()
+		h.AssertEq(t, getStatus(second.ID).Status, server.Running)
+		builder.release <- nil
+	})
+
+	it("cancels builds", func() {
+		status := startBuild("some/image")
+		waitForBuild()
+
+		req, err := http.NewRequest(http.MethodDelete, ts.URL+"/builds/"+status.ID, nil)
+		h.AssertNil(t, err)
+		res, err := http.DefaultClient.Do(req)
+		h.AssertNil(t, err)
+		res.Body.Close()
+		h.AssertEq(t, res.StatusCode, http.StatusAccepted)
+
+		getStream("/builds/" + status.ID + "/logs")
+		h.AssertEq(t, getStatus(status.ID).Status, server.Canceled)
+	})
+
+	it("forgets finished builds once their TTL has passed", func() {
+		ts.Close()
+		srv.Close()
+		srv = server.New(builder, tmpDir, server.WithBuildTTL(time.Millisecond))
+		ts = httptest.NewServer(srv.Handler())
+
+		status := startBuild("some/image")
+		waitForBuild()
+		builder.release <- nil
+		getStream("/builds/" + status.ID + "/logs")
+		time.Sleep(10 * time.Millisecond)
+
+		res, err := http.Get(ts.URL + "/builds/" + status.ID)
+		h.AssertNil(t, err)
+		res.Body.Close()
+		h.AssertEq(t, res.StatusCode, http.StatusNotFound)
+	})
+
+	it("keeps running builds past their TTL", func() {
+		ts.Close()
+		srv.Close()
+		srv = server.New(builder, tmpDir, server.WithBuildTTL(time.Millisecond))
+		ts = httptest.NewServer(srv.Handler())
+
+		status := startBuild("some/image")
+		waitForBuild()
+		time.Sleep(10 * time.Millisecond)
+		h.AssertEq(t, getStatus(status.ID).Status, server.Running)
+		builder.release <- nil
+	})
+
+	it("rejects app sources larger than the max source size", func() {
+		ts.Close()
+		srv.Close()
+		srv = server.New(builder, tmpDir, server.WithMaxSourceSize(100))
+		ts = httptest.NewServer(srv.Handler())
+
+		res, err := http.Post(ts.URL+"/builds?image=some/image", "application/x-tar", appSource())
+		h.AssertNil(t, err)
+		defer res.Body.Close()
+		h.AssertEq(t, res.StatusCode, http.StatusRequestEntityTooLarge)
+	})
+
+	it("requires an image", func() {
+		res, err := http.Post(ts.URL+"/builds", "application/x-tar", appSource())
+		h.AssertNil(t, err)
+		defer res.Body.Close()
+		h.AssertEq(t, res.StatusCode, http.StatusBadRequest)
+	})
+
+	it("rejects app sources writing outside of the app directory through symlinks", func() {
+		outsideDir, err := ioutil.TempDir("", "pack.server.outside")
+		h.AssertNil(t, err)
+		defer os.RemoveAll(outsideDir)
+
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: outsideDir}))
+		h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "link/pwned", Typeflag: tar.TypeReg, Mode: 0644, Size: 4}))
+		_, err = tw.Write([]byte("evil"))
+		h.AssertNil(t, err)
+		h.AssertNil(t, tw.Close())
+
+		res, err := http.Post(ts.URL+"/builds?image=some/image", "application/x-tar", &buf)
+		h.AssertNil(t, err)
+		defer res.Body.Close()
+		h.AssertEq(t, res.StatusCode, http.StatusBadRequest)
+		_, err = os.Stat(filepath.Join(outsideDir, "pwned"))
+		h.AssertEq(t, os.IsNotExist(err), true)
+	})
+
+	it("rejects Git repositories that are not remote", func() {
+		for _, repo := range []string{"file:///some/repo.git", "/some/repo", "../some/repo.git", "ext::sh -c touch% /tmp/pwned"} {
+			res, err := http.Post(ts.URL+"/builds?image=some/image&git="+url.QueryEscape(repo), "application/x-tar", nil)
+			h.AssertNil(t, err)
+			res.Body.Close()
+			h.AssertEq(t, res.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	it("rejects bodies that are not tar archives", func() {
+		res, err := http.Post(ts.URL+"/builds?image=some/image", "application/x-tar", bytes.NewBufferString("not a tar"))
+		h.AssertNil(t, err)
+		defer res.Body.Close()
+		h.AssertEq(t, res.StatusCode, http.StatusBadRequest)
+	})
+}
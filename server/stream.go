@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// stream is an append-only buffer that any number of readers follow from the start until it is
+// closed, so that clients connecting late to a build still receive all of its output
+type stream struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    []byte
+	closed bool
+}
+
+func newStream() *stream {
+	s := &stream{}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *stream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	s.buf = append(s.buf, p...)
+	s.cond.Broadcast()
+	return len(p), nil
+}
+
+func (s *stream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.cond.Broadcast()
+	return nil
+}
+
+// follow copies the stream to w, calling flush after each write, until the stream is closed or
+// ctx is done
+func (s *stream) follow(ctx context.Context, w io.Writer, flush func()) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	offset := 0
+	for {
+		s.mu.Lock()
+		for offset == len(s.buf) && !s.closed && ctx.Err() == nil {
+			s.cond.Wait()
+		}
+		chunk, closed := s.buf[offset:], s.closed
+		s.mu.Unlock()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(chunk) > 0 {
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+			flush()
+			offset += len(chunk)
+		}
+		if closed {
+			return nil
+		}
+	}
+}
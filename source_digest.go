@@ -0,0 +1,162 @@
+package pack
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/style"
+)
+
+// SourceDigestLabel is the label of app images built with BuildFlags.SkipUnchanged, holding the
+// digest of the app source and of the inputs of the build, see BuildConfig
+const SourceDigestLabel = "io.buildpacks.source-digest"
+
+// sourceDigest returns the digest of the app source of the build, as copied into it, together with
+// everything else given to the build that changes the app image: the IDs of the builder and run
+// images, the contents of the buildpack directories, and the environment, platform files and
+// volumes of the build.
+func (bf *BuildFactory) sourceDigest(ctx context.Context, f *BuildFlags, b *BuildConfig, metadata *builder.Metadata) (string, error) {
+	appDigest, err := build.SourceDigest(b.LifecycleConfig.AppDir, f.Include, f.Exclude)
+	if err != nil {
+		return "", errors.Wrapf(err, "computing the digest of app %s", style.Symbol(f.AppDir))
+	}
+	builderID, err := bf.imageID(ctx, b.Builder, false)
+	if err != nil {
+		return "", err
+	}
+	runImageID, err := bf.imageID(ctx, b.RunImage, b.Publish)
+	if err != nil {
+		return "", err
+	}
+	// buildpacks in the builder are given as <id>@<version>, the others as the directories they were
+	// fetched or extracted to, whose paths change between builds
+	var buildpacks []string
+	for _, bp := range b.LifecycleConfig.Buildpacks {
+		if fi, err := os.Stat(bp); err == nil && fi.IsDir() {
+			digest, err := build.SourceDigest(bp, nil, nil)
+			if err != nil {
+				return "", errors.Wrapf(err, "computing the digest of buildpack %s", style.Symbol(bp))
+			}
+			bp = digest
+		}
+		buildpacks = append(buildpacks, bp)
+	}
+	// maps are encoded with sorted keys, so the same inputs always have the same encoding
+	inputs, err := json.Marshal(struct {
+		App             string
+		BuilderID       string
+		BuilderMetadata *builder.Metadata
+		RunImageID      string
+		Buildpacks      []string
+		Env             map[string]string
+		PlatformFiles   map[string][]byte
+		Volumes         []string
+		DefaultProcess  string
+		Labels          map[string]string
+		ExposedPorts    []string
+		Args            []string
+		WorkingDir      string
+	}{appDigest, builderID, metadata, runImageID, buildpacks, b.LifecycleConfig.Env, b.LifecycleConfig.PlatformFiles, b.LifecycleConfig.Volumes, f.DefaultProcess, f.Labels, b.ExposedPorts, b.Args, b.WorkingDir})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(inputs)), nil
+}
+
+// imageID returns the ID of the local image, or the digest of the remote one
+func (bf *BuildFactory) imageID(ctx context.Context, name string, remote bool) (string, error) {
+	if remote {
+		img, err := bf.Fetcher.FetchRemoteImage(name)
+		if err != nil {
+			return "", err
+		}
+		return img.Digest()
+	}
+	inspect, _, err := bf.Cli.ImageInspectWithRaw(ctx, name)
+	if err != nil {
+		return "", errors.Wrapf(err, "inspecting image %s", style.Symbol(name))
+	}
+	return inspect.ID, nil
+}
+
+// reuseImage gives the app image name of the build, and its additional tags, to an image already
+// built from the same source with the same inputs, reporting whether there was one. Builds in the
+// daemon reuse any image in the daemon, while published builds only reuse the image they would
+// replace at the registry.
+func (b *BuildConfig) reuseImage(ctx context.Context) (bool, error) {
+	if b.Publish {
+		ref, err := name.ParseReference(b.RepoName, name.WeakValidation)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid image name %s", style.Symbol(b.RepoName))
+		}
+		img, err := remoteImage(ref, registryTransport(b.Config))
+		if err != nil {
+			b.Logger.Verbose("Building image %s, which cannot be fetched: %s", style.Symbol(b.RepoName), err)
+			return false, nil
+		}
+		configFile, err := img.ConfigFile()
+		if err != nil {
+			return false, errors.Wrapf(err, "reading config of image %s", style.Symbol(b.RepoName))
+		}
+		if configFile.Config.Labels[SourceDigestLabel] != b.SourceDigest {
+			return false, nil
+		}
+		b.Logger.Info("Skipping build, image %s was built from the same source", style.Symbol(b.RepoName))
+		return true, b.tag(ctx)
+	}
+
+	images, err := b.Cli.ImageList(ctx, types.ImageListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", SourceDigestLabel+"="+b.SourceDigest)),
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "listing images built from the same source")
+	}
+	if len(images) == 0 {
+		return false, nil
+	}
+	latest := images[0]
+	for _, img := range images[1:] {
+		if img.Created > latest.Created {
+			latest = img
+		}
+	}
+	b.Logger.Info("Skipping build, image %s was built from the same source", style.Symbol(latest.ID))
+	b.Logger.Verbose("Tagging image %s as %s", style.Symbol(latest.ID), style.Symbol(b.RepoName))
+	if err := b.Cli.ImageTag(ctx, latest.ID, b.RepoName); err != nil {
+		return false, errors.Wrapf(err, "tagging image %s as %s", style.Symbol(latest.ID), style.Symbol(b.RepoName))
+	}
+	return true, b.tag(ctx)
+}
+
+// reusedResult returns the result of a build that reused an image, which has no phases, build
+// metadata or cache to update
+func (b *BuildConfig) reusedResult(ctx context.Context) (*BuildResult, error) {
+	result, err := b.result(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	b.logResult(result)
+
+	if b.OutputFormat != "" {
+		b.Logger.Verbose(style.Step("SAVING"))
+		if err := b.save(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if b.ReportPath != "" {
+		if err := b.writeReport(result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,210 @@
+package pack_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/fatih/color"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestSourceDigest(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "SourceDigest", testSourceDigest, spec.Report(report.Terminal{}))
+}
+
+func testSourceDigest(t *testing.T, when spec.G, it spec.S) {
+	var (
+		outBuf         bytes.Buffer
+		logger         *logging.Logger
+		mockController *gomock.Controller
+		mockDocker     *mocks.MockDocker
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockDocker = mocks.NewMockDocker(mockController)
+		logger = logging.NewLogger(&outBuf, &outBuf, true, false)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#BuildConfigFromFlags", func() {
+		var (
+			appDir     string
+			factory    *pack.BuildFactory
+			builderID  string
+			runImageID string
+		)
+
+		it.Before(func() {
+			var err error
+			appDir, err = ioutil.TempDir("", "pack.source-digest")
+			h.AssertNil(t, err)
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "app.js"), []byte("console.log('hi')"), 0644))
+
+			mockCache := mocks.NewMockCache(mockController)
+			mockCache.EXPECT().Image().AnyTimes()
+			mockCache.EXPECT().Dir().AnyTimes()
+			mockFetcher := mocks.NewMockFetcher(mockController)
+			mockBuilderImage := mocks.NewMockImage(mockController)
+			mockBuilderImage.EXPECT().Label("io.buildpacks.builder.metadata").Return(`{"stack":{"runImage": {"image": "some/run"}}}`, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/builder", gomock.Any()).Return(mockBuilderImage, nil).AnyTimes()
+			mockRunImage := mocks.NewMockImage(mockController)
+			mockRunImage.EXPECT().Found().Return(true, nil).AnyTimes()
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(mockRunImage, nil).AnyTimes()
+
+			builderID, runImageID = "sha256:builder-id", "sha256:run-id"
+			mockDocker.EXPECT().ImageInspectWithRaw(gomock.Any(), "some/builder").DoAndReturn(func(context.Context, string) (types.ImageInspect, []byte, error) {
+				return types.ImageInspect{ID: builderID}, nil, nil
+			}).AnyTimes()
+			mockDocker.EXPECT().ImageInspectWithRaw(gomock.Any(), "some/run").DoAndReturn(func(context.Context, string) (types.ImageInspect, []byte, error) {
+				return types.ImageInspect{ID: runImageID}, nil, nil
+			}).AnyTimes()
+
+			factory = &pack.BuildFactory{
+				Cli:     mockDocker,
+				Fetcher: mockFetcher,
+				Config:  &config.Config{TrustedBuilders: []string{"some/builder"}},
+				Logger:  logger,
+				Cache:   mockCache,
+			}
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.RemoveAll(appDir))
+		})
+
+		digestWithBuildpacks := func(buildpacks []string, env ...string) string {
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				AppDir:        appDir,
+				RepoName:      "some/app",
+				Builder:       "some/builder",
+				Buildpacks:    buildpacks,
+				Env:           env,
+				SkipUnchanged: true,
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.Labels[pack.SourceDigestLabel], config.SourceDigest)
+			return config.SourceDigest
+		}
+
+		digest := func(env ...string) string {
+			return digestWithBuildpacks(nil, env...)
+		}
+
+		it("labels the image with the digest of the source and inputs of the build", func() {
+			before := digest()
+			h.AssertContains(t, before, "sha256:")
+			h.AssertEq(t, digest(), before)
+			h.AssertNotEq(t, digest("SOME_VAR=some-value"), before)
+
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(appDir, "app.js"), []byte("console.log('hello')"), 0644))
+			h.AssertNotEq(t, digest(), before)
+		})
+
+		it("changes with the builder and run images", func() {
+			before := digest()
+			builderID = "sha256:other-builder-id"
+			withBuilder := digest()
+			h.AssertNotEq(t, withBuilder, before)
+
+			runImageID = "sha256:other-run-id"
+			h.AssertNotEq(t, digest(), withBuilder)
+		})
+
+		it("changes with the contents of buildpack directories rather than their paths", func() {
+			var bpDirs []string
+			for i := 0; i < 2; i++ {
+				bpDir, err := ioutil.TempDir("", "pack.source-digest.buildpack")
+				h.AssertNil(t, err)
+				defer os.RemoveAll(bpDir)
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(bpDir, "buildpack.toml"), []byte("[buildpack]\nid = \"some.bp\""), 0644))
+				bpDirs = append(bpDirs, bpDir)
+			}
+
+			before := digestWithBuildpacks(bpDirs[:1])
+			h.AssertEq(t, digestWithBuildpacks(bpDirs[1:]), before)
+
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(bpDirs[0], "buildpack.toml"), []byte("[buildpack]\nid = \"other.bp\""), 0644))
+			h.AssertNotEq(t, digestWithBuildpacks(bpDirs[:1]), before)
+		})
+
+		it("changes with the volumes of the build", func() {
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				AppDir:        appDir,
+				RepoName:      "some/app",
+				Builder:       "some/builder",
+				Volumes:       []string{"/some/host/dir:/some/dir"},
+				SkipUnchanged: true,
+			})
+			h.AssertNil(t, err)
+			h.AssertNotEq(t, config.SourceDigest, digest())
+		})
+
+		it("leaves the digest unset by default", func() {
+			config, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				AppDir:   appDir,
+				RepoName: "some/app",
+				Builder:  "some/builder",
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.SourceDigest, "")
+		})
+
+		it("cannot skip apps read from stdin", func() {
+			_, err := factory.BuildConfigFromFlags(context.TODO(), &pack.BuildFlags{
+				AppDir:        pack.AppDirStdin,
+				RepoName:      "some/app",
+				SkipUnchanged: true,
+			})
+			h.AssertError(t, err, "unchanged builds cannot be skipped for apps read from stdin")
+		})
+	})
+
+	when("#Build", func() {
+		it("tags the latest image built from the same source instead of building", func() {
+			mockDocker.EXPECT().ImageList(gomock.Any(), types.ImageListOptions{
+				Filters: filters.NewArgs(filters.Arg("label", pack.SourceDigestLabel+"=sha256:some-digest")),
+			}).Return([]types.ImageSummary{
+				{ID: "sha256:older-id", Created: 1},
+				{ID: "sha256:latest-id", Created: 2},
+			}, nil)
+			mockDocker.EXPECT().ImageTag(gomock.Any(), "sha256:latest-id", "some/app").Return(nil)
+			mockDocker.EXPECT().ImageTag(gomock.Any(), "some/app", "some/app:v1").Return(nil)
+			mockDocker.EXPECT().ImageInspectWithRaw(gomock.Any(), "some/app").Return(types.ImageInspect{
+				ID:       "sha256:latest-id",
+				RepoTags: []string{"some/app:latest", "some/app:v1"},
+			}, nil, nil)
+
+			b := &pack.BuildConfig{
+				RepoName:       "some/app",
+				AdditionalTags: []string{"some/app:v1"},
+				SourceDigest:   "sha256:some-digest",
+				Cli:            mockDocker,
+				Logger:         logger,
+			}
+			result, err := b.Build(context.TODO())
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.ImageID, "sha256:latest-id")
+			h.AssertEq(t, result.Phases, []pack.PhaseTiming(nil))
+			h.AssertContains(t, outBuf.String(), "Skipping build, image 'sha256:latest-id' was built from the same source")
+		})
+	})
+}
@@ -2,10 +2,28 @@ package style
 
 import (
 	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
 
+	"github.com/docker/docker/pkg/term"
 	"github.com/fatih/color"
 )
 
+// ColorEnabled reports whether output written to w should be colored when color was not
+// explicitly requested or disabled. It follows the NO_COLOR and CLICOLOR/CLICOLOR_FORCE
+// conventions, and otherwise only colors output to an interactive terminal.
+func ColorEnabled(w io.Writer) bool {
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("CLICOLOR") == "0" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	_, isTerm := term.GetFdInfo(w)
+	return isTerm
+}
+
 var Noop = func(format string, a ...interface{}) string {
 	return color.WhiteString("") + fmt.Sprintf(format, a...)
 }
@@ -23,11 +41,43 @@ var Tip = color.New(color.FgGreen, color.Bold).SprintfFunc()
 
 var Error = color.New(color.FgRed, color.Bold).SprintfFunc()
 
+var Warn = color.New(color.FgYellow, color.Bold).SprintfFunc()
+
 var Step = func(format string, a ...interface{}) string {
 	return color.CyanString("===> "+format, a...)
 }
 
-var Prefix = color.CyanString
+var phasePrefixColors = map[string]color.Attribute{
+	"detector": color.FgCyan,
+	"restorer": color.FgBlue,
+	"analyzer": color.FgMagenta,
+	"builder":  color.FgYellow,
+	"exporter": color.FgGreen,
+	"cacher":   color.FgHiBlue,
+	"creator":  color.FgHiGreen,
+}
+
+var prefixColors = []color.Attribute{
+	color.FgCyan,
+	color.FgMagenta,
+	color.FgYellow,
+	color.FgGreen,
+	color.FgBlue,
+	color.FgHiCyan,
+	color.FgHiMagenta,
+	color.FgHiYellow,
+}
+
+// Prefix colors a log prefix, such as a lifecycle phase name, with a color that is stable for that name
+var Prefix = func(name string) string {
+	attr, ok := phasePrefixColors[name]
+	if !ok {
+		h := fnv.New32a()
+		h.Write([]byte(name))
+		attr = prefixColors[h.Sum32()%uint32(len(prefixColors))]
+	}
+	return color.New(attr).Sprint(name)
+}
 
 var TimestampColorCode = color.FgHiBlack
 
@@ -0,0 +1,82 @@
+package style_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/style"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestStyle(t *testing.T) {
+	spec.Run(t, "style", testStyle, spec.Report(report.Terminal{}))
+}
+
+func testStyle(t *testing.T, when spec.G, it spec.S) {
+	when("#ColorEnabled", func() {
+		var env = map[string]string{}
+
+		setenv := func(key, value string) {
+			if _, ok := env[key]; !ok {
+				env[key] = os.Getenv(key)
+			}
+			os.Setenv(key, value)
+		}
+
+		it.Before(func() {
+			for _, key := range []string{"CLICOLOR_FORCE", "NO_COLOR", "CLICOLOR"} {
+				setenv(key, "")
+			}
+		})
+
+		it.After(func() {
+			for key, value := range env {
+				os.Setenv(key, value)
+			}
+		})
+
+		it("disables color for output that is not a terminal", func() {
+			h.AssertEq(t, style.ColorEnabled(&bytes.Buffer{}), false)
+		})
+
+		it("enables color when CLICOLOR_FORCE is set", func() {
+			setenv("CLICOLOR_FORCE", "1")
+			h.AssertEq(t, style.ColorEnabled(&bytes.Buffer{}), true)
+		})
+
+		it("disables color when NO_COLOR is set", func() {
+			setenv("NO_COLOR", "1")
+			setenv("CLICOLOR_FORCE", "0")
+			h.AssertEq(t, style.ColorEnabled(&bytes.Buffer{}), false)
+		})
+	})
+
+	when("#Prefix", func() {
+		var noColor bool
+
+		it.Before(func() {
+			noColor = color.NoColor
+			color.NoColor = false
+		})
+
+		it.After(func() {
+			color.NoColor = noColor
+		})
+
+		it("colors the same phase consistently and different phases differently", func() {
+			h.AssertContains(t, style.Prefix("builder"), "\x1b[")
+			h.AssertEq(t, style.Prefix("builder"), style.Prefix("builder"))
+			h.AssertNotEq(t, style.Prefix("builder"), style.Prefix("detector"))
+		})
+
+		it("colors other prefixes consistently", func() {
+			h.AssertContains(t, style.Prefix("some.buildpack"), "\x1b[")
+			h.AssertEq(t, style.Prefix("some.buildpack"), style.Prefix("some.buildpack"))
+		})
+	})
+}
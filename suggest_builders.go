@@ -0,0 +1,83 @@
+package pack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/style"
+)
+
+// SuggestedBuilder is a builder recommended to users who have not selected a default builder
+type SuggestedBuilder struct {
+	Vendor      string
+	Image       string
+	Description string
+}
+
+// SuggestedBuilders are the curated builders suggested to users, with the descriptions used when the
+// metadata of a builder has none
+var SuggestedBuilders = []SuggestedBuilder{
+	{"Cloud Foundry", "cloudfoundry/cnb:bionic", "small base image with Java & Node.js"},
+	{"Cloud Foundry", "cloudfoundry/cnb:cflinuxfs3", "larger base image with Java, Node.js & Python"},
+	{"Heroku", "heroku/buildpacks", "heroku-18 base image with official Heroku buildpacks"},
+}
+
+// SuggestBuilders returns the SuggestedBuilders with the descriptions in the metadata of their images
+// at the registry, keeping the curated description of builders that cannot be fetched
+func SuggestBuilders(fetcher Fetcher) []SuggestedBuilder {
+	suggested := make([]SuggestedBuilder, len(SuggestedBuilders))
+	copy(suggested, SuggestedBuilders)
+
+	var wg sync.WaitGroup
+	for i := range suggested {
+		wg.Add(1)
+		go func(b *SuggestedBuilder) {
+			defer wg.Done()
+			if description := builderDescription(fetcher, b.Image); description != "" {
+				b.Description = description
+			}
+		}(&suggested[i])
+	}
+	wg.Wait()
+	return suggested
+}
+
+// SuggestBuilders returns the suggested builders with the descriptions in their metadata, see
+// SuggestBuilders
+func (c *Client) SuggestBuilders() []SuggestedBuilder {
+	return SuggestBuilders(c.fetcher)
+}
+
+// builderDescription returns the description in the metadata of a builder at the registry, or
+// nothing when it has none or cannot be fetched
+func builderDescription(fetcher Fetcher, name string) string {
+	img, err := fetcher.FetchRemoteImage(name)
+	if err != nil {
+		return ""
+	}
+	label, err := img.Label(builder.MetadataLabel)
+	if err != nil || label == "" {
+		return ""
+	}
+	var metadata builder.Metadata
+	if err := json.Unmarshal([]byte(label), &metadata); err != nil {
+		return ""
+	}
+	return metadata.Description
+}
+
+// errNoBuilder tells users without a default builder how to select one
+func errNoBuilder() error {
+	var suggestions []string
+	for _, b := range SuggestedBuilders {
+		suggestions = append(suggestions, fmt.Sprintf("\t%s (%s: %s)", b.Image, b.Vendor, b.Description))
+	}
+	return fmt.Errorf(
+		"no builder selected, use --builder or set a default builder with %s, such as one of:\n%s",
+		style.Symbol("pack set-default-builder <builder image>"),
+		strings.Join(suggestions, "\n"),
+	)
+}
@@ -0,0 +1,63 @@
+package pack_test
+
+import (
+	"errors"
+	"testing"
+
+	imgtest "github.com/buildpack/lifecycle/testhelpers"
+	"github.com/fatih/color"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestSuggestBuilders(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "SuggestBuilders", testSuggestBuilders, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testSuggestBuilders(t *testing.T, when spec.G, it spec.S) {
+	var (
+		client         *pack.Client
+		mockFetcher    *mocks.MockFetcher
+		mockController *gomock.Controller
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockFetcher = mocks.NewMockFetcher(mockController)
+		var err error
+		client, err = pack.NewClient(pack.WithConfig(&config.Config{}), pack.WithFetcher(mockFetcher))
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("uses the descriptions in the metadata of the builders, or the curated ones", func() {
+		described := imgtest.NewFakeImage(t, pack.SuggestedBuilders[0].Image, "", "")
+		h.AssertNil(t, described.SetLabel("io.buildpacks.builder.metadata", `{"description": "some description"}`))
+		undescribed := imgtest.NewFakeImage(t, pack.SuggestedBuilders[1].Image, "", "")
+		h.AssertNil(t, undescribed.SetLabel("io.buildpacks.builder.metadata", `{}`))
+
+		mockFetcher.EXPECT().FetchRemoteImage(pack.SuggestedBuilders[0].Image).Return(described, nil)
+		mockFetcher.EXPECT().FetchRemoteImage(pack.SuggestedBuilders[1].Image).Return(undescribed, nil)
+		for _, b := range pack.SuggestedBuilders[2:] {
+			mockFetcher.EXPECT().FetchRemoteImage(b.Image).Return(nil, errors.New("some error"))
+		}
+
+		suggested := client.SuggestBuilders()
+		h.AssertEq(t, len(suggested), len(pack.SuggestedBuilders))
+		h.AssertEq(t, suggested[0].Image, pack.SuggestedBuilders[0].Image)
+		h.AssertEq(t, suggested[0].Description, "some description")
+		for i := 1; i < len(suggested); i++ {
+			h.AssertEq(t, suggested[i], pack.SuggestedBuilders[i])
+		}
+	})
+}
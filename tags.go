@@ -0,0 +1,61 @@
+package pack
+
+import (
+	"context"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/keychain"
+	"github.com/buildpack/pack/style"
+)
+
+// checkTags validates the additional tags of the build
+func checkTags(f *BuildFlags) error {
+	for _, tag := range f.AdditionalTags {
+		if _, err := name.NewTag(tag, name.WeakValidation); err != nil {
+			return errors.Wrapf(err, "invalid tag %s", style.Symbol(tag))
+		}
+	}
+	return nil
+}
+
+// tag applies the additional tags to the exported app image, in the daemon or, when published, by
+// pushing the image under each of them
+func (b *BuildConfig) tag(ctx context.Context) error {
+	if !b.Publish {
+		for _, tag := range b.AdditionalTags {
+			b.Logger.Verbose("Tagging image %s as %s", style.Symbol(b.RepoName), style.Symbol(tag))
+			if err := b.Cli.ImageTag(ctx, b.RepoName, tag); err != nil {
+				return errors.Wrapf(err, "tagging image %s as %s", style.Symbol(b.RepoName), style.Symbol(tag))
+			}
+		}
+		return nil
+	}
+
+	ref, err := name.ParseReference(b.RepoName, name.WeakValidation)
+	if err != nil {
+		return errors.Wrapf(err, "invalid image name %s", style.Symbol(b.RepoName))
+	}
+	transport := registryTransport(b.Config)
+	img, err := remoteImage(ref, transport)
+	if err != nil {
+		return errors.Wrapf(err, "fetching image %s", style.Symbol(b.RepoName))
+	}
+	for _, tag := range b.AdditionalTags {
+		b.Logger.Verbose("Pushing image %s as %s", style.Symbol(b.RepoName), style.Symbol(tag))
+		t, err := name.NewTag(tag, name.WeakValidation)
+		if err != nil {
+			return errors.Wrapf(err, "invalid tag %s", style.Symbol(tag))
+		}
+		auth, err := keychain.Default.Resolve(t.Context().Registry)
+		if err != nil {
+			return err
+		}
+		if err := remote.Write(t, img, auth, transport); err != nil {
+			return errors.Wrapf(err, "pushing image %s", style.Symbol(tag))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package testhelpers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// FakeRegistry is an in-memory image registry served over HTTP on localhost, which pack and the
+// registry client reach without TLS, for tests pushing and pulling images without a registry
+// container
+type FakeRegistry struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	manifests map[string]fakeManifest
+	blobs     map[string][]byte
+	uploads   map[string][]byte
+	requests  []string
+}
+
+type fakeManifest struct {
+	contentType string
+	body        []byte
+}
+
+func NewFakeRegistry() *FakeRegistry {
+	r := &FakeRegistry{
+		manifests: map[string]fakeManifest{},
+		blobs:     map[string][]byte{},
+		uploads:   map[string][]byte{},
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+// Host is the <host>:<port> of the registry, which prefixes the names of its images
+func (r *FakeRegistry) Host() string {
+	return strings.TrimPrefix(r.URL, "http://")
+}
+
+// Manifest returns the manifest of an image or index of the repository, by tag or digest
+func (r *FakeRegistry) Manifest(repo, ref string) ([]byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.manifests[repo+"@"+ref]
+	return m.body, ok
+}
+
+// Requests returns the method and path of each request the registry received
+func (r *FakeRegistry) Requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.requests...)
+}
+
+func (r *FakeRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req.Method+" "+req.URL.Path)
+
+	if req.URL.Path == "/v2/" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case strings.Contains(path, "/blobs/uploads/"):
+		i := strings.LastIndex(path, "/blobs/uploads/")
+		r.serveUpload(w, req, path[:i], path[i+len("/blobs/uploads/"):])
+	case strings.Contains(path, "/blobs/"):
+		i := strings.LastIndex(path, "/blobs/")
+		r.serveBlob(w, req, path[i+len("/blobs/"):])
+	case strings.Contains(path, "/manifests/"):
+		i := strings.LastIndex(path, "/manifests/")
+		r.serveManifest(w, req, path[:i], path[i+len("/manifests/"):])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *FakeRegistry) serveUpload(w http.ResponseWriter, req *http.Request, repo, id string) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	switch req.Method {
+	case http.MethodPost:
+		id = fmt.Sprintf("upload-%d", len(r.uploads))
+		r.uploads[id] = body
+	case http.MethodPatch:
+		r.uploads[id] = append(r.uploads[id], body...)
+	case http.MethodPut:
+		r.blobs[req.URL.Query().Get("digest")] = append(r.uploads[id], body...)
+		delete(r.uploads, id)
+		w.WriteHeader(http.StatusCreated)
+		return
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%s", repo, id))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (r *FakeRegistry) serveBlob(w http.ResponseWriter, req *http.Request, digest string) {
+	blob, ok := r.blobs[digest]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		if req.Method != http.MethodHead {
+			w.Write([]byte(`{"errors":[{"code":"BLOB_UNKNOWN","message":"blob unknown"}]}`))
+		}
+		return
+	}
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(blob)))
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.WriteHeader(http.StatusOK)
+	if req.Method != http.MethodHead {
+		w.Write(blob)
+	}
+}
+
+func (r *FakeRegistry) serveManifest(w http.ResponseWriter, req *http.Request, repo, ref string) {
+	switch req.Method {
+	case http.MethodPut:
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		digest := fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+		m := fakeManifest{contentType: req.Header.Get("Content-Type"), body: body}
+		r.manifests[repo+"@"+ref] = m
+		r.manifests[repo+"@"+digest] = m
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet, http.MethodHead:
+		m, ok := r.manifests[repo+"@"+ref]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if req.Method == http.MethodGet {
+				w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+			}
+			return
+		}
+		w.Header().Set("Content-Type", m.contentType)
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(m.body)))
+		w.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256(m.body)))
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			w.Write(m.body)
+		}
+	case http.MethodDelete:
+		delete(r.manifests, repo+"@"+ref)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -206,6 +206,7 @@ func ConfigurePackHome(t *testing.T, packHome, registryPort string) {
 	AssertNil(t, ioutil.WriteFile(filepath.Join(packHome, "config.toml"), []byte(fmt.Sprintf(`
 				default-stack-id = "io.buildpacks.stacks.bionic"
                 default-builder = "%s"
+                trusted-builders = ["%s"]
 
 				[[stacks]]
 				  id = "io.buildpacks.stacks.bionic"
@@ -215,7 +216,7 @@ func ConfigurePackHome(t *testing.T, packHome, registryPort string) {
                 [[run-images]]
                   image = "packs/run:%s"
                   mirrors = ["%s"]
-			`, DefaultBuilderImage(t, registryPort), DefaultBuildImage(t, registryPort), DefaultRunImage(t, registryPort), tag, DefaultRunImage(t, registryPort))), 0666))
+			`, DefaultBuilderImage(t, registryPort), DefaultBuilderImage(t, registryPort), DefaultBuildImage(t, registryPort), DefaultRunImage(t, registryPort), tag, DefaultRunImage(t, registryPort))), 0666))
 }
 
 func CreateImageOnLocal(t *testing.T, dockerCli *docker.Client, repoName, dockerFile string) {
@@ -0,0 +1,253 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+)
+
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+	statusCodeError  = 2
+)
+
+// Tracer turns build events into OpenTelemetry spans and exports them with OTLP over HTTP
+// once the build completes. The root span covers the whole build, with a child span for
+// each image pull and lifecycle phase. Pushes to a registry are part of the exporter span.
+type Tracer struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+	logger   *logging.Logger
+	traceID  string
+	root     *span
+	phases   map[string]*span
+	spans    []*span
+}
+
+func NewTracer(endpoint string, headers map[string]string, logger *logging.Logger) *Tracer {
+	t := &Tracer{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   logger,
+		traceID:  randomID(16),
+		phases:   map[string]*span{},
+	}
+	t.root = t.startSpan("build", "", spanKindInternal, time.Now())
+	return t
+}
+
+// NewTracerFromEnv returns a tracer configured by the standard OTEL_EXPORTER_OTLP_* environment
+// variables, or nil when no OTLP endpoint is configured
+func NewTracerFromEnv(logger *logging.Logger) *Tracer {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			return nil
+		}
+		endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+	}
+	headers := parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	for k, v := range parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS")) {
+		headers[k] = v
+	}
+	return NewTracer(endpoint, headers, logger)
+}
+
+// HandleEvent records e as part of the trace. It can be used as a pack.EventHandler.
+func (t *Tracer) HandleEvent(e pack.Event) {
+	switch e.Type {
+	case pack.ImagePulled:
+		s := t.startSpan("pull", t.root.SpanID, spanKindClient, e.Time.Add(-e.Duration))
+		s.setAttribute("image.name", e.Image)
+		s.end(e.Time, nil)
+	case pack.PhaseStarted:
+		s := t.startSpan(e.Phase, t.root.SpanID, spanKindInternal, e.Time)
+		s.setAttribute("lifecycle.phase", e.Phase)
+		t.phases[e.Phase] = s
+	case pack.PhaseFinished:
+		if s, ok := t.phases[e.Phase]; ok {
+			s.end(e.Time, e.Err)
+			delete(t.phases, e.Phase)
+		}
+	case pack.LayerExported:
+		t.root.addCount("layers.exported")
+		if e.Reused {
+			t.root.addCount("layers.reused")
+		}
+	case pack.BuildCompleted:
+		t.root.setAttribute("image.name", e.Image)
+		if e.Digest != "" {
+			t.root.setAttribute("image.digest", e.Digest)
+		}
+		t.root.end(e.Time, e.Err)
+		if err := t.export(); err != nil {
+			t.logger.Warn("Failed to export build trace: %s", err)
+		}
+	}
+}
+
+func (t *Tracer) startSpan(name, parentID string, kind int, start time.Time) *span {
+	s := &span{
+		TraceID:           t.traceID,
+		SpanID:            randomID(8),
+		ParentSpanID:      parentID,
+		Name:              name,
+		Kind:              kind,
+		StartTimeUnixNano: unixNano(start),
+		Attributes:        []attribute{},
+	}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+func (t *Tracer) export() error {
+	body, err := json.Marshal(exportRequest{ResourceSpans: []resourceSpans{{
+		Resource: resource{Attributes: []attribute{stringAttribute("service.name", "pack")}},
+		ScopeSpans: []scopeSpans{{
+			Scope: scope{Name: "github.com/buildpack/pack"},
+			Spans: t.spans,
+		}},
+	}}})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "posting spans to %s", t.endpoint)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("posting spans to %s: unexpected status %s", t.endpoint, resp.Status)
+	}
+	t.logger.Debug("Exported %d spans to %s", len(t.spans), t.endpoint)
+	return nil
+}
+
+// parseHeaders parses the OTEL_EXPORTER_OTLP_HEADERS format of comma separated key=value pairs
+func parseHeaders(s string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return headers
+}
+
+func randomID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// The types below follow the JSON encoding of the OTLP trace protocol
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []attribute `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope   `json:"scope"`
+	Spans []*span `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type span struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano,omitempty"`
+	Attributes        []attribute `json:"attributes"`
+	Status            *status     `json:"status,omitempty"`
+}
+
+func (s *span) end(t time.Time, err error) {
+	s.EndTimeUnixNano = unixNano(t)
+	if err != nil {
+		s.Status = &status{Code: statusCodeError, Message: err.Error()}
+	}
+}
+
+func (s *span) setAttribute(key, value string) {
+	s.Attributes = append(s.Attributes, stringAttribute(key, value))
+}
+
+func (s *span) addCount(key string) {
+	for i, a := range s.Attributes {
+		if a.Key == key {
+			n, _ := strconv.Atoi(a.Value.IntValue)
+			s.Attributes[i].Value.IntValue = strconv.Itoa(n + 1)
+			return
+		}
+	}
+	s.Attributes = append(s.Attributes, attribute{Key: key, Value: value{IntValue: "1"}})
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type attribute struct {
+	Key   string `json:"key"`
+	Value value  `json:"value"`
+}
+
+type value struct {
+	StringValue string `json:"stringValue,omitempty"`
+	IntValue    string `json:"intValue,omitempty"`
+}
+
+func stringAttribute(key, v string) attribute {
+	return attribute{Key: key, Value: value{StringValue: v}}
+}
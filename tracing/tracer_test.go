@@ -0,0 +1,101 @@
+package tracing_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+	"github.com/buildpack/pack/tracing"
+)
+
+func TestTracer(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "tracer", testTracer, spec.Report(report.Terminal{}))
+}
+
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Status       *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+func testTracer(t *testing.T, when spec.G, it spec.S) {
+	var (
+		server   *httptest.Server
+		requests chan *http.Request
+		bodies   chan []byte
+		outBuf   bytes.Buffer
+		logger   *logging.Logger
+	)
+
+	it.Before(func() {
+		requests = make(chan *http.Request, 1)
+		bodies = make(chan []byte, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests <- r
+			bodies <- body
+		}))
+		logger = logging.NewLogger(&outBuf, &outBuf, true, false)
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	when("#HandleEvent", func() {
+		it("exports a span per pull and phase under a build span once the build completes", func() {
+			tracer := tracing.NewTracer(server.URL+"/v1/traces", map[string]string{"X-Token": "secret"}, logger)
+			now := time.Now()
+			tracer.HandleEvent(pack.Event{Type: pack.ImagePulled, Time: now, Image: "some/builder", Duration: time.Second})
+			tracer.HandleEvent(pack.Event{Type: pack.PhaseStarted, Time: now, Phase: "detector"})
+			tracer.HandleEvent(pack.Event{Type: pack.PhaseFinished, Time: now, Phase: "detector", Err: errors.New("no buildpacks")})
+			tracer.HandleEvent(pack.Event{Type: pack.BuildCompleted, Time: now, Image: "some/app"})
+
+			req := <-requests
+			h.AssertEq(t, req.URL.Path, "/v1/traces")
+			h.AssertEq(t, req.Header.Get("Content-Type"), "application/json")
+			h.AssertEq(t, req.Header.Get("X-Token"), "secret")
+
+			var payload struct {
+				ResourceSpans []struct {
+					ScopeSpans []struct {
+						Spans []exportedSpan `json:"spans"`
+					} `json:"scopeSpans"`
+				} `json:"resourceSpans"`
+			}
+			h.AssertNil(t, json.Unmarshal(<-bodies, &payload))
+			spans := payload.ResourceSpans[0].ScopeSpans[0].Spans
+			h.AssertEq(t, len(spans), 3)
+
+			root := spans[0]
+			h.AssertEq(t, root.Name, "build")
+			h.AssertEq(t, root.ParentSpanID, "")
+			h.AssertEq(t, spans[1].Name, "pull")
+			h.AssertEq(t, spans[2].Name, "detector")
+			for _, s := range spans[1:] {
+				h.AssertEq(t, s.TraceID, root.TraceID)
+				h.AssertEq(t, s.ParentSpanID, root.SpanID)
+			}
+			h.AssertEq(t, spans[2].Status.Code, 2)
+			h.AssertEq(t, spans[2].Status.Message, "no buildpacks")
+		})
+	})
+}
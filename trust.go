@@ -0,0 +1,35 @@
+package pack
+
+import (
+	"github.com/buildpack/pack/style"
+)
+
+// TrustPrompt asks whether to trust a builder that is not trusted, such as by prompting the user on a
+// terminal. Builders it trusts are added to the trusted builders of the config, see
+// config.Config.TrustBuilder.
+type TrustPrompt func(builder string) (bool, error)
+
+// WithTrustPrompt asks prompt whether to trust builders that are not trusted, rather than running
+// the phases of their builds with access to the docker daemon or registries in the lifecycle image
+func WithTrustPrompt(prompt TrustPrompt) func(*BuildFactory) {
+	return func(bf *BuildFactory) {
+		bf.TrustPrompt = prompt
+	}
+}
+
+// confirmTrust reports whether the builder that is not trusted is trusted by the TrustPrompt, saving
+// the builders it trusts in the config so that it is not asked about them again
+func (bf *BuildFactory) confirmTrust(builder string) (bool, error) {
+	if bf.TrustPrompt == nil {
+		bf.Logger.Info("Builder %s is not trusted, so the phases with access to the docker daemon or registries run in the lifecycle image. Trust it with 'pack trust-builder %s'.", style.Symbol(builder), builder)
+		return false, nil
+	}
+	trusted, err := bf.TrustPrompt(builder)
+	if err != nil || !trusted {
+		return false, err
+	}
+	if err := bf.Config.TrustBuilder(builder); err != nil {
+		bf.Logger.Warn("Trusting builder %s for this build only, as it could not be saved: %s", style.Symbol(builder), err)
+	}
+	return true, nil
+}
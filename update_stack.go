@@ -0,0 +1,149 @@
+package pack
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/buildpack/lifecycle"
+	"github.com/buildpack/lifecycle/image"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+type UpdateStackFlags struct {
+	// NoPull compares app images with the run images in the daemon, rather than pulling them first
+	NoPull bool
+	// Rebase rebases the stale app images onto the latest run images
+	Rebase bool
+}
+
+// StaleImage is an app image in the docker daemon based on an older run image than the latest one
+type StaleImage struct {
+	Image string
+	// RunImage is the run image the app image is compared with, which is a mirror of the run image
+	// of its stack in the registry of the app image, when there is one
+	RunImage string
+	// SHA and LatestSHA are the digests of the run image the app image is based on, and of the
+	// latest run image. They are empty when the run image was never pushed to a registry, in which
+	// case its top layer tells whether it changed.
+	SHA       string
+	LatestSHA string
+	// Rebased is true when the app image was rebased onto the latest run image
+	Rebased bool
+
+	config RebaseConfig
+}
+
+// UpdateStack pulls the latest run images of the app images built by pack in the docker daemon,
+// returning the app images based on older run images, which are rebased onto the latest ones when
+// flags.Rebase is set. Each tag of an app image is checked.
+func (c *Client) UpdateStack(ctx context.Context, flags UpdateStackFlags) ([]StaleImage, error) {
+	summaries, err := c.docker.ImageList(ctx, types.ImageListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", lifecycle.MetadataLabel)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing app images")
+	}
+	var repoNames []string
+	for _, summary := range summaries {
+		for _, tag := range summary.RepoTags {
+			if tag != "<none>:<none>" {
+				repoNames = append(repoNames, tag)
+			}
+		}
+	}
+	sort.Strings(repoNames)
+
+	f := &RebaseFactory{
+		Logger:  c.logger,
+		Config:  c.config,
+		Fetcher: c.fetcher,
+	}
+	stale, err := f.StaleImages(ctx, repoNames, flags.NoPull)
+	if err != nil || !flags.Rebase {
+		return stale, err
+	}
+	for i := range stale {
+		if err := f.Rebase(stale[i].config); err != nil {
+			return stale, errors.Wrapf(err, "rebasing image %s", style.Symbol(stale[i].Image))
+		}
+		stale[i].Rebased = true
+	}
+	return stale, nil
+}
+
+// StaleImages returns the app images in the docker daemon, among repoNames, that are based on an
+// older run image than the latest one, which is pulled once for all of them unless noPull is set
+func (f *RebaseFactory) StaleImages(ctx context.Context, repoNames []string, noPull bool) ([]StaleImage, error) {
+	runImages := map[string]image.Image{}
+	var stale []StaleImage
+	for _, repoName := range repoNames {
+		appImage, err := f.Fetcher.FetchLocalImage(repoName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetching image %s", style.Symbol(repoName))
+		}
+		label, err := appImage.Label(lifecycle.MetadataLabel)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading metadata of image %s", style.Symbol(repoName))
+		}
+		if label == "" {
+			continue
+		}
+		var metadata lifecycle.AppImageMetadata
+		if err := json.Unmarshal([]byte(label), &metadata); err != nil {
+			return nil, errors.Wrapf(err, "parsing metadata of image %s", style.Symbol(repoName))
+		}
+		if metadata.Stack.RunImage.Image == "" {
+			f.Logger.Verbose("Skipping image %s, which does not record the run image of its stack", style.Symbol(repoName))
+			continue
+		}
+
+		runImageName, err := f.runImageName(repoName, metadata)
+		if err != nil {
+			return nil, err
+		}
+		runImage, ok := runImages[runImageName]
+		if !ok {
+			if noPull {
+				runImage, err = f.Fetcher.FetchLocalImage(runImageName)
+			} else {
+				runImage, err = f.Fetcher.FetchUpdatedLocalImage(ctx, runImageName, f.Logger.RawVerboseWriter())
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "fetching run image %s", style.Symbol(runImageName))
+			}
+			runImages[runImageName] = runImage
+		}
+
+		latestSHA, err := runImage.Digest()
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading digest of run image %s", style.Symbol(runImageName))
+		}
+		if latestSHA != "" && metadata.RunImage.SHA != "" {
+			if latestSHA == metadata.RunImage.SHA {
+				continue
+			}
+		} else {
+			topLayer, err := runImage.TopLayer()
+			if err != nil {
+				return nil, errors.Wrapf(err, "reading top layer of run image %s", style.Symbol(runImageName))
+			}
+			if topLayer == metadata.RunImage.TopLayer {
+				continue
+			}
+		}
+
+		stale = append(stale, StaleImage{
+			Image:     repoName,
+			RunImage:  runImageName,
+			SHA:       metadata.RunImage.SHA,
+			LatestSHA: latestSHA,
+			config:    RebaseConfig{Image: appImage, NewBaseImage: runImage},
+		})
+	}
+	return stale, nil
+}
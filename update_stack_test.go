@@ -0,0 +1,101 @@
+package pack_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	imgtest "github.com/buildpack/lifecycle/testhelpers"
+	"github.com/fatih/color"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestUpdateStack(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "UpdateStack", testUpdateStack, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testUpdateStack(t *testing.T, when spec.G, it spec.S) {
+	when("#StaleImages", func() {
+		var (
+			mockController *gomock.Controller
+			mockFetcher    *mocks.MockFetcher
+			factory        *pack.RebaseFactory
+			runImage       *imgtest.FakeImage
+		)
+
+		givenAppImage := func(name, metadata string) {
+			appImage := imgtest.NewFakeImage(t, name, "", "")
+			h.AssertNil(t, appImage.SetLabel("io.buildpacks.lifecycle.metadata", metadata))
+			mockFetcher.EXPECT().FetchLocalImage(name).Return(appImage, nil)
+		}
+
+		it.Before(func() {
+			mockController = gomock.NewController(t)
+			mockFetcher = mocks.NewMockFetcher(mockController)
+			factory = &pack.RebaseFactory{
+				Logger:  logging.NewLogger(&bytes.Buffer{}, &bytes.Buffer{}, false, false),
+				Config:  &config.Config{},
+				Fetcher: mockFetcher,
+			}
+			runImage = imgtest.NewFakeImage(t, "some/run", "latest-top-layer", "sha256:latest")
+		})
+
+		it.After(func() {
+			mockController.Finish()
+		})
+
+		it("returns the app images based on older run images, pulling each run image once", func() {
+			givenAppImage("some/app:latest", `{"runImage": {"topLayer": "old-top-layer", "sha": "sha256:old"}, "stack": {"runImage": {"image": "some/run"}}}`)
+			givenAppImage("other/app:latest", `{"runImage": {"topLayer": "latest-top-layer", "sha": "sha256:latest"}, "stack": {"runImage": {"image": "some/run"}}}`)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "some/run", gomock.Any()).Return(runImage, nil)
+
+			stale, err := factory.StaleImages(context.TODO(), []string{"some/app:latest", "other/app:latest"}, false)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(stale), 1)
+			h.AssertEq(t, stale[0].Image, "some/app:latest")
+			h.AssertEq(t, stale[0].RunImage, "some/run")
+			h.AssertEq(t, stale[0].SHA, "sha256:old")
+			h.AssertEq(t, stale[0].LatestSHA, "sha256:latest")
+		})
+
+		it("compares the top layers of run images that were never pushed", func() {
+			runImage = imgtest.NewFakeImage(t, "some/run", "latest-top-layer", "")
+			givenAppImage("some/app:latest", `{"runImage": {"topLayer": "old-top-layer"}, "stack": {"runImage": {"image": "some/run"}}}`)
+			givenAppImage("other/app:latest", `{"runImage": {"topLayer": "latest-top-layer"}, "stack": {"runImage": {"image": "some/run"}}}`)
+			mockFetcher.EXPECT().FetchLocalImage("some/run").Return(runImage, nil)
+
+			stale, err := factory.StaleImages(context.TODO(), []string{"some/app:latest", "other/app:latest"}, true)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(stale), 1)
+			h.AssertEq(t, stale[0].Image, "some/app:latest")
+		})
+
+		it("compares app images with the mirror of their run image in their registry", func() {
+			factory.Config = &config.Config{RunImages: []config.RunImage{{Image: "some/run", Mirrors: []string{"registry.example.com/some/run"}}}}
+			givenAppImage("registry.example.com/some/app", `{"runImage": {"topLayer": "old-top-layer", "sha": "sha256:old"}, "stack": {"runImage": {"image": "some/run"}}}`)
+			mockFetcher.EXPECT().FetchUpdatedLocalImage(gomock.Any(), "registry.example.com/some/run", gomock.Any()).Return(runImage, nil)
+
+			stale, err := factory.StaleImages(context.TODO(), []string{"registry.example.com/some/app"}, false)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(stale), 1)
+			h.AssertEq(t, stale[0].RunImage, "registry.example.com/some/run")
+		})
+
+		it("skips app images that do not record their stack", func() {
+			givenAppImage("some/app:latest", `{"runImage": {"topLayer": "old-top-layer"}}`)
+
+			stale, err := factory.StaleImages(context.TODO(), []string{"some/app:latest"}, false)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(stale), 0)
+		})
+	})
+}
@@ -0,0 +1,293 @@
+package pack
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/buildpack/lifecycle"
+	"github.com/buildpack/lifecycle/image"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/buildpack"
+	"github.com/buildpack/pack/style"
+)
+
+// the checks of ValidateBuilder, which name the findings of each
+const (
+	CheckLabels    = "labels"
+	CheckLifecycle = "lifecycle"
+	CheckUser      = "user"
+	CheckOrder     = "order"
+	CheckStack     = "stack"
+)
+
+// paths of the lifecycle and buildpacks in linux builder images
+const (
+	builderLifecycleDir  = "/lifecycle"
+	builderBuildpacksDir = "/buildpacks"
+	builderOrderPath     = "/buildpacks/order.toml"
+)
+
+// BuilderFinding is a problem with a builder image found by ValidateBuilder
+type BuilderFinding struct {
+	// Check is the check that found the problem, such as CheckLabels
+	Check   string
+	Message string
+	// Fatal findings make builds with the builder fail, while others only make them behave in ways
+	// that may be unexpected
+	Fatal bool
+}
+
+// ValidateBuilder checks the builder image in the docker daemon before it is used for builds: its
+// labels, the user running the phases, the lifecycle binaries, the consistency of its order.toml
+// with its metadata and the buildpacks it contains, and the stack of its run image. It returns
+// what it found wrong, so that builds do not fail midway with obscure errors. The files of the
+// builder are read from a container that is created but never started.
+func (c *Client) ValidateBuilder(ctx context.Context, name string) ([]BuilderFinding, error) {
+	return ValidateBuilder(ctx, c.docker, c.fetcher, name)
+}
+
+// ValidateBuilder checks the builder image named name with the given docker client and fetcher, see
+// Client.ValidateBuilder
+func ValidateBuilder(ctx context.Context, docker Docker, fetcher Fetcher, name string) ([]BuilderFinding, error) {
+	img, err := fetcher.FetchLocalImage(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching builder image %s", style.Symbol(name))
+	}
+	if found, err := img.Found(); err != nil {
+		return nil, errors.Wrapf(err, "fetching builder image %s", style.Symbol(name))
+	} else if !found {
+		return nil, fmt.Errorf("builder image %s not found in the docker daemon -- pull it first", style.Symbol(name))
+	}
+
+	v := &builderValidation{docker: docker, fetcher: fetcher, name: name, image: img}
+	if err := v.run(ctx); err != nil {
+		return nil, err
+	}
+	return v.findings, nil
+}
+
+type builderValidation struct {
+	docker   Docker
+	fetcher  Fetcher
+	name     string
+	image    image.Image
+	ctrID    string
+	findings []BuilderFinding
+}
+
+func (v *builderValidation) fatal(check, format string, a ...interface{}) {
+	v.findings = append(v.findings, BuilderFinding{Check: check, Message: fmt.Sprintf(format, a...), Fatal: true})
+}
+
+func (v *builderValidation) warn(check, format string, a ...interface{}) {
+	v.findings = append(v.findings, BuilderFinding{Check: check, Message: fmt.Sprintf(format, a...)})
+}
+
+func (v *builderValidation) run(ctx context.Context) error {
+	bldr := builder.NewBuilder(v.image, nil)
+	stackID, err := bldr.GetStack()
+	if err != nil {
+		v.fatal(CheckLabels, "%s", err)
+	}
+	metadata, err := bldr.GetMetadata()
+	if err != nil {
+		// the other checks compare the builder with its metadata
+		v.fatal(CheckLabels, "%s", err)
+		return nil
+	}
+
+	var lifecycleVersion string
+	if metadata.Lifecycle == nil {
+		v.warn(CheckLifecycle, "builder does not record the version of its lifecycle, which is assumed to be compatible")
+	} else {
+		lifecycleVersion = metadata.Lifecycle.Version
+		var platformAPI string
+		if metadata.Lifecycle.API != nil {
+			platformAPI = metadata.Lifecycle.API.Platform
+		}
+		if err := build.CheckLifecycleCompatibility(lifecycleVersion, platformAPI); err != nil {
+			v.fatal(CheckLifecycle, "%s", err)
+		}
+	}
+
+	inspect, _, err := v.docker.ImageInspectWithRaw(ctx, v.name)
+	if err != nil {
+		return errors.Wrapf(err, "inspecting builder image %s", style.Symbol(v.name))
+	}
+	if inspect.Os == "windows" {
+		v.warn(CheckLifecycle, "the files of windows builders are not checked")
+	} else {
+		if err := v.checkFiles(ctx, metadata, lifecycleVersion); err != nil {
+			return err
+		}
+	}
+
+	var imageUser string
+	if inspect.Config != nil {
+		imageUser = inspect.Config.User
+	}
+	v.checkUser(imageUser)
+
+	if stackID != "" {
+		v.checkStack(stackID, metadata.Stack.RunImage.Image)
+	}
+	return nil
+}
+
+// checkFiles checks the lifecycle binaries, order.toml and buildpacks in a container of the builder
+func (v *builderValidation) checkFiles(ctx context.Context, metadata *builder.Metadata, lifecycleVersion string) error {
+	ctr, err := v.docker.ContainerCreate(ctx, &container.Config{
+		Image:  v.name,
+		Labels: map[string]string{"author": "pack"},
+	}, &container.HostConfig{}, nil, "")
+	if err != nil {
+		return errors.Wrapf(err, "creating container of builder %s", style.Symbol(v.name))
+	}
+	v.ctrID = ctr.ID
+	defer v.docker.ContainerRemove(context.Background(), ctr.ID, types.ContainerRemoveOptions{Force: true})
+
+	phases := []string{"detector", "restorer", "analyzer", "builder", "exporter", "cacher"}
+	if build.SupportsCreator(lifecycleVersion) {
+		phases = append(phases, "creator")
+	}
+	for _, phase := range phases {
+		if !v.exists(ctx, path.Join(builderLifecycleDir, phase)) {
+			v.fatal(CheckLifecycle, "lifecycle binary %s is missing", style.Symbol(path.Join(builderLifecycleDir, phase)))
+		}
+	}
+
+	v.checkOrder(ctx, metadata)
+	return nil
+}
+
+func (v *builderValidation) exists(ctx context.Context, file string) bool {
+	rc, _, err := v.docker.CopyFromContainer(ctx, v.ctrID, file)
+	if err != nil {
+		return false
+	}
+	rc.Close()
+	return true
+}
+
+// checkOrder checks that the order.toml of the builder has the groups of its metadata, and that it
+// contains the buildpacks of each group
+func (v *builderValidation) checkOrder(ctx context.Context, metadata *builder.Metadata) {
+	rc, _, err := v.docker.CopyFromContainer(ctx, v.ctrID, builderOrderPath)
+	if err != nil {
+		v.fatal(CheckOrder, "%s is missing", style.Symbol(builderOrderPath))
+		return
+	}
+	defer rc.Close()
+	tr := tar.NewReader(rc)
+	if _, err := tr.Next(); err != nil {
+		v.fatal(CheckOrder, "reading %s: %s", style.Symbol(builderOrderPath), err)
+		return
+	}
+	data, err := ioutil.ReadAll(tr)
+	if err != nil {
+		v.fatal(CheckOrder, "reading %s: %s", style.Symbol(builderOrderPath), err)
+		return
+	}
+	groups, err := buildpack.DecodeOrder(builderOrderPath, data)
+	if err != nil {
+		v.fatal(CheckOrder, "parsing %s: %s", style.Symbol(builderOrderPath), err)
+		return
+	}
+
+	if len(groups) != len(metadata.Groups) {
+		v.warn(CheckOrder, "%s has %d groups, but the builder metadata has %d", style.Symbol(builderOrderPath), len(groups), len(metadata.Groups))
+	}
+	for i, group := range groups {
+		if i < len(metadata.Groups) && !sameGroup(group, metadata.Groups[i]) {
+			v.warn(CheckOrder, "group %d of %s differs from the builder metadata", i+1, style.Symbol(builderOrderPath))
+		}
+		for _, bp := range group.Buildpacks {
+			if bp.Version == "" {
+				continue
+			}
+			dir := path.Join(builderBuildpacksDir, (&buildpack.Buildpack{ID: bp.ID}).EscapedID(), bp.Version)
+			if !v.exists(ctx, dir) {
+				v.fatal(CheckOrder, "buildpack %s of group %d is missing from %s", style.Symbol(bp.ID+"@"+bp.Version), i+1, style.Symbol(dir))
+			}
+		}
+	}
+}
+
+func sameGroup(group lifecycle.BuildpackGroup, metadata builder.GroupMetadata) bool {
+	if len(group.Buildpacks) != len(metadata.Buildpacks) {
+		return false
+	}
+	for i, bp := range group.Buildpacks {
+		if bp.ID != metadata.Buildpacks[i].ID || bp.Version != metadata.Buildpacks[i].Version {
+			return false
+		}
+	}
+	return true
+}
+
+// checkUser checks that the user running the phases is set, by CNB_USER_ID and CNB_GROUP_ID or else
+// by the USER of the builder
+func (v *builderValidation) checkUser(imageUser string) {
+	if _, _, err := build.BuilderUser(v.image, imageUser); err != nil {
+		v.fatal(CheckUser, "%s", err)
+		return
+	}
+	uid, err := v.image.Env("CNB_USER_ID")
+	if err != nil {
+		v.fatal(CheckUser, "reading builder env variables: %s", err)
+		return
+	}
+	if uid == "" {
+		v.warn(CheckUser, "builder has no CNB_USER_ID and CNB_GROUP_ID, so builds run as its USER %s", style.Symbol(imageUser))
+	}
+}
+
+// checkStack checks that the run image of the builder, from the daemon or else its registry, has
+// the stack of the builder
+func (v *builderValidation) checkStack(stackID, runImageName string) {
+	if runImageName == "" {
+		v.fatal(CheckStack, "builder has no run image")
+		return
+	}
+	runImage, err := v.fetchRunImage(runImageName)
+	if err != nil {
+		v.fatal(CheckStack, "run image %s cannot be fetched: %s", style.Symbol(runImageName), err)
+		return
+	}
+	runStackID, err := runImage.Label("io.buildpacks.stack.id")
+	if err != nil {
+		v.fatal(CheckStack, "reading stack of run image %s: %s", style.Symbol(runImageName), err)
+		return
+	}
+	if runStackID != stackID {
+		v.fatal(CheckStack, "run image %s has stack %s, but the builder has stack %s", style.Symbol(runImageName), style.Symbol(runStackID), style.Symbol(stackID))
+	}
+}
+
+func (v *builderValidation) fetchRunImage(name string) (image.Image, error) {
+	img, err := v.fetcher.FetchLocalImage(name)
+	if err != nil {
+		return nil, err
+	}
+	if found, err := img.Found(); err != nil || found {
+		return img, err
+	}
+	img, err = v.fetcher.FetchRemoteImage(name)
+	if err != nil {
+		return nil, err
+	}
+	if found, err := img.Found(); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, errors.New("not found in the docker daemon or its registry")
+	}
+	return img, nil
+}
@@ -0,0 +1,172 @@
+package pack_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	imgtest "github.com/buildpack/lifecycle/testhelpers"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/fatih/color"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/mocks"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestValidateBuilder(t *testing.T) {
+	color.NoColor = true
+	spec.Run(t, "ValidateBuilder", testValidateBuilder, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testValidateBuilder(t *testing.T, when spec.G, it spec.S) {
+	var (
+		mockController *gomock.Controller
+		mockDocker     *mocks.MockDocker
+		mockFetcher    *mocks.MockFetcher
+		builderImage   *imgtest.FakeImage
+		runImage       *imgtest.FakeImage
+		files          map[string]string
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockDocker = mocks.NewMockDocker(mockController)
+		mockFetcher = mocks.NewMockFetcher(mockController)
+
+		builderImage = imgtest.NewFakeImage(t, "some/builder", "", "")
+		h.AssertNil(t, builderImage.SetLabel("io.buildpacks.stack.id", "some.stack"))
+		h.AssertNil(t, builderImage.SetLabel("io.buildpacks.builder.metadata", `{
+  "buildpacks": [{"id": "some/bp", "version": "1.0"}],
+  "groups": [{"buildpacks": [{"id": "some/bp", "version": "1.0"}]}],
+  "stack": {"runImage": {"image": "some/run"}},
+  "lifecycle": {"version": "0.7.0"}
+}`))
+		h.AssertNil(t, builderImage.SetEnv("CNB_USER_ID", "1000"))
+		h.AssertNil(t, builderImage.SetEnv("CNB_GROUP_ID", "1000"))
+		mockFetcher.EXPECT().FetchLocalImage("some/builder").Return(builderImage, nil)
+
+		runImage = imgtest.NewFakeImage(t, "some/run", "", "")
+		h.AssertNil(t, runImage.SetLabel("io.buildpacks.stack.id", "some.stack"))
+		mockFetcher.EXPECT().FetchLocalImage("some/run").Return(runImage, nil).AnyTimes()
+
+		files = map[string]string{
+			"/lifecycle/detector": "",
+			"/lifecycle/restorer": "",
+			"/lifecycle/analyzer": "",
+			"/lifecycle/builder":  "",
+			"/lifecycle/exporter": "",
+			"/lifecycle/cacher":   "",
+			"/lifecycle/creator":  "",
+			"/buildpacks/order.toml": `[[groups]]
+  [[groups.buildpacks]]
+    id = "some/bp"
+    version = "1.0"
+`,
+			"/buildpacks/some_bp/1.0": "",
+		}
+		mockDocker.EXPECT().ImageInspectWithRaw(gomock.Any(), "some/builder").Return(types.ImageInspect{Os: "linux", Config: &container.Config{User: "1000:1000"}}, nil, nil).AnyTimes()
+		mockDocker.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), nil, "").Return(container.ContainerCreateCreatedBody{ID: "some-ctr"}, nil).AnyTimes()
+		mockDocker.EXPECT().ContainerRemove(gomock.Any(), "some-ctr", gomock.Any()).AnyTimes()
+		mockDocker.EXPECT().CopyFromContainer(gomock.Any(), "some-ctr", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, path string) (io.ReadCloser, types.ContainerPathStat, error) {
+				content, ok := files[path]
+				if !ok {
+					return nil, types.ContainerPathStat{}, errors.New("no such file")
+				}
+				return singleFileTar(t, path, content), types.ContainerPathStat{Name: path}, nil
+			}).AnyTimes()
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	it("finds nothing wrong with a valid builder", func() {
+		findings, err := pack.ValidateBuilder(context.TODO(), mockDocker, mockFetcher, "some/builder")
+		h.AssertNil(t, err)
+		h.AssertEq(t, len(findings), 0)
+	})
+
+	it("finds missing lifecycle binaries and buildpacks", func() {
+		delete(files, "/lifecycle/creator")
+		delete(files, "/buildpacks/some_bp/1.0")
+
+		findings, err := pack.ValidateBuilder(context.TODO(), mockDocker, mockFetcher, "some/builder")
+		h.AssertNil(t, err)
+		h.AssertEq(t, findings, []pack.BuilderFinding{
+			{Check: pack.CheckLifecycle, Message: "lifecycle binary '/lifecycle/creator' is missing", Fatal: true},
+			{Check: pack.CheckOrder, Message: "buildpack 'some/bp@1.0' of group 1 is missing from '/buildpacks/some_bp/1.0'", Fatal: true},
+		})
+	})
+
+	it("finds an order.toml that differs from the builder metadata", func() {
+		files["/buildpacks/order.toml"] = `[[groups]]
+  [[groups.buildpacks]]
+    id = "other/bp"
+`
+
+		findings, err := pack.ValidateBuilder(context.TODO(), mockDocker, mockFetcher, "some/builder")
+		h.AssertNil(t, err)
+		h.AssertEq(t, findings, []pack.BuilderFinding{
+			{Check: pack.CheckOrder, Message: "group 1 of '/buildpacks/order.toml' differs from the builder metadata"},
+		})
+	})
+
+	it("finds a run image of another stack", func() {
+		h.AssertNil(t, runImage.SetLabel("io.buildpacks.stack.id", "other.stack"))
+
+		findings, err := pack.ValidateBuilder(context.TODO(), mockDocker, mockFetcher, "some/builder")
+		h.AssertNil(t, err)
+		h.AssertEq(t, findings, []pack.BuilderFinding{
+			{Check: pack.CheckStack, Message: "run image 'some/run' has stack 'other.stack', but the builder has stack 'some.stack'", Fatal: true},
+		})
+	})
+
+	it("warns about builders running as their USER", func() {
+		h.AssertNil(t, builderImage.SetEnv("CNB_USER_ID", ""))
+		h.AssertNil(t, builderImage.SetEnv("CNB_GROUP_ID", ""))
+
+		findings, err := pack.ValidateBuilder(context.TODO(), mockDocker, mockFetcher, "some/builder")
+		h.AssertNil(t, err)
+		h.AssertEq(t, findings, []pack.BuilderFinding{
+			{Check: pack.CheckUser, Message: "builder has no CNB_USER_ID and CNB_GROUP_ID, so builds run as its USER '1000:1000'"},
+		})
+	})
+
+	it("finds missing labels", func() {
+		h.AssertNil(t, builderImage.SetLabel("io.buildpacks.builder.metadata", ""))
+
+		findings, err := pack.ValidateBuilder(context.TODO(), mockDocker, mockFetcher, "some/builder")
+		h.AssertNil(t, err)
+		h.AssertEq(t, findings, []pack.BuilderFinding{
+			{Check: pack.CheckLabels, Message: "builder 'some/builder' missing label 'io.buildpacks.builder.metadata' -- try recreating builder", Fatal: true},
+		})
+	})
+
+	it("fails when the builder is not in the daemon", func() {
+		h.AssertNil(t, builderImage.Delete())
+
+		_, err := pack.ValidateBuilder(context.TODO(), mockDocker, mockFetcher, "some/builder")
+		h.AssertError(t, err, "builder image 'some/builder' not found in the docker daemon")
+	})
+}
+
+func singleFileTar(t *testing.T, path, content string) io.ReadCloser {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: path, Mode: 0644, Size: int64(len(content))}))
+	_, err := tw.Write([]byte(content))
+	h.AssertNil(t, err)
+	h.AssertNil(t, tw.Close())
+	return ioutil.NopCloser(buf)
+}
@@ -0,0 +1,19 @@
+package pack
+
+import (
+	"github.com/buildpack/pack/buildpack"
+)
+
+// ValidateBuildpack checks the buildpack in dir without building with it, so that its authors get
+// feedback quickly: its buildpack.toml must be valid TOML with the required fields and no unknown
+// keys outside of metadata, and its bin/detect and bin/build must be executable. The problems
+// found are returned together as descriptor.ValidationErrors, each with the file, key and line it
+// is at.
+func ValidateBuildpack(dir string) error {
+	return buildpack.Validate(dir)
+}
+
+// ValidateBuildpack checks the buildpack in dir, see ValidateBuildpack
+func (c *Client) ValidateBuildpack(dir string) error {
+	return ValidateBuildpack(dir)
+}
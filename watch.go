@@ -0,0 +1,98 @@
+package pack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/build"
+	"github.com/buildpack/pack/git"
+	"github.com/buildpack/pack/style"
+)
+
+// Watch builds the app image like Build, then rebuilds it whenever the files of the app dir copied
+// into builds change, until ctx is canceled. The include and exclude patterns of the project
+// descriptor are read when the watch starts. A change cancels the build in progress, whose
+// containers are cleaned up, before the next build starts. Failed builds are logged rather than
+// ending the watch, so that the next change can fix them.
+func (c *Client) Watch(ctx context.Context, flags BuildFlags, ops ...func(*BuildFactory)) error {
+	if _, _, ok := git.ParseURL(flags.AppDir); ok {
+		return errors.Errorf("cannot watch app source %s, which is a Git repository", style.Symbol(flags.AppDir))
+	}
+	if flags.AppDir == AppDirStdin {
+		return errors.New("cannot watch an app read from stdin")
+	}
+	appDir := flags.AppDir
+	if appDir == "" {
+		appDir = "."
+	}
+	appDir, err := filepath.Abs(appDir)
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(appDir); err != nil || !fi.IsDir() {
+		return errors.Errorf("app dir %s must be a directory to be watched", style.Symbol(appDir))
+	}
+	flags.AppDir = appDir
+	if flags.RepoName, err = RepositoryName(c.logger, &flags); err != nil {
+		return err
+	}
+
+	// the files are selected like those copied into the build, with the include and exclude
+	// patterns of the project descriptor when no flags are given
+	include, exclude := flags.Include, flags.Exclude
+	project, err := ReadProjectDescriptor(appDir)
+	if err != nil {
+		return err
+	}
+	if project != nil {
+		if len(include) == 0 {
+			include = project.Build.Include
+		}
+		if len(exclude) == 0 {
+			exclude = project.Build.Exclude
+		}
+	}
+	changes, err := build.WatchAppDir(ctx, appDir, include, exclude, build.DefaultWatchInterval, build.DefaultWatchDebounce)
+	if err != nil {
+		return errors.Wrapf(err, "watching app dir %s", style.Symbol(appDir))
+	}
+	c.logger.Info("Watching %s for changes", style.Symbol(appDir))
+
+	for {
+		buildCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			_, err := c.Build(buildCtx, flags, ops...)
+			done <- err
+		}()
+
+		changed := false
+		select {
+		case err := <-done:
+			if err != nil {
+				c.logger.Error("Build failed: %s", err)
+			} else {
+				c.logger.Info("Successfully built image %s", style.Symbol(flags.RepoName))
+			}
+			select {
+			case _, changed = <-changes:
+			case <-ctx.Done():
+			}
+		case _, changed = <-changes:
+			cancel()
+			<-done
+			c.logger.Info("Canceled build, as the app dir changed")
+		case <-ctx.Done():
+			<-done
+		}
+		cancel()
+
+		if !changed {
+			return nil
+		}
+		c.logger.Info("Rebuilding image %s", style.Symbol(flags.RepoName))
+	}
+}
@@ -0,0 +1,94 @@
+package pack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// WorkspaceDescriptorName is the file at the root of a repository holding many apps, such as the
+// services of a monorepo, declaring the image each app directory is built into
+const WorkspaceDescriptorName = "pack-workspace.toml"
+
+// WorkspaceDescriptor is the pack-workspace.toml of a repository holding many apps
+type WorkspaceDescriptor struct {
+	// Parallelism limits the number of apps built at once, see WithParallelism
+	Parallelism int            `toml:"parallelism"`
+	Apps        []WorkspaceApp `toml:"apps"`
+
+	// dir is the directory of the descriptor, which the paths of the apps are relative to
+	dir string
+}
+
+// WorkspaceApp is an app directory of a workspace, relative to the workspace, built into the image
+// named Image or, when it has none, the image named by its project descriptor
+type WorkspaceApp struct {
+	Path  string `toml:"path"`
+	Image string `toml:"image"`
+}
+
+// ReadWorkspaceDescriptor reads the workspace descriptor at path, or in path when it is a directory
+func ReadWorkspaceDescriptor(path string) (*WorkspaceDescriptor, error) {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		path = filepath.Join(path, WorkspaceDescriptorName)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace := WorkspaceDescriptor{dir: filepath.Dir(path)}
+	if _, err := toml.DecodeFile(path, &workspace); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode workspace descriptor %s", style.Symbol(path))
+	}
+	if len(workspace.Apps) == 0 {
+		return nil, errors.Errorf("workspace descriptor %s has no apps", style.Symbol(path))
+	}
+	for _, app := range workspace.Apps {
+		if app.Path == "" {
+			return nil, errors.Errorf("apps in workspace descriptor %s must have a path", style.Symbol(path))
+		}
+		if filepath.IsAbs(app.Path) || escapesWorkspace(app.Path) {
+			return nil, errors.Errorf("app path %s in workspace descriptor %s must be within the workspace", style.Symbol(app.Path), style.Symbol(path))
+		}
+	}
+	return &workspace, nil
+}
+
+// Builds returns a build of each app of the workspace, with the given flags apart from the app
+// directory and image name
+func (w *WorkspaceDescriptor) Builds(flags BuildFlags) []BatchBuild {
+	var builds []BatchBuild
+	for _, app := range w.Apps {
+		appFlags := flags
+		appFlags.AppDir = filepath.Join(w.dir, app.Path)
+		appFlags.RepoName = app.Image
+		builds = append(builds, BatchBuild{Flags: appFlags})
+	}
+	return builds
+}
+
+// BuildWorkspace builds every app of the workspace descriptor at path with BuildAll, as many at once
+// as the descriptor allows unless ops limit it otherwise
+func (c *Client) BuildWorkspace(ctx context.Context, path string, flags BuildFlags, ops ...func(*Batch)) (BatchResults, error) {
+	workspace, err := ReadWorkspaceDescriptor(path)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.Parallelism > 0 {
+		ops = append([]func(*Batch){WithParallelism(workspace.Parallelism)}, ops...)
+	}
+	return c.BuildAll(ctx, workspace.Builds(flags), ops...), nil
+}
+
+// escapesWorkspace reports whether the relative path rel points outside of the workspace
+func escapesWorkspace(rel string) bool {
+	rel = filepath.Clean(rel)
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
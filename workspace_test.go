@@ -0,0 +1,99 @@
+package pack_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestWorkspaceDescriptor(t *testing.T) {
+	spec.Run(t, "WorkspaceDescriptor", testWorkspaceDescriptor, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testWorkspaceDescriptor(t *testing.T, when spec.G, it spec.S) {
+	var dir string
+
+	it.Before(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "pack.workspace")
+		h.AssertNil(t, err)
+		// the temp dir may be a symlink, such as on macOS
+		dir, err = filepath.EvalSymlinks(dir)
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(dir))
+	})
+
+	it("returns a build of each app, relative to the workspace", func() {
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(dir, "pack-workspace.toml"), []byte(`
+parallelism = 2
+
+[[apps]]
+  path = "services/api"
+  image = "registry.example.com/api"
+
+[[apps]]
+  path = "services/web"
+`), 0644))
+
+		workspace, err := pack.ReadWorkspaceDescriptor(dir)
+		h.AssertNil(t, err)
+		h.AssertEq(t, workspace.Parallelism, 2)
+
+		builds := workspace.Builds(pack.BuildFlags{Builder: "some/builder", Publish: true})
+		h.AssertEq(t, builds, []pack.BatchBuild{
+			{Flags: pack.BuildFlags{
+				AppDir:   filepath.Join(dir, "services", "api"),
+				RepoName: "registry.example.com/api",
+				Builder:  "some/builder",
+				Publish:  true,
+			}},
+			{Flags: pack.BuildFlags{
+				AppDir:  filepath.Join(dir, "services", "web"),
+				Builder: "some/builder",
+				Publish: true,
+			}},
+		})
+	})
+
+	it("reads a descriptor given by path", func() {
+		path := filepath.Join(dir, "other.toml")
+		h.AssertNil(t, ioutil.WriteFile(path, []byte(`
+[[apps]]
+  path = "."
+  image = "some/app"
+`), 0644))
+
+		workspace, err := pack.ReadWorkspaceDescriptor(path)
+		h.AssertNil(t, err)
+		h.AssertEq(t, workspace.Builds(pack.BuildFlags{})[0].Flags.AppDir, dir)
+	})
+
+	it("fails for apps outside of the workspace", func() {
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(dir, "pack-workspace.toml"), []byte(`
+[[apps]]
+  path = "../other"
+`), 0644))
+
+		_, err := pack.ReadWorkspaceDescriptor(dir)
+		h.AssertNotNil(t, err)
+		h.AssertContains(t, err.Error(), "app path '../other' in workspace descriptor")
+	})
+
+	it("fails without apps", func() {
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(dir, "pack-workspace.toml"), []byte("parallelism = 2\n"), 0644))
+
+		_, err := pack.ReadWorkspaceDescriptor(dir)
+		h.AssertNotNil(t, err)
+		h.AssertContains(t, err.Error(), "has no apps")
+	})
+}